@@ -0,0 +1,222 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// Autocorrelation returns the sample autocorrelation function of the series x
+// at lags 0 through maxLag,
+//  r_k = c_k / c_0, c_k = 1/n \sum_{t=0}^{n-k-1} (x_t - x̄)(x_{t+k} - x̄)
+// The autocovariances are divided by n rather than n-k, as acf in R, which
+// biases them towards zero but keeps the sequence positive semi-definite, as
+// needed by PartialAutocorrelation. The mean is computed once, and the
+// function takes O(n maxLag) time.
+//
+// The autocorrelations of a constant series are NaN. Autocorrelation panics
+// if maxLag is negative or not less than len(x).
+func Autocorrelation(x []float64, maxLag int) []float64 {
+	checkMaxLag(len(x), maxLag)
+	mean := Mean(x, nil)
+	d := make([]float64, len(x))
+	for i, v := range x {
+		d[i] = v - mean
+	}
+	r := make([]float64, maxLag+1)
+	for k := range r {
+		var c float64
+		for t, v := range d[:len(d)-k] {
+			c += v * d[t+k]
+		}
+		r[k] = c
+	}
+	c0 := r[0]
+	for k := range r {
+		r[k] /= c0
+	}
+	return r
+}
+
+// PartialAutocorrelation returns the sample partial autocorrelation function
+// of the series x at lags 0 through maxLag. The partial autocorrelation at
+// lag k is the last coefficient φ_kk of the autoregression of order k fitted
+// to the autocorrelations of x, as computed by Autocorrelation, by the
+// Durbin–Levinson recursion
+//  φ_kk = (r_k - \sum_{j=1}^{k-1} φ_{k-1,j} r_{k-j}) / (1 - \sum_{j=1}^{k-1} φ_{k-1,j} r_j)
+//  φ_kj = φ_{k-1,j} - φ_kk φ_{k-1,k-j}
+// These are the Yule–Walker estimates, as pacf in R. The value at lag 0 is 1,
+// so that the result is aligned with that of Autocorrelation. The function
+// takes O(n maxLag + maxLag²) time.
+//
+// The partial autocorrelations of a constant series are NaN.
+// PartialAutocorrelation panics if maxLag is negative or not less than
+// len(x).
+func PartialAutocorrelation(x []float64, maxLag int) []float64 {
+	r := Autocorrelation(x, maxLag)
+	pacf := make([]float64, maxLag+1)
+	pacf[0] = r[0]
+	phi := make([]float64, maxLag)
+	prev := make([]float64, maxLag)
+	for k := 1; k <= maxLag; k++ {
+		num, den := r[k], 1.0
+		for j := 1; j < k; j++ {
+			num -= prev[j-1] * r[k-j]
+			den -= prev[j-1] * r[j]
+		}
+		a := num / den
+		for j := 1; j < k; j++ {
+			phi[j-1] = prev[j-1] - a*prev[k-j-1]
+		}
+		phi[k-1] = a
+		copy(prev, phi[:k])
+		pacf[k] = a
+	}
+	return pacf
+}
+
+// AutocorrelationBound returns the half-width of the approximate 95%
+// confidence band, 1.96/√n, for the autocorrelations and partial
+// autocorrelations at non-zero lags of a white noise series of length n.
+// Sample values outside ±AutocorrelationBound(n) indicate serial structure,
+// as in the plots of acf and pacf in R. AutocorrelationBound panics if n is
+// not positive.
+func AutocorrelationBound(n int) float64 {
+	if n < 1 {
+		panic("stat: non-positive series length")
+	}
+	return 1.96 / math.Sqrt(float64(n))
+}
+
+// LjungBox performs the Ljung–Box portmanteau test of the null hypothesis
+// that the series x is white noise, with the statistic
+//  Q = n (n+2) \sum_{k=1}^{lags} r_k² / (n-k)
+// where r_k are the autocorrelations of x as computed by Autocorrelation. Q
+// is asymptotically chi-squared distributed with lags - fitdf degrees of
+// freedom, from which the p-value is computed. When x holds the residuals of
+// a fitted ARMA(p, q) model, fitdf should be p + q, as in Box.test in R; for
+// a raw series it is 0.
+//
+// LjungBox panics if lags is not positive or not less than len(x), or if
+// fitdf is negative or not less than lags.
+func LjungBox(x []float64, lags, fitdf int) (q, p float64) {
+	if lags < 1 {
+		panic("stat: non-positive lag")
+	}
+	if fitdf < 0 {
+		panic("stat: negative fitted degrees of freedom")
+	}
+	if fitdf >= lags {
+		panic("stat: fitted degrees of freedom not less than lags")
+	}
+	r := Autocorrelation(x, lags)
+	n := float64(len(x))
+	for k := 1; k <= lags; k++ {
+		q += r[k] * r[k] / (n - float64(k))
+	}
+	q *= n * (n + 2)
+	return q, chiSquareSurvival(q, float64(lags-fitdf))
+}
+
+// CrossCorrelationNorm specifies how CrossCorrelation normalizes the sums of
+// products of the overlapping parts of two series at each lag.
+type CrossCorrelationNorm int
+
+const (
+	// BiasedCrossCorrelation divides the sums by the length n of the
+	// series at every lag, as ccf in R. The correlations shrink towards
+	// zero as the overlap n-|k| decreases, which damps the noisy estimates
+	// at large lags.
+	BiasedCrossCorrelation CrossCorrelationNorm = iota
+	// UnbiasedCrossCorrelation divides the sums by the overlap n-|k|,
+	// which removes the shrinkage at the cost of a larger variance at
+	// large lags. The correlations may then exceed 1 in magnitude.
+	UnbiasedCrossCorrelation
+)
+
+// CrossCorrelation returns the sample cross-correlation function of the
+// series x and y at lags -maxLag through maxLag,
+//  r_k = c_k / (σ_x σ_y), c_k = 1/m \sum_t (x_{t+k} - x̄)(y_t - ȳ)
+// where the sum is over the times at which both series are observed. The
+// element i of the result is the correlation at lag k = i - maxLag. Each
+// series is centered by its own mean, and σ_x and σ_y are the standard
+// deviations with divisor n, so that r_0 is the Pearson correlation of x and
+// y. The divisor m is n or the overlap n-|k|, as specified by norm.
+//
+// As in R, r_k estimates the correlation of x_{t+k} with y_t, so a peak at a
+// positive lag k means that y leads x by k steps, and a peak at a negative
+// lag means that x leads y. CrossCorrelationLag returns the lag of the peak.
+//
+// The correlations are NaN if either series is constant. CrossCorrelation
+// panics if the lengths of x and y differ, if maxLag is negative or not less
+// than len(x), or if norm is not a valid CrossCorrelationNorm.
+func CrossCorrelation(x, y []float64, maxLag int, norm CrossCorrelationNorm) []float64 {
+	if len(x) != len(y) {
+		panic(ErrLengthMismatch{Got: len(y), Want: len(x)})
+	}
+	checkMaxLag(len(x), maxLag)
+	if norm != BiasedCrossCorrelation && norm != UnbiasedCrossCorrelation {
+		panic("stat: bad cross-correlation normalization")
+	}
+	n := len(x)
+	dx := make([]float64, n)
+	dy := make([]float64, n)
+	mx, my := Mean(x, nil), Mean(y, nil)
+	var sx, sy float64
+	for i := range x {
+		dx[i] = x[i] - mx
+		dy[i] = y[i] - my
+		sx += dx[i] * dx[i]
+		sy += dy[i] * dy[i]
+	}
+	scale := math.Sqrt(sx * sy)
+	r := make([]float64, 2*maxLag+1)
+	for i := range r {
+		k := i - maxLag
+		var c float64
+		overlap := n - k
+		if k >= 0 {
+			for t, v := range dy[:n-k] {
+				c += dx[t+k] * v
+			}
+		} else {
+			overlap = n + k
+			for t, v := range dx[:overlap] {
+				c += v * dy[t-k]
+			}
+		}
+		// σ_x σ_y has divisor n, which cancels that of the biased sums.
+		if norm == UnbiasedCrossCorrelation {
+			c *= float64(n) / float64(overlap)
+		}
+		r[i] = c / scale
+	}
+	return r
+}
+
+// CrossCorrelationLag returns the lag in -maxLag through maxLag at which the
+// cross-correlation of x and y, as computed by CrossCorrelation, is largest
+// in magnitude, and the correlation at that lag. Ties are broken in favor of
+// the lag nearest zero, and then of the negative lag. If the correlations
+// are NaN, CrossCorrelationLag returns 0 and NaN.
+func CrossCorrelationLag(x, y []float64, maxLag int, norm CrossCorrelationNorm) (lag int, r float64) {
+	cc := CrossCorrelation(x, y, maxLag, norm)
+	lag, r = 0, cc[maxLag]
+	for k := 1; k <= maxLag; k++ {
+		for _, l := range [2]int{-k, k} {
+			if v := cc[maxLag+l]; math.Abs(v) > math.Abs(r) || (math.IsNaN(r) && !math.IsNaN(v)) {
+				lag, r = l, v
+			}
+		}
+	}
+	return lag, r
+}
+
+func checkMaxLag(n, maxLag int) {
+	if maxLag < 0 {
+		panic("stat: negative lag")
+	}
+	if maxLag >= n {
+		panic("stat: lag exceeds series length")
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAutocorrelation(t *testing.T) {
+	// Values computed with exact rational arithmetic.
+	x := []float64{2, 7, 1, 8, 2, 8, 1, 8, 2, 8, 4, 5, 9, 0, 4, 5, 2, 3, 5, 3, 6, 0, 2, 8}
+	acf := Autocorrelation(x, 4)
+	wantACF := []float64{1, -0.5841242999490872, 0.32815113826460107, -0.16476652847479817, 0.1319732344170485}
+	if !sameFloatsNaN(acf, wantACF, 1e-14) {
+		t.Errorf("autocorrelation mismatch. Want %v, got %v", wantACF, acf)
+	}
+	pacf := PartialAutocorrelation(x, 4)
+	wantPACF := []float64{1, -0.5841242999490872, -0.019808869540522186, 0.029065295648430318, 0.0785944681143459}
+	if !sameFloatsNaN(pacf, wantPACF, 1e-14) {
+		t.Errorf("partial autocorrelation mismatch. Want %v, got %v", wantPACF, pacf)
+	}
+	if got := Autocorrelation(x, 0); len(got) != 1 || got[0] != 1 {
+		t.Errorf("autocorrelation at lag 0: want [1], got %v", got)
+	}
+
+	// An AR(1) process has autocorrelations φ^k and a partial
+	// autocorrelation function that cuts off after lag 1.
+	rnd := rand.New(rand.NewSource(1))
+	n := 5000
+	phi := 0.7
+	ar := make([]float64, n)
+	for i := 1; i < n; i++ {
+		ar[i] = phi*ar[i-1] + rnd.NormFloat64()
+	}
+	acf = Autocorrelation(ar, 10)
+	pacf = PartialAutocorrelation(ar, 10)
+	bound := AutocorrelationBound(n)
+	if bound != 1.96/math.Sqrt(5000) {
+		t.Errorf("unexpected bound %v", bound)
+	}
+	for k := 1; k <= 10; k++ {
+		if want := math.Pow(phi, float64(k)); math.Abs(acf[k]-want) > 0.05 {
+			t.Errorf("AR(1) autocorrelation at lag %d: want about %v, got %v", k, want, acf[k])
+		}
+	}
+	if math.Abs(pacf[1]-phi) > 0.05 {
+		t.Errorf("AR(1) partial autocorrelation at lag 1: want about %v, got %v", phi, pacf[1])
+	}
+	var outside int
+	for k := 2; k <= 10; k++ {
+		if math.Abs(pacf[k]) > bound {
+			outside++
+		}
+	}
+	if outside > 1 {
+		t.Errorf("AR(1) partial autocorrelations beyond lag 1 outside the bounds: %v", pacf[2:])
+	}
+
+	for _, v := range PartialAutocorrelation([]float64{3, 3, 3}, 2) {
+		if !math.IsNaN(v) {
+			t.Errorf("partial autocorrelation of constant series: want NaN, got %v", v)
+		}
+	}
+
+	for i, fn := range []func(){
+		func() { Autocorrelation(x, -1) },
+		func() { Autocorrelation(x, len(x)) },
+		func() { PartialAutocorrelation(nil, 0) },
+		func() { AutocorrelationBound(0) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestLjungBox(t *testing.T) {
+	// Statistics computed with exact rational arithmetic. With two degrees
+	// of freedom the p-value is exp(-Q/2).
+	x := []float64{2, 7, 1, 8, 2, 8, 1, 8, 2, 8, 4, 5, 9, 0, 4, 5, 2, 3, 5, 3, 6, 0, 2, 8}
+	q, p := LjungBox(x, 1, 0)
+	if math.Abs(q-9.256936844417) > 1e-12 {
+		t.Errorf("statistic at 1 lag mismatch. Want 9.256936844417, got %v", q)
+	}
+	if want := chiSquareSurvival(q, 1); math.Abs(p-want) > 1e-15 {
+		t.Errorf("p-value at 1 lag mismatch. Want %v, got %v", want, p)
+	}
+	q, p = LjungBox(x, 4, 2)
+	if math.Abs(q-13.661315160650808) > 1e-12 {
+		t.Errorf("statistic at 4 lags mismatch. Want 13.661315160650808, got %v", q)
+	}
+	if want := math.Exp(-q / 2); math.Abs(p-want) > 1e-12 {
+		t.Errorf("p-value at 4 lags mismatch. Want %v, got %v", want, p)
+	}
+	if _, p0 := LjungBox(x, 4, 0); p0 <= p {
+		t.Errorf("p-value did not increase with more degrees of freedom: %v and %v", p0, p)
+	}
+
+	// White noise is rarely rejected, and an AR(1) series always is.
+	rnd := rand.New(rand.NewSource(1))
+	var rejected int
+	noise := make([]float64, 200)
+	for trial := 0; trial < 200; trial++ {
+		for i := range noise {
+			noise[i] = rnd.NormFloat64()
+		}
+		if _, p := LjungBox(noise, 10, 0); p < 0.05 {
+			rejected++
+		}
+	}
+	if rejected > 20 {
+		t.Errorf("white noise rejected in %d of 200 trials at the 5%% level", rejected)
+	}
+	ar := make([]float64, 200)
+	for i := 1; i < len(ar); i++ {
+		ar[i] = 0.5*ar[i-1] + rnd.NormFloat64()
+	}
+	if _, p := LjungBox(ar, 10, 0); p > 1e-6 {
+		t.Errorf("AR(1) series not rejected: p = %v", p)
+	}
+
+	for i, fn := range []func(){
+		func() { LjungBox(x, 0, 0) },
+		func() { LjungBox(x, len(x), 0) },
+		func() { LjungBox(x, 4, -1) },
+		func() { LjungBox(x, 4, 4) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestCrossCorrelation(t *testing.T) {
+	x := []float64{2, 7, 1, 8, 2, 8, 1, 8, 2, 8, 4, 5, 9, 0, 4, 5, 2, 3, 5, 3, 6, 0, 2, 8}
+	y := []float64{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9, 7, 9, 3, 2, 3, 8, 4, 6, 2, 6, 4}
+	for _, test := range []struct {
+		norm CrossCorrelationNorm
+		want []float64
+	}{
+		{
+			norm: BiasedCrossCorrelation,
+			want: []float64{0.3097319589916171, -0.09394552992667234, 0.20362901364907635, -0.03165031777042528, -0.11599302843190534},
+		},
+		{
+			norm: UnbiasedCrossCorrelation,
+			want: []float64{0.3378894098090368, -0.09803011818435373, 0.20362901364907635, -0.03302641854305247, -0.12653784919844221},
+		},
+	} {
+		got := CrossCorrelation(x, y, 2, test.norm)
+		if !sameFloatsNaN(got, test.want, 1e-14) {
+			t.Errorf("norm %d: cross-correlation mismatch. Want %v, got %v", test.norm, test.want, got)
+		}
+	}
+	if r := CrossCorrelation(x, y, 0, BiasedCrossCorrelation)[0]; math.Abs(r-Correlation(x, y, nil)) > 1e-14 {
+		t.Errorf("cross-correlation at lag 0 is not the correlation: %v", r)
+	}
+	// The cross-correlation of a series with itself is its autocorrelation,
+	// symmetric in the lag.
+	acf := Autocorrelation(x, 3)
+	cc := CrossCorrelation(x, x, 3, BiasedCrossCorrelation)
+	for k := 0; k <= 3; k++ {
+		if math.Abs(cc[3+k]-acf[k]) > 1e-14 || math.Abs(cc[3-k]-acf[k]) > 1e-14 {
+			t.Errorf("cross-correlation of x with itself at lag ±%d mismatch. Want %v, got %v and %v", k, acf[k], cc[3-k], cc[3+k])
+		}
+	}
+
+	// y leads x by three steps, and x leads -y by three steps.
+	rnd := rand.New(rand.NewSource(1))
+	n := 500
+	a := make([]float64, n)
+	b := make([]float64, n)
+	neg := make([]float64, n)
+	for i := range b {
+		b[i] = rnd.NormFloat64()
+	}
+	for i := range a {
+		a[i] = 0.3 * rnd.NormFloat64()
+		if i >= 3 {
+			a[i] += b[i-3]
+		}
+	}
+	for i := range neg {
+		neg[i] = -a[(i+n-3)%n]
+	}
+	for _, norm := range []CrossCorrelationNorm{BiasedCrossCorrelation, UnbiasedCrossCorrelation} {
+		if lag, r := CrossCorrelationLag(a, b, 10, norm); lag != 3 || r < 0.9 {
+			t.Errorf("norm %d: want peak near 1 at lag 3, got %v at lag %d", norm, r, lag)
+		}
+		if lag, r := CrossCorrelationLag(a, neg, 10, norm); lag != -3 || r > -0.9 {
+			t.Errorf("norm %d: want peak near -1 at lag -3, got %v at lag %d", norm, r, lag)
+		}
+	}
+	// Ties favor the lag nearest zero.
+	if lag, _ := CrossCorrelationLag([]float64{1, 2, 1, 2, 1, 2}, []float64{1, 2, 1, 2, 1, 2}, 4, UnbiasedCrossCorrelation); lag != 0 {
+		t.Errorf("tied peaks: want lag 0, got %d", lag)
+	}
+	if lag, r := CrossCorrelationLag([]float64{1, 1, 1}, x[:3], 1, BiasedCrossCorrelation); lag != 0 || !math.IsNaN(r) {
+		t.Errorf("constant series: want NaN at lag 0, got %v at lag %d", r, lag)
+	}
+
+	for i, fn := range []func(){
+		func() { CrossCorrelation(x, y[:3], 1, BiasedCrossCorrelation) },
+		func() { CrossCorrelation(x, y, -1, BiasedCrossCorrelation) },
+		func() { CrossCorrelation(x, y, len(x), BiasedCrossCorrelation) },
+		func() { CrossCorrelation(x, y, 1, CrossCorrelationNorm(2)) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ADFRegression specifies the deterministic terms included in the test
+// regression of the augmented Dickey–Fuller test.
+type ADFRegression int
+
+const (
+	// ADFNone includes no deterministic terms.
+	ADFNone ADFRegression = iota
+	// ADFConstant includes a constant.
+	ADFConstant
+	// ADFConstantTrend includes a constant and a linear time trend.
+	ADFConstantTrend
+)
+
+// ADF performs the augmented Dickey–Fuller test for a unit root in the
+// series x. The test statistic is the t statistic of γ in the regression
+//  Δx_t = [α + β t] + γ x_{t-1} + \sum_{i=1}^k δ_i Δx_{t-i} + ε_t
+// where the deterministic terms in brackets are specified by regression.
+// The null hypothesis is that x has a unit root, γ = 0; small (large
+// negative) values of the statistic are evidence against it.
+//
+// The number of lagged differences k is selected by minimizing the Akaike
+// information criterion over 0 ≤ k ≤ maxLag, with each candidate fitted to
+// the same sample, and the test regression is then refitted on all of the
+// available observations. If maxLag is negative it is set to Schwert's rule
+// of thumb, ⌈12 (n/100)^{1/4}⌉. The lag used is returned in usedLag; use
+// ADFLag to fix the number of lags instead.
+//
+// The p-value is computed using the response surface approximation of
+// MacKinnon (1994) to the asymptotic distribution of the statistic, as is
+// done by statsmodels' adfuller.
+func ADF(x []float64, maxLag int, regression ADFRegression) (stat, p float64, usedLag int) {
+	nt := adfTrendTerms(regression)
+	if maxLag < 0 {
+		maxLag = int(math.Ceil(12 * math.Pow(float64(len(x))/100, 0.25)))
+		if m := len(x)/2 - nt - 1; m < maxLag {
+			maxLag = m
+		}
+		if maxLag < 0 {
+			panic("stat: series too short for ADF test")
+		}
+	}
+	best := math.Inf(1)
+	for k := 0; k <= maxLag; k++ {
+		l := adfFit(x, k, maxLag+1, nt)
+		if aic := l.aic(); aic < best {
+			best = aic
+			usedLag = k
+		}
+	}
+	stat, p = ADFLag(x, usedLag, regression)
+	return stat, p, usedLag
+}
+
+// ADFLag performs the augmented Dickey–Fuller test using a fixed number of
+// lagged differences in the test regression. See ADF for details.
+func ADFLag(x []float64, lag int, regression ADFRegression) (stat, p float64) {
+	if lag < 0 {
+		panic("stat: negative lag")
+	}
+	nt := adfTrendTerms(regression)
+	l := adfFit(x, lag, lag+1, nt)
+	stat = l.coef[0] / l.stdErr()[0]
+	return stat, mackinnonP(stat, regression)
+}
+
+func adfTrendTerms(regression ADFRegression) int {
+	switch regression {
+	case ADFNone:
+		return 0
+	case ADFConstant:
+		return 1
+	case ADFConstantTrend:
+		return 2
+	}
+	panic("stat: unknown ADF regression")
+}
+
+// adfFit fits the ADF test regression with k lagged differences and nt
+// deterministic terms using the observations Δx_t for t ≥ start. The
+// coefficient of the lagged level is the first element of the result.
+func adfFit(x []float64, k, start, nt int) *lstsq {
+	n := len(x) - start
+	p := 1 + k + nt
+	if n <= p {
+		panic("stat: series too short for ADF test")
+	}
+	design := mat64.NewDense(n, p, nil)
+	y := make([]float64, n)
+	for i := 0; i < n; i++ {
+		t := start + i
+		y[i] = x[t] - x[t-1]
+		row := design.RawRowView(i)
+		row[0] = x[t-1]
+		for j := 1; j <= k; j++ {
+			row[j] = x[t-j] - x[t-j-1]
+		}
+		if nt > 0 {
+			row[k+1] = 1
+		}
+		if nt > 1 {
+			row[k+2] = float64(i + 1)
+		}
+	}
+	return fitLstsq(design, y)
+}
+
+// MacKinnon (1994) response surface coefficients for the asymptotic
+// distribution of the Dickey–Fuller statistic with a single series, indexed
+// by ADFRegression.
+var (
+	adfTauMax  = [3]float64{math.Inf(1), 2.74, 0.7}
+	adfTauMin  = [3]float64{-19.04, -18.83, -16.18}
+	adfTauStar = [3]float64{-1.04, -1.61, -2.89}
+
+	adfTauSmallP = [3][3]float64{
+		{0.6344, 1.2378, 3.2496e-2},
+		{2.1659, 1.4412, 3.8269e-2},
+		{3.2512, 1.6047, 4.9588e-2},
+	}
+	adfTauLargeP = [3][4]float64{
+		{0.4797, 9.3557e-1, -0.6999e-1, 3.3066e-2},
+		{1.7339, 9.3202e-1, -1.2745e-1, -1.0368e-2},
+		{2.5261, 6.1654e-1, -3.7956e-1, -6.0285e-2},
+	}
+)
+
+// mackinnonP returns the approximate asymptotic p-value of the Dickey–Fuller
+// statistic tau.
+func mackinnonP(tau float64, regression ADFRegression) float64 {
+	r := int(regression)
+	if tau > adfTauMax[r] {
+		return 1
+	}
+	if tau < adfTauMin[r] {
+		return 0
+	}
+	var coef []float64
+	if tau <= adfTauStar[r] {
+		coef = adfTauSmallP[r][:]
+	} else {
+		coef = adfTauLargeP[r][:]
+	}
+	// Evaluate the polynomial by Horner's method.
+	var z float64
+	for i := len(coef) - 1; i >= 0; i-- {
+		z = z*tau + coef[i]
+	}
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
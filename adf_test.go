@@ -0,0 +1,176 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestMackinnonP(t *testing.T) {
+	// Asymptotic critical values of the Dickey–Fuller distribution.
+	for _, test := range []struct {
+		tau   float64
+		reg   ADFRegression
+		level float64
+	}{
+		{-2.5658, ADFNone, 0.01},
+		{-1.9393, ADFNone, 0.05},
+		{-1.6156, ADFNone, 0.10},
+		{-3.4336, ADFConstant, 0.01},
+		{-2.8621, ADFConstant, 0.05},
+		{-2.5671, ADFConstant, 0.10},
+		{-3.9638, ADFConstantTrend, 0.01},
+		{-3.4126, ADFConstantTrend, 0.05},
+		{-3.1279, ADFConstantTrend, 0.10},
+	} {
+		p := mackinnonP(test.tau, test.reg)
+		if math.Abs(p-test.level) > 2e-3 {
+			t.Errorf("regression %d: p-value mismatch at %v. Want %v, got %v", test.reg, test.tau, test.level, p)
+		}
+	}
+	if p := mackinnonP(-30, ADFConstant); p != 0 {
+		t.Errorf("expected zero p-value below the table, got %v", p)
+	}
+	if p := mackinnonP(5, ADFConstant); p != 1 {
+		t.Errorf("expected unit p-value above the table, got %v", p)
+	}
+}
+
+func TestADF(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	n := 500
+	walk := make([]float64, n)
+	ar := make([]float64, n)
+	for i := 1; i < n; i++ {
+		e := rnd.NormFloat64()
+		walk[i] = walk[i-1] + e
+		ar[i] = 0.5*ar[i-1] + e
+	}
+	trended := make([]float64, n)
+	for i := range trended {
+		trended[i] = ar[i] + 3 + 0.05*float64(i)
+	}
+	for _, reg := range []ADFRegression{ADFNone, ADFConstant, ADFConstantTrend} {
+		_, p, _ := ADF(walk, 8, reg)
+		if p < 0.1 {
+			t.Errorf("regression %d: unit root rejected for random walk, p = %v", reg, p)
+		}
+		stat, p, lag := ADF(ar, 8, reg)
+		if p > 0.01 {
+			t.Errorf("regression %d: unit root not rejected for AR(1), p = %v", reg, p)
+		}
+		if s, _ := ADFLag(ar, lag, reg); s != stat {
+			t.Errorf("regression %d: ADF and ADFLag disagree: %v, %v", reg, stat, s)
+		}
+	}
+	if _, p, _ := ADF(trended, -1, ADFConstantTrend); p > 0.01 {
+		t.Errorf("unit root not rejected for trend stationary series, p = %v", p)
+	}
+
+	// With no lags the statistic has a closed form. Without deterministic
+	// terms γ = Σ x_{t-1} Δx_t / Σ x_{t-1}², and with a constant the lagged
+	// levels are centered first.
+	x := ar[:50]
+	for _, reg := range []ADFRegression{ADFNone, ADFConstant} {
+		m := len(x) - 1
+		lagged := x[:m]
+		diff := make([]float64, m)
+		for i := range diff {
+			diff[i] = x[i+1] - x[i]
+		}
+		var mx, md float64
+		if reg == ADFConstant {
+			mx = Mean(lagged, nil)
+			md = Mean(diff, nil)
+		}
+		var sxx, sxy float64
+		for i, v := range lagged {
+			sxx += (v - mx) * (v - mx)
+			sxy += (v - mx) * (diff[i] - md)
+		}
+		gamma := sxy / sxx
+		var ssr float64
+		for i, v := range lagged {
+			r := diff[i] - md - gamma*(v-mx)
+			ssr += r * r
+		}
+		df := float64(m - 1 - adfTrendTerms(reg))
+		want := gamma / math.Sqrt(ssr/df/sxx)
+		got, _ := ADFLag(x, 0, reg)
+		if math.Abs(got-want) > 1e-10 {
+			t.Errorf("regression %d: statistic mismatch. Want %v, got %v", reg, want, got)
+		}
+	}
+
+	if !Panics(func() { ADF(ar[:5], 4, ADFConstant) }) {
+		t.Errorf("ADF did not panic with short series")
+	}
+	if !Panics(func() { ADFLag(ar, -1, ADFConstant) }) {
+		t.Errorf("ADFLag did not panic with negative lag")
+	}
+}
+
+func TestADFStatsmodels(t *testing.T) {
+	// A random walk and an AR(1) series with coefficient 0.5 and the same
+	// standard normal innovations, rounded to three decimals.
+	walk := []float64{
+		0.0, -0.518, -1.285, -0.023, 0.798, 1.192, 2.058, 3.008, 3.123,
+		3.752, 3.767, 4.797, 4.651, 3.718, 3.863, 3.529, 2.288, 3.986,
+		4.562, 3.635, 4.45, 2.692, 2.395, 2.214, 2.095, 2.271, 1.23, 1.862,
+		2.112, 2.599, 1.344, 1.723, 2.988, 2.241, 0.379, 1.706, 1.78, 2.411,
+		2.825, 3.093, 4.569, 3.935, 3.538, 3.448, 2.246, 0.524, 0.302,
+		-1.229, -1.237, -1.614, -2.279, -3.105, -3.95, -4.52, -5.552,
+		-5.942, -5.528, -6.031, -5.72, -4.334, -4.198, -3.489, -2.562,
+		-1.879, -1.589, -2.341, -2.185, -0.213, 0.704, 0.61, 2.182, 1.222,
+		0.323, 2.572, 3.11, 3.78, 4.121, 3.151, 2.74, 3.025, 2.887, 4.201,
+		3.071, 2.297, 1.621, 1.731, 0.568, -1.718, -1.495, -2.562, -1.133,
+		-1.429, -0.902, -0.202, -0.366, 0.068, -0.052, 0.429, 0.781, 0.93,
+	}
+	ar := []float64{
+		0.0, -0.518, -1.026, 0.749, 1.196, 0.992, 1.362, 1.631, 0.93, 1.094,
+		0.562, 1.311, 0.51, -0.678, -0.194, -0.431, -1.456, 0.97, 1.061,
+		-0.396, 0.617, -1.449, -1.022, -0.692, -0.465, -0.057, -1.069,
+		0.098, 0.299, 0.636, -0.937, -0.09, 1.22, -0.137, -1.931, 0.362,
+		0.255, 0.758, 0.793, 0.664, 1.808, 0.27, -0.262, -0.221, -1.312,
+		-2.378, -1.411, -2.236, -1.126, -0.94, -1.135, -1.394, -1.542,
+		-1.341, -1.702, -1.241, -0.206, -0.606, 0.008, 1.39, 0.831, 1.125,
+		1.489, 1.428, 1.004, -0.25, 0.031, 1.987, 1.91, 0.861, 2.003, 0.042,
+		-0.878, 1.81, 1.443, 1.392, 1.037, -0.451, -0.637, -0.033, -0.155,
+		1.236, -0.512, -1.03, -1.191, -0.485, -1.405, -2.989, -1.272,
+		-1.703, 0.578, -0.007, 0.524, 0.962, 0.317, 0.592, 0.176, 0.569,
+		0.637, 0.468,
+	}
+	// The expected values follow statsmodels' adfuller(x, regression=...,
+	// autolag="AIC") with the default maximum lag of 12 and its mackinnonp,
+	// evaluated with exact rational least squares.
+	for i, test := range []struct {
+		x       []float64
+		reg     ADFRegression
+		stat, p float64
+		lag     int
+	}{
+		{walk, ADFNone, -2.227551109508409, 0.02490347976578045, 3},
+		{walk, ADFConstant, -2.361060526783864, 0.15302965312370093, 3},
+		{walk, ADFConstantTrend, -2.5477851882431155, 0.30449731138348707, 3},
+		{ar, ADFNone, -4.996910175405863, 1.0718700955414657e-06, 0},
+		{ar, ADFConstant, -4.9722230954988795, 2.518070936393026e-05, 0},
+		{ar, ADFConstantTrend, -4.9468707911213325, 0.00025681149969482975, 0},
+	} {
+		stat, p, lag := ADF(test.x, -1, test.reg)
+		if lag != test.lag {
+			t.Errorf("case %d: lag mismatch. Want %d, got %d", i, test.lag, lag)
+		}
+		if math.Abs(stat-test.stat) > 1e-3 {
+			t.Errorf("case %d: statistic mismatch. Want %v, got %v", i, test.stat, stat)
+		}
+		if !floats.EqualWithinAbsOrRel(p, test.p, 1e-3, 1e-3) {
+			t.Errorf("case %d: p-value mismatch. Want %v, got %v", i, test.p, p)
+		}
+	}
+}
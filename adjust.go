@@ -0,0 +1,84 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+// AdjustMethod specifies the method used by AdjustPValues.
+type AdjustMethod int
+
+const (
+	// Bonferroni multiplies each p-value by the number of tests, m,
+	// controlling the familywise error rate.
+	Bonferroni AdjustMethod = iota
+	// Holm is the step-down method of Holm, which controls the familywise
+	// error rate and is uniformly more powerful than Bonferroni. The ith
+	// smallest p-value is multiplied by m-i+1, and the running maximum is
+	// taken in increasing order of the p-values.
+	Holm
+	// Hochberg is the step-up method of Hochberg, which controls the
+	// familywise error rate for independent tests. The ith smallest p-value
+	// is multiplied by m-i+1, and the running minimum is taken in
+	// decreasing order of the p-values.
+	Hochberg
+	// BenjaminiHochberg is the step-up method of Benjamini and Hochberg,
+	// which controls the false discovery rate for independent or positively
+	// dependent tests. The ith smallest p-value is multiplied by m/i, and
+	// the running minimum is taken in decreasing order of the p-values.
+	BenjaminiHochberg
+)
+
+// AdjustPValues returns the p-values of p adjusted for multiple comparisons
+// by the given method, in the order of p, as computed by R's p.adjust. The
+// adjusted p-values are clamped to at most 1, and a test is rejected at level
+// α when its adjusted p-value is at most α. NaN values are kept as NaN and
+// are not counted among the tests. p is not modified. AdjustPValues panics if
+// a p-value is outside [0, 1] or if method is not a known AdjustMethod.
+func AdjustPValues(p []float64, method AdjustMethod) []float64 {
+	adj := make([]float64, len(p))
+	idx := make([]int, 0, len(p))
+	for i, v := range p {
+		switch {
+		case math.IsNaN(v):
+			adj[i] = v
+			continue
+		case v < 0 || v > 1:
+			panic("stat: p-value out of range")
+		}
+		idx = append(idx, i)
+	}
+	sort.Stable(indexSorter{idx: idx, x: p})
+	m := float64(len(idx))
+
+	switch method {
+	case Bonferroni:
+		for _, i := range idx {
+			adj[i] = math.Min(1, m*p[i])
+		}
+	case Holm:
+		var max float64
+		for k, i := range idx {
+			max = math.Max(max, (m-float64(k))*p[i])
+			adj[i] = math.Min(1, max)
+		}
+	case Hochberg, BenjaminiHochberg:
+		min := math.Inf(1)
+		for k := len(idx) - 1; k >= 0; k-- {
+			i := idx[k]
+			f := m - float64(k)
+			if method == BenjaminiHochberg {
+				f = m / float64(k+1)
+			}
+			min = math.Min(min, f*p[i])
+			adj[i] = math.Min(1, min)
+		}
+	default:
+		panic("stat: unknown adjustment method")
+	}
+	return adj
+}
@@ -0,0 +1,64 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestAdjustPValues(t *testing.T) {
+	// The expected values are those of R's p.adjust. With the
+	// Benjamini–Hochberg method, the raw adjustment of 0.03 is 0.06, which
+	// the running minimum lowers to the 0.0571 of the larger 0.04.
+	p := []float64{0.01, 0.02, 0.03, 0.04, 0.05, 0.3, 0.001, 0.04, 0.8, 0.0002}
+	for _, test := range []struct {
+		method AdjustMethod
+		want   []float64
+	}{
+		{Bonferroni, []float64{0.1, 0.2, 0.3, 0.4, 0.5, 1, 0.01, 0.4, 1, 0.002}},
+		{Holm, []float64{0.08, 0.14, 0.18, 0.2, 0.2, 0.6, 0.009, 0.2, 0.8, 0.002}},
+		{Hochberg, []float64{0.08, 0.14, 0.15, 0.15, 0.15, 0.6, 0.009, 0.15, 0.8, 0.002}},
+		{BenjaminiHochberg, []float64{1.0 / 30, 0.05, 0.4 / 7, 0.4 / 7, 0.0625, 1.0 / 3, 0.005, 0.4 / 7, 0.8, 0.002}},
+	} {
+		pCopy := append([]float64(nil), p...)
+		got := AdjustPValues(p, test.method)
+		if !floats.EqualApprox(got, test.want, 1e-15) {
+			t.Errorf("method %d: got %v, want %v", test.method, got, test.want)
+		}
+		if !floats.Same(pCopy, p) {
+			t.Errorf("method %d: p modified", test.method)
+		}
+		// Adjusted p-values are monotone in the raw p-values.
+		for i := range p {
+			for j := range p {
+				if p[i] < p[j] && got[i] > got[j] {
+					t.Errorf("method %d: adjusted p-values not monotone at %v and %v", test.method, p[i], p[j])
+				}
+			}
+		}
+	}
+
+	// NaN values are kept and not counted among the tests.
+	got := AdjustPValues([]float64{0.01, math.NaN(), 0.04}, BenjaminiHochberg)
+	if want := []float64{0.02, math.NaN(), 0.04}; !floats.Same(got, want) {
+		t.Errorf("NaN p-value: got %v, want %v", got, want)
+	}
+	if got := AdjustPValues(nil, Holm); len(got) != 0 {
+		t.Errorf("expected empty result for no p-values, got %v", got)
+	}
+
+	for _, f := range []func(){
+		func() { AdjustPValues([]float64{0.5, 1.5}, Holm) },
+		func() { AdjustPValues([]float64{-0.1}, Bonferroni) },
+		func() { AdjustPValues(p, AdjustMethod(-1)) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
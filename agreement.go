@@ -0,0 +1,350 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// z975 is the 0.975 quantile of the standard normal distribution.
+const z975 = 1.959963984540054
+
+// ConcordanceCorrelation returns Lin's concordance correlation coefficient
+// between the paired measurements x and y,
+//  ρc = 2 s_xy / (s_x² + s_y² + (x̄ - ȳ)²)
+// where the variances and covariance use the divisor n, together with a 95%
+// confidence interval. The coefficient measures the agreement of the pairs
+// with the line y = x, and is the product of the Pearson correlation and the
+// bias correction factor returned by ConcordanceDecomposition.
+//
+// The interval is computed on the Fisher z scale using the asymptotic
+// variance of Lin (1989, 2000), as by DescTools::CCC and epiR::epi.ccc in
+// R. ConcordanceCorrelation returns NaN values if x or y is constant, and
+// panics if the lengths of x and y differ or are less than three.
+func ConcordanceCorrelation(x, y []float64) (ccc float64, ci [2]float64) {
+	m := concordanceMoments(x, y)
+	if m.sxx == 0 || m.syy == 0 {
+		nan := math.NaN()
+		return nan, [2]float64{nan, nan}
+	}
+	r := m.sxy / math.Sqrt(m.sxx*m.syy)
+	d := m.mx - m.my
+	ccc = 2 * m.sxy / (m.sxx + m.syy + d*d)
+	u2 := d * d / math.Sqrt(m.sxx*m.syy)
+	p2 := ccc * ccc
+	q := 1 - p2
+	v := ((1-r*r)*p2/(q*r*r) + 2*p2*ccc*(1-ccc)*u2/(r*q*q) - p2*p2*u2*u2/(2*r*r*q*q)) /
+		float64(len(x)-2)
+	z := math.Atanh(ccc)
+	se := math.Sqrt(v)
+	return ccc, [2]float64{math.Tanh(z - z975*se), math.Tanh(z + z975*se)}
+}
+
+// ConcordanceDecomposition returns the components of Lin's concordance
+// correlation coefficient between x and y, which is the product
+// accuracy × precision. Precision is the Pearson correlation of x and y, and
+// accuracy is the bias correction factor
+//  C_b = 2 / (v + 1/v + u²)
+// which measures the departure of the best-fit line from y = x through the
+// scale shift v = s_x / s_y and the location shift u = (x̄ - ȳ) / √(s_x s_y).
+// The conventions are the same as for ConcordanceCorrelation.
+func ConcordanceDecomposition(x, y []float64) (accuracy, precision, scaleShift, locationShift float64) {
+	m := concordanceMoments(x, y)
+	if m.sxx == 0 || m.syy == 0 {
+		nan := math.NaN()
+		return nan, nan, nan, nan
+	}
+	precision = m.sxy / math.Sqrt(m.sxx*m.syy)
+	scaleShift = math.Sqrt(m.sxx / m.syy)
+	locationShift = (m.mx - m.my) / math.Pow(m.sxx*m.syy, 0.25)
+	accuracy = 2 / (scaleShift + 1/scaleShift + locationShift*locationShift)
+	return accuracy, precision, scaleShift, locationShift
+}
+
+type pairedMoments struct {
+	mx, my        float64
+	sxx, syy, sxy float64
+}
+
+// concordanceMoments returns the means and the variances and covariance with
+// divisor n of x and y.
+func concordanceMoments(x, y []float64) pairedMoments {
+	checkLengths(x, y)
+	if len(x) < 3 {
+		panic("stat: too few samples")
+	}
+	var m pairedMoments
+	m.mx = Mean(x, nil)
+	m.my = Mean(y, nil)
+	for i, v := range x {
+		dx := v - m.mx
+		dy := y[i] - m.my
+		m.sxx += dx * dx
+		m.syy += dy * dy
+		m.sxy += dx * dy
+	}
+	n := float64(len(x))
+	m.sxx /= n
+	m.syy /= n
+	m.sxy /= n
+	return m
+}
+
+// MeasurementLevel specifies the level of measurement of ratings, which
+// determines how disagreements between pairs of values are weighted.
+type MeasurementLevel int
+
+const (
+	// NominalLevel treats values as unordered categories; all pairs of
+	// distinct values disagree equally.
+	NominalLevel MeasurementLevel = iota
+	// OrdinalLevel treats values as ranks; the disagreement between two
+	// values grows with the number of ratings between them.
+	OrdinalLevel
+	// IntervalLevel uses the squared difference of values.
+	IntervalLevel
+	// RatioLevel uses the squared difference of values relative to their
+	// sum. The values must be non-negative.
+	RatioLevel
+)
+
+// KrippendorffAlpha returns Krippendorff's alpha reliability coefficient of
+// the ratings, in which rows are units and columns are raters, and NaN marks
+// a missing rating. Units with fewer than two ratings are excluded.
+//
+// The coefficient is computed from the coincidence matrix o, in which o_ck
+// counts the pairs of values c and k assigned to the same unit, each pair
+// within a unit with m ratings weighted by 1/(m-1), as
+//  α = 1 - (n-1) Σ_ck o_ck δ²_ck / Σ_ck n_c n_k δ²_ck
+// where n_c = Σ_k o_ck, n = Σ_c n_c, and δ² is the difference function for the
+// level of measurement,
+//  nominal   δ²_ck = 0 if c = k and 1 otherwise
+//  ordinal   δ²_ck = (Σ_{g=c}^{k} n_g - (n_c + n_k)/2)²
+//  interval  δ²_ck = (c - k)²
+//  ratio     δ²_ck = ((c - k) / (c + k))²
+// Alpha is 1 for perfect agreement and 0 when the agreement is that expected
+// by chance. If there is no variation among the ratings, KrippendorffAlpha
+// returns NaN.
+func KrippendorffAlpha(ratings *mat64.Dense, level MeasurementLevel) float64 {
+	units := pairableUnits(ratings)
+	return krippendorffAlpha(units, level)
+}
+
+// KrippendorffAlphaCI returns a bootstrap confidence interval for
+// Krippendorff's alpha of the ratings at the given confidence level,
+// obtained from the percentiles of alpha over nBoot resamples of the units
+// with replacement. If src is nil the global random source is used.
+// KrippendorffAlphaCI panics if conf is not between 0 and 1 or if nBoot is
+// not positive.
+func KrippendorffAlphaCI(ratings *mat64.Dense, level MeasurementLevel, conf float64, nBoot int, src *rand.Rand) (lo, hi float64) {
+	if !(conf > 0 && conf < 1) {
+		panic("stat: confidence level out of range")
+	}
+	if nBoot < 1 {
+		panic("stat: non-positive number of resamples")
+	}
+	intn := rand.Intn
+	if src != nil {
+		intn = src.Intn
+	}
+	units := pairableUnits(ratings)
+	resample := make([][]float64, len(units))
+	alphas := make([]float64, 0, nBoot)
+	for b := 0; b < nBoot; b++ {
+		for i := range resample {
+			resample[i] = units[intn(len(units))]
+		}
+		a := krippendorffAlpha(resample, level)
+		if !math.IsNaN(a) {
+			alphas = append(alphas, a)
+		}
+	}
+	if len(alphas) == 0 {
+		return math.NaN(), math.NaN()
+	}
+	sort.Float64s(alphas)
+	tail := (1 - conf) / 2
+	return Quantile(tail, Empirical, alphas, nil), Quantile(1-tail, Empirical, alphas, nil)
+}
+
+// pairableUnits returns the non-missing ratings of each unit with at least
+// two ratings.
+func pairableUnits(ratings *mat64.Dense) [][]float64 {
+	r, c := ratings.Dims()
+	var units [][]float64
+	for i := 0; i < r; i++ {
+		var u []float64
+		for _, v := range ratings.RawRowView(i)[:c] {
+			if !math.IsNaN(v) {
+				u = append(u, v)
+			}
+		}
+		if len(u) >= 2 {
+			units = append(units, u)
+		}
+	}
+	return units
+}
+
+func krippendorffAlpha(units [][]float64, level MeasurementLevel) float64 {
+	// Index the distinct values in increasing order.
+	index := make(map[float64]int)
+	var values []float64
+	for _, u := range units {
+		for _, v := range u {
+			if level == RatioLevel && v < 0 {
+				panic("stat: negative value at ratio level")
+			}
+			if _, ok := index[v]; !ok {
+				index[v] = 0
+				values = append(values, v)
+			}
+		}
+	}
+	sort.Float64s(values)
+	for i, v := range values {
+		index[v] = i
+	}
+	k := len(values)
+
+	// Coincidence matrix and value totals.
+	o := make([]float64, k*k)
+	for _, u := range units {
+		w := 1 / float64(len(u)-1)
+		for i, a := range u {
+			for j, b := range u {
+				if i != j {
+					o[index[a]*k+index[b]] += w
+				}
+			}
+		}
+	}
+	nc := make([]float64, k)
+	var n float64
+	for c := range nc {
+		for _, v := range o[c*k : (c+1)*k] {
+			nc[c] += v
+		}
+		n += nc[c]
+	}
+
+	delta2 := func(c, d int) float64 {
+		switch level {
+		case NominalLevel:
+			if c == d {
+				return 0
+			}
+			return 1
+		case OrdinalLevel:
+			if c > d {
+				c, d = d, c
+			}
+			var s float64
+			for g := c; g <= d; g++ {
+				s += nc[g]
+			}
+			s -= (nc[c] + nc[d]) / 2
+			return s * s
+		case IntervalLevel:
+			diff := values[c] - values[d]
+			return diff * diff
+		case RatioLevel:
+			sum := values[c] + values[d]
+			if sum == 0 {
+				return 0
+			}
+			diff := (values[c] - values[d]) / sum
+			return diff * diff
+		}
+		panic("stat: unknown measurement level")
+	}
+	var observed, expected float64
+	for c := 0; c < k; c++ {
+		for d := 0; d < k; d++ {
+			if c == d {
+				continue
+			}
+			dd := delta2(c, d)
+			observed += o[c*k+d] * dd
+			expected += nc[c] * nc[d] * dd
+		}
+	}
+	if expected == 0 {
+		return math.NaN()
+	}
+	return 1 - (n-1)*observed/expected
+}
+
+// FleissKappa returns Fleiss' kappa for the agreement of multiple raters
+// assigning units to categories, where counts has a row for each unit and a
+// column for each category, and element (i, j) is the number of raters who
+// assigned unit i to category j. Every unit must be rated by the same number
+// of raters m.
+//
+// The kappa of category j is
+//  κ_j = 1 - Σ_i n_ij (m - n_ij) / (N m (m-1) p_j (1 - p_j))
+// where N is the number of units and p_j the proportion of all assignments
+// made to category j, and the overall kappa is the mean of the κ_j weighted
+// by p_j (1 - p_j). The returned z is the overall kappa divided by its
+// standard error under the null hypothesis of no agreement beyond chance,
+//  √2 √((Σ_j p_j q_j)² - Σ_j p_j q_j (q_j - p_j)) / (Σ_j p_j q_j √(N m (m-1)))
+// with q_j = 1 - p_j, and p is the two-sided p-value from the standard normal
+// distribution. The results are those of irr::kappam.fleiss in R.
+//
+// Categories never used have NaN kappa and are excluded from the overall
+// value. FleissKappa panics if there are fewer than two raters, if the rows
+// of counts have different sums or if a count is negative.
+func FleissKappa(counts *mat64.Dense) (kappa, z, p float64, perCategory []float64) {
+	n, k := counts.Dims()
+	var m float64
+	for i := 0; i < n; i++ {
+		var s float64
+		for _, v := range counts.RawRowView(i)[:k] {
+			if v < 0 {
+				panic("stat: negative count")
+			}
+			s += v
+		}
+		if i == 0 {
+			m = s
+		} else if s != m {
+			panic("stat: unequal number of raters")
+		}
+	}
+	if m < 2 {
+		panic("stat: fewer than two raters")
+	}
+	total := float64(n) * m
+	pj := make([]float64, k)
+	disagree := make([]float64, k)
+	for i := 0; i < n; i++ {
+		for j, v := range counts.RawRowView(i)[:k] {
+			pj[j] += v
+			disagree[j] += v * (m - v)
+		}
+	}
+	perCategory = make([]float64, k)
+	var sumPQ, sumPQK, sumPQQP float64
+	for j := range pj {
+		pj[j] /= total
+		pq := pj[j] * (1 - pj[j])
+		perCategory[j] = 1 - disagree[j]/(total*(m-1)*pq)
+		if pq == 0 {
+			continue
+		}
+		sumPQ += pq
+		sumPQK += pq * perCategory[j]
+		sumPQQP += pq * (1 - 2*pj[j])
+	}
+	kappa = sumPQK / sumPQ
+	se := math.Sqrt(2/(total*(m-1))) * math.Sqrt(sumPQ*sumPQ-sumPQQP) / sumPQ
+	z = kappa / se
+	p = math.Erfc(math.Abs(z) / math.Sqrt2)
+	return kappa, z, p, perCategory
+}
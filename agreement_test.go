@@ -0,0 +1,195 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestConcordanceCorrelation(t *testing.T) {
+	// Peak expiratory flow rate measured with the Wright and the mini Wright
+	// meters, from Bland and Altman (1986). The values were computed with
+	// the formulas of DescTools::CCC.
+	wright := []float64{494, 395, 516, 434, 476, 557, 413, 442, 650, 433, 417, 656, 267, 478, 178, 423, 427}
+	mini := []float64{512, 430, 520, 428, 500, 600, 364, 380, 658, 445, 432, 626, 260, 477, 259, 350, 451}
+	ccc, ci := ConcordanceCorrelation(wright, mini)
+	if want := 0.9427424314274847; math.Abs(ccc-want) > 1e-14 {
+		t.Errorf("CCC mismatch. Want %v, got %v", want, ccc)
+	}
+	want := [2]float64{0.850491873168561, 0.9787262791701226}
+	if math.Abs(ci[0]-want[0]) > 1e-12 || math.Abs(ci[1]-want[1]) > 1e-12 {
+		t.Errorf("CCC interval mismatch. Want %v, got %v", want, ci)
+	}
+
+	acc, prec, v, u := ConcordanceDecomposition(wright, mini)
+	for _, test := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"accuracy", acc, 0.999430693136343},
+		{"precision", prec, 0.9432794468909462},
+		{"scale shift", v, 1 / 0.9725091213361884},
+		{"location shift", u, -0.019030250091597076},
+		{"product", acc * prec, ccc},
+		{"correlation", prec, Correlation(wright, mini, nil)},
+	} {
+		if math.Abs(test.got-test.want) > 1e-13 {
+			t.Errorf("%s mismatch. Want %v, got %v", test.name, test.want, test.got)
+		}
+	}
+
+	// Perfect agreement, and perfect correlation with a location shift.
+	x := []float64{1, 2, 3, 4, 5}
+	if ccc, _ := ConcordanceCorrelation(x, x); ccc != 1 {
+		t.Errorf("CCC of identical series mismatch. Want 1, got %v", ccc)
+	}
+	y := []float64{2, 3, 4, 5, 6}
+	// s_x² = s_y² = s_xy = 2 and the squared mean difference is 1.
+	if ccc, _ := ConcordanceCorrelation(x, y); math.Abs(ccc-0.8) > 1e-15 {
+		t.Errorf("CCC of shifted series mismatch. Want 0.8, got %v", ccc)
+	}
+
+	if ccc, ci := ConcordanceCorrelation(x, []float64{3, 3, 3, 3, 3}); !math.IsNaN(ccc) || !math.IsNaN(ci[0]) {
+		t.Errorf("CCC of constant series not NaN: %v, %v", ccc, ci)
+	}
+	if !Panics(func() { ConcordanceCorrelation(x, y[1:]) }) {
+		t.Errorf("ConcordanceCorrelation did not panic with length mismatch")
+	}
+}
+
+func TestKrippendorffAlpha(t *testing.T) {
+	// The reliability data of Krippendorff, "Computing Krippendorff's
+	// alpha-reliability" (2011), with four observers rating twelve units,
+	// transposed so that units are rows. The published values are 0.743,
+	// 0.815, 0.849 and 0.797, which irr::kripp.alpha in R also gives.
+	nan := math.NaN()
+	ratings := mat64.NewDense(12, 4, []float64{
+		1, 1, nan, 1,
+		2, 2, 3, 2,
+		3, 3, 3, 3,
+		3, 3, 3, 3,
+		2, 2, 2, 2,
+		1, 2, 3, 4,
+		4, 4, 4, 4,
+		1, 1, 2, 1,
+		2, 2, 2, 2,
+		nan, 5, 5, 5,
+		nan, nan, 1, 1,
+		nan, 3, nan, nan,
+	})
+	for _, test := range []struct {
+		level MeasurementLevel
+		want  float64
+	}{
+		{NominalLevel, 0.743},
+		{OrdinalLevel, 0.815},
+		{IntervalLevel, 0.849},
+		{RatioLevel, 0.797},
+	} {
+		if a := KrippendorffAlpha(ratings, test.level); math.Abs(a-test.want) > 5e-4 {
+			t.Errorf("level %d: alpha mismatch. Want %v, got %v", test.level, test.want, a)
+		}
+	}
+
+	// Units with a single rating do not contribute.
+	single := mat64.NewDense(11, 4, nil)
+	for i := 0; i < 11; i++ {
+		for j := 0; j < 4; j++ {
+			single.Set(i, j, ratings.At(i, j))
+		}
+	}
+	if a, b := KrippendorffAlpha(single, IntervalLevel), KrippendorffAlpha(ratings, IntervalLevel); a != b {
+		t.Errorf("unit with a single rating changed alpha: %v, %v", a, b)
+	}
+
+	perfect := mat64.NewDense(3, 2, []float64{1, 1, 2, 2, 5, 5})
+	if a := KrippendorffAlpha(perfect, NominalLevel); a != 1 {
+		t.Errorf("alpha for perfect agreement mismatch. Want 1, got %v", a)
+	}
+	constant := mat64.NewDense(2, 2, []float64{1, 1, 1, 1})
+	if a := KrippendorffAlpha(constant, IntervalLevel); !math.IsNaN(a) {
+		t.Errorf("alpha without variation not NaN: %v", a)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	a := KrippendorffAlpha(ratings, IntervalLevel)
+	lo, hi := KrippendorffAlphaCI(ratings, IntervalLevel, 0.95, 1000, rnd)
+	if !(lo <= a && a <= hi) {
+		t.Errorf("bootstrap interval [%v, %v] does not contain alpha %v", lo, hi, a)
+	}
+	if !Panics(func() { KrippendorffAlphaCI(ratings, IntervalLevel, 1, 10, nil) }) {
+		t.Errorf("KrippendorffAlphaCI did not panic with confidence level of one")
+	}
+	if !Panics(func() { KrippendorffAlpha(mat64.NewDense(1, 2, []float64{-1, 2}), RatioLevel) }) {
+		t.Errorf("KrippendorffAlpha did not panic with negative ratio value")
+	}
+}
+
+func TestFleissKappa(t *testing.T) {
+	// The worked example of the Wikipedia article on Fleiss' kappa, with
+	// fourteen raters assigning ten units to five categories, for which
+	// κ = 0.210. The per-category values and z were computed with the
+	// formulas of irr::kappam.fleiss.
+	counts := mat64.NewDense(10, 5, []float64{
+		0, 0, 0, 0, 14,
+		0, 2, 6, 4, 2,
+		0, 0, 3, 5, 6,
+		0, 3, 9, 2, 0,
+		2, 2, 8, 1, 1,
+		7, 7, 0, 0, 0,
+		3, 2, 6, 3, 0,
+		2, 5, 3, 2, 2,
+		6, 5, 2, 1, 0,
+		0, 2, 2, 3, 7,
+	})
+	kappa, z, p, per := FleissKappa(counts)
+	if math.Abs(kappa-0.20993070442195522) > 1e-14 {
+		t.Errorf("kappa mismatch. Want 0.20993070442195522, got %v", kappa)
+	}
+	if math.Abs(z-12.374291059190464) > 1e-10 {
+		t.Errorf("z mismatch. Want 12.374291059190464, got %v", z)
+	}
+	if math.Abs(p-3.600594323466763e-35)/3.600594323466763e-35 > 1e-8 {
+		t.Errorf("p-value mismatch. Want 3.600594323466763e-35, got %v", p)
+	}
+	want := []float64{0.20128205128205134, 0.07967032967032961, 0.17159763313609477, 0.030381383322559685, 0.5076566951566952}
+	for j, v := range want {
+		if math.Abs(per[j]-v) > 1e-14 {
+			t.Errorf("category %d kappa mismatch. Want %v, got %v", j, v, per[j])
+		}
+	}
+
+	// Complete agreement.
+	agree := mat64.NewDense(3, 3, []float64{
+		4, 0, 0,
+		0, 4, 0,
+		0, 0, 4,
+	})
+	if kappa, _, _, _ := FleissKappa(agree); math.Abs(kappa-1) > 1e-15 {
+		t.Errorf("kappa for complete agreement mismatch. Want 1, got %v", kappa)
+	}
+
+	// An unused category is excluded.
+	unused := mat64.NewDense(10, 6, nil)
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 5; j++ {
+			unused.Set(i, j, counts.At(i, j))
+		}
+	}
+	if k, _, _, per := FleissKappa(unused); math.Abs(k-kappa) > 1e-15 || !math.IsNaN(per[5]) {
+		t.Errorf("unused category changed kappa: %v, %v", k, per)
+	}
+
+	if !Panics(func() { FleissKappa(mat64.NewDense(2, 2, []float64{1, 2, 2, 2})) }) {
+		t.Errorf("FleissKappa did not panic with unequal numbers of raters")
+	}
+	if !Panics(func() { FleissKappa(mat64.NewDense(2, 2, []float64{1, 0, 0, 1})) }) {
+		t.Errorf("FleissKappa did not panic with a single rater")
+	}
+}
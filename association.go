@@ -0,0 +1,231 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// CramersV returns Cramér's V measure of association between the row and
+// column variables of the contingency table of counts, such as the Counts of
+// a ContingencyTable,
+//  V = √(φ² / min(r-1, c-1))
+// where φ² = χ²/n, χ² is Pearson's chi-square statistic for independence
+// without continuity correction, n is the total count and r and c are the
+// numbers of non-empty rows and columns. Empty rows and columns are ignored.
+//
+// If biasCorrected is true, the bias correction of Bergsma (2013) is applied,
+// replacing φ² by max(0, φ² - (r-1)(c-1)/(n-1)) and r and c by
+// r - (r-1)²/(n-1) and c - (c-1)²/(n-1). The result matches CramerV of the R
+// package DescTools with and without correct = TRUE, except that DescTools
+// returns NaN for a table with empty rows or columns.
+func CramersV(table mat64.Matrix, biasCorrected bool) float64 {
+	chi2, r, c, n := pearsonChiSquare(table)
+	phi2 := chi2 / n
+	kr, kc := float64(r), float64(c)
+	if biasCorrected {
+		phi2 = math.Max(0, phi2-(kr-1)*(kc-1)/(n-1))
+		kr -= (kr - 1) * (kr - 1) / (n - 1)
+		kc -= (kc - 1) * (kc - 1) / (n - 1)
+	}
+	return math.Sqrt(phi2 / math.Min(kr-1, kc-1))
+}
+
+// Phi returns the phi coefficient of the 2×2 contingency table of counts
+//  [a b]
+//  [c d]
+// which is
+//  φ = (ad - bc) / √((a+b)(c+d)(a+c)(b+d))
+// the Pearson correlation of the two binary variables. Its absolute value
+// equals CramersV(table, false). Phi panics if table is not 2×2.
+func Phi(table mat64.Matrix) float64 {
+	r, c := table.Dims()
+	if r != 2 || c != 2 {
+		panic("stat: phi coefficient requires a 2×2 table")
+	}
+	a, b := table.At(0, 0), table.At(0, 1)
+	cc, d := table.At(1, 0), table.At(1, 1)
+	return (a*d - b*cc) / math.Sqrt((a+b)*(cc+d)*(a+cc)*(b+d))
+}
+
+// TheilsU returns Theil's uncertainty coefficient of the categorical variable
+// a given the categorical variable b, with categories coded as in Crosstab,
+//  U(a|b) = (H(a) - H(a|b)) / H(a)
+// where H(a) is the entropy of a and H(a|b) is the conditional entropy of a
+// given b. U(a|b) is the fraction of the uncertainty about a that is removed
+// by knowing b, and lies between 0, when a and b are independent, and 1,
+// when b determines a. The coefficient is not symmetric; TheilsU(b, a) gives
+// the fraction of the uncertainty about b removed by knowing a. If a is
+// constant, TheilsU returns 1.
+func TheilsU(a, b []int) float64 {
+	t := Crosstab(a, b)
+	n := t.Total()
+	p := t.RowTotals(nil)
+	for i := range p {
+		p[i] /= n
+	}
+	ha := Entropy(p)
+	if ha == 0 {
+		return 1
+	}
+	return (ha - conditionalEntropy(t)) / ha
+}
+
+// MutualInformation returns the mutual information between the row and
+// column variables of the joint table of counts or probabilities, such as
+// the Counts of a ContingencyTable,
+//  I(row; col) = Σ_ij p_ij log(p_ij / (p_i· p_·j))
+// in nats, where p_ij are the entries of the table normalized to sum to 1.
+// Empty cells contribute zero. The mutual information is zero if and only if
+// the variables are independent in the table. MutualInformation panics if an
+// entry of the table is negative.
+func MutualInformation(joint mat64.Matrix) float64 {
+	t := NewContingencyTable(joint)
+	rows := t.RowTotals(nil)
+	cols := t.ColTotals(nil)
+	n := t.Total()
+	var mi float64
+	for i, ri := range rows {
+		for j, v := range t.counts.RawRowView(i)[:len(cols)] {
+			if v == 0 {
+				continue
+			}
+			mi += v / n * math.Log(v*n/(ri*cols[j]))
+		}
+	}
+	// The sum is non-negative, but rounding may make it slightly negative
+	// for independent variables.
+	return math.Max(mi, 0)
+}
+
+// MutualInformationLabels returns the mutual information between the
+// categorical variables a and b, with categories coded as in Crosstab, the
+// MutualInformation of their cross-tabulation.
+func MutualInformationLabels(a, b []int) float64 {
+	return MutualInformation(Crosstab(a, b).Counts())
+}
+
+// ConditionalEntropy returns the conditional entropy of the row variable of
+// the joint table of counts or probabilities given the column variable,
+//  H(row|col) = -Σ_ij p_ij log(p_ij / p_·j)
+// in nats, where p_ij are the entries of the table normalized to sum to 1.
+// Empty cells contribute zero. The conditional entropy of the column
+// variable given the row variable is that of the transposed table.
+// ConditionalEntropy panics if an entry of the table is negative.
+func ConditionalEntropy(joint mat64.Matrix) float64 {
+	return conditionalEntropy(NewContingencyTable(joint))
+}
+
+// MINormalization specifies the mean of the marginal entropies by which
+// NormalizedMutualInformation divides the mutual information.
+type MINormalization int
+
+const (
+	// MINormArithmetic uses the arithmetic mean (H(row) + H(col)) / 2,
+	// which gives the V-measure and is the default of
+	// normalized_mutual_info_score in scikit-learn.
+	MINormArithmetic MINormalization = iota
+	// MINormGeometric uses the geometric mean √(H(row) H(col)).
+	MINormGeometric
+	// MINormMin uses the smaller of the entropies.
+	MINormMin
+	// MINormMax uses the larger of the entropies.
+	MINormMax
+)
+
+// NormalizedMutualInformation returns the mutual information between the row
+// and column variables of the joint table of counts or probabilities divided
+// by the mean of their entropies selected by norm. The result lies between
+// 0, for independent variables, and 1, when each variable determines the
+// other, or for MINormMin when either determines the other. As in
+// scikit-learn, it is 1 if both variables are constant and 0 if only one is.
+// NormalizedMutualInformation panics if an entry of the table is negative or
+// if norm is not a valid MINormalization.
+func NormalizedMutualInformation(joint mat64.Matrix, norm MINormalization) float64 {
+	t := NewContingencyTable(joint)
+	n := t.Total()
+	rows := t.RowTotals(nil)
+	cols := t.ColTotals(nil)
+	for i := range rows {
+		rows[i] /= n
+	}
+	for j := range cols {
+		cols[j] /= n
+	}
+	hr, hc := Entropy(rows), Entropy(cols)
+	var h float64
+	switch norm {
+	case MINormArithmetic:
+		h = (hr + hc) / 2
+	case MINormGeometric:
+		h = math.Sqrt(hr * hc)
+	case MINormMin:
+		h = math.Min(hr, hc)
+	case MINormMax:
+		h = math.Max(hr, hc)
+	default:
+		panic("stat: bad mutual information normalization")
+	}
+	if hr == 0 && hc == 0 {
+		return 1
+	}
+	if h == 0 {
+		return 0
+	}
+	return math.Min(MutualInformation(joint)/h, 1)
+}
+
+// conditionalEntropy returns the conditional entropy of the row variable of
+// the table given the column variable,
+//  H(row|col) = -Σ_ij p_ij log(p_ij / p_j)
+// in nats.
+func conditionalEntropy(t *ContingencyTable) float64 {
+	r, c := t.Dims()
+	n := t.Total()
+	cols := t.ColTotals(nil)
+	var h float64
+	for i := 0; i < r; i++ {
+		for j, v := range t.counts.RawRowView(i)[:c] {
+			if v == 0 {
+				continue
+			}
+			h -= v / n * math.Log(v/cols[j])
+		}
+	}
+	return h
+}
+
+// pearsonChiSquare returns Pearson's chi-square statistic for independence
+// of the rows and columns of the table of counts, the numbers of non-empty
+// rows and columns, and the total count. Empty rows and columns do not
+// contribute to the statistic.
+func pearsonChiSquare(table mat64.Matrix) (chi2 float64, r, c int, n float64) {
+	t := NewContingencyTable(table)
+	rows := t.RowTotals(nil)
+	cols := t.ColTotals(nil)
+	n = t.Total()
+	for i, ri := range rows {
+		if ri == 0 {
+			continue
+		}
+		r++
+		for j, cj := range cols {
+			if cj == 0 {
+				continue
+			}
+			e := ri * cj / n
+			d := t.counts.At(i, j) - e
+			chi2 += d * d / e
+		}
+	}
+	for _, cj := range cols {
+		if cj != 0 {
+			c++
+		}
+	}
+	return chi2, r, c, n
+}
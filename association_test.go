@@ -0,0 +1,169 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestCramersV(t *testing.T) {
+	// The values of CramerV(tab) and CramerV(tab, correct = TRUE) as
+	// defined in the source of the R package DescTools, and of its Phi(tab)
+	// for the 2×2 tables, which is |φ|.
+	for _, test := range []struct {
+		r, c      int
+		counts    []float64
+		v, vBias  float64
+		phiSigned float64
+	}{
+		{
+			r: 3, c: 3,
+			counts: []float64{12, 5, 7, 3, 15, 6, 9, 4, 14},
+			v:      0.35311360024451144, vBias: 0.31682017959161624,
+		},
+		{
+			r: 2, c: 2,
+			counts: []float64{10, 6, 4, 12},
+			v:      0.3779644730092272, vBias: 0.3380617018914066,
+			phiSigned: 0.3779644730092272,
+		},
+		{
+			// The correction truncates φ² at zero.
+			r: 2, c: 2,
+			counts: []float64{5, 5, 5, 6},
+			v:      5.0 / 110, vBias: 0,
+			phiSigned: 5.0 / 110,
+		},
+	} {
+		table := mat64.NewDense(test.r, test.c, test.counts)
+		if v := CramersV(table, false); math.Abs(v-test.v) > 1e-14 {
+			t.Errorf("table %v: V mismatch. Want %v, got %v", test.counts, test.v, v)
+		}
+		if v := CramersV(table, true); math.Abs(v-test.vBias) > 1e-14 {
+			t.Errorf("table %v: bias corrected V mismatch. Want %v, got %v", test.counts, test.vBias, v)
+		}
+		if test.r == 2 {
+			phi := Phi(table)
+			if math.Abs(phi-test.phiSigned) > 1e-14 {
+				t.Errorf("table %v: phi mismatch. Want %v, got %v", test.counts, test.phiSigned, phi)
+			}
+			if v := CramersV(table, false); math.Abs(math.Abs(phi)-v) > 1e-14 {
+				t.Errorf("table %v: |phi| %v does not equal V %v", test.counts, phi, v)
+			}
+		}
+	}
+
+	// Empty rows and columns are ignored, so a table of integer codes with
+	// unused categories gives the same result.
+	a := []int{0, 0, 2, 2, 2, 0, 2}
+	b := []int{1, 1, 3, 3, 1, 3, 3}
+	want := CramersV(mat64.NewDense(2, 2, []float64{2, 1, 1, 3}), false)
+	if v := CramersV(Crosstab(a, b).Counts(), false); math.Abs(v-want) > 1e-14 {
+		t.Errorf("V with empty categories mismatch. Want %v, got %v", want, v)
+	}
+
+	if !Panics(func() { Phi(mat64.NewDense(2, 3, nil)) }) {
+		t.Errorf("Phi did not panic with 2×3 table")
+	}
+}
+
+func TestTheilsU(t *testing.T) {
+	// a is determined by b, but b is not determined by a.
+	b := []int{0, 1, 2, 3, 0, 1, 2, 3, 0, 1, 2, 3}
+	a := make([]int, len(b))
+	for i, v := range b {
+		a[i] = v / 2
+	}
+	if u := TheilsU(a, b); math.Abs(u-1) > 1e-14 {
+		t.Errorf("U(a|b) mismatch. Want 1, got %v", u)
+	}
+	// H(b) = log 4 and H(b|a) = log 2.
+	if u := TheilsU(b, a); math.Abs(u-0.5) > 1e-14 {
+		t.Errorf("U(b|a) mismatch. Want 0.5, got %v", u)
+	}
+
+	// Independent variables.
+	x := []int{0, 0, 1, 1, 0, 0, 1, 1}
+	y := []int{0, 1, 0, 1, 2, 2, 2, 2}
+	if u := TheilsU(x, y); math.Abs(u) > 1e-14 {
+		t.Errorf("U for independent variables mismatch. Want 0, got %v", u)
+	}
+
+	if u := TheilsU([]int{1, 1, 1}, []int{0, 1, 2}); u != 1 {
+		t.Errorf("U for constant variable mismatch. Want 1, got %v", u)
+	}
+}
+
+func TestMutualInformation(t *testing.T) {
+	// The cross-tabulation of the labels [0 0 0 1 1 1] and [0 0 1 1 2 2],
+	// whose mutual information is 0.4620981203732969 and arithmetic NMI
+	// is 0.5158037429793889 in scikit-learn.
+	a := []int{0, 0, 0, 1, 1, 1}
+	b := []int{0, 0, 1, 1, 2, 2}
+	joint := mat64.NewDense(2, 3, []float64{2, 1, 0, 0, 1, 2})
+	const mi = 0.4620981203732969
+	if got := MutualInformation(joint); math.Abs(got-mi) > 1e-15 {
+		t.Errorf("mutual information mismatch. Want %v, got %v", mi, got)
+	}
+	if got := MutualInformationLabels(a, b); math.Abs(got-mi) > 1e-15 {
+		t.Errorf("mutual information of labels mismatch. Want %v, got %v", mi, got)
+	}
+	// Probabilities give the same result as counts.
+	probs := mat64.NewDense(2, 3, []float64{2.0 / 6, 1.0 / 6, 0, 0, 1.0 / 6, 2.0 / 6})
+	if got := MutualInformation(probs); math.Abs(got-mi) > 1e-15 {
+		t.Errorf("mutual information of probabilities mismatch. Want %v, got %v", mi, got)
+	}
+
+	// I(row; col) = H(row) - H(row|col).
+	if got, want := ConditionalEntropy(joint), math.Ln2-mi; math.Abs(got-want) > 1e-15 {
+		t.Errorf("conditional entropy mismatch. Want %v, got %v", want, got)
+	}
+	// The entropy of the uniform column variable is log(3).
+	transpose := mat64.NewDense(3, 2, []float64{2, 0, 1, 1, 0, 2})
+	if got, want := ConditionalEntropy(transpose), math.Log(3)-mi; math.Abs(got-want) > 1e-15 {
+		t.Errorf("conditional entropy of the transpose mismatch. Want %v, got %v", want, got)
+	}
+
+	for _, test := range []struct {
+		norm MINormalization
+		want float64
+	}{
+		{MINormArithmetic, 0.5158037429793888},
+		{MINormGeometric, 0.5295405780575617},
+		{MINormMin, 2.0 / 3},
+		{MINormMax, 0.420619835714305},
+	} {
+		if got := NormalizedMutualInformation(joint, test.norm); math.Abs(got-test.want) > 1e-14 {
+			t.Errorf("normalization %d: NMI mismatch. Want %v, got %v", test.norm, test.want, got)
+		}
+	}
+
+	// Independent variables.
+	indep := mat64.NewDense(2, 2, []float64{3, 6, 1, 2})
+	if got := MutualInformation(indep); got != 0 {
+		t.Errorf("mutual information of independent variables: want 0, got %v", got)
+	}
+	// Identical labelings and constant labelings.
+	same := mat64.NewDense(3, 3, []float64{2, 0, 0, 0, 3, 0, 0, 0, 1})
+	if got := NormalizedMutualInformation(same, MINormGeometric); math.Abs(got-1) > 1e-15 {
+		t.Errorf("NMI of identical labels: want 1, got %v", got)
+	}
+	if got := NormalizedMutualInformation(mat64.NewDense(1, 1, []float64{4}), MINormArithmetic); got != 1 {
+		t.Errorf("NMI of constant labels: want 1, got %v", got)
+	}
+	if got := NormalizedMutualInformation(mat64.NewDense(1, 2, []float64{2, 2}), MINormMin); got != 0 {
+		t.Errorf("NMI with one constant labeling: want 0, got %v", got)
+	}
+
+	if !Panics(func() { NormalizedMutualInformation(joint, MINormalization(-1)) }) {
+		t.Errorf("NormalizedMutualInformation did not panic with bad normalization")
+	}
+	if !Panics(func() { MutualInformation(mat64.NewDense(1, 2, []float64{1, -1})) }) {
+		t.Errorf("MutualInformation did not panic with negative count")
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gonum/floats"
+)
+
+// The bin selection functions below return the dividers of equal-width bins
+// spanning the range of the data, for use with Histogram, following the rules
+// of numpy.histogram_bin_edges. For n samples with range [min, max], the
+// number of bins is ⌈(max - min) / h⌉ for the bin width h of the rule, and
+// at least one. Since Histogram counts a value v in the bin
+// dividers[j] <= v < dividers[j+1], the last divider is the next float64
+// after max, so that max falls in the last bin. If all of the values are
+// equal, the single bin [min - 0.5, max + 0.5) is returned. x is not
+// modified, need not be sorted, and must not contain NaN. All of the
+// functions panic if x is empty.
+
+// SturgesBins returns the dividers of ⌈log₂(n)⌉ + 1 bins of the values of x,
+// by Sturges' rule. The rule assumes approximately normal data and gives too
+// few bins for large samples.
+func SturgesBins(x []float64) []float64 {
+	min, max := binRange(x)
+	return binDividers(min, max, sturgesWidth(min, max, len(x)))
+}
+
+// ScottBins returns the dividers of the bins of the values of x of width
+//  h = (24 √π / n)^(1/3) σ ≈ 3.49 σ n^(-1/3)
+// by Scott's rule, where σ is the population standard deviation of x. The
+// width minimizes the mean integrated squared error of the histogram as an
+// estimate of a normal density.
+func ScottBins(x []float64) []float64 {
+	min, max := binRange(x)
+	n := float64(len(x))
+	_, v := MeanVariance(x, nil)
+	sigma := math.Sqrt(v * (n - 1) / n)
+	h := math.Cbrt(24*math.Sqrt(math.Pi)/n) * sigma
+	return binDividers(min, max, h)
+}
+
+// FreedmanDiaconisBins returns the dividers of the bins of the values of x
+// of width
+//  h = 2 IQR n^(-1/3)
+// by the rule of Freedman and Diaconis, where IQR is the interquartile range
+// of x computed with the Empirical kind. Using the IQR rather than the
+// standard deviation makes the rule robust to outliers.
+func FreedmanDiaconisBins(x []float64) []float64 {
+	min, max := binRange(x)
+	return binDividers(min, max, freedmanDiaconisWidth(x))
+}
+
+// AutoBins returns the dividers of the bins of the values of x of the
+// smaller of the widths of the Sturges and Freedman–Diaconis rules, as the
+// "auto" estimator of numpy. The Freedman–Diaconis rule is used for large
+// samples, and Sturges' rule for small ones or when the IQR is zero.
+func AutoBins(x []float64) []float64 {
+	min, max := binRange(x)
+	h := sturgesWidth(min, max, len(x))
+	if fd := freedmanDiaconisWidth(x); fd > 0 {
+		h = math.Min(h, fd)
+	}
+	return binDividers(min, max, h)
+}
+
+// binRange returns the minimum and maximum of x, and panics if x is empty.
+func binRange(x []float64) (min, max float64) {
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	return floats.Min(x), floats.Max(x)
+}
+
+// sturgesWidth returns the bin width of Sturges' rule for n values spanning
+// [min, max].
+func sturgesWidth(min, max float64, n int) float64 {
+	return (max - min) / (math.Log2(float64(n)) + 1)
+}
+
+// freedmanDiaconisWidth returns the bin width of the Freedman–Diaconis rule
+// for the values of x.
+func freedmanDiaconisWidth(x []float64) float64 {
+	return 2 * IQR(x, Empirical) / math.Cbrt(float64(len(x)))
+}
+
+// binDividers returns the dividers of the equal-width bins of width near h
+// spanning [min, max], as described above.
+func binDividers(min, max, h float64) []float64 {
+	if min == max {
+		return []float64{min - 0.5, max + 0.5}
+	}
+	n := 1
+	if h > 0 {
+		// Allow for the rounding of widths computed from the range, which
+		// would otherwise add a bin.
+		n = int(math.Max(1, math.Ceil((max-min)/h*(1-1e-12))))
+	}
+	dividers := floats.Span(make([]float64, n+1), min, max)
+	dividers[n] = math.Nextafter(max, math.Inf(1))
+	return dividers
+}
+
+// EqualFrequencyDividers returns the dividers of n bins of the values of x
+// holding approximately equal numbers of values, for use with Histogram.
+// For N values in sorted order, dividers[i] is the value of (zero-based)
+// rank ⌊iN/n⌋, so without ties bin i holds ⌊(i+1)N/n⌋ - ⌊iN/n⌋ values, and
+// the last divider is the next float64 after the maximum, as for the rules
+// above. Tied values cannot be split between bins, so repeated dividers are
+// collapsed into one, keeping the dividers strictly increasing, and the
+// bin of a collapsed divider holds all of the values tied with it. There
+// are then fewer than n bins. If all of the values are equal, the single bin
+// [min - 0.5, max + 0.5) is returned.
+//
+// x is not modified and must not contain NaN. EqualFrequencyDividers panics
+// if x is empty or if n is not positive.
+func EqualFrequencyDividers(x []float64, n int) []float64 {
+	min, max := binRange(x)
+	if n < 1 {
+		panic("stat: non-positive number of bins")
+	}
+	if min == max {
+		return []float64{min - 0.5, max + 0.5}
+	}
+	sorted := make([]float64, len(x))
+	copy(sorted, x)
+	sort.Float64s(sorted)
+	dividers := make([]float64, 0, n+1)
+	for i := 0; i < n; i++ {
+		v := sorted[i*len(x)/n]
+		if len(dividers) == 0 || v > dividers[len(dividers)-1] {
+			dividers = append(dividers, v)
+		}
+	}
+	return append(dividers, math.Nextafter(max, math.Inf(1)))
+}
+
+// LogDividers returns the dividers of n bins of the values of x of equal
+// width on a logarithmic scale, spanning [min, max] with the last divider
+// the next float64 after max, as for the rules above. If all of the values
+// are equal, the single bin [min/2, 2 max) is returned.
+//
+// x is not modified and must not contain NaN. LogDividers panics if x is
+// empty, if a value is not positive or if n is not positive.
+func LogDividers(x []float64, n int) []float64 {
+	min, max := binRange(x)
+	if n < 1 {
+		panic("stat: non-positive number of bins")
+	}
+	if !(min > 0) {
+		panic("stat: non-positive value for logarithmic bins")
+	}
+	if min == max {
+		return []float64{min / 2, 2 * max}
+	}
+	dividers := floats.LogSpan(make([]float64, n+1), min, max)
+	// Avoid the rounding of the logarithms at the ends.
+	dividers[0] = min
+	dividers[n] = math.Nextafter(max, math.Inf(1))
+	return dividers
+}
@@ -0,0 +1,183 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestBins(t *testing.T) {
+	x := []float64{1, 2, 2, 3, 3, 3, 4, 4, 5, 9, 10, 2.5, 3.5}
+	outlier := []float64{1, 1, 1, 1, 1, 1, 1, 5}
+	powerOfTwo := []float64{0, 1, 2, 3, 4, 5, 6, 7}
+	for _, test := range []struct {
+		name string
+		fn   func([]float64) []float64
+		x    []float64
+		bins int
+	}{
+		{"Sturges", SturgesBins, x, 5},
+		{"Scott", ScottBins, x, 3},
+		{"Freedman–Diaconis", FreedmanDiaconisBins, x, 8},
+		{"auto", AutoBins, x, 8},
+
+		// The IQR is zero, so the Freedman–Diaconis rule gives a
+		// single bin and the automatic choice falls back to Sturges.
+		{"Freedman–Diaconis", FreedmanDiaconisBins, outlier, 1},
+		{"auto", AutoBins, outlier, 4},
+
+		// log₂(8) + 1 = 4 bins, without an extra bin from rounding.
+		{"Sturges", SturgesBins, powerOfTwo, 4},
+	} {
+		orig := append([]float64(nil), test.x...)
+		dividers := test.fn(test.x)
+		if !floats.Equal(orig, test.x) {
+			t.Errorf("%s rule modified its input", test.name)
+		}
+		if len(dividers) != test.bins+1 {
+			t.Errorf("%s rule for %v: want %d bins, got %d", test.name, test.x, test.bins, len(dividers)-1)
+			continue
+		}
+		min, max := floats.Min(test.x), floats.Max(test.x)
+		if dividers[0] != min || dividers[test.bins] != math.Nextafter(max, math.Inf(1)) {
+			t.Errorf("%s rule for %v: dividers %v do not span the data", test.name, test.x, dividers)
+		}
+		for i := 1; i < test.bins; i++ {
+			want := min + float64(i)*(max-min)/float64(test.bins)
+			if math.Abs(dividers[i]-want) > 1e-14*(max-min) {
+				t.Errorf("%s rule for %v: unequal bins %v", test.name, test.x, dividers)
+				break
+			}
+		}
+
+		// The dividers can be passed to Histogram, and every value is
+		// counted.
+		sorted := append([]float64(nil), test.x...)
+		sort.Float64s(sorted)
+		count := Histogram(nil, dividers, sorted, nil)
+		if floats.Sum(count) != float64(len(test.x)) {
+			t.Errorf("%s rule for %v: histogram %v does not count all values", test.name, test.x, count)
+		}
+	}
+
+	// Constant data give a single bin of unit width.
+	for _, fn := range []func([]float64) []float64{SturgesBins, ScottBins, FreedmanDiaconisBins, AutoBins} {
+		dividers := fn([]float64{3, 3, 3})
+		if !floats.Equal(dividers, []float64{2.5, 3.5}) {
+			t.Errorf("unexpected dividers for constant data: %v", dividers)
+		}
+		if dividers := fn([]float64{7}); !floats.Equal(dividers, []float64{6.5, 7.5}) {
+			t.Errorf("unexpected dividers for a single value: %v", dividers)
+		}
+		if !Panics(func() { fn(nil) }) {
+			t.Errorf("expected panic for empty data")
+		}
+	}
+}
+
+func TestEqualFrequencyDividers(t *testing.T) {
+	for _, test := range []struct {
+		x        []float64
+		n        int
+		dividers []float64
+		counts   []float64
+	}{
+		{
+			x:        []float64{3, 9, 1, 10, 5, 2, 8, 4, 7, 6},
+			n:        2,
+			dividers: []float64{1, 6, math.Nextafter(10, 11)},
+			counts:   []float64{5, 5},
+		},
+		{
+			x:        []float64{3, 9, 1, 10, 5, 2, 8, 4, 7, 6},
+			n:        3,
+			dividers: []float64{1, 4, 7, math.Nextafter(10, 11)},
+			counts:   []float64{3, 3, 4},
+		},
+		{
+			// The tied values at 1 collapse the first three dividers.
+			x:        []float64{1, 1, 1, 1, 1, 1, 2, 3, 4, 5},
+			n:        5,
+			dividers: []float64{1, 2, 4, math.Nextafter(5, 6)},
+			counts:   []float64{6, 2, 2},
+		},
+		{
+			x:        []float64{2, 2},
+			n:        4,
+			dividers: []float64{1.5, 2.5},
+			counts:   []float64{2},
+		},
+	} {
+		orig := append([]float64(nil), test.x...)
+		dividers := EqualFrequencyDividers(test.x, test.n)
+		if !floats.Equal(orig, test.x) {
+			t.Errorf("EqualFrequencyDividers modified its input")
+		}
+		if !floats.Equal(dividers, test.dividers) {
+			t.Errorf("dividers mismatch for %v and %d bins. Want %v, got %v", test.x, test.n, test.dividers, dividers)
+			continue
+		}
+		sorted := append([]float64(nil), test.x...)
+		sort.Float64s(sorted)
+		if counts := Histogram(nil, dividers, sorted, nil); !floats.Equal(counts, test.counts) {
+			t.Errorf("counts mismatch for %v and %d bins. Want %v, got %v", test.x, test.n, test.counts, counts)
+		}
+	}
+
+	// Skewed data fill the bins evenly.
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = math.Exp(3 * rnd.NormFloat64())
+	}
+	sort.Float64s(x)
+	for _, c := range Histogram(nil, EqualFrequencyDividers(x, 8), x, nil) {
+		if c != 125 {
+			t.Errorf("unequal bin count %v for skewed data", c)
+		}
+	}
+
+	if !Panics(func() { EqualFrequencyDividers(nil, 2) }) {
+		t.Errorf("EqualFrequencyDividers did not panic with empty data")
+	}
+	if !Panics(func() { EqualFrequencyDividers([]float64{1, 2}, 0) }) {
+		t.Errorf("EqualFrequencyDividers did not panic with zero bins")
+	}
+}
+
+func TestLogDividers(t *testing.T) {
+	x := []float64{100, 1, 1000, 10, 3}
+	dividers := LogDividers(x, 3)
+	if len(dividers) != 4 || dividers[0] != 1 || dividers[3] != math.Nextafter(1000, 1001) {
+		t.Fatalf("unexpected dividers %v", dividers)
+	}
+	if !floats.EqualApprox(dividers[1:3], []float64{10, 100}, 1e-12) {
+		t.Errorf("interior dividers mismatch. Want [10 100], got %v", dividers[1:3])
+	}
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+	if counts := Histogram(nil, dividers, sorted, nil); floats.Sum(counts) != 5 {
+		t.Errorf("histogram %v does not count all values", counts)
+	}
+	if d := LogDividers([]float64{4, 4}, 3); !floats.Equal(d, []float64{2, 8}) {
+		t.Errorf("unexpected dividers for constant data: %v", d)
+	}
+
+	for i, fn := range []func(){
+		func() { LogDividers(nil, 2) },
+		func() { LogDividers([]float64{0, 1}, 2) },
+		func() { LogDividers([]float64{-1, 1}, 2) },
+		func() { LogDividers([]float64{1, 2}, 0) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
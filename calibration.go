@@ -0,0 +1,111 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "sort"
+
+// BrierScore returns the Brier score of the predicted probabilities probs of
+// the binary outcomes, the weighted mean squared difference between the
+// probabilities and the outcomes coded as 1 for true and 0 for false,
+//  BS = \sum_i w_i (p_i - o_i)^2 / \sum_i w_i
+// Lower scores are better; predicting 0.5 for every outcome scores 0.25.
+//
+// If weights is nil then all of the weights are 1. If weights is not nil,
+// then len(probs) must equal len(weights). BrierScore panics if the lengths
+// of probs and outcomes differ or if a probability is outside [0, 1].
+func BrierScore(probs []float64, outcomes []bool, weights []float64) float64 {
+	if len(outcomes) != len(probs) {
+		panic(ErrLengthMismatch{Got: len(outcomes), Want: len(probs)})
+	}
+	checkWeightLength(probs, weights)
+	var sum, sumWeights float64
+	for i, p := range probs {
+		checkProbability(p)
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		d := p
+		if outcomes[i] {
+			d = 1 - p
+		}
+		sum += w * d * d
+		sumWeights += w
+	}
+	return sum / sumWeights
+}
+
+// CalibrationCurve returns the calibration, or reliability, curve of the
+// predicted probabilities probs of the binary outcomes. The probabilities
+// are grouped into nBins bins, and for each bin predicted holds the mean
+// probability, observed the fraction of true outcomes and counts the number
+// of observations. A well calibrated prediction has observed close to
+// predicted in each bin.
+//
+// If quantile is false the bins are of equal width on [0, 1], otherwise
+// their edges are the empirical quantiles of probs at 1/nBins, 2/nBins, ...,
+// so that the bins hold approximately equal numbers of observations. As in
+// scikit-learn, each bin includes its upper edge and the first bin also its
+// lower edge. Empty bins, including those of equal quantile edges, are
+// omitted, so the curves may have fewer than nBins points, in increasing
+// order of the bins.
+//
+// CalibrationCurve panics if the lengths of probs and outcomes differ, if
+// nBins is not positive or if a probability is outside [0, 1].
+func CalibrationCurve(probs []float64, outcomes []bool, nBins int, quantile bool) (predicted, observed []float64, counts []int) {
+	if len(outcomes) != len(probs) {
+		panic(ErrLengthMismatch{Got: len(outcomes), Want: len(probs)})
+	}
+	if nBins <= 0 {
+		panic("stat: non-positive number of bins")
+	}
+	for _, p := range probs {
+		checkProbability(p)
+	}
+
+	// edges holds the interior edges of the bins.
+	edges := make([]float64, nBins-1)
+	if quantile && len(probs) > 0 {
+		sorted := make([]float64, len(probs))
+		copy(sorted, probs)
+		sort.Float64s(sorted)
+		for i := range edges {
+			edges[i] = Quantile(float64(i+1)/float64(nBins), Empirical, sorted, nil)
+		}
+	} else {
+		for i := range edges {
+			edges[i] = float64(i+1) / float64(nBins)
+		}
+	}
+
+	sums := make([]float64, nBins)
+	trues := make([]float64, nBins)
+	n := make([]int, nBins)
+	for i, p := range probs {
+		// The bin of p is the number of edges less than p.
+		b := sort.SearchFloat64s(edges, p)
+		sums[b] += p
+		if outcomes[i] {
+			trues[b]++
+		}
+		n[b]++
+	}
+	for b, c := range n {
+		if c == 0 {
+			continue
+		}
+		predicted = append(predicted, sums[b]/float64(c))
+		observed = append(observed, trues[b]/float64(c))
+		counts = append(counts, c)
+	}
+	return predicted, observed, counts
+}
+
+// checkProbability panics if p is not a probability.
+func checkProbability(p float64) {
+	if !(p >= 0 && p <= 1) {
+		panic("stat: probability out of range")
+	}
+}
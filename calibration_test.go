@@ -0,0 +1,154 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestBrierScore(t *testing.T) {
+	for i, test := range []struct {
+		probs    []float64
+		outcomes []bool
+		weights  []float64
+		want     float64
+	}{
+		{
+			// The example of brier_score_loss in scikit-learn.
+			probs:    []float64{0.1, 0.9, 0.8, 0.3},
+			outcomes: []bool{false, true, true, false},
+			want:     0.0375,
+		},
+		{
+			probs:    []float64{0.5, 0.5, 0.5},
+			outcomes: []bool{true, false, false},
+			want:     0.25,
+		},
+		{
+			probs:    []float64{1, 0, 1},
+			outcomes: []bool{true, false, false},
+			want:     1.0 / 3,
+		},
+		{
+			// A weight of 2 counts as two copies of an observation.
+			probs:    []float64{0.2, 0.6},
+			outcomes: []bool{true, true},
+			weights:  []float64{2, 1},
+			want:     (2*0.64 + 0.16) / 3,
+		},
+	} {
+		got := BrierScore(test.probs, test.outcomes, test.weights)
+		if math.Abs(got-test.want) > 1e-15 {
+			t.Errorf("case %d: Brier score mismatch. Want %v, got %v", i, test.want, got)
+		}
+	}
+	for i, fn := range []func(){
+		func() { BrierScore([]float64{0.5}, []bool{true, false}, nil) },
+		func() { BrierScore([]float64{0.5}, []bool{true}, []float64{1, 1}) },
+		func() { BrierScore([]float64{1.5}, []bool{true}, nil) },
+		func() { BrierScore([]float64{math.NaN()}, []bool{true}, nil) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestCalibrationCurve(t *testing.T) {
+	for i, test := range []struct {
+		probs    []float64
+		outcomes []bool
+		nBins    int
+		quantile bool
+
+		predicted, observed []float64
+		counts              []int
+	}{
+		{
+			// The third of four bins is empty and omitted.
+			probs:    []float64{0, 0.1, 0.3, 0.35, 0.9, 1},
+			outcomes: []bool{false, false, true, false, true, true},
+			nBins:    4,
+
+			predicted: []float64{0.05, 0.325, 0.95},
+			observed:  []float64{0, 0.5, 1},
+			counts:    []int{2, 2, 2},
+		},
+		{
+			// The upper edge of a bin belongs to it.
+			probs:    []float64{0.5, 0.5, 0.75, 0.25},
+			outcomes: []bool{true, false, true, false},
+			nBins:    2,
+
+			predicted: []float64{5.0 / 12, 0.75},
+			observed:  []float64{1.0 / 3, 1},
+			counts:    []int{3, 1},
+		},
+		{
+			probs:    []float64{0, 0.1, 0.3, 0.35, 0.9, 1},
+			outcomes: []bool{false, false, true, false, true, true},
+			nBins:    3,
+			quantile: true,
+
+			predicted: []float64{0.05, 0.325, 0.95},
+			observed:  []float64{0, 0.5, 1},
+			counts:    []int{2, 2, 2},
+		},
+		{
+			// Tied probabilities give equal quantile edges and an
+			// empty bin.
+			probs:    []float64{0.5, 0.9, 0.5, 0.5, 0.9, 0.5},
+			outcomes: []bool{true, true, false, false, true, true},
+			nBins:    3,
+			quantile: true,
+
+			predicted: []float64{0.5, 0.9},
+			observed:  []float64{0.5, 1},
+			counts:    []int{4, 2},
+		},
+		{
+			probs:    []float64{0.2, 0.7},
+			outcomes: []bool{false, true},
+			nBins:    1,
+
+			predicted: []float64{0.45},
+			observed:  []float64{0.5},
+			counts:    []int{2},
+		},
+	} {
+		predicted, observed, counts := CalibrationCurve(test.probs, test.outcomes, test.nBins, test.quantile)
+		if !floats.EqualApprox(predicted, test.predicted, 1e-15) {
+			t.Errorf("case %d: predicted mismatch. Want %v, got %v", i, test.predicted, predicted)
+		}
+		if !floats.EqualApprox(observed, test.observed, 1e-15) {
+			t.Errorf("case %d: observed mismatch. Want %v, got %v", i, test.observed, observed)
+		}
+		if !reflect.DeepEqual(counts, test.counts) {
+			t.Errorf("case %d: counts mismatch. Want %v, got %v", i, test.counts, counts)
+		}
+	}
+
+	// Bins of equal width at multiples of 0.1 must not be misplaced by
+	// rounding of the edges.
+	probs := []float64{0.1, 0.2, 0.3, 0.7}
+	_, _, counts := CalibrationCurve(probs, make([]bool, len(probs)), 10, false)
+	if !reflect.DeepEqual(counts, []int{1, 1, 1, 1}) {
+		t.Errorf("unexpected counts for probabilities on the bin edges: %v", counts)
+	}
+
+	for i, fn := range []func(){
+		func() { CalibrationCurve([]float64{0.5}, []bool{true, false}, 2, false) },
+		func() { CalibrationCurve([]float64{0.5}, []bool{true}, 0, false) },
+		func() { CalibrationCurve([]float64{-0.1}, []bool{true}, 2, true) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ChangePointsMeanShift detects multiple shifts in the mean of the series x
+// by binary segmentation with the L2 cost, using a minimum segment length
+// of two. See ChangePointsMeanShiftMinSize for details.
+func ChangePointsMeanShift(x []float64, penalty float64) []int {
+	return ChangePointsMeanShiftMinSize(x, penalty, 2)
+}
+
+// ChangePointsMeanShiftMinSize detects multiple shifts in the mean of the
+// series x by binary segmentation with the L2 cost. The returned change
+// points are sorted and each is the index of the first element of a new
+// segment.
+//
+// Starting from the whole series, the split of a segment that most reduces
+// the within-segment sum of squared deviations from the segment means is
+// found. For a split of a segment of length n into lengths n₁ and n₂ the
+// reduction is
+//  n₁ n₂ / n (mean₁ - mean₂)²
+// which is the square of the CUSUM statistic at the split. The split is
+// accepted if the reduction exceeds penalty, and the two halves are then
+// segmented recursively. No segment shorter than minSize is created.
+//
+// The penalty is on the scale of the squared data. A common choice is
+// 2 σ² log(n), where σ² is the noise variance and n = len(x); larger values
+// detect fewer change points.
+func ChangePointsMeanShiftMinSize(x []float64, penalty float64, minSize int) []int {
+	if minSize < 1 {
+		panic("stat: non-positive minimum segment size")
+	}
+	if penalty < 0 {
+		panic("stat: negative penalty")
+	}
+	// Prefix sums for computing segment means in constant time. The values
+	// are centered first to reduce cancellation.
+	mean := Mean(x, nil)
+	cum := make([]float64, len(x)+1)
+	for i, v := range x {
+		cum[i+1] = cum[i] + (v - mean)
+	}
+	var cps []int
+	var segment func(lo, hi int)
+	segment = func(lo, hi int) {
+		n := hi - lo
+		if n < 2*minSize {
+			return
+		}
+		best := -1.0
+		split := -1
+		for tau := lo + minSize; tau <= hi-minSize; tau++ {
+			n1 := float64(tau - lo)
+			n2 := float64(hi - tau)
+			d := (cum[tau]-cum[lo])/n1 - (cum[hi]-cum[tau])/n2
+			gain := n1 * n2 / float64(n) * d * d
+			if gain > best {
+				best = gain
+				split = tau
+			}
+		}
+		if best <= penalty {
+			return
+		}
+		cps = append(cps, split)
+		segment(lo, split)
+		segment(split, hi)
+	}
+	segment(0, len(x))
+	sort.Ints(cps)
+	return cps
+}
+
+// CUSUMTest tests the series x for a single shift in mean. The statistic is
+// the maximum of the standardized CUSUM process,
+//  max_k |S_k - k/n S_n| / (σ √n)
+// where S_k is the sum of the first k elements and σ the sample standard
+// deviation of x, and loc is the index of the first element after the
+// maximizing split, the estimated change point.
+//
+// The p-value is computed by comparing the statistic with its value on
+// nPerm random permutations of x, which share the null distribution under
+// exchangeability, as
+//  (1 + #{permuted ≥ observed}) / (1 + nPerm)
+// If src is nil the global random source is used. x is not modified.
+func CUSUMTest(x []float64, nPerm int, src *rand.Rand) (stat float64, loc int, p float64) {
+	if len(x) < 2 {
+		panic("stat: too few samples for CUSUM test")
+	}
+	if nPerm < 0 {
+		panic("stat: negative permutation count")
+	}
+	obs, loc := cusumMax(x)
+	perm := make([]float64, len(x))
+	copy(perm, x)
+	exceed := 1
+	for i := 0; i < nPerm; i++ {
+		shuffleFloats(perm, src)
+		if s, _ := cusumMax(perm); s >= obs {
+			exceed++
+		}
+	}
+	stat = obs / (StdDev(x, nil) * math.Sqrt(float64(len(x))))
+	return stat, loc, float64(exceed) / float64(nPerm+1)
+}
+
+// cusumMax returns the maximum absolute centered partial sum of x and the
+// number of elements in the maximizing partial sum.
+func cusumMax(x []float64) (max float64, loc int) {
+	mean := Mean(x, nil)
+	var s float64
+	for k, v := range x[:len(x)-1] {
+		s += v - mean
+		if a := math.Abs(s); a > max {
+			max = a
+			loc = k + 1
+		}
+	}
+	return max, loc
+}
+
+// shuffleFloats randomly permutes x in place using the Fisher–Yates
+// algorithm. If src is nil the global random source is used.
+func shuffleFloats(x []float64, src *rand.Rand) {
+	intn := rand.Intn
+	if src != nil {
+		intn = src.Intn
+	}
+	for i := len(x) - 1; i > 0; i-- {
+		j := intn(i + 1)
+		x[i], x[j] = x[j], x[i]
+	}
+}
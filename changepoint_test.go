@@ -0,0 +1,119 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestChangePointsMeanShift(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, test := range []struct {
+		levels []float64
+		bounds []int // Start of each segment after the first.
+		n      int
+	}{
+		{[]float64{0, 3}, []int{150}, 300},
+		{[]float64{0, 2, -1}, []int{100, 220}, 300},
+		{[]float64{5, 6.5, 5, 8}, []int{80, 200, 260}, 400},
+		{[]float64{0, 1.5, 0, 1.5, 0}, []int{100, 200, 300, 400}, 500},
+	} {
+		x := make([]float64, test.n)
+		seg := 0
+		for i := range x {
+			if seg < len(test.bounds) && i == test.bounds[seg] {
+				seg++
+			}
+			x[i] = test.levels[seg] + rnd.NormFloat64()
+		}
+		penalty := 3 * math.Log(float64(test.n))
+		got := ChangePointsMeanShift(x, penalty)
+		if len(got) != len(test.bounds) {
+			t.Errorf("levels %v: wrong number of change points. Want %v, got %v", test.levels, test.bounds, got)
+			continue
+		}
+		for i, b := range test.bounds {
+			if d := got[i] - b; d < -2 || d > 2 {
+				t.Errorf("levels %v: change point mislocated. Want %v, got %v", test.levels, test.bounds, got)
+			}
+		}
+	}
+
+	noise := make([]float64, 1000)
+	for i := range noise {
+		noise[i] = rnd.NormFloat64()
+	}
+	if got := ChangePointsMeanShift(noise, 3*math.Log(1000)); len(got) != 0 {
+		t.Errorf("change points found in homogeneous noise: %v", got)
+	}
+
+	// A single outlier splits off a short segment unless the minimum size
+	// forbids it.
+	x := []float64{0, 0, 0, 0, 0, 0, 10, 0, 0, 0, 0, 0}
+	if got := ChangePointsMeanShiftMinSize(x, 1, 1); len(got) != 2 || got[0] != 6 || got[1] != 7 {
+		t.Errorf("expected change points [6 7], got %v", got)
+	}
+	for _, cp := range ChangePointsMeanShiftMinSize(x, 1, 3) {
+		if cp < 3 || cp > len(x)-3 {
+			t.Errorf("change point %v violates the minimum segment size", cp)
+		}
+	}
+
+	if !Panics(func() { ChangePointsMeanShiftMinSize(x, 1, 0) }) {
+		t.Errorf("ChangePointsMeanShiftMinSize did not panic with zero minimum size")
+	}
+	if !Panics(func() { ChangePointsMeanShift(x, -1) }) {
+		t.Errorf("ChangePointsMeanShift did not panic with negative penalty")
+	}
+}
+
+func TestCUSUMTest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float64, 200)
+	for i := range x {
+		x[i] = rnd.NormFloat64()
+		if i >= 120 {
+			x[i] += 1
+		}
+	}
+	orig := make([]float64, len(x))
+	copy(orig, x)
+	stat, loc, p := CUSUMTest(x, 999, rand.New(rand.NewSource(2)))
+	if p > 0.01 {
+		t.Errorf("shift not detected, p = %v", p)
+	}
+	if loc < 115 || loc > 125 {
+		t.Errorf("change point mislocated at %v", loc)
+	}
+	if stat <= 0 {
+		t.Errorf("non-positive statistic %v", stat)
+	}
+	for i := range x {
+		if x[i] != orig[i] {
+			t.Fatalf("input modified by CUSUMTest")
+		}
+	}
+
+	noise := make([]float64, 200)
+	for i := range noise {
+		noise[i] = rnd.NormFloat64()
+	}
+	if _, _, p := CUSUMTest(noise, 999, rand.New(rand.NewSource(3))); p < 0.05 {
+		t.Errorf("shift detected in homogeneous noise, p = %v", p)
+	}
+	if _, _, p := CUSUMTest(noise, 0, nil); p != 1 {
+		t.Errorf("expected unit p-value without permutations, got %v", p)
+	}
+
+	// The statistic is the maximum of the standardized CUSUM process.
+	y := []float64{1, 2, 6, 7}
+	stat, loc, _ = CUSUMTest(y, 0, nil)
+	want := 5 / (StdDev(y, nil) * 2)
+	if math.Abs(stat-want) > 1e-14 || loc != 2 {
+		t.Errorf("CUSUM statistic mismatch. Want %v at 2, got %v at %v", want, stat, loc)
+	}
+}
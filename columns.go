@@ -0,0 +1,481 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// The column statistics below traverse the matrix in row-major order,
+// accumulating all of the columns at once, which matches the layout of a
+// mat64.Dense and avoids copying its columns. The rows are split into chunks
+// of columnChunkRows whose partial results are combined in order, which may
+// run in parallel as configured by Parallel.
+
+// columnChunkRows is the number of rows in the chunks of the column
+// statistics.
+const columnChunkRows = 4096
+
+// eachRow calls f with each row of x in [lo, hi) in order. The row slice must
+// not be retained or modified by f.
+func eachRow(x mat64.Matrix, lo, hi int, f func(i int, row []float64)) {
+	_, c := x.Dims()
+	if d, ok := x.(*mat64.Dense); ok {
+		for i := lo; i < hi; i++ {
+			f(i, d.RawRowView(i)[:c])
+		}
+		return
+	}
+	row := make([]float64, c)
+	for i := lo; i < hi; i++ {
+		for j := range row {
+			row[j] = x.At(i, j)
+		}
+		f(i, row)
+	}
+}
+
+// columnPartials holds the partial results of the chunks of a round of
+// reduceChunks, each a slice of length n.
+type columnPartials [reduceRound][]float64
+
+// get returns the zeroed partial result of length n for slot.
+func (p *columnPartials) get(slot, n int) []float64 {
+	if len(p[slot]) != n {
+		p[slot] = make([]float64, n)
+	}
+	s := p[slot]
+	for i := range s {
+		s[i] = 0
+	}
+	return s
+}
+
+// ColumnMeans returns the weighted means of the columns of x, equal up to
+// rounding to the values returned by Mean for each column, and exactly equal
+// for fewer than 4096 rows. If weights is nil then all of the weights are 1.
+// If weights is not nil, then its length must equal the number of rows of x.
+// If dst is nil, a new slice is allocated, otherwise the means are stored in
+// dst, which must have length equal to the number of columns.
+func ColumnMeans(dst []float64, x mat64.Matrix, weights []float64) []float64 {
+	r, c := x.Dims()
+	if weights != nil && len(weights) != r {
+		panic(ErrLengthMismatch{Got: len(weights), Want: r})
+	}
+	dst = reuseFloats(dst, c)
+	for j := range dst {
+		dst[j] = 0
+	}
+	// The last element of each partial result is the sum of the weights.
+	var partial columnPartials
+	var sumWeights float64
+	reduceChunks(r, columnChunkRows, c, func(slot, lo, hi int) {
+		sum := partial.get(slot, c+1)
+		if weights == nil {
+			eachRow(x, lo, hi, func(_ int, row []float64) {
+				for j, v := range row {
+					sum[j] += v
+				}
+			})
+			sum[c] = float64(hi - lo)
+			return
+		}
+		eachRow(x, lo, hi, func(i int, row []float64) {
+			w := weights[i]
+			for j, v := range row {
+				sum[j] += w * v
+			}
+			sum[c] += w
+		})
+	}, func(slot int) {
+		for j, v := range partial[slot][:c] {
+			dst[j] += v
+		}
+		sumWeights += partial[slot][c]
+	})
+	for j := range dst {
+		dst[j] /= sumWeights
+	}
+	return dst
+}
+
+// ColumnVariances returns the weighted sample variances of the columns of x,
+// equal up to rounding to the values returned by Variance for each column,
+// and exactly equal for fewer than 4096 rows. The weights and dst are as for
+// ColumnMeans.
+func ColumnVariances(dst []float64, x mat64.Matrix, weights []float64) []float64 {
+	return columnVariances(dst, x, weights, ColumnMeans(nil, x, weights))
+}
+
+// columnVariances is ColumnVariances with the column means of x precomputed.
+func columnVariances(dst []float64, x mat64.Matrix, weights, mean []float64) []float64 {
+	r, c := x.Dims()
+	dst = reuseFloats(dst, c)
+	// The corrected two-pass algorithm of MeanVariance, with the sums of
+	// squares in the first c elements of the partial results and the
+	// compensation terms in the next c. The last element is the sum of the
+	// weights.
+	comp := make([]float64, c)
+	for j := range dst {
+		dst[j] = 0
+	}
+	var partial columnPartials
+	var sumWeights float64
+	reduceChunks(r, columnChunkRows, c, func(slot, lo, hi int) {
+		p := partial.get(slot, 2*c+1)
+		ss, cp := p[:c], p[c:2*c]
+		if weights == nil {
+			eachRow(x, lo, hi, func(_ int, row []float64) {
+				for j, v := range row {
+					d := v - mean[j]
+					ss[j] += d * d
+					cp[j] += d
+				}
+			})
+			p[2*c] = float64(hi - lo)
+			return
+		}
+		eachRow(x, lo, hi, func(i int, row []float64) {
+			w := weights[i]
+			for j, v := range row {
+				d := v - mean[j]
+				wd := w * d
+				ss[j] += wd * d
+				cp[j] += wd
+			}
+			p[2*c] += w
+		})
+	}, func(slot int) {
+		p := partial[slot]
+		for j := range dst {
+			dst[j] += p[j]
+			comp[j] += p[c+j]
+		}
+		sumWeights += p[2*c]
+	})
+	for j := range dst {
+		dst[j] = (dst[j] - comp[j]*comp[j]/sumWeights) / (sumWeights - 1)
+	}
+	return dst
+}
+
+// CenterColumns stores in dst the columns of x with their weighted means
+// subtracted, and returns the means, as computed by ColumnMeans. dst must have
+// the same dimensions as x, and may be x itself to center x in place. The
+// weights are as for ColumnMeans.
+func CenterColumns(dst *mat64.Dense, x mat64.Matrix, weights []float64) (means []float64) {
+	checkColumnsDst(dst, x)
+	means = ColumnMeans(nil, x, weights)
+	r, _ := x.Dims()
+	eachRow(x, 0, r, func(i int, row []float64) {
+		d := dst.RawRowView(i)
+		for j, v := range row {
+			d[j] = v - means[j]
+		}
+	})
+	return means
+}
+
+// ScaleColumns stores in dst the columns of x each multiplied by the
+// corresponding element of scales, which must have length equal to the number
+// of columns of x. dst must have the same dimensions as x, and may be x itself
+// to scale x in place.
+func ScaleColumns(dst *mat64.Dense, x mat64.Matrix, scales []float64) {
+	checkColumnsDst(dst, x)
+	r, c := x.Dims()
+	if len(scales) != c {
+		panic(ErrLengthMismatch{Got: len(scales), Want: c})
+	}
+	eachRow(x, 0, r, func(i int, row []float64) {
+		d := dst.RawRowView(i)
+		for j, v := range row {
+			d[j] = v * scales[j]
+		}
+	})
+}
+
+// CenterScaleColumns stores in dst the standardized columns of x, with their
+// weighted means subtracted and divided by their weighted sample standard
+// deviations, and returns the means and standard deviations. The result is
+// written in a single pass over x, rather than the two of CenterColumns
+// followed by ScaleColumns. Columns with zero standard deviation are only
+// centered. dst and the weights are as for CenterColumns.
+func CenterScaleColumns(dst *mat64.Dense, x mat64.Matrix, weights []float64) (means, stds []float64) {
+	checkColumnsDst(dst, x)
+	means = ColumnMeans(nil, x, weights)
+	stds = columnVariances(nil, x, weights, means)
+	for j, v := range stds {
+		stds[j] = math.Sqrt(v)
+	}
+	r, _ := x.Dims()
+	eachRow(x, 0, r, func(i int, row []float64) {
+		d := dst.RawRowView(i)
+		for j, v := range row {
+			v -= means[j]
+			if stds[j] != 0 {
+				v /= stds[j]
+			}
+			d[j] = v
+		}
+	})
+	return means, stds
+}
+
+// RescaleColumns stores in dst the columns of x linearly mapped from the
+// range of their values onto [lo, hi], and returns the smallest and largest
+// values of the columns, as computed by ColumnMinMax, so that the same
+// transform can be applied to new data. Columns with all values equal are
+// mapped to lo, and columns containing NaN to NaN. dst must have the same
+// dimensions as x, and may be x itself to rescale x in place. RescaleColumns
+// panics if lo is not less than hi.
+func RescaleColumns(dst *mat64.Dense, x mat64.Matrix, lo, hi float64) (min, max []float64) {
+	checkColumnsDst(dst, x)
+	if !(lo < hi) {
+		panic("stat: empty range")
+	}
+	min, max = ColumnMinMax(nil, nil, x)
+	r, _ := x.Dims()
+	eachRow(x, 0, r, func(i int, row []float64) {
+		d := dst.RawRowView(i)
+		for j, v := range row {
+			switch span := max[j] - min[j]; {
+			case span == 0:
+				d[j] = lo
+			case v == max[j]:
+				// Avoid rounding past the end of the range.
+				d[j] = hi
+			default:
+				d[j] = lo + (hi-lo)*((v-min[j])/span)
+			}
+		}
+	})
+	return min, max
+}
+
+// checkColumnsDst panics if dst does not have the dimensions of x.
+func checkColumnsDst(dst *mat64.Dense, x mat64.Matrix) {
+	r, c := x.Dims()
+	if dr, dc := dst.Dims(); dr != r || dc != c {
+		panic(mat64.ErrShape)
+	}
+}
+
+// ColumnMinMax returns the smallest and largest values of the columns of x.
+// A column containing a NaN value has NaN minimum and maximum. If min or max
+// is nil, a new slice is allocated, otherwise the values are stored in it,
+// and it must have length equal to the number of columns. ColumnMinMax panics
+// if x has no rows.
+func ColumnMinMax(min, max []float64, x mat64.Matrix) ([]float64, []float64) {
+	r, c := x.Dims()
+	if r == 0 {
+		panic("stat: zero length slice")
+	}
+	min = reuseFloats(min, c)
+	max = reuseFloats(max, c)
+	// The partial minima are in the first c elements of the partial
+	// results and the maxima in the next c.
+	var partial columnPartials
+	first := true
+	update := func(lo, hi []float64, row []float64) {
+		for j, v := range row {
+			switch {
+			case math.IsNaN(lo[j]):
+			case math.IsNaN(v):
+				lo[j] = v
+				hi[j] = v
+			case v < lo[j]:
+				lo[j] = v
+			case v > hi[j]:
+				hi[j] = v
+			}
+		}
+	}
+	reduceChunks(r, columnChunkRows, c, func(slot, lo, hi int) {
+		p := partial.get(slot, 2*c)
+		pmin, pmax := p[:c], p[c:]
+		eachRow(x, lo, hi, func(i int, row []float64) {
+			if i == lo {
+				copy(pmin, row)
+				copy(pmax, row)
+				return
+			}
+			update(pmin, pmax, row)
+		})
+	}, func(slot int) {
+		p := partial[slot]
+		if first {
+			copy(min, p[:c])
+			copy(max, p[c:])
+			first = false
+			return
+		}
+		update(min, max, p[:c])
+		update(min, max, p[c:])
+	})
+	return min, max
+}
+
+// ColumnSummary holds the summary statistics of a column of a matrix
+// returned by ColumnStats.
+type ColumnSummary struct {
+	// Count is the sum of the weights of the observations, which is their
+	// number if all of the weights are 1.
+	Count float64
+
+	Mean, Variance, Skew, ExKurtosis float64
+
+	// Min and Max are the smallest and largest values of the observations
+	// with non-zero weight.
+	Min, Max float64
+}
+
+// ColumnStats returns the count, weighted mean, sample variance, skewness,
+// excess kurtosis and the range of each column of x, as computed by Mean,
+// Variance, Skew, ExKurtosis, Min and Max for the column, in a single pass
+// over x. The moments are accumulated as by Moments, so they equal those of
+// the two-pass functions up to rounding. A column containing NaN has NaN
+// statistics, and a column with no observations of non-zero weight has a zero
+// Count and NaN for the rest. Quantiles, which need a sort, are computed
+// separately by ColumnQuantiles. If weights is nil then all of the weights
+// are 1. If weights is not nil, then its length must equal the number of rows
+// of x, and the weights must not be negative.
+func ColumnStats(x mat64.Matrix, weights []float64) []ColumnSummary {
+	r, c := x.Dims()
+	if weights != nil && len(weights) != r {
+		panic(ErrLengthMismatch{Got: len(weights), Want: r})
+	}
+	moments := make([]Moments, c)
+	min := make([]float64, c)
+	max := make([]float64, c)
+	for j := range min {
+		min[j] = math.Inf(1)
+		max[j] = math.Inf(-1)
+	}
+	// The partial minima are in the first c elements of the partial
+	// extremes and the maxima in the next c.
+	var partial [reduceRound][]Moments
+	var extremes columnPartials
+	update := func(lo, hi []float64, j int, vlo, vhi float64) {
+		switch {
+		case math.IsNaN(lo[j]):
+		case math.IsNaN(vlo) || math.IsNaN(vhi):
+			lo[j] = math.NaN()
+			hi[j] = math.NaN()
+		default:
+			lo[j] = math.Min(lo[j], vlo)
+			hi[j] = math.Max(hi[j], vhi)
+		}
+	}
+	reduceChunks(r, columnChunkRows, c, func(slot, lo, hi int) {
+		if len(partial[slot]) != c {
+			partial[slot] = make([]Moments, c)
+		}
+		m := partial[slot]
+		for j := range m {
+			m[j] = Moments{}
+		}
+		e := extremes.get(slot, 2*c)
+		pmin, pmax := e[:c], e[c:]
+		for j := range pmin {
+			pmin[j] = math.Inf(1)
+			pmax[j] = math.Inf(-1)
+		}
+		eachRow(x, lo, hi, func(i int, row []float64) {
+			w := 1.0
+			if weights != nil {
+				w = weights[i]
+			}
+			for j, v := range row {
+				m[j].Add(v, w)
+			}
+			if w == 0 {
+				return
+			}
+			for j, v := range row {
+				update(pmin, pmax, j, v, v)
+			}
+		})
+	}, func(slot int) {
+		e := extremes[slot]
+		for j := range moments {
+			moments[j].Merge(&partial[slot][j])
+			update(min, max, j, e[j], e[c+j])
+		}
+	})
+
+	stats := make([]ColumnSummary, c)
+	for j := range stats {
+		m := &moments[j]
+		if m.Count() == 0 {
+			nan := math.NaN()
+			stats[j] = ColumnSummary{Mean: nan, Variance: nan, Skew: nan, ExKurtosis: nan, Min: nan, Max: nan}
+			continue
+		}
+		stats[j] = ColumnSummary{
+			Count:      m.Count(),
+			Mean:       m.Mean(),
+			Variance:   m.Variance(),
+			Skew:       m.Skew(),
+			ExKurtosis: m.ExKurtosis(),
+			Min:        min[j],
+			Max:        max[j],
+		}
+	}
+	return stats
+}
+
+// columnBlock is the number of columns sorted at a time by ColumnQuantiles.
+const columnBlock = 64
+
+// ColumnQuantiles returns the quantiles at ps of the columns of x, as
+// returned by Quantile with kind Empirical and nil weights for each sorted
+// column. Element (i, j) of the result is the quantile at ps[i] of column j.
+// If dst is nil, a new matrix is allocated, otherwise the quantiles are stored
+// in dst, which must be len(ps)×c where c is the number of columns of x. x is
+// not modified.
+//
+// The columns are copied and sorted in blocks, reading the rows of x once
+// for each block of columns. The blocks may be processed in parallel as
+// configured by Parallel.
+func ColumnQuantiles(dst *mat64.Dense, ps []float64, x mat64.Matrix) *mat64.Dense {
+	for _, p := range ps {
+		if !(p >= 0 && p <= 1) {
+			panic("stat: percentile out of bounds")
+		}
+	}
+	r, c := x.Dims()
+	if dst == nil {
+		dst = mat64.NewDense(len(ps), c, nil)
+	} else if dr, dc := dst.Dims(); dr != len(ps) || dc != c {
+		panic(mat64.ErrShape)
+	}
+	blocks := (c + columnBlock - 1) / columnBlock
+	runParallel(blocks, r*c, func(k int) {
+		start := k * columnBlock
+		end := start + columnBlock
+		if end > c {
+			end = c
+		}
+		cols := make([][]float64, end-start)
+		for j := range cols {
+			cols[j] = make([]float64, r)
+		}
+		eachRow(x, 0, r, func(i int, row []float64) {
+			for j, v := range row[start:end] {
+				cols[j][i] = v
+			}
+		})
+		for j, col := range cols {
+			sort.Float64s(col)
+			for i, p := range ps {
+				dst.Set(i, start+j, Quantile(p, Empirical, col, nil))
+			}
+		}
+	})
+	return dst
+}
@@ -0,0 +1,360 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestColumnStats(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	ps := []float64{0, 0.1, 0.25, 0.5, 0.9, 1}
+	for _, dims := range [][2]int{{1, 1}, {5, 3}, {100, 7}, {20, columnBlock + 3}} {
+		r, c := dims[0], dims[1]
+		x := mat64.NewDense(r, c, nil)
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				x.Set(i, j, math.Floor(100*rnd.NormFloat64())/10)
+			}
+		}
+		wts := make([]float64, r)
+		for i := range wts {
+			wts[i] = rnd.Float64()
+		}
+		for _, m := range []mat64.Matrix{x, transposed{transposedCopy(x)}} {
+			for _, w := range [][]float64{nil, wts} {
+				means := ColumnMeans(nil, m, w)
+				vars := ColumnVariances(make([]float64, c), m, w)
+				for j := 0; j < c; j++ {
+					col := x.Col(nil, j)
+					if got, want := means[j], Mean(col, w); got != want {
+						t.Errorf("%d×%d column %d: mean mismatch: got %v, want %v", r, c, j, got, want)
+					}
+					if r < 2 {
+						continue
+					}
+					if got, want := vars[j], Variance(col, w); got != want {
+						t.Errorf("%d×%d column %d: variance mismatch: got %v, want %v", r, c, j, got, want)
+					}
+				}
+			}
+			min, max := ColumnMinMax(nil, nil, m)
+			q := ColumnQuantiles(nil, ps, m)
+			for j := 0; j < c; j++ {
+				col := x.Col(nil, j)
+				if min[j] != Min(col) || max[j] != Max(col) {
+					t.Errorf("%d×%d column %d: min/max mismatch: got %v, %v, want %v, %v", r, c, j, min[j], max[j], Min(col), Max(col))
+				}
+				sort.Float64s(col)
+				for i, p := range ps {
+					if got, want := q.At(i, j), Quantile(p, Empirical, col, nil); got != want {
+						t.Errorf("%d×%d column %d: quantile %v mismatch: got %v, want %v", r, c, j, p, got, want)
+					}
+				}
+			}
+		}
+	}
+
+	x := mat64.NewDense(3, 2, []float64{1, 2, math.NaN(), 4, 0, 6})
+	min, max := ColumnMinMax(nil, nil, x)
+	if !math.IsNaN(min[0]) || !math.IsNaN(max[0]) || min[1] != 2 || max[1] != 6 {
+		t.Errorf("NaN min/max mismatch: got %v, %v", min, max)
+	}
+	q := ColumnQuantiles(nil, []float64{0.5}, x)
+	if !math.IsNaN(q.At(0, 0)) || q.At(0, 1) != 4 {
+		t.Errorf("NaN quantile mismatch: got %v", q)
+	}
+	if !math.IsNaN(x.At(1, 0)) || x.At(2, 0) != 0 {
+		t.Errorf("ColumnQuantiles modified its input")
+	}
+
+	for _, f := range []func(){
+		func() { ColumnMeans(make([]float64, 3), x, nil) },
+		func() { ColumnMeans(nil, x, []float64{1, 1}) },
+		func() { ColumnVariances(nil, x, []float64{1}) },
+		func() { ColumnMinMax(nil, make([]float64, 1), x) },
+		func() { ColumnMinMax(nil, nil, mat64.NewDense(0, 0, nil)) },
+		func() { ColumnQuantiles(mat64.NewDense(2, 2, nil), []float64{0.5}, x) },
+		func() { ColumnQuantiles(nil, []float64{1.5}, x) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestColumnSummaryStats(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	// More rows than columnChunkRows, so that the chunks are combined.
+	for _, dims := range [][2]int{{4, 1}, {50, 3}, {5000, 3}} {
+		r, c := dims[0], dims[1]
+		x := mat64.NewDense(r, c, nil)
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				x.Set(i, j, 1e3*float64(j)+math.Exp(rnd.NormFloat64()))
+			}
+		}
+		wts := make([]float64, r)
+		for i := range wts {
+			wts[i] = rnd.Float64()
+		}
+		for _, m := range []mat64.Matrix{x, transposed{transposedCopy(x)}} {
+			for _, w := range [][]float64{nil, wts} {
+				stats := ColumnStats(m, w)
+				if len(stats) != c {
+					t.Fatalf("%d×%d: unexpected number of columns %d", r, c, len(stats))
+				}
+				for j, s := range stats {
+					col := x.Col(nil, j)
+					count := float64(r)
+					if w != nil {
+						count = floats.Sum(w)
+					}
+					for _, v := range []struct {
+						name      string
+						got, want float64
+					}{
+						{"count", s.Count, count},
+						{"mean", s.Mean, Mean(col, w)},
+						{"variance", s.Variance, Variance(col, w)},
+						{"skewness", s.Skew, Skew(col, w)},
+						{"excess kurtosis", s.ExKurtosis, ExKurtosis(col, w)},
+					} {
+						if !floats.EqualWithinAbsOrRel(v.got, v.want, 1e-10, 1e-10) {
+							t.Errorf("%d×%d column %d: %s mismatch: got %v, want %v", r, c, j, v.name, v.got, v.want)
+						}
+					}
+					if s.Min != Min(col) || s.Max != Max(col) {
+						t.Errorf("%d×%d column %d: min/max mismatch: got %v, %v, want %v, %v", r, c, j, s.Min, s.Max, Min(col), Max(col))
+					}
+				}
+			}
+		}
+	}
+
+	// Observations with zero weight are excluded from the range, and NaN
+	// spreads to all of the statistics of its column.
+	x := mat64.NewDense(4, 3, []float64{
+		1, 2, 9,
+		math.NaN(), 4, 7,
+		0, 6, 1,
+		3, -5, 0,
+	})
+	stats := ColumnStats(x, []float64{1, 2, 1, 0})
+	for _, v := range []float64{stats[0].Mean, stats[0].Variance, stats[0].Min, stats[0].Max} {
+		if !math.IsNaN(v) {
+			t.Errorf("expected NaN statistics for NaN column, got %+v", stats[0])
+			break
+		}
+	}
+	if stats[1].Count != 4 || stats[1].Mean != 4 || stats[1].Min != 2 || stats[1].Max != 6 {
+		t.Errorf("weighted column mismatch, got %+v", stats[1])
+	}
+	stats = ColumnStats(x, []float64{0, 0, 0, 0})
+	if s := stats[2]; s.Count != 0 || !math.IsNaN(s.Mean) || !math.IsNaN(s.Min) {
+		t.Errorf("expected empty summary for zero weights, got %+v", s)
+	}
+
+	for _, f := range []func(){
+		func() { ColumnStats(x, []float64{1, 1}) },
+		func() { ColumnStats(x, []float64{1, 1, -1, 1}) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestCenterScaleColumns(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const r, c = 50, 4
+	x := mat64.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c-1; j++ {
+			x.Set(i, j, 10*float64(j)+rnd.NormFloat64())
+		}
+		// The last column has zero variance, also when weighted since
+		// scaling by a power of two is exact.
+		x.Set(i, c-1, 4)
+	}
+	wts := make([]float64, r)
+	for i := range wts {
+		wts[i] = rnd.Float64()
+	}
+	scales := []float64{2, -1, 0.5, 4}
+	for _, w := range [][]float64{nil, wts} {
+		centered := mat64.NewDense(r, c, nil)
+		means := CenterColumns(centered, x, w)
+		standard := mat64.NewDense(r, c, nil)
+		smeans, stds := CenterScaleColumns(standard, x, w)
+		scaled := mat64.NewDense(r, c, nil)
+		ScaleColumns(scaled, x, scales)
+		for j := 0; j < c; j++ {
+			col := x.Col(nil, j)
+			mean, std := MeanStdDev(col, w)
+			if !floats.EqualWithinAbsOrRel(means[j], mean, 1e-14, 1e-14) || smeans[j] != means[j] {
+				t.Errorf("column %d: mean mismatch: got %v and %v, want %v", j, means[j], smeans[j], mean)
+			}
+			if !floats.EqualWithinAbsOrRel(stds[j], std, 1e-14, 1e-14) {
+				t.Errorf("column %d: standard deviation mismatch: got %v, want %v", j, stds[j], std)
+			}
+			for i, v := range col {
+				if got, want := centered.At(i, j), v-means[j]; got != want {
+					t.Errorf("column %d row %d: centered mismatch: got %v, want %v", j, i, got, want)
+				}
+				want := v - means[j]
+				if stds[j] != 0 {
+					want /= stds[j]
+				}
+				if got := standard.At(i, j); got != want {
+					t.Errorf("column %d row %d: standardized mismatch: got %v, want %v", j, i, got, want)
+				}
+				if got, want := scaled.At(i, j), v*scales[j]; got != want {
+					t.Errorf("column %d row %d: scaled mismatch: got %v, want %v", j, i, got, want)
+				}
+			}
+		}
+		if stds[c-1] != 0 || Mean(standard.Col(nil, c-1), nil) != 0 {
+			t.Errorf("zero variance column not centered: std %v", stds[c-1])
+		}
+
+		// In place operation when dst is x.
+		for _, test := range []struct {
+			name string
+			f    func(dst *mat64.Dense, x mat64.Matrix)
+			want *mat64.Dense
+		}{
+			{"CenterColumns", func(dst *mat64.Dense, x mat64.Matrix) { CenterColumns(dst, x, w) }, centered},
+			{"ScaleColumns", func(dst *mat64.Dense, x mat64.Matrix) { ScaleColumns(dst, x, scales) }, scaled},
+			{"CenterScaleColumns", func(dst *mat64.Dense, x mat64.Matrix) { CenterScaleColumns(dst, x, w) }, standard},
+		} {
+			y := mat64.DenseCopyOf(x)
+			test.f(y, y)
+			if !y.Equals(test.want) {
+				t.Errorf("%s: in place result mismatch", test.name)
+			}
+		}
+	}
+
+	for _, f := range []func(){
+		func() { CenterColumns(mat64.NewDense(r, c+1, nil), x, nil) },
+		func() { CenterColumns(mat64.NewDense(r, c, nil), x, []float64{1}) },
+		func() { ScaleColumns(mat64.NewDense(r-1, c, nil), x, scales) },
+		func() { ScaleColumns(mat64.NewDense(r, c, nil), x, scales[1:]) },
+		func() { CenterScaleColumns(mat64.NewDense(1, 1, nil), x, nil) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestRescaleColumns(t *testing.T) {
+	x := mat64.NewDense(4, 4, []float64{
+		1, -2, 5, 3,
+		3, 8, 5, math.NaN(),
+		2, 0, 5, 1,
+		5, 3, 5, 2,
+	})
+	for _, test := range []struct {
+		lo, hi float64
+		want   []float64
+	}{
+		{0, 1, []float64{
+			0, 0, 0, math.NaN(),
+			0.5, 1, 0, math.NaN(),
+			0.25, 0.2, 0, math.NaN(),
+			1, 0.5, 0, math.NaN(),
+		}},
+		{-1, 1, []float64{
+			-1, -1, -1, math.NaN(),
+			0, 1, -1, math.NaN(),
+			-0.5, -0.6, -1, math.NaN(),
+			1, 0, -1, math.NaN(),
+		}},
+	} {
+		dst := mat64.NewDense(4, 4, nil)
+		min, max := RescaleColumns(dst, x, test.lo, test.hi)
+		if !sameFloatsNaN(min, []float64{1, -2, 5, math.NaN()}, 0) || !sameFloatsNaN(max, []float64{5, 8, 5, math.NaN()}, 0) {
+			t.Errorf("range [%v, %v]: unexpected column ranges %v and %v", test.lo, test.hi, min, max)
+		}
+		if got := dst.RawMatrix().Data; !sameFloatsNaN(got, test.want, 1e-15) {
+			t.Errorf("range [%v, %v]: result mismatch. Want %v, got %v", test.lo, test.hi, test.want, got)
+		}
+
+		y := mat64.DenseCopyOf(x)
+		RescaleColumns(y, y, test.lo, test.hi)
+		if !sameFloatsNaN(y.RawMatrix().Data, dst.RawMatrix().Data, 0) {
+			t.Errorf("range [%v, %v]: in place result mismatch", test.lo, test.hi)
+		}
+	}
+
+	// The ends of the range are attained exactly.
+	rnd := rand.New(rand.NewSource(1))
+	y := mat64.NewDense(100, 3, nil)
+	for i := 0; i < 100; i++ {
+		for j := 0; j < 3; j++ {
+			y.Set(i, j, 1e3*rnd.NormFloat64()+float64(j))
+		}
+	}
+	RescaleColumns(y, y, -3, 0.7)
+	min, max := ColumnMinMax(nil, nil, y)
+	for j := range min {
+		if min[j] != -3 || max[j] != 0.7 {
+			t.Errorf("column %d: range not attained: got [%v, %v]", j, min[j], max[j])
+		}
+	}
+
+	for _, f := range []func(){
+		func() { RescaleColumns(mat64.NewDense(4, 3, nil), x, 0, 1) },
+		func() { RescaleColumns(mat64.NewDense(4, 4, nil), x, 1, 1) },
+		func() { RescaleColumns(mat64.NewDense(4, 4, nil), x, 1, math.NaN()) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+// transposedCopy returns the transpose of m.
+func transposedCopy(m *mat64.Dense) *mat64.Dense {
+	var t mat64.Dense
+	t.TCopy(m)
+	return &t
+}
+
+func BenchmarkColumnMeans(b *testing.B) {
+	x := randMat(100000, 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ColumnMeans(nil, x, nil)
+	}
+}
+
+func BenchmarkColumnStats(b *testing.B) {
+	x := randMat(100000, 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ColumnStats(x, nil)
+	}
+}
+
+func BenchmarkColumnMeansPerColumn(b *testing.B) {
+	x := randMat(100000, 100).(*mat64.Dense)
+	_, c := x.Dims()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		means := make([]float64, c)
+		for j := range means {
+			means[j] = Mean(x.Col(nil, j), nil)
+		}
+	}
+}
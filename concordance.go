@@ -0,0 +1,335 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+// pairCounts holds the classification of the n(n-1)/2 pairs of a paired
+// sample (x_i, y_i) as concordant, discordant or tied. For a weighted sample
+// each pair counts with the product of the weights of its observations.
+type pairCounts struct {
+	n int
+
+	// concordant and discordant are the numbers of pairs ordered the same
+	// and the opposite way by x and y. Tied pairs are neither.
+	concordant, discordant float64
+
+	// tiesX and tiesY are the numbers of pairs tied in x and in y, and
+	// tiesXY the number tied in both.
+	tiesX, tiesY, tiesXY float64
+
+	// conc[i] and disc[i] are the numbers of observations forming a
+	// concordant or discordant pair with observation i, and groupX[i] and
+	// groupY[i] the numbers of observations, including i, with the same x
+	// and y values as observation i. For a weighted sample they are the
+	// weighted numbers.
+	conc, disc     []float64
+	groupX, groupY []float64
+}
+
+// countPairs classifies the pairs of the paired sample (x_i, y_i) in
+// O(n log n) time, by sweeping over the observations in order of x while
+// keeping the counts of the y ranks seen so far in a Fenwick tree. If weights
+// is nil then all of the weights are 1, otherwise len(weights) must equal
+// len(x).
+func countPairs(x, y, weights []float64) *pairCounts {
+	checkLengths(x, y)
+	checkWeightLength(x, weights)
+	n := len(x)
+	weight := func(i int) float64 {
+		if weights == nil {
+			return 1
+		}
+		return weights[i]
+	}
+	pc := &pairCounts{
+		n:      n,
+		conc:   make([]float64, n),
+		disc:   make([]float64, n),
+		groupX: make([]float64, n),
+		groupY: make([]float64, n),
+	}
+
+	// Dense ranks of y, starting at 1.
+	rank := make([]int, n)
+	byY := argsortFloats(y)
+	m := 0
+	for k, i := range byY {
+		if k == 0 || y[i] != y[byY[k-1]] {
+			m++
+		}
+		rank[i] = m
+	}
+	pc.tiesY = tiedPairs(y, byY, weights, pc.groupY)
+
+	// Order by x, breaking ties by y so that joint ties are adjacent.
+	byX := make([]int, n)
+	copy(byX, byY)
+	sort.Stable(indexSorter{idx: byX, x: x})
+	pc.tiesX = tiedPairs(x, byX, weights, pc.groupX)
+	for start := 0; start < n; {
+		end := start + 1
+		for end < n && x[byX[end]] == x[byX[start]] && y[byX[end]] == y[byX[start]] {
+			end++
+		}
+		pc.tiesXY += groupPairs(byX[start:end], weights)
+		start = end
+	}
+
+	// Sweep in increasing and then decreasing order of x. Observations with
+	// equal x are added to the tree only after the whole group has been
+	// counted, so that pairs tied in x are not counted.
+	tree := make(fenwick, m+1)
+	sweep := func(order []int, before bool) {
+		for i := range tree {
+			tree[i] = 0
+		}
+		var added float64
+		for start := 0; start < n; {
+			end := start + 1
+			for end < n && x[order[end]] == x[order[start]] {
+				end++
+			}
+			for _, i := range order[start:end] {
+				below := tree.sum(rank[i] - 1)
+				above := added - tree.sum(rank[i])
+				if before {
+					// Earlier observations have smaller x.
+					pc.conc[i] += below
+					pc.disc[i] += above
+				} else {
+					pc.conc[i] += above
+					pc.disc[i] += below
+				}
+			}
+			for _, i := range order[start:end] {
+				tree.add(rank[i], weight(i))
+				added += weight(i)
+			}
+			start = end
+		}
+	}
+	sweep(byX, true)
+	rev := make([]int, n)
+	for i, v := range byX {
+		rev[n-1-i] = v
+	}
+	sweep(rev, false)
+
+	for i := range pc.conc {
+		pc.concordant += weight(i) * pc.conc[i]
+		pc.discordant += weight(i) * pc.disc[i]
+	}
+	pc.concordant /= 2
+	pc.discordant /= 2
+	return pc
+}
+
+// tiedPairs returns the weighted number of pairs with equal values of x,
+// given the indices of x in sorted order, and stores the weighted size of the
+// group of equal values containing each observation in group.
+func tiedPairs(x []float64, order []int, weights, group []float64) float64 {
+	var ties float64
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && x[order[end]] == x[order[start]] {
+			end++
+		}
+		k := float64(end - start)
+		if weights != nil {
+			k = 0
+			for _, i := range order[start:end] {
+				k += weights[i]
+			}
+		}
+		for _, i := range order[start:end] {
+			group[i] = k
+		}
+		ties += groupPairs(order[start:end], weights)
+		start = end
+	}
+	return ties
+}
+
+// groupPairs returns the weighted number of pairs of the observations with
+// the given indices,
+//  ((Σ_i w_i)² - Σ_i w_i²) / 2
+// which is k(k-1)/2 for k observations if weights is nil.
+func groupPairs(idx []int, weights []float64) float64 {
+	if weights == nil {
+		k := float64(len(idx))
+		return k * (k - 1) / 2
+	}
+	var sum, sumSq float64
+	for _, i := range idx {
+		w := weights[i]
+		sum += w
+		sumSq += w * w
+	}
+	return (sum*sum - sumSq) / 2
+}
+
+// argsortFloats returns the indices of x in increasing order of x.
+func argsortFloats(x []float64) []int {
+	idx := make([]int, len(x))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Stable(indexSorter{idx: idx, x: x})
+	return idx
+}
+
+// indexSorter sorts a slice of indices by the values of x at the indices.
+type indexSorter struct {
+	idx []int
+	x   []float64
+}
+
+func (s indexSorter) Len() int           { return len(s.idx) }
+func (s indexSorter) Less(i, j int) bool { return s.x[s.idx[i]] < s.x[s.idx[j]] }
+func (s indexSorter) Swap(i, j int)      { s.idx[i], s.idx[j] = s.idx[j], s.idx[i] }
+
+// fenwick is a binary indexed tree over the positions 1, ..., len-1.
+type fenwick []float64
+
+func (f fenwick) add(i int, v float64) {
+	for ; i < len(f); i += i & -i {
+		f[i] += v
+	}
+}
+
+// sum returns the sum of the values at positions 1, ..., i.
+func (f fenwick) sum(i int) float64 {
+	var s float64
+	for ; i > 0; i -= i & -i {
+		s += f[i]
+	}
+	return s
+}
+
+// find returns the smallest position i for which sum(i) >= v, or len(f) if
+// there is none, for non-negative values, in O(log n) time.
+func (f fenwick) find(v float64) int {
+	step := 1
+	for step*2 < len(f) {
+		step *= 2
+	}
+	var pos int
+	for ; step > 0; step /= 2 {
+		if next := pos + step; next < len(f) && f[next] < v {
+			pos = next
+			v -= f[next]
+		}
+	}
+	return pos + 1
+}
+
+// Kendall returns the weighted Kendall rank correlation tau-b between the
+// samples of x and y,
+//  τ_b = (C - D) / √((n₀ - T_x)(n₀ - T_y))
+// where C and D are the numbers of concordant and discordant pairs of
+// observations, n₀ is the total number of pairs and T_x and T_y are the
+// numbers of pairs tied in x and in y. Each pair counts with the product of
+// the weights of its observations. The pairs are counted in O(n log n) time.
+// Kendall returns NaN if all of the values of x or of y are equal.
+// The lengths of x and y must be equal. If weights is nil then all of the
+// weights are 1. If weights is not nil, then len(x) must equal len(weights).
+func Kendall(x, y, weights []float64) float64 {
+	pc := countPairs(x, y, weights)
+	c, d := pc.concordant, pc.discordant
+	// The pairs not tied in x are those ordered by x, C + D, and those
+	// tied in y alone, and likewise for y.
+	nx := c + d + pc.tiesY - pc.tiesXY
+	ny := c + d + pc.tiesX - pc.tiesXY
+	if nx == 0 || ny == 0 {
+		return math.NaN()
+	}
+	return (c - d) / math.Sqrt(nx*ny)
+}
+
+// GoodmanKruskalGamma returns the Goodman–Kruskal gamma measure of ordinal
+// association between x and y,
+//  γ = (C - D) / (C + D)
+// where C and D are the numbers of concordant and discordant pairs of
+// observations. Pairs tied in x or y are ignored. GoodmanKruskalGamma panics
+// if the lengths of x and y differ.
+func GoodmanKruskalGamma(x, y []int) float64 {
+	g, _ := GoodmanKruskalGammaStdErr(x, y)
+	return g
+}
+
+// GoodmanKruskalGammaStdErr returns the Goodman–Kruskal gamma of x and y
+// together with its asymptotic standard error,
+//  σ = 2/(C + D)² √(Σ_i (D C_i - C D_i)²)
+// where C_i and D_i are the numbers of observations concordant and
+// discordant with observation i. The standard error is the one reported by
+// DescTools::GoodmanKruskalGamma in R and by SAS PROC FREQ.
+func GoodmanKruskalGammaStdErr(x, y []int) (gamma, stdErr float64) {
+	pc := countPairs(intsToFloats(x), intsToFloats(y), nil)
+	c, d := pc.concordant, pc.discordant
+	gamma = (c - d) / (c + d)
+	var ss float64
+	for i := range pc.conc {
+		v := d*pc.conc[i] - c*pc.disc[i]
+		ss += v * v
+	}
+	return gamma, 2 / ((c + d) * (c + d)) * math.Sqrt(ss)
+}
+
+// SomersD returns Somers' D measure of ordinal association between x and y.
+// If asymmetric is true, the result is D(y|x), with y the dependent variable,
+//  D(y|x) = (C - D) / (n₀ - T_x)
+// where C and D are the numbers of concordant and discordant pairs, n₀ is
+// the total number of pairs and T_x is the number of pairs tied in x. Pairs
+// tied only in y count against the association, unlike for gamma. D(x|y) is
+// obtained by swapping the arguments. If asymmetric is false, the symmetric
+// form with the mean of n₀ - T_x and n₀ - T_y in the denominator is returned.
+// SomersD panics if the lengths of x and y differ.
+func SomersD(x, y []int, asymmetric bool) float64 {
+	d, _ := SomersDStdErr(x, y, asymmetric)
+	return d
+}
+
+// SomersDStdErr returns Somers' D of x and y together with its asymptotic
+// standard error. For the asymmetric form the standard error is
+//  σ = 2/w² √(Σ_i (w (C_i - D_i) - (P - Q)(n - n_i))²)
+// where w = n² - Σ_k n_k² over the groups of tied x, P - Q = 2(C - D), C_i
+// and D_i are the numbers of observations concordant and discordant with
+// observation i and n_i is the size of the group of tied x containing
+// observation i. This is the standard error reported by DescTools::SomersDelta
+// in R and by SAS PROC FREQ. For the symmetric form, w and n_i are replaced
+// by the means of their values for x and for y.
+func SomersDStdErr(x, y []int, asymmetric bool) (d, stdErr float64) {
+	pc := countPairs(intsToFloats(x), intsToFloats(y), nil)
+	n := float64(pc.n)
+	pq := 2 * (pc.concordant - pc.discordant)
+	// n² - Σ n_k² is twice the number of pairs not tied in x.
+	w := n*n - n - 2*pc.tiesX
+	if !asymmetric {
+		w = n*n - n - pc.tiesX - pc.tiesY
+	}
+	var ss float64
+	for i := range pc.conc {
+		ni := pc.groupX[i]
+		if !asymmetric {
+			ni = (pc.groupX[i] + pc.groupY[i]) / 2
+		}
+		v := w*(pc.conc[i]-pc.disc[i]) - pq*(n-ni)
+		ss += v * v
+	}
+	return pq / w, 2 / (w * w) * math.Sqrt(ss)
+}
+
+// intsToFloats returns the values of x converted to float64.
+func intsToFloats(x []int) []float64 {
+	f := make([]float64, len(x))
+	for i, v := range x {
+		f[i] = float64(v)
+	}
+	return f
+}
@@ -0,0 +1,226 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCountPairs(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 2, 10, 57} {
+		// Few distinct values, so that ties are common.
+		x := make([]float64, n)
+		y := make([]float64, n)
+		for i := range x {
+			x[i] = float64(rnd.Intn(4))
+			y[i] = float64(rnd.Intn(5))
+		}
+		pc := countPairs(x, y, nil)
+		var c, d, tx, ty, txy float64
+		for i := range x {
+			var ci, di float64
+			for j := range x {
+				if i == j {
+					continue
+				}
+				s := (x[i] - x[j]) * (y[i] - y[j])
+				switch {
+				case s > 0:
+					ci++
+				case s < 0:
+					di++
+				}
+				if j > i {
+					if x[i] == x[j] {
+						tx++
+					}
+					if y[i] == y[j] {
+						ty++
+					}
+					if x[i] == x[j] && y[i] == y[j] {
+						txy++
+					}
+				}
+			}
+			if pc.conc[i] != ci || pc.disc[i] != di {
+				t.Errorf("n = %d: observation %d counts mismatch. Want %v, %v, got %v, %v", n, i, ci, di, pc.conc[i], pc.disc[i])
+			}
+			c += ci
+			d += di
+		}
+		c /= 2
+		d /= 2
+		if pc.concordant != c || pc.discordant != d {
+			t.Errorf("n = %d: pair counts mismatch. Want %v, %v, got %v, %v", n, c, d, pc.concordant, pc.discordant)
+		}
+		if pc.tiesX != tx || pc.tiesY != ty || pc.tiesXY != txy {
+			t.Errorf("n = %d: tie counts mismatch. Want %v, %v, %v, got %v, %v, %v", n, tx, ty, txy, pc.tiesX, pc.tiesY, pc.tiesXY)
+		}
+		if total := float64(n*(n-1)) / 2; c+d+tx+ty-txy != total {
+			t.Errorf("n = %d: pairs do not add up to %v", n, total)
+		}
+	}
+}
+
+func TestKendall(t *testing.T) {
+	// Value from scipy.stats.kendalltau.
+	x := []float64{12, 2, 1, 12, 2}
+	y := []float64{1, 4, 7, 1, 0}
+	if got, want := Kendall(x, y, nil), -0.47140452079103173; math.Abs(got-want) > 1e-15 {
+		t.Errorf("Kendall mismatch: want %v, got %v", want, got)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{2, 10, 57, 200} {
+		x := make([]float64, n)
+		y := make([]float64, n)
+		w := make([]float64, n)
+		ones := make([]float64, n)
+		for i := range x {
+			x[i] = float64(rnd.Intn(6))
+			y[i] = float64(rnd.Intn(7)) - 0.5*x[i]
+			w[i] = rnd.Float64()
+			ones[i] = 1
+		}
+		for _, weights := range [][]float64{nil, ones, w} {
+			var num, nx, ny float64
+			for i := range x {
+				for j := i + 1; j < n; j++ {
+					wij := 1.0
+					if weights != nil {
+						wij = weights[i] * weights[j]
+					}
+					s := (x[i] - x[j]) * (y[i] - y[j])
+					switch {
+					case s > 0:
+						num += wij
+					case s < 0:
+						num -= wij
+					}
+					if x[i] != x[j] {
+						nx += wij
+					}
+					if y[i] != y[j] {
+						ny += wij
+					}
+				}
+			}
+			want := num / math.Sqrt(nx*ny)
+			if got := Kendall(x, y, weights); math.Abs(got-want) > 1e-12 {
+				t.Errorf("n = %d: Kendall mismatch: want %v, got %v", n, want, got)
+			}
+		}
+	}
+
+	if got := Kendall([]float64{1, 2, 3}, []float64{4, 4, 4}, nil); !math.IsNaN(got) {
+		t.Errorf("Kendall of constant sample: want NaN, got %v", got)
+	}
+	if !Panics(func() { Kendall(make([]float64, 2), make([]float64, 3), nil) }) {
+		t.Errorf("Kendall did not panic with length mismatch")
+	}
+	if !Panics(func() { Kendall(make([]float64, 3), make([]float64, 3), make([]float64, 2)) }) {
+		t.Errorf("Kendall did not panic with weights length mismatch")
+	}
+}
+
+func BenchmarkKendall(b *testing.B) {
+	x := make([]float64, 500000)
+	y := make([]float64, len(x))
+	for i := range x {
+		x[i] = rand.Float64()
+		y[i] = x[i] + rand.Float64()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Kendall(x, y, nil)
+	}
+}
+
+// jobSatisfaction returns the observations of the cross-classification of
+// income (rows) and job satisfaction (columns) from the 1996 General Social
+// Survey, as analysed in Agresti, Categorical Data Analysis.
+func jobSatisfaction() (income, satisfaction []int) {
+	table := [4][4]int{
+		{1, 3, 10, 6},
+		{2, 3, 10, 7},
+		{1, 6, 14, 12},
+		{0, 1, 9, 11},
+	}
+	for i, row := range table {
+		for j, count := range row {
+			for k := 0; k < count; k++ {
+				income = append(income, i)
+				satisfaction = append(satisfaction, j)
+			}
+		}
+	}
+	return income, satisfaction
+}
+
+func TestGoodmanKruskalGamma(t *testing.T) {
+	// Agresti reports C = 1331, D = 849 and γ = 0.221 with ASE 0.117.
+	income, satisfaction := jobSatisfaction()
+	g, se := GoodmanKruskalGammaStdErr(income, satisfaction)
+	if want := 482.0 / 2180; math.Abs(g-want) > 1e-15 {
+		t.Errorf("gamma mismatch. Want %v, got %v", want, g)
+	}
+	if want := 0.11716282939545594; math.Abs(se-want) > 1e-12 {
+		t.Errorf("gamma standard error mismatch. Want %v, got %v", want, se)
+	}
+	if g2 := GoodmanKruskalGamma(satisfaction, income); g2 != g {
+		t.Errorf("gamma not symmetric: %v, %v", g, g2)
+	}
+
+	// Perfect association ignoring ties.
+	if g := GoodmanKruskalGamma([]int{1, 1, 2, 3, 3}, []int{1, 2, 2, 5, 5}); g != 1 {
+		t.Errorf("gamma of monotone data mismatch. Want 1, got %v", g)
+	}
+	if !Panics(func() { GoodmanKruskalGamma([]int{1, 2}, []int{1}) }) {
+		t.Errorf("GoodmanKruskalGamma did not panic with length mismatch")
+	}
+}
+
+func TestSomersD(t *testing.T) {
+	// Values computed with the formulas of DescTools::SomersDelta from the
+	// table of counts.
+	income, satisfaction := jobSatisfaction()
+	for _, test := range []struct {
+		asymmetric bool
+		d, se      float64
+	}{
+		{true, 0.14172302264039988, 0.07639511293610642},
+		{false, 0.15195460277427492, 0.08163898772501434},
+	} {
+		d, se := SomersDStdErr(income, satisfaction, test.asymmetric)
+		if math.Abs(d-test.d) > 1e-14 {
+			t.Errorf("asymmetric %t: D mismatch. Want %v, got %v", test.asymmetric, test.d, d)
+		}
+		if math.Abs(se-test.se) > 1e-12 {
+			t.Errorf("asymmetric %t: standard error mismatch. Want %v, got %v", test.asymmetric, test.se, se)
+		}
+	}
+
+	// The symmetric form is symmetric, and lies between the two asymmetric
+	// forms, of which it is the harmonic mean.
+	dyx := SomersD(income, satisfaction, true)
+	dxy := SomersD(satisfaction, income, true)
+	sym := SomersD(satisfaction, income, false)
+	if want := 2 / (1/dyx + 1/dxy); math.Abs(sym-want) > 1e-14 {
+		t.Errorf("symmetric D mismatch. Want %v, got %v", want, sym)
+	}
+
+	// Ties in y only reduce D but not gamma.
+	x := []int{1, 2, 3, 4}
+	y := []int{1, 1, 2, 3}
+	if d := SomersD(x, y, true); math.Abs(d-5.0/6) > 1e-15 {
+		t.Errorf("D(y|x) mismatch. Want %v, got %v", 5.0/6, d)
+	}
+	if d := SomersD(y, x, true); d != 1 {
+		t.Errorf("D(x|y) mismatch. Want 1, got %v", d)
+	}
+}
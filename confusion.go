@@ -0,0 +1,241 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ConfusionMatrix is the table of the weighted counts of the observations of
+// a classification by their true and predicted classes, coded as the integers
+// 0, 1, 2, .... Element (i, j) is the count of observations of true class i
+// predicted as class j.
+//
+// Metrics whose denominators are zero, such as the precision of a class that
+// is never predicted, are 0 rather than NaN, as in scikit-learn, so that
+// averages over the classes are defined.
+type ConfusionMatrix struct {
+	counts *mat64.Dense
+}
+
+// NewConfusionMatrix returns the confusion matrix of the classification of
+// observations of the true classes truth as the classes predicted. The
+// matrix has k rows and columns, where k is one more than the largest class
+// in truth and predicted. If weights is nil then all of the weights are 1.
+// If weights is not nil, then len(truth) must equal len(weights) and the
+// weights must not be negative, or NewConfusionMatrix panics with an
+// ErrLengthMismatch or an ErrNegativeWeight. NewConfusionMatrix also panics if
+// the lengths of truth and predicted differ, if they are empty or if a class
+// is negative.
+func NewConfusionMatrix(truth, predicted []int, weights []float64) *ConfusionMatrix {
+	if len(predicted) != len(truth) {
+		panic(ErrLengthMismatch{Got: len(predicted), Want: len(truth)})
+	}
+	if weights != nil {
+		checkWeightLength(intsToFloats(truth), weights)
+		if err := validateNonNegative(weights); err != nil {
+			panic(err)
+		}
+	}
+	if len(truth) == 0 {
+		panic("stat: zero length slice")
+	}
+	var k int
+	for i, v := range truth {
+		if v < 0 || predicted[i] < 0 {
+			panic("stat: negative class")
+		}
+		if v >= k {
+			k = v + 1
+		}
+		if predicted[i] >= k {
+			k = predicted[i] + 1
+		}
+	}
+	counts := mat64.NewDense(k, k, nil)
+	for i, v := range truth {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		counts.Set(v, predicted[i], counts.At(v, predicted[i])+w)
+	}
+	return &ConfusionMatrix{counts: counts}
+}
+
+// NewBinaryConfusionMatrix returns the 2×2 confusion matrix of a binary
+// classification, with false the class 0 and true the class 1, as for
+// NewConfusionMatrix.
+func NewBinaryConfusionMatrix(truth, predicted []bool, weights []float64) *ConfusionMatrix {
+	return NewConfusionMatrix(boolsToClasses(truth), boolsToClasses(predicted), weights).binary()
+}
+
+// NewThresholdConfusionMatrix returns the 2×2 confusion matrix of the binary
+// classification of observations as positive, class 1, when their scores are
+// at least threshold and as negative, class 0, otherwise, as for
+// NewBinaryConfusionMatrix. The points of the curves of
+// PrecisionRecallCurve are the precision and recall of these matrices at
+// its thresholds.
+func NewThresholdConfusionMatrix(truth []bool, scores []float64, threshold float64, weights []float64) *ConfusionMatrix {
+	if len(scores) != len(truth) {
+		panic(ErrLengthMismatch{Got: len(scores), Want: len(truth)})
+	}
+	predicted := make([]bool, len(scores))
+	for i, s := range scores {
+		predicted[i] = s >= threshold
+	}
+	return NewBinaryConfusionMatrix(truth, predicted, weights)
+}
+
+// binary returns c enlarged to 2×2 if all observations are of class 0.
+func (c *ConfusionMatrix) binary() *ConfusionMatrix {
+	if k, _ := c.counts.Dims(); k == 1 {
+		counts := mat64.NewDense(2, 2, nil)
+		counts.Set(0, 0, c.counts.At(0, 0))
+		c.counts = counts
+	}
+	return c
+}
+
+// boolsToClasses returns the classes 0 for false and 1 for true of b.
+func boolsToClasses(b []bool) []int {
+	classes := make([]int, len(b))
+	for i, v := range b {
+		if v {
+			classes[i] = 1
+		}
+	}
+	return classes
+}
+
+// Classes returns the number of classes of the matrix.
+func (c *ConfusionMatrix) Classes() int {
+	k, _ := c.counts.Dims()
+	return k
+}
+
+// At returns the count of observations of true class i predicted as class j.
+func (c *ConfusionMatrix) At(i, j int) float64 {
+	return c.counts.At(i, j)
+}
+
+// Total returns the total count of the observations.
+func (c *ConfusionMatrix) Total() float64 {
+	var t float64
+	k := c.Classes()
+	for i := 0; i < k; i++ {
+		t += c.Support(i)
+	}
+	return t
+}
+
+// Support returns the count of the observations of true class i.
+func (c *ConfusionMatrix) Support(i int) float64 {
+	var s float64
+	for j := 0; j < c.Classes(); j++ {
+		s += c.counts.At(i, j)
+	}
+	return s
+}
+
+// predictedCount returns the count of the observations predicted as class j.
+func (c *ConfusionMatrix) predictedCount(j int) float64 {
+	var s float64
+	for i := 0; i < c.Classes(); i++ {
+		s += c.counts.At(i, j)
+	}
+	return s
+}
+
+// Accuracy returns the fraction of the observations whose class is
+// correctly predicted. It equals the micro-averaged precision, recall and
+// F1 score.
+func (c *ConfusionMatrix) Accuracy() float64 {
+	var correct float64
+	for i := 0; i < c.Classes(); i++ {
+		correct += c.counts.At(i, i)
+	}
+	return ratioOrZero(correct, c.Total())
+}
+
+// Precision returns the precision of class i, the fraction of the
+// observations predicted as class i that are of class i.
+func (c *ConfusionMatrix) Precision(i int) float64 {
+	return ratioOrZero(c.counts.At(i, i), c.predictedCount(i))
+}
+
+// Recall returns the recall of class i, the fraction of the observations of
+// class i that are predicted as class i.
+func (c *ConfusionMatrix) Recall(i int) float64 {
+	return ratioOrZero(c.counts.At(i, i), c.Support(i))
+}
+
+// F1 returns the F1 score of class i, the harmonic mean of its precision
+// and recall,
+//  F1 = 2 TP / (2 TP + FP + FN)
+func (c *ConfusionMatrix) F1(i int) float64 {
+	tp := c.counts.At(i, i)
+	return ratioOrZero(2*tp, c.predictedCount(i)+c.Support(i))
+}
+
+// MacroPrecision, MacroRecall and MacroF1 return the unweighted means over
+// the classes of the precision, recall and F1 score. Classes that occur
+// neither in the truth nor in the predictions are left out of the means, as
+// in scikit-learn, so that unused class numbers do not affect them.
+func (c *ConfusionMatrix) MacroPrecision() float64 { return c.macro(c.Precision) }
+func (c *ConfusionMatrix) MacroRecall() float64    { return c.macro(c.Recall) }
+func (c *ConfusionMatrix) MacroF1() float64        { return c.macro(c.F1) }
+
+// MicroF1 returns the F1 score computed from the counts of true positives,
+// false positives and false negatives summed over the classes. Since each
+// misclassification is a false positive of one class and a false negative
+// of another, it equals the accuracy.
+func (c *ConfusionMatrix) MicroF1() float64 { return c.Accuracy() }
+
+func (c *ConfusionMatrix) macro(metric func(int) float64) float64 {
+	var sum, n float64
+	for i := 0; i < c.Classes(); i++ {
+		if c.Support(i) == 0 && c.predictedCount(i) == 0 {
+			continue
+		}
+		sum += metric(i)
+		n++
+	}
+	return ratioOrZero(sum, n)
+}
+
+// MCC returns the Matthews correlation coefficient of the classification,
+// in the multiclass form of Gorodkin,
+//  MCC = (c s - \sum_k p_k t_k) / √((s² - \sum_k p_k²)(s² - \sum_k t_k²))
+// where c is the count of correct predictions, s the total count, and p_k
+// and t_k the counts of the observations predicted as and of class k. For
+// two classes it is the correlation between the true and predicted classes.
+// MCC returns 0 if the denominator is zero, which is the case if all of the
+// observations are of one class or are predicted as one class.
+func (c *ConfusionMatrix) MCC() float64 {
+	k := c.Classes()
+	s := c.Total()
+	var correct, pt, pp, tt float64
+	for i := 0; i < k; i++ {
+		p := c.predictedCount(i)
+		t := c.Support(i)
+		correct += c.counts.At(i, i)
+		pt += p * t
+		pp += p * p
+		tt += t * t
+	}
+	den := math.Sqrt((s*s - pp) * (s*s - tt))
+	return ratioOrZero(correct*s-pt, den)
+}
+
+// ratioOrZero returns num/den, or 0 if den is zero.
+func ratioOrZero(num, den float64) float64 {
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
@@ -0,0 +1,259 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConfusionMatrix(t *testing.T) {
+	const tol = 1e-14
+	for i, test := range []struct {
+		truth, predicted []int
+		weights          []float64
+
+		classes                int
+		accuracy               float64
+		precision, recall, f1  []float64
+		macroP, macroR, macroF float64
+		mcc                    float64
+	}{
+		{
+			// The example of precision_recall_fscore_support in
+			// scikit-learn.
+			truth:     []int{0, 1, 2, 0, 1, 2},
+			predicted: []int{0, 2, 1, 0, 0, 1},
+
+			classes:   3,
+			accuracy:  1.0 / 3,
+			precision: []float64{2.0 / 3, 0, 0},
+			recall:    []float64{1, 0, 0},
+			f1:        []float64{0.8, 0, 0},
+			macroP:    2.0 / 9,
+			macroR:    1.0 / 3,
+			macroF:    0.8 / 3,
+			mcc:       0,
+		},
+		{
+			truth:     []int{0, 0, 1, 1, 2, 2, 2},
+			predicted: []int{0, 1, 1, 1, 2, 0, 2},
+
+			classes:   3,
+			accuracy:  5.0 / 7,
+			precision: []float64{0.5, 2.0 / 3, 1},
+			recall:    []float64{0.5, 1, 2.0 / 3},
+			f1:        []float64{0.5, 0.8, 0.8},
+			macroP:    13.0 / 18,
+			macroR:    13.0 / 18,
+			macroF:    0.7,
+			mcc:       0.59375,
+		},
+		{
+			// Class 1 is never predicted and class 3 never occurs.
+			truth:     []int{0, 1, 2, 2},
+			predicted: []int{0, 2, 2, 3},
+
+			classes:   4,
+			accuracy:  0.5,
+			precision: []float64{1, 0, 0.5, 0},
+			recall:    []float64{1, 0, 0.5, 0},
+			f1:        []float64{1, 0, 0.5, 0},
+			macroP:    0.375,
+			macroR:    0.375,
+			macroF:    0.375,
+			mcc:       (2*4 - (1 + 0 + 4 + 0)) / math.Sqrt((16-6)*(16-6)),
+		},
+		{
+			// A weight of 2 counts as two copies of an observation.
+			truth:     []int{0, 0, 1},
+			predicted: []int{0, 1, 1},
+			weights:   []float64{2, 1, 1},
+
+			classes:   2,
+			accuracy:  0.75,
+			precision: []float64{1, 0.5},
+			recall:    []float64{2.0 / 3, 1},
+			f1:        []float64{0.8, 2.0 / 3},
+			macroP:    0.75,
+			macroR:    5.0 / 6,
+			macroF:    (0.8 + 2.0/3) / 2,
+			mcc:       (3*4 - (2*3 + 2*1)) / math.Sqrt((16-8)*(16-10)),
+		},
+		{
+			// All observations of one class and predicted correctly. The
+			// unused class 0 is left out of the macro averages.
+			truth:     []int{1, 1, 1},
+			predicted: []int{1, 1, 1},
+
+			classes:   2,
+			accuracy:  1,
+			precision: []float64{0, 1},
+			recall:    []float64{0, 1},
+			f1:        []float64{0, 1},
+			macroP:    1,
+			macroR:    1,
+			macroF:    1,
+			mcc:       0,
+		},
+		{
+			// Class 2 occurs only in the predictions and is averaged,
+			// while the unused class 1 is not.
+			truth:     []int{0, 0},
+			predicted: []int{0, 2},
+
+			classes:   3,
+			accuracy:  0.5,
+			precision: []float64{1, 0, 0},
+			recall:    []float64{0.5, 0, 0},
+			f1:        []float64{2.0 / 3, 0, 0},
+			macroP:    0.5,
+			macroR:    0.25,
+			macroF:    1.0 / 3,
+			mcc:       0,
+		},
+	} {
+		c := NewConfusionMatrix(test.truth, test.predicted, test.weights)
+		if c.Classes() != test.classes {
+			t.Errorf("case %d: classes mismatch. Want %d, got %d", i, test.classes, c.Classes())
+			continue
+		}
+		var total float64
+		for k := 0; k < c.Classes(); k++ {
+			total += c.Support(k)
+		}
+		want := float64(len(test.truth))
+		if test.weights != nil {
+			want = 0
+			for _, w := range test.weights {
+				want += w
+			}
+		}
+		if total != want || c.Total() != want {
+			t.Errorf("case %d: total mismatch. Want %v, got %v and %v", i, want, c.Total(), total)
+		}
+		for _, m := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"accuracy", c.Accuracy(), test.accuracy},
+			{"micro F1", c.MicroF1(), test.accuracy},
+			{"macro precision", c.MacroPrecision(), test.macroP},
+			{"macro recall", c.MacroRecall(), test.macroR},
+			{"macro F1", c.MacroF1(), test.macroF},
+			{"MCC", c.MCC(), test.mcc},
+		} {
+			if math.Abs(m.got-m.want) > tol {
+				t.Errorf("case %d: %s mismatch. Want %v, got %v", i, m.name, m.want, m.got)
+			}
+		}
+		for k := 0; k < c.Classes(); k++ {
+			if math.Abs(c.Precision(k)-test.precision[k]) > tol {
+				t.Errorf("case %d: precision mismatch for class %d. Want %v, got %v", i, k, test.precision[k], c.Precision(k))
+			}
+			if math.Abs(c.Recall(k)-test.recall[k]) > tol {
+				t.Errorf("case %d: recall mismatch for class %d. Want %v, got %v", i, k, test.recall[k], c.Recall(k))
+			}
+			if math.Abs(c.F1(k)-test.f1[k]) > tol {
+				t.Errorf("case %d: F1 mismatch for class %d. Want %v, got %v", i, k, test.f1[k], c.F1(k))
+			}
+		}
+	}
+}
+
+func TestBinaryConfusionMatrix(t *testing.T) {
+	// The example of matthews_corrcoef in scikit-learn.
+	truth := []bool{true, true, true, false}
+	predicted := []bool{true, false, true, true}
+	c := NewBinaryConfusionMatrix(truth, predicted, nil)
+	if c.At(1, 1) != 2 || c.At(1, 0) != 1 || c.At(0, 1) != 1 || c.At(0, 0) != 0 {
+		t.Errorf("unexpected counts %v %v %v %v", c.At(0, 0), c.At(0, 1), c.At(1, 0), c.At(1, 1))
+	}
+	if got, want := c.MCC(), -1.0/3; math.Abs(got-want) > 1e-15 {
+		t.Errorf("MCC mismatch. Want %v, got %v", want, got)
+	}
+
+	// For two classes the MCC is the correlation of the classes.
+	truth = []bool{true, false, true, true, false, false, true, false}
+	predicted = []bool{true, false, false, true, true, false, true, false}
+	x := make([]float64, len(truth))
+	y := make([]float64, len(truth))
+	for i := range truth {
+		if truth[i] {
+			x[i] = 1
+		}
+		if predicted[i] {
+			y[i] = 1
+		}
+	}
+	c = NewBinaryConfusionMatrix(truth, predicted, nil)
+	if got, want := c.MCC(), Correlation(x, y, nil); math.Abs(got-want) > 1e-14 {
+		t.Errorf("MCC mismatch with correlation. Want %v, got %v", want, got)
+	}
+
+	// All observations negative, which is still a 2×2 matrix.
+	c = NewBinaryConfusionMatrix([]bool{false, false}, []bool{false, false}, nil)
+	if c.Classes() != 2 {
+		t.Errorf("classes mismatch for all negative. Want 2, got %d", c.Classes())
+	}
+	if c.MCC() != 0 || c.Precision(1) != 0 || c.Recall(1) != 0 || c.Accuracy() != 1 {
+		t.Errorf("unexpected metrics for all negative")
+	}
+}
+
+func TestThresholdConfusionMatrix(t *testing.T) {
+	scores := []float64{0.1, 0.4, 0.35, 0.8}
+	classes := []bool{false, false, true, true}
+	precision, recall, thresholds := PrecisionRecallCurve(scores, classes, nil)
+	for i, th := range thresholds {
+		c := NewThresholdConfusionMatrix(classes, scores, th, nil)
+		if math.Abs(c.Precision(1)-precision[i]) > 1e-15 {
+			t.Errorf("precision mismatch at threshold %v. Want %v, got %v", th, precision[i], c.Precision(1))
+		}
+		if math.Abs(c.Recall(1)-recall[i]) > 1e-15 {
+			t.Errorf("recall mismatch at threshold %v. Want %v, got %v", th, recall[i], c.Recall(1))
+		}
+	}
+	c := NewThresholdConfusionMatrix(classes, scores, 0.5, nil)
+	if c.At(1, 1) != 1 || c.At(1, 0) != 1 || c.At(0, 0) != 2 || c.At(0, 1) != 0 {
+		t.Errorf("unexpected counts at threshold 0.5")
+	}
+}
+
+func TestConfusionMatrixPanics(t *testing.T) {
+	for i, test := range []struct {
+		name string
+		fn   func()
+	}{
+		{"length mismatch", func() { NewConfusionMatrix([]int{0, 1}, []int{0}, nil) }},
+		{"weight length", func() { NewConfusionMatrix([]int{0, 1}, []int{0, 1}, []float64{1}) }},
+		{"negative weight", func() { NewConfusionMatrix([]int{0, 1}, []int{0, 1}, []float64{1, -1}) }},
+		{"empty", func() { NewConfusionMatrix(nil, nil, nil) }},
+		{"negative class", func() { NewConfusionMatrix([]int{0, -1}, []int{0, 1}, nil) }},
+		{"score length", func() { NewThresholdConfusionMatrix([]bool{true}, []float64{1, 2}, 0, nil) }},
+	} {
+		if !Panics(test.fn) {
+			t.Errorf("case %d: expected panic for %s", i, test.name)
+		}
+	}
+
+	// The weights are checked by the shared validators.
+	for _, test := range []struct {
+		weights []float64
+		want    error
+	}{
+		{[]float64{1}, ErrLengthMismatch{Got: 1, Want: 2}},
+		{[]float64{1, -1}, ErrNegativeWeight{Index: 1}},
+	} {
+		var got interface{}
+		func() {
+			defer func() { got = recover() }()
+			NewConfusionMatrix([]int{0, 1}, []int{0, 1}, test.weights)
+		}()
+		if got != test.want {
+			t.Errorf("weights %v: panic value mismatch: got %v, want %v", test.weights, got, test.want)
+		}
+	}
+}
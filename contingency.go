@@ -0,0 +1,383 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ContingencyTable is a two-way table of the counts of observations
+// cross-classified by two categorical variables, the row variable and the
+// column variable.
+type ContingencyTable struct {
+	counts *mat64.Dense
+}
+
+// NewContingencyTable returns a contingency table holding a copy of the
+// given counts. NewContingencyTable panics if a count is negative.
+func NewContingencyTable(counts mat64.Matrix) *ContingencyTable {
+	c := mat64.DenseCopyOf(counts)
+	r, k := c.Dims()
+	for i := 0; i < r; i++ {
+		for _, v := range c.RawRowView(i)[:k] {
+			if v < 0 {
+				panic("stat: negative count")
+			}
+		}
+	}
+	return &ContingencyTable{counts: c}
+}
+
+// Crosstab returns the contingency table of the paired observations of two
+// categorical variables whose categories are coded as the integers
+// 0, 1, 2, .... Element (i, j) of the table is the number of indices k
+// with a[k] == i and b[k] == j, and the table has max(a)+1 rows and
+// max(b)+1 columns. Crosstab panics if the lengths of a and b differ, if
+// they are empty or if a category is negative.
+func Crosstab(a, b []int) *ContingencyTable {
+	if len(a) != len(b) {
+		panic(ErrLengthMismatch{Got: len(b), Want: len(a)})
+	}
+	if len(a) == 0 {
+		panic("stat: zero length slice")
+	}
+	var r, c int
+	for i, v := range a {
+		if v < 0 || b[i] < 0 {
+			panic("stat: negative category")
+		}
+		if v >= r {
+			r = v + 1
+		}
+		if b[i] >= c {
+			c = b[i] + 1
+		}
+	}
+	counts := mat64.NewDense(r, c, nil)
+	for i, v := range a {
+		counts.Set(v, b[i], counts.At(v, b[i])+1)
+	}
+	return &ContingencyTable{counts: counts}
+}
+
+// CrosstabLabels returns the contingency table of the paired observations of
+// two categorical variables with string labels, along with the maps from
+// the labels of each variable to the corresponding row or column of the
+// table. Labels are assigned to rows and columns in sorted order.
+// CrosstabLabels panics if the lengths of a and b differ or if they are
+// empty.
+func CrosstabLabels(a, b []string) (t *ContingencyTable, rows, cols map[string]int) {
+	if len(a) != len(b) {
+		panic(ErrLengthMismatch{Got: len(b), Want: len(a)})
+	}
+	rows = labelIndex(a)
+	cols = labelIndex(b)
+	ia := make([]int, len(a))
+	ib := make([]int, len(b))
+	for i := range a {
+		ia[i] = rows[a[i]]
+		ib[i] = cols[b[i]]
+	}
+	return Crosstab(ia, ib), rows, cols
+}
+
+// labelIndex returns a map from the distinct labels to their positions in
+// sorted order.
+func labelIndex(labels []string) map[string]int {
+	m := make(map[string]int)
+	for _, l := range labels {
+		m[l] = 0
+	}
+	sorted := make([]string, 0, len(m))
+	for l := range m {
+		sorted = append(sorted, l)
+	}
+	sort.Strings(sorted)
+	for i, l := range sorted {
+		m[l] = i
+	}
+	return m
+}
+
+// Dims returns the number of rows and columns of the table.
+func (t *ContingencyTable) Dims() (r, c int) {
+	return t.counts.Dims()
+}
+
+// Counts returns a copy of the counts of the table.
+func (t *ContingencyTable) Counts() *mat64.Dense {
+	return mat64.DenseCopyOf(t.counts)
+}
+
+// Total returns the total count of the table.
+func (t *ContingencyTable) Total() float64 {
+	r, c := t.Dims()
+	var n float64
+	for i := 0; i < r; i++ {
+		for _, v := range t.counts.RawRowView(i)[:c] {
+			n += v
+		}
+	}
+	return n
+}
+
+// RowTotals returns the marginal totals of the rows of the table. If dst is
+// nil, a new slice is allocated, otherwise the totals are stored in dst,
+// which must have length equal to the number of rows.
+func (t *ContingencyTable) RowTotals(dst []float64) []float64 {
+	r, c := t.Dims()
+	dst = reuseFloats(dst, r)
+	for i := range dst {
+		dst[i] = 0
+		for _, v := range t.counts.RawRowView(i)[:c] {
+			dst[i] += v
+		}
+	}
+	return dst
+}
+
+// ColTotals returns the marginal totals of the columns of the table. If dst
+// is nil, a new slice is allocated, otherwise the totals are stored in dst,
+// which must have length equal to the number of columns.
+func (t *ContingencyTable) ColTotals(dst []float64) []float64 {
+	r, c := t.Dims()
+	dst = reuseFloats(dst, c)
+	for j := range dst {
+		dst[j] = 0
+	}
+	for i := 0; i < r; i++ {
+		for j, v := range t.counts.RawRowView(i)[:c] {
+			dst[j] += v
+		}
+	}
+	return dst
+}
+
+// Proportions returns the counts of the table divided by the total count.
+func (t *ContingencyTable) Proportions() *mat64.Dense {
+	p := t.Counts()
+	p.Scale(1/t.Total(), p)
+	return p
+}
+
+// RowProportions returns the counts of the table divided by their row
+// totals, so that each row sums to one. Rows with a zero total are NaN.
+func (t *ContingencyTable) RowProportions() *mat64.Dense {
+	r, c := t.Dims()
+	rows := t.RowTotals(nil)
+	p := t.Counts()
+	for i := 0; i < r; i++ {
+		row := p.RawRowView(i)[:c]
+		for j := range row {
+			row[j] /= rows[i]
+		}
+	}
+	return p
+}
+
+// ColProportions returns the counts of the table divided by their column
+// totals, so that each column sums to one. Columns with a zero total are
+// NaN.
+func (t *ContingencyTable) ColProportions() *mat64.Dense {
+	r, c := t.Dims()
+	cols := t.ColTotals(nil)
+	p := t.Counts()
+	for i := 0; i < r; i++ {
+		row := p.RawRowView(i)[:c]
+		for j := range row {
+			row[j] /= cols[j]
+		}
+	}
+	return p
+}
+
+// Expected returns the expected counts under independence of the row and
+// column variables,
+//  E_ij = R_i C_j / N
+// where R_i and C_j are the row and column totals and N is the total count.
+func (t *ContingencyTable) Expected() *mat64.Dense {
+	r, c := t.Dims()
+	rows := t.RowTotals(nil)
+	cols := t.ColTotals(nil)
+	n := t.Total()
+	e := mat64.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		row := e.RawRowView(i)[:c]
+		for j := range row {
+			row[j] = rows[i] * cols[j] / n
+		}
+	}
+	return e
+}
+
+// PearsonResiduals returns the Pearson residuals of the table under
+// independence,
+//  (O_ij - E_ij) / √E_ij
+// where O and E are the observed and expected counts. The sum of the squared
+// residuals is Pearson's chi-square statistic.
+func (t *ContingencyTable) PearsonResiduals() *mat64.Dense {
+	r, c := t.Dims()
+	res := t.Expected()
+	for i := 0; i < r; i++ {
+		row := res.RawRowView(i)[:c]
+		for j, e := range row {
+			row[j] = (t.counts.At(i, j) - e) / math.Sqrt(e)
+		}
+	}
+	return res
+}
+
+// AdjustedResiduals returns the adjusted (standardized) residuals of the
+// table under independence,
+//  (O_ij - E_ij) / √(E_ij (1 - R_i/N) (1 - C_j/N))
+// which are asymptotically standard normal when the variables are
+// independent.
+func (t *ContingencyTable) AdjustedResiduals() *mat64.Dense {
+	r, c := t.Dims()
+	rows := t.RowTotals(nil)
+	cols := t.ColTotals(nil)
+	n := t.Total()
+	res := t.Expected()
+	for i := 0; i < r; i++ {
+		row := res.RawRowView(i)[:c]
+		for j, e := range row {
+			v := e * (1 - rows[i]/n) * (1 - cols[j]/n)
+			row[j] = (t.counts.At(i, j) - e) / math.Sqrt(v)
+		}
+	}
+	return res
+}
+
+// ChiSquareResult is the result of Pearson's chi-square test of independence.
+type ChiSquareResult struct {
+	// Statistic is the chi-square statistic, with DF degrees of freedom,
+	// and P its p-value.
+	Statistic float64
+	DF        float64
+	P         float64
+
+	// Expected holds the expected counts under independence, and
+	// StdResiduals the adjusted residuals as returned by
+	// ContingencyTable.AdjustedResiduals.
+	Expected     *mat64.Dense
+	StdResiduals *mat64.Dense
+}
+
+// ChiSquareIndependence performs Pearson's chi-square test of the
+// independence of the row and column variables of the table of counts,
+//  X² = sum_ij (O_ij - E_ij)² / E_ij
+// where O and E are the observed and expected counts. X² has (r-1)(c-1)
+// degrees of freedom for a table with r rows and c columns. If yates is true
+// and the table is 2×2, Yates' continuity correction is applied, reducing
+// each |O_ij - E_ij| by min(0.5, |O_ij - E_ij|); otherwise yates is ignored.
+//
+// A row or column whose counts sum to zero has no expected counts, so it does
+// not contribute to X² and is not counted in the degrees of freedom, and its
+// standardized residuals are NaN. If fewer than two rows or columns have
+// non-zero totals, DF is zero and P is NaN.
+//
+// ChiSquareIndependence panics if a count is negative.
+func ChiSquareIndependence(table mat64.Matrix, yates bool) ChiSquareResult {
+	t := NewContingencyTable(table)
+	r, c := t.Dims()
+	expected := t.Expected()
+	correct := yates && r == 2 && c == 2
+
+	var x float64
+	for i := 0; i < r; i++ {
+		for j, e := range expected.RawRowView(i)[:c] {
+			if e == 0 {
+				continue
+			}
+			d := math.Abs(t.counts.At(i, j) - e)
+			if correct {
+				d -= math.Min(0.5, d)
+			}
+			x += d * d / e
+		}
+	}
+	nonZero := func(totals []float64) float64 {
+		var n float64
+		for _, v := range totals {
+			if v != 0 {
+				n++
+			}
+		}
+		return n
+	}
+	var df float64
+	p := math.NaN()
+	if nr, nc := nonZero(t.RowTotals(nil)), nonZero(t.ColTotals(nil)); nr > 1 && nc > 1 {
+		df = (nr - 1) * (nc - 1)
+		p = chiSquareSurvival(x, df)
+	}
+	return ChiSquareResult{
+		Statistic:    x,
+		DF:           df,
+		P:            p,
+		Expected:     expected,
+		StdResiduals: t.AdjustedResiduals(),
+	}
+}
+
+// FisherExact performs Fisher's exact test of the independence of the rows
+// and columns of the 2×2 table of counts
+//  a b
+//  c d
+// against the alternative alt, where Less and Greater are the alternatives
+// that the odds ratio is less or greater than one. It returns the sample odds
+// ratio ad/(bc) and the p-value, computed from the hypergeometric
+// distribution of the count a given the row and column totals. As in R's
+// fisher.test, the two-sided p-value is the sum of the probabilities of the
+// tables that are no more likely than the observed one, up to a relative
+// tolerance of 1e-7. The probabilities are computed from log-gamma functions,
+// so large counts do not overflow.
+//
+// The odds ratio is +Inf if bc is zero and ad is not, and NaN if both are
+// zero. FisherExact panics if a count is negative or not an integer.
+func FisherExact(a, b, c, d float64, alt Alternative) (oddsRatio, p float64) {
+	for _, v := range []float64{a, b, c, d} {
+		if v < 0 || v != math.Floor(v) || math.IsInf(v, 0) {
+			panic("stat: count is not a non-negative integer")
+		}
+	}
+	oddsRatio = a * d / (b * c)
+
+	row, col, n := a+b, a+c, a+b+c+d
+	lo := math.Max(0, col-(c+d))
+	hi := math.Min(row, col)
+	lchoose := func(n, k float64) float64 {
+		ln, _ := math.Lgamma(n + 1)
+		lk, _ := math.Lgamma(k + 1)
+		lnk, _ := math.Lgamma(n - k + 1)
+		return ln - lk - lnk
+	}
+	total := lchoose(n, col)
+	logProb := func(x float64) float64 {
+		return lchoose(row, x) + lchoose(n-row, col-x) - total
+	}
+
+	switch alt {
+	case Less:
+		for x := lo; x <= a; x++ {
+			p += math.Exp(logProb(x))
+		}
+	case Greater:
+		for x := a; x <= hi; x++ {
+			p += math.Exp(logProb(x))
+		}
+	default:
+		obs := logProb(a) + math.Log1p(1e-7)
+		for x := lo; x <= hi; x++ {
+			if lp := logProb(x); lp <= obs {
+				p += math.Exp(lp)
+			}
+		}
+	}
+	return oddsRatio, math.Min(p, 1)
+}
@@ -0,0 +1,284 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestContingencyTable(t *testing.T) {
+	a := []int{0, 0, 1, 1, 1, 0, 1, 0, 0, 1, 0, 0}
+	b := []int{0, 2, 1, 0, 0, 2, 2, 1, 2, 0, 2, 0}
+	tab := Crosstab(a, b)
+	want := mat64.NewDense(2, 3, []float64{
+		2, 1, 4,
+		3, 1, 1,
+	})
+	if !tab.Counts().Equals(want) {
+		t.Errorf("counts mismatch. Want %v, got %v", want, tab.Counts())
+	}
+	if r, c := tab.Dims(); r != 2 || c != 3 {
+		t.Errorf("dims mismatch. Want 2×3, got %d×%d", r, c)
+	}
+	if n := tab.Total(); n != float64(len(a)) {
+		t.Errorf("total mismatch. Want %d, got %v", len(a), n)
+	}
+
+	tab = NewContingencyTable(mat64.NewDense(2, 3, []float64{
+		10, 20, 30,
+		20, 10, 10,
+	}))
+	rows := tab.RowTotals(nil)
+	cols := tab.ColTotals(nil)
+	if !floats.Equal(rows, []float64{60, 40}) {
+		t.Errorf("row totals mismatch: %v", rows)
+	}
+	if !floats.Equal(cols, []float64{30, 30, 40}) {
+		t.Errorf("column totals mismatch: %v", cols)
+	}
+	if floats.Sum(rows) != tab.Total() || floats.Sum(cols) != tab.Total() {
+		t.Errorf("marginal totals do not sum to the total")
+	}
+	expected := mat64.NewDense(2, 3, []float64{
+		18, 18, 24,
+		12, 12, 16,
+	})
+	if e := tab.Expected(); !e.EqualsApprox(expected, 1e-12) {
+		t.Errorf("expected counts mismatch. Want %v, got %v", expected, e)
+	}
+
+	p := tab.Proportions()
+	rp := tab.RowProportions()
+	cp := tab.ColProportions()
+	pr := tab.PearsonResiduals()
+	ar := tab.AdjustedResiduals()
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			o := tab.Counts().At(i, j)
+			e := expected.At(i, j)
+			for _, test := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"proportion", p.At(i, j), o / 100},
+				{"row proportion", rp.At(i, j), o / rows[i]},
+				{"column proportion", cp.At(i, j), o / cols[j]},
+				{"Pearson residual", pr.At(i, j), (o - e) / math.Sqrt(e)},
+				{"adjusted residual", ar.At(i, j), (o - e) / math.Sqrt(e*(1-rows[i]/100)*(1-cols[j]/100))},
+			} {
+				if math.Abs(test.got-test.want) > 1e-14 {
+					t.Errorf("%s (%d, %d) mismatch. Want %v, got %v", test.name, i, j, test.want, test.got)
+				}
+			}
+		}
+	}
+
+	// A zero row has NaN row proportions.
+	zero := NewContingencyTable(mat64.NewDense(2, 2, []float64{0, 0, 1, 3}))
+	if rp := zero.RowProportions(); !math.IsNaN(rp.At(0, 0)) || rp.At(1, 1) != 0.75 {
+		t.Errorf("unexpected row proportions for table with a zero row: %v", rp)
+	}
+
+	if !Panics(func() { Crosstab([]int{0, 1}, []int{0}) }) {
+		t.Errorf("Crosstab did not panic with length mismatch")
+	}
+	if !Panics(func() { Crosstab([]int{0, -1}, []int{0, 1}) }) {
+		t.Errorf("Crosstab did not panic with negative category")
+	}
+	if !Panics(func() { NewContingencyTable(mat64.NewDense(1, 2, []float64{1, -1})) }) {
+		t.Errorf("NewContingencyTable did not panic with negative count")
+	}
+	if !Panics(func() { tab.RowTotals(make([]float64, 3)) }) {
+		t.Errorf("RowTotals did not panic with wrong destination length")
+	}
+}
+
+func TestCrosstabLabels(t *testing.T) {
+	a := []string{"yes", "no", "yes", "maybe", "no", "yes"}
+	b := []string{"red", "blue", "blue", "red", "red", "red"}
+	tab, rows, cols := CrosstabLabels(a, b)
+	wantRows := map[string]int{"maybe": 0, "no": 1, "yes": 2}
+	wantCols := map[string]int{"blue": 0, "red": 1}
+	if len(rows) != len(wantRows) || len(cols) != len(wantCols) {
+		t.Fatalf("label map size mismatch: %v, %v", rows, cols)
+	}
+	for l, i := range wantRows {
+		if rows[l] != i {
+			t.Errorf("row label %q mismatch. Want %d, got %d", l, i, rows[l])
+		}
+	}
+	for l, i := range wantCols {
+		if cols[l] != i {
+			t.Errorf("column label %q mismatch. Want %d, got %d", l, i, cols[l])
+		}
+	}
+
+	// Recoding the labels with the maps reproduces the table.
+	ia := make([]int, len(a))
+	ib := make([]int, len(b))
+	for i := range a {
+		ia[i] = rows[a[i]]
+		ib[i] = cols[b[i]]
+	}
+	if !Crosstab(ia, ib).Counts().Equals(tab.Counts()) {
+		t.Errorf("recoded table mismatch")
+	}
+	for i, l := range a {
+		if tab.Counts().At(rows[l], cols[b[i]]) == 0 {
+			t.Errorf("observation %d (%q, %q) not counted", i, l, b[i])
+		}
+	}
+}
+
+func TestChiSquareIndependence(t *testing.T) {
+	for i, test := range []struct {
+		table        *mat64.Dense
+		yates        bool
+		stat, df, p  float64
+		stdResiduals []float64
+	}{
+		{
+			// The party identification example of R's chisq.test, where
+			// X² = 30.07 with p = 2.954e-07.
+			table:        mat64.NewDense(2, 3, []float64{762, 327, 468, 484, 239, 477}),
+			stat:         30.070149095754672,
+			df:           2,
+			p:            2.953589183211757e-07,
+			stdResiduals: []float64{4.502053521086705, 0.6994517329844296, -5.315945542704929, -4.502053521086705, -0.6994517329844296, 5.315945542704929},
+		},
+		{
+			table: mat64.NewDense(2, 2, []float64{12, 5, 3, 9}),
+			stat:  5.85483193277311,
+			df:    1,
+			p:     0.015534341414683482,
+		},
+		{
+			table: mat64.NewDense(2, 2, []float64{12, 5, 3, 9}),
+			yates: true,
+			stat:  4.171457749766574,
+			df:    1,
+			p:     0.04111041419430707,
+		},
+		{
+			// The correction only applies to 2×2 tables.
+			table:        mat64.NewDense(2, 3, []float64{762, 327, 468, 484, 239, 477}),
+			yates:        true,
+			stat:         30.070149095754672,
+			df:           2,
+			p:            2.953589183211757e-07,
+			stdResiduals: []float64{4.502053521086705, 0.6994517329844296, -5.315945542704929, -4.502053521086705, -0.6994517329844296, 5.315945542704929},
+		},
+		{
+			// A zero row and column are dropped.
+			table: mat64.NewDense(3, 3, []float64{10, 0, 5, 0, 0, 0, 4, 0, 11}),
+			stat:  4.821428571428571,
+			df:    1,
+			p:     0.028108040147151837,
+		},
+		{
+			table: mat64.NewDense(2, 2, []float64{3, 0, 5, 0}),
+			stat:  0,
+			df:    0,
+			p:     math.NaN(),
+		},
+	} {
+		res := ChiSquareIndependence(test.table, test.yates)
+		if !floats.EqualWithinAbsOrRel(res.Statistic, test.stat, 1e-12, 1e-12) {
+			t.Errorf("statistic mismatch case %d: got %v, want %v", i, res.Statistic, test.stat)
+		}
+		if res.DF != test.df {
+			t.Errorf("degrees of freedom mismatch case %d: got %v, want %v", i, res.DF, test.df)
+		}
+		if !floats.EqualWithinAbsOrRel(res.P, test.p, 1e-12, 1e-12) && !(math.IsNaN(res.P) && math.IsNaN(test.p)) {
+			t.Errorf("p-value mismatch case %d: got %v, want %v", i, res.P, test.p)
+		}
+		if !res.Expected.Equals(NewContingencyTable(test.table).Expected()) {
+			t.Errorf("expected counts mismatch case %d", i)
+		}
+		if test.stdResiduals != nil {
+			r, c := test.table.Dims()
+			if !res.StdResiduals.EqualsApprox(mat64.NewDense(r, c, test.stdResiduals), 1e-12) {
+				t.Errorf("standardized residuals mismatch case %d: got %v", i, res.StdResiduals.RawMatrix().Data)
+			}
+		}
+	}
+
+	res := ChiSquareIndependence(mat64.NewDense(3, 3, []float64{10, 0, 5, 0, 0, 0, 4, 0, 11}), false)
+	if !math.IsNaN(res.StdResiduals.At(1, 0)) || !math.IsNaN(res.StdResiduals.At(0, 1)) {
+		t.Errorf("expected NaN residuals for zero row and column")
+	}
+	if !Panics(func() { ChiSquareIndependence(mat64.NewDense(2, 2, []float64{1, -1, 2, 3}), false) }) {
+		t.Errorf("expected panic with negative count")
+	}
+}
+
+func TestFisherExact(t *testing.T) {
+	// The first case is the tea tasting example of R's fisher.test, with
+	// one-sided p-value 0.2429. The p-values were computed in exact integer
+	// arithmetic.
+	for i, test := range []struct {
+		a, b, c, d       float64
+		odds             float64
+		less, greater, p float64
+	}{
+		{3, 1, 1, 3, 9, 0.9857142857142858, 0.24285714285714285, 0.4857142857142857},
+		{12, 5, 3, 9, 7.2, 0.9978677328002665, 0.01968489439490781, 0.025327687033676143},
+		{1, 9, 11, 3, 1.0 / 33, 0.0013797280926100418, 0.9999663480953022, 0.0027594561852200836},
+		{0, 5, 0, 7, math.NaN(), 1, 1, 1},
+		{2000, 1100, 1900, 1150, 2000.0 * 1150 / (1100 * 1900), 0.9667118074117695, 0.03740973801870846, 0.0718568082166828},
+	} {
+		for _, v := range []struct {
+			alt  Alternative
+			want float64
+		}{
+			{Less, test.less},
+			{Greater, test.greater},
+			{TwoSided, test.p},
+		} {
+			odds, p := FisherExact(test.a, test.b, test.c, test.d, v.alt)
+			if !floats.EqualWithinAbsOrRel(odds, test.odds, 1e-14, 1e-14) && !(math.IsNaN(odds) && math.IsNaN(test.odds)) {
+				t.Errorf("odds ratio mismatch case %d: got %v, want %v", i, odds, test.odds)
+			}
+			if !floats.EqualWithinAbsOrRel(p, v.want, 1e-10, 1e-10) {
+				t.Errorf("p-value mismatch case %d alternative %d: got %v, want %v", i, v.alt, p, v.want)
+			}
+		}
+	}
+
+	// Counts in the tens of thousands.
+	odds, p := FisherExact(20000, 10100, 19800, 10000, TwoSided)
+	if math.IsNaN(p) || p < 0 || p > 1 || math.IsInf(odds, 0) {
+		t.Errorf("invalid result for large counts: odds ratio %v, p-value %v", odds, p)
+	}
+	_, less := FisherExact(20000, 10100, 19800, 10000, Less)
+	_, greater := FisherExact(20000, 10100, 19800, 10000, Greater)
+	if want := 1 + math.Exp(fisherLogProb(20000, 10100, 19800, 10000)); math.Abs(less+greater-want) > 1e-10 {
+		t.Errorf("one-sided p-values of large counts do not add up: got %v, want %v", less+greater, want)
+	}
+
+	for _, f := range []func(){
+		func() { FisherExact(-1, 2, 3, 4, TwoSided) },
+		func() { FisherExact(1, 2.5, 3, 4, TwoSided) },
+		func() { FisherExact(1, 2, math.Inf(1), 4, TwoSided) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+// fisherLogProb returns the log of the hypergeometric probability of the
+// 2×2 table.
+func fisherLogProb(a, b, c, d float64) float64 {
+	lf := func(x float64) float64 {
+		v, _ := math.Lgamma(x + 1)
+		return v
+	}
+	return lf(a+b) + lf(c+d) + lf(a+c) + lf(b+d) - lf(a) - lf(b) - lf(c) - lf(d) - lf(a+b+c+d)
+}
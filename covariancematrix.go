@@ -5,6 +5,8 @@
 package stat
 
 import (
+	"context"
+
 	"github.com/gonum/floats"
 	"github.com/gonum/matrix/mat64"
 	"math"
@@ -12,73 +14,337 @@ import (
 
 // CovarianceMatrix calculates a covariance matrix (also known as a
 // variance-covariance matrix) from a matrix of data, using a two-pass
-// algorithm. The matrix returned will be symmetric and square.
+// algorithm. The matrix returned will be symmetric and square. The way the
+// two passes are organized depends on the shape of x, as described for
+// CovarianceAlgorithm.
 //
 // The weights wts should have the length equal to the number of rows in
 // input data matrix x. If c is nil, then a new matrix with appropriate size will
 // be constructed.  If c is not nil, it should be a square matrix with the same
 // number of columns as the input data matrix x, and it will be used as the receiver
-// for the covariance data.  Weights cannot be negative.
+// for the covariance data.  Weights cannot be negative. CovarianceMatrixSym
+// returns the same matrix as a *mat64.SymDense.
 func CovarianceMatrix(cov *mat64.Dense, x mat64.Matrix, wts []float64) *mat64.Dense {
-	// This is the matrix version of the two-pass algorithm. It doesn't use the
-	// additional floating point error correction that the Covariance function uses
-	// to reduce the impact of rounding during centering.
-
-	// TODO(jonlawlor): indicate that the resulting matrix is symmetric, and change
-	// the returned type from a *mat.Dense to a *mat.Symmetric.
-
+	if err := ValidateCovarianceMatrix(cov, x, wts); err != nil {
+		panic(err)
+	}
 	r, c := x.Dims()
-
 	if cov == nil {
 		cov = mat64.NewDense(c, c, nil)
-	} else if covr, covc := cov.Dims(); covr != covc || covc != c {
-		panic(mat64.ErrShape)
 	}
+	switch covarianceAlgorithm(r, c) {
+	case StreamingCovariance:
+		covarianceRows(context.Background(), cov, MatrixRowReader{x}, wts)
+	case BlockedCovariance:
+		covarianceBlocked(cov, x, wts, true)
+	default:
+		covarianceProduct(cov, x, wts)
+	}
+	return cov
+}
 
-	var xt mat64.Dense
-	xt.TCopy(x)
-	// Subtract the mean of each of the columns.
-	for i := 0; i < c; i++ {
-		v := xt.RawRowView(i)
-		// This will panic with ErrShape if len(wts) != len(v), so
-		// we don't have to check the size later.
-		mean := Mean(v, wts)
-		floats.AddConst(-mean, v)
+// CovarianceMatrixSym is like CovarianceMatrix, but stores the covariance
+// matrix in a *mat64.SymDense, computing only the elements on and above the
+// diagonal where the algorithm allows it. If cov is nil, a new matrix is
+// allocated, otherwise cov must have as many rows as x has columns.
+func CovarianceMatrixSym(cov *mat64.SymDense, x mat64.Matrix, wts []float64) *mat64.SymDense {
+	r, c := x.Dims()
+	if cov != nil && cov.Symmetric() != c {
+		panic("stat: symmetric destination size does not match the number of columns")
 	}
+	if err := ValidateCovarianceMatrix(nil, x, wts); err != nil {
+		panic(err)
+	}
+	// The upper triangle of upper holds the result.
+	var upper *mat64.Dense
+	switch covarianceAlgorithm(r, c) {
+	case StreamingCovariance:
+		upper, _ = covarianceRows(context.Background(), nil, MatrixRowReader{x}, wts)
+	case BlockedCovariance:
+		upper = mat64.NewDense(c, c, nil)
+		covarianceBlocked(upper, x, wts, false)
+	default:
+		upper = mat64.NewDense(c, c, nil)
+		covarianceProduct(upper, x, wts)
+	}
+	sym := mat64.NewSymDense(c, upper.RawMatrix().Data)
+	if cov == nil {
+		return sym
+	}
+	cov.CopySym(sym)
+	return cov
+}
 
-	var n float64
-	if wts == nil {
+// CovarianceAlgorithm specifies the algorithm used by CovarianceMatrix.
+type CovarianceAlgorithm int
+
+const (
+	// AutoCovariance chooses the algorithm from the shape of the data.
+	AutoCovariance CovarianceAlgorithm = iota
+
+	// ProductCovariance centers a transposed copy of the data and forms
+	// the covariance matrix as a single matrix product.
+	ProductCovariance
 
-		n = float64(r)
+	// StreamingCovariance accumulates the co-moments over blocks of rows,
+	// without copying the data whole. It is the fastest for data with many
+	// more rows than columns.
+	StreamingCovariance
 
-		cov.MulTrans(&xt, false, &xt, true)
+	// BlockedCovariance centers a transposed copy of the data and forms
+	// the covariance matrix from products of blocks of columns, computing
+	// only the blocks on and above the diagonal. It is the fastest for
+	// data with many columns.
+	BlockedCovariance
+)
+
+// CovarianceMatrixAlgorithm is the algorithm used by CovarianceMatrix. It
+// is intended for debugging and benchmarking; the algorithms give the same
+// results up to floating point rounding.
+var CovarianceMatrixAlgorithm = AutoCovariance
 
-		// Scale by the sample size.
-		cov.Scale(1/(n-1), cov)
-		return cov
+// covarianceAlgorithm returns the algorithm to use for r×c data.
+func covarianceAlgorithm(r, c int) CovarianceAlgorithm {
+	if CovarianceMatrixAlgorithm != AutoCovariance {
+		return CovarianceMatrixAlgorithm
+	}
+	switch {
+	case c >= 2*covColumnBlock:
+		return BlockedCovariance
+	case r >= covStreamRatio*c && r >= covBlockRows:
+		return StreamingCovariance
+	}
+	return ProductCovariance
+}
+
+const (
+	// covColumnBlock is the number of columns in the blocks of
+	// BlockedCovariance.
+	covColumnBlock = 64
+
+	// covStreamRatio is the ratio of rows to columns from which
+	// AutoCovariance uses StreamingCovariance.
+	covStreamRatio = 32
+)
+
+// centered returns x with the weighted mean of each column subtracted, and
+// each row scaled by the square root of its weight, along with the sum of the
+// weights.
+func centered(x mat64.Matrix, wts []float64) (*mat64.Dense, float64) {
+	r, c := x.Dims()
+	xc := mat64.NewDense(r, c, nil)
+	CenterColumns(xc, x, wts)
+	if wts == nil {
+		return xc, float64(r)
 	}
 
 	// Multiply by the sqrt of the weights, so that multiplication is symmetric.
-	sqrtwts := make([]float64, r)
 	for i, w := range wts {
-		if w < 0 {
-			panic("stat: negative covariance matrix weights")
-		}
-		sqrtwts[i] = math.Sqrt(w)
-	}
-	// Weight the rows.
-	for i := 0; i < c; i++ {
-		v := xt.RawRowView(i)
-		floats.Mul(v, sqrtwts)
+		floats.Scale(math.Sqrt(w), xc.RawRowView(i))
 	}
+	return xc, floats.Sum(wts)
+}
 
-	// Calculate the normalization factor.
-	n = floats.Sum(wts)
-	cov.MulTrans(&xt, false, &xt, true)
+// covarianceProduct computes the covariance matrix of x into cov as a single
+// matrix product of the centered data.
+func covarianceProduct(cov *mat64.Dense, x mat64.Matrix, wts []float64) {
+	// This is the matrix version of the two-pass algorithm. It doesn't use the
+	// additional floating point error correction that the Covariance function uses
+	// to reduce the impact of rounding during centering.
+	xc, n := centered(x, wts)
+	cov.MulTrans(xc, true, xc, false)
 
 	// Scale by the sample size.
 	cov.Scale(1/(n-1), cov)
-	return cov
+}
+
+// covarianceBlocked computes the covariance matrix of x into cov from the
+// products of blocks of columns of the centered data on and above the
+// diagonal. If mirror is true, the blocks below the diagonal are filled by
+// symmetry, otherwise they are left unchanged.
+func covarianceBlocked(cov *mat64.Dense, x mat64.Matrix, wts []float64, mirror bool) {
+	xc, n := centered(x, wts)
+	r, c := xc.Dims()
+	nb := (c + covColumnBlock - 1) / covColumnBlock
+	var pairs [][2]int
+	for i := 0; i < nb; i++ {
+		for j := i; j < nb; j++ {
+			pairs = append(pairs, [2]int{i * covColumnBlock, j * covColumnBlock})
+		}
+	}
+	size := func(i int) int {
+		if i+covColumnBlock > c {
+			return c - i
+		}
+		return covColumnBlock
+	}
+	// The blocks are written to disjoint parts of cov, so they may be
+	// computed in parallel.
+	runParallel(len(pairs), r*c*c/len(pairs), func(k int) {
+		i, j := pairs[k][0], pairs[k][1]
+		bi, bj := size(i), size(j)
+		var prod mat64.Dense
+		prod.MulTrans(xc.View(0, i, r, bi), true, xc.View(0, j, r, bj), false)
+		prod.Scale(1/(n-1), &prod)
+		for k := 0; k < bi; k++ {
+			row := prod.RawRowView(k)[:bj]
+			copy(cov.RawRowView(i + k)[j:j+bj], row)
+			if mirror && i != j {
+				for l, v := range row {
+					cov.Set(j+l, i+k, v)
+				}
+			}
+		}
+	})
+}
+
+// covBlockRows is the number of observations in each block of rows processed
+// by CovarianceMatrixCtx and CovarianceMatrixFromReader, and covCheckBlocks
+// the number of blocks between checks of the context.
+const (
+	covBlockRows   = 256
+	covCheckBlocks = 8
+)
+
+// CovarianceMatrixCtx is like CovarianceMatrix, but stops and returns the
+// error of ctx if ctx is cancelled or expires before the computation is
+// complete. The observations are then processed in blocks of rows, and ctx is
+// checked at regular intervals between the blocks. On cancellation, the
+// returned matrix is nil and cov is left unchanged.
+//
+// If ctx can never be cancelled, as for context.Background, the result is
+// that of CovarianceMatrix. Otherwise the result may differ from it by
+// floating point rounding. CovarianceMatrixCtx panics for the same invalid
+// arguments as CovarianceMatrix.
+func CovarianceMatrixCtx(ctx context.Context, cov *mat64.Dense, x mat64.Matrix, wts []float64) (*mat64.Dense, error) {
+	if ctx.Done() == nil {
+		return CovarianceMatrix(cov, x, wts), nil
+	}
+	if err := ValidateCovarianceMatrix(cov, x, wts); err != nil {
+		panic(err)
+	}
+	return covarianceRows(ctx, cov, MatrixRowReader{x}, wts)
+}
+
+// covarianceRows returns the weighted covariance matrix of the rows read
+// from rr, computed with the two-pass algorithm over blocks of covBlockRows
+// rows, so that the data are never held whole. The blocks are grouped into
+// chunks of covCheckBlocks blocks, whose partial results are combined in
+// order, and which may be processed in parallel as configured by Parallel.
+// covarianceRows stops and returns the error of ctx if ctx is done, checking
+// it before each chunk, or the error of rr if a read fails, in which case cov
+// is left unchanged. The arguments must have been validated.
+func covarianceRows(ctx context.Context, cov *mat64.Dense, rr RowReader, wts []float64) (*mat64.Dense, error) {
+	r, c := rr.Dims()
+	weight := func(i int) float64 {
+		if wts == nil {
+			return 1
+		}
+		return wts[i]
+	}
+
+	var (
+		bufs [reduceRound][]float64
+		errs [reduceRound]error
+		err  error
+	)
+	// blocks calls f with each block of rows of the chunk [lo, hi), read
+	// into the buffer for slot.
+	blocks := func(slot, lo, hi int, f func(block *mat64.Dense, start int)) {
+		if errs[slot] = ctx.Err(); errs[slot] != nil {
+			return
+		}
+		if bufs[slot] == nil {
+			bufs[slot] = make([]float64, covBlockRows*c)
+		}
+		buf := bufs[slot]
+		for start := lo; start < hi; start += covBlockRows {
+			b := covBlockRows
+			if start+b > hi {
+				b = hi - start
+			}
+			if errs[slot] = rr.ReadRows(buf[:b*c], start, b); errs[slot] != nil {
+				return
+			}
+			f(mat64.NewDense(b, c, buf[:b*c]), start)
+		}
+	}
+	chunkRows := covBlockRows * covCheckBlocks
+
+	// The partial results hold the weighted sums of the columns followed
+	// by the sum of the weights.
+	var partial columnPartials
+	mean := make([]float64, c)
+	var n float64
+	reduceChunks(r, chunkRows, c, func(slot, lo, hi int) {
+		p := partial.get(slot, c+1)
+		blocks(slot, lo, hi, func(block *mat64.Dense, start int) {
+			rows, _ := block.Dims()
+			for i := 0; i < rows; i++ {
+				w := weight(start + i)
+				for j, v := range block.RawRowView(i) {
+					p[j] += w * v
+				}
+				p[c] += w
+			}
+		})
+	}, func(slot int) {
+		if err == nil {
+			err = errs[slot]
+		}
+		floats.Add(mean, partial[slot][:c])
+		n += partial[slot][c]
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	floats.Scale(1/n, mean)
+
+	// Accumulate the upper triangle of the co-moments by rank one updates
+	// with the centered and weighted rows.
+	acc := make([]float64, c*c)
+	reduceChunks(r, chunkRows, c*c, func(slot, lo, hi int) {
+		p := partial.get(slot, c*c)
+		blocks(slot, lo, hi, func(block *mat64.Dense, start int) {
+			rows, _ := block.Dims()
+			for i := 0; i < rows; i++ {
+				row := block.RawRowView(i)
+				floats.Sub(row, mean)
+				floats.Scale(math.Sqrt(weight(start+i)), row)
+				for j, v := range row {
+					if v != 0 {
+						floats.AddScaled(p[j*c+j:(j+1)*c], v, row[j:])
+					}
+				}
+			}
+		})
+	}, func(slot int) {
+		if err == nil {
+			err = errs[slot]
+		}
+		floats.Add(acc, partial[slot])
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	for j := 0; j < c; j++ {
+		for k := j; k < c; k++ {
+			v := acc[j*c+k] / (n - 1)
+			acc[j*c+k] = v
+			acc[k*c+j] = v
+		}
+	}
+	if cov == nil {
+		return mat64.NewDense(c, c, acc), nil
+	}
+	cov.Copy(mat64.NewDense(c, c, acc))
+	return cov, nil
 }
 
 // CorrelationMatrix calculates a correlation matrix from a matrix of data,
@@ -88,23 +354,397 @@ func CovarianceMatrix(cov *mat64.Dense, x mat64.Matrix, wts []float64) *mat64.De
 // input data matrix x. If c is nil, then a new matrix with appropriate size will
 // be constructed.  If c is not nil, it should be a square matrix with the same
 // number of columns as the input data matrix x, and it will be used as the receiver
-// for the correlation data.  Weights cannot be negative.
+// for the correlation data.  Weights cannot be negative. CorrelationMatrixSym
+// returns the same matrix as a *mat64.SymDense.
 func CorrelationMatrix(c *mat64.Dense, x mat64.Matrix, wts []float64) *mat64.Dense {
-
-	// TODO(jonlawlor): indicate that the resulting matrix is symmetric, and change
-	// the returned type from a *mat.Dense to a *mat.Symmetric.
-
 	// This will panic if the sizes don't match, or if wts is the wrong size.
 	c = CovarianceMatrix(c, x, wts)
 	covToCorr(c)
 	return c
 }
 
-// covToCorr converts a covariance matrix to a correlation matrix.
-func covToCorr(c *mat64.Dense) {
+// CrossCovarianceMatrix calculates the p×q matrix of the weighted covariances
+// between the columns of the r×p matrix x and those of the r×q matrix y, the
+// off-diagonal block of the covariance matrix of the columns of both,
+// computed without the diagonal blocks. Its element (i, j) is the covariance
+// of the column i of x and the column j of y as computed by Covariance, and
+// the weights are as for CovarianceMatrix. x and y are not modified.
+//
+// If dst is nil, a new matrix is allocated, otherwise it must be p×q.
+// CrossCovarianceMatrix panics if x and y have different numbers of rows.
+func CrossCovarianceMatrix(dst *mat64.Dense, x, y mat64.Matrix, wts []float64) *mat64.Dense {
+	r, p := x.Dims()
+	ry, q := y.Dims()
+	if ry != r {
+		panic(mat64.ErrShape)
+	}
+	if err := validateCovarianceDims(nil, r, p, wts); err != nil {
+		panic(err)
+	}
+	if dst == nil {
+		dst = mat64.NewDense(p, q, nil)
+	} else if dr, dc := dst.Dims(); dr != p || dc != q {
+		panic(mat64.ErrShape)
+	}
+	xc, n := centered(x, wts)
+	yc, _ := centered(y, wts)
+	dst.MulTrans(xc, true, yc, false)
+	dst.Scale(1/(n-1), dst)
+	return dst
+}
 
-	// TODO(jonlawlor): use a *mat64.Symmetric as input.
+// CrossCorrelationMatrix calculates the p×q matrix of the weighted
+// correlations between the columns of the r×p matrix x and those of the r×q
+// matrix y, the covariances computed by CrossCovarianceMatrix divided by the
+// standard deviations of the columns. Elements for constant columns are NaN.
+// dst and wts are as for CrossCovarianceMatrix.
+func CrossCorrelationMatrix(dst *mat64.Dense, x, y mat64.Matrix, wts []float64) *mat64.Dense {
+	dst = CrossCovarianceMatrix(dst, x, y, wts)
+	sx := ColumnVariances(nil, x, wts)
+	sy := ColumnVariances(nil, y, wts)
+	for i, vx := range sx {
+		row := dst.RawRowView(i)
+		for j, vy := range sy {
+			row[j] /= math.Sqrt(vx * vy)
+		}
+	}
+	return dst
+}
+
+// ExpWeightedCovarianceMatrix calculates the exponentially weighted covariance
+// matrix of the rows of x, in which the row i of the r rows has the weight
+//  w_i = λ^(r-1-i)
+// so that recent rows count more, as in the RiskMetrics model. The weighted
+// co-moments are normalized as the variance of an EWMA,
+//  C = \sum_i w_i (x_i - m)(x_i - m)^T / (\sum_i w_i - \sum_i w_i² / \sum_i w_i)
+// where m is the weighted mean of the rows. The normalization treats the
+// weights as reliability weights rather than frequencies, so that the result
+// does not depend on their scale, and for λ = 1 it is that of
+// CovarianceMatrix with nil weights. The weights are computed during the
+// accumulation rather than stored, and those that underflow for long series
+// are zero. The computation takes two passes over the rows.
+//
+// If cov is nil, then a new matrix with appropriate size will be constructed,
+// otherwise it must be square with as many rows as x has columns.
+// ExpWeightedCovarianceMatrix panics if lambda is not in (0, 1].
+func ExpWeightedCovarianceMatrix(cov *mat64.Dense, x mat64.Matrix, lambda float64) *mat64.Dense {
+	if err := ValidateCovarianceMatrix(cov, x, nil); err != nil {
+		panic(err)
+	}
+	if !(lambda > 0 && lambda <= 1) {
+		panic("stat: decay factor out of range")
+	}
+	r, c := x.Dims()
+	if cov == nil {
+		cov = mat64.NewDense(c, c, nil)
+	}
 
+	// The rows are visited from the last, whose weight is 1, so that each
+	// weight is that of the following row times λ.
+	mean := make([]float64, c)
+	var sumW, sumW2 float64
+	w := 1.0
+	for i := r - 1; i >= 0; i-- {
+		for j := range mean {
+			mean[j] += w * x.At(i, j)
+		}
+		sumW += w
+		sumW2 += w * w
+		w *= lambda
+	}
+	floats.Scale(1/sumW, mean)
+
+	// Accumulate the upper triangle of the co-moments by rank one updates
+	// with the centered rows.
+	acc := make([]float64, c*c)
+	row := make([]float64, c)
+	w = 1
+	for i := r - 1; i >= 0 && w > 0; i-- {
+		for j := range row {
+			row[j] = x.At(i, j) - mean[j]
+		}
+		for j, v := range row {
+			if v != 0 {
+				floats.AddScaled(acc[j*c+j:(j+1)*c], w*v, row[j:])
+			}
+		}
+		w *= lambda
+	}
+	norm := sumW - sumW2/sumW
+	for i := 0; i < c; i++ {
+		for j := i; j < c; j++ {
+			v := acc[i*c+j] / norm
+			cov.Set(i, j, v)
+			cov.Set(j, i, v)
+		}
+	}
+	return cov
+}
+
+// ExpWeightedCorrelationMatrix calculates the exponentially weighted
+// correlation matrix of the rows of x, the correlation matrix of the
+// covariance matrix computed by ExpWeightedCovarianceMatrix. cov and lambda
+// are as for ExpWeightedCovarianceMatrix.
+func ExpWeightedCorrelationMatrix(c *mat64.Dense, x mat64.Matrix, lambda float64) *mat64.Dense {
+	c = ExpWeightedCovarianceMatrix(c, x, lambda)
+	covToCorr(c)
+	return c
+}
+
+// CovarianceMatrixPairwise calculates the covariance matrix of the columns of
+// x, which may contain NaN values for missing observations, by pairwise
+// deletion: the element (i, j) is the weighted covariance of the columns i
+// and j, as computed by Covariance, over the rows in which neither is NaN,
+// using the weights of those rows only. The diagonal holds the variances of
+// the columns over their rows without NaN. Elements with fewer than two such
+// rows are NaN. Without NaN values, the result is that of CovarianceMatrix
+// up to floating point rounding.
+//
+// Since the elements are computed from different subsets of the rows, the
+// result need not be positive semi-definite, as a covariance matrix is, and
+// should be projected onto the positive semi-definite matrices, for example
+// by NearestPSD, before it is used where that is required.
+//
+// If counts is not nil, its element (i, j) is set to the number of rows used
+// for the element (i, j), and it must be square with as many rows as x has
+// columns. cov and wts are as for CovarianceMatrix. The computation takes
+// O(r c²) time for r×c data, since the elements are computed separately.
+func CovarianceMatrixPairwise(cov, counts *mat64.Dense, x mat64.Matrix, wts []float64) *mat64.Dense {
+	return pairwiseMatrix(cov, counts, x, wts, Covariance)
+}
+
+// CorrelationMatrixPairwise calculates the correlation matrix of the columns
+// of x by pairwise deletion, as for CovarianceMatrixPairwise. Each element is
+// the weighted correlation of the columns, as computed by Correlation, over
+// the rows in which neither is NaN, so that its magnitude is at most 1 up to
+// rounding. The diagonal holds ones, or NaN for columns with fewer than two
+// values that are not NaN or with all of them equal. The result need not be
+// positive semi-definite. c, counts and wts are as for
+// CovarianceMatrixPairwise.
+func CorrelationMatrixPairwise(c, counts *mat64.Dense, x mat64.Matrix, wts []float64) *mat64.Dense {
+	c = pairwiseMatrix(c, counts, x, wts, Correlation)
+	n, _ := c.Dims()
+	for i := 0; i < n; i++ {
+		if !math.IsNaN(c.At(i, i)) {
+			c.Set(i, i, 1)
+		}
+	}
+	return c
+}
+
+// pairwiseMatrix computes the symmetric matrix of the statistic fn of each
+// pair of columns of x over the rows in which neither is NaN.
+func pairwiseMatrix(dst, counts *mat64.Dense, x mat64.Matrix, wts []float64, fn func(x, y, weights []float64) float64) *mat64.Dense {
+	if err := ValidateCovarianceMatrix(dst, x, wts); err != nil {
+		panic(err)
+	}
+	r, c := x.Dims()
+	if counts != nil {
+		if cr, cc := counts.Dims(); cr != c || cc != c {
+			panic(mat64.ErrShape)
+		}
+	}
+	if dst == nil {
+		dst = mat64.NewDense(c, c, nil)
+	}
+	cols := make([][]float64, c)
+	for j := range cols {
+		cols[j] = make([]float64, r)
+		for i := range cols[j] {
+			cols[j][i] = x.At(i, j)
+		}
+	}
+	for i := 0; i < c; i++ {
+		for j := i; j < c; j++ {
+			xi, xj, w := dropNaN(cols[i], cols[j], wts)
+			v := math.NaN()
+			if len(xi) >= 2 {
+				v = fn(xi, xj, w)
+			}
+			dst.Set(i, j, v)
+			dst.Set(j, i, v)
+			if counts != nil {
+				counts.Set(i, j, float64(len(xi)))
+				counts.Set(j, i, float64(len(xi)))
+			}
+		}
+	}
+	return dst
+}
+
+// NearestPSD computes the positive semi-definite matrix nearest to the
+// symmetric matrix a in the Frobenius norm, by setting the negative
+// eigenvalues of a to zero, and stores it in dst. a is symmetrized by
+// averaging it with its transpose. If dst is nil, a new matrix is allocated,
+// otherwise it must have the dimensions of a, and it may be a itself.
+//
+// The result is suited to the repair of a covariance matrix computed by
+// CovarianceMatrixPairwise. The diagonal of a correlation matrix is not
+// preserved, and rescaling the result to a unit diagonal gives a valid
+// correlation matrix, which is close to but not the nearest to a.
+//
+// NearestPSD panics if a is not square or has an element that is NaN or
+// infinite.
+func NearestPSD(dst *mat64.Dense, a mat64.Matrix) *mat64.Dense {
+	dst, sym, n := symmetrized(dst, a)
+	projectPSD(sym, sym, n)
+	for i := 0; i < n; i++ {
+		copy(dst.RawRowView(i), sym[i*n:(i+1)*n])
+	}
+	return dst
+}
+
+// NearestCorrelationMatrix computes the correlation matrix, a positive
+// semi-definite matrix with a unit diagonal, nearest to the symmetric matrix
+// a in the Frobenius norm, and stores it in dst. It uses the alternating
+// projections method of Higham, "Computing the nearest correlation matrix —
+// a problem from finance", IMA Journal of Numerical Analysis, 22, 2002,
+// which alternates between the projection onto the positive semi-definite
+// matrices, as computed by NearestPSD, with Dykstra's correction, and the
+// projection onto the matrices with a unit diagonal. a is symmetrized by
+// averaging it with its transpose. If dst is nil, a new matrix is
+// allocated, otherwise it must have the dimensions of a, and it may be a
+// itself.
+//
+// The iteration stops when the relative changes of both iterates and the
+// relative difference between them are at most tol, or after maxIter
+// iterations, and NearestCorrelationMatrix returns the number of iterations
+// and whether the iteration converged. The result has an exactly unit
+// diagonal, and its eigenvalues may be negative by an amount of the order of
+// tol, so a tolerance well below that of a subsequent Cholesky factorization
+// should be used. The convergence is linear, and a few tens of iterations
+// are typical for tol = 1e-10.
+//
+// NearestCorrelationMatrix panics if a is not square or has an element that
+// is NaN or infinite, if tol is not positive or if maxIter is less than 1.
+func NearestCorrelationMatrix(dst *mat64.Dense, a mat64.Matrix, tol float64, maxIter int) (corr *mat64.Dense, iter int, converged bool) {
+	if !(tol > 0) {
+		panic("stat: non-positive tolerance")
+	}
+	if maxIter < 1 {
+		panic("stat: non-positive maximum iterations")
+	}
+	dst, y, n := symmetrized(dst, a)
+	x := make([]float64, n*n)
+	xOld := make([]float64, n*n)
+	yOld := make([]float64, n*n)
+	r := make([]float64, n*n)
+	// correction is Dykstra's correction of the projection onto the
+	// positive semi-definite matrices, which makes the iteration converge
+	// to the nearest matrix in the intersection rather than any matrix in
+	// it.
+	correction := make([]float64, n*n)
+	for iter = 1; iter <= maxIter; iter++ {
+		copy(xOld, x)
+		copy(yOld, y)
+		floats.SubTo(r, y, correction)
+		projectPSD(x, r, n)
+		floats.SubTo(correction, x, r)
+		copy(y, x)
+		for i := 0; i < n; i++ {
+			y[i*n+i] = 1
+		}
+		normX := floats.Norm(x, 2)
+		normY := floats.Norm(y, 2)
+		if floats.Distance(x, xOld, 2) <= tol*normX &&
+			floats.Distance(y, yOld, 2) <= tol*normY &&
+			floats.Distance(y, x, 2) <= tol*normY {
+			converged = true
+			break
+		}
+	}
+	if iter > maxIter {
+		iter = maxIter
+	}
+	for i := 0; i < n; i++ {
+		copy(dst.RawRowView(i), y[i*n:(i+1)*n])
+	}
+	return dst, iter, converged
+}
+
+// symmetrized checks that a is square and finite and that dst is nil or of
+// the dimensions of a, and returns dst or a new matrix if it is nil, along
+// with the average of a and its transpose in row-major order and the order
+// of a.
+func symmetrized(dst *mat64.Dense, a mat64.Matrix) (*mat64.Dense, []float64, int) {
+	n, c := a.Dims()
+	if n != c {
+		panic(mat64.ErrShape)
+	}
+	if dst == nil {
+		dst = mat64.NewDense(n, n, nil)
+	} else if r, c := dst.Dims(); r != n || c != n {
+		panic(mat64.ErrShape)
+	}
+	sym := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			v := (a.At(i, j) + a.At(j, i)) / 2
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				panic("stat: non-finite matrix element")
+			}
+			sym[i*n+j] = v
+		}
+	}
+	return dst, sym, n
+}
+
+// projectPSD stores in dst the projection of the n×n symmetric matrix a onto
+// the positive semi-definite matrices, with the negative eigenvalues of a
+// set to zero. Both matrices are in row-major order, and dst may be a.
+func projectPSD(dst, a []float64, n int) {
+	vals, vecs := symEigen(append([]float64(nil), a...), n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			var v float64
+			for k, l := range vals {
+				if l > 0 {
+					v += vecs[i*n+k] * l * vecs[j*n+k]
+				}
+			}
+			dst[i*n+j] = v
+			dst[j*n+i] = v
+		}
+	}
+}
+
+// CorrelationMatrixSym is like CorrelationMatrix, but stores the correlation
+// matrix in a *mat64.SymDense. If c is nil, a new matrix is allocated,
+// otherwise c must have as many rows as x has columns.
+func CorrelationMatrixSym(c *mat64.SymDense, x mat64.Matrix, wts []float64) *mat64.SymDense {
+	// This will panic if the sizes don't match, or if wts is the wrong size.
+	c = CovarianceMatrixSym(c, x, wts)
+	covToCorrSym(c)
+	return c
+}
+
+// SpearmanMatrix calculates the matrix of the Spearman rank correlations
+// between the columns of x, the correlation matrix of the ranks of each
+// column as computed by Spearman. c and wts are as for CorrelationMatrix.
+func SpearmanMatrix(c *mat64.Dense, x mat64.Matrix, wts []float64) *mat64.Dense {
+	r, cols := x.Dims()
+	ranks := mat64.NewDense(r, cols, nil)
+	col := make([]float64, r)
+	for j := 0; j < cols; j++ {
+		for i := range col {
+			col[i] = x.At(i, j)
+		}
+		for i, v := range averageRanks(col) {
+			ranks.Set(i, j, v)
+		}
+	}
+	return CorrelationMatrix(c, ranks, wts)
+}
+
+// symSetter is a symmetric matrix whose elements can be set, such as a
+// *mat64.SymDense.
+type symSetter interface {
+	mat64.Symmetric
+	SetSym(i, j int, v float64)
+}
+
+// covToCorr converts a covariance matrix to a correlation matrix.
+func covToCorr(c *mat64.Dense) {
 	r, _ := c.Dims()
 
 	s := make([]float64, r)
@@ -130,9 +770,6 @@ func covToCorr(c *mat64.Dense) {
 // to the covariance.  It will panic if len(sigma) is not equal to the
 // number of rows in the correlation matrix.
 func corrToCov(c *mat64.Dense, sigma []float64) {
-
-	// TODO(jonlawlor): use a *mat64.Symmetric as input.
-
 	r, _ := c.Dims()
 
 	if r != len(sigma) {
@@ -152,3 +789,39 @@ func corrToCov(c *mat64.Dense, sigma []float64) {
 		}
 	}
 }
+
+// covToCorrSym is like covToCorr for a symmetric matrix, scaling only the
+// elements on and above the diagonal.
+func covToCorrSym(c symSetter) {
+	r := c.Symmetric()
+
+	s := make([]float64, r)
+	for i := 0; i < r; i++ {
+		s[i] = 1 / math.Sqrt(c.At(i, i))
+	}
+	for i, sx := range s {
+		// Ensure that the diagonal has exactly ones.
+		c.SetSym(i, i, 1)
+		for j := i + 1; j < r; j++ {
+			c.SetSym(i, j, c.At(i, j)*sx*s[j])
+		}
+	}
+}
+
+// corrToCovSym is like corrToCov for a symmetric matrix, scaling only the
+// elements on and above the diagonal.
+func corrToCovSym(c symSetter, sigma []float64) {
+	r := c.Symmetric()
+
+	if r != len(sigma) {
+		panic(mat64.ErrShape)
+	}
+
+	for i, sx := range sigma {
+		// Ensure that the diagonal has exactly sigma squared.
+		c.SetSym(i, i, sx*sx)
+		for j := i + 1; j < r; j++ {
+			c.SetSym(i, j, c.At(i, j)*sx*sigma[j])
+		}
+	}
+}
@@ -5,9 +5,11 @@
 package stat
 
 import (
+	"context"
 	"math"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/gonum/floats"
 	"github.com/gonum/matrix/mat64"
@@ -190,6 +192,28 @@ func TestCorrelationMatrix(t *testing.T) {
 	}
 }
 
+func TestSpearmanMatrix(t *testing.T) {
+	x := mat64.NewDense(5, 3, []float64{
+		8, 10, 1,
+		-3, 15, 2,
+		7, 4, 3,
+		8, 5, 4,
+		-4, -1, 5,
+	})
+	wts := []float64{1, 3, 1, 2, 2}
+	for _, w := range [][]float64{nil, wts} {
+		m := SpearmanMatrix(nil, x, w)
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				want := Spearman(x.Col(nil, i), x.Col(nil, j), w)
+				if math.Abs(m.At(i, j)-want) > 1e-14 {
+					t.Errorf("SpearmanMatrix mismatch at (%d, %d): got %v, want %v", i, j, m.At(i, j), want)
+				}
+			}
+		}
+	}
+}
+
 func TestCorrCov(t *testing.T) {
 	// test both Cov2Corr and Cov2Corr
 	for i, test := range []struct {
@@ -255,6 +279,681 @@ func TestCorrCov(t *testing.T) {
 	}
 }
 
+func TestCovarianceMatrixCtx(t *testing.T) {
+	x := randMat(1000, 5)
+	wts := make([]float64, 1000)
+	for i := range wts {
+		wts[i] = rand.Float64()
+	}
+	for _, w := range [][]float64{nil, wts} {
+		want := CovarianceMatrix(nil, x, w)
+
+		// A context that is never cancelled must not change the result.
+		got, err := CovarianceMatrixCtx(context.Background(), nil, x, w)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got.Equals(want) {
+			t.Errorf("background context changed result: got %v, want %v", got, want)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		dst := mat64.NewDense(5, 5, nil)
+		got, err = CovarianceMatrixCtx(ctx, dst, x, w)
+		cancel()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != dst {
+			t.Errorf("result not stored in destination")
+		}
+		if !got.EqualsApprox(want, 1e-12) {
+			t.Errorf("cancellable context mismatch: got %v, want %v", got, want)
+		}
+	}
+
+	// A cancelled context returns at once and leaves the destination alone.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dst := mat64.NewDense(5, 5, nil)
+	got, err := CovarianceMatrixCtx(ctx, dst, x, nil)
+	if got != nil || err != context.Canceled {
+		t.Errorf("cancelled context: got (%v, %v), want (nil, %v)", got, err, context.Canceled)
+	}
+	if !dst.Equals(mat64.NewDense(5, 5, nil)) {
+		t.Errorf("destination modified on cancellation")
+	}
+
+	if !Panics(func() { CovarianceMatrixCtx(ctx, mat64.NewDense(1, 1, nil), x, nil) }) {
+		t.Errorf("CovarianceMatrixCtx did not panic with mismatched destination")
+	}
+}
+
+func TestCovarianceMatrixCtxPrompt(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping timing test in short mode")
+	}
+	x := randMat(covBlockRows*covCheckBlocks*200, 50)
+
+	// Time a stretch of blocks between two context checks.
+	sub := mat64.DenseCopyOf(x.(*mat64.Dense).View(0, 0, covBlockRows*covCheckBlocks, 50))
+	start := time.Now()
+	CovarianceMatrix(nil, sub, nil)
+	interval := time.Since(start)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := CovarianceMatrixCtx(ctx, nil, x, nil)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancelled := time.Now()
+	cancel()
+	err := <-done
+	elapsed := time.Since(cancelled)
+	if err != context.Canceled {
+		t.Fatalf("unexpected error: got %v, want %v", err, context.Canceled)
+	}
+	// Allow for a few check intervals and scheduling delays.
+	if limit := 5*interval + 50*time.Millisecond; elapsed > limit {
+		t.Errorf("slow return after cancellation: took %v, want at most %v", elapsed, limit)
+	}
+}
+
+func TestCovarianceMatrixAlgorithms(t *testing.T) {
+	defer func(alg CovarianceAlgorithm) { CovarianceMatrixAlgorithm = alg }(CovarianceMatrixAlgorithm)
+	rnd := rand.New(rand.NewSource(1))
+	for _, dims := range [][2]int{
+		{2, 1}, {5, 3}, {10, 150}, {covBlockRows + 1, 4}, {300, covColumnBlock + 5}, {3 * covBlockRows, 2 * covColumnBlock},
+	} {
+		r, c := dims[0], dims[1]
+		x := mat64.NewDense(r, c, nil)
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				x.Set(i, j, float64(j)+rnd.NormFloat64())
+			}
+		}
+		wts := make([]float64, r)
+		for i := range wts {
+			wts[i] = rnd.Float64()
+		}
+		for _, w := range [][]float64{nil, wts} {
+			CovarianceMatrixAlgorithm = ProductCovariance
+			want := CovarianceMatrix(nil, x, w)
+			for _, alg := range []CovarianceAlgorithm{AutoCovariance, StreamingCovariance, BlockedCovariance} {
+				CovarianceMatrixAlgorithm = alg
+				for _, m := range []mat64.Matrix{x, transposed{transposedCopy(x)}} {
+					got := CovarianceMatrix(mat64.NewDense(c, c, nil), m, w)
+					if !got.EqualsApprox(want, 1e-13) {
+						t.Errorf("%d×%d algorithm %d: covariance mismatch", r, c, alg)
+					}
+					for i := 0; i < c; i++ {
+						for j := 0; j < i; j++ {
+							if got.At(i, j) != got.At(j, i) {
+								t.Errorf("%d×%d algorithm %d: result not symmetric", r, c, alg)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCovarianceMatrixSym(t *testing.T) {
+	defer func(alg CovarianceAlgorithm) { CovarianceMatrixAlgorithm = alg }(CovarianceMatrixAlgorithm)
+	rnd := rand.New(rand.NewSource(1))
+	for _, dims := range [][2]int{
+		{2, 1}, {5, 3}, {covBlockRows + 1, 4}, {300, covColumnBlock + 5},
+	} {
+		r, c := dims[0], dims[1]
+		x := mat64.NewDense(r, c, nil)
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				x.Set(i, j, float64(j)+rnd.NormFloat64())
+			}
+		}
+		wts := make([]float64, r)
+		for i := range wts {
+			wts[i] = rnd.Float64()
+		}
+		for _, w := range [][]float64{nil, wts} {
+			for _, alg := range []CovarianceAlgorithm{ProductCovariance, StreamingCovariance, BlockedCovariance} {
+				CovarianceMatrixAlgorithm = alg
+				for _, test := range []struct {
+					name  string
+					dense *mat64.Dense
+					sym   *mat64.SymDense
+				}{
+					{"covariance", CovarianceMatrix(nil, x, w), CovarianceMatrixSym(nil, x, w)},
+					{"covariance into", CovarianceMatrix(nil, x, w), CovarianceMatrixSym(mat64.NewSymDense(c, nil), x, w)},
+					{"correlation", CorrelationMatrix(nil, x, w), CorrelationMatrixSym(nil, x, w)},
+					{"correlation into", CorrelationMatrix(nil, x, w), CorrelationMatrixSym(mat64.NewSymDense(c, nil), x, w)},
+				} {
+					if !symAgrees(test.sym, test.dense) {
+						t.Errorf("%d×%d algorithm %d %s: mismatch", r, c, alg, test.name)
+					}
+				}
+			}
+		}
+	}
+
+	x := mat64.NewDense(3, 2, []float64{1, 2, 3, 5, 4, 1})
+	for _, f := range []func(){
+		func() { CovarianceMatrixSym(mat64.NewSymDense(3, nil), x, nil) },
+		func() { CorrelationMatrixSym(mat64.NewSymDense(1, nil), x, nil) },
+		func() { CovarianceMatrixSym(nil, x, []float64{1, 1}) },
+		func() { corrToCovSym(mat64.NewSymDense(2, nil), []float64{1}) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestCovarianceMatrixCentering(t *testing.T) {
+	// CovarianceMatrix must agree with the product of the transposed data
+	// centered by Mean, as computed before CenterColumns was shared.
+	defer func(alg CovarianceAlgorithm) { CovarianceMatrixAlgorithm = alg }(CovarianceMatrixAlgorithm)
+	rnd := rand.New(rand.NewSource(1))
+	for _, dims := range [][2]int{{5, 3}, {100, 10}, {300, covColumnBlock + 5}} {
+		r, c := dims[0], dims[1]
+		x := mat64.NewDense(r, c, nil)
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				x.Set(i, j, float64(j)+rnd.NormFloat64())
+			}
+		}
+		wts := make([]float64, r)
+		for i := range wts {
+			wts[i] = rnd.Float64()
+		}
+		for _, w := range [][]float64{nil, wts} {
+			var xt mat64.Dense
+			xt.TCopy(x)
+			n := float64(r)
+			if w != nil {
+				n = floats.Sum(w)
+			}
+			for i := 0; i < c; i++ {
+				v := xt.RawRowView(i)
+				floats.AddConst(-Mean(v, w), v)
+				if w != nil {
+					for k := range v {
+						v[k] *= math.Sqrt(w[k])
+					}
+				}
+			}
+			var want mat64.Dense
+			want.MulTrans(&xt, false, &xt, true)
+			want.Scale(1/(n-1), &want)
+			for _, alg := range []CovarianceAlgorithm{ProductCovariance, BlockedCovariance} {
+				CovarianceMatrixAlgorithm = alg
+				if got := CovarianceMatrix(nil, x, w); !got.EqualsApprox(&want, 1e-14) {
+					t.Errorf("%d×%d algorithm %d: covariance changed", r, c, alg)
+				}
+			}
+		}
+	}
+}
+
+// symAgrees returns whether the elements of sym on and above the diagonal
+// equal those of dense, and the elements below the diagonal agree with those
+// of dense up to the rounding of the order of scaling.
+func symAgrees(sym *mat64.SymDense, dense *mat64.Dense) bool {
+	n := sym.Symmetric()
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			got, want := sym.At(i, j), dense.At(i, j)
+			if j >= i && got != want {
+				return false
+			}
+			if !floats.EqualWithinAbsOrRel(got, want, 1e-15, 1e-15) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// countingSym counts the elements set in a *mat64.SymDense.
+type countingSym struct {
+	*mat64.SymDense
+	sets int
+}
+
+func (s *countingSym) SetSym(i, j int, v float64) {
+	s.sets++
+	s.SymDense.SetSym(i, j, v)
+}
+
+func TestCrossCovarianceMatrix(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r, p, q := 50, 3, 4
+	x := mat64.NewDense(r, p, nil)
+	y := mat64.NewDense(r, q, nil)
+	both := mat64.NewDense(r, p+q, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < p; j++ {
+			x.Set(i, j, rnd.NormFloat64())
+			both.Set(i, j, x.At(i, j))
+		}
+		for j := 0; j < q; j++ {
+			y.Set(i, j, float64(j)*x.At(i, j%p)+rnd.NormFloat64())
+			both.Set(i, p+j, y.At(i, j))
+		}
+	}
+	wts := make([]float64, r)
+	for i := range wts {
+		wts[i] = rnd.Float64()
+	}
+	xOrig := mat64.DenseCopyOf(x)
+	yOrig := mat64.DenseCopyOf(y)
+	for _, w := range [][]float64{nil, wts} {
+		// The result is the off-diagonal block of the covariance matrix of
+		// the concatenated columns.
+		full := CovarianceMatrix(nil, both, w)
+		fullCorr := CorrelationMatrix(nil, both, w)
+		cov := CrossCovarianceMatrix(nil, x, y, w)
+		corr := CrossCorrelationMatrix(mat64.NewDense(p, q, nil), x, y, w)
+		if rows, cols := cov.Dims(); rows != p || cols != q {
+			t.Fatalf("unexpected dimensions %d×%d", rows, cols)
+		}
+		for i := 0; i < p; i++ {
+			for j := 0; j < q; j++ {
+				if want := full.At(i, p+j); math.Abs(cov.At(i, j)-want) > 1e-14 {
+					t.Errorf("covariance mismatch at (%d, %d). Want %v, got %v", i, j, want, cov.At(i, j))
+				}
+				if want := fullCorr.At(i, p+j); math.Abs(corr.At(i, j)-want) > 1e-14 {
+					t.Errorf("correlation mismatch at (%d, %d). Want %v, got %v", i, j, want, corr.At(i, j))
+				}
+			}
+		}
+	}
+	if !x.Equals(xOrig) || !y.Equals(yOrig) {
+		t.Errorf("input modified")
+	}
+	// The cross-covariance of x with itself is its covariance matrix.
+	if got, want := CrossCovarianceMatrix(nil, x, x, nil), CovarianceMatrix(nil, x, nil); !floats.EqualApprox(got.RawMatrix().Data, want.RawMatrix().Data, 1e-14) {
+		t.Errorf("cross-covariance with itself mismatch.\nWant %v\nGot %v", want.RawMatrix().Data, got.RawMatrix().Data)
+	}
+
+	for i, fn := range []func(){
+		func() { CrossCovarianceMatrix(nil, x, mat64.NewDense(r-1, q, nil), nil) },
+		func() { CrossCovarianceMatrix(mat64.NewDense(q, p, nil), x, y, nil) },
+		func() { CrossCorrelationMatrix(nil, x, y, wts[1:]) },
+		func() { CrossCovarianceMatrix(nil, x, y, append([]float64{-1}, wts[1:]...)) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestExpWeightedCovarianceMatrix(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r, c := 60, 4
+	x := mat64.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			x.Set(i, j, rnd.NormFloat64()+float64(j)*x.At(i, 0))
+		}
+	}
+	for _, lambda := range []float64{0.5, 0.94, 0.999} {
+		// The result is the weighted covariance matrix with the explicit
+		// weights, rescaled from frequency to reliability weights.
+		wts := make([]float64, r)
+		var sumW, sumW2 float64
+		for i := range wts {
+			wts[i] = math.Pow(lambda, float64(r-1-i))
+			sumW += wts[i]
+			sumW2 += wts[i] * wts[i]
+		}
+		want := CovarianceMatrix(nil, x, wts)
+		want.Scale((sumW-1)/(sumW-sumW2/sumW), want)
+		got := ExpWeightedCovarianceMatrix(nil, x, lambda)
+		if !floats.EqualApprox(got.RawMatrix().Data, want.RawMatrix().Data, 1e-12) {
+			t.Errorf("λ = %v: covariance mismatch.\nWant %v\nGot %v", lambda, want.RawMatrix().Data, got.RawMatrix().Data)
+		}
+		corr := ExpWeightedCorrelationMatrix(mat64.NewDense(c, c, nil), x, lambda)
+		wantCorr := CorrelationMatrix(nil, x, wts)
+		if !floats.EqualApprox(corr.RawMatrix().Data, wantCorr.RawMatrix().Data, 1e-12) {
+			t.Errorf("λ = %v: correlation mismatch.\nWant %v\nGot %v", lambda, wantCorr.RawMatrix().Data, corr.RawMatrix().Data)
+		}
+	}
+
+	// Equal weights give CovarianceMatrix, and weights approaching them give
+	// results approaching it.
+	want := CovarianceMatrix(nil, x, nil)
+	if got := ExpWeightedCovarianceMatrix(nil, x, 1); !floats.EqualApprox(got.RawMatrix().Data, want.RawMatrix().Data, 1e-13) {
+		t.Errorf("λ = 1: covariance mismatch.\nWant %v\nGot %v", want.RawMatrix().Data, got.RawMatrix().Data)
+	}
+	prev := math.Inf(1)
+	for _, lambda := range []float64{0.9, 0.99, 0.999, 0.9999} {
+		got := ExpWeightedCovarianceMatrix(nil, x, lambda)
+		var diff float64
+		for i, v := range got.RawMatrix().Data {
+			diff = math.Max(diff, math.Abs(v-want.RawMatrix().Data[i]))
+		}
+		if diff >= prev {
+			t.Errorf("λ = %v: difference from equal weights %v did not decrease from %v", lambda, diff, prev)
+		}
+		prev = diff
+	}
+	if prev > 1e-2 {
+		t.Errorf("λ = 0.9999: difference from equal weights %v too large", prev)
+	}
+
+	// Weights that underflow for long series are harmless.
+	long := mat64.NewDense(5000, 2, nil)
+	for i := 0; i < 5000; i++ {
+		long.Set(i, 0, rnd.NormFloat64())
+		long.Set(i, 1, 2*long.At(i, 0)+rnd.NormFloat64())
+	}
+	corr := ExpWeightedCorrelationMatrix(nil, long, 0.8)
+	if v := corr.At(0, 1); math.IsNaN(v) || v < 0.5 || v > 1 {
+		t.Errorf("unexpected correlation %v of a long series", v)
+	}
+
+	for i, fn := range []func(){
+		func() { ExpWeightedCovarianceMatrix(nil, x, 0) },
+		func() { ExpWeightedCovarianceMatrix(nil, x, 1.1) },
+		func() { ExpWeightedCovarianceMatrix(mat64.NewDense(c, c+1, nil), x, 0.9) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestCovarianceMatrixPairwise(t *testing.T) {
+	nan := math.NaN()
+	rnd := rand.New(rand.NewSource(1))
+	r, c := 40, 4
+	full := mat64.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			full.Set(i, j, rnd.NormFloat64()+0.5*float64(j)*full.At(i, 0))
+		}
+	}
+	wts := make([]float64, r)
+	for i := range wts {
+		wts[i] = 0.5 + rnd.Float64()
+	}
+
+	// Without NaN values the results are those of the complete data.
+	for _, w := range [][]float64{nil, wts} {
+		want := CovarianceMatrix(nil, full, w)
+		got := CovarianceMatrixPairwise(nil, nil, full, w)
+		if !floats.EqualApprox(got.RawMatrix().Data, want.RawMatrix().Data, 1e-13) {
+			t.Errorf("covariance without NaN mismatch.\nWant %v\nGot %v", want.RawMatrix().Data, got.RawMatrix().Data)
+		}
+		want = CorrelationMatrix(nil, full, w)
+		got = CorrelationMatrixPairwise(nil, nil, full, w)
+		if !floats.EqualApprox(got.RawMatrix().Data, want.RawMatrix().Data, 1e-13) {
+			t.Errorf("correlation without NaN mismatch.\nWant %v\nGot %v", want.RawMatrix().Data, got.RawMatrix().Data)
+		}
+	}
+
+	// Scattered NaN values remove only the pairs they affect.
+	x := mat64.DenseCopyOf(full)
+	for i := 0; i < r; i++ {
+		x.Set(i, i%c, nan)
+	}
+	x.Set(0, 3, nan)
+	for _, w := range [][]float64{nil, wts} {
+		counts := mat64.NewDense(c, c, nil)
+		cov := CovarianceMatrixPairwise(nil, counts, x, w)
+		corr := CorrelationMatrixPairwise(mat64.NewDense(c, c, nil), nil, x, w)
+		for i := 0; i < c; i++ {
+			for j := 0; j < c; j++ {
+				var xi, xj, wij []float64
+				for k := 0; k < r; k++ {
+					a, b := x.At(k, i), x.At(k, j)
+					if math.IsNaN(a) || math.IsNaN(b) {
+						continue
+					}
+					xi = append(xi, a)
+					xj = append(xj, b)
+					if w != nil {
+						wij = append(wij, w[k])
+					}
+				}
+				if counts.At(i, j) != float64(len(xi)) {
+					t.Errorf("count mismatch at (%d, %d). Want %d, got %v", i, j, len(xi), counts.At(i, j))
+				}
+				if want := Covariance(xi, xj, wij); math.Abs(cov.At(i, j)-want) > 1e-14 {
+					t.Errorf("covariance mismatch at (%d, %d). Want %v, got %v", i, j, want, cov.At(i, j))
+				}
+				want := Correlation(xi, xj, wij)
+				if i == j {
+					want = 1
+				}
+				if math.Abs(corr.At(i, j)-want) > 1e-14 {
+					t.Errorf("correlation mismatch at (%d, %d). Want %v, got %v", i, j, want, corr.At(i, j))
+				}
+			}
+		}
+		if counts.At(0, 3) != 20 || counts.At(1, 1) != 30 {
+			t.Errorf("unexpected counts %v and %v", counts.At(0, 3), counts.At(1, 1))
+		}
+	}
+
+	// Columns without two complete pairs give NaN.
+	sparse := mat64.NewDense(3, 2, []float64{
+		1, nan,
+		2, 5,
+		nan, 6,
+	})
+	cov := CovarianceMatrixPairwise(nil, nil, sparse, nil)
+	if cov.At(0, 0) != 0.5 || cov.At(1, 1) != 0.5 || !math.IsNaN(cov.At(0, 1)) || !math.IsNaN(cov.At(1, 0)) {
+		t.Errorf("unexpected covariance of sparse data %v", cov.RawMatrix().Data)
+	}
+
+	for i, fn := range []func(){
+		func() { CovarianceMatrixPairwise(mat64.NewDense(c+1, c+1, nil), nil, x, nil) },
+		func() { CovarianceMatrixPairwise(nil, mat64.NewDense(c, c+1, nil), x, nil) },
+		func() { CorrelationMatrixPairwise(nil, nil, x, wts[1:]) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestNearestPSD(t *testing.T) {
+	// The eigenvalues of a are 3 and -1, with the eigenvector of 3 along
+	// (1, 1).
+	a := mat64.NewDense(2, 2, []float64{1, 2, 2, 1})
+	got := NearestPSD(nil, a)
+	if want := []float64{1.5, 1.5, 1.5, 1.5}; !floats.EqualApprox(got.RawMatrix().Data, want, 1e-14) {
+		t.Errorf("nearest PSD matrix mismatch. Want %v, got %v", want, got.RawMatrix().Data)
+	}
+
+	// A positive semi-definite matrix is unchanged.
+	cov := CovarianceMatrix(nil, randMat(30, 6), nil)
+	got = NearestPSD(nil, cov)
+	if !floats.EqualApprox(got.RawMatrix().Data, cov.RawMatrix().Data, 1e-13) {
+		t.Errorf("PSD matrix changed.\nWant %v\nGot %v", cov.RawMatrix().Data, got.RawMatrix().Data)
+	}
+
+	// An indefinite matrix of correlations, as may be computed by pairwise
+	// deletion, is repaired in place.
+	corr := mat64.NewDense(3, 3, []float64{
+		1, 0.9, 0.9,
+		0.9, 1, -0.9,
+		0.9, -0.9, 1,
+	})
+	orig := mat64.DenseCopyOf(corr)
+	NearestPSD(corr, corr)
+	vals, _ := symEigen(append([]float64(nil), corr.RawMatrix().Data...), 3)
+	for _, v := range vals {
+		if v < -1e-12 {
+			t.Errorf("negative eigenvalue %v after projection", v)
+		}
+	}
+	origVals, _ := symEigen(append([]float64(nil), orig.RawMatrix().Data...), 3)
+	if floats.Min(origVals) >= 0 {
+		t.Fatalf("test matrix is not indefinite: eigenvalues %v", origVals)
+	}
+	// The distance is the norm of the negative eigenvalues.
+	var dist, want float64
+	for i, v := range corr.RawMatrix().Data {
+		d := v - orig.RawMatrix().Data[i]
+		dist += d * d
+	}
+	for _, v := range origVals {
+		if v < 0 {
+			want += v * v
+		}
+	}
+	if math.Abs(dist-want) > 1e-12 {
+		t.Errorf("squared distance to the projection mismatch. Want %v, got %v", want, dist)
+	}
+
+	for i, fn := range []func(){
+		func() { NearestPSD(nil, mat64.NewDense(2, 3, nil)) },
+		func() { NearestPSD(mat64.NewDense(3, 3, nil), a) },
+		func() { NearestPSD(nil, mat64.NewDense(2, 2, []float64{1, math.NaN(), math.NaN(), 1})) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestNearestCorrelationMatrix(t *testing.T) {
+	// The example of Higham (2002), section 4.
+	a := mat64.NewDense(3, 3, []float64{
+		1, 1, 0,
+		1, 1, 1,
+		0, 1, 1,
+	})
+	corr, iter, ok := NearestCorrelationMatrix(nil, a, 1e-12, 1000)
+	if !ok || iter < 2 || iter > 1000 {
+		t.Fatalf("unexpected convergence %t after %d iterations", ok, iter)
+	}
+	want := []float64{
+		1, 0.7607, 0.1573,
+		0.7607, 1, 0.7607,
+		0.1573, 0.7607, 1,
+	}
+	if !floats.EqualApprox(corr.RawMatrix().Data, want, 1e-4) {
+		t.Errorf("nearest correlation matrix mismatch. Want %v, got %v", want, corr.RawMatrix().Data)
+	}
+	checkCorrelationMatrix(t, "Higham example", corr, 1e-10)
+
+	// An indefinite matrix of pairwise correlations is repaired in place,
+	// and the result is closer to it than the PSD projection rescaled to a
+	// unit diagonal.
+	c := 6
+	pairwise := mat64.NewDense(c, c, []float64{
+		1, 0.8, 0.8, 0.8, -0.5, 0,
+		0.8, 1, -0.6, 0.7, 0.1, 0.2,
+		0.8, -0.6, 1, 0.5, 0.3, -0.1,
+		0.8, 0.7, 0.5, 1, -0.9, 0.4,
+		-0.5, 0.1, 0.3, -0.9, 1, 0.6,
+		0, 0.2, -0.1, 0.4, 0.6, 1,
+	})
+	orig := mat64.DenseCopyOf(pairwise)
+	if vals, _ := symEigen(append([]float64(nil), orig.RawMatrix().Data...), c); floats.Min(vals) >= 0 {
+		t.Fatalf("test matrix is not indefinite: eigenvalues %v", vals)
+	}
+	_, iter, ok = NearestCorrelationMatrix(pairwise, pairwise, 1e-10, 1000)
+	if !ok {
+		t.Errorf("no convergence after %d iterations", iter)
+	}
+	checkCorrelationMatrix(t, "pairwise", pairwise, 1e-8)
+	rescaled := NearestPSD(nil, orig)
+	covToCorr(rescaled)
+	dist := func(m *mat64.Dense) float64 {
+		return floats.Distance(m.RawMatrix().Data, orig.RawMatrix().Data, 2)
+	}
+	if dist(pairwise) > dist(rescaled) {
+		t.Errorf("nearest correlation matrix at distance %v is farther than the rescaled projection at %v", dist(pairwise), dist(rescaled))
+	}
+
+	// A correlation matrix is unchanged after the first iteration.
+	valid := CorrelationMatrix(nil, randMat(20, 4), nil)
+	got, iter, ok := NearestCorrelationMatrix(nil, valid, 1e-12, 10)
+	if !ok || iter > 2 {
+		t.Errorf("correlation matrix: unexpected convergence %t after %d iterations", ok, iter)
+	}
+	if !floats.EqualApprox(got.RawMatrix().Data, valid.RawMatrix().Data, 1e-12) {
+		t.Errorf("correlation matrix changed")
+	}
+
+	// Too few iterations are reported.
+	if _, iter, ok := NearestCorrelationMatrix(nil, a, 1e-12, 2); ok || iter != 2 {
+		t.Errorf("want no convergence after 2 iterations, got %t after %d", ok, iter)
+	}
+
+	for i, fn := range []func(){
+		func() { NearestCorrelationMatrix(nil, mat64.NewDense(2, 3, nil), 1e-8, 10) },
+		func() { NearestCorrelationMatrix(nil, a, 0, 10) },
+		func() { NearestCorrelationMatrix(nil, a, 1e-8, 0) },
+		func() { NearestCorrelationMatrix(mat64.NewDense(2, 2, nil), a, 1e-8, 10) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+// checkCorrelationMatrix checks that m is symmetric with a unit diagonal and
+// eigenvalues not below -tol.
+func checkCorrelationMatrix(t *testing.T, name string, m *mat64.Dense, tol float64) {
+	n, _ := m.Dims()
+	for i := 0; i < n; i++ {
+		if m.At(i, i) != 1 {
+			t.Errorf("%s: diagonal element %d is %v", name, i, m.At(i, i))
+		}
+		for j := 0; j < i; j++ {
+			if m.At(i, j) != m.At(j, i) {
+				t.Errorf("%s: asymmetric at (%d, %d)", name, i, j)
+			}
+		}
+	}
+	vals, _ := symEigen(append([]float64(nil), m.RawMatrix().Data...), n)
+	if min := floats.Min(vals); min < -tol {
+		t.Errorf("%s: negative eigenvalue %v", name, min)
+	}
+}
+
+func TestCorrCovSym(t *testing.T) {
+	const n = 20
+	x := randMat(100, n)
+	cov := CovarianceMatrix(nil, x, nil)
+	sigma := make([]float64, n)
+	for i := range sigma {
+		sigma[i] = math.Sqrt(cov.At(i, i))
+	}
+	corr := mat64.DenseCopyOf(cov)
+	covToCorr(corr)
+
+	// The symmetric conversions set each element on and above the diagonal
+	// once, about half of the n² elements scaled by the Dense conversions.
+	sym := &countingSym{SymDense: CovarianceMatrixSym(nil, x, nil)}
+	covToCorrSym(sym)
+	if want := n * (n + 1) / 2; sym.sets != want {
+		t.Errorf("covToCorrSym set %d elements, want %d", sym.sets, want)
+	}
+	if !symAgrees(sym.SymDense, corr) {
+		t.Errorf("covToCorrSym mismatch")
+	}
+
+	sym.sets = 0
+	corrToCovSym(sym, sigma)
+	corrToCov(corr, sigma)
+	if want := n * (n + 1) / 2; sym.sets != want {
+		t.Errorf("corrToCovSym set %d elements, want %d", sym.sets, want)
+	}
+	if !symAgrees(sym.SymDense, corr) {
+		t.Errorf("corrToCovSym mismatch")
+	}
+}
+
 // benchmarks
 
 func randMat(r, c int) mat64.Matrix {
@@ -271,6 +970,11 @@ func benchmarkCovarianceMatrix(b *testing.B, m mat64.Matrix) {
 		CovarianceMatrix(nil, m, nil)
 	}
 }
+func benchmarkCovarianceMatrixProduct(b *testing.B, m mat64.Matrix) {
+	defer func(alg CovarianceAlgorithm) { CovarianceMatrixAlgorithm = alg }(CovarianceMatrixAlgorithm)
+	CovarianceMatrixAlgorithm = ProductCovariance
+	benchmarkCovarianceMatrix(b, m)
+}
 func benchmarkCovarianceMatrixWeighted(b *testing.B, m mat64.Matrix) {
 	r, _ := m.Dims()
 	wts := make([]float64, r)
@@ -325,6 +1029,22 @@ func BenchmarkCovarianceMatrixHugexSmall(b *testing.B) {
 	benchmarkCovarianceMatrix(b, x)
 }
 
+func BenchmarkCovarianceMatrixSmallxMediumProduct(b *testing.B) {
+	// 10 * 1000 elements
+	x := randMat(small, medium)
+	benchmarkCovarianceMatrixProduct(b, x)
+}
+func BenchmarkCovarianceMatrixMediumxSmallProduct(b *testing.B) {
+	// 1000 * 10 elements
+	x := randMat(medium, small)
+	benchmarkCovarianceMatrixProduct(b, x)
+}
+func BenchmarkCovarianceMatrixLargexSmallProduct(b *testing.B) {
+	// 1e5 * 10 elements
+	x := randMat(large, small)
+	benchmarkCovarianceMatrixProduct(b, x)
+}
+
 func BenchmarkCovarianceMatrixSmallxSmallWeighted(b *testing.B) {
 	// 10 * 10 elements
 	x := randMat(small, small)
@@ -406,6 +1126,20 @@ func BenchmarkCovToCorr(b *testing.B) {
 	}
 }
 
+func BenchmarkCovToCorrSym(b *testing.B) {
+	// generate a 10x10 covariance matrix
+	m := randMat(small, small)
+	c := CovarianceMatrixSym(nil, m, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cc := mat64.NewSymDense(small, nil)
+		cc.CopySym(c)
+		b.StartTimer()
+		covToCorrSym(cc)
+	}
+}
+
 func BenchmarkCorrToCov(b *testing.B) {
 	// generate a 10x10 correlation matrix
 	m := randMat(small, small)
@@ -422,3 +1156,14 @@ func BenchmarkCorrToCov(b *testing.B) {
 		corrToCov(cc, sigma)
 	}
 }
+
+func BenchmarkCovarianceMatrixCtxLargexSmall(b *testing.B) {
+	// 1e5 * 10 elements
+	x := randMat(large, small)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CovarianceMatrixCtx(ctx, nil, x, nil)
+	}
+}
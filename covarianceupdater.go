@@ -0,0 +1,145 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// CovarianceUpdater maintains the weighted covariance matrix of a stream of
+// observations, one row at a time, without storing them. Each update costs
+// O(c²) for c columns, rather than the O(n·c²) of recomputing the matrix with
+// CovarianceMatrix. The updates are the multivariate form of those of
+// Moments.
+type CovarianceUpdater struct {
+	n          int
+	sumWeights float64
+	mean       []float64
+	// scatter holds the upper triangle of the weighted sums of the products
+	// of the deviations from the means, in row-major order.
+	scatter []float64
+
+	dev []float64
+}
+
+// NewCovarianceUpdater returns a CovarianceUpdater with no observations for
+// rows of the given number of columns.
+func NewCovarianceUpdater(cols int) *CovarianceUpdater {
+	if cols <= 0 {
+		panic("stat: non-positive number of columns")
+	}
+	return &CovarianceUpdater{
+		mean:    make([]float64, cols),
+		scatter: make([]float64, cols*cols),
+		dev:     make([]float64, cols),
+	}
+}
+
+// AddRow adds the observation row with the given weight. The length of row
+// must equal the number of columns of u, and weight must not be negative.
+func (u *CovarianceUpdater) AddRow(row []float64, weight float64) {
+	u.check(row, weight)
+	if weight == 0 {
+		return
+	}
+	wa := u.sumWeights
+	u.n++
+	u.sumWeights += weight
+	floats.SubTo(u.dev, row, u.mean)
+	floats.AddScaled(u.mean, weight/u.sumWeights, u.dev)
+	u.rankOne(wa * weight / u.sumWeights)
+}
+
+// RemoveRow removes the observation row with the given weight, which must
+// have been added before, so that u can be used over a sliding window.
+// Removal reverses the update of AddRow up to floating point rounding, so the
+// rounding errors of a long series of additions and removals accumulate.
+func (u *CovarianceUpdater) RemoveRow(row []float64, weight float64) {
+	u.check(row, weight)
+	if weight == 0 {
+		return
+	}
+	if u.n <= 1 {
+		u.reset()
+		return
+	}
+	w := u.sumWeights
+	u.n--
+	u.sumWeights -= weight
+	floats.SubTo(u.dev, row, u.mean)
+	floats.AddScaled(u.mean, -weight/u.sumWeights, u.dev)
+	// The deviation from the means of the remaining observations.
+	floats.SubTo(u.dev, row, u.mean)
+	u.rankOne(-u.sumWeights * weight / w)
+}
+
+// rankOne adds alpha times the outer product of the deviation to the upper
+// triangle of the scatter matrix.
+func (u *CovarianceUpdater) rankOne(alpha float64) {
+	c := len(u.mean)
+	for j, v := range u.dev {
+		if v != 0 {
+			floats.AddScaled(u.scatter[j*c+j:(j+1)*c], alpha*v, u.dev[j:])
+		}
+	}
+}
+
+func (u *CovarianceUpdater) check(row []float64, weight float64) {
+	if len(row) != len(u.mean) {
+		panic(ErrLengthMismatch{Got: len(row), Want: len(u.mean)})
+	}
+	if weight < 0 {
+		panic("stat: negative weight")
+	}
+}
+
+func (u *CovarianceUpdater) reset() {
+	u.n = 0
+	u.sumWeights = 0
+	for i := range u.mean {
+		u.mean[i] = 0
+	}
+	for i := range u.scatter {
+		u.scatter[i] = 0
+	}
+}
+
+// Count returns the sum of the weights of the observations, which is their
+// number if all of the weights are 1.
+func (u *CovarianceUpdater) Count() float64 {
+	return u.sumWeights
+}
+
+// Mean returns the weighted means of the columns of the observations. If dst
+// is nil, a new slice is allocated, otherwise the means are stored in dst,
+// which must have length equal to the number of columns.
+func (u *CovarianceUpdater) Mean(dst []float64) []float64 {
+	dst = reuseFloats(dst, len(u.mean))
+	copy(dst, u.mean)
+	return dst
+}
+
+// Cov returns the weighted covariance matrix of the observations, as computed
+// by CovarianceMatrix. If dst is nil, a new matrix is allocated, otherwise
+// the matrix is stored in dst, which must be square with the number of
+// columns of u.
+func (u *CovarianceUpdater) Cov(dst *mat64.Dense) *mat64.Dense {
+	c := len(u.mean)
+	if dst == nil {
+		dst = mat64.NewDense(c, c, nil)
+	} else if r, cc := dst.Dims(); r != c || cc != c {
+		panic(mat64.ErrShape)
+	}
+	f := 1 / (u.sumWeights - 1)
+	for i := 0; i < c; i++ {
+		for j := i; j < c; j++ {
+			v := u.scatter[i*c+j] * f
+			dst.Set(i, j, v)
+			dst.Set(j, i, v)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,111 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestCovarianceUpdater(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const r, c = 300, 5
+	x := mat64.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			x.Set(i, j, 10*float64(j)+rnd.NormFloat64())
+		}
+	}
+	wts := make([]float64, r)
+	for i := range wts {
+		wts[i] = 0.5 + rnd.Float64()
+	}
+	for _, w := range [][]float64{nil, wts} {
+		weight := func(i int) float64 {
+			if w == nil {
+				return 1
+			}
+			return w[i]
+		}
+		u := NewCovarianceUpdater(c)
+		for i := 0; i < r; i++ {
+			u.AddRow(x.RawRowView(i), weight(i))
+			if i < 1 {
+				continue
+			}
+			var ww []float64
+			if w != nil {
+				ww = w[:i+1]
+			}
+			sub := x.View(0, 0, i+1, c)
+			want := CovarianceMatrix(nil, sub, ww)
+			if got := u.Cov(nil); !got.EqualsApprox(want, 1e-12) {
+				t.Errorf("after %d rows: covariance mismatch", i+1)
+			}
+			means := u.Mean(make([]float64, c))
+			for j, m := range ColumnMeans(nil, sub, ww) {
+				if d := means[j] - m; d > 1e-12 || d < -1e-12 {
+					t.Errorf("after %d rows: mean mismatch for column %d: got %v, want %v", i+1, j, means[j], m)
+				}
+			}
+		}
+
+		// Sliding window.
+		const window = 25
+		u = NewCovarianceUpdater(c)
+		dst := mat64.NewDense(c, c, nil)
+		for i := 0; i < r; i++ {
+			u.AddRow(x.RawRowView(i), weight(i))
+			if i >= window {
+				u.RemoveRow(x.RawRowView(i-window), weight(i-window))
+			}
+			if i < window-1 {
+				continue
+			}
+			var ww []float64
+			if w != nil {
+				ww = w[i-window+1 : i+1]
+			}
+			want := CovarianceMatrix(nil, x.View(i-window+1, 0, window, c), ww)
+			if got := u.Cov(dst); !got.EqualsApprox(want, 1e-10) {
+				t.Errorf("window ending at %d: covariance mismatch", i)
+			}
+		}
+		for i := r - window; i < r; i++ {
+			u.RemoveRow(x.RawRowView(i), weight(i))
+		}
+		if u.Count() != 0 {
+			t.Errorf("updater not empty after removing all rows: count %v", u.Count())
+		}
+	}
+
+	u := NewCovarianceUpdater(2)
+	for _, f := range []func(){
+		func() { NewCovarianceUpdater(0) },
+		func() { u.AddRow([]float64{1}, 1) },
+		func() { u.AddRow([]float64{1, 2}, -1) },
+		func() { u.RemoveRow([]float64{1, 2, 3}, 1) },
+		func() { u.Cov(mat64.NewDense(2, 3, nil)) },
+		func() { u.Mean(make([]float64, 1)) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func BenchmarkCovarianceUpdaterAddRow(b *testing.B) {
+	const c = 50
+	x := randMat(1000, c).(*mat64.Dense)
+	u := NewCovarianceUpdater(c)
+	dst := mat64.NewDense(c, c, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.AddRow(x.RawRowView(i%1000), 1)
+		u.Cov(dst)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// DistanceCovariance returns the unbiased estimate of the squared distance
+// covariance of the paired sample (x_i, y_i) of Székely and Rizzo, "Partial
+// distance correlation with methods for dissimilarities", Annals of
+// Statistics, 2014,
+//  Ω = 1/(n(n-3)) \sum_{i≠j} Ã_ij B̃_ij
+// where Ã and B̃ are the U-centered matrices of the distances
+// a_ij = |x_i - x_j| and b_ij = |y_i - y_j|,
+//  Ã_ij = a_ij - a_i·/(n-2) - a_·j/(n-2) + a_··/((n-1)(n-2))
+// The population distance covariance is zero if and only if x and y are
+// independent, and being unbiased the estimate may be negative.
+//
+// The sums are computed without forming the distance matrices by the
+// algorithm of Huo and Székely, "Fast computing for distance covariance",
+// Technometrics, 2016, in O(n log n) time.
+//
+// DistanceCovariance panics if the lengths of x and y differ or if there are
+// fewer than 4 observations.
+func DistanceCovariance(x, y []float64) float64 {
+	checkLengths(x, y)
+	if len(x) < 4 {
+		panic("stat: too few samples for distance covariance")
+	}
+	return distanceCov(x, y, distanceSums(x), distanceSums(y))
+}
+
+// DistanceCorrelation returns the bias-corrected distance correlation of
+// the paired sample (x_i, y_i) of Székely and Rizzo,
+//  R = Ω(x, y) / √(Ω(x, x) Ω(y, y))
+// where Ω is the unbiased squared distance covariance computed by
+// DistanceCovariance. Unlike Correlation, which measures linear dependence,
+// the population distance correlation is zero only if x and y are
+// independent, so it detects dependence such as that of y = x² on x
+// symmetric about zero. R estimates the square of the population distance
+// correlation without bias, and may be slightly negative under independence.
+// It is 0 if the denominator is not positive, as for a constant x or y, as
+// bcdcor in the R package energy.
+//
+// DistanceCorrelation takes O(n log n) time, and panics if the lengths of x
+// and y differ or if there are fewer than 4 observations.
+func DistanceCorrelation(x, y []float64) float64 {
+	checkLengths(x, y)
+	if len(x) < 4 {
+		panic("stat: too few samples for distance covariance")
+	}
+	ax := distanceSums(x)
+	by := distanceSums(y)
+	xx := distanceCov(x, x, ax, ax)
+	yy := distanceCov(y, y, by, by)
+	if !(xx*yy > 0) {
+		return 0
+	}
+	return distanceCov(x, y, ax, by) / math.Sqrt(xx*yy)
+}
+
+// distanceCov returns the unbiased squared distance covariance of x and y
+// given the row sums ax and by of their distance matrices, using
+//  n(n-3) Ω = T_1 - 2 T_2/(n-2) + T_3/((n-1)(n-2))
+// with T_1 = \sum_{i≠j} a_ij b_ij, T_2 = \sum_i a_i· b_i· and T_3 = a_·· b_··.
+func distanceCov(x, y, ax, by []float64) float64 {
+	n := float64(len(x))
+	var t2, sa, sb float64
+	for i, a := range ax {
+		t2 += a * by[i]
+		sa += a
+		sb += by[i]
+	}
+	t1 := distanceProductSum(x, y)
+	return (t1 - 2*t2/(n-2) + sa*sb/((n-1)*(n-2))) / (n * (n - 3))
+}
+
+// distanceSums returns the sums a_i· = \sum_j |x_i - x_j| for each i.
+func distanceSums(x []float64) []float64 {
+	n := len(x)
+	order := argsortFloats(x)
+	// Center the data to reduce the cancellation in the differences of
+	// the partial sums.
+	mean := Mean(x, nil)
+	var total float64
+	for _, v := range x {
+		total += v - mean
+	}
+	sums := make([]float64, n)
+	var below float64
+	for k, i := range order {
+		v := x[i] - mean
+		// The k elements before v in order are not greater than it.
+		above := total - below - v
+		sums[i] = v*float64(k) - below + above - v*float64(n-k-1)
+		below += v
+	}
+	return sums
+}
+
+// distanceProductSum returns \sum_{i≠j} |x_i - x_j| |y_i - y_j|. For the
+// pairs with x_j < x_i, the product is ±(x_i - x_j)(y_i - y_j) with the sign
+// of y_i - y_j, so the sum over the earlier observations in order of x is
+// obtained from the sums of 1, x_j, y_j and x_j y_j over the observations
+// with y_j below and above y_i, kept in Fenwick trees over the ranks of y.
+func distanceProductSum(x, y []float64) float64 {
+	n := len(x)
+	// Center the data to reduce the cancellation in the expanded products.
+	mx, my := Mean(x, nil), Mean(y, nil)
+
+	// Dense ranks of y, starting at 1.
+	rank := make([]int, n)
+	byY := argsortFloats(y)
+	m := 0
+	for k, i := range byY {
+		if k == 0 || y[i] != y[byY[k-1]] {
+			m++
+		}
+		rank[i] = m
+	}
+	byX := argsortFloats(x)
+
+	count := make(fenwick, m+1)
+	sumX := make(fenwick, m+1)
+	sumY := make(fenwick, m+1)
+	sumXY := make(fenwick, m+1)
+	var totX, totY, totXY float64
+	var s float64
+	for k, i := range byX {
+		xi, yi := x[i]-mx, y[i]-my
+		r := rank[i]
+		// The earlier observations with y below y_i, and with y above it.
+		c0, x0, y0, xy0 := count.sum(r-1), sumX.sum(r-1), sumY.sum(r-1), sumXY.sum(r-1)
+		c1 := float64(k) - count.sum(r)
+		x1 := totX - sumX.sum(r)
+		y1 := totY - sumY.sum(r)
+		xy1 := totXY - sumXY.sum(r)
+		s += (c0-c1)*xi*yi - xi*(y0-y1) - yi*(x0-x1) + (xy0 - xy1)
+
+		count.add(r, 1)
+		sumX.add(r, xi)
+		sumY.add(r, yi)
+		sumXY.add(r, xi*yi)
+		totX += xi
+		totY += yi
+		totXY += xi * yi
+	}
+	return 2 * s
+}
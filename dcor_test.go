@@ -0,0 +1,130 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// naiveDistanceCov computes the unbiased squared distance covariance from the
+// U-centered distance matrices.
+func naiveDistanceCov(x, y []float64) float64 {
+	n := len(x)
+	center := func(z []float64) [][]float64 {
+		d := make([][]float64, n)
+		row := make([]float64, n)
+		var total float64
+		for i := range d {
+			d[i] = make([]float64, n)
+			for j := range d[i] {
+				d[i][j] = math.Abs(z[i] - z[j])
+				row[i] += d[i][j]
+			}
+			total += row[i]
+		}
+		nf := float64(n)
+		for i := range d {
+			for j := range d[i] {
+				if i == j {
+					d[i][j] = 0
+					continue
+				}
+				d[i][j] += -row[i]/(nf-2) - row[j]/(nf-2) + total/((nf-1)*(nf-2))
+			}
+		}
+		return d
+	}
+	a, b := center(x), center(y)
+	var s float64
+	for i := range a {
+		for j := range a[i] {
+			s += a[i][j] * b[i][j]
+		}
+	}
+	return s / float64(n*(n-3))
+}
+
+func TestDistanceCovariance(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for k := 0; k < 30; k++ {
+		n := 4 + rnd.Intn(30)
+		x := make([]float64, n)
+		y := make([]float64, n)
+		for i := range x {
+			if k%3 == 0 {
+				// Many ties in both variables.
+				x[i] = float64(rnd.Intn(4))
+				y[i] = float64(rnd.Intn(3))
+			} else {
+				x[i] = rnd.NormFloat64() + 100
+				y[i] = x[i]*x[i]/50 + rnd.NormFloat64()
+			}
+		}
+		want := naiveDistanceCov(x, y)
+		got := DistanceCovariance(x, y)
+		if math.Abs(got-want) > 1e-10*math.Max(1, math.Abs(want)) {
+			t.Errorf("case %d: distance covariance mismatch. Want %v, got %v", k, want, got)
+		}
+		wantR := 0.0
+		if d := naiveDistanceCov(x, x) * naiveDistanceCov(y, y); d > 0 {
+			wantR = want / math.Sqrt(d)
+		}
+		if r := DistanceCorrelation(x, y); math.Abs(r-wantR) > 1e-10 {
+			t.Errorf("case %d: distance correlation mismatch. Want %v, got %v", k, wantR, r)
+		}
+	}
+
+	for i, fn := range []func(){
+		func() { DistanceCovariance([]float64{1, 2, 3, 4}, []float64{1, 2, 3}) },
+		func() { DistanceCovariance([]float64{1, 2, 3}, []float64{1, 2, 3}) },
+		func() { DistanceCorrelation([]float64{1, 2, 3}, []float64{1, 2, 3}) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestDistanceCorrelation(t *testing.T) {
+	// y = x² on x symmetric about zero has no linear correlation.
+	x := make([]float64, 41)
+	y := make([]float64, len(x))
+	for i := range x {
+		x[i] = float64(i-20) / 10
+		y[i] = x[i] * x[i]
+	}
+	if r := Correlation(x, y, nil); math.Abs(r) > 1e-14 {
+		t.Errorf("unexpected Pearson correlation %v", r)
+	}
+	if r := DistanceCorrelation(x, y); r < 0.1 {
+		t.Errorf("distance correlation of y = x² too small: %v", r)
+	}
+
+	// Linear relations have a distance correlation of 1.
+	for i := range y {
+		y[i] = 3 - 2*x[i]
+	}
+	if r := DistanceCorrelation(x, y); math.Abs(r-1) > 1e-12 {
+		t.Errorf("distance correlation of a linear relation: want 1, got %v", r)
+	}
+
+	// The estimate is unbiased, so it is near zero for independent samples.
+	rnd := rand.New(rand.NewSource(1))
+	x = make([]float64, 2000)
+	y = make([]float64, len(x))
+	for i := range x {
+		x[i] = rnd.NormFloat64()
+		y[i] = rnd.ExpFloat64()
+	}
+	if r := DistanceCorrelation(x, y); math.Abs(r) > 0.01 {
+		t.Errorf("distance correlation of independent samples too large: %v", r)
+	}
+
+	if r := DistanceCorrelation([]float64{1, 2, 3, 4}, []float64{5, 5, 5, 5}); r != 0 {
+		t.Errorf("distance correlation with a constant: want 0, got %v", r)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"errors"
+	"math"
+)
+
+// DecomposeModel specifies how the components of a seasonal decomposition
+// combine to form the series.
+type DecomposeModel int
+
+const (
+	// AdditiveModel decomposes the series as trend + seasonal + residual.
+	AdditiveModel DecomposeModel = iota
+	// MultiplicativeModel decomposes the series as trend * seasonal * residual.
+	// All of the values in the series must be positive.
+	MultiplicativeModel
+)
+
+// Decomposition holds the components of a seasonal decomposition. Each
+// component has the same length as the decomposed series.
+type Decomposition struct {
+	Trend    []float64
+	Seasonal []float64
+	Residual []float64
+}
+
+// SeasonalDecompose performs the classical decomposition of x into trend,
+// seasonal and residual components by moving averages, where period is the
+// number of observations in a seasonal cycle.
+//
+// The trend is estimated by a centered moving average over one period. For
+// an even period the average spans period+1 observations with the two end
+// points given half weight. The trend is undefined for the first and last
+// period/2 observations, which are set to NaN in Trend and Residual.
+//
+// The seasonal component is obtained by averaging the detrended series over
+// each position in the cycle and normalizing the averages to sum to zero
+// for the additive model or to average one for the multiplicative model.
+// This is the algorithm used by R's decompose and by statsmodels'
+// seasonal_decompose.
+//
+// SeasonalDecompose returns an error if x does not contain at least two full
+// periods, or for the multiplicative model if any value of x is not
+// positive. It panics if period is less than 2.
+func SeasonalDecompose(x []float64, period int, model DecomposeModel) (Decomposition, error) {
+	if period < 2 {
+		panic("stat: period less than two")
+	}
+	n := len(x)
+	if n < 2*period {
+		return Decomposition{}, errors.New("stat: series shorter than two periods")
+	}
+	mult := false
+	switch model {
+	case AdditiveModel:
+	case MultiplicativeModel:
+		mult = true
+		for _, v := range x {
+			if !(v > 0) {
+				return Decomposition{}, errors.New("stat: multiplicative decomposition of non-positive series")
+			}
+		}
+	default:
+		panic("stat: unknown decomposition model")
+	}
+
+	d := Decomposition{
+		Trend:    make([]float64, n),
+		Seasonal: make([]float64, n),
+		Residual: make([]float64, n),
+	}
+
+	// Centered moving average.
+	h := period / 2
+	for t := range d.Trend {
+		if t < h || t >= n-h {
+			d.Trend[t] = math.NaN()
+			continue
+		}
+		var s float64
+		if period%2 == 0 {
+			s = 0.5 * (x[t-h] + x[t+h])
+			for k := t - h + 1; k < t+h; k++ {
+				s += x[k]
+			}
+		} else {
+			for k := t - h; k <= t+h; k++ {
+				s += x[k]
+			}
+		}
+		d.Trend[t] = s / float64(period)
+	}
+
+	// Average the detrended series at each position in the cycle, ignoring
+	// the positions where the trend is undefined.
+	figure := make([]float64, period)
+	for i := range figure {
+		var sum, count float64
+		for t := i; t < n; t += period {
+			if math.IsNaN(d.Trend[t]) {
+				continue
+			}
+			if mult {
+				sum += x[t] / d.Trend[t]
+			} else {
+				sum += x[t] - d.Trend[t]
+			}
+			count++
+		}
+		figure[i] = sum / count
+	}
+	m := Mean(figure, nil)
+	for i := range figure {
+		if mult {
+			figure[i] /= m
+		} else {
+			figure[i] -= m
+		}
+	}
+
+	for t := range x {
+		s := figure[t%period]
+		d.Seasonal[t] = s
+		if mult {
+			d.Residual[t] = x[t] / (d.Trend[t] * s)
+		} else {
+			d.Residual[t] = x[t] - d.Trend[t] - s
+		}
+	}
+	return d, nil
+}
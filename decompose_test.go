@@ -0,0 +1,95 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestSeasonalDecompose(t *testing.T) {
+	for _, test := range []struct {
+		model     DecomposeModel
+		figure    []float64
+		residuals []float64 // At indices 6, 7, 8 and len-7.
+	}{
+		{
+			// Seasonal figure of decompose(AirPassengers) in R.
+			model: AdditiveModel,
+			figure: []float64{
+				-24.748737373737377, -36.18813131313132, -2.2411616161616097, -8.036616161616141,
+				-4.506313131313134, 35.402777777777764, 63.83080808080808, 62.82323232323231,
+				16.52020202020204, -20.642676767676765, -53.593434343434346, -28.619949494949502,
+			},
+			residuals: []float64{-42.62247474747474, -42.0732323232323, -8.478535353535353, 24.555555555555607},
+		},
+		{
+			model: MultiplicativeModel,
+			figure: []float64{
+				0.9102303673722009, 0.8836253206943756, 1.0073662876035456, 0.9759060123228475,
+				0.9813780274951296, 1.112775826679273, 1.2265555429312016, 1.2199109694456254,
+				1.0604919326468183, 0.9217572404104976, 0.8011780824134744, 0.8988243899850115,
+			},
+			residuals: []float64{0.9516643164028831, 0.9534014056242446, 1.00221976781656, 1.0120789574210474},
+		},
+	} {
+		d, err := SeasonalDecompose(airPassengers, 12, test.model)
+		if err != nil {
+			t.Fatalf("model %d: unexpected error: %v", test.model, err)
+		}
+		n := len(airPassengers)
+		for i := 0; i < n; i++ {
+			nan := i < 6 || i >= n-6
+			if math.IsNaN(d.Trend[i]) != nan || math.IsNaN(d.Residual[i]) != nan {
+				t.Errorf("model %d: unexpected NaN state at %d", test.model, i)
+			}
+			if math.Abs(d.Seasonal[i]-test.figure[i%12]) > 1e-10 {
+				t.Errorf("model %d: seasonal mismatch at %d. Want %v, got %v", test.model, i, test.figure[i%12], d.Seasonal[i])
+			}
+		}
+		for i, idx := range []int{6, 7, 8, n - 7} {
+			if math.Abs(d.Residual[idx]-test.residuals[i]) > 1e-10 {
+				t.Errorf("model %d: residual mismatch at %d. Want %v, got %v", test.model, idx, test.residuals[i], d.Residual[idx])
+			}
+		}
+		trend := []float64{126.79166666666666, 127.24999999999999, 127.95833333333331}
+		if !floats.EqualApprox(d.Trend[6:9], trend, 1e-12) || math.Abs(d.Trend[n-7]-475.04166666666663) > 1e-10 {
+			t.Errorf("model %d: trend mismatch", test.model)
+		}
+	}
+
+	// An odd period uses a plain moving average, and a series that is exactly
+	// trend plus a zero-sum seasonal pattern is recovered with zero residual.
+	seasonal := []float64{1, -3, 2}
+	x := make([]float64, 12)
+	for i := range x {
+		x[i] = 0.5*float64(i) + seasonal[i%3]
+	}
+	d, err := SeasonalDecompose(x, 3, AdditiveModel)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 1; i < len(x)-1; i++ {
+		if math.Abs(d.Trend[i]-0.5*float64(i)) > 1e-14 || math.Abs(d.Residual[i]) > 1e-14 {
+			t.Errorf("odd period: unexpected trend %v or residual %v at %d", d.Trend[i], d.Residual[i], i)
+		}
+	}
+	if !floats.EqualApprox(d.Seasonal[:3], seasonal, 1e-14) {
+		t.Errorf("odd period: seasonal mismatch. Want %v, got %v", seasonal, d.Seasonal[:3])
+	}
+
+	if _, err := SeasonalDecompose(airPassengers[:23], 12, AdditiveModel); err == nil {
+		t.Errorf("expected error for series shorter than two periods")
+	}
+	neg := []float64{1, 2, -1, 3, 4, 5}
+	if _, err := SeasonalDecompose(neg, 2, MultiplicativeModel); err == nil {
+		t.Errorf("expected error for multiplicative model of non-positive series")
+	}
+	if !Panics(func() { SeasonalDecompose(airPassengers, 1, AdditiveModel) }) {
+		t.Errorf("SeasonalDecompose did not panic with period one")
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"sort"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// DetrendKind specifies the trend removed by Detrend. The value of a
+// DetrendKind is the degree of the polynomial in the sample index that is
+// fitted by least squares, so DetrendKind(k) removes a degree-k polynomial
+// trend for any k ≥ 0.
+type DetrendKind int
+
+const (
+	// ConstantTrend removes the mean of the series.
+	ConstantTrend DetrendKind = 0
+	// LinearTrend removes the least-squares line through the series.
+	LinearTrend DetrendKind = 1
+)
+
+// Detrend removes the trend of the given kind from the series x, returning
+// the residual series. The trend is a function of the sample index, so x is
+// assumed to be sampled at equally spaced points.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored
+// in dst and dst is returned. A non-nil dst must have the same length as x
+// and may be x itself. Detrend panics if the degree of the trend is negative
+// or not less than len(x).
+func Detrend(dst, x []float64, kind DetrendKind) []float64 {
+	dst = reuseFloats(dst, len(x))
+	trend := Trend(nil, x, kind)
+	for i, v := range x {
+		dst[i] = v - trend[i]
+	}
+	return dst
+}
+
+// Trend returns the least-squares fit to x of the trend of the given kind,
+// such that Detrend(nil, x, kind) is the difference between x and the
+// returned trend. The conventions for dst are the same as for Detrend.
+func Trend(dst, x []float64, kind DetrendKind) []float64 {
+	n := len(x)
+	if kind < 0 {
+		panic("stat: negative trend degree")
+	}
+	if int(kind) >= n {
+		panic("stat: trend degree too large for series")
+	}
+	dst = reuseFloats(dst, n)
+	switch kind {
+	case ConstantTrend:
+		m := Mean(x, nil)
+		for i := range dst {
+			dst[i] = m
+		}
+		return dst
+	case LinearTrend:
+		idx := make([]float64, n)
+		for i := range idx {
+			idx[i] = float64(i)
+		}
+		alpha, beta := LinearRegression(idx, x, nil, false)
+		for i := range dst {
+			dst[i] = alpha + beta*float64(i)
+		}
+		return dst
+	}
+
+	// Fit the polynomial in the index rescaled to [-1, 1] so that the
+	// Vandermonde design stays well conditioned. The fitted values do not
+	// depend on the scaling.
+	t := make([]float64, n)
+	for i := range t {
+		t[i] = 2*float64(i)/float64(n-1) - 1
+	}
+	design := vandermonde(t, int(kind))
+	coef := newQR(design).solve(nil, x)
+	for i := range dst {
+		dst[i] = floats.Dot(design.RawRowView(i), coef)
+	}
+	return dst
+}
+
+// DetrendBreakpoints removes a separate least-squares line from each
+// segment of x delimited by the given breakpoints, returning the residual
+// series. A breakpoint b starts a new segment at x[b], so the segments are
+// x[0:bp[0]], x[bp[0]:bp[1]], ..., x[bp[len(bp)-1]:]. Duplicate breakpoints
+// and breakpoints at 0 or len(x) are ignored. This matches the behavior of
+// scipy.signal.detrend with the bp argument.
+//
+// The conventions for dst are the same as for Detrend. DetrendBreakpoints
+// panics if a breakpoint is outside [0, len(x)].
+func DetrendBreakpoints(dst, x []float64, bp []int) []float64 {
+	n := len(x)
+	dst = reuseFloats(dst, n)
+	bounds := make([]int, 0, len(bp)+2)
+	bounds = append(bounds, 0)
+	for _, b := range bp {
+		if b < 0 || b > n {
+			panic("stat: breakpoint out of range")
+		}
+		bounds = append(bounds, b)
+	}
+	bounds = append(bounds, n)
+	sort.Ints(bounds)
+	for i := 1; i < len(bounds); i++ {
+		lo, hi := bounds[i-1], bounds[i]
+		switch hi - lo {
+		case 0:
+			continue
+		case 1:
+			dst[lo] = 0
+			continue
+		}
+		Detrend(dst[lo:hi], x[lo:hi], LinearTrend)
+	}
+	return dst
+}
+
+// vandermonde returns the len(t)×(degree+1) design matrix of a polynomial
+// of the given degree evaluated at t, with columns in increasing order of
+// power.
+func vandermonde(t []float64, degree int) *mat64.Dense {
+	v := mat64.NewDense(len(t), degree+1, nil)
+	for i, ti := range t {
+		row := v.RawRowView(i)
+		p := 1.0
+		for j := range row {
+			row[j] = p
+			p *= ti
+		}
+	}
+	return v
+}
+
+// reuseFloats returns dst if it has length n, or a new slice of length n if
+// dst is nil. Otherwise it panics.
+func reuseFloats(dst []float64, n int) []float64 {
+	if dst == nil {
+		return make([]float64, n)
+	}
+	if len(dst) != n {
+		panic(ErrLengthMismatch{Got: len(dst), Want: n})
+	}
+	return dst
+}
@@ -0,0 +1,112 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestDetrend(t *testing.T) {
+	x := []float64{1, 4, 2, 8, 5, 7, 3}
+
+	// Residuals of the least-squares line through the index, as returned by
+	// scipy.signal.detrend(x).
+	want := []float64{
+		-1.6785714285714286, 0.7857142857142857, -1.75, 3.7142857142857144,
+		0.17857142857142858, 1.6428571428571428, -2.892857142857143,
+	}
+	got := Detrend(nil, x, LinearTrend)
+	if !floats.EqualApprox(got, want, 1e-13) {
+		t.Errorf("linear detrend mismatch. Want %v, got %v", want, got)
+	}
+	var dot float64
+	for i, v := range got {
+		dot += float64(i) * v
+	}
+	if math.Abs(dot) > 1e-12 || math.Abs(floats.Sum(got)) > 1e-12 {
+		t.Errorf("linear detrend residuals not orthogonal to index: %v, sum %v", dot, floats.Sum(got))
+	}
+	trend := Trend(nil, x, LinearTrend)
+	for i := range x {
+		if math.Abs(trend[i]+got[i]-x[i]) > 1e-14 {
+			t.Errorf("trend and residual do not sum to the series at %d", i)
+		}
+	}
+
+	got = Detrend(nil, x, ConstantTrend)
+	m := Mean(x, nil)
+	for i, v := range got {
+		if v != x[i]-m {
+			t.Errorf("constant detrend mismatch at %d. Want %v, got %v", i, x[i]-m, v)
+		}
+	}
+
+	// A polynomial of degree one must agree with the linear trend, and a
+	// polynomial series is removed exactly by a trend of its degree.
+	poly1 := Detrend(nil, x, DetrendKind(1))
+	if !floats.EqualApprox(poly1, want, 1e-13) {
+		t.Errorf("degree one detrend mismatch. Want %v, got %v", want, poly1)
+	}
+	cubic := make([]float64, 20)
+	for i := range cubic {
+		v := float64(i)
+		cubic[i] = 3 - 2*v + 0.5*v*v - 0.01*v*v*v
+	}
+	res := Detrend(nil, cubic, DetrendKind(3))
+	for i, v := range res {
+		if math.Abs(v) > 1e-12 {
+			t.Errorf("cubic detrend residual %d not zero: %v", i, v)
+		}
+	}
+	res = Detrend(nil, cubic, DetrendKind(2))
+	var dot2 float64
+	for i, v := range res {
+		fi := float64(i)
+		dot2 += fi * fi * v
+	}
+	if math.Abs(dot2) > 1e-9 {
+		t.Errorf("quadratic detrend residuals not orthogonal to squared index: %v", dot2)
+	}
+
+	// In-place operation.
+	xc := make([]float64, len(x))
+	copy(xc, x)
+	Detrend(xc, xc, LinearTrend)
+	if !floats.EqualApprox(xc, want, 1e-13) {
+		t.Errorf("in-place detrend mismatch. Want %v, got %v", want, xc)
+	}
+
+	if !Panics(func() { Detrend(nil, x, DetrendKind(-1)) }) {
+		t.Errorf("Detrend did not panic with negative degree")
+	}
+	if !Panics(func() { Detrend(nil, x, DetrendKind(len(x))) }) {
+		t.Errorf("Detrend did not panic with degree too large")
+	}
+	if !Panics(func() { Detrend(make([]float64, 2), x, LinearTrend) }) {
+		t.Errorf("Detrend did not panic with dst length mismatch")
+	}
+}
+
+func TestDetrendBreakpoints(t *testing.T) {
+	x := []float64{1, 4, 2, 8, 5, 7, 3, 10, 12, 11, 15}
+	got := DetrendBreakpoints(nil, x, []int{7, 4, 7})
+	want := make([]float64, len(x))
+	Detrend(want[:4], x[:4], LinearTrend)
+	Detrend(want[4:7], x[4:7], LinearTrend)
+	Detrend(want[7:], x[7:], LinearTrend)
+	if !floats.EqualApprox(got, want, 1e-14) {
+		t.Errorf("breakpoint detrend mismatch. Want %v, got %v", want, got)
+	}
+	got = DetrendBreakpoints(nil, x, nil)
+	if !floats.EqualApprox(got, Detrend(nil, x, LinearTrend), 1e-14) {
+		t.Errorf("breakpoint detrend without breakpoints does not match Detrend")
+	}
+	if !Panics(func() { DetrendBreakpoints(nil, x, []int{len(x) + 1}) }) {
+		t.Errorf("DetrendBreakpoints did not panic with out of range breakpoint")
+	}
+}
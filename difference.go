@@ -0,0 +1,116 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// Difference computes the lag-differences of x applied order times,
+//  y_t = x_t - x_{t-lag}
+// The ordinary first difference has lag 1 and order 1, and seasonal
+// differencing of monthly data uses lag 12. Each application shortens the
+// series by lag, so the result has length len(x) - lag*order; the element
+// y[i] corresponds to x[i + lag*order]. DifferencePadded returns the same
+// values aligned with x instead.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored in
+// dst and dst is returned. Difference panics if lag or order is not positive,
+// if len(x) < lag*order, or if a non-nil dst has the wrong length.
+func Difference(dst, x []float64, lag, order int) []float64 {
+	m := checkDifference(len(x), lag, order)
+	dst = reuseFloats(dst, len(x)-m)
+	tmp := make([]float64, len(x))
+	copy(tmp, x)
+	difference(tmp, lag, order)
+	copy(dst, tmp[m:])
+	return dst
+}
+
+// DifferencePadded is like Difference except that the result has the same
+// length as x, with dst[i] corresponding to x[i]. The first lag*order
+// elements, for which the difference is undefined, are set to NaN. dst may
+// be x itself.
+func DifferencePadded(dst, x []float64, lag, order int) []float64 {
+	m := checkDifference(len(x), lag, order)
+	dst = reuseFloats(dst, len(x))
+	copy(dst, x)
+	difference(dst, lag, order)
+	for i := 0; i < m; i++ {
+		dst[i] = math.NaN()
+	}
+	return dst
+}
+
+// difference differences x in place, leaving the undefined leading elements
+// in an unspecified state.
+func difference(x []float64, lag, order int) {
+	for k := 1; k <= order; k++ {
+		// Work backwards so that x[t-lag] still holds the previous level.
+		for t := len(x) - 1; t >= k*lag; t-- {
+			x[t] -= x[t-lag]
+		}
+	}
+}
+
+func checkDifference(n, lag, order int) int {
+	if lag < 1 {
+		panic("stat: non-positive lag")
+	}
+	if order < 1 {
+		panic("stat: non-positive order")
+	}
+	m := lag * order
+	if n < m {
+		panic("stat: series too short for differencing")
+	}
+	return m
+}
+
+// CumulativeUndifference inverts Difference. Given the differenced series
+// diffs and the first lag*order values of the original series in initial, it
+// reconstructs the original series, which has length
+// len(initial) + len(diffs).
+//
+// To map forecasts made on the differenced scale back to the original scale,
+// append them to the differenced series before calling
+// CumulativeUndifference; the tail of the result then holds the forecasts
+// on the original scale. Values returned by DifferencePadded can be
+// undifferenced by passing the series without its NaN prefix.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored in
+// dst and dst is returned. CumulativeUndifference panics if lag or order is
+// not positive, if len(initial) != lag*order, or if a non-nil dst has the
+// wrong length.
+func CumulativeUndifference(dst, diffs, initial []float64, lag, order int) []float64 {
+	if lag < 1 {
+		panic("stat: non-positive lag")
+	}
+	if order < 1 {
+		panic("stat: non-positive order")
+	}
+	m := lag * order
+	if len(initial) != m {
+		panic("stat: initial values length mismatch")
+	}
+	n := m + len(diffs)
+	dst = reuseFloats(dst, n)
+
+	// seeds[k*lag:(k+1)*lag] holds the first lag values of the k-th order
+	// difference, which occupy the same indices of the original series.
+	seeds := make([]float64, m)
+	copy(seeds, initial)
+	difference(seeds, lag, order-1)
+
+	copy(dst[m:], diffs)
+	for k := order; k >= 1; k-- {
+		// Rebuild level k-1, which is defined from index (k-1)*lag, from
+		// level k held in dst[k*lag:].
+		lo := (k - 1) * lag
+		copy(dst[lo:lo+lag], seeds[lo:lo+lag])
+		for t := k * lag; t < n; t++ {
+			dst[t] += dst[t-lag]
+		}
+	}
+	return dst
+}
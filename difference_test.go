@@ -0,0 +1,119 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+// airPassengers is the classic Box & Jenkins monthly airline passenger
+// series, 1949–1960.
+var airPassengers = []float64{
+	112, 118, 132, 129, 121, 135, 148, 148, 136, 119, 104, 118,
+	115, 126, 141, 135, 125, 149, 170, 170, 158, 133, 114, 140,
+	145, 150, 178, 163, 172, 178, 199, 199, 184, 162, 146, 166,
+	171, 180, 193, 181, 183, 218, 230, 242, 209, 191, 172, 194,
+	196, 196, 236, 235, 229, 243, 264, 272, 237, 211, 180, 201,
+	204, 188, 235, 227, 234, 264, 302, 293, 259, 229, 203, 229,
+	242, 233, 267, 269, 270, 315, 364, 347, 312, 274, 237, 278,
+	284, 277, 317, 313, 318, 374, 413, 405, 355, 306, 271, 306,
+	315, 301, 356, 348, 355, 422, 465, 467, 404, 347, 305, 336,
+	340, 318, 362, 348, 363, 435, 491, 505, 404, 359, 310, 337,
+	360, 342, 406, 396, 420, 472, 548, 559, 463, 407, 362, 405,
+	417, 391, 419, 461, 472, 535, 622, 606, 508, 461, 390, 432,
+}
+
+func TestDifference(t *testing.T) {
+	x := []float64{1, 4, 9, 16, 25, 36}
+	for _, test := range []struct {
+		lag, order int
+		want       []float64
+	}{
+		{1, 1, []float64{3, 5, 7, 9, 11}},
+		{1, 2, []float64{2, 2, 2, 2}},
+		{1, 3, []float64{0, 0, 0}},
+		{2, 1, []float64{8, 12, 16, 20}},
+		{2, 2, []float64{8, 8}},
+		{3, 2, []float64{}},
+	} {
+		got := Difference(nil, x, test.lag, test.order)
+		if !floats.Equal(got, test.want) {
+			t.Errorf("lag %d order %d: want %v, got %v", test.lag, test.order, test.want, got)
+		}
+		padded := DifferencePadded(nil, x, test.lag, test.order)
+		m := test.lag * test.order
+		for i := 0; i < m; i++ {
+			if !math.IsNaN(padded[i]) {
+				t.Errorf("lag %d order %d: padded value %d not NaN", test.lag, test.order, i)
+			}
+		}
+		if !floats.Equal(padded[m:], test.want) {
+			t.Errorf("lag %d order %d: padded want %v, got %v", test.lag, test.order, test.want, padded[m:])
+		}
+	}
+
+	if !Panics(func() { Difference(nil, x, 0, 1) }) {
+		t.Errorf("Difference did not panic with zero lag")
+	}
+	if !Panics(func() { Difference(nil, x, 1, 0) }) {
+		t.Errorf("Difference did not panic with zero order")
+	}
+	if !Panics(func() { Difference(nil, x, 4, 2) }) {
+		t.Errorf("Difference did not panic with short series")
+	}
+	if !Panics(func() { Difference(make([]float64, len(x)), x, 1, 1) }) {
+		t.Errorf("Difference did not panic with dst length mismatch")
+	}
+}
+
+func TestCumulativeUndifference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	noise := make([]float64, 100)
+	for i := range noise {
+		noise[i] = rnd.NormFloat64()
+	}
+	for _, test := range []struct {
+		x          []float64
+		lag, order int
+		tol        float64
+	}{
+		{airPassengers, 1, 1, 0},
+		{airPassengers, 1, 2, 0},
+		{airPassengers, 12, 1, 0},
+		{airPassengers, 12, 2, 0},
+		{airPassengers, 5, 3, 0},
+		{noise, 1, 1, 1e-13},
+		{noise, 3, 2, 1e-13},
+	} {
+		m := test.lag * test.order
+		d := Difference(nil, test.x, test.lag, test.order)
+		got := CumulativeUndifference(nil, d, test.x[:m], test.lag, test.order)
+		if !floats.EqualApprox(got, test.x, test.tol) {
+			t.Errorf("lag %d order %d: round trip mismatch", test.lag, test.order)
+		}
+		padded := DifferencePadded(nil, test.x, test.lag, test.order)
+		got = CumulativeUndifference(got, padded[m:], test.x[:m], test.lag, test.order)
+		if !floats.EqualApprox(got, test.x, test.tol) {
+			t.Errorf("lag %d order %d: padded round trip mismatch", test.lag, test.order)
+		}
+	}
+
+	// A constant forecast of the second difference continues a quadratic.
+	x := []float64{1, 4, 9, 16}
+	d := append(Difference(nil, x, 1, 2), 2, 2)
+	got := CumulativeUndifference(nil, d, x[:2], 1, 2)
+	want := []float64{1, 4, 9, 16, 25, 36}
+	if !floats.Equal(got, want) {
+		t.Errorf("forecast undifference mismatch. Want %v, got %v", want, got)
+	}
+
+	if !Panics(func() { CumulativeUndifference(nil, d, x[:1], 1, 2) }) {
+		t.Errorf("CumulativeUndifference did not panic with wrong initial length")
+	}
+}
@@ -0,0 +1,361 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// This file holds the distribution functions needed for computing p-values.
+// They are kept private to avoid an import of the dist package, which
+// depends on stat.
+
+// regIncBeta returns the regularized incomplete beta function I_x(a, b).
+// The continued fraction is evaluated by the modified Lentz method, using
+// the symmetry I_x(a, b) = 1 - I_{1-x}(b, a) where it converges faster.
+func regIncBeta(x, a, b float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaCF(x, a, b) / a
+	}
+	return 1 - front*betaCF(1-x, b, a)/b
+}
+
+// betaCF evaluates the continued fraction for the incomplete beta function.
+func betaCF(x, a, b float64) float64 {
+	const (
+		maxIter = 1000
+		eps     = 1e-16
+		tiny    = 1e-300
+	)
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		// Even step.
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// Odd step.
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
+
+// fSurvival returns the probability that an F-distributed variable with d1
+// and d2 degrees of freedom exceeds f.
+func fSurvival(f, d1, d2 float64) float64 {
+	if f <= 0 {
+		return 1
+	}
+	return regIncBeta(d2/(d2+d1*f), d2/2, d1/2)
+}
+
+// invRegIncBeta returns the x in [0, 1] such that I_x(a, b) = p, found by
+// bisection.
+func invRegIncBeta(p, a, b float64) float64 {
+	switch {
+	case p <= 0:
+		return 0
+	case p >= 1:
+		return 1
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 200; i++ {
+		mid := (lo + hi) / 2
+		if mid == lo || mid == hi {
+			break
+		}
+		if regIncBeta(mid, a, b) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// fQuantile returns the p-quantile of the F distribution with d1 and d2
+// degrees of freedom.
+func fQuantile(p, d1, d2 float64) float64 {
+	x := invRegIncBeta(p, d1/2, d2/2)
+	return d2 * x / (d1 * (1 - x))
+}
+
+// kolmogorovSurvival returns the probability that a variable with the
+// Kolmogorov distribution exceeds x,
+//  Q(x) = 2 sum_{k=1}^∞ (-1)^(k-1) exp(-2 k² x²)
+// For x < 1 the equivalent series
+//  Q(x) = 1 - sqrt(2π)/x sum_{k=1}^∞ exp(-(2k-1)² π²/(8 x²))
+// is used, which converges faster there.
+func kolmogorovSurvival(x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	var sum float64
+	if x < 1 {
+		f := -math.Pi * math.Pi / (8 * x * x)
+		for k := 1; k < 200; k += 2 {
+			term := math.Exp(float64(k*k) * f)
+			sum += term
+			if term <= 1e-17*sum {
+				break
+			}
+		}
+		return 1 - math.Sqrt(2*math.Pi)/x*sum
+	}
+	for k := 1; k < 200; k++ {
+		term := math.Exp(-2 * float64(k*k) * x * x)
+		if k%2 == 0 {
+			sum -= term
+		} else {
+			sum += term
+		}
+		if term <= 1e-17 {
+			break
+		}
+	}
+	return 2 * sum
+}
+
+// smirnovExact returns the probability that the two-sample Kolmogorov–Smirnov
+// statistic of samples of sizes m and n without ties is less than d. It counts
+// the lattice paths from (0, 0) to (m, n) that stay within d of the diagonal,
+// normalized as they are counted so that the result does not overflow.
+func smirnovExact(d float64, m, n int) float64 {
+	if m > n {
+		m, n = n, m
+	}
+	md, nd := float64(m), float64(n)
+	// The attainable values of d are multiples of 1/(m·n), so moving q half
+	// way to the next smaller one makes the comparisons below robust to
+	// rounding.
+	q := (0.5 + math.Floor(d*md*nd-1e-7)) / (md * nd)
+	u := make([]float64, n+1)
+	for j := range u {
+		if float64(j)/nd <= q {
+			u[j] = 1
+		}
+	}
+	for i := 1; i <= m; i++ {
+		w := float64(i) / float64(i+n)
+		if float64(i)/md > q {
+			u[0] = 0
+		} else {
+			u[0] *= w
+		}
+		for j := 1; j <= n; j++ {
+			if math.Abs(float64(i)/md-float64(j)/nd) > q {
+				u[j] = 0
+			} else {
+				u[j] = w*u[j] + u[j-1]
+			}
+		}
+	}
+	return u[n]
+}
+
+// tTwoSided returns the probability that the absolute value of a Student's
+// t-distributed variable with df degrees of freedom exceeds |t|.
+func tTwoSided(t, df float64) float64 {
+	return regIncBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// tQuantile returns the p-quantile of Student's t distribution with df
+// degrees of freedom.
+func tQuantile(p, df float64) float64 {
+	if p == 0.5 {
+		return 0
+	}
+	x := invRegIncBeta(2*math.Min(p, 1-p), df/2, 0.5)
+	t := math.Sqrt(df * (1 - x) / x)
+	if p < 0.5 {
+		return -t
+	}
+	return t
+}
+
+// noncentralTCDF returns the cumulative distribution function at t of the
+// noncentral t distribution with df degrees of freedom and noncentrality
+// delta, by the twin series of algorithm AS 243, Lenth, "Cumulative
+// distribution function of the non-central t distribution", Applied
+// Statistics 38, 1989. The series underflows for |delta| above about 37, and
+// there, as in R's pnt, the normal approximation of Abramowitz and Stegun
+// 26.7.10,
+//  P(T ≤ t) ≈ Φ((t (1 - 1/(4 df)) - delta) / √(1 + t²/(2 df)))
+// is used instead.
+func noncentralTCDF(t, df, delta float64) float64 {
+	const (
+		errMax  = 1e-12
+		iterMax = 1000
+
+		// maxLambda is the largest delta² for which exp(-delta²/2) does
+		// not underflow to a subnormal number.
+		maxLambda = 2 * math.Ln2 * 1021
+	)
+	if delta*delta > maxLambda {
+		s := 1 / (4 * df)
+		z := (t*(1-s) - delta) / math.Sqrt(1+2*t*t*s)
+		return 0.5 * math.Erfc(-z/math.Sqrt2)
+	}
+	neg := t < 0
+	if neg {
+		t, delta = -t, -delta
+	}
+	var cdf float64
+	if x := t * t / (t*t + df); x > 0 {
+		lambda := delta * delta
+		p := 0.5 * math.Exp(-0.5*lambda)
+		q := math.Sqrt(2/math.Pi) * p * delta
+		s := 0.5 - p
+		a, b := 0.5, 0.5*df
+		rxb := math.Pow(1-x, b)
+		lb, _ := math.Lgamma(b)
+		lab, _ := math.Lgamma(a + b)
+		logBeta := 0.5*math.Log(math.Pi) + lb - lab
+		xOdd := regIncBeta(x, a, b)
+		gOdd := 2 * rxb * math.Exp(a*math.Log(x)-logBeta)
+		xEven := 1 - rxb
+		gEven := b * x * rxb
+		cdf = p*xOdd + q*xEven
+		for n := 1.0; n <= iterMax; n++ {
+			a++
+			xOdd -= gOdd
+			xEven -= gEven
+			gOdd *= x * (a + b - 1) / a
+			gEven *= x * (a + b - 0.5) / (a + 0.5)
+			p *= lambda / (2 * n)
+			q *= lambda / (2*n + 1)
+			s -= p
+			cdf += p*xOdd + q*xEven
+			if 2*s*(xOdd-gOdd) <= errMax {
+				break
+			}
+		}
+	}
+	cdf += 0.5 * math.Erfc(delta/math.Sqrt2)
+	if neg {
+		return 1 - cdf
+	}
+	return cdf
+}
+
+// regIncGammaUpper returns the regularized upper incomplete gamma function
+//  Q(a, x) = Γ(a, x) / Γ(a)
+// using the series for P = 1 - Q when x < a+1 and the continued fraction,
+// evaluated by the modified Lentz method, otherwise.
+func regIncGammaUpper(a, x float64) float64 {
+	const (
+		maxIter = 1000
+		eps     = 1e-16
+		tiny    = 1e-300
+	)
+	if x <= 0 {
+		return 1
+	}
+	lg, _ := math.Lgamma(a)
+	front := math.Exp(a*math.Log(x) - x - lg)
+	if x < a+1 {
+		term := 1 / a
+		sum := term
+		for n := 1; n <= maxIter; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < eps*math.Abs(sum) {
+				break
+			}
+		}
+		return 1 - front*sum
+	}
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n <= maxIter; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return front * h
+}
+
+// chiSquareSurvival returns the probability that a chi-square distributed
+// variable with df degrees of freedom exceeds x.
+func chiSquareSurvival(x, df float64) float64 {
+	return regIncGammaUpper(df/2, x/2)
+}
+
+// normalQuantile returns the p-quantile of the standard normal distribution,
+// found by bisection.
+func normalQuantile(p float64) float64 {
+	switch {
+	case p <= 0:
+		return math.Inf(-1)
+	case p >= 1:
+		return math.Inf(1)
+	case p > 0.5:
+		return -normalQuantile(1 - p)
+	}
+	lo, hi := -40.0, 0.0
+	for i := 0; i < 200; i++ {
+		mid := (lo + hi) / 2
+		if mid == lo || mid == hi {
+			break
+		}
+		if 0.5*math.Erfc(-mid/math.Sqrt2) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
@@ -0,0 +1,234 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRegIncBeta(t *testing.T) {
+	for _, x := range []float64{0, 0.01, 0.2, 0.5, 0.73, 0.99, 1} {
+		for _, test := range []struct {
+			a, b float64
+			want float64
+		}{
+			{1, 1, x},
+			{3.5, 1, math.Pow(x, 3.5)},
+			{1, 2.5, 1 - math.Pow(1-x, 2.5)},
+			// I_x(2, 2) = 3x² - 2x³.
+			{2, 2, 3*x*x - 2*x*x*x},
+		} {
+			got := regIncBeta(x, test.a, test.b)
+			if math.Abs(got-test.want) > 1e-13 {
+				t.Errorf("I_%v(%v, %v) mismatch. Want %v, got %v", x, test.a, test.b, test.want, got)
+			}
+		}
+	}
+	// Symmetry for large parameters, where the continued fraction is
+	// evaluated on both sides.
+	for _, x := range []float64{0.3, 0.45, 0.5, 0.55, 0.7} {
+		a, b := 40.0, 60.0
+		if s := regIncBeta(x, a, b) + regIncBeta(1-x, b, a); math.Abs(s-1) > 1e-13 {
+			t.Errorf("symmetry violated at %v: sum is %v", x, s)
+		}
+	}
+}
+
+func TestFSurvival(t *testing.T) {
+	for _, f := range []float64{0.1, 0.7, 1, 2.5, 10} {
+		for _, d := range []float64{1, 3, 17.5, 40} {
+			// Closed forms with two degrees of freedom in the numerator or
+			// the denominator.
+			want := math.Pow(1+2*f/d, -d/2)
+			if got := fSurvival(f, 2, d); math.Abs(got-want) > 1e-13 {
+				t.Errorf("F(2, %v) survival at %v mismatch. Want %v, got %v", d, f, want, got)
+			}
+			want = 1 - math.Pow(d*f/(d*f+2), d/2)
+			if got := fSurvival(f, d, 2); math.Abs(got-want) > 1e-13 {
+				t.Errorf("F(%v, 2) survival at %v mismatch. Want %v, got %v", d, f, want, got)
+			}
+		}
+	}
+	// Tabulated upper 5% and 1% points.
+	for _, test := range []struct {
+		f, d1, d2, p float64
+	}{
+		{3.7083, 3, 10, 0.05},
+		{6.5523, 3, 10, 0.01},
+		{2.5336, 5, 30, 0.05},
+	} {
+		if got := fSurvival(test.f, test.d1, test.d2); math.Abs(got-test.p) > 1e-4 {
+			t.Errorf("F(%v, %v) survival at %v mismatch. Want %v, got %v", test.d1, test.d2, test.f, test.p, got)
+		}
+	}
+	if got := fSurvival(0, 3, 4); got != 1 {
+		t.Errorf("F survival at zero not one: %v", got)
+	}
+}
+
+func TestFQuantile(t *testing.T) {
+	for _, test := range []struct {
+		p, d1, d2 float64
+	}{
+		{0.95, 3, 10},
+		{0.975, 5, 18},
+		{0.025, 5, 15},
+		{0.5, 1, 1},
+		{0.99, 7.3, 2.6},
+	} {
+		f := fQuantile(test.p, test.d1, test.d2)
+		if got := 1 - fSurvival(f, test.d1, test.d2); math.Abs(got-test.p) > 1e-12 {
+			t.Errorf("F(%v, %v) quantile %v does not invert the CDF: %v", test.d1, test.d2, test.p, got)
+		}
+	}
+	if f := fQuantile(0.95, 3, 10); math.Abs(f-3.7083) > 1e-4 {
+		t.Errorf("F(3, 10) upper 5%% point mismatch. Want 3.7083, got %v", f)
+	}
+}
+
+func TestKolmogorovSurvival(t *testing.T) {
+	for _, test := range []struct {
+		x, want float64
+	}{
+		{0, 1},
+		{0.5, 0.9639452436648751},
+		{1, 0.26999967167735456},
+		{1.3581, 0.0499996304316674},
+		{2, 0.0006709252557796953},
+	} {
+		if got := kolmogorovSurvival(test.x); math.Abs(got-test.want) > 1e-14 {
+			t.Errorf("Kolmogorov survival at %v mismatch. Want %v, got %v", test.x, test.want, got)
+		}
+	}
+	// The two series agree where they switch.
+	lo, hi := kolmogorovSurvival(math.Nextafter(1, 0)), kolmogorovSurvival(1)
+	if math.Abs(lo-hi) > 1e-15 {
+		t.Errorf("series disagree at one: %v and %v", lo, hi)
+	}
+}
+
+func TestTDistribution(t *testing.T) {
+	for _, x := range []float64{0, 0.3, 1, 2.5, 12} {
+		// Closed forms with one and two degrees of freedom.
+		want := 1 - 2/math.Pi*math.Atan(x)
+		if got := tTwoSided(x, 1); math.Abs(got-want) > 1e-13 {
+			t.Errorf("t(1) two-sided p at %v mismatch. Want %v, got %v", x, want, got)
+		}
+		want = 1 - x/math.Sqrt(2+x*x)
+		if got := tTwoSided(-x, 2); math.Abs(got-want) > 1e-13 {
+			t.Errorf("t(2) two-sided p at %v mismatch. Want %v, got %v", -x, want, got)
+		}
+	}
+	for _, p := range []float64{0.01, 0.2, 0.5, 0.9, 0.975} {
+		for _, df := range []float64{1, 2.5, 9, 100} {
+			q := tQuantile(p, df)
+			got := tTwoSided(q, df) / 2
+			if q > 0 {
+				got = 1 - got
+			}
+			if math.Abs(got-p) > 1e-12 {
+				t.Errorf("t(%v) quantile %v does not invert the CDF: %v", df, p, got)
+			}
+		}
+	}
+	if q := tQuantile(0.975, 9); math.Abs(q-2.2622) > 1e-4 {
+		t.Errorf("t(9) upper 2.5%% point mismatch. Want 2.2622, got %v", q)
+	}
+}
+
+func TestNoncentralTCDF(t *testing.T) {
+	// The values were computed by numerical integration over the chi
+	// distribution of the denominator.
+	for _, test := range []struct {
+		t, df, delta float64
+		want         float64
+	}{
+		{2, 10, 1, 0.8076115625303649},
+		{-1.5, 5, 0.5, 0.03926767466196853},
+		{3, 20, 2.5, 0.6616028734935026},
+		{0.5, 3, -1, 0.92427388128861},
+		{10, 30, 8, 0.8812134923778757},
+		{-4, 7, -6, 0.9272667560829982},
+		{1, 1, 1, 0.42202003039262714},
+	} {
+		if got := noncentralTCDF(test.t, test.df, test.delta); math.Abs(got-test.want) > 1e-10 {
+			t.Errorf("noncentral t CDF(%v; %v, %v) mismatch. Want %v, got %v", test.t, test.df, test.delta, test.want, got)
+		}
+	}
+	// Beyond the range of the series the normal approximation is within
+	// a few thousandths of the values from numerical integration.
+	for _, test := range []struct {
+		t, df, delta float64
+		want         float64
+	}{
+		{42, 198, 40, 0.797313995933722},
+		{60, 198, 58, 0.7262108296401449},
+		{45, 50, 40, 0.8505959732331027},
+		{-42, 198, -40, 1 - 0.797313995933722},
+	} {
+		if got := noncentralTCDF(test.t, test.df, test.delta); math.Abs(got-test.want) > 3e-3 {
+			t.Errorf("noncentral t CDF(%v; %v, %v) mismatch. Want %v, got %v", test.t, test.df, test.delta, test.want, got)
+		}
+	}
+	// With zero noncentrality it is the central t distribution function.
+	for _, df := range []float64{1, 4.5, 30} {
+		for _, x := range []float64{-3, -0.2, 0, 1, 5} {
+			want := 0.5 * tTwoSided(x, df)
+			if x > 0 {
+				want = 1 - want
+			}
+			if got := noncentralTCDF(x, df, 0); math.Abs(got-want) > 1e-12 {
+				t.Errorf("central t CDF(%v; %v) mismatch. Want %v, got %v", x, df, want, got)
+			}
+		}
+	}
+}
+
+func TestChiSquareSurvival(t *testing.T) {
+	for _, x := range []float64{0, 0.01, 0.5, 1, 2.7, 6, 15, 40} {
+		// Closed forms for one, two, three and four degrees of freedom.
+		for _, test := range []struct {
+			df, want float64
+		}{
+			{1, math.Erfc(math.Sqrt(x / 2))},
+			{2, math.Exp(-x / 2)},
+			{3, math.Erfc(math.Sqrt(x/2)) + math.Sqrt(2*x/math.Pi)*math.Exp(-x/2)},
+			{4, (1 + x/2) * math.Exp(-x/2)},
+		} {
+			got := chiSquareSurvival(x, test.df)
+			if math.Abs(got-test.want) > 1e-12*test.want {
+				t.Errorf("chi-square(%v) survival at %v mismatch. Want %v, got %v", test.df, x, test.want, got)
+			}
+		}
+	}
+	// Tabulated upper 5% points.
+	for _, test := range []struct {
+		x, df float64
+	}{
+		{3.8415, 1},
+		{11.0705, 5},
+		{18.3070, 10},
+	} {
+		if got := chiSquareSurvival(test.x, test.df); math.Abs(got-0.05) > 1e-5 {
+			t.Errorf("chi-square(%v) survival at %v mismatch. Want 0.05, got %v", test.df, test.x, got)
+		}
+	}
+}
+
+func TestNormalQuantile(t *testing.T) {
+	for _, p := range []float64{1e-300, 1e-10, 0.001, 0.025, 0.3, 0.5, 0.7, 0.975, 0.999999} {
+		z := normalQuantile(p)
+		if got := 0.5 * math.Erfc(-z/math.Sqrt2); math.Abs(got-p) > 1e-12*p {
+			t.Errorf("normal quantile %v does not invert the CDF: %v", p, got)
+		}
+	}
+	if z := normalQuantile(0.975); math.Abs(z-1.959963984540054) > 1e-14 {
+		t.Errorf("normal upper 2.5%% point mismatch. Want 1.959963984540054, got %v", z)
+	}
+	if !math.IsInf(normalQuantile(0), -1) || !math.IsInf(normalQuantile(1), 1) {
+		t.Errorf("normal quantile not infinite at the ends")
+	}
+}
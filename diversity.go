@@ -0,0 +1,109 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// The diversity indices below take the counts (or abundances) of the
+// species in a community, and zero counts are allowed, representing species
+// that are absent. They are plug-in estimates computed from the observed
+// proportions p_i = n_i / Σ n_j. For small samples the observed proportions
+// miss rare species, so the indices are biased low, and comparisons between
+// communities should be made at equal sample sizes. All of the functions
+// panic if a count is negative.
+
+// ShannonDiversity returns the Shannon diversity index of the species counts,
+//  H = -Σ_i p_i log_b(p_i)
+// where b is the given base of the logarithm. The base is usually e, as in
+// vegan::diversity in R, or 2.
+func ShannonDiversity(counts []float64, base float64) float64 {
+	return Entropy(proportions(counts)) / math.Log(base)
+}
+
+// SimpsonIndex returns the Gini–Simpson index of the species counts,
+//  1 - Σ_i p_i²
+// the probability that two individuals drawn at random with replacement
+// belong to different species.
+func SimpsonIndex(counts []float64) float64 {
+	return 1 - simpsonConcentration(counts)
+}
+
+// InverseSimpson returns the inverse Simpson index of the species counts,
+//  1 / Σ_i p_i²
+// the Hill number of order 2.
+func InverseSimpson(counts []float64) float64 {
+	return 1 / simpsonConcentration(counts)
+}
+
+// PielouEvenness returns Pielou's evenness of the species counts,
+//  J = H / log(S)
+// where H is the Shannon diversity with natural logarithms and S is the
+// number of species present. J is 1 when the present species are equally
+// abundant. If fewer than two species are present, PielouEvenness returns
+// NaN.
+func PielouEvenness(counts []float64) float64 {
+	var s int
+	for _, v := range counts {
+		if v > 0 {
+			s++
+		}
+	}
+	if s < 2 {
+		return math.NaN()
+	}
+	return ShannonDiversity(counts, math.E) / math.Log(float64(s))
+}
+
+// HillNumber returns the Hill number, or effective number of species, of
+// order q of the species counts,
+//  D_q = (Σ_i p_i^q)^(1/(1-q))
+// over the species present. D_q is the number of equally abundant species
+// that would give the same diversity. Order 0 is the number of species
+// present, order 1, defined by the limit, is exp(H) for the Shannon
+// diversity H with natural logarithms, and order 2 is the inverse Simpson
+// index. Larger orders give less weight to rare species. HillNumber panics
+// if q is negative.
+func HillNumber(counts []float64, q float64) float64 {
+	if q < 0 {
+		panic("stat: negative order")
+	}
+	p := proportions(counts)
+	if q == 1 {
+		return math.Exp(Entropy(p))
+	}
+	var sum float64
+	for _, v := range p {
+		if v > 0 {
+			sum += math.Pow(v, q)
+		}
+	}
+	return math.Pow(sum, 1/(1-q))
+}
+
+// simpsonConcentration returns Simpson's concentration Σ_i p_i².
+func simpsonConcentration(counts []float64) float64 {
+	var d float64
+	for _, v := range proportions(counts) {
+		d += v * v
+	}
+	return d
+}
+
+// proportions returns the counts divided by their total. It panics if a
+// count is negative.
+func proportions(counts []float64) []float64 {
+	var sum float64
+	for _, v := range counts {
+		if v < 0 {
+			panic("stat: negative count")
+		}
+		sum += v
+	}
+	p := make([]float64, len(counts))
+	for i, v := range counts {
+		p[i] = v / sum
+	}
+	return p
+}
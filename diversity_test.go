@@ -0,0 +1,78 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDiversity(t *testing.T) {
+	// Rows of a community matrix x, with the values of diversity(x),
+	// diversity(x, "simpson"), diversity(x, "invsimpson"),
+	// diversity(x)/log(specnumber(x)) and renyi(x, scales = c(0.5, 3),
+	// hill = TRUE) as defined in the source of the R package vegan.
+	for _, test := range []struct {
+		counts                       []float64
+		shannon, simpson, invSimpson float64
+		pielou, hillHalf, hillThree  float64
+	}{
+		{
+			counts:  []float64{12, 0, 3, 7, 1, 0, 25},
+			shannon: 1.2210346915630461, simpson: 0.640625, invSimpson: 2.7826086956521734,
+			pielou: 0.7586715101773411, hillHalf: 3.9916312286456193, hillThree: 2.4979341498378616,
+		},
+		{
+			counts:  []float64{4, 4, 4, 4, 0, 0, 0},
+			shannon: math.Log(4), simpson: 0.75, invSimpson: 4,
+			pielou: 1, hillHalf: 4, hillThree: 4,
+		},
+		{
+			counts:  []float64{30, 1, 1, 1, 1, 1, 0},
+			shannon: 0.640036020064709, simpson: 0.2612244897959184, invSimpson: 1.3535911602209945,
+			pielou: 0.35721090417368145, hillHalf: 3.1363501643004734, hillThree: 1.2600273608911867,
+		},
+	} {
+		var richness float64
+		for _, v := range test.counts {
+			if v > 0 {
+				richness++
+			}
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"Shannon", ShannonDiversity(test.counts, math.E), test.shannon},
+			{"Shannon base 2", ShannonDiversity(test.counts, 2), test.shannon / math.Ln2},
+			{"Simpson", SimpsonIndex(test.counts), test.simpson},
+			{"inverse Simpson", InverseSimpson(test.counts), test.invSimpson},
+			{"Pielou", PielouEvenness(test.counts), test.pielou},
+			{"Hill 0", HillNumber(test.counts, 0), richness},
+			{"Hill 0.5", HillNumber(test.counts, 0.5), test.hillHalf},
+			{"Hill 1", HillNumber(test.counts, 1), math.Exp(test.shannon)},
+			{"Hill 2", HillNumber(test.counts, 2), test.invSimpson},
+			{"Hill 3", HillNumber(test.counts, 3), test.hillThree},
+		} {
+			if math.Abs(v.got-v.want) > 1e-13*math.Max(1, v.want) {
+				t.Errorf("%s index of %v mismatch. Want %v, got %v", v.name, test.counts, v.want, v.got)
+			}
+		}
+		// The order 1 Hill number is the limit of the general form.
+		if d := HillNumber(test.counts, 1+1e-7); math.Abs(d-math.Exp(test.shannon)) > 1e-5 {
+			t.Errorf("Hill number not continuous at order 1 for %v: %v", test.counts, d)
+		}
+	}
+
+	if j := PielouEvenness([]float64{0, 5, 0}); !math.IsNaN(j) {
+		t.Errorf("Pielou evenness of single species not NaN: %v", j)
+	}
+	if !Panics(func() { SimpsonIndex([]float64{1, -1, 3}) }) {
+		t.Errorf("SimpsonIndex did not panic with negative count")
+	}
+	if !Panics(func() { HillNumber([]float64{1, 2}, -1) }) {
+		t.Errorf("HillNumber did not panic with negative order")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+// ECDF is the empirical cumulative distribution function of a weighted
+// sample. It holds the distinct values of the sample in increasing order
+// with their cumulative weights, so that it is evaluated in O(log n) time.
+type ECDF struct {
+	x     []float64
+	cum   []float64
+	total float64
+	nan   bool
+}
+
+// NewECDF returns the empirical distribution function of the sample x with
+// the given weights. If weights is nil then all of the weights are 1,
+// otherwise len(x) must equal len(weights). x and weights are not modified.
+// If x contains NaN, the methods of the ECDF return NaN.
+//
+// NewECDF panics if x is empty, if a weight is negative or if the total
+// weight is zero.
+func NewECDF(x, weights []float64) *ECDF {
+	if err := ValidateWeights(x, weights); err != nil {
+		panic(err)
+	}
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	if equalWeights(weights) {
+		// Avoid the rounding of fractional cumulative weights, so that
+		// the result is exactly that for nil weights, as for Quantile.
+		weights = nil
+	}
+	e := &ECDF{}
+	order := argsortFloats(x)
+	for _, i := range order {
+		v := x[i]
+		if math.IsNaN(v) {
+			e.nan = true
+			continue
+		}
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		e.total += w
+		if n := len(e.x); n > 0 && e.x[n-1] == v {
+			e.cum[n-1] = e.total
+			continue
+		}
+		e.x = append(e.x, v)
+		e.cum = append(e.cum, e.total)
+	}
+	if !e.nan && !(e.total > 0) {
+		panic("stat: zero total weight")
+	}
+	return e
+}
+
+// Evaluate returns the fraction of the weight of the sample at values less
+// than or equal to q, as CDF with the Empirical kind.
+func (e *ECDF) Evaluate(q float64) float64 {
+	if e.nan || math.IsNaN(q) {
+		return math.NaN()
+	}
+	// The number of distinct values at most q.
+	i := sort.Search(len(e.x), func(i int) bool { return e.x[i] > q })
+	if i == 0 {
+		return 0
+	}
+	if i == len(e.x) {
+		return 1
+	}
+	return e.cum[i-1] / e.total
+}
+
+// EvaluateMany evaluates the distribution function at each of the values of
+// q, and stores the results in dst. If dst is nil, a new slice is allocated,
+// otherwise its length must equal len(q).
+func (e *ECDF) EvaluateMany(dst, q []float64) []float64 {
+	if dst == nil {
+		dst = make([]float64, len(q))
+	} else if len(dst) != len(q) {
+		panic(ErrLengthMismatch{Got: len(dst), Want: len(q)})
+	}
+	for i, v := range q {
+		dst[i] = e.Evaluate(v)
+	}
+	return dst
+}
+
+// Inverse returns the smallest value of the sample at which the distribution
+// function is at least p, as Quantile with the Empirical kind. Inverse
+// panics if p is not between 0 and 1.
+func (e *ECDF) Inverse(p float64) float64 {
+	if !(p >= 0 && p <= 1) {
+		panic("stat: percentile out of bounds")
+	}
+	if e.nan {
+		return math.NaN()
+	}
+	target := p * e.total
+	i := sort.Search(len(e.cum), func(i int) bool { return e.cum[i] >= target })
+	if i == len(e.cum) {
+		// Rounding of the cumulative weights may leave the last just
+		// below p·total for p = 1.
+		i--
+	}
+	return e.x[i]
+}
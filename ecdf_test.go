@@ -0,0 +1,104 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestECDF(t *testing.T) {
+	x := []float64{3, 1, 2, 2, 5}
+	e := NewECDF(x, nil)
+	for _, test := range []struct {
+		q, want float64
+	}{
+		{0, 0},
+		{1, 0.2},
+		{1.5, 0.2},
+		{2, 0.6},
+		{4.9, 0.8},
+		{5, 1},
+		{math.Inf(1), 1},
+		{math.Inf(-1), 0},
+	} {
+		if got := e.Evaluate(test.q); got != test.want {
+			t.Errorf("ECDF at %v mismatch. Want %v, got %v", test.q, test.want, got)
+		}
+	}
+	if !floats.Equal(x, []float64{3, 1, 2, 2, 5}) {
+		t.Errorf("NewECDF modified its input")
+	}
+	for _, test := range []struct {
+		p, want float64
+	}{
+		{0, 1}, {0.2, 1}, {0.21, 2}, {0.6, 2}, {0.61, 3}, {1, 5},
+	} {
+		if got := e.Inverse(test.p); got != test.want {
+			t.Errorf("inverse ECDF at %v mismatch. Want %v, got %v", test.p, test.want, got)
+		}
+	}
+	q := []float64{0, 2, 10}
+	if got := e.EvaluateMany(nil, q); !floats.Equal(got, []float64{0, 0.6, 1}) {
+		t.Errorf("EvaluateMany mismatch. Got %v", got)
+	}
+
+	// The ECDF agrees with CDF and Quantile of the sorted sample.
+	rnd := rand.New(rand.NewSource(1))
+	for k := 0; k < 20; k++ {
+		n := 1 + rnd.Intn(30)
+		x := make([]float64, n)
+		weights := make([]float64, n)
+		for i := range x {
+			x[i] = float64(rnd.Intn(10))
+			weights[i] = rnd.Float64()
+			if k%4 == 0 {
+				weights[i] = 2
+			}
+		}
+		e := NewECDF(x, weights)
+		sx := append([]float64(nil), x...)
+		sw := append([]float64(nil), weights...)
+		SortWeighted(sx, sw)
+		for q := -0.5; q < 10; q += 0.5 {
+			want := CDF(q, Empirical, sx, sw)
+			if got := e.Evaluate(q); math.Abs(got-want) > 1e-14 {
+				t.Errorf("case %d: ECDF at %v mismatch with CDF. Want %v, got %v", k, q, want, got)
+			}
+		}
+		for p := 0.0; p <= 1; p += 0.05 {
+			want := Quantile(p, Empirical, sx, sw)
+			if got := e.Inverse(p); got != want {
+				t.Errorf("case %d: inverse ECDF at %v mismatch with Quantile. Want %v, got %v", k, p, want, got)
+			}
+		}
+		sort.Float64s(sx)
+		if got := e.Inverse(1); got != sx[n-1] {
+			t.Errorf("case %d: inverse ECDF at 1 is %v, want the maximum %v", k, got, sx[n-1])
+		}
+	}
+
+	e = NewECDF([]float64{1, math.NaN()}, nil)
+	if !math.IsNaN(e.Evaluate(1)) || !math.IsNaN(e.Inverse(0.5)) {
+		t.Errorf("ECDF of sample with NaN: want NaN")
+	}
+
+	for i, fn := range []func(){
+		func() { NewECDF(nil, nil) },
+		func() { NewECDF([]float64{1, 2}, []float64{1}) },
+		func() { NewECDF([]float64{1, 2}, []float64{1, -1}) },
+		func() { NewECDF([]float64{1, 2}, []float64{0, 0}) },
+		func() { NewECDF([]float64{1, 2}, nil).Inverse(1.5) },
+		func() { NewECDF([]float64{1, 2}, nil).EvaluateMany(make([]float64, 1), q) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/floats"
+)
+
+// CohensD returns Cohen's d, the standardized difference between the means
+// of x and y,
+//  d = (mean(x) - mean(y)) / s_p
+// where s_p is the pooled standard deviation of x and y, as returned by
+// PooledStdDev. CohensD panics if x or y is empty or if they have fewer than
+// three values in total.
+func CohensD(x, y []float64) float64 {
+	checkTwoSamples(x, y)
+	return (Mean(x, nil) - Mean(y, nil)) / PooledStdDev([][]float64{x, y}, nil)
+}
+
+// HedgesG returns Hedges' g, Cohen's d of x and y corrected for its bias in
+// small samples,
+//  g = J(ν) d
+//  J(ν) = Γ(ν/2) / (√(ν/2) Γ((ν-1)/2))
+// where ν = n_x + n_y - 2 are the degrees of freedom of the pooled standard
+// deviation. J(ν) is close to 1 - 3/(4ν - 1), and is zero for ν = 1, where d
+// has no finite mean. HedgesG panics under the same conditions as CohensD.
+func HedgesG(x, y []float64) float64 {
+	d := CohensD(x, y)
+	return hedgesCorrection(float64(len(x)+len(y)-2)) * d
+}
+
+// hedgesCorrection returns the factor J(df) that makes Cohen's d with df
+// degrees of freedom unbiased.
+func hedgesCorrection(df float64) float64 {
+	a, _ := math.Lgamma(df / 2)
+	b, _ := math.Lgamma((df - 1) / 2)
+	return math.Exp(a-b) / math.Sqrt(df/2)
+}
+
+// GlassDelta returns Glass's Δ, the difference between the means of the
+// treatment group x and the control group divided by the standard deviation
+// of the control group alone,
+//  Δ = (mean(x) - mean(control)) / s_control
+// which does not assume equal variances of the groups. GlassDelta panics if
+// x is empty or if control has fewer than two values.
+func GlassDelta(x, control []float64) float64 {
+	if len(x) == 0 || len(control) < 2 {
+		panic("stat: too few samples")
+	}
+	mc, sc := MeanStdDev(control, nil)
+	return (Mean(x, nil) - mc) / sc
+}
+
+// CohensDPaired returns Cohen's d_z for paired samples, the mean of the
+// differences x_i - y_i divided by their standard deviation,
+//  d_z = mean(x - y) / s_{x-y}
+// This is the t statistic of TTestPaired divided by √n, and unlike CohensD
+// of the two samples it depends on the correlation between them. CohensDPaired
+// panics if x and y have different lengths or if there are fewer than two
+// pairs.
+func CohensDPaired(x, y []float64) float64 {
+	checkLengths(x, y)
+	if len(x) < 2 {
+		panic("stat: too few samples")
+	}
+	d := make([]float64, len(x))
+	floats.SubTo(d, x, y)
+	mean, std := MeanStdDev(d, nil)
+	return mean / std
+}
+
+// CohensDInterval returns the confidence interval of Cohen's d of x and y at
+// the confidence level level, assuming normal samples with equal variances.
+// The interval is that of the noncentrality δ of the noncentral t
+// distribution of the two-sample t statistic
+//  t = d √(n_x n_y / (n_x + n_y))
+// with n_x + n_y - 2 degrees of freedom, scaled to d, which is exact rather
+// than based on the large-sample standard error of d. The limits of δ are
+// found by inverting the noncentral t distribution function, which uses a
+// normal approximation for |δ| above about 37. CohensDInterval panics under
+// the same conditions as CohensD, or if level is not between 0 and 1.
+func CohensDInterval(x, y []float64, level float64) (lo, hi float64) {
+	d := CohensD(x, y)
+	nx, ny := float64(len(x)), float64(len(y))
+	scale := math.Sqrt(nx * ny / (nx + ny))
+	lo, hi = noncentralityInterval(d*scale, nx+ny-2, level)
+	return lo / scale, hi / scale
+}
+
+// HedgesGInterval returns the confidence interval of Hedges' g of x and y at
+// the confidence level level, the interval of CohensDInterval multiplied by
+// the bias correction of HedgesG.
+func HedgesGInterval(x, y []float64, level float64) (lo, hi float64) {
+	lo, hi = CohensDInterval(x, y, level)
+	j := hedgesCorrection(float64(len(x) + len(y) - 2))
+	return j * lo, j * hi
+}
+
+// CohensDPairedInterval returns the confidence interval of Cohen's d_z of the
+// paired samples x and y at the confidence level level, from the noncentral t
+// distribution of the paired t statistic d_z √n with n - 1 degrees of
+// freedom, as for CohensDInterval. CohensDPairedInterval panics under the
+// same conditions as CohensDPaired, or if level is not between 0 and 1.
+func CohensDPairedInterval(x, y []float64, level float64) (lo, hi float64) {
+	d := CohensDPaired(x, y)
+	n := float64(len(x))
+	scale := math.Sqrt(n)
+	lo, hi = noncentralityInterval(d*scale, n-1, level)
+	return lo / scale, hi / scale
+}
+
+// noncentralityInterval returns the confidence interval at the confidence
+// level level of the noncentrality of the noncentral t distribution with df
+// degrees of freedom from the observed statistic t. The limits are the
+// noncentralities for which t is the (1+level)/2 and (1-level)/2 quantile,
+// found by bisection since the distribution function decreases with the
+// noncentrality.
+func noncentralityInterval(t, df, level float64) (lo, hi float64) {
+	if !(level > 0 && level < 1) {
+		panic("stat: confidence level out of range")
+	}
+	if math.IsNaN(t) || math.IsInf(t, 0) {
+		return math.NaN(), math.NaN()
+	}
+	solve := func(p float64) float64 {
+		a, b := t-1, t+1
+		for step := 1.0; noncentralTCDF(t, df, a) < p; step *= 2 {
+			a -= step
+		}
+		for step := 1.0; noncentralTCDF(t, df, b) > p; step *= 2 {
+			b += step
+		}
+		for i := 0; i < 200; i++ {
+			mid := (a + b) / 2
+			if mid == a || mid == b {
+				break
+			}
+			if noncentralTCDF(t, df, mid) > p {
+				a = mid
+			} else {
+				b = mid
+			}
+		}
+		return (a + b) / 2
+	}
+	return solve((1 + level) / 2), solve((1 - level) / 2)
+}
+
+// checkTwoSamples panics if x or y is empty or if they have fewer than three
+// values in total, too few for a pooled standard deviation.
+func checkTwoSamples(x, y []float64) {
+	if len(x) == 0 || len(y) == 0 || len(x)+len(y) < 3 {
+		panic("stat: too few samples")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEffectSizes(t *testing.T) {
+	x := []float64{5.1, 4.8, 6.2, 5.9, 6.5, 5.5, 4.9, 6.0}
+	y := []float64{4.2, 5.0, 4.6, 3.9, 5.3, 4.4, 4.8}
+	a := []float64{10.1, 12.3, 9.8, 11.5, 13.0, 10.7}
+	b := []float64{9.5, 11.0, 9.9, 10.2, 12.1, 10.0}
+	for _, test := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"Cohen's d", CohensD(x, y), 1.7837109878265334},
+		{"Cohen's d reversed", CohensD(y, x), -1.7837109878265334},
+		{"Hedges' g", HedgesG(x, y), 1.6784407665804055},
+		{"Glass's delta", GlassDelta(x, y), 2.1112084423278827},
+		{"paired Cohen's d", CohensDPaired(a, b), 1.4973925096831546},
+	} {
+		if math.Abs(test.got-test.want) > 1e-14 {
+			t.Errorf("%s mismatch. Want %v, got %v", test.name, test.want, test.got)
+		}
+	}
+	// The paired effect size is the paired t statistic divided by √n.
+	if got, want := CohensDPaired(a, b), TTestPaired(a, b, 0.95).T/math.Sqrt(6); math.Abs(got-want) > 1e-14 {
+		t.Errorf("paired Cohen's d does not match t statistic. Want %v, got %v", want, got)
+	}
+	// The bias correction is close to its usual approximation.
+	for _, df := range []float64{5, 13, 100} {
+		if j := hedgesCorrection(df); math.Abs(j-(1-3/(4*df-1))) > 0.1/(df*df) {
+			t.Errorf("bias correction for %v degrees of freedom: got %v", df, j)
+		}
+	}
+
+	// The interval limits were computed by inverting the noncentral t
+	// distribution function evaluated by numerical integration.
+	j := hedgesCorrection(13)
+	for _, test := range []struct {
+		name           string
+		f              func() (lo, hi float64)
+		wantLo, wantHi float64
+		tol            float64
+	}{
+		{"Cohen's d", func() (lo, hi float64) { return CohensDInterval(x, y, 0.95) }, 0.5398023593001133, 2.980653711467278, 1e-8},
+		{"Hedges' g", func() (lo, hi float64) { return HedgesGInterval(x, y, 0.95) }, j * 0.5398023593001133, j * 2.980653711467278, 1e-8},
+		{"paired Cohen's d", func() (lo, hi float64) { return CohensDPairedInterval(a, b, 0.9) }, 0.4398043205320404, 2.4633031355770685, 1e-8},
+	} {
+		lo, hi := test.f()
+		if math.Abs(lo-test.wantLo) > test.tol || math.Abs(hi-test.wantHi) > test.tol {
+			t.Errorf("%s interval mismatch. Want [%v, %v], got [%v, %v]", test.name, test.wantLo, test.wantHi, lo, hi)
+		}
+	}
+
+	// For large samples the interval approaches d ± z σ with the asymptotic
+	// standard error
+	//  σ² = (n_x + n_y)/(n_x n_y) + d²/(2(n_x + n_y))
+	rnd := rand.New(rand.NewSource(1))
+	u := make([]float64, 2000)
+	v := make([]float64, 3000)
+	for i := range u {
+		u[i] = rnd.NormFloat64() + 0.3
+	}
+	for i := range v {
+		v[i] = rnd.NormFloat64()
+	}
+	d := CohensD(u, v)
+	lo, hi := CohensDInterval(u, v, 0.95)
+	sigma := math.Sqrt(5000.0/(2000*3000) + d*d/(2*5000))
+	if math.Abs(lo-(d-1.96*sigma)) > 1e-3 || math.Abs(hi-(d+1.96*sigma)) > 1e-3 {
+		t.Errorf("large sample interval mismatch. Want about [%v, %v], got [%v, %v]", d-1.96*sigma, d+1.96*sigma, lo, hi)
+	}
+
+	// The intervals contain d for effects too large for the series of the
+	// noncentral t distribution function.
+	for _, shift := range []float64{6, 8, 20} {
+		u := make([]float64, 100)
+		v := make([]float64, 100)
+		for i := range u {
+			v[i] = rnd.NormFloat64()
+			u[i] = v[i] + shift + 0.1*rnd.NormFloat64()
+			v[i] += 0.1 * rnd.NormFloat64()
+		}
+		d := CohensD(u, v)
+		sigma := math.Sqrt(200.0/(100*100) + d*d/(2*200))
+		lo, hi := CohensDInterval(u, v, 0.95)
+		if !(lo < d && d < hi) || math.Abs(hi-lo-2*1.96*sigma) > 0.1*sigma {
+			t.Errorf("shift %v: Cohen's d interval [%v, %v] inconsistent with d = %v", shift, lo, hi, d)
+		}
+		d = CohensDPaired(u, v)
+		lo, hi = CohensDPairedInterval(u, v, 0.95)
+		if !(lo < d && d < hi) {
+			t.Errorf("shift %v: paired interval [%v, %v] does not contain d = %v", shift, lo, hi, d)
+		}
+	}
+
+	for i, fn := range []func(){
+		func() { CohensD(nil, y) },
+		func() { CohensD([]float64{1}, []float64{2}) },
+		func() { HedgesG(x, nil) },
+		func() { GlassDelta(x, []float64{1}) },
+		func() { CohensDPaired(a, b[1:]) },
+		func() { CohensDPaired([]float64{1}, []float64{2}) },
+		func() { CohensDInterval(x, y, 1) },
+		func() { CohensDPairedInterval(a, b, 0) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// EWMA accumulates the exponentially weighted mean and variance of a stream
+// of observations, without storing them. After n observations, the
+// observation i has weight
+//  w_i = (1-α)^(n-1-i)
+// with adjusted weighting, and
+//  w_0 = (1-α)^(n-1), w_i = α (1-α)^(n-1-i) for i > 0
+// without, for which the mean follows the recursion
+//  m_i = (1-α) m_{i-1} + α x_i
+// from m_0 = x_0. The weightings agree for long series, but the adjusted one
+// does not give the first observation excess weight in a short series. They
+// are those of ewm in pandas with adjust set to true and false.
+//
+// The weights of the observations are decayed at each addition, and the mean
+// and the weighted sum of the squared deviations from it are updated by the
+// weighted form of Welford's algorithm, as in Moments.
+type EWMA struct {
+	alpha  float64
+	adjust bool
+
+	n      int
+	sumW   float64
+	sumW2  float64
+	mean   float64
+	sqDevs float64
+}
+
+// NewEWMA returns an EWMA with no observations, with the smoothing factor
+// alpha and the weighting specified by adjust. NewEWMA panics if alpha is not
+// in (0, 1].
+func NewEWMA(alpha float64, adjust bool) *EWMA {
+	if !(alpha > 0 && alpha <= 1) {
+		panic("stat: smoothing factor out of range")
+	}
+	return &EWMA{alpha: alpha, adjust: adjust}
+}
+
+// HalfLifeAlpha returns the smoothing factor
+//  α = 1 - exp(-ln 2 / h)
+// for which the weight of an observation halves after h further
+// observations. HalfLifeAlpha panics if h is not positive.
+func HalfLifeAlpha(h float64) float64 {
+	if !(h > 0) {
+		panic("stat: non-positive half-life")
+	}
+	return -math.Expm1(-math.Ln2 / h)
+}
+
+// Add adds the observation x.
+func (e *EWMA) Add(x float64) {
+	decay := 1 - e.alpha
+	e.sumW *= decay
+	e.sumW2 *= decay * decay
+	e.sqDevs *= decay
+	w := 1.0
+	if !e.adjust && e.n > 0 {
+		w = e.alpha
+	}
+	e.n++
+	e.sumW += w
+	e.sumW2 += w * w
+	d := x - e.mean
+	e.mean += w / e.sumW * d
+	e.sqDevs += w * d * (x - e.mean)
+}
+
+// Count returns the number of observations.
+func (e *EWMA) Count() int {
+	return e.n
+}
+
+// Mean returns the exponentially weighted mean of the observations,
+//  \sum_i w_i x_i / \sum_i w_i
+// or NaN if there are none.
+func (e *EWMA) Mean() float64 {
+	if e.n == 0 {
+		return math.NaN()
+	}
+	return e.mean
+}
+
+// Variance returns the bias-corrected exponentially weighted variance of the
+// observations,
+//  V = \sum_i w_i (x_i - m)² / \sum_i w_i × (\sum_i w_i)² / ((\sum_i w_i)² - \sum_i w_i²)
+// which is unbiased for independent observations with equal variances, as
+// var of ewm in pandas. It is NaN if there are fewer than two observations.
+func (e *EWMA) Variance() float64 {
+	if e.n < 2 {
+		return math.NaN()
+	}
+	return e.sqDevs * e.sumW / (e.sumW*e.sumW - e.sumW2)
+}
+
+// StdDev returns the square root of the variance returned by Variance.
+func (e *EWMA) StdDev() float64 {
+	return math.Sqrt(e.Variance())
+}
+
+// ExpMovingMean computes the exponentially weighted moving mean of x with
+// the smoothing factor alpha and adjusted weighting, so that dst[i] is the
+// mean returned by an EWMA after the addition of x[0] through x[i]. The
+// recursive moving mean is obtained with an EWMA without adjusted weighting.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored in
+// dst and dst is returned. dst may be x itself. ExpMovingMean panics if
+// alpha is not in (0, 1] or if a non-nil dst has the wrong length.
+func ExpMovingMean(dst, x []float64, alpha float64) []float64 {
+	e := NewEWMA(alpha, true)
+	dst = reuseFloats(dst, len(x))
+	for i, v := range x {
+		e.Add(v)
+		dst[i] = e.Mean()
+	}
+	return dst
+}
@@ -0,0 +1,107 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEWMA(t *testing.T) {
+	// Values computed from the explicit weights, which agree with ewm in
+	// pandas.
+	x := []float64{1, 3, 2, 5, 4}
+	for _, test := range []struct {
+		adjust    bool
+		mean, vrc []float64
+	}{
+		{
+			adjust: true,
+			mean:   []float64{1, 2.1764705882352944, 2.095890410958904, 3.24240031583103, 3.5155962641087593},
+			vrc:    []float64{math.NaN(), 2.0000000000000004, 0.8356164383561641, 3.2842082682112106, 2.1635668559814465},
+		},
+		{
+			adjust: false,
+			mean:   []float64{1, 1.5999999999999999, 1.7199999999999998, 2.704, 3.0928000000000004},
+			vrc:    []float64{math.NaN(), 1.9999999999999996, 0.9932885906040265, 3.7079937575862676, 2.884833383755045},
+		},
+	} {
+		e := NewEWMA(0.3, test.adjust)
+		if !math.IsNaN(e.Mean()) || e.Count() != 0 {
+			t.Errorf("adjust %t: empty EWMA has mean %v and count %d", test.adjust, e.Mean(), e.Count())
+		}
+		for i, v := range x {
+			e.Add(v)
+			if e.Count() != i+1 {
+				t.Errorf("adjust %t: count mismatch. Want %d, got %d", test.adjust, i+1, e.Count())
+			}
+			if math.Abs(e.Mean()-test.mean[i]) > 1e-14 {
+				t.Errorf("adjust %t: mean mismatch after %d observations. Want %v, got %v", test.adjust, i+1, test.mean[i], e.Mean())
+			}
+			if !sameOrClose(e.Variance(), test.vrc[i], 1e-13) {
+				t.Errorf("adjust %t: variance mismatch after %d observations. Want %v, got %v", test.adjust, i+1, test.vrc[i], e.Variance())
+			}
+			if !sameOrClose(e.StdDev(), math.Sqrt(test.vrc[i]), 1e-13) {
+				t.Errorf("adjust %t: standard deviation mismatch after %d observations", test.adjust, i+1)
+			}
+		}
+	}
+
+	// With α = 1 only the last observation counts, and the unadjusted mean
+	// follows the recursion.
+	e := NewEWMA(1, true)
+	e.Add(3)
+	e.Add(7)
+	if e.Mean() != 7 {
+		t.Errorf("mean with α = 1: want 7, got %v", e.Mean())
+	}
+	rnd := rand.New(rand.NewSource(1))
+	e = NewEWMA(0.1, false)
+	var m float64
+	for i := 0; i < 1000; i++ {
+		v := 1e6 + rnd.NormFloat64()
+		e.Add(v)
+		if i == 0 {
+			m = v
+		} else {
+			m = 0.9*m + 0.1*v
+		}
+		if math.Abs(e.Mean()-m) > 1e-8 {
+			t.Fatalf("unadjusted mean departs from the recursion at %d: want %v, got %v", i, m, e.Mean())
+		}
+	}
+	// The variance of a long series of unit variance is about 1.
+	if v := e.Variance(); math.Abs(v-1) > 0.5 {
+		t.Errorf("variance of unit noise: got %v", v)
+	}
+
+	// A weight halves after the half-life.
+	alpha := HalfLifeAlpha(10)
+	if w := math.Pow(1-alpha, 10); math.Abs(w-0.5) > 1e-15 {
+		t.Errorf("weight after half-life: want 0.5, got %v", w)
+	}
+
+	got := ExpMovingMean(nil, x, 0.3)
+	if !sameFloatsNaN(got, []float64{1, 2.1764705882352944, 2.095890410958904, 3.24240031583103, 3.5155962641087593}, 1e-14) {
+		t.Errorf("ExpMovingMean mismatch. Got %v", got)
+	}
+	inPlace := append([]float64(nil), x...)
+	ExpMovingMean(inPlace, inPlace, 0.3)
+	if !sameFloatsNaN(inPlace, got, 0) {
+		t.Errorf("in-place ExpMovingMean mismatch. Want %v, got %v", got, inPlace)
+	}
+
+	for i, fn := range []func(){
+		func() { NewEWMA(0, true) },
+		func() { NewEWMA(1.5, false) },
+		func() { HalfLifeAlpha(0) },
+		func() { ExpMovingMean(make([]float64, 2), x, 0.3) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
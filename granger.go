@@ -0,0 +1,84 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// GrangerCausality tests whether the series y Granger-causes the series x,
+// that is whether the lags of y improve the prediction of x beyond the lags
+// of x itself. The number of lags is chosen in 1, ..., maxLag by minimizing
+// the BIC of the unrestricted regression, with all candidate regressions
+// fitted to the observations from maxLag on so that they are comparable.
+// The test is then performed as by GrangerCausalityLag with the chosen lag.
+//
+// To test the opposite direction, swap the arguments.
+func GrangerCausality(x, y []float64, maxLag int) (fStat, p float64, lagUsed int) {
+	if maxLag < 1 {
+		panic("stat: non-positive lag")
+	}
+	best := math.Inf(1)
+	for lag := 1; lag <= maxLag; lag++ {
+		l := grangerFit(x, y, lag, maxLag, true)
+		if bic := l.bic(); bic < best {
+			best = bic
+			lagUsed = lag
+		}
+	}
+	fStat, p = GrangerCausalityLag(x, y, lagUsed)
+	return fStat, p, lagUsed
+}
+
+// GrangerCausalityLag tests whether the series y Granger-causes the series x
+// using the given number of lags. The restricted model regresses x_t on a
+// constant and x_{t-1}, ..., x_{t-lag}, and the unrestricted model adds
+// y_{t-1}, ..., y_{t-lag}, both fitted by least squares to the observations
+// t = lag, ..., n-1. The statistic
+//  F = ((SSR_r - SSR_u) / lag) / (SSR_u / (n - 3 lag - 1))
+// is compared with the F distribution with lag and n - 3 lag - 1 degrees of
+// freedom. This is the ssr_ftest of statsmodels' grangercausalitytests.
+//
+// GrangerCausalityLag panics if x and y have different lengths, if lag is
+// not positive or if the series are too short for the regressions.
+func GrangerCausalityLag(x, y []float64, lag int) (fStat, p float64) {
+	if lag < 1 {
+		panic("stat: non-positive lag")
+	}
+	restricted := grangerFit(x, y, lag, lag, false)
+	unrestricted := grangerFit(x, y, lag, lag, true)
+	df := float64(unrestricted.n - unrestricted.p)
+	fStat = (restricted.ssr - unrestricted.ssr) / float64(lag) / (unrestricted.ssr / df)
+	return fStat, fSurvival(fStat, float64(lag), df)
+}
+
+// grangerFit regresses x_t for t ≥ start on a constant and lag lags of x,
+// and of y if withY is true.
+func grangerFit(x, y []float64, lag, start int, withY bool) *lstsq {
+	checkLengths(x, y)
+	n := len(x) - start
+	p := 1 + lag
+	if withY {
+		p += lag
+	}
+	if n <= p {
+		panic("stat: series too short for Granger causality test")
+	}
+	design := mat64.NewDense(n, p, nil)
+	for i := 0; i < n; i++ {
+		t := start + i
+		row := design.RawRowView(i)
+		row[0] = 1
+		for j := 1; j <= lag; j++ {
+			row[j] = x[t-j]
+			if withY {
+				row[lag+j] = y[t-j]
+			}
+		}
+	}
+	return fitLstsq(design, x[start:])
+}
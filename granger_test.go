@@ -0,0 +1,69 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGrangerCausality(t *testing.T) {
+	// The statistics and p-values of the ssr_ftest of statsmodels'
+	// grangercausalitytests(np.column_stack([x, y]), maxlag=3), evaluated
+	// with exact rational least squares and the F survival function in
+	// closed form for these degrees of freedom.
+	x := []float64{2, 7, 1, 8, 2, 8, 1, 8, 2, 8, 4, 5, 9, 0, 4, 5, 2, 3, 5, 3, 6, 0, 2, 8}
+	y := []float64{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9, 7, 9, 3, 2, 3, 8, 4, 6, 2, 6, 4}
+	for _, test := range []struct {
+		lag  int
+		f, p float64
+	}{
+		{1, 0.41355563066788453, 0.5274753142936361},
+		{2, 0.7077846014963434, 0.5066897080463054},
+		{3, 0.9834415200862795, 0.42870089250593224},
+	} {
+		f, p := GrangerCausalityLag(x, y, test.lag)
+		if math.Abs(f-test.f) > 1e-12 {
+			t.Errorf("lag %d: statistic mismatch. Want %v, got %v", test.lag, test.f, f)
+		}
+		if math.Abs(p-test.p) > 1e-12 {
+			t.Errorf("lag %d: p-value mismatch. Want %v, got %v", test.lag, test.p, p)
+		}
+	}
+
+	// y leads x by two steps.
+	rnd := rand.New(rand.NewSource(1))
+	n := 300
+	a := make([]float64, n)
+	b := make([]float64, n)
+	for i := range a {
+		b[i] = rnd.NormFloat64()
+		a[i] = 0.5 * rnd.NormFloat64()
+		if i >= 2 {
+			a[i] += 0.8 * b[i-2]
+		}
+	}
+	f, p, lag := GrangerCausality(a, b, 5)
+	if lag != 2 {
+		t.Errorf("selected lag mismatch. Want 2, got %d", lag)
+	}
+	if p > 1e-10 {
+		t.Errorf("causality not detected: F = %v, p = %v", f, p)
+	}
+	if _, p, _ := GrangerCausality(b, a, 5); p < 0.05 {
+		t.Errorf("causality detected in the non-causal direction: p = %v", p)
+	}
+
+	if !Panics(func() { GrangerCausalityLag(x, y[1:], 1) }) {
+		t.Errorf("GrangerCausalityLag did not panic with length mismatch")
+	}
+	if !Panics(func() { GrangerCausalityLag(x, y, 0) }) {
+		t.Errorf("GrangerCausalityLag did not panic with zero lag")
+	}
+	if !Panics(func() { GrangerCausality(x[:6], y[:6], 2) }) {
+		t.Errorf("GrangerCausality did not panic with short series")
+	}
+}
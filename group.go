@@ -0,0 +1,187 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// GroupSummary holds the summary statistics of a group of observations
+// returned by GroupStats.
+type GroupSummary struct {
+	// Count is the sum of the weights of the observations in the group,
+	// which is their number if all of the weights are 1.
+	Count float64
+
+	// Sum is the weighted sum of the observations.
+	Sum float64
+
+	Mean, Variance float64
+
+	// Min and Max are the smallest and largest observations with non-zero
+	// weight.
+	Min, Max float64
+}
+
+// GroupStats returns the count, weighted sum, mean, sample variance and range
+// of the observations of x in each group, where the groups are coded as the
+// integers 0, 1, 2, ... by labels. Element k of the result summarizes the
+// observations x[i] with labels[i] == k, and the result has max(labels)+1
+// elements. The statistics are accumulated in a single pass over x, with the
+// mean and variance as by Moments, so they equal those computed by Mean and
+// Variance for the observations of each group up to rounding. A group with no
+// observations of non-zero weight has a zero Count and Sum and NaN for the
+// rest, and a group containing NaN has NaN statistics. String labels can be
+// coded with GroupLabels.
+//
+// If weights is nil then all of the weights are 1. If weights is not nil, then
+// its length must equal len(x), and the weights must not be negative.
+// GroupStats panics if the lengths of labels and x differ, if they are empty
+// or if a label is negative.
+func GroupStats(labels []int, x, weights []float64) []GroupSummary {
+	k := checkGroups(labels, x, weights)
+	moments := make([]Moments, k)
+	stats := make([]GroupSummary, k)
+	for g := range stats {
+		stats[g].Min = math.Inf(1)
+		stats[g].Max = math.Inf(-1)
+	}
+	for i, v := range x {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		g := labels[i]
+		moments[g].Add(v, w)
+		if w == 0 {
+			continue
+		}
+		s := &stats[g]
+		s.Sum += w * v
+		switch {
+		case math.IsNaN(s.Min):
+		case math.IsNaN(v):
+			s.Min = v
+			s.Max = v
+		default:
+			s.Min = math.Min(s.Min, v)
+			s.Max = math.Max(s.Max, v)
+		}
+	}
+	for g := range stats {
+		m := &moments[g]
+		s := &stats[g]
+		if m.Count() == 0 {
+			nan := math.NaN()
+			*s = GroupSummary{Mean: nan, Variance: nan, Min: nan, Max: nan}
+			continue
+		}
+		s.Count = m.Count()
+		s.Mean = m.Mean()
+		s.Variance = m.Variance()
+	}
+	return stats
+}
+
+// GroupQuantiles returns the weighted quantiles at ps of the observations of
+// x in each group, as returned by Quantile with kind c for the sorted
+// observations of the group. Element (i, k) of the result is the quantile at
+// ps[i] of the group with label k. A group with no observations has NaN
+// quantiles. If dst is nil, a new matrix is allocated, otherwise the quantiles
+// are stored in dst, which must be len(ps)×(max(labels)+1). The labels and
+// weights are as for GroupStats, and x and weights are not modified.
+func GroupQuantiles(dst *mat64.Dense, ps []float64, c CumulantKind, labels []int, x, weights []float64) *mat64.Dense {
+	k := checkGroups(labels, x, weights)
+	if dst == nil {
+		dst = mat64.NewDense(len(ps), k, nil)
+	} else if r, cols := dst.Dims(); r != len(ps) || cols != k {
+		panic(mat64.ErrShape)
+	}
+
+	// Order the observations by group, keeping their order within each
+	// group, by a counting sort of the labels.
+	start := make([]int, k+1)
+	for _, g := range labels {
+		start[g+1]++
+	}
+	for g := 0; g < k; g++ {
+		start[g+1] += start[g]
+	}
+	next := make([]int, k)
+	copy(next, start)
+	xs := make([]float64, len(x))
+	var ws []float64
+	if weights != nil {
+		ws = make([]float64, len(x))
+	}
+	for i, g := range labels {
+		xs[next[g]] = x[i]
+		if weights != nil {
+			ws[next[g]] = weights[i]
+		}
+		next[g]++
+	}
+
+	for g := 0; g < k; g++ {
+		gx := xs[start[g]:start[g+1]]
+		var gw []float64
+		if weights != nil {
+			gw = ws[start[g]:start[g+1]]
+		}
+		if len(gx) == 0 {
+			for i, p := range ps {
+				if !(p >= 0 && p <= 1) {
+					panic("stat: percentile out of bounds")
+				}
+				dst.Set(i, g, math.NaN())
+			}
+			continue
+		}
+		SortWeighted(gx, gw)
+		for i, p := range ps {
+			dst.Set(i, g, Quantile(p, c, gx, gw))
+		}
+	}
+	return dst
+}
+
+// GroupLabels returns the coding of the string labels as the integers
+// 0, 1, 2, ... used by GroupStats and GroupQuantiles, along with the map
+// from the labels to their codes. Labels are assigned codes in sorted order,
+// so that the order of the groups does not depend on the order of the
+// observations.
+func GroupLabels(labels []string) (codes []int, index map[string]int) {
+	index = labelIndex(labels)
+	codes = make([]int, len(labels))
+	for i, l := range labels {
+		codes[i] = index[l]
+	}
+	return codes, index
+}
+
+// checkGroups returns the number of groups, max(labels)+1, panicking if the
+// lengths of labels, x and weights differ, if they are empty or if a label
+// is negative.
+func checkGroups(labels []int, x, weights []float64) int {
+	if len(labels) != len(x) {
+		panic(ErrLengthMismatch{Got: len(x), Want: len(labels)})
+	}
+	checkWeightLength(x, weights)
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	var k int
+	for _, g := range labels {
+		if g < 0 {
+			panic("stat: negative label")
+		}
+		if g >= k {
+			k = g + 1
+		}
+	}
+	return k
+}
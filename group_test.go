@@ -0,0 +1,144 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestGroupStats(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const n, k = 500, 5
+	labels := make([]int, n)
+	x := make([]float64, n)
+	wts := make([]float64, n)
+	for i := range x {
+		// Group 3 is left empty.
+		labels[i] = rnd.Intn(k - 1)
+		if labels[i] == 3 {
+			labels[i] = 4
+		}
+		x[i] = 10*float64(labels[i]) + rnd.ExpFloat64()
+		wts[i] = rnd.Float64()
+	}
+	ps := []float64{0, 0.1, 0.5, 0.75, 1}
+	for _, w := range [][]float64{nil, wts} {
+		xCopy := append([]float64(nil), x...)
+		stats := GroupStats(labels, x, w)
+		q := GroupQuantiles(nil, ps, Empirical, labels, x, w)
+		if len(stats) != k {
+			t.Fatalf("unexpected number of groups %d", len(stats))
+		}
+		if r, c := q.Dims(); r != len(ps) || c != k {
+			t.Fatalf("unexpected quantile dimensions %d×%d", r, c)
+		}
+		if !floats.Equal(x, xCopy) {
+			t.Errorf("input modified")
+		}
+		for g, s := range stats {
+			var gx, gw []float64
+			for i, l := range labels {
+				if l == g {
+					gx = append(gx, x[i])
+					if w != nil {
+						gw = append(gw, w[i])
+					}
+				}
+			}
+			if gx == nil {
+				if s.Count != 0 || s.Sum != 0 || !math.IsNaN(s.Mean) || !math.IsNaN(s.Variance) || !math.IsNaN(s.Min) || !math.IsNaN(s.Max) {
+					t.Errorf("group %d: expected empty summary, got %+v", g, s)
+				}
+				for i := range ps {
+					if !math.IsNaN(q.At(i, g)) {
+						t.Errorf("group %d: expected NaN quantile, got %v", g, q.At(i, g))
+					}
+				}
+				continue
+			}
+			count := float64(len(gx))
+			sum := floats.Sum(gx)
+			if w != nil {
+				count = floats.Sum(gw)
+				sum = floats.Dot(gx, gw)
+			}
+			for _, v := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"count", s.Count, count},
+				{"sum", s.Sum, sum},
+				{"mean", s.Mean, Mean(gx, gw)},
+				{"variance", s.Variance, Variance(gx, gw)},
+			} {
+				if !floats.EqualWithinAbsOrRel(v.got, v.want, 1e-12, 1e-12) {
+					t.Errorf("group %d: %s mismatch: got %v, want %v", g, v.name, v.got, v.want)
+				}
+			}
+			if s.Min != floats.Min(gx) || s.Max != floats.Max(gx) {
+				t.Errorf("group %d: range mismatch: got [%v, %v], want [%v, %v]", g, s.Min, s.Max, floats.Min(gx), floats.Max(gx))
+			}
+			SortWeighted(gx, gw)
+			for i, p := range ps {
+				if got, want := q.At(i, g), Quantile(p, Empirical, gx, gw); got != want {
+					t.Errorf("group %d: quantile %v mismatch: got %v, want %v", g, p, got, want)
+				}
+			}
+		}
+	}
+
+	// String labels are coded in sorted order, and zero weights and NaN
+	// are handled as for ColumnStats.
+	codes, index := GroupLabels([]string{"b", "a", "c", "b", "a", "c"})
+	if want := []int{1, 0, 2, 1, 0, 2}; !intsEqual(codes, want) {
+		t.Errorf("label codes mismatch: got %v, want %v", codes, want)
+	}
+	if len(index) != 3 || index["a"] != 0 || index["b"] != 1 || index["c"] != 2 {
+		t.Errorf("label index mismatch: got %v", index)
+	}
+	stats := GroupStats(codes, []float64{1, 2, math.NaN(), 5, 4, 3}, []float64{1, 1, 1, 1, 1, 0})
+	if s := stats[0]; s.Count != 2 || s.Sum != 6 || s.Mean != 3 || s.Variance != 2 || s.Min != 2 || s.Max != 4 {
+		t.Errorf("group a mismatch: got %+v", s)
+	}
+	if s := stats[1]; s.Count != 2 || s.Mean != 3 || s.Min != 1 || s.Max != 5 {
+		t.Errorf("group b mismatch: got %+v", s)
+	}
+	if s := stats[2]; !math.IsNaN(s.Mean) || !math.IsNaN(s.Sum) || !math.IsNaN(s.Min) || !math.IsNaN(s.Max) {
+		t.Errorf("expected NaN statistics for group c, got %+v", s)
+	}
+
+	for i, fn := range []func(){
+		func() { GroupStats([]int{0, 1}, []float64{1}, nil) },
+		func() { GroupStats([]int{0, 1}, []float64{1, 2}, []float64{1}) },
+		func() { GroupStats(nil, nil, nil) },
+		func() { GroupStats([]int{0, -1}, []float64{1, 2}, nil) },
+		func() { GroupStats([]int{0, 1}, []float64{1, 2}, []float64{1, -1}) },
+		func() { GroupQuantiles(mat64.NewDense(1, 1, nil), ps, Empirical, labels, x, nil) },
+		func() { GroupQuantiles(nil, []float64{1.5}, Empirical, labels, x, nil) },
+		func() { GroupQuantiles(nil, []float64{-1}, Empirical, []int{2}, []float64{1}, nil) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+// intsEqual returns whether a and b have the same elements.
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if v != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// SeasonalKind specifies how the seasonal component enters a Holt–Winters
+// model.
+type SeasonalKind int
+
+const (
+	// AdditiveSeasonal models the series as level + trend + seasonal.
+	AdditiveSeasonal SeasonalKind = iota
+	// MultiplicativeSeasonal models the series as (level + trend) * seasonal.
+	// All of the values in the series must be positive.
+	MultiplicativeSeasonal
+)
+
+// HoltWintersModel is a Holt–Winters exponential smoothing model fitted to a
+// series of length n.
+//
+// Level, Trend and Season hold the smoothed states after each observation.
+// The states are initialized at index Period-1, and the first Period elements
+// of Season hold the initial seasonal components. Fitted holds the one-step
+// ahead predictions of the series. Elements before the initialization are NaN.
+// SSE is the sum of squared one-step ahead prediction errors.
+type HoltWintersModel struct {
+	Alpha, Beta, Gamma float64
+	Period             int
+	Seasonal           SeasonalKind
+
+	Level  []float64
+	Trend  []float64
+	Season []float64
+	Fitted []float64
+	SSE    float64
+}
+
+// HoltWinters fits a Holt–Winters model with the given seasonal period to the
+// series x. For the additive model, the recursions for the level l, the trend
+// b and the seasonal component s are
+//  l_t = α (x_t - s_{t-p}) + (1-α) (l_{t-1} + b_{t-1})
+//  b_t = β (l_t - l_{t-1}) + (1-β) b_{t-1}
+//  s_t = γ (x_t - l_t) + (1-γ) s_{t-p}
+// and for the multiplicative model the seasonal component divides rather than
+// subtracts. The one-step ahead prediction of x_t is l_{t-1} + b_{t-1} + s_{t-p}
+// or (l_{t-1} + b_{t-1}) s_{t-p} respectively.
+//
+// The initial states are obtained from the first two seasons of x. These are
+// decomposed by SeasonalDecompose, the initial seasonal components are the
+// seasonal figure, and the initial level and trend are the intercept and
+// slope of the least-squares line through the moving-average trend. The
+// recursions start with the second season. This is the heuristic used by R's
+// HoltWinters.
+//
+// If any of alpha, beta and gamma is NaN, it is estimated by minimizing the
+// SSE over [0, 1] using the Nelder–Mead method, with the remaining parameters
+// held fixed. The estimated values are stored in the returned model.
+//
+// HoltWinters panics if period is less than 2, if x has fewer than two full
+// seasons, if a parameter is outside [0, 1], or for the multiplicative model
+// if any value of x is not positive.
+func HoltWinters(x []float64, period int, alpha, beta, gamma float64, seasonal SeasonalKind) *HoltWintersModel {
+	if period < 2 {
+		panic("stat: period less than two")
+	}
+	if len(x) < 2*period {
+		panic("stat: series shorter than two periods")
+	}
+	var model DecomposeModel
+	switch seasonal {
+	case AdditiveSeasonal:
+		model = AdditiveModel
+	case MultiplicativeSeasonal:
+		model = MultiplicativeModel
+	default:
+		panic("stat: unknown seasonal kind")
+	}
+	params := []float64{alpha, beta, gamma}
+	var free []int
+	for i, v := range params {
+		if math.IsNaN(v) {
+			free = append(free, i)
+			continue
+		}
+		if v < 0 || v > 1 {
+			panic("stat: smoothing parameter out of range")
+		}
+	}
+
+	d, err := SeasonalDecompose(x[:2*period], period, model)
+	if err != nil {
+		panic(err.Error())
+	}
+	trend := d.Trend[period/2 : 2*period-period/2]
+	index := make([]float64, len(trend))
+	for i := range index {
+		index[i] = float64(i + 1)
+	}
+	l0, b0 := LinearRegression(index, trend, nil, false)
+
+	m := &HoltWintersModel{
+		Period:   period,
+		Seasonal: seasonal,
+		Level:    make([]float64, len(x)),
+		Trend:    make([]float64, len(x)),
+		Season:   make([]float64, len(x)),
+		Fitted:   make([]float64, len(x)),
+	}
+	init := func() {
+		for i := 0; i < period-1; i++ {
+			m.Level[i] = math.NaN()
+			m.Trend[i] = math.NaN()
+		}
+		for i := 0; i < period; i++ {
+			m.Fitted[i] = math.NaN()
+		}
+		m.Level[period-1] = l0
+		m.Trend[period-1] = b0
+		copy(m.Season, d.Seasonal[:period])
+	}
+
+	if len(free) > 0 {
+		lo := make([]float64, len(free))
+		hi := make([]float64, len(free))
+		x0 := make([]float64, len(free))
+		for i, j := range free {
+			hi[i] = 1
+			// The starting values used by R's HoltWinters.
+			x0[i] = []float64{0.3, 0.1, 0.1}[j]
+		}
+		sse := func(v []float64) float64 {
+			for i, j := range free {
+				params[j] = v[i]
+			}
+			m.Alpha, m.Beta, m.Gamma = params[0], params[1], params[2]
+			init()
+			m.smooth(x)
+			return m.SSE
+		}
+		best, _ := nelderMead(sse, x0, lo, hi, 1e-10, 2000)
+		for i, j := range free {
+			params[j] = best[i]
+		}
+	}
+	m.Alpha, m.Beta, m.Gamma = params[0], params[1], params[2]
+	init()
+	m.smooth(x)
+	return m
+}
+
+// smooth runs the Holt–Winters recursions over x from the initialized states,
+// filling the states, the fitted values and the SSE of m.
+func (m *HoltWintersModel) smooth(x []float64) {
+	p := m.Period
+	alpha, beta, gamma := m.Alpha, m.Beta, m.Gamma
+	mult := m.Seasonal == MultiplicativeSeasonal
+	m.SSE = 0
+	for t := p; t < len(x); t++ {
+		l, b, s := m.Level[t-1], m.Trend[t-1], m.Season[t-p]
+		if mult {
+			if x[t] <= 0 {
+				panic("stat: non-positive value in multiplicative model")
+			}
+			m.Fitted[t] = (l + b) * s
+			m.Level[t] = alpha*x[t]/s + (1-alpha)*(l+b)
+			m.Season[t] = gamma*x[t]/m.Level[t] + (1-gamma)*s
+		} else {
+			m.Fitted[t] = l + b + s
+			m.Level[t] = alpha*(x[t]-s) + (1-alpha)*(l+b)
+			m.Season[t] = gamma*(x[t]-m.Level[t]) + (1-gamma)*s
+		}
+		m.Trend[t] = beta*(m.Level[t]-l) + (1-beta)*b
+		e := x[t] - m.Fitted[t]
+		m.SSE += e * e
+	}
+}
+
+// Forecast returns the forecasts of the next h values of the series from
+// the final states of the model,
+//  l_n + k b_n + s_{n-p+(k-1) mod p}
+// for k = 1, ..., h, with the seasonal component multiplying for the
+// multiplicative model.
+func (m *HoltWintersModel) Forecast(h int) []float64 {
+	if h < 0 {
+		panic("stat: negative forecast horizon")
+	}
+	n := len(m.Level)
+	l, b := m.Level[n-1], m.Trend[n-1]
+	f := make([]float64, h)
+	for k := range f {
+		s := m.Season[n-m.Period+k%m.Period]
+		if m.Seasonal == MultiplicativeSeasonal {
+			f[k] = (l + float64(k+1)*b) * s
+		} else {
+			f[k] = l + float64(k+1)*b + s
+		}
+	}
+	return f
+}
@@ -0,0 +1,143 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHoltWinters(t *testing.T) {
+	// Parameters and coefficients reported by R's HoltWinters(AirPassengers)
+	// with type "additive" and "multiplicative".
+	for _, test := range []struct {
+		seasonal           SeasonalKind
+		alpha, beta, gamma float64
+		level, trend       float64
+		season             []float64
+		tol                float64
+	}{
+		{
+			seasonal: AdditiveSeasonal,
+			alpha:    0.2479595, beta: 0.0345373, gamma: 1,
+			level: 477.827781, trend: 3.127627,
+			season: []float64{
+				-27.457685, -54.692464, -20.174608, 12.919120, 18.873607, 75.294426,
+				152.888368, 134.613464, 33.778349, -18.379060, -87.772408, -45.827781,
+			},
+			// The reported parameters are rounded, which limits the
+			// agreement of the states.
+			tol: 5e-3,
+		},
+		{
+			seasonal: MultiplicativeSeasonal,
+			alpha:    0.2755925, beta: 0.03269295, gamma: 0.8707292,
+			level: 469.3232206, trend: 3.0215391,
+			season: []float64{
+				0.9464611, 0.8829239, 0.9717369, 1.0304825, 1.0476884, 1.1805272,
+				1.3590778, 1.3331706, 1.1083381, 0.9868813, 0.8361333, 0.9209877,
+			},
+			tol: 1e-6,
+		},
+	} {
+		n := len(airPassengers)
+		want := make([]float64, 24)
+		for k := range want {
+			s := test.season[k%12]
+			if test.seasonal == MultiplicativeSeasonal {
+				want[k] = (test.level + float64(k+1)*test.trend) * s
+			} else {
+				want[k] = test.level + float64(k+1)*test.trend + s
+			}
+		}
+
+		m := HoltWinters(airPassengers, 12, test.alpha, test.beta, test.gamma, test.seasonal)
+		if math.Abs(m.Level[n-1]-test.level) > test.tol*test.level {
+			t.Errorf("seasonal %d: level mismatch. Want %v, got %v", test.seasonal, test.level, m.Level[n-1])
+		}
+		if math.Abs(m.Trend[n-1]-test.trend) > test.tol*test.level {
+			t.Errorf("seasonal %d: trend mismatch. Want %v, got %v", test.seasonal, test.trend, m.Trend[n-1])
+		}
+		for i, s := range test.season {
+			if math.Abs(m.Season[n-12+i]-s) > test.tol*math.Max(1, math.Abs(s)) {
+				t.Errorf("seasonal %d: seasonal component %d mismatch. Want %v, got %v", test.seasonal, i, s, m.Season[n-12+i])
+			}
+		}
+		for i := 0; i < 12; i++ {
+			if !math.IsNaN(m.Fitted[i]) {
+				t.Errorf("seasonal %d: fitted value %d not NaN during initialization", test.seasonal, i)
+			}
+		}
+		var sse float64
+		for i := 12; i < n; i++ {
+			e := airPassengers[i] - m.Fitted[i]
+			sse += e * e
+		}
+		if math.Abs(sse-m.SSE) > 1e-8*sse {
+			t.Errorf("seasonal %d: SSE mismatch. Want %v, got %v", test.seasonal, sse, m.SSE)
+		}
+
+		// Estimate all of the parameters.
+		nan := math.NaN()
+		opt := HoltWinters(airPassengers, 12, nan, nan, nan, test.seasonal)
+		got := []float64{opt.Alpha, opt.Beta, opt.Gamma}
+		for i, v := range []float64{test.alpha, test.beta, test.gamma} {
+			if math.Abs(got[i]-v) > 1e-3 {
+				t.Errorf("seasonal %d: parameter %d mismatch. Want %v, got %v", test.seasonal, i, v, got[i])
+			}
+		}
+		if opt.SSE > m.SSE*(1+1e-8) {
+			t.Errorf("seasonal %d: optimized SSE %v larger than SSE at R's optimum %v", test.seasonal, opt.SSE, m.SSE)
+		}
+		f := opt.Forecast(len(want))
+		for i, v := range want {
+			if math.Abs(f[i]-v) > 1e-4*v {
+				t.Errorf("seasonal %d: forecast %d mismatch. Want %v, got %v", test.seasonal, i, v, f[i])
+			}
+		}
+
+		// Estimating a single parameter holds the others fixed.
+		part := HoltWinters(airPassengers, 12, test.alpha, nan, test.gamma, test.seasonal)
+		if part.Alpha != test.alpha || part.Gamma != test.gamma {
+			t.Errorf("seasonal %d: fixed parameters modified", test.seasonal)
+		}
+		if part.SSE > m.SSE*(1+1e-8) {
+			t.Errorf("seasonal %d: partially optimized SSE %v larger than SSE at R's optimum %v", test.seasonal, part.SSE, m.SSE)
+		}
+	}
+
+	// A series with a constant level and a fixed seasonal pattern is
+	// reproduced exactly whatever the smoothing parameters.
+	pattern := []float64{3, -1, 4, -6}
+	x := make([]float64, 20)
+	for i := range x {
+		x[i] = 10 + pattern[i%4]
+	}
+	m := HoltWinters(x, 4, 0.5, 0.2, 0.3, AdditiveSeasonal)
+	if m.SSE > 1e-20 {
+		t.Errorf("non-zero SSE for exactly seasonal series: %v", m.SSE)
+	}
+	for i, v := range m.Forecast(8) {
+		if math.Abs(v-x[i%4]) > 1e-12 {
+			t.Errorf("forecast %d mismatch for exactly seasonal series. Want %v, got %v", i, x[i%4], v)
+		}
+	}
+
+	if !Panics(func() { HoltWinters(x[:7], 4, 0.5, 0.5, 0.5, AdditiveSeasonal) }) {
+		t.Errorf("HoltWinters did not panic with short series")
+	}
+	if !Panics(func() { HoltWinters(x, 4, 1.5, 0.5, 0.5, AdditiveSeasonal) }) {
+		t.Errorf("HoltWinters did not panic with parameter out of range")
+	}
+	neg := make([]float64, len(x))
+	copy(neg, x)
+	neg[10] = -1
+	if !Panics(func() { HoltWinters(neg, 4, 0.5, 0.5, 0.5, MultiplicativeSeasonal) }) {
+		t.Errorf("HoltWinters did not panic with non-positive multiplicative series")
+	}
+	if !Panics(func() { m.Forecast(-1) }) {
+		t.Errorf("Forecast did not panic with negative horizon")
+	}
+}
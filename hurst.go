@@ -0,0 +1,125 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// HurstMethod specifies the method used to estimate the Hurst exponent.
+type HurstMethod int
+
+const (
+	// RescaledRange estimates the Hurst exponent by rescaled range (R/S)
+	// analysis. For each scale m the series is divided into non-overlapping
+	// blocks of length m, and the range of the cumulative sum of the
+	// deviations from the block mean, divided by the block standard deviation,
+	// is averaged over the blocks. The exponent is the slope of log(R/S)
+	// against log(m).
+	RescaledRange HurstMethod = iota
+	// AggregatedVariance estimates the Hurst exponent from the variance of
+	// the block means. For each scale m the series is divided into
+	// non-overlapping blocks of length m and the variance of the block means
+	// is computed. The variance scales as m^{2H-2}, so the exponent is
+	// 1 + β/2 where β is the slope of the log variance against log(m).
+	AggregatedVariance
+)
+
+// HurstExponent estimates the Hurst exponent of the series x using the
+// given method and the default schedule of scales described in
+// HurstAnalysis. For a stationary series, values near 0.5 indicate no long
+// range dependence, and values above or below 0.5 indicate persistence or
+// anti-persistence respectively.
+func HurstExponent(x []float64, method HurstMethod) float64 {
+	h, _, _ := HurstAnalysis(x, method, nil)
+	return h
+}
+
+// HurstAnalysis estimates the Hurst exponent of the series x using the given
+// method at the specified scales (block lengths), and returns the estimate
+// together with the statistic computed at each scale for diagnostic plots.
+// For RescaledRange the statistic is the mean R/S of the blocks, and for
+// AggregatedVariance it is the variance of the block means. The exponent is
+// obtained from the least-squares line through the logarithms of the
+// statistics and the scales.
+//
+// If scales is nil, the powers of two from 8 to len(x)/4 are used for
+// RescaledRange and from 2 to len(x)/8 for AggregatedVariance, so that
+// every scale has several blocks. The scales used are returned.
+//
+// HurstAnalysis panics if fewer than two scales are available or if a scale
+// is less than 2 or leaves fewer than two blocks.
+func HurstAnalysis(x []float64, method HurstMethod, scales []int) (h float64, usedScales []int, stats []float64) {
+	n := len(x)
+	if scales == nil {
+		lo, hi := 8, n/4
+		if method == AggregatedVariance {
+			lo, hi = 2, n/8
+		}
+		for m := lo; m <= hi; m *= 2 {
+			scales = append(scales, m)
+		}
+	}
+	if len(scales) < 2 {
+		panic("stat: too few scales for Hurst exponent")
+	}
+	logScale := make([]float64, len(scales))
+	logStat := make([]float64, len(scales))
+	stats = make([]float64, len(scales))
+	for i, m := range scales {
+		if m < 2 || n/m < 2 {
+			panic("stat: bad Hurst exponent scale")
+		}
+		switch method {
+		case RescaledRange:
+			stats[i] = rescaledRange(x, m)
+		case AggregatedVariance:
+			stats[i] = aggregatedVariance(x, m)
+		default:
+			panic("stat: unknown Hurst method")
+		}
+		logScale[i] = math.Log(float64(m))
+		logStat[i] = math.Log(stats[i])
+	}
+	_, beta := LinearRegression(logScale, logStat, nil, false)
+	if method == AggregatedVariance {
+		return 1 + beta/2, scales, stats
+	}
+	return beta, scales, stats
+}
+
+// rescaledRange returns the mean rescaled range of the non-overlapping
+// blocks of length m of x. Blocks with zero standard deviation are skipped.
+func rescaledRange(x []float64, m int) float64 {
+	var sum float64
+	var count int
+	for start := 0; start+m <= len(x); start += m {
+		block := x[start : start+m]
+		mean := Mean(block, nil)
+		var y, min, max, ss float64
+		for _, v := range block {
+			d := v - mean
+			y += d
+			min = math.Min(min, y)
+			max = math.Max(max, y)
+			ss += d * d
+		}
+		s := math.Sqrt(ss / float64(m))
+		if s == 0 {
+			continue
+		}
+		sum += (max - min) / s
+		count++
+	}
+	return sum / float64(count)
+}
+
+// aggregatedVariance returns the variance of the means of the
+// non-overlapping blocks of length m of x.
+func aggregatedVariance(x []float64, m int) float64 {
+	means := make([]float64, len(x)/m)
+	for i := range means {
+		means[i] = Mean(x[i*m:(i+1)*m], nil)
+	}
+	return Variance(means, nil)
+}
@@ -0,0 +1,110 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// fractionalGaussianNoise generates n samples of fractional Gaussian noise
+// with Hurst exponent h using Hosking's method, which obtains the exact
+// conditional distributions through the Durbin–Levinson recursion.
+func fractionalGaussianNoise(n int, h float64, rnd *rand.Rand) []float64 {
+	gamma := func(k int) float64 {
+		kf := float64(k)
+		return 0.5 * (math.Pow(kf+1, 2*h) - 2*math.Pow(kf, 2*h) + math.Pow(math.Abs(kf-1), 2*h))
+	}
+	x := make([]float64, n)
+	phi := make([]float64, n)
+	prev := make([]float64, n)
+	v := 1.0
+	x[0] = rnd.NormFloat64()
+	for t := 1; t < n; t++ {
+		num := gamma(t)
+		for j := 1; j < t; j++ {
+			num -= prev[j-1] * gamma(t-j)
+		}
+		k := num / v
+		for j := 1; j < t; j++ {
+			phi[j-1] = prev[j-1] - k*prev[t-j-1]
+		}
+		phi[t-1] = k
+		v *= 1 - k*k
+		copy(prev, phi[:t])
+		var m float64
+		for j := 1; j <= t; j++ {
+			m += phi[j-1] * x[t-j]
+		}
+		x[t] = m + math.Sqrt(v)*rnd.NormFloat64()
+	}
+	return x
+}
+
+func TestHurstExponent(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	n := 4096
+	noise := make([]float64, n)
+	walk := make([]float64, n)
+	for i := range noise {
+		noise[i] = rnd.NormFloat64()
+		if i > 0 {
+			walk[i] = walk[i-1] + noise[i]
+		}
+	}
+	fgn := fractionalGaussianNoise(n, 0.7, rnd)
+	for _, test := range []struct {
+		name   string
+		x      []float64
+		method HurstMethod
+		want   float64
+		tol    float64
+	}{
+		// R/S analysis is biased upwards for short blocks of white noise.
+		{"noise", noise, RescaledRange, 0.5, 0.06},
+		{"noise", noise, AggregatedVariance, 0.5, 0.06},
+		{"walk", walk, RescaledRange, 1, 0.05},
+		{"walk", walk, AggregatedVariance, 1, 0.05},
+		{"fgn", fgn, RescaledRange, 0.7, 0.05},
+		{"fgn", fgn, AggregatedVariance, 0.7, 0.05},
+	} {
+		h := HurstExponent(test.x, test.method)
+		if math.Abs(h-test.want) > test.tol {
+			t.Errorf("%s method %d: Hurst exponent mismatch. Want %v±%v, got %v", test.name, test.method, test.want, test.tol, h)
+		}
+	}
+
+	// The per-scale statistics follow the documented definitions.
+	x := []float64{1, 3, 2, 6, 4, 4, 0, 5}
+	h, scales, stats := HurstAnalysis(x, AggregatedVariance, []int{2, 4})
+	wantStats := []float64{
+		Variance([]float64{2, 4, 4, 2.5}, nil),
+		Variance([]float64{3, 3.25}, nil),
+	}
+	if scales[0] != 2 || scales[1] != 4 || math.Abs(stats[0]-wantStats[0]) > 1e-14 || math.Abs(stats[1]-wantStats[1]) > 1e-14 {
+		t.Errorf("aggregated variance statistics mismatch. Want %v, got %v", wantStats, stats)
+	}
+	wantH := 1 + math.Log(wantStats[1]/wantStats[0])/math.Log(2)/2
+	if math.Abs(h-wantH) > 1e-14 {
+		t.Errorf("aggregated variance exponent mismatch. Want %v, got %v", wantH, h)
+	}
+	_, _, stats = HurstAnalysis(x, RescaledRange, []int{2, 4})
+	// Blocks {1, 3}, {2, 6}, {4, 4}, {0, 5} have R/S of 1 except the constant
+	// block, which is skipped. For blocks of four, {1, 3, 2, 6} has
+	// cumulative deviations -2, -2, -3, 0 and standard deviation √3.5, and
+	// {4, 4, 0, 5} has 0.75, 1.5, -1.75, 0 and standard deviation √3.6875.
+	wantRS := (3/math.Sqrt(3.5) + 3.25/math.Sqrt(3.6875)) / 2
+	if stats[0] != 1 || math.Abs(stats[1]-wantRS) > 1e-14 {
+		t.Errorf("rescaled range statistics mismatch. Want [1 %v], got %v", wantRS, stats)
+	}
+
+	if !Panics(func() { HurstExponent(x, RescaledRange) }) {
+		t.Errorf("HurstExponent did not panic with too few scales")
+	}
+	if !Panics(func() { HurstAnalysis(x, RescaledRange, []int{2, 8}) }) {
+		t.Errorf("HurstAnalysis did not panic with a single block")
+	}
+}
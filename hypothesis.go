@@ -0,0 +1,620 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/floats"
+)
+
+// Alternative specifies the alternative hypothesis of a test.
+type Alternative int
+
+const (
+	// TwoSided is the alternative that the statistic differs from its value
+	// under the null hypothesis in either direction.
+	TwoSided Alternative = iota
+	// Less is the alternative that the first sample tends to be smaller
+	// than the second.
+	Less
+	// Greater is the alternative that the first sample tends to be larger
+	// than the second.
+	Greater
+)
+
+// KolmogorovSmirnovTest performs the two-sample Kolmogorov–Smirnov test of
+// whether x and y are drawn from the same continuous distribution. It returns
+// the statistic d computed by KolmogorovSmirnov and its two-sided p-value.
+// The requirements on x, y and their weights are as for KolmogorovSmirnov.
+//
+// If both weights are nil, there are no ties within or between x and y and
+// len(x)·len(y) < 10000, the p-value is exact. Otherwise it is the asymptotic
+// p-value
+//  Q(sqrt(n m / (n + m)) d)
+// of the Kolmogorov distribution Q, with n and m the effective sizes of the
+// samples. For a weighted sample this is Kish's effective sample size
+//  (sum_i w_i)² / sum_i w_i²
+// which equals the number of observations when all of the weights are equal,
+// and otherwise it is the number of observations. The asymptotic p-value is
+// conservative in the presence of ties. Without weights the p-values are
+// those of R's ks.test(x, y) up to R 4.2; later versions of R also compute
+// exact p-values with ties.
+//
+// If x or y is empty, or if either contains a NaN value, p is NaN.
+func KolmogorovSmirnovTest(x, xWeights, y, yWeights []float64) (d, p float64) {
+	d = KolmogorovSmirnov(x, xWeights, y, yWeights)
+	if len(x) == 0 || len(y) == 0 || math.IsNaN(d) {
+		return d, math.NaN()
+	}
+	if xWeights == nil && yWeights == nil && len(x)*len(y) < 10000 && !hasTiesSorted(x, y) {
+		return d, 1 - smirnovExact(d, len(x), len(y))
+	}
+	n := effectiveSize(x, xWeights)
+	m := effectiveSize(y, yWeights)
+	return d, kolmogorovSurvival(math.Sqrt(n*m/(n+m)) * d)
+}
+
+// effectiveSize returns Kish's effective sample size of x with the given
+// weights, or len(x) if weights is nil.
+func effectiveSize(x, weights []float64) float64 {
+	if weights == nil {
+		return float64(len(x))
+	}
+	sum := floats.Sum(weights)
+	return sum * sum / floats.Dot(weights, weights)
+}
+
+// hasTiesSorted returns whether the sorted x and y have a value in common
+// or a repeated value.
+func hasTiesSorted(x, y []float64) bool {
+	for _, s := range [][]float64{x, y} {
+		for i := 1; i < len(s); i++ {
+			if s[i] == s[i-1] {
+				return true
+			}
+		}
+	}
+	var i, j int
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] < y[j]:
+			i++
+		case x[i] > y[j]:
+			j++
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// TTestOneSample performs the one-sample t-test of whether the mean of x is
+// mu0. The returned P is the two-sided p-value, Estimate is the mean of x and
+// CI its confidence interval at the confidence level level. TTestOneSample
+// panics if x has fewer than two values or if level is not between 0 and 1.
+func TTestOneSample(x []float64, mu0, level float64) TTestResult {
+	if len(x) < 2 {
+		panic("stat: too few samples")
+	}
+	mean, variance := MeanVariance(x, nil)
+	n := float64(len(x))
+	return tTest(mean, mu0, math.Sqrt(variance/n), n-1, level)
+}
+
+// TTestWelch performs Welch's two-sample t-test of whether x and y have equal
+// means, without assuming that their variances are equal. The degrees of
+// freedom are given by the Welch–Satterthwaite equation
+//  (s_x²/n_x + s_y²/n_y)² / ((s_x²/n_x)²/(n_x-1) + (s_y²/n_y)²/(n_y-1))
+// The returned P is the two-sided p-value, Estimate is the difference of the
+// means of x and y and CI its confidence interval at the confidence level
+// level. TTestWelch panics if x or y has fewer than two values or if level is
+// not between 0 and 1.
+func TTestWelch(x, y []float64, level float64) TTestResult {
+	if len(x) < 2 || len(y) < 2 {
+		panic("stat: too few samples")
+	}
+	mx, vx := MeanVariance(x, nil)
+	my, vy := MeanVariance(y, nil)
+	nx, ny := float64(len(x)), float64(len(y))
+	a, b := vx/nx, vy/ny
+	df := (a + b) * (a + b) / (a*a/(nx-1) + b*b/(ny-1))
+	return tTest(mx-my, 0, math.Sqrt(a+b), df, level)
+}
+
+// TTestPaired performs the paired t-test of whether the mean of the
+// differences x_i - y_i is zero, the one-sample t-test of the differences.
+// Estimate is the mean difference. TTestPaired panics if x and y have
+// different lengths, if there are fewer than two pairs or if level is not
+// between 0 and 1.
+func TTestPaired(x, y []float64, level float64) TTestResult {
+	checkLengths(x, y)
+	d := make([]float64, len(x))
+	floats.SubTo(d, x, y)
+	return TTestOneSample(d, 0, level)
+}
+
+// tTest returns the result of the t-test of whether the estimate with the
+// given standard error and degrees of freedom equals null.
+func tTest(estimate, null, stdErr, df, level float64) TTestResult {
+	if !(level > 0 && level < 1) {
+		panic("stat: confidence level out of range")
+	}
+	t := (estimate - null) / stdErr
+	half := tQuantile((1+level)/2, df) * stdErr
+	return TTestResult{
+		T:        t,
+		DF:       df,
+		P:        tTwoSided(t, df),
+		Estimate: estimate,
+		StdErr:   stdErr,
+		CI:       [2]float64{estimate - half, estimate + half},
+		Level:    level,
+	}
+}
+
+// MannWhitneyU performs the Mann–Whitney U test, also known as the Wilcoxon
+// rank-sum test, of whether x and y are drawn from the same distribution
+// against the alternative alt that the values of x tend to be smaller or
+// larger than those of y. It returns the statistic
+//  U = R_x - n_x (n_x + 1) / 2
+// where R_x is the sum of the ranks of x in the combined sample, with tied
+// values given their average rank, and its p-value. U counts the pairs with
+// x_i > y_j, with the tied pairs counted as one half.
+//
+// If there are no ties and len(x)·len(y) <= 10000, the p-value is exact,
+// computed from the distribution of U by recurrence on the sample sizes.
+// Otherwise it uses the normal approximation with the variance corrected for
+// ties,
+//  σ² = n_x n_y / 12 ((N + 1) - sum_k (t_k³ - t_k) / (N (N - 1)))
+// where N = n_x + n_y and t_k are the sizes of the groups of tied values, and
+// with a continuity correction of one half. The p-value is NaN if all of the
+// values are equal.
+//
+// MannWhitneyU panics if x or y is empty. It returns NaN if x or y contains a
+// NaN value.
+func MannWhitneyU(x, y []float64, alt Alternative) (u, p float64) {
+	if len(x) == 0 || len(y) == 0 {
+		panic("stat: zero length slice")
+	}
+	if floats.HasNaN(x) || floats.HasNaN(y) {
+		return math.NaN(), math.NaN()
+	}
+	nx, ny := len(x), len(y)
+	all := make([]float64, 0, nx+ny)
+	all = append(all, x...)
+	all = append(all, y...)
+	ranks := averageRanks(all)
+	for _, r := range ranks[:nx] {
+		u += r
+	}
+	u -= float64(nx*(nx+1)) / 2
+
+	ties := tieSum(all)
+
+	if ties == 0 && nx*ny <= 10000 {
+		dist := mannWhitneyDist(nx, ny)
+		k := int(u)
+		var lower, upper float64
+		for i, v := range dist {
+			if i <= k {
+				lower += v
+			}
+			if i >= k {
+				upper += v
+			}
+		}
+		switch alt {
+		case Less:
+			p = lower
+		case Greater:
+			p = upper
+		default:
+			p = math.Min(1, 2*math.Min(lower, upper))
+		}
+		return u, p
+	}
+
+	n, m := float64(nx), float64(ny)
+	total := n + m
+	sigma := math.Sqrt(n * m / 12 * ((total + 1) - ties/(total*(total-1))))
+	if sigma == 0 {
+		return u, math.NaN()
+	}
+	diff := u - n*m/2
+	switch alt {
+	case Less:
+		p = 0.5 * math.Erfc(-(diff+0.5)/sigma/math.Sqrt2)
+	case Greater:
+		p = 0.5 * math.Erfc((diff-0.5)/sigma/math.Sqrt2)
+	default:
+		// The continuity correction is towards zero, as in R's
+		// wilcox.test, so that U = n m / 2 has p-value 1.
+		var corr float64
+		switch {
+		case diff > 0:
+			corr = 0.5
+		case diff < 0:
+			corr = -0.5
+		}
+		z := (diff - corr) / sigma
+		p = math.Erfc(math.Abs(z) / math.Sqrt2)
+	}
+	return u, p
+}
+
+// tieSum returns the sum of t³ - t over the groups of tied values of x, where
+// t is the size of the group, which is zero if there are no ties.
+func tieSum(x []float64) float64 {
+	sorted := NewSorted(x)
+	var sum float64
+	for i := 0; i < len(sorted); {
+		j := i + 1
+		for j < len(sorted) && sorted[j] == sorted[i] {
+			j++
+		}
+		t := float64(j - i)
+		sum += t*t*t - t
+		i = j
+	}
+	return sum
+}
+
+// mannWhitneyDist returns the probabilities of the values 0, 1, ..., n·m of
+// the Mann–Whitney U statistic of samples of sizes n and m without ties. The
+// number of assignments with U = u is the number of partitions of u into at
+// most k = min(n, m) parts no larger than M = max(n, m), the coefficient of
+// q^u in the Gaussian binomial coefficient
+//  [M+k, k]_q = Π_{i=1}^k (1 - q^{M+i}) / (1 - q^i)
+// and the counts are divided by the binomial coefficient C(n+m, n). The
+// product is expanded in place in O(n·m) memory, dividing by 1 - q^i before
+// multiplying by 1 - q^{M+i} so that the running coefficients stay positive.
+// The upper half of the distribution is taken from the lower half by
+// symmetry, since the subtractions lose accuracy in the upper tail.
+func mannWhitneyDist(n, m int) []float64 {
+	k, max := n, m
+	if k > max {
+		k, max = max, k
+	}
+	c := make([]float64, n*m+1)
+	c[0] = 1
+	var top int
+	for i := 1; i <= k; i++ {
+		top += max
+		for u := i; u <= top; u++ {
+			c[u] += c[u-i]
+		}
+		for u := top; u >= max+i; u-- {
+			c[u] -= c[u-max-i]
+		}
+	}
+	for u := top/2 + 1; u <= top; u++ {
+		c[u] = c[top-u]
+	}
+	binom := 1.0
+	for i := 1; i <= k; i++ {
+		binom = binom * float64(max+i) / float64(i)
+	}
+	for u := range c {
+		c[u] /= binom
+	}
+	return c
+}
+
+// KruskalWallis performs the Kruskal–Wallis test of whether the groups are
+// drawn from the same distribution, the extension of the Mann–Whitney U test
+// to more than two groups. It returns the statistic
+//  H = (12 / (N (N + 1)) sum_i R_i² / n_i - 3 (N + 1)) / (1 - sum_k (t_k³ - t_k) / (N³ - N))
+// where n_i is the size of group i, R_i the sum of the ranks of its values in
+// the combined sample of size N, with tied values given their average rank,
+// and t_k are the sizes of the groups of tied values. The p-value is that of
+// the chi-square distribution with one fewer degrees of freedom than the
+// number of groups. H and p are NaN if all of the values are equal.
+//
+// KruskalWallis panics if there are fewer than two groups or if any group is
+// empty. It returns NaN if a group contains a NaN value.
+func KruskalWallis(groups [][]float64) (h, p float64) {
+	if len(groups) < 2 {
+		panic("stat: fewer than two groups")
+	}
+	var all []float64
+	for _, g := range groups {
+		if len(g) == 0 {
+			panic("stat: zero length slice")
+		}
+		if floats.HasNaN(g) {
+			return math.NaN(), math.NaN()
+		}
+		all = append(all, g...)
+	}
+	ranks := averageRanks(all)
+	var start int
+	for _, g := range groups {
+		var r float64
+		for _, v := range ranks[start : start+len(g)] {
+			r += v
+		}
+		h += r * r / float64(len(g))
+		start += len(g)
+	}
+	n := float64(len(all))
+	h = 12/(n*(n+1))*h - 3*(n+1)
+
+	ties := tieSum(all)
+	correction := 1 - ties/(n*n*n-n)
+	if correction == 0 {
+		return math.NaN(), math.NaN()
+	}
+	h /= correction
+	return h, chiSquareSurvival(h, float64(len(groups)-1))
+}
+
+// OneWayANOVA performs the one-way analysis of variance of whether the groups
+// have equal means. The sums of squares between and within the groups are
+//  SSB = sum_i n_i (mean_i - mean)²
+//  SSW = sum_i sum_j (x_ij - mean_i)²
+// with k-1 and N-k degrees of freedom for k groups of N values in total, and
+// F = MSB/MSW is the ratio of the mean squares. The effect sizes are
+//  η² = SSB / (SSB + SSW)
+//  ω² = (SSB - (k-1) MSW) / (SSB + SSW + MSW)
+// A group of a single value contributes no within group variation. If every
+// group has a single value, the within group quantities are NaN.
+//
+// OneWayANOVA panics if there are fewer than two groups or if any group is
+// empty.
+func OneWayANOVA(groups [][]float64) ANOVAResult {
+	if len(groups) < 2 {
+		panic("stat: fewer than two groups")
+	}
+	means := make([]float64, len(groups))
+	var n, sum float64
+	for i, g := range groups {
+		if len(g) == 0 {
+			panic("stat: zero length slice")
+		}
+		means[i] = Mean(g, nil)
+		n += float64(len(g))
+		sum += floats.Sum(g)
+	}
+	mean := sum / n
+
+	var ssb, ssw float64
+	for i, g := range groups {
+		d := means[i] - mean
+		ssb += float64(len(g)) * d * d
+		for _, v := range g {
+			d := v - means[i]
+			ssw += d * d
+		}
+	}
+	dfb := float64(len(groups) - 1)
+	dfw := n - float64(len(groups))
+	msb := ssb / dfb
+	msw := ssw / dfw
+	f := msb / msw
+	return ANOVAResult{
+		F:            f,
+		DFBetween:    dfb,
+		DFWithin:     dfw,
+		P:            fSurvival(f, dfb, dfw),
+		SSBetween:    ssb,
+		SSWithin:     ssw,
+		MSBetween:    msb,
+		MSWithin:     msw,
+		EtaSquared:   ssb / (ssb + ssw),
+		OmegaSquared: (ssb - dfb*msw) / (ssb + ssw + msw),
+	}
+}
+
+// ShapiroWilk performs the Shapiro–Wilk test of whether x is drawn from a
+// normal distribution. It returns the statistic
+//  W = (sum_i a_i x_(i))² / sum_i (x_i - mean)²
+// where x_(i) are the sorted values and a_i the coefficients of the
+// expected normal order statistics, and its p-value. Small values of W
+// indicate departure from normality. The coefficients and the p-value are
+// computed by the approximations of Royston, "Remark AS R94: A remark on
+// algorithm AS 181: The W-test for normality", Applied Statistics, 1995, as
+// in R's shapiro.test, which are valid for 3 <= len(x) <= 5000.
+//
+// x is not modified. ShapiroWilk returns NaN if x contains a NaN value or if
+// all of its values are equal, and panics if len(x) is not in [3, 5000].
+func ShapiroWilk(x []float64) (w, p float64) {
+	n := len(x)
+	if n < 3 || n > 5000 {
+		panic("stat: sample size out of range")
+	}
+	if floats.HasNaN(x) {
+		return math.NaN(), math.NaN()
+	}
+	s := NewSorted(x)
+	rng := s[n-1] - s[0]
+	if rng == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	half := swCoefficients(n)
+	an := float64(n)
+
+	// W is the squared correlation of the scaled data and the coefficients,
+	// which sum to zero. 1-W is computed directly since it determines the
+	// p-value.
+	mean := floats.Sum(s) / (an * rng)
+	var ssa, ssx, sax float64
+	for i, v := range s {
+		var a float64
+		switch j := n - 1 - i; {
+		case i < j:
+			a = -half[i]
+		case i > j:
+			a = half[j]
+		}
+		xs := v/rng - mean
+		ssa += a * a
+		ssx += xs * xs
+		sax += a * xs
+	}
+	ssassx := math.Sqrt(ssa * ssx)
+	w1 := (ssassx - sax) * (ssassx + sax) / (ssa * ssx)
+	w = 1 - w1
+
+	if n == 3 {
+		const (
+			pi6  = 6 / math.Pi
+			stqr = math.Pi / 3
+		)
+		return w, math.Max(0, pi6*(math.Asin(math.Sqrt(w))-stqr))
+	}
+	y := math.Log(w1)
+	var mu, sigma float64
+	if n <= 11 {
+		gamma := swPoly(swG, an)
+		if y >= gamma {
+			return w, 1e-99
+		}
+		y = -math.Log(gamma - y)
+		mu = swPoly(swC3, an)
+		sigma = math.Exp(swPoly(swC4, an))
+	} else {
+		ln := math.Log(an)
+		mu = swPoly(swC5, ln)
+		sigma = math.Exp(swPoly(swC6, ln))
+	}
+	return w, 0.5 * math.Erfc((y-mu)/sigma/math.Sqrt2)
+}
+
+// swCoefficients returns the largest n/2 of the Shapiro–Wilk coefficients for
+// samples of size n, in decreasing order. The others follow from the
+// antisymmetry a_(n+1-i) = -a_i.
+func swCoefficients(n int) []float64 {
+	half := make([]float64, n/2)
+	an := float64(n)
+	if n == 3 {
+		half[0] = math.Sqrt(0.5)
+	} else {
+		m := make([]float64, n/2)
+		var summ2 float64
+		for i := range m {
+			m[i] = normalQuantile((float64(i+1) - 0.375) / (an + 0.25))
+			summ2 += m[i] * m[i]
+		}
+		summ2 *= 2
+		ssumm2 := math.Sqrt(summ2)
+		rsn := 1 / math.Sqrt(an)
+		a1 := swPoly(swC1, rsn) - m[0]/ssumm2
+		i1 := 1
+		var fac float64
+		if n > 5 {
+			i1 = 2
+			a2 := swPoly(swC2, rsn) - m[1]/ssumm2
+			fac = math.Sqrt((summ2 - 2*m[0]*m[0] - 2*m[1]*m[1]) / (1 - 2*a1*a1 - 2*a2*a2))
+			half[1] = a2
+		} else {
+			fac = math.Sqrt((summ2 - 2*m[0]*m[0]) / (1 - 2*a1*a1))
+		}
+		half[0] = a1
+		for i := i1; i < len(half); i++ {
+			half[i] = -m[i] / fac
+		}
+	}
+	return half
+}
+
+// The polynomial coefficients of algorithm AS R94.
+var (
+	swG  = []float64{-2.273, 0.459}
+	swC1 = []float64{0, 0.221157, -0.147981, -2.07119, 4.434685, -2.706056}
+	swC2 = []float64{0, 0.042981, -0.293762, -1.752461, 5.682633, -3.582633}
+	swC3 = []float64{0.544, -0.39978, 0.025054, -6.714e-4}
+	swC4 = []float64{1.3822, -0.77857, 0.062767, -0.0020322}
+	swC5 = []float64{-1.5861, -0.31082, -0.083751, 0.0038915}
+	swC6 = []float64{-0.4803, -0.082676, 0.0030302}
+)
+
+// swPoly evaluates the polynomial with the coefficients c in increasing order
+// of degree at x.
+func swPoly(c []float64, x float64) float64 {
+	var v float64
+	for i := len(c) - 1; i >= 0; i-- {
+		v = v*x + c[i]
+	}
+	return v
+}
+
+// CenterKind specifies the center of the groups used by Levene's test.
+type CenterKind int
+
+const (
+	// CenterMean uses the group means, giving Levene's original test.
+	CenterMean CenterKind = iota
+	// CenterMedian uses the group medians, giving the Brown–Forsythe
+	// test, which is robust to non-normal data.
+	CenterMedian
+)
+
+// Levene performs Levene's test of whether the groups have equal variances.
+// The statistic W is the F statistic of the one-way analysis of variance of
+// the absolute deviations |x_ij - c_i| of the values from the centers c_i of
+// their groups, with k-1 and N-k degrees of freedom for k groups of N values
+// in total, and p is its p-value from the F distribution.
+//
+// Levene panics if there are fewer than two groups, if any group is empty or
+// if center is not a known CenterKind.
+func Levene(groups [][]float64, center CenterKind) (w, df1, df2, p float64) {
+	dev := make([][]float64, len(groups))
+	for i, g := range groups {
+		if len(g) == 0 {
+			panic("stat: zero length slice")
+		}
+		var c float64
+		switch center {
+		case CenterMean:
+			c = Mean(g, nil)
+		case CenterMedian:
+			c = WeightedMedian(g, nil)
+		default:
+			panic("stat: unknown center")
+		}
+		dev[i] = make([]float64, len(g))
+		for j, v := range g {
+			dev[i][j] = math.Abs(v - c)
+		}
+	}
+	r := OneWayANOVA(dev)
+	return r.F, r.DFBetween, r.DFWithin, r.P
+}
+
+// Bartlett performs Bartlett's test of whether the groups, assumed to be
+// normally distributed, have equal variances. It returns the statistic
+//  K² = ((N - k) ln s_p² - sum_i (n_i - 1) ln s_i²) / (1 + (sum_i 1/(n_i - 1) - 1/(N - k)) / (3 (k - 1)))
+// where s_i² are the variances of the k groups, of sizes n_i and N values in
+// total, and s_p² is their pooled variance, and its p-value from the
+// chi-square distribution with k-1 degrees of freedom. Bartlett's test is
+// sensitive to departures from normality, for which Levene's test is more
+// robust.
+//
+// Bartlett panics if there are fewer than two groups or if any group has
+// fewer than two values.
+func Bartlett(groups [][]float64) (k2, df, p float64) {
+	if len(groups) < 2 {
+		panic("stat: fewer than two groups")
+	}
+	var n, pooled, logSum, invSum float64
+	for _, g := range groups {
+		if len(g) < 2 {
+			panic("stat: too few samples")
+		}
+		v := Variance(g, nil)
+		ni := float64(len(g) - 1)
+		n += ni
+		pooled += ni * v
+		logSum += ni * math.Log(v)
+		invSum += 1 / ni
+	}
+	k := float64(len(groups))
+	pooled /= n
+	k2 = (n*math.Log(pooled) - logSum) / (1 + (invSum-1/n)/(3*(k-1)))
+	df = k - 1
+	return k2, df, chiSquareSurvival(k2, df)
+}
@@ -0,0 +1,660 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestKolmogorovSmirnovTest(t *testing.T) {
+	x120 := make([]float64, 120)
+	for i := range x120 {
+		x120[i] = float64(i)
+	}
+	y100 := make([]float64, 100)
+	for j := range y100 {
+		y100[j] = 1.5*float64(j) + 10.25
+	}
+	// The p-values without weights are from psmirnov2x and pkstwo of R
+	// 4.2's ks.c as called by ks.test(x, y), exact for the first three cases
+	// and asymptotic for the fourth since n·m ≥ 10000, and by ks.test(x, y,
+	// exact = FALSE) for the case with ties. pkstwo sums the Kolmogorov
+	// series to an absolute tolerance of 1e-6, so the asymptotic p-values
+	// are compared less strictly. The p-value of the weighted case is
+	// Q(sqrt(4·16/3 / (4+16/3)) 3/8) from the Kolmogorov series.
+	for i, test := range []struct {
+		x, xw, y, yw []float64
+		d, p, tol    float64
+	}{
+		{
+			x:   []float64{-0.93, -0.26, -0.25, 0.09, 0.16, 0.99, 1.25, 1.9},
+			y:   []float64{-0.83, -0.71, -0.53, -0.17, 0.36, 0.43, 0.76, 0.77, 1.39, 1.53, 1.93},
+			d:   23.0 / 88,
+			p:   0.829602286258633,
+			tol: 1e-12,
+		},
+		{
+			x:   []float64{1, 2, 3, 4, 5},
+			y:   []float64{6, 7, 8, 9, 10, 11},
+			d:   1,
+			p:   0.004329004329004329,
+			tol: 1e-12,
+		},
+		{
+			x:   []float64{0.1, 0.4, 0.7},
+			y:   []float64{0.2, 0.3, 0.5, 0.6},
+			d:   1.0 / 3,
+			p:   0.9714285714285714,
+			tol: 1e-12,
+		},
+		{
+			// Too large for the exact computation.
+			x:   x120,
+			y:   y100,
+			d:   163.0 / 600,
+			p:   0.0006374307331709073,
+			tol: 1e-6,
+		},
+		{
+			// Ties.
+			x:   []float64{1, 2, 2, 3},
+			y:   []float64{2, 4, 5},
+			d:   2.0 / 3,
+			p:   0.4312569798791259,
+			tol: 1e-5,
+		},
+		{
+			// Effective sizes 4 and 16/3.
+			x:   []float64{0.1, 0.4, 0.7, 1.2, 1.5},
+			xw:  []float64{1, 2, 1, 3, 1},
+			y:   []float64{0.2, 0.3, 0.5, 0.6, 0.9, 2.0},
+			yw:  []float64{2, 2, 1, 1, 1, 1},
+			d:   0.375,
+			p:   0.9047976820901158,
+			tol: 1e-12,
+		},
+	} {
+		d, p := KolmogorovSmirnovTest(test.x, test.xw, test.y, test.yw)
+		if !floats.EqualWithinAbsOrRel(d, test.d, 1e-14, 1e-14) {
+			t.Errorf("statistic mismatch case %d: got %v, want %v", i, d, test.d)
+		}
+		if !floats.EqualWithinAbsOrRel(p, test.p, test.tol, test.tol) {
+			t.Errorf("p-value mismatch case %d: got %v, want %v", i, p, test.p)
+		}
+		// The test is symmetric in its samples.
+		if d2, p2 := KolmogorovSmirnovTest(test.y, test.yw, test.x, test.xw); d2 != d || math.Abs(p2-p) > 1e-14 {
+			t.Errorf("case %d not symmetric: got %v %v, want %v %v", i, d2, p2, d, p)
+		}
+	}
+
+	// Uniform weights give the unweighted asymptotic p-value.
+	w120 := make([]float64, len(x120))
+	for i := range w120 {
+		w120[i] = 0.3
+	}
+	_, p := KolmogorovSmirnovTest(x120, w120, y100, nil)
+	if !floats.EqualWithinRel(p, 0.0006374307331708785, 1e-10) {
+		t.Errorf("p-value mismatch with uniform weights: got %v", p)
+	}
+
+	for _, test := range []struct {
+		x, y []float64
+	}{
+		{nil, []float64{1, 2}},
+		{[]float64{1, 2}, nil},
+		{[]float64{1, math.NaN()}, []float64{1, 2}},
+	} {
+		if _, p := KolmogorovSmirnovTest(test.x, nil, test.y, nil); !math.IsNaN(p) {
+			t.Errorf("expected NaN p-value for %v and %v, got %v", test.x, test.y, p)
+		}
+	}
+	if !Panics(func() { KolmogorovSmirnovTest([]float64{2, 1}, nil, []float64{1, 2}, nil) }) {
+		t.Errorf("expected panic with unsorted input")
+	}
+}
+
+// The extra sleep of two groups of ten patients, from Student (1908), the
+// sleep data set of R.
+var (
+	sleep1 = []float64{0.7, -1.6, -0.2, -1.2, -0.1, 3.4, 3.7, 0.8, 0.0, 2.0}
+	sleep2 = []float64{1.9, 0.8, 1.1, 0.1, -0.1, 4.4, 5.5, 1.6, 4.6, 3.4}
+)
+
+func TestTTest(t *testing.T) {
+	// The reference values agree with those printed by R's t.test.
+	for _, test := range []struct {
+		name string
+		got  TTestResult
+		want TTestResult
+	}{
+		{
+			name: "one sample",
+			got:  TTestOneSample(sleep1, 0, 0.95),
+			want: TTestResult{
+				T: 1.3257101407138212, DF: 9, P: 0.21759778006845953,
+				Estimate: 0.75, StdErr: 0.5657345274557277,
+				CI: [2]float64{-0.529780413526237, 2.029780413526237}, Level: 0.95,
+			},
+		},
+		{
+			name: "one sample shifted",
+			got:  TTestOneSample(sleep1, -0.5, 0.95),
+			want: TTestResult{
+				T: 1.25 / 0.5657345274557277, DF: 9, P: 0.05448816744101348,
+				Estimate: 0.75, StdErr: 0.5657345274557277,
+				CI: [2]float64{-0.529780413526237, 2.029780413526237}, Level: 0.95,
+			},
+		},
+		{
+			name: "Welch",
+			got:  TTestWelch(sleep1, sleep2, 0.95),
+			want: TTestResult{
+				T: -1.8608134674868526, DF: 17.776473516178495, P: 0.07939414018735647,
+				Estimate: -1.58, StdErr: 0.849091017238762,
+				CI: [2]float64{-3.3654832307116838, 0.20548323071168428}, Level: 0.95,
+			},
+		},
+		{
+			name: "paired",
+			got:  TTestPaired(sleep1, sleep2, 0.8),
+			want: TTestResult{
+				T: -4.062127683382037, DF: 9, P: 0.00283289019738886,
+				Estimate: -1.58, StdErr: 0.38895872388839525,
+				CI: [2]float64{-2.1179410931877407, -1.0420589068122594}, Level: 0.8,
+			},
+		},
+	} {
+		got, want := test.got, test.want
+		for _, v := range []struct {
+			field     string
+			got, want float64
+		}{
+			{"T", got.T, want.T},
+			{"DF", got.DF, want.DF},
+			{"P", got.P, want.P},
+			{"Estimate", got.Estimate, want.Estimate},
+			{"StdErr", got.StdErr, want.StdErr},
+			{"CI[0]", got.CI[0], want.CI[0]},
+			{"CI[1]", got.CI[1], want.CI[1]},
+			{"Level", got.Level, want.Level},
+		} {
+			if !floats.EqualWithinAbsOrRel(v.got, v.want, 1e-9, 1e-9) {
+				t.Errorf("%s: %s mismatch: got %v, want %v", test.name, v.field, v.got, v.want)
+			}
+		}
+	}
+
+	// The Welch test is antisymmetric in its samples.
+	r, s := TTestWelch(sleep1, sleep2, 0.9), TTestWelch(sleep2, sleep1, 0.9)
+	if r.T != -s.T || r.DF != s.DF || r.P != s.P || r.CI[0] != -s.CI[1] {
+		t.Errorf("Welch test not antisymmetric: %+v and %+v", r, s)
+	}
+
+	for _, f := range []func(){
+		func() { TTestOneSample([]float64{1}, 0, 0.95) },
+		func() { TTestOneSample(sleep1, 0, 1) },
+		func() { TTestWelch(sleep1, []float64{1}, 0.95) },
+		func() { TTestWelch(sleep1, sleep2, 0) },
+		func() { TTestPaired(sleep1, sleep2[:9], 0.95) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	// The first case is the example of R's wilcox.test, where the one-sided
+	// p-value is 0.1272. The exact p-values were computed by enumerating the
+	// assignments of the values to the samples, and the approximate ones
+	// independently from the normal approximation.
+	x := []float64{0.80, 0.83, 1.89, 1.04, 1.45, 1.38, 1.91, 1.64, 0.73, 1.46}
+	y := []float64{1.15, 0.88, 0.90, 0.74, 1.21}
+	xt := []float64{1, 2, 2, 3, 5, 5, 5, 8}
+	yt := []float64{2, 3, 4, 4, 6, 7, 9, 9, 10}
+	for i, test := range []struct {
+		x, y []float64
+		alt  Alternative
+		u, p float64
+	}{
+		{x, y, Greater, 35, 0.1272061272061272},
+		{x, y, Less, 35, 0.8967698967698968},
+		{x, y, TwoSided, 35, 0.2544122544122544},
+		{y, x, Less, 15, 0.1272061272061272},
+		{[]float64{1, 2, 3}, []float64{4, 5, 6, 7}, Less, 0, 1.0 / 35},
+		{[]float64{1, 2, 3}, []float64{4, 5, 6, 7}, Greater, 0, 1},
+		{[]float64{1, 2, 3}, []float64{4, 5, 6, 7}, TwoSided, 0, 2.0 / 35},
+		{xt, yt, Less, 20.5, 0.07308359072021642},
+		{xt, yt, Greater, 20.5, 0.9394393266434675},
+		{xt, yt, TwoSided, 20.5, 0.14616718144043284},
+		{[]float64{3, 3}, []float64{3, 3, 3}, TwoSided, 3, math.NaN()},
+		// U equal to its mean n m / 2 under the normal approximation.
+		{[]float64{1, 2, 2, 3}, []float64{3, 2, 1, 2}, TwoSided, 8, 1},
+	} {
+		u, p := MannWhitneyU(test.x, test.y, test.alt)
+		if u != test.u {
+			t.Errorf("U mismatch case %d: got %v, want %v", i, u, test.u)
+		}
+		if !floats.EqualWithinAbsOrRel(p, test.p, 1e-14, 1e-14) && !(math.IsNaN(p) && math.IsNaN(test.p)) {
+			t.Errorf("p-value mismatch case %d: got %v, want %v", i, p, test.p)
+		}
+	}
+
+	// The exact distribution matches the counts of the partitions of u into
+	// at most n parts no larger than m, the coefficients of the Gaussian
+	// binomial coefficient.
+	const n, m = 4, 6
+	counts := make([]float64, n*m+1)
+	var count func(u, parts, max int)
+	count = func(u, parts, max int) {
+		counts[u]++
+		if parts == 0 {
+			return
+		}
+		for v := 1; v <= max; v++ {
+			count(u+v, parts-1, v)
+		}
+	}
+	count(0, n, m)
+	dist := mannWhitneyDist(n, m)
+	for u, c := range counts {
+		if want := c / 210; math.Abs(dist[u]-want) > 1e-15 {
+			t.Errorf("probability of U = %d mismatch: got %v, want %v", u, dist[u], want)
+		}
+	}
+
+	// The distribution does not depend on the order of the sizes, and takes
+	// memory proportional to n·m.
+	if !floats.Same(mannWhitneyDist(m, n), dist) {
+		t.Errorf("distribution not symmetric in the sample sizes")
+	}
+	for _, size := range [][2]int{{1, 10000}, {2, 5000}, {100, 100}} {
+		var d []float64
+		got := allocatedBytes(func() { d = mannWhitneyDist(size[0], size[1]) })
+		if max := 2 * 8 * (size[0]*size[1] + 1); got > uint64(max) {
+			t.Errorf("%d×%d distribution allocated %d bytes, want at most %d", size[0], size[1], got, max)
+		}
+		if sum := floats.Sum(d); math.Abs(sum-1) > 1e-12 {
+			t.Errorf("%d×%d distribution sums to %v", size[0], size[1], sum)
+		}
+	}
+	// P(U ≤ 3500) and P(U = 5000) for n = m = 100, from the counts of
+	// partitions in integer arithmetic.
+	d := mannWhitneyDist(100, 100)
+	if got := floats.Sum(d[:3501]); !floats.EqualWithinRel(got, 0.00011050617210445985, 1e-12) {
+		t.Errorf("lower tail mismatch for n = m = 100: got %v", got)
+	}
+	if !floats.EqualWithinRel(d[5000], 0.000972571734583764, 1e-12) {
+		t.Errorf("central probability mismatch for n = m = 100: got %v", d[5000])
+	}
+	// For n = 1 all values of U are equally likely.
+	for u, v := range mannWhitneyDist(1, 10000) {
+		if math.Abs(v-1.0/10001) > 1e-18 {
+			t.Errorf("probability of U = %d mismatch for n = 1: got %v, want %v", u, v, 1.0/10001)
+			break
+		}
+	}
+
+	if u, p := MannWhitneyU([]float64{1, math.NaN()}, []float64{1}, TwoSided); !math.IsNaN(u) || !math.IsNaN(p) {
+		t.Errorf("expected NaN for NaN input")
+	}
+	if !Panics(func() { MannWhitneyU(nil, []float64{1}, TwoSided) }) {
+		t.Errorf("expected panic with empty input")
+	}
+}
+
+func BenchmarkMannWhitneyUExact(b *testing.B) {
+	x := RandomSlice(100)
+	y := RandomSlice(100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MannWhitneyU(x, y, TwoSided)
+	}
+}
+
+func TestKruskalWallis(t *testing.T) {
+	for i, test := range []struct {
+		groups [][]float64
+		h, p   float64
+	}{
+		{
+			// The mucociliary efficiency example of Hollander and Wolfe
+			// (1973), used by R's kruskal.test, with H = 0.77143.
+			groups: [][]float64{
+				{2.9, 3.0, 2.5, 2.6, 3.2},
+				{3.8, 2.7, 4.0, 2.4},
+				{2.8, 3.4, 3.7, 2.2, 2.0},
+			},
+			h: 0.7714285714285722,
+			p: 0.6799647735788935,
+		},
+		{
+			// The examples of scipy.stats.kruskal.
+			groups: [][]float64{{1, 3, 5, 7, 9}, {2, 4, 6, 8, 10}},
+			h:      0.2727272727272734,
+			p:      0.6015081344405895,
+		},
+		{
+			groups: [][]float64{{1, 1, 1}, {2, 2, 2}, {2, 2}},
+			h:      7,
+			p:      0.0301973834223185,
+		},
+		{
+			groups: [][]float64{
+				{1, 2, 2, 5},
+				{3, 3, 6, 7, 7},
+				{4, 8, 9},
+				{5, 10, 11, 12, 12, 13},
+			},
+			h: 11.344061203319496,
+			p: 0.010003723599945035,
+		},
+		{
+			groups: [][]float64{{4, 4}, {4}},
+			h:      math.NaN(),
+			p:      math.NaN(),
+		},
+		{
+			groups: [][]float64{{1, 2}, {3, math.NaN()}},
+			h:      math.NaN(),
+			p:      math.NaN(),
+		},
+	} {
+		h, p := KruskalWallis(test.groups)
+		if !floats.EqualWithinAbsOrRel(h, test.h, 1e-12, 1e-12) && !(math.IsNaN(h) && math.IsNaN(test.h)) {
+			t.Errorf("H mismatch case %d: got %v, want %v", i, h, test.h)
+		}
+		if !floats.EqualWithinAbsOrRel(p, test.p, 1e-12, 1e-12) && !(math.IsNaN(p) && math.IsNaN(test.p)) {
+			t.Errorf("p-value mismatch case %d: got %v, want %v", i, p, test.p)
+		}
+	}
+
+	// With two groups and no ties, H is the square of the normal deviate of
+	// the Mann–Whitney U statistic without continuity correction.
+	x, y := []float64{0.5, 2.5, 3, 7, 11}, []float64{1, 4, 5, 6, 8, 9, 10}
+	u, _ := MannWhitneyU(x, y, TwoSided)
+	n, m := float64(len(x)), float64(len(y))
+	z := (u - n*m/2) / math.Sqrt(n*m*(n+m+1)/12)
+	if h, _ := KruskalWallis([][]float64{x, y}); math.Abs(h-z*z) > 1e-12 {
+		t.Errorf("H of two groups mismatch: got %v, want %v", h, z*z)
+	}
+
+	for _, groups := range [][][]float64{
+		nil,
+		{{1, 2}},
+		{{1, 2}, {}},
+	} {
+		if !Panics(func() { KruskalWallis(groups) }) {
+			t.Errorf("expected panic for %v", groups)
+		}
+	}
+}
+
+func TestOneWayANOVA(t *testing.T) {
+	for i, test := range []struct {
+		groups [][]float64
+		want   ANOVAResult
+	}{
+		{
+			// The PlantGrowth data set of R, for which summary(aov) gives
+			// F = 4.846 with p = 0.0159.
+			groups: [][]float64{
+				{4.17, 5.58, 5.18, 6.11, 4.50, 4.61, 5.17, 4.53, 5.33, 5.14},
+				{4.81, 4.17, 4.41, 3.59, 5.87, 3.83, 6.03, 4.89, 4.32, 4.69},
+				{6.31, 5.12, 5.54, 5.50, 5.37, 5.29, 4.92, 6.15, 5.80, 5.26},
+			},
+			want: ANOVAResult{
+				F: 4.846087862380136, DFBetween: 2, DFWithin: 27, P: 0.015909958325622926,
+				SSBetween: 3.76634, SSWithin: 10.49209,
+				MSBetween: 1.88317, MSWithin: 0.3885959259259259,
+				EtaSquared: 0.26414829683211966, OmegaSquared: 0.20407884598997092,
+			},
+		},
+		{
+			// A group of a single value.
+			groups: [][]float64{{1, 2, 3}, {4}, {2, 6, 7, 9}},
+			want: ANOVAResult{
+				F: 2.455357142857143, DFBetween: 2, DFWithin: 5, P: 0.18078509448271687,
+				SSBetween: 27.5, SSWithin: 28,
+				MSBetween: 13.75, MSWithin: 5.6,
+				EtaSquared: 0.4954954954954955, OmegaSquared: 0.26677577741407527,
+			},
+		},
+	} {
+		got := OneWayANOVA(test.groups)
+		for _, v := range []struct {
+			field     string
+			got, want float64
+		}{
+			{"F", got.F, test.want.F},
+			{"DFBetween", got.DFBetween, test.want.DFBetween},
+			{"DFWithin", got.DFWithin, test.want.DFWithin},
+			{"P", got.P, test.want.P},
+			{"SSBetween", got.SSBetween, test.want.SSBetween},
+			{"SSWithin", got.SSWithin, test.want.SSWithin},
+			{"MSBetween", got.MSBetween, test.want.MSBetween},
+			{"MSWithin", got.MSWithin, test.want.MSWithin},
+			{"EtaSquared", got.EtaSquared, test.want.EtaSquared},
+			{"OmegaSquared", got.OmegaSquared, test.want.OmegaSquared},
+		} {
+			if !floats.EqualWithinAbsOrRel(v.got, v.want, 1e-12, 1e-12) {
+				t.Errorf("case %d: %s mismatch: got %v, want %v", i, v.field, v.got, v.want)
+			}
+		}
+	}
+
+	// With two groups, F is the square of the pooled two-sample t statistic.
+	x, y := sleep1, sleep2
+	n, m := float64(len(x)), float64(len(y))
+	mx, vx := MeanVariance(x, nil)
+	my, vy := MeanVariance(y, nil)
+	pooled := ((n-1)*vx + (m-1)*vy) / (n + m - 2)
+	tStat := (mx - my) / math.Sqrt(pooled*(1/n+1/m))
+	r := OneWayANOVA([][]float64{x, y})
+	if math.Abs(r.F-tStat*tStat) > 1e-12 {
+		t.Errorf("F of two groups mismatch: got %v, want %v", r.F, tStat*tStat)
+	}
+	if want := tTwoSided(tStat, n+m-2); math.Abs(r.P-want) > 1e-12 {
+		t.Errorf("p-value of two groups mismatch: got %v, want %v", r.P, want)
+	}
+
+	if r := OneWayANOVA([][]float64{{1}, {2}}); !math.IsNaN(r.F) || !math.IsNaN(r.MSWithin) {
+		t.Errorf("expected NaN within group quantities for single value groups, got %+v", r)
+	}
+	for _, groups := range [][][]float64{
+		nil,
+		{{1, 2}},
+		{{1, 2}, {}},
+	} {
+		if !Panics(func() { OneWayANOVA(groups) }) {
+			t.Errorf("expected panic for %v", groups)
+		}
+	}
+}
+
+func TestShapiroWilk(t *testing.T) {
+	squares := make([]float64, 100)
+	for i := range squares {
+		squares[i] = float64((i + 1) * (i + 1))
+	}
+	// The reference values follow algorithm AS R94 of Royston (1995), which
+	// R's shapiro.test implements in swilk.c. For the weights of eleven men
+	// from Shapiro and Wilk (1965), shapiro.test gives W = 0.78881 with
+	// p = 0.006704.
+	for i, test := range []struct {
+		x    []float64
+		w, p float64
+	}{
+		{
+			// Normal.
+			x: []float64{9.49, 11.02, 9.55, 9.37, 8.14, 9.57, 12.22, 10.85, 12.07, 10.5,
+				10.79, 10.37, 6.67, 11.71, 11.01, 11.0, 6.62, 6.51, 8.22, 9.06},
+			w: 0.923502524031563,
+			p: 0.11573947097650689,
+		},
+		{
+			// Exponential.
+			x: []float64{3.74, 0.05, 1.96, 0.34, 0.16, 0.13, 0.37, 1.69, 0.2, 0.87,
+				1.02, 0.47, 0.79, 0.06, 0.06, 0.23, 1.14, 0.56, 0.38, 0.88,
+				0.6, 0.36, 1.58, 1.2, 0.28, 0.85, 0.74, 2.08, 1.31, 0.34},
+			w: 0.8128692093327675,
+			p: 0.00011353557208798976,
+		},
+		{
+			x: []float64{148, 154, 158, 160, 161, 162, 166, 170, 182, 195, 236},
+			w: 0.7888146948353865,
+			p: 0.006703814056502777,
+		},
+		{
+			x: []float64{1, 2, 4},
+			w: 0.9642857142857144,
+			p: 0.6368868450289701,
+		},
+		{
+			x: []float64{2.1, 3.3, 0.7, 5.2, 4.4, 3.9, 2.8},
+			w: 0.9858077185306093,
+			p: 0.9827971220061662,
+		},
+		{
+			x: squares,
+			w: 0.8961800544436139,
+			p: 9.390245820633043e-07,
+		},
+	} {
+		xCopy := append([]float64(nil), test.x...)
+		w, p := ShapiroWilk(test.x)
+		if !floats.EqualWithinAbsOrRel(w, test.w, 1e-12, 1e-12) {
+			t.Errorf("W mismatch case %d: got %v, want %v", i, w, test.w)
+		}
+		if !floats.EqualWithinAbsOrRel(p, test.p, 1e-10, 1e-10) {
+			t.Errorf("p-value mismatch case %d: got %v, want %v", i, p, test.p)
+		}
+		if !floats.Same(xCopy, test.x) {
+			t.Errorf("case %d: x modified", i)
+		}
+	}
+
+	// The approximate coefficients agree with the exact ones tabulated by
+	// Shapiro and Wilk (1965).
+	for _, test := range []struct {
+		n    int
+		want []float64
+	}{
+		{10, []float64{0.5739, 0.3291, 0.2141, 0.1224, 0.0399}},
+		{20, []float64{0.4734, 0.3211, 0.2565, 0.2085, 0.1686, 0.1334, 0.1013, 0.0711, 0.0422, 0.0140}},
+	} {
+		got := swCoefficients(test.n)
+		if !floats.EqualApprox(got, test.want, 1e-3) {
+			t.Errorf("coefficients mismatch for n = %d: got %v, want %v", test.n, got, test.want)
+		}
+	}
+
+	// The p-values of normal samples are uniformly distributed.
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{8, 30, 500} {
+		const trials = 2000
+		var rejected int
+		x := make([]float64, n)
+		for k := 0; k < trials; k++ {
+			for i := range x {
+				x[i] = rnd.NormFloat64()
+			}
+			if _, p := ShapiroWilk(x); p < 0.1 {
+				rejected++
+			}
+		}
+		if frac := float64(rejected) / trials; math.Abs(frac-0.1) > 0.025 {
+			t.Errorf("n = %d: fraction of normal samples rejected at 10%%: got %v", n, frac)
+		}
+	}
+
+	if w, p := ShapiroWilk([]float64{2, 2, 2, 2}); !math.IsNaN(w) || !math.IsNaN(p) {
+		t.Errorf("expected NaN for constant input")
+	}
+	if w, p := ShapiroWilk([]float64{1, 2, math.NaN()}); !math.IsNaN(w) || !math.IsNaN(p) {
+		t.Errorf("expected NaN for NaN input")
+	}
+	for _, n := range []int{2, 5001} {
+		if !Panics(func() { ShapiroWilk(make([]float64, n)) }) {
+			t.Errorf("expected panic for %d values", n)
+		}
+	}
+}
+
+// The InsectSprays data set of R, the counts of insects on agricultural
+// units treated with six insecticides.
+var insectSprays = [][]float64{
+	{10, 7, 20, 14, 14, 12, 10, 23, 17, 20, 14, 13},
+	{11, 17, 21, 11, 16, 14, 17, 17, 19, 21, 7, 13},
+	{0, 1, 7, 2, 3, 1, 2, 1, 3, 0, 1, 4},
+	{3, 5, 12, 6, 4, 3, 5, 5, 5, 5, 2, 4},
+	{3, 5, 3, 5, 3, 6, 1, 1, 3, 2, 6, 4},
+	{11, 9, 15, 22, 15, 16, 13, 10, 26, 26, 24, 13},
+}
+
+func TestLevene(t *testing.T) {
+	// For the median, car::leveneTest in R gives F = 3.8214 with
+	// p = 0.004223.
+	for _, test := range []struct {
+		center CenterKind
+		w, p   float64
+	}{
+		{CenterMean, 6.4553527100866965, 6.10363383448207e-05},
+		{CenterMedian, 3.8213563132259276, 0.0042227911389921095},
+	} {
+		w, df1, df2, p := Levene(insectSprays, test.center)
+		if !floats.EqualWithinAbsOrRel(w, test.w, 1e-12, 1e-12) {
+			t.Errorf("center %d: W mismatch: got %v, want %v", test.center, w, test.w)
+		}
+		if df1 != 5 || df2 != 66 {
+			t.Errorf("center %d: degrees of freedom mismatch: got %v and %v, want 5 and 66", test.center, df1, df2)
+		}
+		if !floats.EqualWithinAbsOrRel(p, test.p, 1e-9, 1e-9) {
+			t.Errorf("center %d: p-value mismatch: got %v, want %v", test.center, p, test.p)
+		}
+	}
+
+	for _, f := range []func(){
+		func() { Levene([][]float64{{1, 2}}, CenterMean) },
+		func() { Levene([][]float64{{1, 2}, {}}, CenterMedian) },
+		func() { Levene(insectSprays, CenterKind(-1)) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestBartlett(t *testing.T) {
+	// R's bartlett.test gives K² = 25.96 with p = 9.085e-05.
+	k2, df, p := Bartlett(insectSprays)
+	if !floats.EqualWithinAbsOrRel(k2, 25.959825320368683, 1e-12, 1e-12) {
+		t.Errorf("K² mismatch: got %v, want 25.959825320368683", k2)
+	}
+	if df != 5 {
+		t.Errorf("degrees of freedom mismatch: got %v, want 5", df)
+	}
+	if !floats.EqualWithinAbsOrRel(p, 9.08512233294533e-05, 1e-12, 1e-12) {
+		t.Errorf("p-value mismatch: got %v, want 9.08512233294533e-05", p)
+	}
+
+	// Equal variances give a zero statistic.
+	if k2, _, p := Bartlett([][]float64{{1, 2, 3}, {11, 12, 13}, {-1, 0, 1}}); math.Abs(k2) > 1e-14 || math.Abs(p-1) > 1e-14 {
+		t.Errorf("equal variances: got K² %v with p %v, want 0 and 1", k2, p)
+	}
+
+	for _, groups := range [][][]float64{
+		{{1, 2}},
+		{{1, 2}, {3}},
+	} {
+		if !Panics(func() { Bartlett(groups) }) {
+			t.Errorf("expected panic for %v", groups)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"errors"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ICCModel specifies the analysis of variance model underlying an intraclass
+// correlation coefficient, following Shrout and Fleiss (1979).
+type ICCModel int
+
+const (
+	// OneWayRandom is the one-way random effects model, ICC(1), in which
+	// each subject may be rated by a different set of raters.
+	OneWayRandom ICCModel = iota
+	// TwoWayRandom is the two-way random effects model, ICC(2), in which
+	// the raters are a random sample of raters and absolute agreement is
+	// measured.
+	TwoWayRandom
+	// TwoWayMixed is the two-way mixed effects model, ICC(3), in which the
+	// raters are the only raters of interest and consistency is measured.
+	TwoWayMixed
+)
+
+// ICCUnit specifies whether an intraclass correlation coefficient describes
+// the reliability of a single rating or of the average of the ratings.
+type ICCUnit int
+
+const (
+	// SingleRating gives the reliability of a single rater, ICC(m, 1).
+	SingleRating ICCUnit = iota
+	// AverageRating gives the reliability of the mean of the k raters,
+	// ICC(m, k).
+	AverageRating
+)
+
+// ICC returns the intraclass correlation coefficient of the ratings, in
+// which rows are subjects and columns are raters, for the given model and
+// unit, together with the F statistic for the hypothesis that the
+// coefficient is zero, its p-value, and a 95% confidence interval.
+//
+// With n subjects and k raters, the coefficients are computed from the mean
+// squares of the two-way analysis of variance, MSR for subjects, MSC for
+// raters, MSE for the residual and MSW within subjects, as
+//  ICC(1, 1) = (MSR - MSW) / (MSR + (k-1) MSW)
+//  ICC(2, 1) = (MSR - MSE) / (MSR + (k-1) MSE + k (MSC - MSE)/n)
+//  ICC(3, 1) = (MSR - MSE) / (MSR + (k-1) MSE)
+//  ICC(1, k) = (MSR - MSW) / MSR
+//  ICC(2, k) = (MSR - MSE) / (MSR + (MSC - MSE)/n)
+//  ICC(3, k) = (MSR - MSE) / MSR
+// The F statistic is MSR/MSW for the one-way model and MSR/MSE otherwise.
+// The confidence intervals are those of McGraw and Wong (1996), with the
+// Satterthwaite approximation for the two-way random model, as reported by
+// psych::ICC in R.
+//
+// ICC returns an error if any rating is NaN, denoting a missing rating.
+// To analyse only the complete subjects, use ListwiseDelete. ICC panics if
+// there are fewer than two subjects or two raters.
+func ICC(ratings *mat64.Dense, model ICCModel, unit ICCUnit) (icc, f, p float64, ci [2]float64, err error) {
+	n, k := ratings.Dims()
+	if n < 2 || k < 2 {
+		panic("stat: too few subjects or raters")
+	}
+	if unit != SingleRating && unit != AverageRating {
+		panic("stat: unknown ICC unit")
+	}
+	for i := 0; i < n; i++ {
+		for _, v := range ratings.RawRowView(i)[:k] {
+			if math.IsNaN(v) {
+				return 0, 0, 0, ci, errors.New("stat: missing rating")
+			}
+		}
+	}
+
+	grand := 0.0
+	rowMeans := make([]float64, n)
+	colMeans := make([]float64, k)
+	for i := range rowMeans {
+		for j, v := range ratings.RawRowView(i)[:k] {
+			rowMeans[i] += v
+			colMeans[j] += v
+			grand += v
+		}
+		rowMeans[i] /= float64(k)
+	}
+	for j := range colMeans {
+		colMeans[j] /= float64(n)
+	}
+	grand /= float64(n * k)
+	var sst, ssr, ssc float64
+	for i, m := range rowMeans {
+		ssr += (m - grand) * (m - grand)
+		for _, v := range ratings.RawRowView(i)[:k] {
+			sst += (v - grand) * (v - grand)
+		}
+	}
+	for _, m := range colMeans {
+		ssc += (m - grand) * (m - grand)
+	}
+	fn, fk := float64(n), float64(k)
+	ssr *= fk
+	ssc *= fn
+	msr := ssr / (fn - 1)
+	msc := ssc / (fk - 1)
+	mse := (sst - ssr - ssc) / ((fn - 1) * (fk - 1))
+	msw := (sst - ssr) / (fn * (fk - 1))
+
+	const alpha = 0.05
+	df1 := fn - 1
+	switch model {
+	case OneWayRandom, TwoWayMixed:
+		msd, df2 := msw, fn*(fk-1)
+		if model == TwoWayMixed {
+			msd, df2 = mse, (fn-1)*(fk-1)
+		}
+		f = msr / msd
+		p = fSurvival(f, df1, df2)
+		fl := f / fQuantile(1-alpha/2, df1, df2)
+		fu := f * fQuantile(1-alpha/2, df2, df1)
+		if unit == SingleRating {
+			icc = (msr - msd) / (msr + (fk-1)*msd)
+			ci = [2]float64{(fl - 1) / (fl + fk - 1), (fu - 1) / (fu + fk - 1)}
+		} else {
+			icc = (msr - msd) / msr
+			ci = [2]float64{1 - 1/fl, 1 - 1/fu}
+		}
+	case TwoWayRandom:
+		f = msr / mse
+		p = fSurvival(f, df1, (fn-1)*(fk-1))
+		icc = (msr - mse) / (msr + (fk-1)*mse + fk*(msc-mse)/fn)
+		// Satterthwaite degrees of freedom for the denominator.
+		fj := msc / mse
+		a := fn*(1+(fk-1)*icc) - fk*icc
+		v := (fk - 1) * (fn - 1) * math.Pow(fk*icc*fj+a, 2) /
+			((fn-1)*fk*fk*icc*icc*fj*fj + a*a)
+		fl := fQuantile(1-alpha/2, fn-1, v)
+		fu := fQuantile(1-alpha/2, v, fn-1)
+		d := fk*msc + (fk*fn-fk-fn)*mse
+		ci = [2]float64{
+			fn * (msr - fl*mse) / (fl*d + fn*msr),
+			fn * (fu*msr - mse) / (d + fn*fu*msr),
+		}
+		if unit == AverageRating {
+			icc = (msr - mse) / (msr + (msc-mse)/fn)
+			for i, c := range ci {
+				ci[i] = c * fk / (1 + c*(fk-1))
+			}
+		}
+	default:
+		panic("stat: unknown ICC model")
+	}
+	return icc, f, p, ci, nil
+}
+
+// ListwiseDelete returns a new matrix holding the rows of m that contain no
+// NaN values.
+func ListwiseDelete(m *mat64.Dense) *mat64.Dense {
+	r, c := m.Dims()
+	var data []float64
+	var rows int
+outer:
+	for i := 0; i < r; i++ {
+		row := m.RawRowView(i)[:c]
+		for _, v := range row {
+			if math.IsNaN(v) {
+				continue outer
+			}
+		}
+		data = append(data, row...)
+		rows++
+	}
+	if rows == 0 {
+		panic("stat: no complete rows")
+	}
+	return mat64.NewDense(rows, c, data)
+}
@@ -0,0 +1,91 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestICC(t *testing.T) {
+	// The example of Shrout and Fleiss (1979), six subjects rated by four
+	// judges, with the values printed by psych::ICC in R.
+	ratings := mat64.NewDense(6, 4, []float64{
+		9, 2, 5, 8,
+		6, 1, 3, 2,
+		8, 4, 6, 8,
+		7, 1, 2, 6,
+		10, 5, 6, 9,
+		6, 2, 4, 7,
+	})
+	for _, test := range []struct {
+		model  ICCModel
+		unit   ICCUnit
+		icc    float64
+		f, p   float64
+		lo, hi float64
+	}{
+		{OneWayRandom, SingleRating, 0.17, 1.8, 0.16477, -0.13, 0.72},
+		{TwoWayRandom, SingleRating, 0.29, 11, 0.00013, 0.02, 0.76},
+		{TwoWayMixed, SingleRating, 0.71, 11, 0.00013, 0.34, 0.95},
+		{OneWayRandom, AverageRating, 0.44, 1.8, 0.16477, -0.88, 0.91},
+		{TwoWayRandom, AverageRating, 0.62, 11, 0.00013, 0.07, 0.93},
+		{TwoWayMixed, AverageRating, 0.91, 11, 0.00013, 0.68, 0.99},
+	} {
+		icc, f, p, ci, err := ICC(ratings, test.model, test.unit)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+			tol       float64
+		}{
+			{"ICC", icc, test.icc, 0.005},
+			{"F", f, test.f, 0.05},
+			{"p-value", p, test.p, 5e-6},
+			{"lower bound", ci[0], test.lo, 0.005},
+			{"upper bound", ci[1], test.hi, 0.005},
+		} {
+			if math.Abs(v.got-v.want) > v.tol {
+				t.Errorf("model %d, unit %d: %s mismatch. Want %v, got %v", test.model, test.unit, v.name, v.want, v.got)
+			}
+		}
+	}
+
+	// ICC(3, 1) measures consistency, so it is one for ratings that differ
+	// only by a rater offset, while ICC(2, 1) measures absolute agreement.
+	shifted := mat64.NewDense(4, 3, []float64{
+		1, 3, 4,
+		2, 4, 5,
+		5, 7, 8,
+		3, 5, 6,
+	})
+	if icc, _, _, _, _ := ICC(shifted, TwoWayMixed, SingleRating); math.Abs(icc-1) > 1e-14 {
+		t.Errorf("ICC(3, 1) of shifted ratings mismatch. Want 1, got %v", icc)
+	}
+	if icc, _, _, _, _ := ICC(shifted, TwoWayRandom, SingleRating); icc >= 1 {
+		t.Errorf("ICC(2, 1) of shifted ratings not less than one: %v", icc)
+	}
+
+	missing := mat64.DenseCopyOf(ratings)
+	missing.Set(2, 1, math.NaN())
+	if _, _, _, _, err := ICC(missing, TwoWayRandom, SingleRating); err == nil {
+		t.Errorf("expected error for missing rating")
+	}
+	complete := ListwiseDelete(missing)
+	if r, _ := complete.Dims(); r != 5 {
+		t.Errorf("unexpected number of complete subjects: %d", r)
+	}
+	if _, _, _, _, err := ICC(complete, TwoWayRandom, SingleRating); err != nil {
+		t.Errorf("unexpected error after listwise deletion: %v", err)
+	}
+
+	if !Panics(func() { ICC(mat64.NewDense(1, 3, nil), OneWayRandom, SingleRating) }) {
+		t.Errorf("ICC did not panic with a single subject")
+	}
+}
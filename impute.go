@@ -0,0 +1,149 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ImputeKind specifies the statistic that replaces the missing values of a
+// column.
+type ImputeKind int
+
+const (
+	// ImputeMean fills with the mean of the values of the column.
+	ImputeMean ImputeKind = iota
+	// ImputeMedian fills with the median of the values of the column.
+	ImputeMedian
+	// ImputeMostFrequent fills with the most frequent value of the column,
+	// the smallest of them if there are several.
+	ImputeMostFrequent
+	// ImputeConstant fills with the Constant of the ImputeStrategy.
+	ImputeConstant
+)
+
+// ImputeStrategy specifies how ImputeColumns fills the missing values of a
+// matrix, which are the NaN values.
+type ImputeStrategy struct {
+	Kind ImputeKind
+
+	// Constant is the fill value of ImputeConstant. If FillEmpty is true,
+	// it is also the fill value of the columns that have no values.
+	Constant  float64
+	FillEmpty bool
+}
+
+// ErrEmptyColumn is the panic value of ImputeColumns for a column with no
+// values when the strategy does not fill empty columns.
+type ErrEmptyColumn struct {
+	Column int
+}
+
+func (e ErrEmptyColumn) Error() string {
+	return fmt.Sprintf("stat: column %d has no values to impute from", e.Column)
+}
+
+// ImputeModel holds the fill values fitted by ImputeColumns, one for each
+// column. Transform applies the same values to other data, so that for
+// example a test set is filled with the statistics of the training set.
+type ImputeModel struct {
+	Values []float64
+}
+
+// ImputeColumns fits the fill values of the columns of x as specified by
+// strategy, ignoring the NaN values, and stores in dst the matrix x with its
+// NaN values replaced by the fill value of their column. dst must have the
+// same dimensions as x, and may be x itself to fill x in place. ImputeColumns
+// panics with an ErrEmptyColumn if a column of x has no values, unless the
+// kind is ImputeConstant or strategy.FillEmpty is true.
+func ImputeColumns(dst *mat64.Dense, x mat64.Matrix, strategy ImputeStrategy) (fitted ImputeModel) {
+	checkColumnsDst(dst, x)
+	r, c := x.Dims()
+	fitted.Values = make([]float64, c)
+	if strategy.Kind == ImputeConstant {
+		for j := range fitted.Values {
+			fitted.Values[j] = strategy.Constant
+		}
+		fitted.Transform(dst, x)
+		return fitted
+	}
+
+	cols := make([][]float64, c)
+	eachRow(x, 0, r, func(_ int, row []float64) {
+		for j, v := range row {
+			if !math.IsNaN(v) {
+				cols[j] = append(cols[j], v)
+			}
+		}
+	})
+	for j, col := range cols {
+		if len(col) == 0 {
+			if !strategy.FillEmpty {
+				panic(ErrEmptyColumn{Column: j})
+			}
+			fitted.Values[j] = strategy.Constant
+			continue
+		}
+		switch strategy.Kind {
+		case ImputeMean:
+			fitted.Values[j] = Mean(col, nil)
+		case ImputeMedian:
+			sort.Float64s(col)
+			fitted.Values[j] = AsSorted(col).Median()
+		case ImputeMostFrequent:
+			sort.Float64s(col)
+			fitted.Values[j] = mostFrequent(col)
+		default:
+			panic("stat: unknown impute kind")
+		}
+	}
+	fitted.Transform(dst, x)
+	return fitted
+}
+
+// mostFrequent returns the most frequent value of the sorted x, the smallest
+// of them if there are several.
+func mostFrequent(x []float64) float64 {
+	var (
+		best      float64
+		bestCount int
+	)
+	for i := 0; i < len(x); {
+		j := i + 1
+		for j < len(x) && x[j] == x[i] {
+			j++
+		}
+		if j-i > bestCount {
+			best = x[i]
+			bestCount = j - i
+		}
+		i = j
+	}
+	return best
+}
+
+// Transform stores in dst the matrix x with its NaN values replaced by the
+// fill value of their column. x must have as many columns as m has values,
+// and dst must have the same dimensions as x, and may be x itself.
+func (m ImputeModel) Transform(dst *mat64.Dense, x mat64.Matrix) {
+	checkColumnsDst(dst, x)
+	r, c := x.Dims()
+	if c != len(m.Values) {
+		panic(mat64.ErrShape)
+	}
+	eachRow(x, 0, r, func(i int, row []float64) {
+		d := dst.RawRowView(i)
+		for j, v := range row {
+			if math.IsNaN(v) {
+				v = m.Values[j]
+			}
+			d[j] = v
+		}
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestImputeColumns(t *testing.T) {
+	nan := math.NaN()
+	x := mat64.NewDense(6, 3, []float64{
+		1, nan, 2,
+		4, 3, 2,
+		nan, 5, nan,
+		2, 5, 7,
+		10, nan, 1,
+		4, 1, nan,
+	})
+	for _, test := range []struct {
+		kind ImputeKind
+		stat func(col []float64) float64
+	}{
+		{ImputeMean, func(col []float64) float64 { return Mean(col, nil) }},
+		{ImputeMedian, Median},
+		{ImputeMostFrequent, func(col []float64) float64 { m, _ := Mode(col, nil); return m }},
+		{ImputeConstant, func([]float64) float64 { return -1 }},
+	} {
+		dst := mat64.NewDense(6, 3, nil)
+		m := ImputeColumns(dst, x, ImputeStrategy{Kind: test.kind, Constant: -1})
+		for j := 0; j < 3; j++ {
+			var col []float64
+			for _, v := range x.Col(nil, j) {
+				if !math.IsNaN(v) {
+					col = append(col, v)
+				}
+			}
+			want := test.stat(col)
+			if m.Values[j] != want {
+				t.Errorf("kind %d column %d: fitted value mismatch: got %v, want %v", test.kind, j, m.Values[j], want)
+			}
+			for i := 0; i < 6; i++ {
+				v := x.At(i, j)
+				if math.IsNaN(v) {
+					v = want
+				}
+				if dst.At(i, j) != v {
+					t.Errorf("kind %d: filled value mismatch at (%d, %d): got %v, want %v", test.kind, i, j, dst.At(i, j), v)
+				}
+			}
+		}
+
+		// A held out matrix is filled with the fitted values, not its own.
+		held := mat64.NewDense(2, 3, []float64{nan, 100, nan, 100, nan, 200})
+		m.Transform(held, held)
+		want := mat64.NewDense(2, 3, []float64{m.Values[0], 100, m.Values[2], 100, m.Values[1], 200})
+		if !held.Equals(want) {
+			t.Errorf("kind %d: transform mismatch: got %v, want %v", test.kind, held, want)
+		}
+	}
+
+	// The smallest of several most frequent values.
+	m := ImputeColumns(mat64.NewDense(5, 1, nil), mat64.NewDense(5, 1, []float64{3, 2, nan, 3, 2}), ImputeStrategy{Kind: ImputeMostFrequent})
+	if m.Values[0] != 2 {
+		t.Errorf("most frequent tie mismatch: got %v, want 2", m.Values[0])
+	}
+
+	empty := mat64.NewDense(2, 2, []float64{1, nan, 3, nan})
+	m = ImputeColumns(empty, empty, ImputeStrategy{Kind: ImputeMedian, Constant: 7, FillEmpty: true})
+	if m.Values[0] != 2 || m.Values[1] != 7 || empty.At(1, 1) != 7 {
+		t.Errorf("empty column fill mismatch: got %v", m.Values)
+	}
+
+	empty = mat64.NewDense(2, 2, []float64{1, nan, 3, nan})
+	func() {
+		defer func() {
+			if e, ok := recover().(ErrEmptyColumn); !ok || e.Column != 1 {
+				t.Errorf("unexpected panic value for empty column: %v", e)
+			}
+		}()
+		ImputeColumns(empty, empty, ImputeStrategy{Kind: ImputeMean})
+	}()
+
+	for _, f := range []func(){
+		func() { ImputeColumns(mat64.NewDense(6, 2, nil), x, ImputeStrategy{}) },
+		func() { ImputeModel{Values: []float64{1, 2}}.Transform(mat64.NewDense(6, 3, nil), x) },
+		func() { ImputeModel{Values: []float64{1, 2, 3}}.Transform(mat64.NewDense(2, 3, nil), x) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
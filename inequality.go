@@ -0,0 +1,156 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+// Gini returns the Gini coefficient of the non-negative values in x with the
+// given weights, computed as twice the covariance between the values and
+// their cumulative distribution, divided by the mean,
+//  G = 2 Σ_i w_i x_i (F_i - 1/2) / (W μ)
+// where the values are sorted, F_i is the cumulative weight up to the middle
+// of the i-th value divided by the total weight W, and μ is the weighted
+// mean. G is 0 when all values are equal and approaches 1 when a single value
+// holds the total. This is the population definition, so that integer
+// weights give the same result as repeated values, and it equals ineq::Gini
+// in R with corr = FALSE.
+//
+// If weights is nil then all of the weights are 1. If weights is not nil,
+// then len(x) must equal len(weights). Gini panics if a value or a weight is
+// negative. If all of the values are zero, Gini returns NaN.
+func Gini(x, weights []float64) float64 {
+	x, weights = sortedNonNegative(x, weights)
+	var total, sum float64
+	for i, v := range x {
+		total += weight(weights, i)
+		sum += weight(weights, i) * v
+	}
+	var cum, g float64
+	for i, v := range x {
+		w := weight(weights, i)
+		g += w * v * ((cum+w/2)/total - 0.5)
+		cum += w
+	}
+	return 2 * g / sum
+}
+
+// LorenzCurve returns the points of the Lorenz curve of the non-negative
+// values in x with the given weights. The values are sorted in increasing
+// order and cumPop[i] and cumValue[i] are the fractions of the total weight
+// and of the total weighted value held by the first i values, so that the
+// curve starts at (0, 0) and ends at (1, 1). The area between the curve and
+// the diagonal is half the Gini coefficient.
+//
+// If weights is nil then all of the weights are 1. If weights is not nil,
+// then len(x) must equal len(weights). LorenzCurve panics if a value or a
+// weight is negative.
+func LorenzCurve(x, weights []float64) (cumPop, cumValue []float64) {
+	x, weights = sortedNonNegative(x, weights)
+	cumPop = make([]float64, len(x)+1)
+	cumValue = make([]float64, len(x)+1)
+	for i, v := range x {
+		w := weight(weights, i)
+		cumPop[i+1] = cumPop[i] + w
+		cumValue[i+1] = cumValue[i] + w*v
+	}
+	totalPop := cumPop[len(x)]
+	totalValue := cumValue[len(x)]
+	for i := range cumPop {
+		cumPop[i] /= totalPop
+		cumValue[i] /= totalValue
+	}
+	return cumPop, cumValue
+}
+
+// sortedNonNegative returns sorted copies of x and weights after checking
+// that they are non-negative.
+func sortedNonNegative(x, weights []float64) ([]float64, []float64) {
+	checkWeightLength(x, weights)
+	for i, v := range x {
+		if v < 0 || (weights != nil && weights[i] < 0) {
+			panic("stat: negative value")
+		}
+	}
+	sx := make([]float64, len(x))
+	copy(sx, x)
+	var sw []float64
+	if weights != nil {
+		sw = make([]float64, len(weights))
+		copy(sw, weights)
+	}
+	SortWeighted(sx, sw)
+	return sx, sw
+}
+
+// weight returns the i-th weight, or 1 if weights is nil.
+func weight(weights []float64, i int) float64 {
+	if weights == nil {
+		return 1
+	}
+	return weights[i]
+}
+
+// HHI returns the Herfindahl–Hirschman index of market concentration,
+//  H = Σ_i s_i²
+// where s_i = x_i / Σ_j x_j are the market shares of the firms. The values
+// in x may be shares summing to one or sizes such as sales, which are
+// normalized internally. H ranges from 1/n for n firms of equal size to 1
+// for a monopoly.
+//
+// If normalized is true, the index is rescaled to range from 0 to 1
+// whatever the number of firms,
+//  H* = (H - 1/n) / (1 - 1/n)
+// which is defined as 1 for a single firm. HHIPoints gives the index on the
+// 0 to 10,000 scale used in antitrust guidelines. HHI panics if x is empty
+// or if a value is negative.
+func HHI(x []float64, normalized bool) float64 {
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	var sum float64
+	for _, v := range x {
+		if v < 0 {
+			panic("stat: negative value")
+		}
+		sum += v
+	}
+	var h float64
+	for _, v := range x {
+		s := v / sum
+		h += s * s
+	}
+	if !normalized {
+		return h
+	}
+	if len(x) == 1 {
+		return 1
+	}
+	n := float64(len(x))
+	return (h - 1/n) / (1 - 1/n)
+}
+
+// HHIPoints returns the Herfindahl–Hirschman index on the points scale,
+// computed with shares expressed as percentages so that a monopoly has an
+// index of 10,000. It is 10,000 times HHI(x, normalized).
+func HHIPoints(x []float64, normalized bool) float64 {
+	return 10000 * HHI(x, normalized)
+}
+
+// ConcentrationRatio returns the k-firm concentration ratio of the firm sizes
+// in x, the combined share of the total held by the k largest firms.
+// ConcentrationRatio panics if k is not between 1 and len(x) or if a value is
+// negative.
+func ConcentrationRatio(x []float64, k int) float64 {
+	if k < 1 || k > len(x) {
+		panic("stat: number of firms out of range")
+	}
+	sorted, _ := sortedNonNegative(x, nil)
+	var top, sum float64
+	for i, v := range sorted {
+		if i >= len(sorted)-k {
+			top += v
+		}
+		sum += v
+	}
+	return top / sum
+}
@@ -0,0 +1,136 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGini(t *testing.T) {
+	if g := Gini([]float64{3, 3, 3, 3}, nil); g != 0 {
+		t.Errorf("Gini of equal values mismatch. Want 0, got %v", g)
+	}
+
+	// A two-point distribution with values a and b and probability p of b
+	// has G = p(1-p)(b-a)/μ.
+	for _, test := range []struct {
+		a, b, p float64
+	}{
+		{0, 1, 0.5},
+		{2, 7, 0.25},
+		{1, 100, 0.9},
+	} {
+		mu := (1-test.p)*test.a + test.p*test.b
+		want := test.p * (1 - test.p) * (test.b - test.a) / mu
+		got := Gini([]float64{test.b, test.a}, []float64{test.p, 1 - test.p})
+		if math.Abs(got-want) > 1e-14 {
+			t.Errorf("two-point Gini mismatch for %v. Want %v, got %v", test, want, got)
+		}
+	}
+
+	// Σ(2i-n-1) x_(i) / (n Σx), the form used by ineq::Gini, for the values
+	// 1, ..., 5 is 20/75.
+	if g := Gini([]float64{5, 2, 4, 1, 3}, nil); math.Abs(g-20.0/75) > 1e-15 {
+		t.Errorf("Gini mismatch. Want %v, got %v", 20.0/75, g)
+	}
+
+	// Integer weights are equivalent to repeating values.
+	x := []float64{12, 3, 7, 0, 25, 7}
+	w := []float64{2, 1, 3, 1, 1, 2}
+	var rep []float64
+	for i, v := range x {
+		for k := 0; k < int(w[i]); k++ {
+			rep = append(rep, v)
+		}
+	}
+	gw := Gini(x, w)
+	if g := Gini(rep, nil); math.Abs(g-gw) > 1e-14 {
+		t.Errorf("weighted Gini mismatch with repeated values. Want %v, got %v", g, gw)
+	}
+
+	// One minus twice the area under the Lorenz curve.
+	p, l := LorenzCurve(x, w)
+	var area float64
+	for i := 1; i < len(p); i++ {
+		area += (p[i] - p[i-1]) * (l[i] + l[i-1]) / 2
+	}
+	if math.Abs(1-2*area-gw) > 1e-14 {
+		t.Errorf("Lorenz curve area inconsistent with Gini. Want %v, got %v", gw, 1-2*area)
+	}
+
+	if !Panics(func() { Gini([]float64{1, -1}, nil) }) {
+		t.Errorf("Gini did not panic with negative value")
+	}
+	if !Panics(func() { Gini([]float64{1, 2}, []float64{1}) }) {
+		t.Errorf("Gini did not panic with length mismatch")
+	}
+}
+
+func TestLorenzCurve(t *testing.T) {
+	p, l := LorenzCurve([]float64{4, 1, 0, 5}, nil)
+	wantP := []float64{0, 0.25, 0.5, 0.75, 1}
+	wantL := []float64{0, 0, 0.1, 0.5, 1}
+	for i := range wantP {
+		if math.Abs(p[i]-wantP[i]) > 1e-15 || math.Abs(l[i]-wantL[i]) > 1e-15 {
+			t.Errorf("point %d mismatch. Want (%v, %v), got (%v, %v)", i, wantP[i], wantL[i], p[i], l[i])
+		}
+	}
+	if !Panics(func() { LorenzCurve([]float64{1, 2}, []float64{1, -1}) }) {
+		t.Errorf("LorenzCurve did not panic with negative weight")
+	}
+}
+
+func TestHHI(t *testing.T) {
+	for _, test := range []struct {
+		x        []float64
+		h, hNorm float64
+		points   float64
+	}{
+		// Monopoly.
+		{x: []float64{1}, h: 1, hNorm: 1, points: 10000},
+		{x: []float64{250, 0, 0}, h: 1, hNorm: 1, points: 10000},
+		// Equal shares.
+		{x: []float64{5, 5, 5, 5}, h: 0.25, hNorm: 0, points: 2500},
+		// The example in the U.S. Horizontal Merger Guidelines: shares of
+		// 30, 30, 20 and 20 percent give 2,600 points.
+		{x: []float64{30, 30, 20, 20}, h: 0.26, hNorm: (0.26 - 0.25) / 0.75, points: 2600},
+		{x: []float64{0.3, 0.3, 0.2, 0.2}, h: 0.26, hNorm: (0.26 - 0.25) / 0.75, points: 2600},
+	} {
+		if h := HHI(test.x, false); math.Abs(h-test.h) > 1e-14 {
+			t.Errorf("HHI of %v mismatch. Want %v, got %v", test.x, test.h, h)
+		}
+		if h := HHI(test.x, true); math.Abs(h-test.hNorm) > 1e-14 {
+			t.Errorf("normalized HHI of %v mismatch. Want %v, got %v", test.x, test.hNorm, h)
+		}
+		if h := HHIPoints(test.x, false); math.Abs(h-test.points) > 1e-10 {
+			t.Errorf("HHI points of %v mismatch. Want %v, got %v", test.x, test.points, h)
+		}
+	}
+	if !Panics(func() { HHI([]float64{0.5, -0.5}, false) }) {
+		t.Errorf("HHI did not panic with negative value")
+	}
+	if !Panics(func() { HHI(nil, false) }) {
+		t.Errorf("HHI did not panic with empty slice")
+	}
+}
+
+func TestConcentrationRatio(t *testing.T) {
+	x := []float64{10, 40, 5, 25, 20}
+	for k, want := range []float64{0.4, 0.65, 0.85, 0.95, 1} {
+		if cr := ConcentrationRatio(x, k+1); math.Abs(cr-want) > 1e-15 {
+			t.Errorf("CR%d mismatch. Want %v, got %v", k+1, want, cr)
+		}
+	}
+	if !Panics(func() { ConcentrationRatio(x, 0) }) {
+		t.Errorf("ConcentrationRatio did not panic with zero firms")
+	}
+	if !Panics(func() { ConcentrationRatio(x, 6) }) {
+		t.Errorf("ConcentrationRatio did not panic with too many firms")
+	}
+	if !Panics(func() { ConcentrationRatio([]float64{1, -1}, 1) }) {
+		t.Errorf("ConcentrationRatio did not panic with negative value")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// jacobiMaxSweeps is the maximum number of sweeps of symEigen, which
+// converges quadratically and in practice needs fewer than ten.
+const jacobiMaxSweeps = 50
+
+// symEigen computes the eigenvalues and eigenvectors of the n×n symmetric
+// matrix stored in row-major order in a, by the cyclic Jacobi method. a is
+// overwritten with a matrix that is diagonal up to rounding. The eigenvalues
+// are returned in no particular order, and the eigenvectors are the columns
+// of vecs, in row-major order.
+func symEigen(a []float64, n int) (vals, vecs []float64) {
+	vecs = make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		vecs[i*n+i] = 1
+	}
+	for sweep := 0; sweep < jacobiMaxSweeps; sweep++ {
+		var off, diag float64
+		for i := 0; i < n; i++ {
+			diag += a[i*n+i] * a[i*n+i]
+			for j := i + 1; j < n; j++ {
+				off += a[i*n+j] * a[i*n+j]
+			}
+		}
+		if off <= 1e-32*diag || off == 0 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := a[p*n+q]
+				if apq == 0 {
+					continue
+				}
+				// The rotation by the angle that zeroes a_pq.
+				theta := (a[q*n+q] - a[p*n+p]) / (2 * apq)
+				t := 1 / (math.Abs(theta) + math.Hypot(theta, 1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Hypot(t, 1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k*n+p], a[k*n+q]
+					a[k*n+p] = c*akp - s*akq
+					a[k*n+q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p*n+k], a[q*n+k]
+					a[p*n+k] = c*apk - s*aqk
+					a[q*n+k] = s*apk + c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := vecs[k*n+p], vecs[k*n+q]
+					vecs[k*n+p] = c*vkp - s*vkq
+					vecs[k*n+q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+	vals = make([]float64, n)
+	for i := range vals {
+		vals[i] = a[i*n+i]
+	}
+	return vals, vecs
+}
@@ -0,0 +1,393 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/floats"
+)
+
+// BandwidthRule specifies the rule of thumb by which NewKDE chooses the
+// bandwidth of a kernel density estimate.
+type BandwidthRule int
+
+const (
+	// SilvermanBandwidth is Silverman's rule of thumb,
+	//  h = 0.9 min(σ, IQR/1.34) n^(-1/5)
+	// as bw.nrd0 in R, which is the default of density.
+	SilvermanBandwidth BandwidthRule = iota
+	// ScottBandwidth is Scott's rule of thumb,
+	//  h = 1.06 min(σ, IQR/1.34) n^(-1/5)
+	// as bw.nrd in R.
+	ScottBandwidth
+)
+
+// KDE is a Gaussian kernel density estimate,
+//  f(x) = 1/(h W) \sum_i w_i φ((x - x_i) / h)
+// of the weighted sample x_i with total weight W, where φ is the standard
+// normal density and h is the bandwidth.
+type KDE struct {
+	x, w      []float64
+	total     float64
+	bandwidth float64
+}
+
+// NewKDE returns the Gaussian kernel density estimate of the sample x with
+// the given weights, and the bandwidth chosen by rule. The weights are
+// frequency weights, so the sample size n of the rule is their total, and
+// σ and the IQR are the weighted standard deviation, as computed by StdDev,
+// and interquartile range. The quartiles are interpolated between the order
+// statistics as by the default type 7 of R's quantile, with a weight of w
+// counting as w copies of an observation, so that for unit weights the
+// bandwidths are those of bw.nrd0 and bw.nrd. If weights is nil then all of
+// the weights are 1, otherwise len(x) must equal len(weights). x and weights
+// are copied.
+//
+// If the IQR is zero, σ is used in place of the minimum, and if the sample
+// is constant, |x_0| is used, or 1 if it is zero, as in R, so that a
+// constant sample gives a narrow spike at its value. The bandwidth may be
+// changed by SetBandwidth.
+//
+// NewKDE panics if x is empty, if a weight is negative, if the total weight
+// is zero or if rule is not a valid BandwidthRule.
+func NewKDE(x, weights []float64, rule BandwidthRule) *KDE {
+	if err := ValidateWeights(x, weights); err != nil {
+		panic(err)
+	}
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	var factor float64
+	switch rule {
+	case SilvermanBandwidth:
+		factor = 0.9
+	case ScottBandwidth:
+		factor = 1.06
+	default:
+		panic("stat: bad bandwidth rule")
+	}
+	k := &KDE{x: make([]float64, len(x)), w: make([]float64, len(x))}
+	copy(k.x, x)
+	if weights == nil {
+		for i := range k.w {
+			k.w[i] = 1
+		}
+	} else {
+		copy(k.w, weights)
+	}
+	k.total = floats.Sum(k.w)
+	if !(k.total > 0) {
+		panic("stat: zero total weight")
+	}
+
+	// The observations are kept sorted for the cross-validation scores.
+	SortWeighted(k.x, k.w)
+	sorted, sw := k.x, k.w
+	sigma := StdDev(sorted, sw)
+	if math.IsNaN(sigma) {
+		// A single observation has no spread.
+		sigma = 0
+	}
+	iqr := interpolatedQuantile(0.75, sorted, sw) - interpolatedQuantile(0.25, sorted, sw)
+	lo := math.Min(sigma, iqr/1.34)
+	if lo == 0 {
+		lo = sigma
+		if lo == 0 {
+			lo = math.Abs(sorted[0])
+			if lo == 0 {
+				lo = 1
+			}
+		}
+	}
+	k.bandwidth = factor * lo * math.Pow(k.total, -0.2)
+	return k
+}
+
+// interpolatedQuantile returns the p-quantile of the sorted sample x with the
+// frequency weights w by linear interpolation between the order statistics,
+// as type 7 of R's quantile. The order statistic of rank k, counted from
+// zero, is the first x_i whose cumulative weight exceeds k, and the quantile
+// is at rank (W - 1) p for the total weight W.
+func interpolatedQuantile(p float64, x, w []float64) float64 {
+	h := math.Max(0, (floats.Sum(w)-1)*p)
+	k := math.Floor(h)
+	at := func(rank float64) float64 {
+		var cum float64
+		for i, v := range w {
+			cum += v
+			if cum > rank {
+				return x[i]
+			}
+		}
+		return x[len(x)-1]
+	}
+	lo := at(k)
+	if h == k {
+		return lo
+	}
+	return lo + (h-k)*(at(k+1)-lo)
+}
+
+// Bandwidth returns the bandwidth of the estimate.
+func (k *KDE) Bandwidth() float64 {
+	return k.bandwidth
+}
+
+// SetBandwidth sets the bandwidth of the estimate to h, overriding the rule
+// of thumb. SetBandwidth panics if h is not positive.
+func (k *KDE) SetBandwidth(h float64) {
+	if !(h > 0) {
+		panic("stat: non-positive bandwidth")
+	}
+	k.bandwidth = h
+}
+
+// Evaluate returns the density estimate at q, in O(n) time.
+func (k *KDE) Evaluate(q float64) float64 {
+	h := k.bandwidth
+	var f float64
+	for i, v := range k.x {
+		z := (q - v) / h
+		f += k.w[i] * math.Exp(-z*z/2)
+	}
+	return f / (math.Sqrt(2*math.Pi) * h * k.total)
+}
+
+// EvaluateMany evaluates the density estimate at each of the values of q,
+// and stores the results in dst. If dst is nil, a new slice is allocated,
+// otherwise its length must equal len(q).
+func (k *KDE) EvaluateMany(dst, q []float64) []float64 {
+	if dst == nil {
+		dst = make([]float64, len(q))
+	} else if len(dst) != len(q) {
+		panic(ErrLengthMismatch{Got: len(dst), Want: len(q)})
+	}
+	for i, v := range q {
+		dst[i] = k.Evaluate(v)
+	}
+	return dst
+}
+
+// Grid returns the density estimate at n equally spaced points spanning
+// three bandwidths beyond the smallest and largest values of the sample, as
+// density in R does. The estimate is approximated by linear binning: the
+// weight of each observation is divided between the two nearest grid points
+// in proportion to its proximity to each, and the binned weights are
+// convolved with the kernel. This takes O(N + n²) time for a sample of size
+// N, rather than the O(Nn) of EvaluateMany, and the error is small when the
+// grid spacing is small compared with the bandwidth. Grid panics if n is
+// less than 2.
+func (k *KDE) Grid(n int) (x, density []float64) {
+	if n < 2 {
+		panic("stat: too few grid points")
+	}
+	h := k.bandwidth
+	lo, hi := floats.Min(k.x)-3*h, floats.Max(k.x)+3*h
+	x = floats.Span(make([]float64, n), lo, hi)
+	delta := (hi - lo) / float64(n-1)
+
+	bins := make([]float64, n)
+	for i, v := range k.x {
+		pos := (v - lo) / delta
+		j := int(pos)
+		if j >= n-1 {
+			j = n - 2
+		}
+		frac := pos - float64(j)
+		bins[j] += k.w[i] * (1 - frac)
+		bins[j+1] += k.w[i] * frac
+	}
+
+	// The kernel at each grid offset.
+	kernel := make([]float64, n)
+	for d := range kernel {
+		z := float64(d) * delta / h
+		kernel[d] = math.Exp(-z * z / 2)
+	}
+	density = make([]float64, n)
+	norm := math.Sqrt(2*math.Pi) * h * k.total
+	for j := range density {
+		var f float64
+		for i, b := range bins {
+			if b == 0 {
+				continue
+			}
+			d := j - i
+			if d < 0 {
+				d = -d
+			}
+			f += b * kernel[d]
+		}
+		density[j] = f / norm
+	}
+	return x, density
+}
+
+// BandwidthCV specifies the cross-validation criterion by which a bandwidth
+// of a kernel density estimate is selected.
+type BandwidthCV int
+
+const (
+	// LeastSquaresCV is least-squares, or unbiased, cross-validation,
+	// which minimizes the estimate of the integrated squared error
+	//  LSCV(h) = ∫ f² - 2/W \sum_i w_i f_{-i}(x_i)
+	// where f_{-i} is the estimate with the bandwidth h from the sample
+	// without the observation i.
+	LeastSquaresCV BandwidthCV = iota
+	// LikelihoodCV is maximum likelihood, or Kullback–Leibler,
+	// cross-validation, which maximizes the leave-one-out log-likelihood
+	//  1/W \sum_i w_i log f_{-i}(x_i)
+	// It is sensitive to outliers, whose leave-one-out densities vanish as
+	// the bandwidth decreases.
+	LikelihoodCV
+)
+
+// cvCutoff is the number of kernel standard deviations beyond which the
+// contributions of pairs of observations to the cross-validation scores are
+// negligible, below 2e-22 of those of coincident observations.
+const cvCutoff = 10
+
+// CVScore returns the cross-validation score of the bandwidth h for the
+// sample of the estimate by the criterion cv, which is to be minimized: the
+// LSCV score for LeastSquaresCV and the negative leave-one-out
+// log-likelihood for LikelihoodCV. Each observation is left out with its
+// weight. Since the sample is kept sorted, only the pairs of observations
+// within a few bandwidths of each other are visited, so the score takes
+// O(n²) time at worst and close to O(n) time when h is small compared with
+// the spread of the sample. For LikelihoodCV, the leave-one-out density of
+// an observation without others within that reach is computed from the
+// whole sample in the log domain, so that it does not vanish. The bandwidth
+// of the estimate is not changed.
+// CVScore panics if h is not positive, if cv is not a valid BandwidthCV or
+// if the sample has a single observation.
+func (k *KDE) CVScore(h float64, cv BandwidthCV) float64 {
+	if !(h > 0) {
+		panic("stat: non-positive bandwidth")
+	}
+	if cv != LeastSquaresCV && cv != LikelihoodCV {
+		panic("stat: bad bandwidth cross-validation criterion")
+	}
+	n := len(k.x)
+	if n < 2 {
+		panic("stat: too few samples for cross-validation")
+	}
+	// The pairs are visited once, for j > i, with the kernel of standard
+	// deviation h for the leave-one-out densities and √2 h for ∫ f², the
+	// convolution of the kernel with itself.
+	loo := make([]float64, n)
+	var conv float64
+	reach := cvCutoff * h
+	if cv == LeastSquaresCV {
+		reach *= math.Sqrt2
+	}
+	for i := 0; i < n; i++ {
+		wi := k.w[i]
+		for j := i + 1; j < n && k.x[j]-k.x[i] <= reach; j++ {
+			z := (k.x[j] - k.x[i]) / h
+			phi := math.Exp(-z * z / 2)
+			loo[i] += k.w[j] * phi
+			loo[j] += wi * phi
+			if cv == LeastSquaresCV {
+				conv += 2 * wi * k.w[j] * math.Exp(-z*z/4)
+			}
+		}
+	}
+	norm := math.Sqrt(2*math.Pi) * h
+	var sum float64
+	for i, v := range loo {
+		if cv == LeastSquaresCV {
+			sum += k.w[i] * v / (norm * (k.total - k.w[i]))
+			continue
+		}
+		var logf float64
+		if v > 0 {
+			logf = math.Log(v)
+		} else {
+			logf = k.isolatedLogSum(i, h)
+		}
+		sum += k.w[i] * (logf - math.Log(norm*(k.total-k.w[i])))
+	}
+	if cv == LikelihoodCV {
+		return -sum / k.total
+	}
+	// The diagonal terms of ∫ f².
+	for _, w := range k.w {
+		conv += w * w
+	}
+	return conv/(math.Sqrt2*norm*k.total*k.total) - 2*sum/k.total
+}
+
+// CVBandwidths returns the bandwidth of the candidates with the smallest
+// cross-validation score by the criterion cv, as computed by CVScore, and
+// the scores of all of the candidates. The bandwidth of the estimate is not
+// changed; SetBandwidth may be used to adopt the selected bandwidth.
+// CVBandwidths panics if there are no candidates or a candidate is not
+// positive.
+func (k *KDE) CVBandwidths(cv BandwidthCV, candidates []float64) (best float64, scores []float64) {
+	if len(candidates) == 0 {
+		panic("stat: no candidate bandwidths")
+	}
+	scores = make([]float64, len(candidates))
+	for i, h := range candidates {
+		scores[i] = k.CVScore(h, cv)
+	}
+	return candidates[floats.MinIdx(scores)], scores
+}
+
+// CVBandwidthSearch returns the bandwidth in [lo, hi] minimizing the
+// cross-validation score by the criterion cv, as computed by CVScore, found
+// by golden-section search on the logarithm of the bandwidth to a relative
+// precision of 1e-4. The score is assumed to be unimodal in the interval,
+// which otherwise should be narrowed using CVBandwidths on a grid of
+// candidates. The bandwidth of the estimate is not changed.
+// CVBandwidthSearch panics if lo is not positive or hi is less than lo.
+func (k *KDE) CVBandwidthSearch(cv BandwidthCV, lo, hi float64) float64 {
+	if !(lo > 0) || !(hi >= lo) {
+		panic("stat: bad bandwidth interval")
+	}
+	const invPhi = 0.6180339887498949 // (√5 - 1) / 2
+	score := func(t float64) float64 { return k.CVScore(math.Exp(t), cv) }
+	a, b := math.Log(lo), math.Log(hi)
+	c := b - invPhi*(b-a)
+	d := a + invPhi*(b-a)
+	fc, fd := score(c), score(d)
+	for b-a > 1e-4 {
+		if fc <= fd {
+			b, d, fd = d, c, fc
+			c = b - invPhi*(b-a)
+			fc = score(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + invPhi*(b-a)
+			fd = score(d)
+		}
+	}
+	return math.Exp((a + b) / 2)
+}
+
+// isolatedLogSum returns the logarithm of
+//  \sum_{j≠i} w_j exp(-((x_i - x_j) / h)² / 2)
+// for an observation i without others within cvCutoff bandwidths, scaled by
+// the term of the nearest observation so that it does not underflow.
+func (k *KDE) isolatedLogSum(i int, h float64) float64 {
+	zmin := math.Inf(1)
+	for j, v := range k.x {
+		if j != i && k.w[j] > 0 {
+			zmin = math.Min(zmin, math.Abs(k.x[i]-v)/h)
+		}
+	}
+	if math.IsInf(zmin, 1) {
+		return math.Inf(-1)
+	}
+	var s float64
+	for j, v := range k.x {
+		if j != i {
+			z := (k.x[i] - v) / h
+			s += k.w[j] * math.Exp(-(z*z-zmin*zmin)/2)
+		}
+	}
+	return math.Log(s) - zmin*zmin/2
+}
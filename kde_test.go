@@ -0,0 +1,233 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestKDE(t *testing.T) {
+	x := []float64{3, 1, 5, 2, 4}
+	// σ = 1.58 and IQR/1.34 = 1.49.
+	for _, test := range []struct {
+		rule   BandwidthRule
+		factor float64
+	}{
+		{SilvermanBandwidth, 0.9},
+		{ScottBandwidth, 1.06},
+	} {
+		k := NewKDE(x, nil, test.rule)
+		if got, want := k.Bandwidth(), test.factor*2/1.34*math.Pow(5, -0.2); math.Abs(got-want) > 1e-15 {
+			t.Errorf("rule %d: bandwidth mismatch. Want %v, got %v", test.rule, want, got)
+		}
+	}
+
+	// The quartiles are interpolated as by R's IQR, so that the bandwidths
+	// are those of bw.nrd0 and bw.nrd, 0.9 and 1.06 times
+	// min(sd(x), IQR(x)/1.34) length(x)^(-1/5). For 1:6 the IQR is 2.5,
+	// where the Empirical quartiles would give 3.
+	for _, test := range []struct {
+		rule BandwidthRule
+		want float64
+	}{
+		{SilvermanBandwidth, 0.9 * 2.5 / 1.34 * math.Pow(6, -0.2)},
+		{ScottBandwidth, 1.06 * 2.5 / 1.34 * math.Pow(6, -0.2)},
+	} {
+		if got := NewKDE([]float64{4, 1, 6, 2, 5, 3}, nil, test.rule).Bandwidth(); math.Abs(got-test.want) > 1e-15 {
+			t.Errorf("rule %d: bandwidth of 1:6 mismatch. Want %v, got %v", test.rule, test.want, got)
+		}
+	}
+	for _, test := range []struct {
+		p, want float64
+	}{
+		{0, 1}, {0.25, 2.25}, {0.5, 3.5}, {0.75, 4.75}, {1, 6},
+	} {
+		if got := interpolatedQuantile(test.p, []float64{1, 2, 3, 4, 5, 6}, []float64{1, 1, 1, 1, 1, 1}); math.Abs(got-test.want) > 1e-15 {
+			t.Errorf("interpolated quantile at %v mismatch. Want %v, got %v", test.p, test.want, got)
+		}
+	}
+
+	k := NewKDE(x, nil, SilvermanBandwidth)
+	k.SetBandwidth(0.5)
+	if k.Bandwidth() != 0.5 {
+		t.Errorf("bandwidth not set")
+	}
+	for _, q := range []float64{-1, 0, 2.5, 3, 7} {
+		var want float64
+		for _, v := range x {
+			z := (q - v) / 0.5
+			want += math.Exp(-z*z/2) / (math.Sqrt(2*math.Pi) * 0.5 * 5)
+		}
+		if got := k.Evaluate(q); math.Abs(got-want) > 1e-15 {
+			t.Errorf("density at %v mismatch. Want %v, got %v", q, want, got)
+		}
+	}
+
+	// Integer weights are equivalent to repeated observations.
+	weighted := NewKDE([]float64{1, 2, 6}, []float64{2, 1, 3}, SilvermanBandwidth)
+	repeated := NewKDE([]float64{1, 1, 2, 6, 6, 6}, nil, SilvermanBandwidth)
+	if math.Abs(weighted.Bandwidth()-repeated.Bandwidth()) > 1e-14 {
+		t.Errorf("bandwidth mismatch for weights. Want %v, got %v", repeated.Bandwidth(), weighted.Bandwidth())
+	}
+	q := []float64{0, 1.5, 4, 6.5}
+	got := weighted.EvaluateMany(nil, q)
+	want := repeated.EvaluateMany(make([]float64, len(q)), q)
+	if !sameFloatsNaN(got, want, 1e-14) {
+		t.Errorf("density mismatch for weights. Want %v, got %v", want, got)
+	}
+
+	// The estimate integrates to 1, and the grid approximation is close
+	// to the exact estimate.
+	rnd := rand.New(rand.NewSource(1))
+	sample := make([]float64, 2000)
+	for i := range sample {
+		sample[i] = rnd.NormFloat64()
+		if i%3 == 0 {
+			sample[i] = 4 + 0.5*rnd.NormFloat64()
+		}
+	}
+	k = NewKDE(sample, nil, ScottBandwidth)
+	gx, gd := k.Grid(512)
+	if len(gx) != 512 || len(gd) != 512 {
+		t.Fatalf("unexpected grid lengths %d and %d", len(gx), len(gd))
+	}
+	exact := k.EvaluateMany(nil, gx)
+	var integral, maxErr, peak float64
+	for i := range gx {
+		if i > 0 {
+			integral += (gx[i] - gx[i-1]) * (exact[i] + exact[i-1]) / 2
+		}
+		maxErr = math.Max(maxErr, math.Abs(gd[i]-exact[i]))
+		peak = math.Max(peak, exact[i])
+	}
+	if math.Abs(integral-1) > 1e-3 {
+		t.Errorf("density integrates to %v", integral)
+	}
+	if maxErr > 1e-3*peak {
+		t.Errorf("grid approximation error %v too large for peak density %v", maxErr, peak)
+	}
+
+	// A constant sample gives a narrow spike.
+	k = NewKDE([]float64{2, 2, 2}, nil, SilvermanBandwidth)
+	if h := k.Bandwidth(); h != 0.9*2*math.Pow(3, -0.2) {
+		t.Errorf("bandwidth of constant sample mismatch. Got %v", h)
+	}
+	if d := k.Evaluate(2); math.IsNaN(d) || math.IsInf(d, 0) || d <= k.Evaluate(3) {
+		t.Errorf("unexpected density of constant sample at its value: %v", d)
+	}
+	if h := NewKDE([]float64{0}, nil, SilvermanBandwidth).Bandwidth(); h != 0.9 {
+		t.Errorf("bandwidth of a zero sample: want 0.9, got %v", h)
+	}
+
+	for i, fn := range []func(){
+		func() { NewKDE(nil, nil, SilvermanBandwidth) },
+		func() { NewKDE(x, []float64{1}, SilvermanBandwidth) },
+		func() { NewKDE([]float64{1, 2}, []float64{1, -1}, SilvermanBandwidth) },
+		func() { NewKDE([]float64{1, 2}, []float64{0, 0}, SilvermanBandwidth) },
+		func() { NewKDE(x, nil, BandwidthRule(2)) },
+		func() { NewKDE(x, nil, SilvermanBandwidth).SetBandwidth(0) },
+		func() { NewKDE(x, nil, SilvermanBandwidth).Grid(1) },
+		func() { NewKDE(x, nil, SilvermanBandwidth).EvaluateMany(make([]float64, 1), q) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestKDEBandwidthCV(t *testing.T) {
+	// The scores match a direct evaluation of the definitions.
+	x := []float64{0.3, -1.2, 2.5, 0.9, 1.1, -0.4, 7}
+	w := []float64{1, 2, 0.5, 1, 3, 1, 1}
+	k := NewKDE(x, w, SilvermanBandwidth)
+	var total float64
+	for _, v := range w {
+		total += v
+	}
+	phi := func(z, h float64) float64 { return math.Exp(-z*z/(2*h*h)) / (math.Sqrt(2*math.Pi) * h) }
+	for _, h := range []float64{0.2, 0.7, 3} {
+		var sq, loo, ll float64
+		for i := range x {
+			var f float64
+			for j := range x {
+				sq += w[i] * w[j] * phi(x[i]-x[j], math.Sqrt2*h)
+				if j != i {
+					f += w[j] * phi(x[i]-x[j], h)
+				}
+			}
+			f /= total - w[i]
+			loo += w[i] * f
+			ll += w[i] * math.Log(f)
+		}
+		wantLS := sq/(total*total) - 2*loo/total
+		if got := k.CVScore(h, LeastSquaresCV); math.Abs(got-wantLS) > 1e-14 {
+			t.Errorf("LSCV score at %v mismatch. Want %v, got %v", h, wantLS, got)
+		}
+		wantML := -ll / total
+		if got := k.CVScore(h, LikelihoodCV); math.Abs(got-wantML) > 1e-13 {
+			t.Errorf("likelihood CV score at %v mismatch. Want %v, got %v", h, wantML, got)
+		}
+	}
+
+	// For a clearly bimodal mixture, cross-validation picks a much smaller
+	// bandwidth than Silverman's rule, which is driven by the spread between
+	// the modes.
+	rnd := rand.New(rand.NewSource(1))
+	sample := make([]float64, 400)
+	for i := range sample {
+		sample[i] = 0.5 * rnd.NormFloat64()
+		if i%2 == 0 {
+			sample[i] -= 3
+		} else {
+			sample[i] += 3
+		}
+	}
+	k = NewKDE(sample, nil, SilvermanBandwidth)
+	silverman := k.Bandwidth()
+	candidates := make([]float64, 40)
+	for i := range candidates {
+		candidates[i] = 0.02 * float64(i+1)
+	}
+	for _, cv := range []BandwidthCV{LeastSquaresCV, LikelihoodCV} {
+		best, scores := k.CVBandwidths(cv, candidates)
+		if len(scores) != len(candidates) {
+			t.Fatalf("criterion %d: unexpected number of scores %d", cv, len(scores))
+		}
+		for i, s := range scores {
+			if s < k.CVScore(best, cv) {
+				t.Errorf("criterion %d: candidate %v scores lower than the selected %v", cv, candidates[i], best)
+			}
+		}
+		if best > silverman/2 || best == candidates[0] || best == candidates[len(candidates)-1] {
+			t.Errorf("criterion %d: unexpected bandwidth %v selected from grid, Silverman's is %v", cv, best, silverman)
+		}
+		h := k.CVBandwidthSearch(cv, 0.02, 0.8)
+		if math.Abs(h-best) > 0.02 {
+			t.Errorf("criterion %d: search found %v, grid %v", cv, h, best)
+		}
+		if k.CVScore(h, cv) > k.CVScore(best, cv)+1e-12 {
+			t.Errorf("criterion %d: search bandwidth %v scores worse than grid %v", cv, h, best)
+		}
+	}
+	if k.Bandwidth() != silverman {
+		t.Errorf("cross-validation changed the bandwidth")
+	}
+
+	for i, fn := range []func(){
+		func() { k.CVScore(0, LeastSquaresCV) },
+		func() { k.CVScore(1, BandwidthCV(2)) },
+		func() { NewKDE([]float64{1}, nil, SilvermanBandwidth).CVScore(1, LikelihoodCV) },
+		func() { k.CVBandwidths(LeastSquaresCV, nil) },
+		func() { k.CVBandwidths(LeastSquaresCV, []float64{1, -1}) },
+		func() { k.CVBandwidthSearch(LeastSquaresCV, 0, 1) },
+		func() { k.CVBandwidthSearch(LeastSquaresCV, 2, 1) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// LoadOptions specifies how LoadColumns reads delimited data.
+type LoadOptions struct {
+	// Comma is the field delimiter. If zero, ',' is used. Use '\t' for
+	// tab separated data.
+	Comma rune
+
+	// Comment, if not zero, is the character that starts a comment line.
+	Comment rune
+
+	// Columns and Indices select the columns to load by header name and by
+	// zero-based index, in the order given. If both are nil, all columns
+	// whose values in the first data row are numeric or missing are loaded,
+	// in the order of the file.
+	Columns []string
+	Indices []int
+
+	// Missing holds the tokens that denote a missing value, which is loaded
+	// as NaN. If nil, the empty field and "NA" are missing. Fields are
+	// compared after removing leading and trailing space.
+	Missing []string
+
+	// Weights is the name of the column holding the weights of the rows,
+	// read by LoadColumnsWeighted. The weights column is not loaded into
+	// the data matrix.
+	Weights string
+}
+
+// loadBlockRows is the number of rows in each block of values held by
+// LoadColumns while reading.
+const loadBlockRows = 4096
+
+// LoadColumns reads delimited data with a header line from r and returns the
+// numeric columns selected by opts as the columns of a matrix, along with
+// their names. Fields may be quoted as described in encoding/csv. Missing
+// values are loaded as NaN, for use with the NaN-aware functions of the
+// package.
+//
+// The values are parsed as the data are read and held in blocks of rows, so
+// the memory used is about twice the size of the returned matrix, however
+// large the input. LoadColumns returns an error if the data cannot be read,
+// if a selected column does not exist, if a field of a selected column is
+// neither a number nor a missing token, or if opts.Weights is set, in which
+// case LoadColumnsWeighted must be used.
+func LoadColumns(r io.Reader, opts LoadOptions) (*mat64.Dense, []string, error) {
+	if opts.Weights != "" {
+		return nil, nil, errors.New("stat: weights column requires LoadColumnsWeighted")
+	}
+	data, _, names, err := loadColumns(r, opts)
+	return data, names, err
+}
+
+// LoadColumnsWeighted is like LoadColumns, but also returns the values of the
+// column named by opts.Weights as the weights of the rows. A missing weight
+// is loaded as NaN. LoadColumnsWeighted returns an error if opts.Weights is
+// not set.
+func LoadColumnsWeighted(r io.Reader, opts LoadOptions) (data *mat64.Dense, weights []float64, names []string, err error) {
+	if opts.Weights == "" {
+		return nil, nil, nil, errors.New("stat: no weights column")
+	}
+	return loadColumns(r, opts)
+}
+
+func loadColumns(r io.Reader, opts LoadOptions) (*mat64.Dense, []float64, []string, error) {
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.Comment = opts.Comment
+	missing := opts.Missing
+	if missing == nil {
+		missing = []string{"", "NA"}
+	}
+	isMissing := make(map[string]bool, len(missing))
+	for _, m := range missing {
+		isMissing[strings.TrimSpace(m)] = true
+	}
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, nil, errors.New("stat: no header")
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	byName := make(map[string]int, len(header))
+	for i, h := range header {
+		byName[h] = i
+	}
+	wcol := -1
+	if opts.Weights != "" {
+		i, ok := byName[opts.Weights]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("stat: no column %q", opts.Weights)
+		}
+		wcol = i
+	}
+
+	var cols []int
+	for _, name := range opts.Columns {
+		i, ok := byName[name]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("stat: no column %q", name)
+		}
+		cols = append(cols, i)
+	}
+	for _, i := range opts.Indices {
+		if i < 0 || i >= len(header) {
+			return nil, nil, nil, fmt.Errorf("stat: column index %d out of range", i)
+		}
+		cols = append(cols, i)
+	}
+
+	parse := func(field string, rec, col int) (float64, error) {
+		field = strings.TrimSpace(field)
+		if isMissing[field] {
+			return math.NaN(), nil
+		}
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return 0, fmt.Errorf("stat: data row %d, column %q: invalid number %q", rec, header[col], field)
+		}
+		return v, nil
+	}
+
+	var (
+		blocks  [][]float64
+		block   []float64
+		weights []float64
+		rows    int
+	)
+	for rec := 1; ; rec++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if cols == nil {
+			// Select the columns from the first data row.
+			cols = []int{}
+			for i, field := range record {
+				if i == wcol {
+					continue
+				}
+				if _, err := parse(field, rec, i); err == nil {
+					cols = append(cols, i)
+				}
+			}
+			if len(cols) == 0 {
+				return nil, nil, nil, errors.New("stat: no numeric columns")
+			}
+		}
+		if block == nil {
+			block = make([]float64, 0, loadBlockRows*len(cols))
+		}
+		for _, i := range cols {
+			v, err := parse(record[i], rec, i)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			block = append(block, v)
+		}
+		if wcol >= 0 {
+			w, err := parse(record[wcol], rec, wcol)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			weights = append(weights, w)
+		}
+		rows++
+		if len(block) == cap(block) {
+			blocks = append(blocks, block)
+			block = nil
+		}
+	}
+	if rows == 0 {
+		return nil, nil, nil, errors.New("stat: no data rows")
+	}
+	blocks = append(blocks, block)
+
+	data := make([]float64, 0, rows*len(cols))
+	for i, b := range blocks {
+		data = append(data, b...)
+		blocks[i] = nil
+	}
+	names := make([]string, len(cols))
+	for k, i := range cols {
+		names[k] = header[i]
+	}
+	return mat64.NewDense(rows, len(cols), data), weights, names, nil
+}
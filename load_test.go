@@ -0,0 +1,198 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// sameFloats reports whether a and b are equal, treating NaNs as equal.
+func sameFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if v != b[i] && !(math.IsNaN(v) && math.IsNaN(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLoadColumns(t *testing.T) {
+	nan := math.NaN()
+	const data = `id,name,height,weight,w
+1,"Smith, J",1.80,75.5,1
+2,"O""Brien",1.65,NA,2
+3,Lee, 1.72 ,,0.5
+`
+	for i, test := range []struct {
+		opts  LoadOptions
+		input string
+		rows  []float64
+		names []string
+	}{
+		{
+			// Columns of text are skipped.
+			input: data,
+			rows:  []float64{1, 1.80, 75.5, 1, 2, 1.65, nan, 2, 3, 1.72, nan, 0.5},
+			names: []string{"id", "height", "weight", "w"},
+		},
+		{
+			opts:  LoadOptions{Columns: []string{"weight", "height"}},
+			input: data,
+			rows:  []float64{75.5, 1.80, nan, 1.65, nan, 1.72},
+			names: []string{"weight", "height"},
+		},
+		{
+			opts:  LoadOptions{Indices: []int{0, 3}},
+			input: data,
+			rows:  []float64{1, 75.5, 2, nan, 3, nan},
+			names: []string{"id", "weight"},
+		},
+		{
+			opts:  LoadOptions{Comma: '\t', Missing: []string{"?"}},
+			input: "a\tb\n1\t?\n\"2\"\t3e2\n",
+			rows:  []float64{1, nan, 2, 300},
+			names: []string{"a", "b"},
+		},
+		{
+			opts:  LoadOptions{Comment: '#'},
+			input: "# comment\nx\n# another\n-1\n",
+			rows:  []float64{-1},
+			names: []string{"x"},
+		},
+	} {
+		m, names, err := LoadColumns(strings.NewReader(test.input), test.opts)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(names, test.names) {
+			t.Errorf("case %d: names mismatch: got %v, want %v", i, names, test.names)
+		}
+		r, c := m.Dims()
+		if r*c != len(test.rows) || c != len(test.names) {
+			t.Errorf("case %d: dimension mismatch: got %d×%d", i, r, c)
+			continue
+		}
+		if !sameFloats(m.RawMatrix().Data, test.rows) {
+			t.Errorf("case %d: data mismatch: got %v, want %v", i, m.RawMatrix().Data, test.rows)
+		}
+	}
+
+	m, w, names, err := LoadColumnsWeighted(strings.NewReader(data), LoadOptions{Weights: "w"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"id", "height", "weight"}) {
+		t.Errorf("weighted names mismatch: got %v", names)
+	}
+	if !reflect.DeepEqual(w, []float64{1, 2, 0.5}) {
+		t.Errorf("weights mismatch: got %v", w)
+	}
+	if r, c := m.Dims(); r != 3 || c != 3 {
+		t.Errorf("weighted dimension mismatch: got %d×%d", r, c)
+	}
+
+	for i, test := range []struct {
+		opts  LoadOptions
+		input string
+	}{
+		{LoadOptions{}, ""},
+		{LoadOptions{}, "a,b\n"},
+		{LoadOptions{}, "a,b\nx,y\n"},
+		{LoadOptions{Columns: []string{"c"}}, "a,b\n1,2\n"},
+		{LoadOptions{Indices: []int{2}}, "a,b\n1,2\n"},
+		{LoadOptions{Columns: []string{"b"}}, "a,b\n1,2\n3,x\n"},
+		{LoadOptions{}, "a,b\n1,2\n3\n"},
+		{LoadOptions{}, "a,b\n1,\"2\n"},
+		{LoadOptions{Weights: "b"}, "a,b\n1,2\n"},
+	} {
+		if _, _, err := LoadColumns(strings.NewReader(test.input), test.opts); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+	if _, _, _, err := LoadColumnsWeighted(strings.NewReader("a,b\n1,2\n"), LoadOptions{}); err == nil {
+		t.Errorf("expected error without weights column")
+	}
+}
+
+func TestLoadColumnsBlocks(t *testing.T) {
+	// Enough rows to fill several blocks.
+	n := 2*loadBlockRows + 17
+	var buf bytes.Buffer
+	buf.WriteString("x,y\n")
+	want := mat64.NewDense(n, 2, nil)
+	for i := 0; i < n; i++ {
+		want.Set(i, 0, float64(i))
+		want.Set(i, 1, float64(-i)/4)
+		fmt.Fprintf(&buf, "%v,%v\n", want.At(i, 0), want.At(i, 1))
+	}
+	m, _, err := LoadColumns(&buf, LoadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !m.Equals(want) {
+		t.Errorf("data mismatch across blocks")
+	}
+}
+
+func loadBenchData(rows, cols int) []byte {
+	var buf bytes.Buffer
+	for j := 0; j < cols; j++ {
+		if j > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, "c%d", j)
+	}
+	buf.WriteByte('\n')
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strconv.FormatFloat(rand.NormFloat64(), 'g', -1, 64))
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkLoadColumns(b *testing.B) {
+	data := loadBenchData(10000, 20)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LoadColumns(bytes.NewReader(data), LoadOptions{})
+	}
+}
+
+func BenchmarkLoadColumnsNaive(b *testing.B) {
+	data := loadBenchData(10000, 20)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		records, _ := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		var vals []float64
+		for _, rec := range records[1:] {
+			for _, f := range rec {
+				v, _ := strconv.ParseFloat(f, 64)
+				vals = append(vals, v)
+			}
+		}
+		mat64.NewDense(len(records)-1, len(records[0]), vals)
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"errors"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+var (
+	// ErrSeparation is returned by NewLogistic if the classes of the
+	// response are perfectly or quasi-perfectly separated by the predictors,
+	// in which case the maximum likelihood estimates do not exist.
+	ErrSeparation = errors.New("stat: fitted probabilities numerically 0 or 1")
+
+	// ErrNotConverged is returned by NewLogistic if the fit did not converge
+	// within the maximum number of iterations.
+	ErrNotConverged = errors.New("stat: logistic regression did not converge")
+)
+
+// LogisticSettings holds the convergence settings of NewLogistic. A zero
+// field selects the default value.
+type LogisticSettings struct {
+	// Tol is the convergence tolerance on the relative change of the
+	// deviance between iterations, |D - D_old| / (|D| + 0.1). The default
+	// is 1e-8, as used by glm in R.
+	Tol float64
+
+	// MaxIter is the maximum number of iterations. The default is 25.
+	MaxIter int
+}
+
+// Logistic is a binary logistic regression fit of the model
+//  P(y = 1) = 1 / (1 + exp(-(b_0 + b_1 x_1 + ... + b_p x_p)))
+// to the rows of an n×p design matrix x and the response y.
+type Logistic struct {
+	// Intercept is whether the model includes the intercept b_0. If it
+	// does, the intercept is the first of the coefficients.
+	Intercept bool
+
+	// Coefficients holds the estimated coefficients and StdErrs their
+	// standard errors, from the inverse of the information matrix.
+	Coefficients []float64
+	StdErrs      []float64
+
+	// LogLikelihood is the maximized log-likelihood, and Iterations the
+	// number of iterations taken.
+	LogLikelihood float64
+	Iterations    int
+}
+
+// NewLogistic fits the logistic regression of the binary response y, whose
+// values must be 0 or 1, on the columns of x by maximum likelihood, using
+// Newton–Raphson iterations in the form of iteratively reweighted least
+// squares. Each iteration solves a weighted least squares problem by the QR
+// factorization of the weighted design matrix. If settings is nil, the
+// default settings are used.
+//
+// If a fitted probability becomes numerically 0 or 1, the classes are
+// separated and NewLogistic stops and returns ErrSeparation. If the fit does
+// not converge within the maximum number of iterations, NewLogistic returns
+// ErrNotConverged. In both cases the returned model holds the estimates of the
+// last iteration.
+//
+// NewLogistic panics if len(y) is not the number of rows of x, if a value of
+// y is not 0 or 1, or if the design matrix, including the intercept column,
+// is rank deficient.
+func NewLogistic(x mat64.Matrix, y []float64, intercept bool, settings *LogisticSettings) (*Logistic, error) {
+	r, c := x.Dims()
+	if len(y) != r {
+		panic(ErrLengthMismatch{Got: len(y), Want: r})
+	}
+	for _, v := range y {
+		if v != 0 && v != 1 {
+			panic("stat: non-binary response")
+		}
+	}
+	tol, maxIter := 1e-8, 25
+	if settings != nil {
+		if settings.Tol != 0 {
+			tol = settings.Tol
+		}
+		if settings.MaxIter != 0 {
+			maxIter = settings.MaxIter
+		}
+	}
+	off := 0
+	if intercept {
+		off = 1
+	}
+	q := c + off
+
+	l := &Logistic{
+		Intercept:    intercept,
+		Coefficients: make([]float64, q),
+		StdErrs:      make([]float64, q),
+	}
+	eta := make([]float64, r)
+	mu := make([]float64, r)
+	a := mat64.NewDense(r, q, nil)
+	z := make([]float64, r)
+
+	// Start from the fitted probabilities (y + 0.5) / 2, as glm in R does.
+	for i, v := range y {
+		mu[i] = (v + 0.5) / 2
+		eta[i] = math.Log(mu[i] / (1 - mu[i]))
+	}
+	dev := logisticDeviance(y, mu)
+	// weigh scales the rows of the design matrix and the working response
+	// by the square roots of the weights μ(1-μ), so that the least squares
+	// fit of the scaled data is the weighted fit.
+	weigh := func() {
+		for i := 0; i < r; i++ {
+			w := mu[i] * (1 - mu[i])
+			sw := math.Sqrt(w)
+			if intercept {
+				a.Set(i, 0, sw)
+			}
+			for j := 0; j < c; j++ {
+				a.Set(i, j+off, sw*x.At(i, j))
+			}
+			z[i] = sw * (eta[i] + (y[i]-mu[i])/w)
+		}
+	}
+	// stdErrs sets the standard errors from the inverse of the information
+	// matrix XᵀWX given the factorization of the weighted design matrix.
+	stdErrs := func(f *qr) {
+		cov := f.unscaledCov()
+		for i := range l.StdErrs {
+			l.StdErrs[i] = math.Sqrt(cov.At(i, i))
+		}
+	}
+	var err error
+	for {
+		weigh()
+		fit := fitLstsq(a, z)
+		copy(l.Coefficients, fit.coef)
+		stdErrs(fit.f)
+		l.Iterations++
+
+		l.linear(eta, x)
+		separated := false
+		for i, v := range eta {
+			mu[i] = 1 / (1 + math.Exp(-v))
+			if mu[i] < logisticEps || mu[i] > 1-logisticEps {
+				separated = true
+			}
+		}
+		old := dev
+		dev = logisticDeviance(y, mu)
+		if separated {
+			err = ErrSeparation
+			break
+		}
+		if math.Abs(dev-old)/(math.Abs(dev)+0.1) < tol {
+			break
+		}
+		if l.Iterations >= maxIter {
+			err = ErrNotConverged
+			break
+		}
+	}
+	if err != ErrSeparation {
+		// Evaluate the information at the estimates rather than at the
+		// previous iteration.
+		weigh()
+		stdErrs(newQR(a))
+	}
+	l.LogLikelihood = -dev / 2
+	return l, err
+}
+
+// logisticEps is the distance from 0 or 1 within which a fitted probability
+// is taken to be numerically 0 or 1, as in glm in R.
+const logisticEps = 10 * 2.220446049250313e-16
+
+// logisticDeviance returns the deviance of the binary response y with fitted
+// probabilities mu, -2 times the log-likelihood.
+func logisticDeviance(y, mu []float64) float64 {
+	var ll float64
+	for i, v := range y {
+		if v == 1 {
+			ll += math.Log(mu[i])
+		} else {
+			ll += math.Log1p(-mu[i])
+		}
+	}
+	return -2 * ll
+}
+
+// PredictProb returns the fitted probabilities P(y = 1) for the rows of x,
+// which must have the same number of columns as the design matrix of the
+// fit, and stores them in dst. If dst is nil, a new slice is allocated,
+// otherwise its length must equal the number of rows of x.
+func (l *Logistic) PredictProb(dst []float64, x mat64.Matrix) []float64 {
+	r, c := x.Dims()
+	off := 0
+	if l.Intercept {
+		off = 1
+	}
+	if c+off != len(l.Coefficients) {
+		panic(mat64.ErrShape)
+	}
+	if dst == nil {
+		dst = make([]float64, r)
+	} else if len(dst) != r {
+		panic(ErrLengthMismatch{Got: len(dst), Want: r})
+	}
+	l.linear(dst, x)
+	for i, v := range dst {
+		dst[i] = 1 / (1 + math.Exp(-v))
+	}
+	return dst
+}
+
+// linear stores in dst the values of the linear predictor for the rows of x.
+func (l *Logistic) linear(dst []float64, x mat64.Matrix) {
+	r, c := x.Dims()
+	var b0 float64
+	b := l.Coefficients
+	if l.Intercept {
+		b0, b = b[0], b[1:]
+	}
+	for i := 0; i < r; i++ {
+		v := b0
+		for j := 0; j < c; j++ {
+			v += b[j] * x.At(i, j)
+		}
+		dst[i] = v
+	}
+}
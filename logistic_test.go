@@ -0,0 +1,184 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// Weight, horsepower and transmission (1 for manual) of the cars of the
+// mtcars data set in R.
+var (
+	mtcarsWt = []float64{
+		2.620, 2.875, 2.320, 3.215, 3.440, 3.460, 3.570, 3.190, 3.150, 3.440, 3.440,
+		4.070, 3.730, 3.780, 5.250, 5.424, 5.345, 2.200, 1.615, 1.835, 2.465, 3.520,
+		3.435, 3.840, 3.845, 1.935, 2.140, 1.513, 3.170, 2.770, 3.570, 2.780,
+	}
+	mtcarsHp = []float64{
+		110, 110, 93, 110, 175, 105, 245, 62, 95, 123, 123,
+		180, 180, 180, 205, 215, 230, 66, 52, 65, 97, 150,
+		150, 245, 175, 66, 91, 113, 264, 175, 335, 109,
+	}
+	mtcarsAm = []float64{
+		1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 1, 1, 1, 0, 0,
+		0, 0, 0, 1, 1, 1, 1, 1, 1, 1,
+	}
+)
+
+func TestLogistic(t *testing.T) {
+	n := len(mtcarsAm)
+	wt := mat64.NewDense(n, 1, mtcarsWt)
+	wtHp := mat64.NewDense(n, 2, nil)
+	for i := 0; i < n; i++ {
+		wtHp.Set(i, 0, mtcarsWt[i])
+		wtHp.Set(i, 1, mtcarsHp[i])
+	}
+	// The maximum likelihood estimates computed by Newton's method on the
+	// normal equations, which agree with glm(am ~ wt, binomial) and
+	// glm(am ~ wt + hp, binomial) in R to the printed precision.
+	for i, test := range []struct {
+		x         mat64.Matrix
+		intercept bool
+		coef, se  []float64
+		ll        float64
+	}{
+		{
+			x:         wt,
+			intercept: true,
+			coef:      []float64{12.040369728657307, -4.023969962173197},
+			se:        []float64{4.510066238966753, 1.43652775262355},
+			ll:        -9.588042403722543,
+		},
+		{
+			x:         wtHp,
+			intercept: true,
+			coef:      []float64{18.866298717204153, -8.083475182444648, 0.03625559608221661},
+			se:        []float64{7.443558427982229, 3.0686752753908095, 0.01773415433194773},
+			ll:        -5.029555236133495,
+		},
+		{
+			x:    wt,
+			coef: []float64{-0.2388044987667313},
+			se:   []float64{0.11655503811384811},
+			ll:   -19.858283369080816,
+		},
+	} {
+		// The default tolerance stops the iterations before the estimates
+		// are fully converged, as it does in R.
+		for _, s := range []struct {
+			settings *LogisticSettings
+			tol      float64
+		}{
+			{nil, 1e-5},
+			{&LogisticSettings{Tol: 1e-14}, 1e-10},
+		} {
+			l, err := NewLogistic(test.x, mtcarsAm, test.intercept, s.settings)
+			if err != nil {
+				t.Errorf("case %d: unexpected error: %v", i, err)
+				continue
+			}
+			if !floats.EqualApprox(l.Coefficients, test.coef, s.tol) {
+				t.Errorf("case %d: coefficient mismatch. Want %v, got %v", i, test.coef, l.Coefficients)
+			}
+			if !floats.EqualApprox(l.StdErrs, test.se, s.tol) {
+				t.Errorf("case %d: standard error mismatch. Want %v, got %v", i, test.se, l.StdErrs)
+			}
+			if math.Abs(l.LogLikelihood-test.ll) > s.tol {
+				t.Errorf("case %d: log-likelihood mismatch. Want %v, got %v", i, test.ll, l.LogLikelihood)
+			}
+			if l.Iterations < 1 || l.Iterations > 25 {
+				t.Errorf("case %d: unexpected number of iterations %d", i, l.Iterations)
+			}
+
+			// The log-likelihood is that of the predicted probabilities.
+			p := l.PredictProb(nil, test.x)
+			var ll float64
+			for j, v := range mtcarsAm {
+				if v == 1 {
+					ll += math.Log(p[j])
+				} else {
+					ll += math.Log(1 - p[j])
+				}
+			}
+			if math.Abs(ll-l.LogLikelihood) > 1e-10 {
+				t.Errorf("case %d: log-likelihood does not match the predicted probabilities. Want %v, got %v", i, ll, l.LogLikelihood)
+			}
+		}
+	}
+
+	l, _ := NewLogistic(wt, mtcarsAm, true, nil)
+	newX := mat64.NewDense(2, 1, []float64{2, 4})
+	dst := make([]float64, 2)
+	l.PredictProb(dst, newX)
+	for i, v := range []float64{2, 4} {
+		want := 1 / (1 + math.Exp(-(l.Coefficients[0] + l.Coefficients[1]*v)))
+		if math.Abs(dst[i]-want) > 1e-15 {
+			t.Errorf("probability mismatch at %v. Want %v, got %v", v, want, dst[i])
+		}
+	}
+	if !Panics(func() { l.PredictProb(nil, mat64.NewDense(2, 2, nil)) }) {
+		t.Errorf("PredictProb did not panic with wrong number of columns")
+	}
+	if !Panics(func() { l.PredictProb(make([]float64, 3), newX) }) {
+		t.Errorf("PredictProb did not panic with wrong dst length")
+	}
+}
+
+func TestLogisticErrors(t *testing.T) {
+	for i, test := range []struct {
+		x, y     []float64
+		settings *LogisticSettings
+		err      error
+	}{
+		{
+			// Complete separation.
+			x:   []float64{1, 2, 3, 4, 5, 6},
+			y:   []float64{0, 0, 0, 1, 1, 1},
+			err: ErrSeparation,
+		},
+		{
+			// Quasi-complete separation, with the classes overlapping
+			// only at x = 3.
+			x:   []float64{1, 2, 3, 3, 4, 5},
+			y:   []float64{0, 0, 0, 1, 1, 1},
+			err: ErrSeparation,
+		},
+		{
+			x:        mtcarsWt,
+			y:        mtcarsAm,
+			settings: &LogisticSettings{MaxIter: 1},
+			err:      ErrNotConverged,
+		},
+	} {
+		l, err := NewLogistic(mat64.NewDense(len(test.x), 1, test.x), test.y, true, test.settings)
+		if err != test.err {
+			t.Errorf("case %d: unexpected error. Want %v, got %v", i, test.err, err)
+		}
+		if l == nil || len(l.Coefficients) != 2 {
+			t.Errorf("case %d: no estimates returned with the error", i)
+		}
+	}
+
+	x := mat64.NewDense(4, 1, []float64{1, 2, 3, 4})
+	for _, test := range []struct {
+		name string
+		f    func()
+	}{
+		{"non-binary response", func() { NewLogistic(x, []float64{0, 1, 2, 1}, true, nil) }},
+		{"length mismatch", func() { NewLogistic(x, []float64{0, 1, 1}, true, nil) }},
+		{"rank deficient", func() {
+			NewLogistic(mat64.NewDense(4, 2, []float64{1, 2, 2, 4, 3, 6, 4, 8}), []float64{0, 1, 0, 1}, false, nil)
+		}},
+	} {
+		if !Panics(test.f) {
+			t.Errorf("%s: NewLogistic did not panic", test.name)
+		}
+	}
+}
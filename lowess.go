@@ -0,0 +1,190 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+// Lowess returns the values at x of the locally weighted regression
+// smoother of Cleveland, "Robust locally weighted regression and smoothing
+// scatterplots", JASA, 1979, fitted to the data in x and y. The smoothed
+// value at each x_i is that of the weighted least squares line through the
+// ⌊frac·n⌋ nearest neighbours of x_i, with at least two neighbours, weighted
+// by the tricube function of their distance from x_i relative to the
+// distance of the farthest of them. The fit is then repeated iter times with
+// the weights multiplied by robustness weights, the bisquare function of the
+// residuals of the previous fit relative to six times their median absolute
+// value, which reduces the influence of outliers. A value of 3 for iter is
+// customary. The algorithm is that of lowess in R with delta = 0, so
+// observations with equal x share the smoothed value.
+//
+// x need not be sorted. The returned values are in the order of the
+// observations. Lowess panics if the lengths of x and y differ, if frac is
+// not positive or if iter is negative.
+func Lowess(x, y []float64, frac float64, iter int) []float64 {
+	checkLengths(x, y)
+	if !(frac > 0) {
+		panic("stat: non-positive smoother span")
+	}
+	if iter < 0 {
+		panic("stat: negative number of iterations")
+	}
+	n := len(x)
+	smooth := make([]float64, n)
+	if n < 2 {
+		copy(smooth, y)
+		return smooth
+	}
+	order := argsortFloats(x)
+	xs := make([]float64, n)
+	ys := make([]float64, n)
+	for k, i := range order {
+		xs[k] = x[i]
+		ys[k] = y[i]
+	}
+
+	ns := int(frac*float64(n) + 1e-7)
+	if ns > n {
+		ns = n
+	}
+	if ns < 2 {
+		ns = 2
+	}
+	// The scale of y, against which the residuals are compared.
+	var scale float64
+	for _, v := range ys {
+		scale += math.Abs(v)
+	}
+	scale /= float64(n)
+
+	fit := make([]float64, n)
+	res := make([]float64, n)
+	rw := make([]float64, n)
+	w := make([]float64, n)
+	for it := 0; ; it++ {
+		left, right := 0, ns-1
+		for i := 0; i < n; i++ {
+			// Move the window of ns neighbours to be nearest to xs[i].
+			for right < n-1 && xs[i]-xs[left] > xs[right+1]-xs[i] {
+				left++
+				right++
+			}
+			if i > 0 && xs[i] == xs[i-1] {
+				fit[i] = fit[i-1]
+				continue
+			}
+			var ok bool
+			fit[i], ok = lowessPoint(xs, ys, xs[i], left, right, w, rw, it > 0)
+			if !ok {
+				fit[i] = ys[i]
+			}
+		}
+		for i := range res {
+			res[i] = ys[i] - fit[i]
+		}
+		if it == iter {
+			break
+		}
+
+		// Compute the bisquare robustness weights from six times the
+		// median absolute residual.
+		for i, v := range res {
+			rw[i] = math.Abs(v)
+		}
+		sort.Float64s(rw)
+		cmad := 6 * rw[n/2]
+		if n%2 == 0 {
+			cmad = 3 * (rw[n/2] + rw[n/2-1])
+		}
+		if cmad < 1e-7*scale {
+			// The residuals are effectively zero.
+			break
+		}
+		c9, c1 := 0.999*cmad, 0.001*cmad
+		for i, v := range res {
+			r := math.Abs(v)
+			switch {
+			case r <= c1:
+				rw[i] = 1
+			case r <= c9:
+				u := r / cmad
+				rw[i] = (1 - u*u) * (1 - u*u)
+			default:
+				rw[i] = 0
+			}
+		}
+	}
+	for k, i := range order {
+		smooth[i] = fit[k]
+	}
+	return smooth
+}
+
+// lowessPoint returns the value at xi of the weighted least squares line
+// through the points of the sorted data xs and ys in the window from left
+// to right, extended by points tied with its ends, with tricube weights
+// stored in w, multiplied by the robustness weights rw if robust is true.
+// It returns false if all of the weights are zero.
+func lowessPoint(xs, ys []float64, xi float64, left, right int, w, rw []float64, robust bool) (float64, bool) {
+	n := len(xs)
+	span := xs[n-1] - xs[0]
+	h := math.Max(xi-xs[left], xs[right]-xi)
+	h9, h1 := 0.999*h, 0.001*h
+
+	var a float64
+	j := left
+	for ; j < n; j++ {
+		w[j] = 0
+		r := math.Abs(xs[j] - xi)
+		if r <= h9 {
+			if r <= h1 {
+				w[j] = 1
+			} else {
+				u := r / h
+				u = 1 - u*u*u
+				w[j] = u * u * u
+			}
+			if robust {
+				w[j] *= rw[j]
+			}
+			a += w[j]
+		} else if xs[j] > xi {
+			break
+		}
+	}
+	end := j
+	if a <= 0 {
+		return 0, false
+	}
+	for j := left; j < end; j++ {
+		w[j] /= a
+	}
+	if h > 0 {
+		// Use a weighted linear fit unless the spread of the points in the
+		// window is negligible.
+		var mean float64
+		for j := left; j < end; j++ {
+			mean += w[j] * xs[j]
+		}
+		var c float64
+		for j := left; j < end; j++ {
+			d := xs[j] - mean
+			c += w[j] * d * d
+		}
+		if math.Sqrt(c) > 0.001*span {
+			b := (xi - mean) / c
+			for j := left; j < end; j++ {
+				w[j] *= b*(xs[j]-mean) + 1
+			}
+		}
+	}
+	var v float64
+	for j := left; j < end; j++ {
+		v += w[j] * ys[j]
+	}
+	return v, true
+}
@@ -0,0 +1,105 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestLowess(t *testing.T) {
+	// The speed and stopping distance of the cars data set and the
+	// smoothed distances of lowess(cars) in R, whose default delta skips
+	// no distinct speeds.
+	speed := []float64{
+		4, 4, 7, 7, 8, 9, 10, 10, 10, 11, 11, 12, 12, 12, 12, 13, 13, 13, 13, 14, 14, 14, 14, 15, 15,
+		15, 16, 16, 17, 17, 17, 18, 18, 18, 18, 19, 19, 19, 20, 20, 20, 20, 20, 22, 23, 24, 24, 24, 24, 25,
+	}
+	dist := []float64{
+		2, 10, 4, 22, 16, 10, 18, 26, 34, 17, 28, 14, 20, 24, 28, 26, 34, 34, 46, 26, 36, 60, 80, 20, 26,
+		54, 32, 40, 32, 40, 50, 42, 56, 76, 84, 36, 46, 68, 32, 48, 52, 56, 64, 66, 54, 70, 92, 93, 120, 85,
+	}
+	// The smoothed values at the distinct speeds.
+	want := map[float64]float64{
+		4: 4.965459, 7: 13.124495, 8: 15.858633, 9: 18.579691, 10: 21.280313, 11: 24.129277,
+		12: 27.119549, 13: 30.027276, 14: 32.962506, 15: 36.757728, 16: 40.435075, 17: 43.463492,
+		18: 46.885479, 19: 50.793152, 20: 56.491224, 22: 67.585824, 23: 73.079695, 24: 78.643164,
+		25: 84.328698,
+	}
+	got := Lowess(speed, dist, 2.0/3, 3)
+	for i, v := range got {
+		if math.Abs(v-want[speed[i]]) > 1e-6 {
+			t.Errorf("cars: smoothed value mismatch at speed %v. Want %v, got %v", speed[i], want[speed[i]], v)
+		}
+	}
+
+	// The result does not depend on the order of the observations.
+	rnd := rand.New(rand.NewSource(1))
+	perm := rnd.Perm(len(speed))
+	ps := make([]float64, len(speed))
+	pd := make([]float64, len(dist))
+	for i, j := range perm {
+		ps[i] = speed[j]
+		pd[i] = dist[j]
+	}
+	for i, v := range Lowess(ps, pd, 2.0/3, 3) {
+		if math.Abs(v-got[perm[i]]) > 1e-12 {
+			t.Errorf("permuted: smoothed value mismatch at %d. Want %v, got %v", i, got[perm[i]], v)
+		}
+	}
+
+	// A line is reproduced for any span, with or without robustness
+	// iterations.
+	n := 40
+	x := make([]float64, n)
+	y := make([]float64, n)
+	for i := range x {
+		x[i] = rnd.Float64() * 10
+		y[i] = 2 - 0.5*x[i]
+	}
+	for _, frac := range []float64{0.1, 0.5, 1, 2} {
+		for _, iter := range []int{0, 3} {
+			if s := Lowess(x, y, frac, iter); !floats.EqualApprox(s, y, 1e-12) {
+				t.Errorf("line, frac %v, iter %d: not reproduced: got %v", frac, iter, s)
+			}
+		}
+	}
+
+	// The robustness iterations remove most of the effect of an outlier.
+	for i := range y {
+		y[i] = math.Sin(x[i]) + 0.1*rnd.NormFloat64()
+	}
+	clean := Lowess(x, y, 0.3, 3)
+	y[7] += 50
+	plain := Lowess(x, y, 0.3, 0)
+	robust := Lowess(x, y, 0.3, 3)
+	var maxPlain, maxRobust float64
+	for i := range x {
+		if i == 7 {
+			continue
+		}
+		maxPlain = math.Max(maxPlain, math.Abs(plain[i]-clean[i]))
+		maxRobust = math.Max(maxRobust, math.Abs(robust[i]-clean[i]))
+	}
+	if maxRobust > 0.1 || maxPlain < 1 {
+		t.Errorf("outlier: unexpected maximum errors %v without and %v with robustness iterations", maxPlain, maxRobust)
+	}
+
+	if s := Lowess([]float64{1}, []float64{3}, 0.5, 3); len(s) != 1 || s[0] != 3 {
+		t.Errorf("single observation: want [3], got %v", s)
+	}
+	if !Panics(func() { Lowess(x, y[1:], 0.5, 3) }) {
+		t.Errorf("Lowess did not panic with length mismatch")
+	}
+	if !Panics(func() { Lowess(x, y, 0, 3) }) {
+		t.Errorf("Lowess did not panic with zero span")
+	}
+	if !Panics(func() { Lowess(x, y, 0.5, -1) }) {
+		t.Errorf("Lowess did not panic with negative iterations")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// Moments accumulates the weighted mean, variance, skewness and kurtosis of
+// a stream of observations, without storing them. The central moments are
+// updated by the combination formulas of Chan, Golub and LeVeque, "Updating
+// formulae and a pairwise algorithm for computing sample variances", extended
+// to the third and fourth moments by Pébay, "Formulas for robust, one-pass
+// parallel computation of covariances and arbitrary-order statistical
+// moments", Sandia Report SAND2008-6212, 2008. For a single observation they
+// reduce to the weighted form of Welford's update, which avoids the
+// cancellation of the sums of powers. Accumulators of separate parts of the
+// data, for example computed concurrently, can be combined with Merge. The
+// zero value is an accumulator with no observations.
+type Moments struct {
+	// n is the number of observations, by which an accumulator emptied
+	// by Remove is recognized despite the rounding of the weights.
+	n          int
+	sumWeights float64
+	mean       float64
+	// m2, m3 and m4 are the weighted sums of the second, third and fourth
+	// powers of the deviations from the mean.
+	m2, m3, m4 float64
+}
+
+// Add adds the observation x with the given weight. weight must not be
+// negative.
+func (m *Moments) Add(x, weight float64) {
+	if weight < 0 {
+		panic("stat: negative weight")
+	}
+	if weight == 0 {
+		return
+	}
+	m.merge(Moments{n: 1, sumWeights: weight, mean: x})
+}
+
+// Merge adds the observations accumulated by other. The result equals up to
+// floating point rounding that of adding the observations one by one, in any
+// order. other is not modified.
+func (m *Moments) Merge(other *Moments) {
+	m.merge(*other)
+}
+
+// merge combines the moments of b into m.
+func (m *Moments) merge(b Moments) {
+	if b.n == 0 {
+		return
+	}
+	if m.n == 0 {
+		*m = b
+		return
+	}
+	wa, wb := m.sumWeights, b.sumWeights
+	w := wa + wb
+	d := b.mean - m.mean
+	dw := d / w
+	m.m4 += b.m4 + d*dw*dw*dw*wa*wb*(wa*wa-wa*wb+wb*wb) + 6*dw*dw*(wa*wa*b.m2+wb*wb*m.m2) + 4*dw*(wa*b.m3-wb*m.m3)
+	m.m3 += b.m3 + d*dw*dw*wa*wb*(wa-wb) + 3*dw*(wa*b.m2-wb*m.m2)
+	m.m2 += b.m2 + d*dw*wa*wb
+	m.mean += dw * wb
+	m.sumWeights = w
+	m.n += b.n
+}
+
+// Remove removes the observation x with the given weight, which must have
+// been added before, so that Moments can be used over a sliding window.
+// Removal reverses the update of Add up to floating point rounding, so the
+// rounding errors of a long series of additions and removals accumulate.
+func (m *Moments) Remove(x, weight float64) {
+	if weight < 0 {
+		panic("stat: negative weight")
+	}
+	if weight == 0 {
+		return
+	}
+	if m.n <= 1 {
+		*m = Moments{}
+		return
+	}
+	// The inverse of merge, solved for the moments of the remaining
+	// observations in order.
+	w, wb := m.sumWeights, weight
+	wa := w - wb
+	mean := m.mean - (x-m.mean)*wb/wa
+	d := x - mean
+	dw := d / w
+	m2 := m.m2 - d*dw*wa*wb
+	if m2 < 0 {
+		m2 = 0
+	}
+	m3 := m.m3 - d*dw*dw*wa*wb*(wa-wb) + 3*dw*wb*m2
+	m4 := m.m4 - d*dw*dw*dw*wa*wb*(wa*wa-wa*wb+wb*wb) - 6*dw*dw*wb*wb*m2 + 4*dw*wb*m3
+	if m4 < 0 {
+		m4 = 0
+	}
+	m.n--
+	m.sumWeights = wa
+	m.mean = mean
+	m.m2, m.m3, m.m4 = m2, m3, m4
+}
+
+// Count returns the sum of the weights of the observations, which is their
+// number if all of the weights are 1.
+func (m *Moments) Count() float64 {
+	return m.sumWeights
+}
+
+// Mean returns the weighted mean of the observations, as computed by Mean,
+// or NaN if there are none.
+func (m *Moments) Mean() float64 {
+	if m.sumWeights == 0 {
+		return math.NaN()
+	}
+	return m.mean
+}
+
+// Variance returns the weighted sample variance of the observations,
+//  \sum_i w_i (x_i - mean)^2 / (sum_i w_i - 1)
+// as computed by Variance.
+func (m *Moments) Variance() float64 {
+	return m.m2 / (m.sumWeights - 1)
+}
+
+// StdDev returns the weighted sample standard deviation of the observations.
+func (m *Moments) StdDev() float64 {
+	return math.Sqrt(m.Variance())
+}
+
+// Skew returns the weighted skewness of the observations, as computed by
+// Skew.
+func (m *Moments) Skew() float64 {
+	std := m.StdDev()
+	return m.m3 / (std * std * std) * skewCorrection(m.sumWeights)
+}
+
+// ExKurtosis returns the weighted excess kurtosis of the observations, as
+// computed by ExKurtosis.
+func (m *Moments) ExKurtosis() float64 {
+	v := m.Variance()
+	mul, offset := kurtosisCorrection(m.sumWeights)
+	return m.m4/(v*v)*mul - offset
+}
@@ -609,3 +609,30 @@ func BenchmarkMomentAboutHugeWeighted(b *testing.B) {
 	wts := RandomSlice(huge)
 	benchmarkMomentAbout(b, 5, s, 0, wts)
 }
+
+// compensated summation versions, for comparison with the above
+
+func BenchmarkMeanStableLarge(b *testing.B) {
+	s := RandomSlice(large)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MeanStable(s, nil)
+	}
+}
+
+func BenchmarkVarianceStableLarge(b *testing.B) {
+	s := RandomSlice(large)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VarianceStable(s, nil)
+	}
+}
+
+func BenchmarkCovarianceStableLarge(b *testing.B) {
+	s1 := RandomSlice(large)
+	s2 := RandomSlice(large)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CovarianceStable(s1, s2, nil)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestMoments(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{2, 10, 1000} {
+		x := make([]float64, n)
+		w := make([]float64, n)
+		for i := range x {
+			x[i] = 1e3 + 10*rnd.NormFloat64()
+			w[i] = rnd.Float64()
+		}
+		for _, weights := range [][]float64{nil, w} {
+			var m Moments
+			for i, v := range x {
+				wt := 1.0
+				if weights != nil {
+					wt = weights[i]
+				}
+				m.Add(v, wt)
+			}
+			mean, variance := MeanVariance(x, weights)
+			sumWeights := float64(n)
+			if weights != nil {
+				sumWeights = floats.Sum(weights)
+			}
+			if !floats.EqualWithinAbsOrRel(m.Count(), sumWeights, 1e-12, 1e-12) {
+				t.Errorf("n = %d: count mismatch: got %v, want %v", n, m.Count(), sumWeights)
+			}
+			if !floats.EqualWithinAbsOrRel(m.Mean(), mean, 1e-12, 1e-12) {
+				t.Errorf("n = %d: mean mismatch: got %v, want %v", n, m.Mean(), mean)
+			}
+			if !floats.EqualWithinAbsOrRel(m.Variance(), variance, 1e-12, 1e-12) {
+				t.Errorf("n = %d: variance mismatch: got %v, want %v", n, m.Variance(), variance)
+			}
+			if !floats.EqualWithinAbsOrRel(m.StdDev(), math.Sqrt(variance), 1e-12, 1e-12) {
+				t.Errorf("n = %d: standard deviation mismatch: got %v, want %v", n, m.StdDev(), math.Sqrt(variance))
+			}
+		}
+	}
+
+	var m Moments
+	if !math.IsNaN(m.Mean()) {
+		t.Errorf("mean of no observations: got %v, want NaN", m.Mean())
+	}
+	m.Add(3, 0)
+	if m.Count() != 0 {
+		t.Errorf("zero weight observation counted")
+	}
+	if !Panics(func() { m.Add(1, -1) }) {
+		t.Errorf("Add did not panic with negative weight")
+	}
+	if !Panics(func() { m.Remove(1, -1) }) {
+		t.Errorf("Remove did not panic with negative weight")
+	}
+}
+
+func TestMomentsRemove(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const n, window = 500, 20
+	x := make([]float64, n)
+	w := make([]float64, n)
+	for i := range x {
+		x[i] = 100 + rnd.NormFloat64()
+		w[i] = 0.5 + rnd.Float64()
+	}
+	var m Moments
+	for i, v := range x {
+		m.Add(v, w[i])
+		if i >= window {
+			m.Remove(x[i-window], w[i-window])
+		}
+		if i < window-1 {
+			continue
+		}
+		mean, variance := MeanVariance(x[i-window+1:i+1], w[i-window+1:i+1])
+		if !floats.EqualWithinAbsOrRel(m.Mean(), mean, 1e-12, 1e-12) {
+			t.Errorf("window ending at %d: mean mismatch: got %v, want %v", i, m.Mean(), mean)
+		}
+		if !floats.EqualWithinAbsOrRel(m.Variance(), variance, 1e-10, 1e-10) {
+			t.Errorf("window ending at %d: variance mismatch: got %v, want %v", i, m.Variance(), variance)
+		}
+		if skew := Skew(x[i-window+1:i+1], w[i-window+1:i+1]); !floats.EqualWithinAbsOrRel(m.Skew(), skew, 1e-8, 1e-8) {
+			t.Errorf("window ending at %d: skewness mismatch: got %v, want %v", i, m.Skew(), skew)
+		}
+		if kurt := ExKurtosis(x[i-window+1:i+1], w[i-window+1:i+1]); !floats.EqualWithinAbsOrRel(m.ExKurtosis(), kurt, 1e-8, 1e-8) {
+			t.Errorf("window ending at %d: excess kurtosis mismatch: got %v, want %v", i, m.ExKurtosis(), kurt)
+		}
+	}
+	for i := n - window; i < n; i++ {
+		m.Remove(x[i], w[i])
+	}
+	if m.Count() != 0 || !math.IsNaN(m.Mean()) {
+		t.Errorf("moments not empty after removing all observations: count %v", m.Count())
+	}
+}
+
+func TestMomentsMerge(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const n = 1000
+	x := make([]float64, n)
+	w := make([]float64, n)
+	for i := range x {
+		x[i] = 50 + math.Exp(rnd.NormFloat64())
+		w[i] = rnd.Float64()
+	}
+	// Uneven chunks, including an empty one.
+	bounds := []int{0, 1, 1, 7, 150, 151, 600, n}
+	for _, weights := range [][]float64{nil, w} {
+		parts := make([]Moments, len(bounds)-1)
+		for k := range parts {
+			for i := bounds[k]; i < bounds[k+1]; i++ {
+				wt := 1.0
+				if weights != nil {
+					wt = weights[i]
+				}
+				parts[k].Add(x[i], wt)
+			}
+		}
+		var forward, backward Moments
+		for k := range parts {
+			forward.Merge(&parts[k])
+			backward.Merge(&parts[len(parts)-1-k])
+		}
+		// Pairwise merging as a tree.
+		tree := append([]Moments(nil), parts...)
+		for len(tree) > 1 {
+			var next []Moments
+			for k := 0; k < len(tree); k += 2 {
+				m := tree[k]
+				if k+1 < len(tree) {
+					m.Merge(&tree[k+1])
+				}
+				next = append(next, m)
+			}
+			tree = next
+		}
+
+		mean, variance := MeanVariance(x, weights)
+		skew := Skew(x, weights)
+		kurt := ExKurtosis(x, weights)
+		for _, test := range []struct {
+			name string
+			m    Moments
+		}{
+			{"forward", forward},
+			{"backward", backward},
+			{"tree", tree[0]},
+		} {
+			m := test.m
+			for _, v := range []struct {
+				stat      string
+				got, want float64
+			}{
+				{"mean", m.Mean(), mean},
+				{"variance", m.Variance(), variance},
+				{"skewness", m.Skew(), skew},
+				{"excess kurtosis", m.ExKurtosis(), kurt},
+			} {
+				if !floats.EqualWithinAbsOrRel(v.got, v.want, 1e-10, 1e-10) {
+					t.Errorf("%s merge weighted=%t: %s mismatch: got %v, want %v", test.name, weights != nil, v.stat, v.got, v.want)
+				}
+			}
+			if m.n != n {
+				t.Errorf("%s merge: count mismatch: got %d, want %d", test.name, m.n, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// The functions in this file treat NaN values as missing observations and
+// skip them, together with their weights, rather than propagating them as
+// the functions they wrap do. If every observation is missing, or too few
+// remain for the statistic to be defined, they return NaN.
+
+// NaNMean returns the weighted mean of the values of x that are not NaN, as
+// computed by Mean. If weights is not nil, then len(x) must equal
+// len(weights).
+func NaNMean(x, weights []float64) float64 {
+	x, _, weights = dropNaN(x, nil, weights)
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	return Mean(x, weights)
+}
+
+// NaNVariance returns the weighted sample variance of the values of x that
+// are not NaN, as computed by Variance. If weights is not nil, then len(x)
+// must equal len(weights).
+func NaNVariance(x, weights []float64) float64 {
+	x, _, weights = dropNaN(x, nil, weights)
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	return Variance(x, weights)
+}
+
+// NaNStdDev returns the weighted sample standard deviation of the values of
+// x that are not NaN, as computed by StdDev. If weights is not nil, then
+// len(x) must equal len(weights).
+func NaNStdDev(x, weights []float64) float64 {
+	return math.Sqrt(NaNVariance(x, weights))
+}
+
+// NaNQuantile returns the quantile p of the values of x that are not NaN, as
+// computed by Quantile. The values that are not NaN must be sorted in
+// increasing order, and the NaN values may be anywhere in x, so that a slice
+// sorted by sort.Float64s, which places them first, may be passed directly.
+// NaNQuantile panics if p is not between 0 and 1.
+func NaNQuantile(p float64, c CumulantKind, x, weights []float64) float64 {
+	if !(p >= 0 && p <= 1) {
+		panic("stat: percentile out of bounds")
+	}
+	x, _, weights = dropNaN(x, nil, weights)
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	return Quantile(p, c, x, weights)
+}
+
+// NaNCorrelation returns the weighted correlation between x and y, as
+// computed by Correlation, over the pairwise-complete observations, those for
+// which neither x[i] nor y[i] is NaN, along with their number n. The
+// correlation is NaN if n is less than 2. The lengths of x and y must be
+// equal, and if weights is not nil, then len(x) must equal len(weights).
+func NaNCorrelation(x, y, weights []float64) (r float64, n int) {
+	checkLengths(x, y)
+	x, y, weights = dropNaN(x, y, weights)
+	if len(x) < 2 {
+		return math.NaN(), len(x)
+	}
+	return Correlation(x, y, weights), len(x)
+}
+
+// NaNMinMax returns the smallest and largest values of x that are not NaN.
+// If x is empty or all of its values are NaN, both are NaN.
+func NaNMinMax(x []float64) (min, max float64) {
+	min, max = math.NaN(), math.NaN()
+	for _, v := range x {
+		if math.IsNaN(v) {
+			continue
+		}
+		if !(v >= min) {
+			min = v
+		}
+		if !(v <= max) {
+			max = v
+		}
+	}
+	return min, max
+}
+
+// dropNaN returns x, y and weights without the elements at the indices at
+// which x or, if it is not nil, y is NaN. The slices are returned unchanged
+// if there are no such indices, and copied otherwise. The lengths of a non-nil
+// weights and x must be equal.
+func dropNaN(x, y, weights []float64) (xs, ys, ws []float64) {
+	checkWeightLength(x, weights)
+	missing := func(i int) bool {
+		return math.IsNaN(x[i]) || (y != nil && math.IsNaN(y[i]))
+	}
+	first := -1
+	for i := range x {
+		if missing(i) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return x, y, weights
+	}
+	xs = append(make([]float64, 0, len(x)), x[:first]...)
+	if y != nil {
+		ys = append(make([]float64, 0, len(x)), y[:first]...)
+	}
+	if weights != nil {
+		ws = append(make([]float64, 0, len(x)), weights[:first]...)
+	}
+	for i := first + 1; i < len(x); i++ {
+		if missing(i) {
+			continue
+		}
+		xs = append(xs, x[i])
+		if y != nil {
+			ys = append(ys, y[i])
+		}
+		if weights != nil {
+			ws = append(ws, weights[i])
+		}
+	}
+	return xs, ys, ws
+}
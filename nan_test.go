@@ -0,0 +1,108 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestNaNStatistics(t *testing.T) {
+	nan := math.NaN()
+	x := []float64{4, nan, 1, 7, nan, 2}
+	w := []float64{1, 5, 2, 1, 3, 0.5}
+	clean := []float64{4, 1, 7, 2}
+	cleanW := []float64{1, 2, 1, 0.5}
+	for _, test := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"mean", NaNMean(x, nil), Mean(clean, nil)},
+		{"weighted mean", NaNMean(x, w), Mean(clean, cleanW)},
+		{"variance", NaNVariance(x, nil), Variance(clean, nil)},
+		{"weighted variance", NaNVariance(x, w), Variance(clean, cleanW)},
+		{"standard deviation", NaNStdDev(x, nil), StdDev(clean, nil)},
+		{"weighted standard deviation", NaNStdDev(x, w), StdDev(clean, cleanW)},
+	} {
+		if test.got != test.want {
+			t.Errorf("%s mismatch. Want %v, got %v", test.name, test.want, test.got)
+		}
+	}
+	if x[0] != 4 || !math.IsNaN(x[1]) || !floats.Equal(x[2:4], []float64{1, 7}) {
+		t.Errorf("input modified: %v", x)
+	}
+
+	// NaN values sort first, and are skipped wherever they are.
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+	for _, p := range []float64{0, 0.3, 0.5, 0.9, 1} {
+		want := Quantile(p, Empirical, []float64{1, 2, 4, 7}, nil)
+		if got := NaNQuantile(p, Empirical, sorted, nil); got != want {
+			t.Errorf("quantile %v mismatch. Want %v, got %v", p, want, got)
+		}
+		if got := NaNQuantile(p, Empirical, []float64{1, nan, 2, 4, nan, 7}, nil); got != want {
+			t.Errorf("quantile %v with interleaved NaN mismatch. Want %v, got %v", p, want, got)
+		}
+	}
+	if got := NaNQuantile(0.5, Empirical, []float64{nan, 1, 2, 4}, []float64{9, 1, 1, 3}); got != 4 {
+		t.Errorf("weighted quantile mismatch. Want 4, got %v", got)
+	}
+
+	min, max := NaNMinMax(x)
+	if min != 1 || max != 7 {
+		t.Errorf("extremes mismatch. Want 1 and 7, got %v and %v", min, max)
+	}
+	if min, max := NaNMinMax([]float64{nan, -3, nan}); min != -3 || max != -3 {
+		t.Errorf("extremes of a single value mismatch. Got %v and %v", min, max)
+	}
+
+	// Pairs are dropped if either element is NaN.
+	y := []float64{1, 2, nan, 5, 6, 3}
+	r, n := NaNCorrelation(x, y, nil)
+	if want := Correlation([]float64{4, 7, 2}, []float64{1, 5, 3}, nil); n != 3 || r != want {
+		t.Errorf("correlation mismatch. Want %v over 3 pairs, got %v over %d", want, r, n)
+	}
+	r, n = NaNCorrelation(x, y, w)
+	if want := Correlation([]float64{4, 7, 2}, []float64{1, 5, 3}, []float64{1, 1, 0.5}); n != 3 || r != want {
+		t.Errorf("weighted correlation mismatch. Want %v over 3 pairs, got %v over %d", want, r, n)
+	}
+	complete := []float64{1, 2, 3}
+	if r, n := NaNCorrelation(complete, complete, nil); n != 3 || math.Abs(r-1) > 1e-15 {
+		t.Errorf("correlation without NaN: want 1 over 3 pairs, got %v over %d", r, n)
+	}
+
+	// All-NaN input gives NaN.
+	allNaN := []float64{nan, nan}
+	for _, v := range []float64{
+		NaNMean(allNaN, nil),
+		NaNVariance(allNaN, []float64{1, 2}),
+		NaNStdDev(nil, nil),
+		NaNQuantile(0.5, Empirical, allNaN, nil),
+	} {
+		if !math.IsNaN(v) {
+			t.Errorf("statistic of all-NaN input: want NaN, got %v", v)
+		}
+	}
+	if min, max := NaNMinMax(allNaN); !math.IsNaN(min) || !math.IsNaN(max) {
+		t.Errorf("extremes of all-NaN input: want NaN, got %v and %v", min, max)
+	}
+	if r, n := NaNCorrelation([]float64{1, nan}, []float64{nan, 2}, nil); n != 0 || !math.IsNaN(r) {
+		t.Errorf("correlation without complete pairs: want NaN over 0 pairs, got %v over %d", r, n)
+	}
+
+	for i, fn := range []func(){
+		func() { NaNMean(x, w[:2]) },
+		func() { NaNCorrelation(x, y[:2], nil) },
+		func() { NaNQuantile(1.5, Empirical, sorted, nil) },
+		func() { NaNQuantile(0.5, Empirical, []float64{2, nan, 1}, nil) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "math"
+
+// nelderMead minimizes f over the box [lo, hi] using the Nelder–Mead simplex
+// method, starting from x0. Points outside the box are projected onto it
+// before f is evaluated. The search stops when the function values at the
+// vertices of the simplex differ by less than tol or after maxIter
+// iterations, and the best vertex found and its value are returned.
+func nelderMead(f func([]float64) float64, x0, lo, hi []float64, tol float64, maxIter int) ([]float64, float64) {
+	const (
+		reflect  = 1
+		expand   = 2
+		contract = 0.5
+		shrink   = 0.5
+	)
+	dim := len(x0)
+	clamp := func(x []float64) []float64 {
+		for i := range x {
+			x[i] = math.Max(lo[i], math.Min(hi[i], x[i]))
+		}
+		return x
+	}
+	type vertex struct {
+		x []float64
+		f float64
+	}
+	simplex := make([]vertex, dim+1)
+	for i := range simplex {
+		x := make([]float64, dim)
+		copy(x, x0)
+		if i > 0 {
+			// Step a tenth of the range along each coordinate, away from the
+			// nearer bound.
+			j := i - 1
+			step := 0.1 * (hi[j] - lo[j])
+			if x[j]+step > hi[j] {
+				step = -step
+			}
+			x[j] += step
+		}
+		clamp(x)
+		simplex[i] = vertex{x, f(x)}
+	}
+	order := func() {
+		// Insertion sort; the simplex is small and nearly sorted.
+		for i := 1; i < len(simplex); i++ {
+			for j := i; j > 0 && simplex[j].f < simplex[j-1].f; j-- {
+				simplex[j], simplex[j-1] = simplex[j-1], simplex[j]
+			}
+		}
+	}
+
+	centroid := make([]float64, dim)
+	point := func(t float64) vertex {
+		// The point centroid + t*(centroid - worst).
+		x := make([]float64, dim)
+		w := simplex[dim].x
+		for i := range x {
+			x[i] = centroid[i] + t*(centroid[i]-w[i])
+		}
+		clamp(x)
+		return vertex{x, f(x)}
+	}
+	for iter := 0; iter < maxIter; iter++ {
+		order()
+		if math.Abs(simplex[dim].f-simplex[0].f) <= tol*(math.Abs(simplex[0].f)+tol) {
+			break
+		}
+		for i := range centroid {
+			centroid[i] = 0
+			for _, v := range simplex[:dim] {
+				centroid[i] += v.x[i]
+			}
+			centroid[i] /= float64(dim)
+		}
+		r := point(reflect)
+		switch {
+		case r.f < simplex[0].f:
+			if e := point(expand); e.f < r.f {
+				simplex[dim] = e
+			} else {
+				simplex[dim] = r
+			}
+		case r.f < simplex[dim-1].f:
+			simplex[dim] = r
+		default:
+			if c := point(-contract); c.f < simplex[dim].f {
+				simplex[dim] = c
+				continue
+			}
+			for i := 1; i <= dim; i++ {
+				for j := range simplex[i].x {
+					simplex[i].x[j] = simplex[0].x[j] + shrink*(simplex[i].x[j]-simplex[0].x[j])
+				}
+				simplex[i].f = f(simplex[i].x)
+			}
+		}
+	}
+	order()
+	return simplex[0].x, simplex[0].f
+}
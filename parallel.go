@@ -0,0 +1,134 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ParallelConfig configures the parallel computations of the package. The
+// work of a computation is always split into the same pieces, combined in the
+// same order, so the results do not depend on the configuration.
+type ParallelConfig struct {
+	// Workers is the largest number of goroutines used by a computation,
+	// including the calling goroutine. Values less than 2 disable
+	// parallelism.
+	Workers int
+
+	// MinWork is the number of data elements below which a computation
+	// runs in the calling goroutine alone.
+	MinWork int
+}
+
+// Parallel is the configuration of the parallel computations of the package.
+// It must not be changed while a computation is running. The goroutines used
+// are kept in a pool shared by all computations.
+var Parallel = ParallelConfig{Workers: 1, MinWork: 1 << 15}
+
+// pool is the shared pool of worker goroutines, which run the functions sent
+// on jobs.
+var pool struct {
+	sync.Mutex
+	jobs chan func()
+	n    int
+}
+
+// startWorkers ensures that the pool has at least n workers, and returns the
+// channel of jobs.
+func startWorkers(n int) chan func() {
+	pool.Lock()
+	defer pool.Unlock()
+	if pool.jobs == nil {
+		pool.jobs = make(chan func())
+	}
+	for ; pool.n < n; pool.n++ {
+		go func(jobs chan func()) {
+			for f := range jobs {
+				f()
+			}
+		}(pool.jobs)
+	}
+	return pool.jobs
+}
+
+// runParallel calls f(i) for each i in [0, n), and returns when all of the
+// calls have returned. If the number of data elements handled, work, is at
+// least Parallel.MinWork, the calls are shared between the calling goroutine
+// and up to Parallel.Workers-1 idle workers of the pool, so f must be safe to
+// call concurrently for different i. Calls from within f are allowed, since
+// the calling goroutine never waits for a busy worker.
+func runParallel(n, work int, f func(i int)) {
+	w := Parallel.Workers
+	if w > n {
+		w = n
+	}
+	if w < 2 || work < Parallel.MinWork {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	jobs := startWorkers(w - 1)
+	next := int64(-1)
+	run := func() {
+		for {
+			i := int(atomic.AddInt64(&next, 1))
+			if i >= n {
+				return
+			}
+			f(i)
+		}
+	}
+	var wg sync.WaitGroup
+	for k := 0; k < w-1; k++ {
+		wg.Add(1)
+		job := func() {
+			defer wg.Done()
+			run()
+		}
+		select {
+		case jobs <- job:
+		default:
+			// No idle worker; the calling goroutine does the work.
+			wg.Done()
+		}
+	}
+	run()
+	wg.Wait()
+}
+
+// reduceRound is the number of chunks whose partial results are held at once
+// by reduceChunks.
+const reduceRound = 16
+
+// reduceChunks splits the rows [0, n) into consecutive chunks of chunkRows
+// rows, each holding cols data elements per row, and calls work(slot, lo, hi)
+// for each chunk [lo, hi), possibly concurrently. The chunks are processed in
+// rounds of reduceRound, and after each round combine(slot) is called from
+// the calling goroutine for each chunk of the round in order. The slot, in
+// [0, reduceRound), identifies the storage for the partial result of the
+// chunk. Since the chunks and the order of combination depend only on n and
+// chunkRows, so do the results.
+func reduceChunks(n, chunkRows, cols int, work func(slot, lo, hi int), combine func(slot int)) {
+	chunks := (n + chunkRows - 1) / chunkRows
+	for first := 0; first < chunks; first += reduceRound {
+		m := chunks - first
+		if m > reduceRound {
+			m = reduceRound
+		}
+		runParallel(m, m*chunkRows*cols, func(slot int) {
+			lo := (first + slot) * chunkRows
+			hi := lo + chunkRows
+			if hi > n {
+				hi = n
+			}
+			work(slot, lo, hi)
+		})
+		for slot := 0; slot < m; slot++ {
+			combine(slot)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestRunParallel(t *testing.T) {
+	defer func(p ParallelConfig) { Parallel = p }(Parallel)
+	for _, w := range []int{1, 2, 7, 32} {
+		Parallel = ParallelConfig{Workers: w}
+		for _, n := range []int{0, 1, 5, 100} {
+			counts := make([]int32, n)
+			runParallel(n, 0, func(i int) {
+				atomic.AddInt32(&counts[i], 1)
+				// Nested calls must not deadlock.
+				runParallel(3, 0, func(int) {})
+			})
+			for i, c := range counts {
+				if c != 1 {
+					t.Errorf("workers=%d n=%d: index %d called %d times", w, n, i, c)
+				}
+			}
+		}
+	}
+}
+
+// parallelFixture returns the results of the parallel computations for x and
+// weights in a single slice.
+func parallelFixture(x *mat64.Dense, weights []float64) []float64 {
+	var res []float64
+	for _, alg := range []CovarianceAlgorithm{StreamingCovariance, BlockedCovariance} {
+		CovarianceMatrixAlgorithm = alg
+		res = append(res, CovarianceMatrix(nil, x, weights).RawMatrix().Data...)
+	}
+	CovarianceMatrixAlgorithm = AutoCovariance
+	res = append(res, ColumnMeans(nil, x, weights)...)
+	res = append(res, ColumnVariances(nil, x, weights)...)
+	min, max := ColumnMinMax(nil, nil, x)
+	res = append(res, min...)
+	res = append(res, max...)
+	res = append(res, ColumnQuantiles(nil, []float64{0.1, 0.5, 0.9}, x).RawMatrix().Data...)
+	return res
+}
+
+func TestParallelDeterministic(t *testing.T) {
+	defer func(p ParallelConfig, alg CovarianceAlgorithm) {
+		Parallel = p
+		CovarianceMatrixAlgorithm = alg
+	}(Parallel, CovarianceMatrixAlgorithm)
+
+	rnd := rand.New(rand.NewSource(1))
+	// Enough rows for several rounds of chunks of the column statistics,
+	// and enough columns for several blocks of columns.
+	for _, dims := range [][2]int{{reduceRound*columnChunkRows + 1000, 3}, {300, 2*covColumnBlock + 5}} {
+		r, c := dims[0], dims[1]
+		x := mat64.NewDense(r, c, nil)
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				x.Set(i, j, 1e3*float64(j)+rnd.NormFloat64())
+			}
+		}
+		wts := make([]float64, r)
+		for i := range wts {
+			wts[i] = rnd.Float64()
+		}
+		for _, w := range [][]float64{nil, wts} {
+			Parallel = ParallelConfig{Workers: 1}
+			want := parallelFixture(x, w)
+			for _, workers := range []int{2, 7, 32} {
+				Parallel = ParallelConfig{Workers: workers}
+				got := parallelFixture(x, w)
+				for i := range got {
+					if got[i] != want[i] {
+						t.Errorf("%d×%d workers=%d: result %d differs: got %v, want %v", r, c, workers, i, got[i], want[i])
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkRunParallelPool(b *testing.B) {
+	defer func(p ParallelConfig) { Parallel = p }(Parallel)
+	Parallel = ParallelConfig{Workers: 4}
+	var sink [4]int64
+	for i := 0; i < b.N; i++ {
+		runParallel(4, 0, func(k int) { sink[k]++ })
+	}
+}
+
+func BenchmarkRunParallelSpawn(b *testing.B) {
+	var sink [4]int64
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		for k := 0; k < 4; k++ {
+			wg.Add(1)
+			go func(k int) {
+				sink[k]++
+				wg.Done()
+			}(k)
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// qr is the Householder QR factorization of an m×n matrix A with m ≥ n,
+// used for solving linear least squares problems without forming AᵀA.
+//
+// The factorization is stored in the compact form of the JAMA library. The
+// elements of a on and below the diagonal hold the Householder vectors and
+// those above the diagonal hold the strictly upper triangular part of R,
+// whose diagonal is kept in rdiag.
+type qr struct {
+	m, n  int
+	a     []float64
+	rdiag []float64
+}
+
+// newQR computes the QR factorization of a. It panics if a has fewer rows
+// than columns.
+func newQR(a mat64.Matrix) *qr {
+	m, n := a.Dims()
+	if m < n {
+		panic("stat: fewer observations than parameters")
+	}
+	f := &qr{
+		m:     m,
+		n:     n,
+		a:     make([]float64, m*n),
+		rdiag: make([]float64, n),
+	}
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			f.a[i*n+j] = a.At(i, j)
+		}
+	}
+	for k := 0; k < n; k++ {
+		// Compute the 2-norm of the k-th column below the diagonal without
+		// under- or overflow.
+		var nrm float64
+		for i := k; i < m; i++ {
+			nrm = math.Hypot(nrm, f.a[i*n+k])
+		}
+		if nrm != 0 {
+			if f.a[k*n+k] < 0 {
+				nrm = -nrm
+			}
+			for i := k; i < m; i++ {
+				f.a[i*n+k] /= nrm
+			}
+			f.a[k*n+k]++
+			// Apply the transformation to the remaining columns.
+			for j := k + 1; j < n; j++ {
+				var s float64
+				for i := k; i < m; i++ {
+					s += f.a[i*n+k] * f.a[i*n+j]
+				}
+				s = -s / f.a[k*n+k]
+				for i := k; i < m; i++ {
+					f.a[i*n+j] += s * f.a[i*n+k]
+				}
+			}
+		}
+		f.rdiag[k] = -nrm
+	}
+	return f
+}
+
+// fullRank returns whether the factorized matrix has full column rank to
+// within a tolerance relative to the largest diagonal element of R.
+func (f *qr) fullRank() bool {
+	var max float64
+	for _, v := range f.rdiag {
+		max = math.Max(max, math.Abs(v))
+	}
+	tol := float64(f.m) * max * 1e-13
+	for _, v := range f.rdiag {
+		if math.Abs(v) <= tol {
+			return false
+		}
+	}
+	return true
+}
+
+// qtMul overwrites b, which must have length m, with Qᵀb.
+func (f *qr) qtMul(b []float64) {
+	m, n := f.m, f.n
+	for k := 0; k < n; k++ {
+		if f.a[k*n+k] == 0 {
+			continue
+		}
+		var s float64
+		for i := k; i < m; i++ {
+			s += f.a[i*n+k] * b[i]
+		}
+		s = -s / f.a[k*n+k]
+		for i := k; i < m; i++ {
+			b[i] += s * f.a[i*n+k]
+		}
+	}
+}
+
+// rSolve solves R x = b in place for the first n elements of b.
+func (f *qr) rSolve(b []float64) {
+	n := f.n
+	for k := n - 1; k >= 0; k-- {
+		b[k] /= f.rdiag[k]
+		for i := 0; i < k; i++ {
+			b[i] -= b[k] * f.a[i*n+k]
+		}
+	}
+}
+
+// rtSolve solves Rᵀ x = b in place for the first n elements of b.
+func (f *qr) rtSolve(b []float64) {
+	n := f.n
+	for k := 0; k < n; k++ {
+		for i := 0; i < k; i++ {
+			b[k] -= f.a[i*n+k] * b[i]
+		}
+		b[k] /= f.rdiag[k]
+	}
+}
+
+// solve returns the least squares solution x minimizing ||A x - b||₂ and
+// stores it in dst, allocating a new slice if dst is nil. b is not
+// modified. solve panics if A is rank deficient.
+func (f *qr) solve(dst, b []float64) []float64 {
+	if len(b) != f.m {
+		panic(ErrLengthMismatch{Got: len(b), Want: f.m})
+	}
+	if !f.fullRank() {
+		panic("stat: rank deficient design matrix")
+	}
+	tmp := make([]float64, f.m)
+	copy(tmp, b)
+	f.qtMul(tmp)
+	f.rSolve(tmp)
+	if dst == nil {
+		dst = make([]float64, f.n)
+	}
+	copy(dst, tmp[:f.n])
+	return dst
+}
+
+// unscaledCov returns (AᵀA)⁻¹ = R⁻¹R⁻ᵀ, the unscaled covariance of the
+// least squares coefficients.
+func (f *qr) unscaledCov() *mat64.Dense {
+	n := f.n
+	// Compute R⁻¹ column by column.
+	rinv := make([]float64, n*n)
+	col := make([]float64, n)
+	for j := 0; j < n; j++ {
+		for i := range col {
+			col[i] = 0
+		}
+		col[j] = 1
+		f.rSolve(col)
+		for i := 0; i < n; i++ {
+			rinv[i*n+j] = col[i]
+		}
+	}
+	cov := mat64.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			var s float64
+			for k := j; k < n; k++ {
+				s += rinv[i*n+k] * rinv[j*n+k]
+			}
+			cov.Set(i, j, s)
+			cov.Set(j, i, s)
+		}
+	}
+	return cov
+}
+
+// lstsq is an ordinary least squares fit of y on the columns of a design
+// matrix.
+type lstsq struct {
+	n, p int
+	coef []float64
+	ssr  float64
+	f    *qr
+}
+
+// fitLstsq computes the least squares fit of y on the columns of x. It
+// panics if the lengths do not match or if x is rank deficient.
+func fitLstsq(x mat64.Matrix, y []float64) *lstsq {
+	n, p := x.Dims()
+	if len(y) != n {
+		panic(ErrLengthMismatch{Got: len(y), Want: n})
+	}
+	f := newQR(x)
+	l := &lstsq{
+		n:    n,
+		p:    p,
+		coef: f.solve(nil, y),
+		f:    f,
+	}
+	// The residual sum of squares is the squared norm of the last n-p
+	// elements of Qᵀy.
+	qty := make([]float64, n)
+	copy(qty, y)
+	f.qtMul(qty)
+	for _, v := range qty[p:] {
+		l.ssr += v * v
+	}
+	return l
+}
+
+// sigma2 returns the unbiased estimate of the error variance.
+func (l *lstsq) sigma2() float64 {
+	return l.ssr / float64(l.n-l.p)
+}
+
+// stdErr returns the standard errors of the coefficients.
+func (l *lstsq) stdErr() []float64 {
+	cov := l.f.unscaledCov()
+	s2 := l.sigma2()
+	se := make([]float64, l.p)
+	for i := range se {
+		se[i] = math.Sqrt(s2 * cov.At(i, i))
+	}
+	return se
+}
+
+// aic returns the Akaike information criterion of the fit under Gaussian
+// errors,
+//  n log(2π ssr/n) + n + 2p
+func (l *lstsq) aic() float64 {
+	n := float64(l.n)
+	return n*math.Log(2*math.Pi*l.ssr/n) + n + 2*float64(l.p)
+}
+
+// bic returns the Bayesian information criterion of the fit under Gaussian
+// errors,
+//  n log(2π ssr/n) + n + p log(n)
+func (l *lstsq) bic() float64 {
+	n := float64(l.n)
+	return n*math.Log(2*math.Pi*l.ssr/n) + n + float64(l.p)*math.Log(n)
+}
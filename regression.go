@@ -0,0 +1,554 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// OLS is a weighted least squares fit of the linear model
+//  y = b_0 + b_1 x_1 + ... + b_p x_p + ε
+// to the rows of an n×p design matrix x and the response y. The fit is
+// computed from the QR factorization of the weighted design matrix rather
+// than from the normal equations, so it is accurate for ill-conditioned
+// designs.
+type OLS struct {
+	// Intercept is whether the model includes the intercept b_0. If it
+	// does, the intercept is the first of the coefficients in Summary.
+	Intercept bool
+
+	// Summary holds the coefficients and their standard errors, t
+	// statistics and p-values, together with the goodness of fit
+	// statistics. For a weighted fit, R² and F are those of the weighted
+	// sums of squares, as reported by lm in R.
+	Summary RegressionSummary
+
+	// Fitted and Residuals hold the fitted values and the residuals
+	// y_i - ŷ_i of the observations.
+	Fitted    []float64
+	Residuals []float64
+
+	// design is the design matrix, including the intercept column, with
+	// its rows scaled by the square roots of the weights, sw, and f is its
+	// QR factorization.
+	design *mat64.Dense
+	sw     []float64
+	f      *qr
+}
+
+// NewOLS fits the linear model of y on the columns of x with the given
+// weights, minimizing
+//  \sum_i w_i (y_i - b_0 - \sum_j b_j x_{ij})²
+// If intercept is false, b_0 is forced to be zero. Observations with zero
+// weight do not count towards the number of observations or the degrees of
+// freedom, but their fitted values and residuals are computed.
+//
+// NewOLS panics if len(y) is not the number of rows of x, if weights is
+// non-nil and has a different length or a negative weight, or if the design
+// matrix, including the intercept column, is rank deficient. If weights is
+// nil then all of the weights are 1.
+func NewOLS(x mat64.Matrix, y, weights []float64, intercept bool) *OLS {
+	r, c := x.Dims()
+	if len(y) != r {
+		panic(ErrLengthMismatch{Got: len(y), Want: r})
+	}
+	if weights != nil {
+		if err := ValidateWeights(y, weights); err != nil {
+			panic(err)
+		}
+	}
+	off := 0
+	if intercept {
+		off = 1
+	}
+	q := c + off
+
+	// Scale the rows by the square roots of the weights, so that the least
+	// squares fit of the scaled data is the weighted fit of the data.
+	a := mat64.NewDense(r, q, nil)
+	yw := make([]float64, r)
+	sw := make([]float64, r)
+	n := 0
+	for i := 0; i < r; i++ {
+		sw[i] = 1
+		if weights != nil {
+			sw[i] = math.Sqrt(weights[i])
+		}
+		if sw[i] != 0 {
+			n++
+		}
+		if intercept {
+			a.Set(i, 0, sw[i])
+		}
+		for j := 0; j < c; j++ {
+			a.Set(i, j+off, sw[i]*x.At(i, j))
+		}
+		yw[i] = sw[i] * y[i]
+	}
+	l := fitLstsq(a, yw)
+
+	o := &OLS{
+		Intercept: intercept,
+		Fitted:    make([]float64, r),
+		Residuals: make([]float64, r),
+		design:    a,
+		sw:        sw,
+		f:         l.f,
+	}
+	o.predict(o.Fitted, x, l.coef)
+	for i, v := range y {
+		o.Residuals[i] = v - o.Fitted[i]
+	}
+
+	// The model sum of squares is taken about the weighted mean of the
+	// fitted values if there is an intercept and about zero otherwise.
+	var mean, sumW float64
+	if intercept {
+		for i, v := range o.Fitted {
+			w := 1.0
+			if weights != nil {
+				w = weights[i]
+			}
+			mean += w * v
+			sumW += w
+		}
+		mean /= sumW
+	}
+	var mss float64
+	for i, v := range o.Fitted {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		d := v - mean
+		mss += w * d * d
+	}
+	rss := l.ssr
+	df := float64(n - q)
+	s2 := rss / df
+
+	cov := l.f.unscaledCov()
+	sym := mat64.NewSymDense(q, nil)
+	se := make([]float64, q)
+	ts := make([]float64, q)
+	ps := make([]float64, q)
+	for i := 0; i < q; i++ {
+		for j := i; j < q; j++ {
+			sym.SetSym(i, j, s2*cov.At(i, j))
+		}
+		se[i] = math.Sqrt(sym.At(i, i))
+		ts[i] = l.coef[i] / se[i]
+		ps[i] = tTwoSided(ts[i], df)
+	}
+
+	r2 := mss / (mss + rss)
+	dfModel := float64(c)
+	f, fp := math.NaN(), math.NaN()
+	if dfModel > 0 {
+		f = mss / dfModel / s2
+		fp = fSurvival(f, dfModel, df)
+	}
+	o.Summary = RegressionSummary{
+		N:              n,
+		DF:             df,
+		Coefficients:   l.coef,
+		StdErrs:        se,
+		TStats:         ts,
+		PValues:        ps,
+		Covariance:     sym,
+		R2:             r2,
+		AdjR2:          1 - (1-r2)*float64(n-off)/df,
+		ResidualStdErr: math.Sqrt(s2),
+		F:              f,
+		FP:             fp,
+	}
+	return o
+}
+
+// Predict returns the values predicted by the fitted model for the rows of
+// x, which must have the same number of columns as the design matrix of the
+// fit, and stores them in dst. If dst is nil, a new slice is allocated,
+// otherwise its length must equal the number of rows of x.
+func (o *OLS) Predict(dst []float64, x mat64.Matrix) []float64 {
+	r, c := x.Dims()
+	off := 0
+	if o.Intercept {
+		off = 1
+	}
+	if c+off != len(o.Summary.Coefficients) {
+		panic(mat64.ErrShape)
+	}
+	if dst == nil {
+		dst = make([]float64, r)
+	} else if len(dst) != r {
+		panic(ErrLengthMismatch{Got: len(dst), Want: r})
+	}
+	o.predict(dst, x, o.Summary.Coefficients)
+	return dst
+}
+
+// predict stores in dst the values of the model with coefficients coef for
+// the rows of x.
+func (o *OLS) predict(dst []float64, x mat64.Matrix, coef []float64) {
+	r, c := x.Dims()
+	var b0 float64
+	b := coef
+	if o.Intercept {
+		b0, b = coef[0], coef[1:]
+	}
+	for i := 0; i < r; i++ {
+		v := b0
+		for j := 0; j < c; j++ {
+			v += b[j] * x.At(i, j)
+		}
+		dst[i] = v
+	}
+}
+
+// Influence holds the influence diagnostics of the observations of a linear
+// regression fit. For a weighted fit, the diagnostics are those of the
+// weighted observations, as computed by influence.measures in R, and they
+// are NaN for observations with zero weight.
+type Influence struct {
+	// Leverage holds the diagonal elements h_i of the hat matrix.
+	Leverage []float64
+
+	// Standardized holds the internally studentized residuals
+	//  r_i = e_i / (s √(1 - h_i))
+	// and Studentized the externally studentized residuals
+	//  t_i = e_i / (s_(i) √(1 - h_i))
+	// where e_i is the residual, s is the residual standard error and
+	// s_(i) that of the fit without observation i.
+	Standardized []float64
+	Studentized  []float64
+
+	// CooksDistance holds Cook's distances
+	//  D_i = r_i² h_i / (p (1 - h_i))
+	// where p is the number of coefficients, and DFFITS the scaled changes
+	// in the fitted values on deleting each observation,
+	//  DFFITS_i = t_i √(h_i / (1 - h_i))
+	CooksDistance []float64
+	DFFITS        []float64
+}
+
+// Influential returns the indices of the observations whose Cook's distance
+// exceeds 4/n, a common threshold for influential observations, where n is
+// the number of observations with a non-NaN distance.
+func (inf Influence) Influential() []int {
+	var n int
+	for _, d := range inf.CooksDistance {
+		if !math.IsNaN(d) {
+			n++
+		}
+	}
+	var idx []int
+	for i, d := range inf.CooksDistance {
+		if d > 4/float64(n) {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Influence returns the influence diagnostics of the observations of the
+// fit. The leverages are computed from the triangular factor R of the QR
+// factorization of the design matrix X as
+//  h_i = ||R⁻ᵀ x_i||²
+// where x_i is the ith row of X, which takes O(n p²) time and O(n) memory
+// beyond the fit rather than the O(n²) memory of the hat matrix.
+func (o *OLS) Influence() Influence {
+	r, q := o.design.Dims()
+	inf := Influence{
+		Leverage:      make([]float64, r),
+		Standardized:  make([]float64, r),
+		Studentized:   make([]float64, r),
+		CooksDistance: make([]float64, r),
+		DFFITS:        make([]float64, r),
+	}
+	p := float64(q)
+	df := o.Summary.DF
+	s2 := o.Summary.ResidualStdErr * o.Summary.ResidualStdErr
+	row := make([]float64, q)
+	for i := 0; i < r; i++ {
+		if o.sw[i] == 0 {
+			nan := math.NaN()
+			inf.Leverage[i] = nan
+			inf.Standardized[i] = nan
+			inf.Studentized[i] = nan
+			inf.CooksDistance[i] = nan
+			inf.DFFITS[i] = nan
+			continue
+		}
+		for j := range row {
+			row[j] = o.design.At(i, j)
+		}
+		o.f.rtSolve(row)
+		var h float64
+		for _, v := range row {
+			h += v * v
+		}
+		e := o.sw[i] * o.Residuals[i]
+		rs := e / math.Sqrt(s2*(1-h))
+		// The residual variance without observation i.
+		s2i := (df*s2 - e*e/(1-h)) / (df - 1)
+		ts := e / math.Sqrt(s2i*(1-h))
+		inf.Leverage[i] = h
+		inf.Standardized[i] = rs
+		inf.Studentized[i] = ts
+		inf.CooksDistance[i] = rs * rs * h / (p * (1 - h))
+		inf.DFFITS[i] = ts * math.Sqrt(h/(1-h))
+	}
+	return inf
+}
+
+// PolynomialRegression fits the polynomial
+//  y = c_0 + c_1 x + ... + c_d x^d
+// of the given degree d to the data in x and y with the given weights by
+// least squares, as NewOLS does for the design matrix with columns x^1, ...,
+// x^d. It returns the coefficients lowest order first, the coefficient of
+// determination and the residuals y_i - ŷ_i. The coefficients can be
+// evaluated with Eval.
+//
+// The powers of x are badly conditioned for high degrees or for values of x
+// far from zero, so centering and scaling x first gives more accurate fits.
+//
+// The lengths of x and y must be equal. If weights is nil then all of the
+// weights are 1. If weights is not nil, then len(x) must equal len(weights).
+// PolynomialRegression panics if degree is not positive or is at least the
+// number of observations, and if the design matrix is rank deficient, which
+// is the case if x has at most degree distinct values.
+func PolynomialRegression(x, y, weights []float64, degree int) (coeffs []float64, r2 float64, residuals []float64) {
+	checkLengths(x, y)
+	checkWeightLength(x, weights)
+	if degree < 1 {
+		panic("stat: non-positive polynomial degree")
+	}
+	if degree >= len(x) {
+		panic("stat: polynomial degree too high")
+	}
+	v := mat64.NewDense(len(x), degree, nil)
+	for i, xi := range x {
+		p := 1.0
+		for j := 0; j < degree; j++ {
+			p *= xi
+			v.Set(i, j, p)
+		}
+	}
+	o := NewOLS(v, y, weights, true)
+	return o.Summary.Coefficients, o.Summary.R2, o.Residuals
+}
+
+// Eval returns the value at x of the polynomial with the given coefficients,
+// lowest order first, as returned by PolynomialRegression,
+//  c_0 + c_1 x + ... + c_d x^d
+// computed by Horner's method. Eval returns 0 if coeffs is empty.
+func Eval(coeffs []float64, x float64) float64 {
+	var v float64
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		v = v*x + coeffs[i]
+	}
+	return v
+}
+
+// Ridge returns the ridge regression coefficients of y on the columns of x,
+// minimizing the penalized sum of squares
+//  \sum_i (y_i - b_0 - \sum_j b_j x_{ij})² + λ \sum_j b_j²
+// with the intercept b_0, if intercept is true, excluded from the penalty.
+// The coefficients are returned with the intercept first. They are computed
+// from the QR factorization of the design matrix augmented with the rows of
+// √λ I, rather than by solving (XᵀX + λI)β = Xᵀy, and the intercept is
+// handled by centering the columns of x and y.
+//
+// If standardize is true, the penalty applies to the coefficients of the
+// columns of x scaled to unit root mean square, about their means if
+// intercept is true and about zero otherwise, and the coefficients are
+// transformed back to the original scale. Otherwise the penalty depends on
+// the scales of the columns.
+//
+// Ridge panics if len(y) is not the number of rows of x, if lambda is
+// negative, or if lambda is zero and the design matrix is rank deficient.
+func Ridge(x mat64.Matrix, y []float64, lambda float64, intercept, standardize bool) []float64 {
+	coeffs, _, _ := ridgeFit(x, y, lambda, intercept, standardize)
+	return coeffs
+}
+
+// RidgeGCV computes the generalized cross-validation score
+//  GCV(λ) = n RSS(λ) / (n - tr H(λ))²
+// of the ridge regression of y on the columns of x, as computed by Ridge,
+// for each of the penalties in lambdas, where RSS is the residual sum of
+// squares and H the hat matrix of the fit. It returns the scores and the
+// penalty with the smallest score, the first of them if there are ties.
+// The trace of H is the effective number of parameters,
+//  1 + \sum_j d_j² / (d_j² + λ)
+// with d_j the singular values of the design matrix as centered and scaled
+// by Ridge, or the same without the 1 if intercept is false.
+//
+// RidgeGCV panics if lambdas is empty and under the same conditions as
+// Ridge.
+func RidgeGCV(x mat64.Matrix, y, lambdas []float64, intercept, standardize bool) (best float64, gcv []float64) {
+	if len(lambdas) == 0 {
+		panic("stat: no ridge penalties")
+	}
+	n := float64(len(y))
+	gcv = make([]float64, len(lambdas))
+	for i, lambda := range lambdas {
+		_, rss, df := ridgeFit(x, y, lambda, intercept, standardize)
+		d := n - df
+		gcv[i] = n * rss / (d * d)
+	}
+	return lambdas[floats.MinIdx(gcv)], gcv
+}
+
+// ridgeFit returns the ridge regression coefficients as described for
+// Ridge, together with the residual sum of squares of the fit and the trace
+// of its hat matrix.
+func ridgeFit(x mat64.Matrix, y []float64, lambda float64, intercept, standardize bool) (coeffs []float64, rss, df float64) {
+	r, c := x.Dims()
+	if len(y) != r {
+		panic(ErrLengthMismatch{Got: len(y), Want: r})
+	}
+	if !(lambda >= 0) {
+		panic("stat: negative ridge penalty")
+	}
+
+	// Augment the design matrix with the rows of √λ I and the response with
+	// zeros, so that the least squares fit is the ridge fit. The columns of
+	// x and y are centered if there is an intercept.
+	a := mat64.NewDense(r+c, c, nil)
+	top := a.View(0, 0, r, c).(*mat64.Dense)
+	b := make([]float64, r+c)
+	mean := make([]float64, c)
+	var ymean float64
+	if intercept {
+		mean = CenterColumns(top, x, nil)
+		ymean = Mean(y, nil)
+	} else {
+		top.Copy(x)
+	}
+	for i := 0; i < r; i++ {
+		b[i] = y[i] - ymean
+	}
+
+	// The columns are scaled to unit root mean square, with divisor n as in
+	// glmnet, rather than to the unit sample standard deviation of
+	// CenterScaleColumns, and about zero when there is no intercept, so
+	// the scaling is done here on the centered columns.
+	scale := make([]float64, c)
+	for j := range scale {
+		scale[j] = 1
+	}
+	if standardize {
+		inv := make([]float64, c)
+		for j := range scale {
+			var ss float64
+			for i := 0; i < r; i++ {
+				d := top.At(i, j)
+				ss += d * d
+			}
+			// A constant column gets a zero coefficient for positive
+			// lambda whatever its scale.
+			if ss > 0 {
+				scale[j] = math.Sqrt(ss / float64(r))
+			}
+			inv[j] = 1 / scale[j]
+		}
+		ScaleColumns(top, top, inv)
+	}
+	sl := math.Sqrt(lambda)
+	for j := 0; j < c; j++ {
+		a.Set(r+j, j, sl)
+	}
+	l := fitLstsq(a, b)
+
+	off := 0
+	if intercept {
+		off = 1
+	}
+	coeffs = make([]float64, c+off)
+	b0 := ymean
+	for j, v := range l.coef {
+		coeffs[j+off] = v / scale[j]
+		b0 -= mean[j] * coeffs[j+off]
+	}
+	if intercept {
+		coeffs[0] = b0
+	}
+	for i := 0; i < r; i++ {
+		res := y[i] - b0
+		for j := 0; j < c; j++ {
+			res -= coeffs[j+off] * x.At(i, j)
+		}
+		rss += res * res
+	}
+
+	// With C = (XᵀX + λI)⁻¹, the trace of X C Xᵀ is that of XᵀX C = I - λC.
+	cov := l.f.unscaledCov()
+	df = float64(c + off)
+	for j := 0; j < c; j++ {
+		df -= lambda * cov.At(j, j)
+	}
+	return coeffs, rss, df
+}
+
+// Isotonic returns the isotonic regression of y, the nondecreasing sequence,
+// or nonincreasing if decreasing is true, closest to y in weighted least
+// squares,
+//  min \sum_i w_i (y_i - f_i)²
+// computed in O(n) time by the pool-adjacent-violators algorithm. The fit is
+// piecewise constant, with each value the weighted mean of y over its block,
+// so the weighted mean of the fit is that of y.
+//
+// If weights is nil then all of the weights are 1. If weights is not nil,
+// then len(y) must equal len(weights) and the weights must be positive.
+func Isotonic(y, weights []float64, decreasing bool) []float64 {
+	checkWeightLength(y, weights)
+	for _, w := range weights {
+		if !(w > 0) {
+			panic("stat: non-positive weight")
+		}
+	}
+	sign := 1.0
+	if decreasing {
+		sign = -1
+	}
+
+	// Each block of pooled observations is kept as its weighted mean, its
+	// total weight and its number of observations. A new observation is
+	// merged with the preceding blocks for as long as they violate the
+	// order, so each observation is merged at most once.
+	n := len(y)
+	mean := make([]float64, 0, n)
+	weight := make([]float64, 0, n)
+	size := make([]int, 0, n)
+	for i, v := range y {
+		m := sign * v
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		k := 1
+		for len(mean) > 0 && mean[len(mean)-1] >= m {
+			last := len(mean) - 1
+			m = (weight[last]*mean[last] + w*m) / (weight[last] + w)
+			w += weight[last]
+			k += size[last]
+			mean, weight, size = mean[:last], weight[:last], size[:last]
+		}
+		mean = append(mean, m)
+		weight = append(weight, w)
+		size = append(size, k)
+	}
+
+	fit := make([]float64, 0, n)
+	for b, m := range mean {
+		for k := 0; k < size[b]; k++ {
+			fit = append(fit, sign*m)
+		}
+	}
+	return fit
+}
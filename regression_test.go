@@ -0,0 +1,668 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestOLS(t *testing.T) {
+	// Coefficients, residuals and sums of squares computed with exact
+	// rational arithmetic from the normal equations, and p-values by
+	// numerical integration of the t and F densities.
+	x := mat64.NewDense(10, 2, []float64{
+		1, 2,
+		2, 1,
+		3, 5,
+		4, 3,
+		5, 8,
+		6, 5,
+		7, 9,
+		8, 12,
+		9, 10,
+		10, 15,
+	})
+	y := []float64{3.1, 3.9, 7.2, 7.1, 11.8, 10.2, 14.9, 17.1, 17.0, 21.9}
+	for i, test := range []struct {
+		weights   []float64
+		intercept bool
+
+		coef, se, tStat, p []float64
+		resid              []float64
+		cov                []float64
+		r2, adjR2, sigma   float64
+		f, fp, df          float64
+	}{
+		{
+			intercept: true,
+			coef:      []float64{0.8200437636761488, 1.0235448577680526, 0.7100656455142232},
+			se:        []float64{0.24020544447688602, 0.09916856188783232, 0.06569344920705396},
+			tStat:     []float64{3.413926630439296, 10.321263496043885, 10.808773996265957},
+			p:         []float64{0.011227710834019522, 1.736205902680421e-05, 1.2784123945785986e-05},
+			resid: []float64{-0.1637199124726477, 0.32280087527352297, -0.24100656455142233, 0.05557986870897155, 0.18170678336980306,
+				-0.31164113785557984, 0.5245514223194748, -0.42919037199124727, -0.13260393873085338, 0.1935229759299781},
+			cov: []float64{
+				0.05769865555633838, -0.011979140636810054, 0.0028770861791465193,
+				-0.011979140636810054, 0.00983440366690083, -0.006015725647306359,
+				0.0028770861791465193, -0.006015725647306359, 0.004315629268719779,
+			},
+			r2: 0.9976040359790113, adjR2: 0.9969194748301573, sigma: 0.34573089691129316,
+			f: 1457.2898822101806, fp: 6.73257224889576e-10, df: 7,
+		},
+		{
+			weights:   []float64{1, 2, 1, 0.5, 1, 3, 1, 1, 2, 1},
+			intercept: true,
+			coef:      []float64{0.9098626246728141, 0.9649068482370452, 0.7411612748704087},
+			se:        []float64{0.24453767877444957, 0.09013754879999077, 0.06108249181030135},
+			tStat:     []float64{3.720746141178636, 10.704826801737303, 12.133776028197566},
+			p:         []float64{0.007448765368919141, 1.3631919765000333e-05, 5.902203077390311e-06},
+			resid: []float64{-0.2570920226506766, 0.31916240398268697, -0.31038954373599303, 0.10702615776777924, 0.13631293517869056,
+				-0.20511008844712847, 0.5653379638341915, -0.4230527090140797, -0.005637007510307427, 0.2236497699006039},
+			cov: []float64{
+				0.05979867634039588, -0.012614820693569818, 0.0033925926447309547,
+				-0.012614820693569818, 0.008124777703670717, -0.004996458392355105,
+				0.0033925926447309547, -0.004996458392355105, 0.0037310708057555317,
+			},
+			r2: 0.9975234986700735, adjR2: 0.9968159268615231, sigma: 0.39011895568846505,
+			f: 1409.7841188919906, fp: 7.558488895985112e-10, df: 7,
+		},
+		{
+			intercept: false,
+			coef:      []float64{1.193798730734361, 0.6691749773345421},
+			se:        []float64{0.13089308238450859, 0.09863526433537521},
+			tStat:     []float64{9.12041117060323, 6.784338054382288},
+			p:         []float64{1.68044533640499e-05, 0.00014004425465552472},
+			resid: []float64{0.5678513145965548, 0.8432275611967361, 0.2727289211242067, 0.3172801450589302, 0.47760652765185857,
+				-0.3086672710788758, 0.5208340888485947, -0.48048957388939256, -0.43593834995466907, -0.07561196736174071},
+			cov: []float64{
+				0.01713299901611775, -0.01263495502663551,
+				-0.01263495502663551, 0.009728915370509341,
+			},
+			r2: 0.9986513585121989, adjR2: 0.9983141981402486, sigma: 0.5279461221446553,
+			f: 2961.94761186068, fp: 3.30815661980758e-12, df: 8,
+		},
+	} {
+		o := NewOLS(x, y, test.weights, test.intercept)
+		s := o.Summary
+		if s.N != 10 || s.DF != test.df {
+			t.Errorf("case %d: degrees of freedom mismatch. Want 10 and %v, got %d and %v", i, test.df, s.N, s.DF)
+		}
+		for _, v := range []struct {
+			name      string
+			got, want []float64
+			tol       float64
+		}{
+			{"coefficients", s.Coefficients, test.coef, 1e-12},
+			{"standard errors", s.StdErrs, test.se, 1e-12},
+			{"t statistics", s.TStats, test.tStat, 1e-10},
+			{"p-values", s.PValues, test.p, 1e-8},
+			{"residuals", o.Residuals, test.resid, 1e-12},
+			{"covariance", symData(s.Covariance), test.cov, 1e-14},
+		} {
+			if !floats.EqualApprox(v.got, v.want, v.tol) {
+				t.Errorf("case %d: %s mismatch. Want %v, got %v", i, v.name, v.want, v.got)
+			}
+		}
+		for j, v := range o.Fitted {
+			if math.Abs(v+o.Residuals[j]-y[j]) > 1e-12 {
+				t.Errorf("case %d: fitted value %d does not add up with the residual", i, j)
+			}
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+			tol       float64
+		}{
+			{"R²", s.R2, test.r2, 1e-13},
+			{"adjusted R²", s.AdjR2, test.adjR2, 1e-13},
+			{"residual standard error", s.ResidualStdErr, test.sigma, 1e-13},
+			{"F", s.F, test.f, 1e-8},
+			{"F p-value", s.FP, test.fp, 1e-12},
+		} {
+			if math.Abs(v.got-v.want) > v.tol*math.Max(1, math.Abs(v.want)) {
+				t.Errorf("case %d: %s mismatch. Want %v, got %v", i, v.name, v.want, v.got)
+			}
+		}
+	}
+}
+
+func TestOLSLinearRegression(t *testing.T) {
+	// With a single predictor, the fit is that of LinearRegression.
+	rnd := rand.New(rand.NewSource(1))
+	n := 50
+	xs := make([]float64, n)
+	y := make([]float64, n)
+	weights := make([]float64, n)
+	for i := range xs {
+		xs[i] = rnd.NormFloat64()
+		y[i] = 3 - 2*xs[i] + rnd.NormFloat64()
+		weights[i] = rnd.Float64()
+	}
+	x := mat64.NewDense(n, 1, xs)
+	for _, w := range [][]float64{nil, weights} {
+		for _, origin := range []bool{false, true} {
+			alpha, beta := LinearRegression(xs, y, w, origin)
+			want := []float64{beta}
+			if !origin {
+				want = []float64{alpha, beta}
+			}
+			got := NewOLS(x, y, w, !origin).Summary.Coefficients
+			if !floats.EqualApprox(got, want, 1e-12) {
+				t.Errorf("weighted %t, origin %t: coefficient mismatch. Want %v, got %v", w != nil, origin, want, got)
+			}
+		}
+	}
+
+	// Observations with zero weight do not affect the fit or the degrees
+	// of freedom.
+	wz := make([]float64, n+2)
+	copy(wz, weights)
+	xz := mat64.NewDense(n+2, 1, append(append([]float64(nil), xs...), 100, -100))
+	yz := append(append([]float64(nil), y...), 1e6, -1e6)
+	o := NewOLS(x, y, weights, true)
+	oz := NewOLS(xz, yz, wz, true)
+	if oz.Summary.N != n || oz.Summary.DF != o.Summary.DF {
+		t.Errorf("zero weights counted: want N = %d and DF = %v, got %d and %v", n, o.Summary.DF, oz.Summary.N, oz.Summary.DF)
+	}
+	if !floats.EqualApprox(oz.Summary.StdErrs, o.Summary.StdErrs, 1e-10) {
+		t.Errorf("zero weights changed the standard errors: want %v, got %v", o.Summary.StdErrs, oz.Summary.StdErrs)
+	}
+	if math.Abs(oz.Summary.R2-o.Summary.R2) > 1e-12 {
+		t.Errorf("zero weights changed R²: want %v, got %v", o.Summary.R2, oz.Summary.R2)
+	}
+}
+
+func TestOLSPredict(t *testing.T) {
+	x := mat64.NewDense(5, 2, []float64{
+		1, 0,
+		0, 1,
+		1, 1,
+		2, 1,
+		1, 3,
+	})
+	// An exact fit of y = 1 + 2 x_1 - x_2.
+	y := []float64{3, 0, 2, 4, 0}
+	o := NewOLS(x, y, nil, true)
+	if !floats.EqualApprox(o.Summary.Coefficients, []float64{1, 2, -1}, 1e-12) {
+		t.Errorf("coefficient mismatch. Want [1 2 -1], got %v", o.Summary.Coefficients)
+	}
+	if !floats.EqualApprox(o.Fitted, y, 1e-12) {
+		t.Errorf("fitted value mismatch. Want %v, got %v", y, o.Fitted)
+	}
+	newX := mat64.NewDense(2, 2, []float64{10, 5, -1, 0.5})
+	want := []float64{16, -1.5}
+	if got := o.Predict(nil, newX); !floats.EqualApprox(got, want, 1e-12) {
+		t.Errorf("prediction mismatch. Want %v, got %v", want, got)
+	}
+	dst := make([]float64, 2)
+	o.Predict(dst, newX)
+	if !floats.EqualApprox(dst, want, 1e-12) {
+		t.Errorf("prediction into dst mismatch. Want %v, got %v", want, dst)
+	}
+	if o.Summary.ResidualStdErr > 1e-12 {
+		t.Errorf("residual standard error of exact fit not zero: %v", o.Summary.ResidualStdErr)
+	}
+
+	if !Panics(func() { o.Predict(nil, mat64.NewDense(2, 3, nil)) }) {
+		t.Errorf("Predict did not panic with wrong number of columns")
+	}
+	if !Panics(func() { o.Predict(make([]float64, 3), newX) }) {
+		t.Errorf("Predict did not panic with wrong dst length")
+	}
+}
+
+func TestOLSPanics(t *testing.T) {
+	x := mat64.NewDense(4, 2, []float64{
+		1, 2,
+		2, 4,
+		3, 6,
+		4, 8,
+	})
+	y := []float64{1, 2, 3, 5}
+	for _, test := range []struct {
+		name string
+		f    func()
+	}{
+		{"rank deficient", func() { NewOLS(x, y, nil, false) }},
+		{"constant column with intercept", func() {
+			NewOLS(mat64.NewDense(4, 1, []float64{2, 2, 2, 2}), y, nil, true)
+		}},
+		{"response length", func() { NewOLS(x, y[:3], nil, true) }},
+		{"weights length", func() { NewOLS(x, y, []float64{1, 1}, true) }},
+		{"negative weight", func() { NewOLS(x, y, []float64{1, -1, 1, 1}, true) }},
+		{"too few observations", func() { NewOLS(mat64.NewDense(2, 2, []float64{1, 2, 3, 5}), y[:2], nil, true) }},
+	} {
+		if !Panics(test.f) {
+			t.Errorf("%s: NewOLS did not panic", test.name)
+		}
+	}
+}
+
+// symData returns the elements of s in row-major order.
+func symData(s *mat64.SymDense) []float64 {
+	n := s.Symmetric()
+	data := make([]float64, 0, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			data = append(data, s.At(i, j))
+		}
+	}
+	return data
+}
+
+func TestPolynomialRegression(t *testing.T) {
+	// Coefficients, R² and residuals computed with exact rational
+	// arithmetic from the normal equations.
+	x := []float64{-2, -1, -0.5, 0, 0.5, 1, 1.5, 2, 3}
+	y := []float64{7.9, 2.1, 0.6, 1.2, 1.4, 3.2, 5.8, 9.1, 20.3}
+	for i, test := range []struct {
+		weights []float64
+		degree  int
+		coeffs  []float64
+		r2      float64
+		resid   []float64
+	}{
+		{
+			degree: 2,
+			coeffs: []float64{0.6410789210789211, 0.42745254745254746, 2.018701298701299},
+			r2:     0.9976982955804083,
+			resid: []float64{0.03902097902097902, -0.13232767232767234, -0.332027972027972, 0.5589210789210789,
+				0.04051948051948052, 0.11276723276723277, -0.024335664335664337, -0.47078921078921077, 0.20825174825174825},
+		},
+		{
+			weights: []float64{1, 1, 2, 1, 0.5, 1, 1, 2, 1},
+			degree:  3,
+			coeffs:  []float64{0.5875348995741349, 0.33771071034892364, 2.00143864696837, 0.01837831697960597},
+			r2:      0.9972488268282379,
+			resid: []float64{0.12915846908708073, -0.13288451921397507, -0.3167419165193147, 0.6124651004258652,
+				0.14095279388686016, 0.2549374261289657, 0.14063525941747737, -0.31573744398230935, 0.19017058821440413},
+		},
+	} {
+		coeffs, r2, resid := PolynomialRegression(x, y, test.weights, test.degree)
+		if !floats.EqualApprox(coeffs, test.coeffs, 1e-12) {
+			t.Errorf("case %d: coefficient mismatch. Want %v, got %v", i, test.coeffs, coeffs)
+		}
+		if math.Abs(r2-test.r2) > 1e-13 {
+			t.Errorf("case %d: R² mismatch. Want %v, got %v", i, test.r2, r2)
+		}
+		if !floats.EqualApprox(resid, test.resid, 1e-12) {
+			t.Errorf("case %d: residual mismatch. Want %v, got %v", i, test.resid, resid)
+		}
+		for j, v := range x {
+			if got := Eval(coeffs, v); math.Abs(got+resid[j]-y[j]) > 1e-12 {
+				t.Errorf("case %d: Eval at %v does not match the fit: got %v, want %v", i, v, got, y[j]-resid[j])
+			}
+		}
+	}
+
+	// A polynomial of degree 1 is the line of LinearRegression.
+	rnd := rand.New(rand.NewSource(1))
+	xs := make([]float64, 30)
+	ys := make([]float64, 30)
+	weights := make([]float64, 30)
+	for i := range xs {
+		xs[i] = rnd.NormFloat64()
+		ys[i] = 1 + 2*xs[i] + rnd.NormFloat64()
+		weights[i] = rnd.Float64()
+	}
+	for _, w := range [][]float64{nil, weights} {
+		alpha, beta := LinearRegression(xs, ys, w, false)
+		coeffs, r2, _ := PolynomialRegression(xs, ys, w, 1)
+		if !floats.EqualApprox(coeffs, []float64{alpha, beta}, 1e-12) {
+			t.Errorf("weighted %t: degree 1 mismatch with LinearRegression. Want %v, got %v", w != nil, []float64{alpha, beta}, coeffs)
+		}
+		rho := Correlation(xs, ys, w)
+		if math.Abs(r2-rho*rho) > 1e-12 {
+			t.Errorf("weighted %t: degree 1 R² mismatch. Want %v, got %v", w != nil, rho*rho, r2)
+		}
+	}
+
+	// An exact cubic.
+	cubic := []float64{1, -2, 0.5, 0.25}
+	xc := []float64{-3, -2, -1, 0, 1, 2, 3, 4}
+	yc := make([]float64, len(xc))
+	for i, v := range xc {
+		yc[i] = 1 - 2*v + 0.5*v*v + 0.25*v*v*v
+	}
+	coeffs, r2, _ := PolynomialRegression(xc, yc, nil, 3)
+	if !floats.EqualApprox(coeffs, cubic, 1e-12) || math.Abs(r2-1) > 1e-14 {
+		t.Errorf("exact cubic mismatch. Want %v and R² 1, got %v and %v", cubic, coeffs, r2)
+	}
+
+	for _, test := range []struct {
+		name string
+		f    func()
+	}{
+		{"zero degree", func() { PolynomialRegression(x, y, nil, 0) }},
+		{"degree not below length", func() { PolynomialRegression(x, y, nil, len(x)) }},
+		{"length mismatch", func() { PolynomialRegression(x, y[1:], nil, 2) }},
+		{"too few distinct values", func() { PolynomialRegression([]float64{1, 1, 2, 2, 2}, y[:5], nil, 2) }},
+	} {
+		if !Panics(test.f) {
+			t.Errorf("%s: PolynomialRegression did not panic", test.name)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	for _, test := range []struct {
+		coeffs []float64
+		x      float64
+		want   float64
+	}{
+		{nil, 2, 0},
+		{[]float64{3}, 2, 3},
+		{[]float64{1, -2, 0.5, 0.25}, 2, 1 - 4 + 2 + 2},
+		{[]float64{1, 1, 1, 1, 1}, -1, 1},
+	} {
+		if got := Eval(test.coeffs, test.x); got != test.want {
+			t.Errorf("Eval(%v, %v) mismatch. Want %v, got %v", test.coeffs, test.x, test.want, got)
+		}
+	}
+}
+
+func TestRidge(t *testing.T) {
+	// A design with nearly collinear first and second columns. The
+	// coefficients, effective degrees of freedom and GCV scores were
+	// computed with exact rational arithmetic from the penalized normal
+	// equations.
+	x := mat64.NewDense(8, 3, []float64{
+		1, 2.1, 3,
+		2, 3.9, 1,
+		3, 6.2, 4,
+		4, 7.8, 2,
+		5, 10.1, 5,
+		6, 12.0, 3,
+		7, 13.8, 6,
+		8, 16.1, 2,
+	})
+	y := []float64{3.2, 4.1, 7.9, 8.2, 12.1, 11.8, 15.9, 14.2}
+	for i, test := range []struct {
+		lambda                 float64
+		intercept, standardize bool
+		coeffs                 []float64
+		gcv                    float64
+	}{
+		{
+			lambda: 0, intercept: true,
+			coeffs: []float64{-0.2016824811105254, 3.2104199613424704, -0.790458618871903, 0.782744684589703},
+			gcv:    0.2602218415041293,
+		},
+		{
+			lambda: 0.1, intercept: true,
+			coeffs: []float64{-0.2043406537162295, 1.0114490893485673, 0.3146727875461625, 0.7679275888406812},
+			gcv:    0.2494819972194512,
+		},
+		{
+			lambda: 1, intercept: true,
+			coeffs: []float64{-0.09998272097810493, 0.41436416942174537, 0.6148375023392177, 0.7313250577007049},
+			gcv:    0.2696955575499708,
+		},
+		{
+			lambda: 10, intercept: true,
+			coeffs: []float64{0.7050182504046657, 0.3325158019380365, 0.6444251898510975, 0.5150258252967053},
+			gcv:    0.6078729148331637,
+		},
+		{
+			lambda: 1, intercept: true, standardize: true,
+			coeffs: []float64{0.33137805505282375, 0.7847258427123461, 0.38560218617624953, 0.7205956852785763},
+			gcv:    0.3519661360636242,
+		},
+		{
+			lambda: 1,
+			coeffs: []float64{0.4131929451774151, 0.6108699995189861, 0.7174986859896122},
+			gcv:    0.1970261610341975,
+		},
+		{
+			lambda: 2.5, standardize: true,
+			coeffs: []float64{0.6883328763156292, 0.34365968057424573, 0.7914538616224424},
+			gcv:    1.8129882028803952,
+		},
+	} {
+		coeffs := Ridge(x, y, test.lambda, test.intercept, test.standardize)
+		if !floats.EqualApprox(coeffs, test.coeffs, 1e-11) {
+			t.Errorf("case %d: coefficient mismatch. Want %v, got %v", i, test.coeffs, coeffs)
+		}
+		_, gcv := RidgeGCV(x, y, []float64{test.lambda}, test.intercept, test.standardize)
+		if math.Abs(gcv[0]-test.gcv) > 1e-12 {
+			t.Errorf("case %d: GCV mismatch. Want %v, got %v", i, test.gcv, gcv[0])
+		}
+	}
+
+	// With no penalty, the fit is that of OLS.
+	want := NewOLS(x, y, nil, true).Summary.Coefficients
+	if got := Ridge(x, y, 0, true, true); !floats.EqualApprox(got, want, 1e-10) {
+		t.Errorf("unpenalized fit mismatch with OLS. Want %v, got %v", want, got)
+	}
+
+	lambdas := []float64{0, 0.1, 1, 10}
+	best, gcv := RidgeGCV(x, y, lambdas, true, false)
+	if best != 0.1 {
+		t.Errorf("best penalty mismatch. Want 0.1, got %v", best)
+	}
+	if len(gcv) != len(lambdas) {
+		t.Errorf("GCV length mismatch. Want %d, got %d", len(lambdas), len(gcv))
+	}
+
+	// A rank deficient design can be fitted with a positive penalty, and
+	// a constant column gets a zero coefficient.
+	xc := mat64.NewDense(4, 2, []float64{
+		1, 5,
+		2, 5,
+		3, 5,
+		4, 5,
+	})
+	coeffs := Ridge(xc, []float64{1, 3, 2, 5}, 1, true, true)
+	if coeffs[2] != 0 {
+		t.Errorf("constant column coefficient not zero: %v", coeffs[2])
+	}
+
+	for _, test := range []struct {
+		name string
+		f    func()
+	}{
+		{"negative penalty", func() { Ridge(x, y, -1, true, false) }},
+		{"length mismatch", func() { Ridge(x, y[1:], 1, true, false) }},
+		{"rank deficient without penalty", func() { Ridge(xc, []float64{1, 3, 2, 5}, 0, true, false) }},
+		{"no penalties", func() { RidgeGCV(x, y, nil, true, false) }},
+	} {
+		if !Panics(test.f) {
+			t.Errorf("%s: did not panic", test.name)
+		}
+	}
+}
+
+func TestOLSInfluence(t *testing.T) {
+	// Diagnostics computed with exact rational arithmetic from the hat
+	// matrix X (XᵀWX)⁻¹ XᵀW.
+	x := mat64.NewDense(10, 2, []float64{
+		1, 2,
+		2, 1,
+		3, 5,
+		4, 3,
+		5, 8,
+		6, 5,
+		7, 9,
+		8, 12,
+		9, 10,
+		10, 15,
+	})
+	y := []float64{3.1, 3.9, 7.2, 7.1, 11.8, 10.2, 14.9, 17.1, 17.0, 21.9}
+	nan := math.NaN()
+	for i, test := range []struct {
+		weights     []float64
+		want        Influence
+		influential []int
+	}{
+		{
+			want: Influence{
+				Leverage: []float64{0.40393873085339166, 0.2938730853391685, 0.2553610503282276, 0.2588621444201313, 0.20700218818380745,
+					0.36564551422319475, 0.1275711159737418, 0.25864332603938733, 0.3759299781181619, 0.45317286652078775},
+				Standardized: []float64{-0.6133634024343763, 1.1111050670147322, -0.807825958755604, 0.1867367626503783, 0.5901969395160548,
+					-1.1317502025076756, 1.6243696195260984, -1.4417772792332415, -0.4855137911792062, 0.7569536944321766},
+				Studentized: []float64{-0.5837681007651545, 1.1334811877921478, -0.7854072521055323, 0.1733168776701689, 0.5605409045857043,
+					-1.1592064420684671, 1.9052253959738379, -1.591969858721059, -0.45726348773485076, 0.7313749394277566},
+				CooksDistance: []float64{0.08498436964281504, 0.17126398648251484, 0.07459732626498114, 0.0040598308941290255, 0.030309278544311563,
+					0.24609803246913545, 0.12860882813053653, 0.24174036565165416, 0.04733207292759509, 0.15828188510211727},
+				DFFITS: []float64{-0.4805655743322175, 0.7312280459619489, -0.45993807214109955, 0.10242963627317181, 0.2863906161004615,
+					-0.8800855759727899, 0.7285469114521284, -0.9403117830436086, -0.3548978298623392, 0.6658049145729726},
+			},
+		},
+		{
+			weights: []float64{1, 2, 1, 0.5, 1, 3, 1, 1, 2, 0},
+			want: Influence{
+				Leverage: []float64{0.3370846730975348, 0.4764636018655311, 0.23850699574172243, 0.08096520958257293, 0.2557428811448104,
+					0.5673068566959242, 0.14407172445757654, 0.4050577909098809, 0.4948002665044466, nan},
+				Standardized: []float64{-0.8036226775799087, 1.3181286822754479, -0.7911723916146681, 0.13836396624686104, 0.5914691771061746,
+					-1.4392006049875243, 1.6770722561075158, -0.9835733984230332, 0.23744466319968432, nan},
+				Studentized: []float64{-0.7765872795348716, 1.4276072438056746, -0.763142123825006, 0.12651043619550476, 0.5563993123274384,
+					-1.6236103538565183, 2.100470279010115, -0.980384163799496, 0.21778195702508044, nan},
+				CooksDistance: []float64{0.10946217126977134, 0.52708082208296, 0.0653515958460717, 0.0005622005200760384, 0.04007038694365239,
+					0.9052310258924862, 0.15780605939808012, 0.2195505362171687, 0.01840646473258484, nan},
+				DFFITS: []float64{-0.5537716843170053, 1.3619155146519042, -0.4270935654333631, 0.03754997042691345, 0.3261571192786723,
+					-1.8590917426532458, 0.8617617849271887, -0.8089420132098787, 0.21552879566942593, nan},
+			},
+			influential: []int{1, 5},
+		},
+	} {
+		got := NewOLS(x, y, test.weights, true).Influence()
+		for _, v := range []struct {
+			name      string
+			got, want []float64
+		}{
+			{"leverage", got.Leverage, test.want.Leverage},
+			{"standardized residual", got.Standardized, test.want.Standardized},
+			{"studentized residual", got.Studentized, test.want.Studentized},
+			{"Cook's distance", got.CooksDistance, test.want.CooksDistance},
+			{"DFFITS", got.DFFITS, test.want.DFFITS},
+		} {
+			if !sameFloatsNaN(v.got, v.want, 1e-12) {
+				t.Errorf("case %d: %s mismatch. Want %v, got %v", i, v.name, v.want, v.got)
+			}
+		}
+		var sum float64
+		for _, h := range got.Leverage {
+			if !math.IsNaN(h) {
+				sum += h
+			}
+		}
+		if math.Abs(sum-3) > 1e-12 {
+			t.Errorf("case %d: leverages do not sum to the number of coefficients: %v", i, sum)
+		}
+		if idx := got.Influential(); !reflect.DeepEqual(idx, test.influential) {
+			t.Errorf("case %d: influential observations mismatch. Want %v, got %v", i, test.influential, idx)
+		}
+	}
+}
+
+// sameFloatsNaN returns whether a and b have the same length and their
+// elements are equal within the relative tolerance tol, with NaNs equal.
+func sameFloatsNaN(a, b []float64, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if math.IsNaN(v) || math.IsNaN(b[i]) {
+			if math.IsNaN(v) != math.IsNaN(b[i]) {
+				return false
+			}
+			continue
+		}
+		if !floats.EqualWithinAbsOrRel(v, b[i], tol, tol) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIsotonic(t *testing.T) {
+	for i, test := range []struct {
+		y, weights []float64
+		decreasing bool
+		want       []float64
+	}{
+		{y: nil, want: []float64{}},
+		{y: []float64{2}, want: []float64{2}},
+		{y: []float64{1, 3, 2, 4, 3, 5}, want: []float64{1, 2.5, 2.5, 3.5, 3.5, 5}},
+		{y: []float64{1, 3, 2}, weights: []float64{1, 1, 3}, want: []float64{1, 2.25, 2.25}},
+		{y: []float64{4, 3, 2, 1}, want: []float64{2.5, 2.5, 2.5, 2.5}},
+		{y: []float64{2, 2, 2, 1, 3, 3}, want: []float64{1.75, 1.75, 1.75, 1.75, 3, 3}},
+		{y: []float64{5, 3, 4, 1}, decreasing: true, want: []float64{5, 3.5, 3.5, 1}},
+		{y: []float64{1, 2, 3}, decreasing: true, want: []float64{2, 2, 2}},
+	} {
+		got := Isotonic(test.y, test.weights, test.decreasing)
+		if !floats.EqualApprox(got, test.want, 1e-14) || len(got) != len(test.want) {
+			t.Errorf("case %d: fit mismatch. Want %v, got %v", i, test.want, got)
+		}
+	}
+
+	// Compare with the min-max formula
+	//  f_i = max_{j≤i} min_{k≥i} mean_w(y_j, ..., y_k)
+	// for random data, and check that the weighted mean is preserved.
+	rnd := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		n := 1 + rnd.Intn(30)
+		y := make([]float64, n)
+		w := make([]float64, n)
+		for i := range y {
+			y[i] = float64(i)/10 + rnd.NormFloat64()
+			if trial%4 == 0 {
+				// Runs of equal values.
+				y[i] = float64(rnd.Intn(4))
+			}
+			w[i] = 0.1 + rnd.Float64()
+		}
+		got := Isotonic(y, w, false)
+		for i := range y {
+			best := math.Inf(-1)
+			for j := 0; j <= i; j++ {
+				min := math.Inf(1)
+				var sw, swy float64
+				for k := j; k < n; k++ {
+					sw += w[k]
+					swy += w[k] * y[k]
+					if k >= i {
+						min = math.Min(min, swy/sw)
+					}
+				}
+				best = math.Max(best, min)
+			}
+			if math.Abs(got[i]-best) > 1e-12 {
+				t.Errorf("trial %d: fit mismatch at %d. Want %v, got %v", trial, i, best, got[i])
+			}
+		}
+		if m, want := Mean(got, w), Mean(y, w); math.Abs(m-want) > 1e-12 {
+			t.Errorf("trial %d: weighted mean not preserved. Want %v, got %v", trial, want, m)
+		}
+
+		// A decreasing fit is the reverse of the increasing fit of the
+		// reversed data.
+		ry := make([]float64, n)
+		rw := make([]float64, n)
+		for i := range y {
+			ry[n-1-i] = y[i]
+			rw[n-1-i] = w[i]
+		}
+		dec := Isotonic(ry, rw, true)
+		for i := range dec {
+			if math.Abs(dec[n-1-i]-got[i]) > 1e-12 {
+				t.Errorf("trial %d: decreasing fit mismatch at %d. Want %v, got %v", trial, n-1-i, got[i], dec[n-1-i])
+			}
+		}
+	}
+
+	if !Panics(func() { Isotonic([]float64{1, 2}, []float64{1}, false) }) {
+		t.Errorf("Isotonic did not panic with length mismatch")
+	}
+	if !Panics(func() { Isotonic([]float64{1, 2}, []float64{1, 0}, false) }) {
+		t.Errorf("Isotonic did not panic with zero weight")
+	}
+}
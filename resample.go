@@ -0,0 +1,517 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// bootstrapRound is the largest number of resamples whose indices are drawn
+// at once by resampleRounds before their statistics are computed.
+const bootstrapRound = 64
+
+// Bootstrap returns the bootstrap distribution of the statistic of x, the
+// values of statistic for n resamples of x drawn with replacement. The
+// resamples are drawn from src, or from the global random source if src is
+// nil, so the result is determined by the state of src. The slice passed to
+// statistic may be modified by it but must not be retained.
+//
+// The statistics of the resamples are computed in parallel as configured by
+// Parallel, so statistic must be safe to call concurrently if Parallel.Workers
+// is greater than one. The indices of the resamples are always drawn from src
+// in the same order, so the result does not depend on Parallel.
+//
+// Bootstrap panics if x is empty or if n is not positive.
+func Bootstrap(x []float64, statistic func([]float64) float64, n int, src *rand.Rand) []float64 {
+	boot, _ := BootstrapCtx(context.Background(), x, statistic, n, src)
+	return boot
+}
+
+// BootstrapCtx is like Bootstrap, but stops and returns the error of ctx if
+// ctx is cancelled or expires before all of the resamples are evaluated.
+// ctx is checked before each round of at most 64 resamples. On
+// cancellation the returned distribution is nil, and src has been advanced by
+// the resamples drawn so far. Otherwise the result is that of Bootstrap with
+// the same state of src.
+func BootstrapCtx(ctx context.Context, x []float64, statistic func([]float64) float64, n int, src *rand.Rand) ([]float64, error) {
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	bufs := make([][]float64, resampleSlots(n, len(x)))
+	for i := range bufs {
+		bufs[i] = make([]float64, len(x))
+	}
+	return bootstrap(ctx, len(x), n, src, func(slot int, idx []int) float64 {
+		buf := bufs[slot]
+		for i, j := range idx {
+			buf[i] = x[j]
+		}
+		return statistic(buf)
+	})
+}
+
+// BootstrapPaired returns the bootstrap distribution of the statistic of the
+// pairs (x_i, y_i), the values of statistic for n resamples of the pairs
+// drawn with replacement, as for Bootstrap. It is suited to statistics such
+// as Correlation. BootstrapPaired panics if x and y have different lengths,
+// if they are empty or if n is not positive.
+func BootstrapPaired(x, y []float64, statistic func(x, y []float64) float64, n int, src *rand.Rand) []float64 {
+	checkLengths(x, y)
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	bufs := make([][2][]float64, resampleSlots(n, len(x)))
+	for i := range bufs {
+		bufs[i] = [2][]float64{make([]float64, len(x)), make([]float64, len(y))}
+	}
+	boot, _ := bootstrap(context.Background(), len(x), n, src, func(slot int, idx []int) float64 {
+		bx, by := bufs[slot][0], bufs[slot][1]
+		for i, j := range idx {
+			bx[i] = x[j]
+			by[i] = y[j]
+		}
+		return statistic(bx, by)
+	})
+	return boot
+}
+
+// bootstrap returns the values of eval for n resamples of size indices drawn
+// with replacement from [0, size), as computed by resampleRounds.
+func bootstrap(ctx context.Context, size, n int, src *rand.Rand, eval func(slot int, idx []int) float64) ([]float64, error) {
+	if n < 1 {
+		panic("stat: non-positive number of resamples")
+	}
+	intn := rand.Intn
+	if src != nil {
+		intn = src.Intn
+	}
+	return resampleRounds(ctx, n, size, func(idx []int) {
+		for i := range idx {
+			idx[i] = intn(size)
+		}
+	}, eval)
+}
+
+// resampleRounds returns the values of eval for n resamples of size indices.
+// The resamples are handled in rounds of resampleSlots(n, size): fill is
+// called for each resample of a round in turn to set its indices, and then
+// eval is called for each with its slot in the round, possibly concurrently.
+// Since fill is called in the same order whatever the parallelism, so is a
+// random source used by it. resampleRounds stops and returns the error of ctx
+// if ctx is done before a round.
+func resampleRounds(ctx context.Context, n, size int, fill func(idx []int), eval func(slot int, idx []int) float64) ([]float64, error) {
+	values := make([]float64, n)
+	round := resampleSlots(n, size)
+	idx := make([]int, round*size)
+	for first := 0; first < n; first += round {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		m := n - first
+		if m > round {
+			m = round
+		}
+		for slot := 0; slot < m; slot++ {
+			fill(idx[slot*size : (slot+1)*size])
+		}
+		runParallel(m, m*size, func(slot int) {
+			values[first+slot] = eval(slot, idx[slot*size:(slot+1)*size])
+		})
+	}
+	return values, nil
+}
+
+// resampleSlots returns the number of resamples of size indices in each round
+// of resampleRounds for n resamples, and so the number of slots passed to its
+// eval. A round holds one resample for each of the Parallel.Workers, or more
+// if needed to reach Parallel.MinWork elements, up to bootstrapRound and n,
+// so that the buffers of a round are no larger than the parallelism needs.
+func resampleSlots(n, size int) int {
+	round := Parallel.Workers
+	if size > 0 {
+		if min := (Parallel.MinWork + size - 1) / size; min > round {
+			round = min
+		}
+	}
+	if round > bootstrapRound {
+		round = bootstrapRound
+	}
+	if round > n {
+		round = n
+	}
+	if round < 1 {
+		round = 1
+	}
+	return round
+}
+
+// Jackknife returns the jackknife values of the statistic of x, the values of
+// statistic for each of the len(x) samples leaving out one value of x, along
+// with the jackknife estimates of the bias and the standard error of the
+// statistic,
+//  bias = (n - 1) (m - θ)
+//  stdErr = sqrt((n - 1)/n sum_i (θ_i - m)²)
+// where θ is the statistic of x, θ_i are the jackknife values and m is their
+// mean. The bias corrected estimate is θ - bias. The samples are stored in a
+// single scratch slice, which is passed to statistic and may be modified by
+// it but must not be retained. x is not modified. Jackknife panics if x has
+// fewer than two values.
+func Jackknife(x []float64, statistic func([]float64) float64) (values []float64, bias, stdErr float64) {
+	if len(x) < 2 {
+		panic("stat: too few samples")
+	}
+	values = make([]float64, len(x))
+	buf := make([]float64, len(x))
+	copy(buf, x)
+	estimate := statistic(buf)
+	buf = buf[:len(x)-1]
+	for i := range x {
+		copy(buf, x[:i])
+		copy(buf[i:], x[i+1:])
+		values[i] = statistic(buf)
+	}
+	bias, stdErr = jackknifeBiasStdErr(values, estimate)
+	return values, bias, stdErr
+}
+
+// JackknifePaired returns the jackknife values, bias and standard error of
+// the statistic of the pairs (x_i, y_i), as for Jackknife. JackknifePaired
+// panics if x and y have different lengths or if there are fewer than two
+// pairs.
+func JackknifePaired(x, y []float64, statistic func(x, y []float64) float64) (values []float64, bias, stdErr float64) {
+	checkLengths(x, y)
+	if len(x) < 2 {
+		panic("stat: too few samples")
+	}
+	values = make([]float64, len(x))
+	bx := make([]float64, len(x))
+	by := make([]float64, len(y))
+	copy(bx, x)
+	copy(by, y)
+	estimate := statistic(bx, by)
+	bx, by = bx[:len(x)-1], by[:len(y)-1]
+	for i := range x {
+		copy(bx, x[:i])
+		copy(bx[i:], x[i+1:])
+		copy(by, y[:i])
+		copy(by[i:], y[i+1:])
+		values[i] = statistic(bx, by)
+	}
+	bias, stdErr = jackknifeBiasStdErr(values, estimate)
+	return values, bias, stdErr
+}
+
+// jackknifeBiasStdErr returns the jackknife estimates of the bias and the
+// standard error from the jackknife values and the estimate from the full
+// sample.
+func jackknifeBiasStdErr(values []float64, estimate float64) (bias, stdErr float64) {
+	n := float64(len(values))
+	m := Mean(values, nil)
+	var ss float64
+	for _, v := range values {
+		d := v - m
+		ss += d * d
+	}
+	return (n - 1) * (m - estimate), math.Sqrt((n - 1) / n * ss)
+}
+
+// BootstrapPercentileCI returns the percentile bootstrap confidence interval
+// at the confidence level level, the (1-level)/2 and (1+level)/2 quantiles of
+// the bootstrap distribution boot, as computed by Quantile with kind
+// Empirical. boot is not modified. BootstrapPercentileCI returns NaN if boot
+// contains a NaN value, and panics if boot is empty or if level is not
+// between 0 and 1.
+func BootstrapPercentileCI(boot []float64, level float64) (lo, hi float64) {
+	s, ok := sortedBoot(boot, level)
+	if !ok {
+		return math.NaN(), math.NaN()
+	}
+	tail := (1 - level) / 2
+	return Quantile(tail, Empirical, s, nil), Quantile(1-tail, Empirical, s, nil)
+}
+
+// BootstrapBasicCI returns the basic bootstrap confidence interval at the
+// confidence level level,
+//  [2 θ - q_(1+level)/2, 2 θ - q_(1-level)/2]
+// where θ is the estimate of the statistic from the original sample and q the
+// quantiles of the bootstrap distribution boot, as for BootstrapPercentileCI.
+func BootstrapBasicCI(boot []float64, estimate, level float64) (lo, hi float64) {
+	qlo, qhi := BootstrapPercentileCI(boot, level)
+	return 2*estimate - qhi, 2*estimate - qlo
+}
+
+// BootstrapBCaCI returns the bias-corrected and accelerated bootstrap
+// confidence interval of Efron at the confidence level level. The interval is
+// bounded by the quantiles of the bootstrap distribution boot at
+//  Φ(z_0 + (z_0 + z_α) / (1 - a (z_0 + z_α)))
+// for z_α = Φ⁻¹((1∓level)/2), where the bias correction z_0 = Φ⁻¹(F) with F the
+// fraction of boot less than the estimate of the statistic from the original
+// sample, and the acceleration
+//  a = sum_i (m - θ_i)³ / (6 (sum_i (m - θ_i)²)^(3/2))
+// is computed from the jackknife values θ_i, with mean m, of the statistic as
+// returned by Jackknife.
+//
+// boot and jack are not modified. BootstrapBCaCI returns NaN if boot or jack
+// contains a NaN value or if no value or every value of boot is less than the
+// estimate, and panics if boot or jack is empty or if level is not between 0
+// and 1.
+func BootstrapBCaCI(boot []float64, estimate float64, jack []float64, level float64) (lo, hi float64) {
+	if len(jack) == 0 {
+		panic("stat: zero length slice")
+	}
+	s, ok := sortedBoot(boot, level)
+	if !ok || math.IsNaN(estimate) {
+		return math.NaN(), math.NaN()
+	}
+	var less int
+	for _, v := range s {
+		if v < estimate {
+			less++
+		}
+	}
+	if less == 0 || less == len(s) {
+		return math.NaN(), math.NaN()
+	}
+	z0 := normalQuantile(float64(less) / float64(len(s)))
+
+	m := Mean(jack, nil)
+	var s2, s3 float64
+	for _, v := range jack {
+		d := m - v
+		s2 += d * d
+		s3 += d * d * d
+	}
+	var a float64
+	if s2 != 0 {
+		a = s3 / (6 * math.Pow(s2, 1.5))
+	}
+	if math.IsNaN(a) {
+		return math.NaN(), math.NaN()
+	}
+
+	adjusted := func(p float64) float64 {
+		z := z0 + normalQuantile(p)
+		return 0.5 * math.Erfc(-(z0+z/(1-a*z))/math.Sqrt2)
+	}
+	tail := (1 - level) / 2
+	return Quantile(adjusted(tail), Empirical, s, nil), Quantile(adjusted(1-tail), Empirical, s, nil)
+}
+
+// sortedBoot returns a sorted copy of the bootstrap distribution boot, and
+// whether it is free of NaN values, after checking boot and level.
+func sortedBoot(boot []float64, level float64) ([]float64, bool) {
+	if len(boot) == 0 {
+		panic("stat: zero length slice")
+	}
+	if !(level > 0 && level < 1) {
+		panic("stat: confidence level out of range")
+	}
+	s := make([]float64, len(boot))
+	copy(s, boot)
+	for _, v := range s {
+		if math.IsNaN(v) {
+			return nil, false
+		}
+	}
+	sort.Float64s(s)
+	return s, true
+}
+
+// PermutationTest performs a two-sample permutation test of whether x and y
+// are drawn from the same distribution, using the statistic of the two
+// samples. It returns the observed statistic of x and y and its p-value, the
+// fraction of the reassignments of the pooled values to groups of the sizes
+// of x and y whose statistic is at least as extreme as the observed one in
+// the direction of the alternative alt: larger for Greater, smaller for Less
+// and larger in absolute value for TwoSided. The comparisons allow a relative
+// difference of 1e-12 so that rounding does not affect ties.
+//
+// If the number of distinct reassignments, (n_x+n_y)!/(n_x! n_y!), is at most
+// n, they are all enumerated and the p-value is exact. Otherwise n random
+// permutations are drawn from src, or from the global random source if src is
+// nil, and the p-value is (k+1)/(n+1) for k permutations at least as extreme,
+// which counts the observed assignment so that the p-value is never zero.
+//
+// The slices passed to statistic may be modified by it but must not be
+// retained. As for Bootstrap, the statistics are computed in parallel as
+// configured by Parallel and the result does not depend on Parallel.
+//
+// PermutationTest panics if x or y is empty or if n is not positive.
+func PermutationTest(x, y []float64, statistic func(x, y []float64) float64, n int, alt Alternative, src *rand.Rand) (observed, p float64) {
+	observed, p, _ = PermutationTestCtx(context.Background(), x, y, statistic, n, alt, src)
+	return observed, p
+}
+
+// PermutationTestCtx is like PermutationTest, but stops and returns the error
+// of ctx if ctx is cancelled or expires before all of the reassignments are
+// evaluated. ctx is checked before each round of at most 64 reassignments.
+// On cancellation the returned p-value is NaN, and src has been
+// advanced by the permutations drawn so far. Otherwise the result is that of
+// PermutationTest with the same state of src.
+func PermutationTestCtx(ctx context.Context, x, y []float64, statistic func(x, y []float64) float64, n int, alt Alternative, src *rand.Rand) (observed, p float64, err error) {
+	if len(x) == 0 || len(y) == 0 {
+		panic("stat: zero length slice")
+	}
+	if n < 1 {
+		panic("stat: non-positive number of resamples")
+	}
+	nx := len(x)
+	pooled := make([]float64, 0, nx+len(y))
+	pooled = append(pooled, x...)
+	pooled = append(pooled, y...)
+	size := len(pooled)
+
+	total, exact := binomialAtMost(size, nx, n)
+	if exact {
+		n = total
+	}
+	bufs := make([][]float64, resampleSlots(n, size))
+	for i := range bufs {
+		bufs[i] = make([]float64, size)
+	}
+	copy(bufs[0], pooled)
+	observed = statistic(bufs[0][:nx], bufs[0][nx:])
+
+	var fill func(idx []int)
+	if exact {
+		// Enumerate the subsets of nx indices for x in lexicographic order.
+		comb := make([]int, nx)
+		for i := range comb {
+			comb[i] = i
+		}
+		in := make([]bool, size)
+		fill = func(idx []int) {
+			for i := range in {
+				in[i] = false
+			}
+			for i, j := range comb {
+				idx[i] = j
+				in[j] = true
+			}
+			k := nx
+			for j, ok := range in {
+				if !ok {
+					idx[k] = j
+					k++
+				}
+			}
+			nextCombination(comb, size)
+		}
+	} else {
+		intn := rand.Intn
+		if src != nil {
+			intn = src.Intn
+		}
+		fill = func(idx []int) {
+			for i := range idx {
+				idx[i] = i
+			}
+			for i := len(idx) - 1; i > 0; i-- {
+				j := intn(i + 1)
+				idx[i], idx[j] = idx[j], idx[i]
+			}
+		}
+	}
+	values, err := resampleRounds(ctx, n, size, fill, func(slot int, idx []int) float64 {
+		buf := bufs[slot]
+		for i, j := range idx {
+			buf[i] = pooled[j]
+		}
+		return statistic(buf[:nx], buf[nx:])
+	})
+	if err != nil {
+		return observed, math.NaN(), err
+	}
+
+	tol := 1e-12 * math.Abs(observed)
+	var count int
+	for _, v := range values {
+		var extreme bool
+		switch alt {
+		case Greater:
+			extreme = v >= observed-tol
+		case Less:
+			extreme = v <= observed+tol
+		default:
+			extreme = math.Abs(v) >= math.Abs(observed)-tol
+		}
+		if extreme {
+			count++
+		}
+	}
+	if exact {
+		return observed, float64(count) / float64(n), nil
+	}
+	return observed, float64(count+1) / float64(n+1), nil
+}
+
+// EnergyTest performs the two-sample energy test of Székely and Rizzo of
+// whether x and y are drawn from the same distribution, an omnibus test
+// sensitive to any difference of the distributions. It returns the
+// statistic
+//  T = n_x n_y / (n_x + n_y) E
+// for the EnergyDistance E of x and y, and its p-value from a permutation
+// test with n reassignments of the pooled values, as for PermutationTest
+// with the Greater alternative. If the number of distinct reassignments is
+// at most n the p-value is exact, otherwise the permutations are drawn from
+// src, or from the global random source if src is nil.
+//
+// EnergyTest panics if x or y is empty or if n is not positive.
+func EnergyTest(x, y []float64, n int, src *rand.Rand) (t, p float64) {
+	e, p := PermutationTest(x, y, EnergyDistance, n, Greater, src)
+	nx, ny := float64(len(x)), float64(len(y))
+	return nx * ny / (nx + ny) * e, p
+}
+
+// binomialAtMost returns the binomial coefficient n choose k and true if it
+// is at most max, and false otherwise.
+func binomialAtMost(n, k, max int) (int, bool) {
+	if k > n-k {
+		k = n - k
+	}
+	// The partial products c = (n-k+1)...(n-k+i)/i! are integers that
+	// increase with i, so the product can stop once it exceeds max.
+	c := 1
+	for i := 1; i <= k; i++ {
+		if float64(c)*float64(n-k+i)/float64(i) > float64(max) {
+			return 0, false
+		}
+		c = c * (n - k + i) / i
+	}
+	return c, true
+}
+
+// nextCombination advances comb, an increasing sequence of indices in [0, n),
+// to the next in lexicographic order, and returns false if comb was the last.
+func nextCombination(comb []int, n int) bool {
+	k := len(comb)
+	i := k - 1
+	for i >= 0 && comb[i] == n-k+i {
+		i--
+	}
+	if i < 0 {
+		return false
+	}
+	comb[i]++
+	for j := i + 1; j < k; j++ {
+		comb[j] = comb[j-1] + 1
+	}
+	return true
+}
+
+// MeanDifference returns the difference of the means of x and y, a statistic
+// for PermutationTest.
+func MeanDifference(x, y []float64) float64 {
+	return Mean(x, nil) - Mean(y, nil)
+}
+
+// MedianDifference returns the difference of the medians of x and y, as
+// computed by WeightedMedian, a statistic for PermutationTest.
+func MedianDifference(x, y []float64) float64 {
+	return WeightedMedian(x, nil) - WeightedMedian(y, nil)
+}
@@ -0,0 +1,503 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+// median returns the median of x, sorting x.
+func median(x []float64) float64 {
+	sort.Float64s(x)
+	return Quantile(0.5, Empirical, x, nil)
+}
+
+func TestBootstrap(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float64, 101)
+	y := make([]float64, len(x))
+	for i := range x {
+		x[i] = rnd.ExpFloat64()
+		y[i] = x[i] + rnd.NormFloat64()
+	}
+	mean := func(x []float64) float64 { return Mean(x, nil) }
+
+	// The result depends only on the source, and not on the parallelism.
+	old := Parallel
+	defer func() { Parallel = old }()
+	want := make(map[int][2][]float64)
+	for _, workers := range []int{1, 2, 7} {
+		Parallel = ParallelConfig{Workers: workers, MinWork: 0}
+		// Sizes around the number of resamples drawn at once.
+		for _, n := range []int{1, 63, 64, 200} {
+			got := Bootstrap(x, median, n, rand.New(rand.NewSource(2)))
+			gotPaired := BootstrapPaired(x, y, pairedCorrelation, n, rand.New(rand.NewSource(3)))
+			w, ok := want[n]
+			if !ok {
+				want[n] = [2][]float64{got, gotPaired}
+				continue
+			}
+			if !floats.Same(got, w[0]) {
+				t.Errorf("workers = %d, n = %d: bootstrap distribution differs", workers, n)
+			}
+			if !floats.Same(gotPaired, w[1]) {
+				t.Errorf("workers = %d, n = %d: paired bootstrap distribution differs", workers, n)
+			}
+		}
+	}
+	Parallel = old
+
+	// Resamples are drawn with replacement from x.
+	xs := append([]float64(nil), x...)
+	sort.Float64s(xs)
+	for _, m := range Bootstrap(x, median, 100, rnd) {
+		if i := sort.SearchFloat64s(xs, m); i == len(xs) || xs[i] != m {
+			t.Errorf("bootstrap median %v is not a value of x", m)
+		}
+	}
+	// The standard deviation of the bootstrap means estimates the standard
+	// error of the mean.
+	boot := Bootstrap(x, mean, 5000, rnd)
+	se := StdDev(x, nil) / math.Sqrt(float64(len(x)))
+	if got := StdDev(boot, nil); math.Abs(got-se) > 0.05*se {
+		t.Errorf("bootstrap standard error mismatch: got %v, want about %v", got, se)
+	}
+	// Resampled pairs stay together.
+	for _, r := range BootstrapPaired(x, x, pairedCorrelation, 20, rnd) {
+		if math.Abs(r-1) > 1e-14 {
+			t.Errorf("correlation of resampled identical pairs not one: %v", r)
+		}
+	}
+
+	// A single resample of a large sample holds one buffer, not a round of
+	// bootstrapRound.
+	big := make([]float64, 100000)
+	if got, max := allocatedBytes(func() { Bootstrap(big, mean, 1, rnd) }), 3*8*len(big); got > uint64(max) {
+		t.Errorf("Bootstrap of one resample allocated %d bytes, want at most %d", got, max)
+	}
+	if got, max := allocatedBytes(func() { BootstrapPaired(big, big, MeanDifference, 1, rnd) }), 4*8*len(big); got > uint64(max) {
+		t.Errorf("BootstrapPaired of one resample allocated %d bytes, want at most %d", got, max)
+	}
+
+	for _, f := range []func(){
+		func() { Bootstrap(nil, mean, 10, nil) },
+		func() { Bootstrap(x, mean, 0, nil) },
+		func() { BootstrapPaired(x, y[1:], pairedCorrelation, 10, nil) },
+		func() { BootstrapPaired(nil, nil, pairedCorrelation, 10, nil) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestJackknife(t *testing.T) {
+	x := []float64{3, 1, 4, 1, 5, 9, 2, 6}
+	xCopy := append([]float64(nil), x...)
+	sum := floats.Sum(x)
+	mean := func(x []float64) float64 { return Mean(x, nil) }
+	values, bias, stdErr := Jackknife(x, mean)
+	for i, v := range x {
+		if want := (sum - v) / float64(len(x)-1); math.Abs(values[i]-want) > 1e-14 {
+			t.Errorf("jackknife mean %d mismatch: got %v, want %v", i, values[i], want)
+		}
+	}
+	// The mean is unbiased, and its jackknife standard error is the usual
+	// one.
+	if want := StdDev(x, nil) / math.Sqrt(float64(len(x))); math.Abs(bias) > 1e-14 || math.Abs(stdErr-want) > 1e-14 {
+		t.Errorf("jackknife of the mean: got bias %v and standard error %v, want 0 and %v", bias, stdErr, want)
+	}
+	// The bias of the variance with divisor n is -s²/n, and the jackknife
+	// corrects it exactly.
+	popVar := func(x []float64) float64 {
+		n := float64(len(x))
+		return Variance(x, nil) * (n - 1) / n
+	}
+	_, bias, _ = Jackknife(x, popVar)
+	if got, want := popVar(append([]float64(nil), x...))-bias, Variance(x, nil); math.Abs(got-want) > 1e-13 {
+		t.Errorf("bias corrected variance mismatch: got %v, want %v", got, want)
+	}
+	if !floats.Same(x, xCopy) {
+		t.Errorf("x modified")
+	}
+
+	y := []float64{2, 7, 1, 8, 2, 8, 1, 8}
+	dot := floats.Dot(x, y)
+	values, bias, _ = JackknifePaired(x, y, func(x, y []float64) float64 { return floats.Dot(x, y) })
+	var sumValues float64
+	for i := range x {
+		want := dot - x[i]*y[i]
+		if values[i] != want {
+			t.Errorf("paired jackknife %d mismatch: got %v, want %v", i, values[i], want)
+		}
+		sumValues += want
+	}
+	n := float64(len(x))
+	if want := (n - 1) * (sumValues/n - dot); math.Abs(bias-want) > 1e-12 {
+		t.Errorf("paired jackknife bias mismatch: got %v, want %v", bias, want)
+	}
+
+	for _, f := range []func(){
+		func() { Jackknife([]float64{1}, median) },
+		func() { JackknifePaired(x, y[1:], pairedCorrelation) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+// pairedCorrelation is the unweighted correlation, with the signature of a
+// paired statistic.
+func pairedCorrelation(x, y []float64) float64 { return Correlation(x, y, nil) }
+
+func TestBootstrapCI(t *testing.T) {
+	boot := make([]float64, 100)
+	for i := range boot {
+		// 100, 99, ..., 1 so that the input is not sorted.
+		boot[i] = float64(len(boot) - i)
+	}
+	bootCopy := append([]float64(nil), boot...)
+	if lo, hi := BootstrapPercentileCI(boot, 0.9); lo != 5 || hi != 95 {
+		t.Errorf("percentile interval mismatch: got [%v, %v], want [5, 95]", lo, hi)
+	}
+	if lo, hi := BootstrapBasicCI(boot, 40, 0.9); lo != -15 || hi != 75 {
+		t.Errorf("basic interval mismatch: got [%v, %v], want [-15, 75]", lo, hi)
+	}
+	// Without bias or acceleration, the BCa interval is the percentile
+	// interval.
+	if lo, hi := BootstrapBCaCI(boot, 50.5, []float64{1, 2, 3}, 0.9); lo != 5 || hi != 95 {
+		t.Errorf("unadjusted BCa interval mismatch: got [%v, %v], want [5, 95]", lo, hi)
+	}
+	// With 30 of 100 values below the estimate, the interval moves down.
+	// z_0 = Φ⁻¹(0.3) gives the levels Φ(2 z_0 ∓ 1.645), 0.0035 and 0.72.
+	if lo, hi := BootstrapBCaCI(boot, 30.5, []float64{1, 2, 3}, 0.9); lo != 1 || hi != 73 {
+		t.Errorf("bias corrected BCa interval mismatch: got [%v, %v], want [1, 73]", lo, hi)
+	}
+	// A jackknife distribution skewed to the left gives a positive
+	// acceleration, which stretches the interval to the right.
+	lo, hi := BootstrapBCaCI(boot, 50.5, []float64{10, 10, 10, 0}, 0.9)
+	if !(lo > 5 && hi > 95) {
+		t.Errorf("accelerated BCa interval not stretched right: got [%v, %v]", lo, hi)
+	}
+	if !floats.Same(boot, bootCopy) {
+		t.Errorf("bootstrap distribution modified")
+	}
+
+	if lo, hi := BootstrapBCaCI(boot, 0, []float64{1, 2}, 0.9); !math.IsNaN(lo) || !math.IsNaN(hi) {
+		t.Errorf("expected NaN interval for estimate below the distribution")
+	}
+	if lo, hi := BootstrapPercentileCI([]float64{1, math.NaN()}, 0.9); !math.IsNaN(lo) || !math.IsNaN(hi) {
+		t.Errorf("expected NaN interval for NaN input")
+	}
+
+	// The intervals for the mean of normal data cover the true mean at
+	// about the nominal level.
+	rnd := rand.New(rand.NewSource(1))
+	mean := func(x []float64) float64 { return Mean(x, nil) }
+	const trials = 300
+	var covered [3]int
+	x := make([]float64, 40)
+	for k := 0; k < trials; k++ {
+		for i := range x {
+			x[i] = rnd.NormFloat64()
+		}
+		b := Bootstrap(x, mean, 500, rnd)
+		est := Mean(x, nil)
+		for j, ci := range [][2]float64{
+			twoFloats(BootstrapPercentileCI(b, 0.9)),
+			twoFloats(BootstrapBasicCI(b, est, 0.9)),
+			twoFloats(BootstrapBCaCI(b, est, jackknifeValues(x, mean), 0.9)),
+		} {
+			if ci[0] <= 0 && 0 <= ci[1] {
+				covered[j]++
+			}
+		}
+	}
+	for j, c := range covered {
+		if frac := float64(c) / trials; math.Abs(frac-0.9) > 0.06 {
+			t.Errorf("interval %d: coverage %v, want about 0.9", j, frac)
+		}
+	}
+
+	for _, f := range []func(){
+		func() { BootstrapPercentileCI(nil, 0.9) },
+		func() { BootstrapPercentileCI(boot, 1) },
+		func() { BootstrapBasicCI(boot, 1, 0) },
+		func() { BootstrapBCaCI(boot, 1, nil, 0.9) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func twoFloats(a, b float64) [2]float64 { return [2]float64{a, b} }
+
+func TestPermutationTest(t *testing.T) {
+	x := []float64{1, 2, 3}
+	y := []float64{4, 5, 6, 7}
+	for _, test := range []struct {
+		alt  Alternative
+		want float64
+	}{
+		// Of the 35 assignments, only the observed one has a mean difference
+		// of -3.5 or less, and its mirror image 3.5.
+		{Less, 1.0 / 35},
+		{Greater, 1},
+		{TwoSided, 2.0 / 35},
+	} {
+		obs, p := PermutationTest(x, y, MeanDifference, 1000, test.alt, nil)
+		if obs != -3.5 {
+			t.Errorf("observed statistic mismatch: got %v, want -3.5", obs)
+		}
+		if math.Abs(p-test.want) > 1e-15 {
+			t.Errorf("alternative %d: p-value mismatch: got %v, want %v", test.alt, p, test.want)
+		}
+	}
+
+	// The exhaustive p-value agrees with a direct enumeration.
+	rnd := rand.New(rand.NewSource(1))
+	x = make([]float64, 5)
+	y = make([]float64, 6)
+	for i := range x {
+		x[i] = rnd.NormFloat64()
+	}
+	for i := range y {
+		y[i] = rnd.NormFloat64() + 1
+	}
+	pooled := append(append([]float64(nil), x...), y...)
+	obs := MedianDifference(x, y)
+	var less, total int
+	for mask := 0; mask < 1<<uint(len(pooled)); mask++ {
+		var gx, gy []float64
+		for i, v := range pooled {
+			if mask&(1<<uint(i)) != 0 {
+				gx = append(gx, v)
+			} else {
+				gy = append(gy, v)
+			}
+		}
+		if len(gx) != len(x) {
+			continue
+		}
+		total++
+		if MedianDifference(gx, gy) <= obs {
+			less++
+		}
+	}
+	xCopy := append([]float64(nil), x...)
+	gotObs, p := PermutationTest(x, y, MedianDifference, total, Less, nil)
+	if gotObs != obs || p != float64(less)/float64(total) {
+		t.Errorf("exhaustive test mismatch: got %v with p %v, want %v with p %v", gotObs, p, obs, float64(less)/float64(total))
+	}
+	if !floats.Same(x, xCopy) {
+		t.Errorf("x modified")
+	}
+
+	// Random permutations estimate the exact p-value, and the result does
+	// not depend on the parallelism.
+	_, exact := PermutationTest(x, y, MedianDifference, total, TwoSided, nil)
+	old := Parallel
+	defer func() { Parallel = old }()
+	var want float64
+	for k, workers := range []int{1, 3} {
+		Parallel = ParallelConfig{Workers: workers, MinWork: 0}
+		_, p := PermutationTest(x, y, MedianDifference, total-1, TwoSided, rand.New(rand.NewSource(2)))
+		if k == 0 {
+			want = p
+		} else if p != want {
+			t.Errorf("workers = %d: p-value differs: got %v, want %v", workers, p, want)
+		}
+	}
+	Parallel = old
+	_, p = PermutationTest(x, y, MedianDifference, 20000, TwoSided, rnd)
+	if math.Abs(p-exact) > 0.01 {
+		t.Errorf("random permutation p-value %v too far from exact %v", p, exact)
+	}
+
+	// The random p-value counts the observed assignment.
+	x = make([]float64, 20)
+	y = make([]float64, 20)
+	for i := range x {
+		x[i] = float64(i)
+		y[i] = float64(i + 20)
+	}
+	if _, p := PermutationTest(x, y, MeanDifference, 999, Less, rnd); p != 1.0/1000 {
+		t.Errorf("p-value of separated samples: got %v, want 0.001", p)
+	}
+
+	// A single permutation of large samples holds one buffer, not a round
+	// of bootstrapRound.
+	big := make([]float64, 50000)
+	if got, max := allocatedBytes(func() { PermutationTest(big, big, MeanDifference, 1, TwoSided, rnd) }), 4*8*2*len(big); got > uint64(max) {
+		t.Errorf("PermutationTest of one permutation allocated %d bytes, want at most %d", got, max)
+	}
+
+	for _, f := range []func(){
+		func() { PermutationTest(nil, y, MeanDifference, 10, TwoSided, nil) },
+		func() { PermutationTest(x, nil, MeanDifference, 10, TwoSided, nil) },
+		func() { PermutationTest(x, y, MeanDifference, 0, TwoSided, nil) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestResampleCtx(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float64, 30)
+	y := make([]float64, 25)
+	for i := range x {
+		x[i] = rnd.NormFloat64()
+	}
+	for i := range y {
+		y[i] = rnd.NormFloat64() + 0.5
+	}
+	mean := func(x []float64) float64 { return Mean(x, nil) }
+
+	// A context that is never cancelled gives the results of the functions
+	// without a context for the same random state.
+	want := Bootstrap(x, mean, 500, rand.New(rand.NewSource(2)))
+	got, err := BootstrapCtx(context.Background(), x, mean, 500, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !floats.Equal(got, want) {
+		t.Errorf("background context changed bootstrap distribution")
+	}
+	wantObs, wantP := PermutationTest(x, y, MeanDifference, 500, TwoSided, rand.New(rand.NewSource(3)))
+	obs, p, err := PermutationTestCtx(context.Background(), x, y, MeanDifference, 500, TwoSided, rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if obs != wantObs || p != wantP {
+		t.Errorf("background context changed permutation test: got (%v, %v), want (%v, %v)", obs, p, wantObs, wantP)
+	}
+
+	// A cancelled context returns before any statistic is computed.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int64
+	count := func(x []float64) float64 {
+		atomic.AddInt64(&calls, 1)
+		return 0
+	}
+	if got, err := BootstrapCtx(ctx, x, count, 1000, nil); got != nil || err != context.Canceled {
+		t.Errorf("cancelled bootstrap: got (%v, %v), want (nil, %v)", got, err, context.Canceled)
+	}
+	obs, p, err = PermutationTestCtx(ctx, x, y, func(a, b []float64) float64 { return count(a) }, 1000, TwoSided, nil)
+	if !math.IsNaN(p) || err != context.Canceled {
+		t.Errorf("cancelled permutation test: got (%v, %v, %v), want NaN p-value and %v", obs, p, err, context.Canceled)
+	}
+	// The observed statistic is computed before the first check.
+	if calls != 1 {
+		t.Errorf("statistic computed %d times after cancellation", calls)
+	}
+
+	// Cancellation during the resampling stops it within a round.
+	const stopAt = 100
+	for _, f := range []func(ctx context.Context, statistic func([]float64) float64) error{
+		func(ctx context.Context, statistic func([]float64) float64) error {
+			_, err := BootstrapCtx(ctx, x, statistic, 100000, nil)
+			return err
+		},
+		func(ctx context.Context, statistic func([]float64) float64) error {
+			_, _, err := PermutationTestCtx(ctx, x, y, func(a, b []float64) float64 { return statistic(a) }, 100000, TwoSided, nil)
+			return err
+		},
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls = 0
+		err := f(ctx, func(x []float64) float64 {
+			if atomic.AddInt64(&calls, 1) == stopAt {
+				cancel()
+			}
+			return 0
+		})
+		cancel()
+		if err != context.Canceled {
+			t.Errorf("unexpected error: got %v, want %v", err, context.Canceled)
+		}
+		if calls > stopAt+bootstrapRound {
+			t.Errorf("statistic computed %d times after cancellation at %d", calls, stopAt)
+		}
+	}
+}
+
+func TestEnergyTest(t *testing.T) {
+	// Of the 20 reassignments, only the observed one and its mirror
+	// image separate the samples.
+	x := []float64{1, 2, 3}
+	y := []float64{10, 11, 12}
+	stat, p := EnergyTest(x, y, 100, nil)
+	if want := 1.5 * (18 - 16.0/9); math.Abs(stat-want) > 1e-13 {
+		t.Errorf("energy statistic mismatch. Want %v, got %v", want, stat)
+	}
+	if p != 0.1 {
+		t.Errorf("exact p-value mismatch. Want 0.1, got %v", p)
+	}
+
+	// The test detects a difference of scale, which the means do not show.
+	rnd := rand.New(rand.NewSource(1))
+	x = make([]float64, 100)
+	y = make([]float64, 100)
+	for i := range x {
+		x[i] = rnd.NormFloat64()
+		y[i] = 4 * rnd.NormFloat64()
+	}
+	if _, p := EnergyTest(x, y, 199, rnd); p != 1.0/200 {
+		t.Errorf("p-value of samples of different scales: got %v, want 0.005", p)
+	}
+	if _, p := PermutationTest(x, y, MeanDifference, 199, TwoSided, rnd); p < 0.05 {
+		t.Errorf("unexpected significant difference of the means: p = %v", p)
+	}
+
+	if !Panics(func() { EnergyTest(x, nil, 10, nil) }) {
+		t.Errorf("EnergyTest did not panic with empty sample")
+	}
+}
+
+func TestBinomialAtMost(t *testing.T) {
+	for _, test := range []struct {
+		n, k, max int
+		want      int
+		ok        bool
+	}{
+		{7, 3, 35, 35, true},
+		{7, 3, 34, 0, false},
+		{10, 0, 1, 1, true},
+		{20, 10, 1 << 20, 184756, true},
+		{60, 30, 1 << 40, 0, false},
+	} {
+		got, ok := binomialAtMost(test.n, test.k, test.max)
+		if got != test.want || ok != test.ok {
+			t.Errorf("binomialAtMost(%d, %d, %d): got %d %t, want %d %t", test.n, test.k, test.max, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+// allocatedBytes returns the number of bytes allocated by f.
+func allocatedBytes(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func jackknifeValues(x []float64, statistic func([]float64) float64) []float64 {
+	values, _, _ := Jackknife(x, statistic)
+	return values
+}
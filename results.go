@@ -0,0 +1,383 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// The result types below are encoded to JSON as objects with the keys given
+// by their json tags. Since encoding/json cannot encode NaN and infinite
+// values, a NaN is encoded as null and the infinities as the strings "+Inf"
+// and "-Inf", and the decoders accept the same forms. Matrices are encoded as
+// arrays of rows, and a nil matrix as null.
+
+// TTestResult is the result of a t-test.
+type TTestResult struct {
+	// T is the t statistic and DF its degrees of freedom.
+	T  float64 `json:"t"`
+	DF float64 `json:"df"`
+
+	// P is the p-value of the test.
+	P float64 `json:"p"`
+
+	// Estimate is the estimated mean or difference in means, and StdErr
+	// its standard error.
+	Estimate float64 `json:"estimate"`
+	StdErr   float64 `json:"std_err"`
+
+	// CI is the confidence interval for the estimate at the confidence
+	// level Level.
+	CI    [2]float64 `json:"ci"`
+	Level float64    `json:"level"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r TTestResult) MarshalJSON() ([]byte, error) { return marshalResult(r) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *TTestResult) UnmarshalJSON(b []byte) error { return unmarshalResult(b, r) }
+
+// ANOVAResult is the result of an analysis of variance.
+type ANOVAResult struct {
+	// F is the F statistic, with DFBetween and DFWithin degrees of freedom,
+	// and P its p-value.
+	F         float64 `json:"f"`
+	DFBetween float64 `json:"df_between"`
+	DFWithin  float64 `json:"df_within"`
+	P         float64 `json:"p"`
+
+	// SSBetween and SSWithin are the between and within group sums of
+	// squares, and MSBetween and MSWithin the corresponding mean squares.
+	SSBetween float64 `json:"ss_between"`
+	SSWithin  float64 `json:"ss_within"`
+	MSBetween float64 `json:"ms_between"`
+	MSWithin  float64 `json:"ms_within"`
+
+	// EtaSquared and OmegaSquared are the η² and ω² effect sizes.
+	EtaSquared   float64 `json:"eta_squared"`
+	OmegaSquared float64 `json:"omega_squared"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r ANOVAResult) MarshalJSON() ([]byte, error) { return marshalResult(r) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *ANOVAResult) UnmarshalJSON(b []byte) error { return unmarshalResult(b, r) }
+
+// RegressionSummary is the summary of a linear regression fit.
+type RegressionSummary struct {
+	// N is the number of observations and DF the residual degrees of
+	// freedom.
+	N  int     `json:"n"`
+	DF float64 `json:"df"`
+
+	// Coefficients holds the estimated coefficients, with StdErrs their
+	// standard errors, TStats their t statistics and PValues the p-values
+	// of the tests that they are zero.
+	Coefficients []float64 `json:"coefficients"`
+	StdErrs      []float64 `json:"std_errs"`
+	TStats       []float64 `json:"t_stats"`
+	PValues      []float64 `json:"p_values"`
+
+	// Covariance is the estimated covariance matrix of the coefficients.
+	Covariance *mat64.SymDense `json:"covariance"`
+
+	// R2 and AdjR2 are the coefficient of determination and its adjusted
+	// form, and ResidualStdErr is the residual standard error.
+	R2             float64 `json:"r2"`
+	AdjR2          float64 `json:"adj_r2"`
+	ResidualStdErr float64 `json:"residual_std_err"`
+
+	// F is the F statistic of the test that all coefficients other than
+	// the intercept are zero, and FP its p-value.
+	F  float64 `json:"f"`
+	FP float64 `json:"f_p"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r RegressionSummary) MarshalJSON() ([]byte, error) { return marshalResult(r) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *RegressionSummary) UnmarshalJSON(b []byte) error { return unmarshalResult(b, r) }
+
+// LinearRegressionResult is the result of a simple linear regression.
+type LinearRegressionResult struct {
+	// Alpha and Beta are the intercept and slope of the fitted line.
+	Alpha float64 `json:"alpha"`
+	Beta  float64 `json:"beta"`
+
+	// AlphaStdErr and BetaStdErr are the standard errors of Alpha and
+	// Beta, AlphaT and BetaT their t statistics and AlphaP and BetaP the
+	// p-values of the tests that they are zero. For a regression through
+	// the origin, the intercept is not estimated and its statistics are
+	// NaN.
+	AlphaStdErr float64 `json:"alpha_std_err"`
+	BetaStdErr  float64 `json:"beta_std_err"`
+	AlphaT      float64 `json:"alpha_t"`
+	BetaT       float64 `json:"beta_t"`
+	AlphaP      float64 `json:"alpha_p"`
+	BetaP       float64 `json:"beta_p"`
+
+	// AlphaCI and BetaCI are the confidence intervals for Alpha and Beta
+	// at the confidence level Level.
+	AlphaCI [2]float64 `json:"alpha_ci"`
+	BetaCI  [2]float64 `json:"beta_ci"`
+	Level   float64    `json:"level"`
+
+	// DF is the residual degrees of freedom, R2 the coefficient of
+	// determination and ResidualStdErr the residual standard error.
+	DF             float64 `json:"df"`
+	R2             float64 `json:"r2"`
+	ResidualStdErr float64 `json:"residual_std_err"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r LinearRegressionResult) MarshalJSON() ([]byte, error) { return marshalResult(r) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *LinearRegressionResult) UnmarshalJSON(b []byte) error { return unmarshalResult(b, r) }
+
+// Description holds the summary statistics of a sample returned by Describe.
+type Description struct {
+	// N is the number of observations, or the sum of the weights.
+	N float64 `json:"n"`
+
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	Min    float64 `json:"min"`
+
+	// Q1, Median and Q3 are the quantiles at 0.25, 0.5 and 0.75, as
+	// returned by Quantile with kind Empirical.
+	Q1     float64 `json:"q1"`
+	Median float64 `json:"median"`
+	Q3     float64 `json:"q3"`
+
+	Max        float64 `json:"max"`
+	Skew       float64 `json:"skew"`
+	ExKurtosis float64 `json:"ex_kurtosis"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d Description) MarshalJSON() ([]byte, error) { return marshalResult(d) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *Description) UnmarshalJSON(b []byte) error { return unmarshalResult(b, d) }
+
+// Describe returns the summary statistics of the sample x. If weights is nil
+// then all of the weights are 1. If weights is not nil, then len(x) must equal
+// len(weights). x and weights are not modified. Describe panics if x is empty.
+func Describe(x, weights []float64) Description {
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	checkWeightLength(x, weights)
+	xs := make([]float64, len(x))
+	copy(xs, x)
+	var ws []float64
+	n := float64(len(x))
+	if weights != nil {
+		ws = make([]float64, len(weights))
+		copy(ws, weights)
+		n = 0
+		for _, w := range ws {
+			n += w
+		}
+	}
+	SortWeighted(xs, ws)
+	mean, std := MeanStdDev(xs, ws)
+	return Description{
+		N:          n,
+		Mean:       mean,
+		StdDev:     std,
+		Min:        Min(xs),
+		Q1:         Quantile(0.25, Empirical, xs, ws),
+		Median:     Quantile(0.5, Empirical, xs, ws),
+		Q3:         Quantile(0.75, Empirical, xs, ws),
+		Max:        Max(xs),
+		Skew:       Skew(xs, ws),
+		ExKurtosis: ExKurtosis(xs, ws),
+	}
+}
+
+// jsonFloat is a float64 with the JSON encoding of non-finite values
+// described above.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	switch {
+	case math.IsNaN(v):
+		return []byte("null"), nil
+	case math.IsInf(v, 1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Inf"`), nil
+	}
+	return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+}
+
+func (f *jsonFloat) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case "null":
+		*f = jsonFloat(math.NaN())
+		return nil
+	case `"+Inf"`:
+		*f = jsonFloat(math.Inf(1))
+		return nil
+	case `"-Inf"`:
+		*f = jsonFloat(math.Inf(-1))
+		return nil
+	}
+	v, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return errors.New("stat: invalid JSON number " + string(b))
+	}
+	*f = jsonFloat(v)
+	return nil
+}
+
+var symDenseType = reflect.TypeOf((*mat64.SymDense)(nil))
+
+// marshalResult encodes the struct v as a JSON object with the keys given by
+// the json tags of its fields, in field order.
+func marshalResult(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	rt := rv.Type()
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := 0; i < rt.NumField(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(rt.Field(i).Tag.Get("json"))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		b, err := json.Marshal(toJSONValue(rv.Field(i)))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// toJSONValue returns the value to encode for the field f, replacing float64
+// values by jsonFloat values.
+func toJSONValue(f reflect.Value) interface{} {
+	switch {
+	case f.Kind() == reflect.Float64:
+		return jsonFloat(f.Float())
+	case (f.Kind() == reflect.Slice || f.Kind() == reflect.Array) && f.Type().Elem().Kind() == reflect.Float64:
+		if f.Kind() == reflect.Slice && f.IsNil() {
+			return nil
+		}
+		s := make([]jsonFloat, f.Len())
+		for i := range s {
+			s[i] = jsonFloat(f.Index(i).Float())
+		}
+		return s
+	case f.Type() == symDenseType:
+		if f.IsNil() {
+			return nil
+		}
+		m := f.Interface().(*mat64.SymDense)
+		n := m.Symmetric()
+		rows := make([][]jsonFloat, n)
+		for i := range rows {
+			rows[i] = make([]jsonFloat, n)
+			for j := range rows[i] {
+				rows[i][j] = jsonFloat(m.At(i, j))
+			}
+		}
+		return rows
+	}
+	return f.Interface()
+}
+
+// unmarshalResult decodes the JSON object in b, as encoded by marshalResult,
+// into the struct pointed to by v. Keys absent from b leave the corresponding
+// fields unchanged.
+func unmarshalResult(b []byte, v interface{}) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v).Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		raw, ok := obj[rt.Field(i).Tag.Get("json")]
+		if !ok {
+			continue
+		}
+		if err := fromJSONValue(raw, rv.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fromJSONValue decodes raw into the field f.
+func fromJSONValue(raw json.RawMessage, f reflect.Value) error {
+	switch {
+	case f.Kind() == reflect.Float64:
+		var v jsonFloat
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		f.SetFloat(float64(v))
+	case (f.Kind() == reflect.Slice || f.Kind() == reflect.Array) && f.Type().Elem().Kind() == reflect.Float64:
+		var s []jsonFloat
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		if f.Kind() == reflect.Slice {
+			if s == nil {
+				f.Set(reflect.Zero(f.Type()))
+				return nil
+			}
+			f.Set(reflect.MakeSlice(f.Type(), len(s), len(s)))
+		} else if len(s) != f.Len() {
+			return errors.New("stat: JSON array length mismatch")
+		}
+		for i, v := range s {
+			f.Index(i).SetFloat(float64(v))
+		}
+	case f.Type() == symDenseType:
+		var rows [][]jsonFloat
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return err
+		}
+		if rows == nil {
+			f.Set(reflect.Zero(f.Type()))
+			return nil
+		}
+		n := len(rows)
+		m := mat64.NewSymDense(n, nil)
+		for i, row := range rows {
+			if len(row) != n {
+				return errors.New("stat: JSON matrix not square")
+			}
+			for j := i; j < n; j++ {
+				m.SetSym(i, j, float64(row[j]))
+			}
+		}
+		f.Set(reflect.ValueOf(m))
+	default:
+		return json.Unmarshal(raw, f.Addr().Interface())
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// sameResult reports whether a and b are equal, treating NaNs as equal and
+// comparing matrices by value.
+func sameResult(a, b reflect.Value) bool {
+	switch {
+	case a.Kind() == reflect.Float64:
+		x, y := a.Float(), b.Float()
+		return x == y || (math.IsNaN(x) && math.IsNaN(y))
+	case a.Type() == symDenseType:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		ma, mb := a.Interface().(*mat64.SymDense), b.Interface().(*mat64.SymDense)
+		n := ma.Symmetric()
+		if mb.Symmetric() != n {
+			return false
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if ma.At(i, j) != mb.At(i, j) {
+					return false
+				}
+			}
+		}
+		return true
+	case a.Kind() == reflect.Slice || a.Kind() == reflect.Array:
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			return false
+		}
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !sameResult(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case a.Kind() == reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !sameResult(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func TestResultsJSON(t *testing.T) {
+	nan := math.NaN()
+	inf := math.Inf(1)
+	for _, test := range []struct {
+		golden string
+		value  interface{}
+		decode interface{}
+	}{
+		{
+			golden: "ttest.json",
+			value: TTestResult{
+				T: 2.5, DF: 17.3, P: 0.0226, Estimate: 1.25, StdErr: 0.5,
+				CI: [2]float64{0.197, 2.303}, Level: 0.95,
+			},
+			decode: &TTestResult{},
+		},
+		{
+			// A degenerate test with zero variance.
+			golden: "ttest_degenerate.json",
+			value: TTestResult{
+				T: inf, DF: 4, P: 0, Estimate: 1, StdErr: 0,
+				CI: [2]float64{1, 1}, Level: 0.99,
+			},
+			decode: &TTestResult{},
+		},
+		{
+			golden: "anova.json",
+			value: ANOVAResult{
+				F: 4.2, DFBetween: 2, DFWithin: 12, P: 0.0412,
+				SSBetween: 8.4, SSWithin: 12, MSBetween: 4.2, MSWithin: 1,
+				EtaSquared: 0.4118, OmegaSquared: 0.2991,
+			},
+			decode: &ANOVAResult{},
+		},
+		{
+			golden: "regression.json",
+			value: RegressionSummary{
+				N:              10,
+				DF:             8,
+				Coefficients:   []float64{1.5, -0.25},
+				StdErrs:        []float64{0.3, 0.05},
+				TStats:         []float64{5, -5},
+				PValues:        []float64{0.00105, 0.00105},
+				Covariance:     mat64.NewSymDense(2, []float64{0.09, -0.01, -0.01, 0.0025}),
+				R2:             0.757,
+				AdjR2:          0.726,
+				ResidualStdErr: 0.41,
+				F:              25,
+				FP:             0.00105,
+			},
+			decode: &RegressionSummary{},
+		},
+		{
+			// A saturated fit, with no residual degrees of freedom.
+			golden: "regression_saturated.json",
+			value: RegressionSummary{
+				N:              2,
+				DF:             0,
+				Coefficients:   []float64{1, 2},
+				StdErrs:        []float64{nan, nan},
+				TStats:         []float64{nan, nan},
+				PValues:        []float64{nan, nan},
+				R2:             1,
+				AdjR2:          nan,
+				ResidualStdErr: nan,
+				F:              nan,
+				FP:             nan,
+			},
+			decode: &RegressionSummary{},
+		},
+		{
+			// A regression through the origin, with no intercept.
+			golden: "linearregression.json",
+			value: LinearRegressionResult{
+				Alpha: 0, Beta: 1.6, AlphaStdErr: nan, BetaStdErr: 0.04,
+				AlphaT: nan, BetaT: 40, AlphaP: nan, BetaP: 1.8e-11,
+				AlphaCI: [2]float64{nan, nan}, BetaCI: [2]float64{1.51, 1.69}, Level: 0.95,
+				DF: 9, R2: 0.994, ResidualStdErr: 0.72,
+			},
+			decode: &LinearRegressionResult{},
+		},
+		{
+			golden: "description.json",
+			value: Description{
+				N: 5, Mean: 4, StdDev: 3.5355339059327378, Min: 1,
+				Q1: 2, Median: 3, Q3: 4, Max: 10,
+				Skew: 1.697056274847714, ExKurtosis: 3.152,
+			},
+			decode: &Description{},
+		},
+	} {
+		got, err := json.MarshalIndent(test.value, "", "\t")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.golden, err)
+			continue
+		}
+		got = append(got, '\n')
+		path := filepath.Join("testdata", test.golden)
+		if *updateGolden {
+			if err := ioutil.WriteFile(path, got, 0644); err != nil {
+				t.Fatalf("%s: failed to write golden file: %v", test.golden, err)
+			}
+		}
+		want, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s: failed to read golden file: %v", test.golden, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: encoding mismatch:\ngot:\n%s\nwant:\n%s", test.golden, got, want)
+		}
+
+		if err := json.Unmarshal(want, test.decode); err != nil {
+			t.Errorf("%s: unexpected decoding error: %v", test.golden, err)
+			continue
+		}
+		if !sameResult(reflect.ValueOf(test.decode).Elem(), reflect.ValueOf(test.value)) {
+			t.Errorf("%s: round trip mismatch: got %+v, want %+v", test.golden, reflect.ValueOf(test.decode).Elem(), test.value)
+		}
+	}
+
+	for _, s := range []string{
+		`{"t": "x"}`,
+		`{"ci": [1, 2, 3]}`,
+		`[1]`,
+	} {
+		var r TTestResult
+		if err := json.Unmarshal([]byte(s), &r); err == nil {
+			t.Errorf("expected error decoding %s", s)
+		}
+	}
+	var r RegressionSummary
+	if err := json.Unmarshal([]byte(`{"covariance": [[1, 2], [3]]}`), &r); err == nil {
+		t.Errorf("expected error decoding non-square matrix")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	x := []float64{4, 1, 3, 2, 10}
+	orig := []float64{4, 1, 3, 2, 10}
+	d := Describe(x, nil)
+	if !reflect.DeepEqual(x, orig) {
+		t.Errorf("Describe modified its input")
+	}
+	want := Description{
+		N: 5, Mean: 4, StdDev: math.Sqrt(12.5), Min: 1,
+		Q1: 2, Median: 3, Q3: 4, Max: 10,
+		Skew: Skew(x, nil), ExKurtosis: ExKurtosis(x, nil),
+	}
+	if d != want {
+		t.Errorf("Describe mismatch: got %+v, want %+v", d, want)
+	}
+
+	// Integer weights are equivalent to repeated observations.
+	dw := Describe([]float64{3, 1, 2}, []float64{1, 2, 1})
+	dr := Describe([]float64{1, 1, 2, 3}, nil)
+	if dw.N != 4 || dw.Min != dr.Min || dw.Q1 != dr.Q1 || dw.Median != dr.Median || dw.Q3 != dr.Q3 || dw.Max != dr.Max {
+		t.Errorf("weighted Describe mismatch: got %+v, want %+v", dw, dr)
+	}
+	if math.Abs(dw.Mean-dr.Mean) > 1e-14 {
+		t.Errorf("weighted mean mismatch: got %v, want %v", dw.Mean, dr.Mean)
+	}
+	if !Panics(func() { Describe(nil, nil) }) {
+		t.Errorf("Describe did not panic with empty data")
+	}
+	if !Panics(func() { Describe([]float64{1, 2}, []float64{1}) }) {
+		t.Errorf("Describe did not panic with mismatched weights")
+	}
+}
@@ -0,0 +1,366 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/gonum/floats"
+)
+
+// TrimmedMean returns the weighted mean of x after removing the fraction frac
+// of the total weight from each end of the sorted sample. An observation that
+// straddles a cut point is kept with the part of its weight inside the cut
+// points. For unweighted data and integer frac·n this drops the frac·n
+// smallest and largest values. If frac is 0, the result equals Mean.
+//
+// x need not be sorted and is not modified. If weights is nil then all of the
+// weights are 1. If weights is not nil, then len(x) must equal len(weights).
+// TrimmedMean returns NaN if x contains a NaN value, and panics if frac is
+// not in [0, 0.5).
+func TrimmedMean(x, weights []float64, frac float64) float64 {
+	return trimmedMean(x, weights, frac, false)
+}
+
+// WinsorizedMean returns the weighted mean of x after replacing the fraction
+// frac of the total weight at each end of the sorted sample by the value at
+// the corresponding cut point, the value of the observation that straddles or
+// starts at it. If frac is 0, the result equals Mean. x, weights and frac are
+// as for TrimmedMean.
+func WinsorizedMean(x, weights []float64, frac float64) float64 {
+	return trimmedMean(x, weights, frac, true)
+}
+
+func trimmedMean(x, weights []float64, frac float64, winsorize bool) float64 {
+	if !(frac >= 0 && frac < 0.5) {
+		panic("stat: trimming fraction out of bounds")
+	}
+	checkWeightLength(x, weights)
+	if frac == 0 {
+		return Mean(x, weights)
+	}
+	if floats.HasNaN(x) {
+		return math.NaN()
+	}
+	weight := func(i int) float64 {
+		if weights == nil {
+			return 1
+		}
+		return weights[i]
+	}
+	var total float64
+	if weights == nil {
+		total = float64(len(x))
+	} else {
+		total = floats.Sum(weights)
+	}
+	lo := frac * total
+	hi := total - lo
+
+	var (
+		sum, kept  float64
+		cum        float64
+		xlo, xhi   float64
+		foundLower bool
+	)
+	for _, i := range argsortFloats(x) {
+		w := weight(i)
+		start, end := cum, cum+w
+		cum = end
+		if w == 0 {
+			continue
+		}
+		if !foundLower && end > lo {
+			xlo = x[i]
+			foundLower = true
+		}
+		if start < hi {
+			xhi = x[i]
+		}
+		// The part of the weight of the observation between the cut points.
+		in := math.Min(end, hi) - math.Max(start, lo)
+		if in > 0 {
+			sum += in * x[i]
+			kept += in
+		}
+	}
+	if winsorize {
+		return (sum + lo*xlo + lo*xhi) / (kept + 2*lo)
+	}
+	return sum / kept
+}
+
+// MADNormalConsistency is the factor, 1/Φ⁻¹(3/4), that makes the median
+// absolute deviation a consistent estimator of the standard deviation of
+// normally distributed data.
+const MADNormalConsistency = 1.482602218505602
+
+// MedianAbsoluteDeviation returns the weighted median absolute deviation of
+// x,
+//  median_i |x_i - median_j x_j|
+// with the medians computed by WeightedMedian. x is not modified. If weights
+// is nil then all of the weights are 1. If weights is not nil, then len(x)
+// must equal len(weights). MedianAbsoluteDeviation returns NaN if x contains
+// a NaN value, and panics if x is empty.
+func MedianAbsoluteDeviation(x, weights []float64) float64 {
+	m := WeightedMedian(x, weights)
+	dev := make([]float64, len(x))
+	for i, v := range x {
+		dev[i] = math.Abs(v - m)
+	}
+	return WeightedMedian(dev, weights)
+}
+
+// MedianAbsoluteDeviationNormal returns the median absolute deviation of x
+// scaled by MADNormalConsistency, an estimate of the standard deviation that
+// is robust to outliers.
+func MedianAbsoluteDeviationNormal(x, weights []float64) float64 {
+	return MADNormalConsistency * MedianAbsoluteDeviation(x, weights)
+}
+
+// Sn returns the Sn robust scale estimator of Rousseeuw and Croux,
+//  Sn = c lomed_i himed_j |x_i - x_j|
+// where himed is the high median, the (⌊n/2⌋+1)th order statistic, and lomed
+// the low median, the ⌊(n+1)/2⌋th. c = 1.1926 with the small sample
+// corrections of Croux and Rousseeuw, "Time-efficient algorithms for two
+// highly robust estimators of scale", Computational Statistics, 1992, which
+// make Sn an estimate of the standard deviation for normally distributed
+// data. Unlike the median absolute deviation, Sn does not assume a symmetric
+// distribution. Sn takes O(n log n) time, and x is not modified. Sn returns
+// NaN if x contains a NaN value, and panics if x has fewer than two values.
+func Sn(x []float64) float64 {
+	n := len(x)
+	if n < 2 {
+		panic("stat: too few samples")
+	}
+	if floats.HasNaN(x) {
+		return math.NaN()
+	}
+	s := NewSorted(x)
+	// The high median of the n distances from s[i], including the zero
+	// distance to itself, is the ⌊n/2⌋th smallest of the other n-1, the
+	// decreasing distances to the smaller values and the increasing
+	// distances to the larger values.
+	meds := make([]float64, n)
+	k := n / 2
+	for i := range s {
+		left := func(j int) float64 { return s[i] - s[i-1-j] }
+		right := func(j int) float64 { return s[i+1+j] - s[i] }
+		meds[i] = kthOfSorted(left, i, right, n-1-i, k)
+	}
+	lomed := NewSorted(meds)[(n+1)/2-1]
+
+	var cn float64
+	switch {
+	case n <= 9:
+		cn = [...]float64{2: 0.743, 3: 1.851, 4: 0.954, 5: 1.351, 6: 0.993, 7: 1.198, 8: 1.005, 9: 1.131}[n]
+	case n%2 == 1:
+		cn = float64(n) / (float64(n) - 0.9)
+	default:
+		cn = 1
+	}
+	return 1.1926 * cn * lomed
+}
+
+// kthOfSorted returns the kth smallest, counting from 1, of the union of the
+// increasing sequences a(0), ..., a(na-1) and b(0), ..., b(nb-1), in
+// O(log(na+nb)) time.
+func kthOfSorted(a func(int) float64, na int, b func(int) float64, nb int, k int) float64 {
+	// Find the number t of elements taken from a, so that the k smallest
+	// are a(0..t-1) and b(0..k-t-1).
+	lo, hi := k-nb, k
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > na {
+		hi = na
+	}
+	for lo < hi {
+		t := (lo + hi) / 2
+		// Too few taken from a if a(t) is smaller than the last taken
+		// from b.
+		if b(k-t-1) > a(t) {
+			lo = t + 1
+		} else {
+			hi = t
+		}
+	}
+	t := lo
+	switch {
+	case t == 0:
+		return b(k - 1)
+	case t == k:
+		return a(k - 1)
+	}
+	return math.Max(a(t-1), b(k-t-1))
+}
+
+// Qn returns the Qn robust scale estimator of Rousseeuw and Croux,
+//  Qn = d {|x_i - x_j|; i < j}_(k)
+// the kth order statistic of the pairwise distances with k = h(h-1)/2 and
+// h = ⌊n/2⌋+1, which is about their first quartile. d = 2.2219 with the small
+// sample corrections of Croux and Rousseeuw (1992), which make Qn an estimate
+// of the standard deviation for normally distributed data. Qn is more
+// efficient than Sn at the normal distribution. The order statistic is found
+// by bisection over the distances with O(n) counting steps, and x is not
+// modified. Qn returns NaN if x contains a NaN value, and panics if x has
+// fewer than two values.
+func Qn(x []float64) float64 {
+	n := len(x)
+	if n < 2 {
+		panic("stat: too few samples")
+	}
+	if floats.HasNaN(x) {
+		return math.NaN()
+	}
+	s := NewSorted(x)
+	h := n/2 + 1
+	q := kthPairDistance(s, h*(h-1)/2)
+
+	var dn float64
+	switch {
+	case n <= 9:
+		dn = [...]float64{2: 0.399, 3: 0.994, 4: 0.512, 5: 0.844, 6: 0.611, 7: 0.857, 8: 0.669, 9: 0.872}[n]
+	case n%2 == 1:
+		dn = float64(n) / (float64(n) + 1.4)
+	default:
+		dn = float64(n) / (float64(n) + 3.8)
+	}
+	return 2.2219 * dn * q
+}
+
+// kthPairDistance returns the kth smallest, counting from 1, of the
+// distances s[j]-s[i] for i < j of the sorted s.
+func kthPairDistance(s Sorted, k int) float64 {
+	n := len(s)
+	// count returns the number of distances at most d.
+	count := func(d float64) int {
+		var c, i int
+		for j := range s {
+			for i < j && s[j]-s[i] > d {
+				i++
+			}
+			c += j - i
+		}
+		return c
+	}
+	// next returns the smallest distance greater than d.
+	next := func(d float64) float64 {
+		min := math.Inf(1)
+		j := 0
+		for i := 0; i < n-1; i++ {
+			if j <= i {
+				j = i + 1
+			}
+			for j < n && s[j]-s[i] <= d {
+				j++
+			}
+			if j < n && s[j]-s[i] < min {
+				min = s[j] - s[i]
+			}
+		}
+		return min
+	}
+	// The kth distance is in (lo, hi], with fewer than k distances at most
+	// lo. Each step either finds that the smallest distance above lo is
+	// the kth, or halves the interval.
+	lo, hi := math.Inf(-1), s[n-1]-s[0]
+	for {
+		cand := next(lo)
+		if count(cand) >= k {
+			return cand
+		}
+		mid := cand + (hi-cand)/2
+		if count(mid) >= k {
+			lo, hi = cand, mid
+		} else {
+			lo = mid
+		}
+	}
+}
+
+// TheilSen returns the Theil–Sen estimate of the line
+//  y = alpha + beta*x
+// through the data in x and y, with beta the median of the slopes
+//  (y_j - y_i) / (x_j - x_i)
+// over the pairs i < j with x_i ≠ x_j and alpha the median of y_i - beta*x_i,
+// the medians computed as by Median. Unlike LinearRegression, the estimate is
+// robust to outliers in both x and y, with a breakdown point of about 29%.
+//
+// TheilSen computes all of the n(n-1)/2 slopes, so it takes O(n²) memory and
+// O(n² log n) time. For large data sets, TheilSenSubsample estimates the line
+// from a random sample of the pairs.
+//
+// TheilSen returns NaN if all of the values of x are equal or if x or y
+// contains a NaN value, and panics if the lengths of x and y differ.
+func TheilSen(x, y []float64) (alpha, beta float64) {
+	checkLengths(x, y)
+	n := len(x)
+	slopes := make([]float64, 0, n*(n-1)/2)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if x[i] != x[j] {
+				slopes = append(slopes, (y[j]-y[i])/(x[j]-x[i]))
+			}
+		}
+	}
+	return theilSenLine(x, y, slopes)
+}
+
+// TheilSenSubsample returns the Theil–Sen estimate of the line through the
+// data in x and y as TheilSen does, but with beta the median of the slopes of
+// pairs pairs of observations drawn uniformly at random with replacement,
+// ignoring those with equal x. It takes O(pairs + n) memory. If pairs is at
+// least n(n-1)/2, the result is that of TheilSen. If src is nil the global
+// random source is used.
+//
+// TheilSenSubsample returns NaN if none of the drawn pairs has distinct x or
+// if x or y contains a NaN value, and panics if the lengths of x and y differ
+// or if pairs is not positive.
+func TheilSenSubsample(x, y []float64, pairs int, src *rand.Rand) (alpha, beta float64) {
+	checkLengths(x, y)
+	if pairs < 1 {
+		panic("stat: non-positive number of pairs")
+	}
+	n := len(x)
+	if pairs >= n*(n-1)/2 {
+		return TheilSen(x, y)
+	}
+	intn := rand.Intn
+	if src != nil {
+		intn = src.Intn
+	}
+	slopes := make([]float64, 0, pairs)
+	for k := 0; k < pairs; k++ {
+		// Draw j uniformly from the indices other than i.
+		i := intn(n)
+		j := intn(n - 1)
+		if j >= i {
+			j++
+		}
+		if x[i] != x[j] {
+			slopes = append(slopes, (y[j]-y[i])/(x[j]-x[i]))
+		}
+	}
+	return theilSenLine(x, y, slopes)
+}
+
+// theilSenLine returns the intercept and slope of the Theil–Sen line through
+// the data in x and y given the slopes of the pairs. slopes is sorted in
+// place.
+func theilSenLine(x, y, slopes []float64) (alpha, beta float64) {
+	if len(slopes) == 0 || floats.HasNaN(x) || floats.HasNaN(y) {
+		return math.NaN(), math.NaN()
+	}
+	sort.Float64s(slopes)
+	beta = AsSorted(slopes).Median()
+	res := make([]float64, len(x))
+	for i, v := range x {
+		res[i] = y[i] - beta*v
+	}
+	return Median(res), beta
+}
@@ -0,0 +1,336 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestTrimmedMean(t *testing.T) {
+	for i, test := range []struct {
+		x, w       []float64
+		frac       float64
+		trim, wins float64
+	}{
+		{
+			x:    []float64{1000, 0, 2, 1, 4, 8, 16, 32, 64, 128},
+			frac: 0.1,
+			trim: 255.0 / 8,
+			wins: 38.4,
+		},
+		{
+			// Partial weights at the cut points 1.5 and 8.5.
+			x:    []float64{1000, 0, 2, 1, 4, 8, 16, 32, 64, 128},
+			frac: 0.15,
+			trim: 190.5 / 7,
+			wins: 38.4,
+		},
+		{
+			// Weights equivalent to repeating observations.
+			x:    []float64{5, 1, 3, 100},
+			w:    []float64{2, 3, 4, 1},
+			frac: 0.2,
+			trim: TrimmedMean([]float64{5, 5, 1, 1, 1, 3, 3, 3, 3, 100}, nil, 0.2),
+			wins: WinsorizedMean([]float64{5, 5, 1, 1, 1, 3, 3, 3, 3, 100}, nil, 0.2),
+		},
+		{
+			x:    []float64{5, math.NaN(), 3},
+			frac: 0.2,
+			trim: math.NaN(),
+			wins: math.NaN(),
+		},
+	} {
+		xCopy := append([]float64(nil), test.x...)
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"TrimmedMean", TrimmedMean(test.x, test.w, test.frac), test.trim},
+			{"WinsorizedMean", WinsorizedMean(test.x, test.w, test.frac), test.wins},
+		} {
+			if !floats.EqualWithinAbsOrRel(v.got, v.want, 1e-14, 1e-14) && !(math.IsNaN(v.got) && math.IsNaN(v.want)) {
+				t.Errorf("%s mismatch case %d: got %v, want %v", v.name, i, v.got, v.want)
+			}
+		}
+		if !floats.Same(xCopy, test.x) {
+			t.Errorf("case %d: x modified", i)
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float64, 100)
+	w := make([]float64, 100)
+	for i := range x {
+		x[i] = rnd.ExpFloat64()
+		w[i] = rnd.Float64()
+	}
+	for _, weights := range [][]float64{nil, w} {
+		if got, want := TrimmedMean(x, weights, 0), Mean(x, weights); got != want {
+			t.Errorf("TrimmedMean with zero fraction: got %v, want %v", got, want)
+		}
+		if got, want := WinsorizedMean(x, weights, 0), Mean(x, weights); got != want {
+			t.Errorf("WinsorizedMean with zero fraction: got %v, want %v", got, want)
+		}
+	}
+
+	for _, f := range []func(){
+		func() { TrimmedMean(x, nil, 0.5) },
+		func() { WinsorizedMean(x, nil, -0.1) },
+		func() { TrimmedMean(x, w[:10], 0.1) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestMedianAbsoluteDeviation(t *testing.T) {
+	for i, test := range []struct {
+		x, w []float64
+		ans  float64
+	}{
+		{x: []float64{9, 1, 2, 6, 1, 2, 4}, ans: 1},
+		{x: []float64{1, 2, 3, 4}, ans: 1},
+		{x: []float64{1, 2, 3, 4}, w: []float64{0.5, 0.5, 0.5, 0.5}, ans: 1},
+		{x: []float64{1, 2, 3, 100}, w: []float64{1, 1, 4, 1}, ans: 0},
+		{x: []float64{1, 2, 3, 100}, w: []float64{1, 1, 3, 1}, ans: 0.5},
+		{x: []float64{1, math.NaN(), 3}, ans: math.NaN()},
+	} {
+		xCopy := append([]float64(nil), test.x...)
+		got := MedianAbsoluteDeviation(test.x, test.w)
+		if got != test.ans && !(math.IsNaN(got) && math.IsNaN(test.ans)) {
+			t.Errorf("MedianAbsoluteDeviation mismatch case %d: got %v, want %v", i, got, test.ans)
+		}
+		if got, want := MedianAbsoluteDeviationNormal(test.x, test.w), MADNormalConsistency*test.ans; got != want && !math.IsNaN(want) {
+			t.Errorf("MedianAbsoluteDeviationNormal mismatch case %d: got %v, want %v", i, got, want)
+		}
+		if !floats.Same(xCopy, test.x) {
+			t.Errorf("case %d: x modified", i)
+		}
+	}
+	if !Panics(func() { MedianAbsoluteDeviation(nil, nil) }) {
+		t.Errorf("MedianAbsoluteDeviation did not panic with empty input")
+	}
+}
+
+// snQnBrute returns the unscaled Sn and Qn statistics of x computed from all
+// of the pairwise distances.
+func snQnBrute(x []float64) (sn, qn float64) {
+	n := len(x)
+	meds := make([]float64, n)
+	var pairs []float64
+	for i := range x {
+		d := make([]float64, n)
+		for j := range x {
+			d[j] = math.Abs(x[i] - x[j])
+			if j > i {
+				pairs = append(pairs, d[j])
+			}
+		}
+		meds[i] = NewSorted(d)[n/2]
+	}
+	h := n/2 + 1
+	return NewSorted(meds)[(n+1)/2-1], NewSorted(pairs)[h*(h-1)/2-1]
+}
+
+func TestSnQn(t *testing.T) {
+	// Prefixes of x, the last with an outlier. The expected values apply the
+	// constants and small sample factors of Croux and Rousseeuw (1992) to
+	// the order statistics of the definitions in Rousseeuw and Croux (1993).
+	x := []float64{3.1, 4.1, 5.9, 2.6, 5.3, 5.8, 9.7, 9.3, 2.3, 8.4, 6.2, 4.3, 38}
+	for _, test := range []struct {
+		n      int
+		sn, qn float64
+	}{
+		{2, 0.8861017999999996, 0.8865380999999997},
+		{3, 2.2075025999999993, 2.208568599999999},
+		{4, 1.7066105999999994, 1.7064191999999996},
+		{5, 1.9334431200000004, 1.8752835999999993},
+		{6, 2.0132280600000003, 1.6290970800000004},
+		{7, 2.571722640000001, 2.28500196},
+		{8, 3.2361201, 2.6756119800000016},
+		{9, 3.64184262, 2.9062451999999994},
+		{10, 3.3392800000000005, 2.89813043478261},
+		{11, 3.3770653465346547, 2.9565604838709674},
+		{12, 3.100760000000001, 2.8687822784810124},
+		{13, 3.459525619834711, 3.409999305555555},
+	} {
+		if got := Sn(x[:test.n]); !floats.EqualWithinRel(got, test.sn, 1e-14) {
+			t.Errorf("n = %d: Sn mismatch: got %v, want %v", test.n, got, test.sn)
+		}
+		if got := Qn(x[:test.n]); !floats.EqualWithinRel(got, test.qn, 1e-14) {
+			t.Errorf("n = %d: Qn mismatch: got %v, want %v", test.n, got, test.qn)
+		}
+	}
+
+	// The fast algorithms find the same order statistics as brute force, so
+	// the ratios to them depend only on n.
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{2, 3, 4, 5, 9, 10, 11, 50, 101} {
+		var snRatio, qnRatio float64
+		for trial := 0; trial < 5; trial++ {
+			x := make([]float64, n)
+			for i := range x {
+				// Rounded values, so that there are ties.
+				x[i] = math.Floor(4 * rnd.NormFloat64())
+				if trial%2 == 1 {
+					x[i] = rnd.ExpFloat64()
+				}
+			}
+			xCopy := append([]float64(nil), x...)
+			sn, qn := snQnBrute(x)
+			for _, v := range []struct {
+				name       string
+				got, brute float64
+				ratio      *float64
+			}{
+				{"Sn", Sn(x), sn, &snRatio},
+				{"Qn", Qn(x), qn, &qnRatio},
+			} {
+				if v.brute == 0 {
+					if v.got != 0 {
+						t.Errorf("n = %d: %s mismatch: got %v, want 0", n, v.name, v.got)
+					}
+					continue
+				}
+				r := v.got / v.brute
+				if *v.ratio == 0 {
+					*v.ratio = r
+				} else if !floats.EqualWithinRel(r, *v.ratio, 1e-14) {
+					t.Errorf("n = %d: %s is not a fixed multiple of the brute force statistic: %v and %v", n, v.name, r, *v.ratio)
+				}
+			}
+			if !floats.Same(xCopy, x) {
+				t.Errorf("n = %d: x modified", n)
+			}
+		}
+	}
+
+	// The estimators are consistent for the standard deviation of normal
+	// data.
+	x = make([]float64, 10000)
+	for i := range x {
+		x[i] = 3 * rnd.NormFloat64()
+	}
+	for _, test := range []struct {
+		name string
+		got  float64
+	}{
+		{"Sn", Sn(x)},
+		{"Qn", Qn(x)},
+		{"MedianAbsoluteDeviationNormal", MedianAbsoluteDeviationNormal(x, nil)},
+	} {
+		if math.Abs(test.got-3) > 0.1 {
+			t.Errorf("%s of normal data: got %v, want about 3", test.name, test.got)
+		}
+	}
+
+	if !math.IsNaN(Sn([]float64{1, math.NaN()})) || !math.IsNaN(Qn([]float64{1, math.NaN()})) {
+		t.Errorf("expected NaN for NaN input")
+	}
+	if !Panics(func() { Sn([]float64{1}) }) || !Panics(func() { Qn(nil) }) {
+		t.Errorf("expected panic for too few samples")
+	}
+}
+
+func BenchmarkQn(b *testing.B) {
+	x := RandomSlice(large)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Qn(x)
+	}
+}
+
+func BenchmarkSn(b *testing.B) {
+	x := RandomSlice(large)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Sn(x)
+	}
+}
+
+func TestTheilSen(t *testing.T) {
+	nan := math.NaN()
+	for i, test := range []struct {
+		x, y        []float64
+		alpha, beta float64
+	}{
+		{
+			// The 14 slopes of the pairs with distinct x have median
+			// (1.5 + 1.6) / 2.
+			x:     []float64{1, 2, 2, 3, 5, 6},
+			y:     []float64{1, 3, 2, 6, 4, 9},
+			alpha: -0.425,
+			beta:  1.55,
+		},
+		{
+			// Two gross outliers on the line y = 2 + 3x.
+			x:     []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			y:     []float64{2, 5, 8, 1000, 14, 17, 20, -500, 26, 29},
+			alpha: 2,
+			beta:  3,
+		},
+		{
+			x:     []float64{1, 1, 1},
+			y:     []float64{1, 2, 3},
+			alpha: nan,
+			beta:  nan,
+		},
+		{
+			x:     []float64{1, 2, 3},
+			y:     []float64{1, nan, 3},
+			alpha: nan,
+			beta:  nan,
+		},
+	} {
+		alpha, beta := TheilSen(test.x, test.y)
+		if !floats.EqualWithinAbsOrRel(alpha, test.alpha, 1e-14, 1e-14) && !(math.IsNaN(alpha) && math.IsNaN(test.alpha)) {
+			t.Errorf("case %d: alpha mismatch. Want %v, got %v", i, test.alpha, alpha)
+		}
+		if !floats.EqualWithinAbsOrRel(beta, test.beta, 1e-14, 1e-14) && !(math.IsNaN(beta) && math.IsNaN(test.beta)) {
+			t.Errorf("case %d: beta mismatch. Want %v, got %v", i, test.beta, beta)
+		}
+
+		// Drawing at least as many pairs as there are gives the exact
+		// estimate.
+		n := len(test.x)
+		sa, sb := TheilSenSubsample(test.x, test.y, n*(n-1)/2, nil)
+		if !(sa == alpha && sb == beta) && !(math.IsNaN(sa) && math.IsNaN(alpha) && math.IsNaN(sb) && math.IsNaN(beta)) {
+			t.Errorf("case %d: subsample of all pairs mismatch. Want (%v, %v), got (%v, %v)", i, alpha, beta, sa, sb)
+		}
+	}
+
+	// A subsample of the pairs of a large data set with 20% outliers
+	// recovers the line.
+	rnd := rand.New(rand.NewSource(1))
+	n := 5000
+	x := make([]float64, n)
+	y := make([]float64, n)
+	for i := range x {
+		x[i] = rnd.Float64() * 10
+		y[i] = 1 + 0.5*x[i] + 0.1*rnd.NormFloat64()
+		if i%5 == 0 {
+			y[i] = 100 * rnd.Float64()
+		}
+	}
+	alpha, beta := TheilSenSubsample(x, y, 20000, rand.New(rand.NewSource(2)))
+	if math.Abs(alpha-1) > 0.05 || math.Abs(beta-0.5) > 0.01 {
+		t.Errorf("subsampled line mismatch. Want (1, 0.5), got (%v, %v)", alpha, beta)
+	}
+	if a2, b2 := TheilSenSubsample(x, y, 20000, rand.New(rand.NewSource(2))); a2 != alpha || b2 != beta {
+		t.Errorf("subsample not reproducible: got (%v, %v) and (%v, %v)", alpha, beta, a2, b2)
+	}
+
+	if !Panics(func() { TheilSen(make([]float64, 3), make([]float64, 2)) }) {
+		t.Errorf("TheilSen did not panic with x, y length mismatch")
+	}
+	if !Panics(func() { TheilSenSubsample(make([]float64, 3), make([]float64, 3), 0, nil) }) {
+		t.Errorf("TheilSenSubsample did not panic with non-positive number of pairs")
+	}
+}
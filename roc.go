@@ -0,0 +1,238 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/floats"
+)
+
+// AUCFromScores returns the area under the receiver operating characteristic
+// curve of the classifier scores for the observations of the given classes,
+// with true the positive class. The area is the probability that a positive
+// observation has a higher score than a negative one, with tied scores
+// counted as one half,
+//  AUC = \sum_{i∈P} \sum_{j∈N} w_i w_j (1[s_i > s_j] + ½ 1[s_i = s_j]) / (W_P W_N)
+// where W_P and W_N are the total weights of the positive and negative
+// observations. This is the area under the curve joining the points of the
+// ROC curve at each distinct score by straight lines, and is U/(n_P n_N) for
+// the Mann–Whitney statistic U of the scores of the positive observations.
+// The scores are sorted, so AUCFromScores takes O(n log n) time.
+//
+// If weights is nil then all of the weights are 1. If weights is not nil,
+// then len(scores) must equal len(weights). AUCFromScores returns NaN if
+// either class has zero total weight or if a score is NaN, and panics if the
+// lengths of scores and classes differ.
+func AUCFromScores(scores []float64, classes []bool, weights []float64) float64 {
+	if len(classes) != len(scores) {
+		panic(ErrLengthMismatch{Got: len(classes), Want: len(scores)})
+	}
+	checkWeightLength(scores, weights)
+	order := argsortFloats(scores)
+	var area, neg, pos float64
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && scores[order[end]] == scores[order[start]] {
+			end++
+		}
+		var p, n float64
+		for _, i := range order[start:end] {
+			if math.IsNaN(scores[i]) {
+				return math.NaN()
+			}
+			w := 1.0
+			if weights != nil {
+				w = weights[i]
+			}
+			if classes[i] {
+				p += w
+			} else {
+				n += w
+			}
+		}
+		// The positives of the group outrank the negatives seen so far
+		// and tie with those of the group.
+		area += p * (neg + n/2)
+		neg += n
+		pos += p
+		start = end
+	}
+	if pos == 0 || neg == 0 {
+		return math.NaN()
+	}
+	return area / (pos * neg)
+}
+
+// PrecisionRecallCurve returns the precision–recall curve of the classifier
+// scores for the observations of the given classes, with true the positive
+// class. thresholds holds the distinct scores in increasing order, and
+// precision[i] and recall[i] are the weighted precision and recall of the
+// classification of the observations with scores at least thresholds[i] as
+// positive. As in scikit-learn, precision and recall have a final element
+// more than thresholds, 1 and 0 respectively, for the point with no
+// observations classified as positive.
+//
+// If weights is nil then all of the weights are 1. If weights is not nil,
+// then len(scores) must equal len(weights). The recall is NaN if the
+// positive class has zero total weight. If a score is NaN, the curve is
+// undefined, as is the area of AUCFromScores, and a single NaN threshold is
+// returned with NaN precision and recall. PrecisionRecallCurve panics if the
+// lengths of scores and classes differ.
+func PrecisionRecallCurve(scores []float64, classes []bool, weights []float64) (precision, recall, thresholds []float64) {
+	if len(classes) != len(scores) {
+		panic(ErrLengthMismatch{Got: len(classes), Want: len(scores)})
+	}
+	checkWeightLength(scores, weights)
+	if floats.HasNaN(scores) {
+		nan := math.NaN()
+		return []float64{nan, nan}, []float64{nan, nan}, []float64{nan}
+	}
+	order := argsortFloats(scores)
+
+	// Accumulate the true and false positives from the highest score down,
+	// one group of tied scores at a time.
+	var tp, fp []float64
+	var tps, fps float64
+	for end := len(order); end > 0; {
+		start := end - 1
+		for start > 0 && scores[order[start-1]] == scores[order[end-1]] {
+			start--
+		}
+		for _, i := range order[start:end] {
+			w := 1.0
+			if weights != nil {
+				w = weights[i]
+			}
+			if classes[i] {
+				tps += w
+			} else {
+				fps += w
+			}
+		}
+		thresholds = append(thresholds, scores[order[start]])
+		tp = append(tp, tps)
+		fp = append(fp, fps)
+		end = start
+	}
+
+	m := len(thresholds)
+	precision = make([]float64, m+1)
+	recall = make([]float64, m+1)
+	for k := range thresholds {
+		// Store in increasing order of threshold.
+		i := m - 1 - k
+		precision[i] = tp[k] / (tp[k] + fp[k])
+		recall[i] = tp[k] / tps
+	}
+	precision[m] = 1
+	recall[m] = 0
+	for i, j := 0, m-1; i < j; i, j = i+1, j-1 {
+		thresholds[i], thresholds[j] = thresholds[j], thresholds[i]
+	}
+	return precision, recall, thresholds
+}
+
+// AveragePrecision returns the average precision of the classifier scores
+// for the observations of the given classes, the step-wise integral of the
+// precision–recall curve returned by PrecisionRecallCurve,
+//  AP = \sum_n (R_n - R_{n-1}) P_n
+// where P_n and R_n are the precision and recall at the nth highest
+// threshold, and R_0 = 0. Unlike the trapezoidal integral it does not
+// interpolate the precision between thresholds, and it matches
+// average_precision_score in scikit-learn.
+//
+// AveragePrecision returns NaN if the positive class has zero total weight
+// or if a score is NaN, and panics under the same conditions as
+// PrecisionRecallCurve.
+func AveragePrecision(scores []float64, classes []bool, weights []float64) float64 {
+	precision, recall, _ := PrecisionRecallCurve(scores, classes, weights)
+	if len(recall) == 1 {
+		return math.NaN()
+	}
+	var ap float64
+	for i := len(recall) - 2; i >= 0; i-- {
+		ap += (recall[i] - recall[i+1]) * precision[i]
+	}
+	return ap
+}
+
+// YoudenThreshold returns the cutoff of the points of a receiver operating
+// characteristic curve, with true and false positive rates tpr and fpr at
+// the cutoffs, that maximizes Youden's J statistic,
+//  J = TPR - FPR
+// and the maximum J. Of the points with the maximum J, the one with the
+// lowest false positive rate is chosen, and of those the first. Points with
+// NaN rates are ignored, and if all of them are, both results are NaN. The
+// cutoff is that of CostThreshold with equal costs and a prevalence of 0.5.
+//
+// YoudenThreshold panics if the lengths of cutoffs, tpr and fpr differ or if
+// they are empty.
+func YoudenThreshold(cutoffs, tpr, fpr []float64) (threshold, j float64) {
+	i := costOptimal(cutoffs, tpr, fpr, 1)
+	if i < 0 {
+		return math.NaN(), math.NaN()
+	}
+	return cutoffs[i], tpr[i] - fpr[i]
+}
+
+// CostThreshold returns the cutoff of the points of a receiver operating
+// characteristic curve, with true and false positive rates tpr and fpr at
+// the cutoffs, that minimizes the expected cost per observation of the
+// classification,
+//  C = costFN π (1 - TPR) + costFP (1 - π) FPR
+// and the minimum cost, where π is the prevalence of the positive class,
+// costFN is the cost of a false negative and costFP that of a false
+// positive. This is the cutoff that maximizes TPR - m FPR with the slope
+//  m = costFP (1 - π) / (costFN π)
+// Of the points with the minimum cost, the one with the lowest false
+// positive rate is chosen, and of those the first. Points with NaN rates are
+// ignored, and if all of them are, both results are NaN.
+//
+// CostThreshold panics if the lengths of cutoffs, tpr and fpr differ, if
+// they are empty, if a cost is not positive or if prevalence is not in
+// (0, 1).
+func CostThreshold(cutoffs, tpr, fpr []float64, costFP, costFN, prevalence float64) (threshold, cost float64) {
+	if !(costFP > 0 && costFN > 0) {
+		panic("stat: non-positive misclassification cost")
+	}
+	if !(prevalence > 0 && prevalence < 1) {
+		panic("stat: prevalence out of range")
+	}
+	m := costFP * (1 - prevalence) / (costFN * prevalence)
+	i := costOptimal(cutoffs, tpr, fpr, m)
+	if i < 0 {
+		return math.NaN(), math.NaN()
+	}
+	return cutoffs[i], costFN*prevalence*(1-tpr[i]) + costFP*(1-prevalence)*fpr[i]
+}
+
+// costOptimal returns the index of the point of the curve that maximizes
+// TPR - m FPR, resolving ties in favour of the lower false positive rate,
+// and then of the earlier point. Points with NaN rates are ignored, and
+// costOptimal returns -1 if all of them are.
+func costOptimal(cutoffs, tpr, fpr []float64, m float64) int {
+	if len(tpr) != len(cutoffs) {
+		panic(ErrLengthMismatch{Got: len(tpr), Want: len(cutoffs)})
+	}
+	if len(fpr) != len(cutoffs) {
+		panic(ErrLengthMismatch{Got: len(fpr), Want: len(cutoffs)})
+	}
+	if len(cutoffs) == 0 {
+		panic("stat: zero length slice")
+	}
+	best := -1
+	bestJ := math.Inf(-1)
+	for i := range cutoffs {
+		j := tpr[i] - m*fpr[i]
+		if math.IsNaN(j) {
+			continue
+		}
+		if best < 0 || j > bestJ || (j == bestJ && fpr[i] < fpr[best]) {
+			best, bestJ = i, j
+		}
+	}
+	return best
+}
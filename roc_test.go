@@ -0,0 +1,265 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAUCFromScores(t *testing.T) {
+	for i, test := range []struct {
+		scores  []float64
+		classes []bool
+		weights []float64
+		want    float64
+	}{
+		{
+			scores:  []float64{0.1, 0.4, 0.35, 0.8},
+			classes: []bool{false, false, true, true},
+			want:    0.75,
+		},
+		{
+			// Perfect separation, and the reverse.
+			scores:  []float64{1, 2, 3, 4},
+			classes: []bool{false, false, true, true},
+			want:    1,
+		},
+		{
+			scores:  []float64{1, 2, 3, 4},
+			classes: []bool{true, true, false, false},
+			want:    0,
+		},
+		{
+			// All scores tied.
+			scores:  []float64{5, 5, 5, 5, 5},
+			classes: []bool{true, false, true, false, false},
+			want:    0.5,
+		},
+		{
+			// The positive at 2 ties with one negative and outranks
+			// the other: (1.5 + 2 + 0) / 6.
+			scores:  []float64{1, 2, 2, 3, 0.5},
+			classes: []bool{false, true, false, true, true},
+			want:    3.5 / 6,
+		},
+		{
+			// A weight of 2 counts as two copies of an observation.
+			scores:  []float64{0.1, 0.4, 0.35, 0.8},
+			classes: []bool{false, false, true, true},
+			weights: []float64{1, 2, 1, 1},
+			want:    (1 + 3) / 6.0,
+		},
+		{
+			scores:  []float64{1, 2},
+			classes: []bool{true, true},
+			want:    math.NaN(),
+		},
+		{
+			scores:  []float64{1, math.NaN(), 3},
+			classes: []bool{true, false, true},
+			want:    math.NaN(),
+		},
+	} {
+		got := AUCFromScores(test.scores, test.classes, test.weights)
+		if math.IsNaN(test.want) {
+			if !math.IsNaN(got) {
+				t.Errorf("case %d: want NaN, got %v", i, got)
+			}
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-15 {
+			t.Errorf("case %d: AUC mismatch. Want %v, got %v", i, test.want, got)
+		}
+	}
+
+	// The AUC is the Mann–Whitney U of the positive scores over the
+	// number of pairs, and integer weights count as repeated observations.
+	rnd := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 10; trial++ {
+		n := 20 + rnd.Intn(30)
+		scores := make([]float64, n)
+		classes := make([]bool, n)
+		weights := make([]float64, n)
+		var pos, neg, repeated []float64
+		var repClasses []bool
+		for i := range scores {
+			scores[i] = float64(rnd.Intn(10))
+			classes[i] = rnd.Intn(2) == 0
+			weights[i] = float64(1 + rnd.Intn(3))
+			if classes[i] {
+				pos = append(pos, scores[i])
+			} else {
+				neg = append(neg, scores[i])
+			}
+			for k := 0; k < int(weights[i]); k++ {
+				repeated = append(repeated, scores[i])
+				repClasses = append(repClasses, classes[i])
+			}
+		}
+		if len(pos) == 0 || len(neg) == 0 {
+			continue
+		}
+		u, _ := MannWhitneyU(pos, neg, TwoSided)
+		want := u / float64(len(pos)*len(neg))
+		if got := AUCFromScores(scores, classes, nil); math.Abs(got-want) > 1e-12 {
+			t.Errorf("trial %d: AUC mismatch with Mann–Whitney U. Want %v, got %v", trial, want, got)
+		}
+		want = AUCFromScores(repeated, repClasses, nil)
+		if got := AUCFromScores(scores, classes, weights); math.Abs(got-want) > 1e-12 {
+			t.Errorf("trial %d: weighted AUC mismatch with repeated observations. Want %v, got %v", trial, want, got)
+		}
+	}
+
+	if !Panics(func() { AUCFromScores([]float64{1, 2}, []bool{true}, nil) }) {
+		t.Errorf("AUCFromScores did not panic with classes length mismatch")
+	}
+	if !Panics(func() { AUCFromScores([]float64{1, 2}, []bool{true, false}, []float64{1}) }) {
+		t.Errorf("AUCFromScores did not panic with weights length mismatch")
+	}
+}
+
+func TestPrecisionRecallCurve(t *testing.T) {
+	for i, test := range []struct {
+		scores  []float64
+		classes []bool
+		weights []float64
+
+		precision, recall, thresholds []float64
+		ap                            float64
+	}{
+		{
+			// The example of precision_recall_curve and
+			// average_precision_score in scikit-learn.
+			scores:     []float64{0.1, 0.4, 0.35, 0.8},
+			classes:    []bool{false, false, true, true},
+			precision:  []float64{0.5, 2.0 / 3, 0.5, 1, 1},
+			recall:     []float64{1, 1, 0.5, 0.5, 0},
+			thresholds: []float64{0.1, 0.35, 0.4, 0.8},
+			ap:         0.8333333333333333,
+		},
+		{
+			// Tied scores are collapsed into one threshold.
+			scores:     []float64{0.2, 0.7, 0.7, 0.7, 0.9},
+			classes:    []bool{true, true, false, true, false},
+			precision:  []float64{0.6, 0.5, 0, 1},
+			recall:     []float64{1, 2.0 / 3, 0, 0},
+			thresholds: []float64{0.2, 0.7, 0.9},
+			ap:         1.0/3*0.6 + 2.0/3*0.5,
+		},
+		{
+			// A weight of 2 counts as two copies of an observation.
+			scores:     []float64{0.1, 0.4, 0.35, 0.8},
+			classes:    []bool{false, false, true, true},
+			weights:    []float64{1, 2, 1, 2},
+			precision:  []float64{0.5, 0.6, 0.5, 1, 1},
+			recall:     []float64{1, 1, 2.0 / 3, 2.0 / 3, 0},
+			thresholds: []float64{0.1, 0.35, 0.4, 0.8},
+			ap:         2.0/3 + 1.0/3*0.6,
+		},
+	} {
+		precision, recall, thresholds := PrecisionRecallCurve(test.scores, test.classes, test.weights)
+		for _, v := range []struct {
+			name      string
+			got, want []float64
+		}{
+			{"precision", precision, test.precision},
+			{"recall", recall, test.recall},
+			{"thresholds", thresholds, test.thresholds},
+		} {
+			if !sameFloatsNaN(v.got, v.want, 1e-14) {
+				t.Errorf("case %d: %s mismatch. Want %v, got %v", i, v.name, v.want, v.got)
+			}
+		}
+		if ap := AveragePrecision(test.scores, test.classes, test.weights); math.Abs(ap-test.ap) > 1e-14 {
+			t.Errorf("case %d: average precision mismatch. Want %v, got %v", i, test.ap, ap)
+		}
+	}
+
+	if ap := AveragePrecision([]float64{1, 2}, []bool{false, false}, nil); !math.IsNaN(ap) {
+		t.Errorf("average precision without positives: want NaN, got %v", ap)
+	}
+	if ap := AveragePrecision(nil, nil, nil); !math.IsNaN(ap) {
+		t.Errorf("average precision of empty data: want NaN, got %v", ap)
+	}
+	if !Panics(func() { PrecisionRecallCurve([]float64{1, 2}, []bool{true}, nil) }) {
+		t.Errorf("PrecisionRecallCurve did not panic with classes length mismatch")
+	}
+	// A NaN score gives NaN results, as for AUCFromScores.
+	p, r, th := PrecisionRecallCurve([]float64{1, math.NaN()}, []bool{true, false}, nil)
+	nan := math.NaN()
+	if !sameFloatsNaN(p, []float64{nan, nan}, 0) || !sameFloatsNaN(r, []float64{nan, nan}, 0) || !sameFloatsNaN(th, []float64{nan}, 0) {
+		t.Errorf("precision-recall curve with NaN score: want NaN, got %v, %v and %v", p, r, th)
+	}
+	if ap := AveragePrecision([]float64{1, math.NaN()}, []bool{true, false}, nil); !math.IsNaN(ap) {
+		t.Errorf("average precision with NaN score: want NaN, got %v", ap)
+	}
+}
+
+func TestYoudenThreshold(t *testing.T) {
+	cutoffs := []float64{0.9, 0.7, 0.5, 0.3, 0.1}
+	tpr := []float64{0.2, 0.6, 0.8, 0.9, 1}
+	fpr := []float64{0, 0.1, 0.3, 0.5, 1}
+
+	// J is 0.5 at both 0.7 and 0.5, and the tie resolves to the lower
+	// false positive rate in either order of the points.
+	th, j := YoudenThreshold(cutoffs, tpr, fpr)
+	if th != 0.7 || math.Abs(j-0.5) > 1e-15 {
+		t.Errorf("Youden mismatch. Want 0.7 and 0.5, got %v and %v", th, j)
+	}
+	rev := func(s []float64) []float64 {
+		r := make([]float64, len(s))
+		for i, v := range s {
+			r[len(s)-1-i] = v
+		}
+		return r
+	}
+	th, _ = YoudenThreshold(rev(cutoffs), rev(tpr), rev(fpr))
+	if th != 0.7 {
+		t.Errorf("Youden mismatch for reversed points. Want 0.7, got %v", th)
+	}
+
+	for i, test := range []struct {
+		costFP, costFN, prevalence float64
+		threshold, cost            float64
+	}{
+		// Costs inversely proportional to the class frequencies are
+		// equivalent to Youden's J.
+		{costFP: 1, costFN: 4, prevalence: 0.2, threshold: 0.7, cost: 4*0.2*0.4 + 0.8*0.1},
+		{costFP: 1, costFN: 1, prevalence: 0.5, threshold: 0.7, cost: 0.25},
+		// Expensive false negatives favour the lowest cutoff, and
+		// expensive false positives the highest.
+		{costFP: 1, costFN: 9, prevalence: 0.5, threshold: 0.1, cost: 0.5},
+		{costFP: 10, costFN: 1, prevalence: 0.5, threshold: 0.9, cost: 0.4},
+	} {
+		th, cost := CostThreshold(cutoffs, tpr, fpr, test.costFP, test.costFN, test.prevalence)
+		if th != test.threshold || math.Abs(cost-test.cost) > 1e-15 {
+			t.Errorf("case %d: cost threshold mismatch. Want %v and %v, got %v and %v", i, test.threshold, test.cost, th, cost)
+		}
+	}
+
+	nan := math.NaN()
+	th, j = YoudenThreshold([]float64{1, 2}, []float64{nan, 0.5}, []float64{nan, 0.25})
+	if th != 2 || j != 0.25 {
+		t.Errorf("Youden mismatch with NaN rates. Want 2 and 0.25, got %v and %v", th, j)
+	}
+	th, j = YoudenThreshold([]float64{1}, []float64{nan}, []float64{nan})
+	if !math.IsNaN(th) || !math.IsNaN(j) {
+		t.Errorf("Youden with all NaN rates: want NaN, got %v and %v", th, j)
+	}
+
+	for i, fn := range []func(){
+		func() { YoudenThreshold(cutoffs, tpr[1:], fpr) },
+		func() { YoudenThreshold(cutoffs, tpr, fpr[1:]) },
+		func() { YoudenThreshold(nil, nil, nil) },
+		func() { CostThreshold(cutoffs, tpr, fpr, 0, 1, 0.5) },
+		func() { CostThreshold(cutoffs, tpr, fpr, 1, 1, 1) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
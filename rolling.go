@@ -0,0 +1,420 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+// RollingCovariance computes the sample covariance between x and y over a
+// sliding window of the given length. The value stored in dst[i] is the
+// covariance of x[i-window+1:i+1] and y[i-window+1:i+1]. The first window-1
+// elements of dst, for which the window is not yet full, are set to NaN.
+//
+// Pairs in which either x[i] or y[i] is NaN are skipped. If fewer than window
+// valid pairs fall into a window the result for that window is NaN; see
+// RollingCovarianceMinPeriods for relaxing this requirement.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored
+// in dst and dst is returned. The lengths of x, y and a non-nil dst must be
+// equal, and window must be positive.
+func RollingCovariance(dst, x, y []float64, window int) []float64 {
+	return RollingCovarianceMinPeriods(dst, x, y, window, window)
+}
+
+// RollingCovarianceMinPeriods is like RollingCovariance except that a value
+// is reported for every window containing at least minPeriods valid pairs,
+// including the partial windows at the start of the series. minPeriods must
+// be between 1 and window inclusive. At least two valid pairs are always
+// needed for a finite result.
+func RollingCovarianceMinPeriods(dst, x, y []float64, window, minPeriods int) []float64 {
+	dst = checkRolling(dst, x, y, window, minPeriods)
+	var c comoment
+	for i := range x {
+		c.slide(x, y, i, window)
+		if c.n < minPeriods || c.n < 2 {
+			dst[i] = math.NaN()
+			continue
+		}
+		dst[i] = c.cxy.sum() / float64(c.n-1)
+	}
+	return dst
+}
+
+// RollingCorrelation computes the Pearson correlation between x and y over a
+// sliding window of the given length. The value stored in dst[i] is the
+// correlation of x[i-window+1:i+1] and y[i-window+1:i+1]. The first window-1
+// elements of dst, for which the window is not yet full, are set to NaN.
+// Windows in which either series is constant have undefined correlation and
+// are also set to NaN.
+//
+// Pairs in which either x[i] or y[i] is NaN are skipped. If fewer than window
+// valid pairs fall into a window the result for that window is NaN; see
+// RollingCorrelationMinPeriods for relaxing this requirement.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored
+// in dst and dst is returned. The lengths of x, y and a non-nil dst must be
+// equal, and window must be positive.
+func RollingCorrelation(dst, x, y []float64, window int) []float64 {
+	return RollingCorrelationMinPeriods(dst, x, y, window, window)
+}
+
+// RollingCorrelationMinPeriods is like RollingCorrelation except that a value
+// is reported for every window containing at least minPeriods valid pairs,
+// including the partial windows at the start of the series. minPeriods must
+// be between 1 and window inclusive. At least two valid pairs are always
+// needed for a finite result.
+func RollingCorrelationMinPeriods(dst, x, y []float64, window, minPeriods int) []float64 {
+	dst = checkRolling(dst, x, y, window, minPeriods)
+	var c comoment
+	for i := range x {
+		c.slide(x, y, i, window)
+		if c.n < minPeriods || c.n < 2 || c.constX() || c.constY() {
+			dst[i] = math.NaN()
+			continue
+		}
+		sxx := c.cxx.sum()
+		syy := c.cyy.sum()
+		if sxx <= 0 || syy <= 0 {
+			dst[i] = math.NaN()
+			continue
+		}
+		r := c.cxy.sum() / math.Sqrt(sxx*syy)
+		// Guard against rounding pushing the result out of range.
+		dst[i] = math.Max(-1, math.Min(1, r))
+	}
+	return dst
+}
+
+func checkRolling(dst, x, y []float64, window, minPeriods int) []float64 {
+	checkLengths(x, y)
+	if window < 1 {
+		panic("stat: non-positive window")
+	}
+	if minPeriods < 1 || minPeriods > window {
+		panic("stat: minimum periods out of range")
+	}
+	return reuseFloats(dst, len(x))
+}
+
+// neumaier is a compensated running sum using Neumaier's variant of the
+// Kahan summation algorithm.
+type neumaier struct {
+	s, c float64
+}
+
+func (k *neumaier) add(v float64) {
+	t := k.s + v
+	if math.Abs(k.s) >= math.Abs(v) {
+		k.c += (k.s - t) + v
+	} else {
+		k.c += (v - t) + k.s
+	}
+	k.s = t
+}
+
+func (k *neumaier) sum() float64 {
+	return k.s + k.c
+}
+
+func (k *neumaier) set(v float64) {
+	k.s = v
+	k.c = 0
+}
+
+// comoment maintains the means and centered second moments of a window of
+// (x, y) pairs under insertion and deletion. The updates are the bivariate
+// form of Welford's algorithm, with the moments accumulated using
+// compensated summation so that long series do not drift.
+//
+// Runs of identical trailing values are tracked so that windows over a
+// constant series are recognized exactly rather than through a second
+// moment that is only approximately zero.
+type comoment struct {
+	n             int
+	mx, my        neumaier
+	cxx, cyy, cxy neumaier
+
+	lastX, lastY float64
+	runX, runY   int
+}
+
+// slide moves the window so that it ends at index i. Removing values from the
+// accumulated moments leaves a rounding residue that is large relative to the
+// moments of a window whose values are small compared to earlier ones, so the
+// moments are recomputed from scratch once every window steps. This keeps
+// the amortized cost constant.
+func (c *comoment) slide(x, y []float64, i, window int) {
+	if i < window {
+		c.add(x[i], y[i])
+		return
+	}
+	if i%window == 0 {
+		*c = comoment{}
+		for j := i - window + 1; j <= i; j++ {
+			c.add(x[j], y[j])
+		}
+		return
+	}
+	c.remove(x[i-window], y[i-window])
+	c.add(x[i], y[i])
+}
+
+func (c *comoment) add(x, y float64) {
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return
+	}
+	if c.n > 0 && x == c.lastX {
+		c.runX++
+	} else {
+		c.runX = 1
+	}
+	if c.n > 0 && y == c.lastY {
+		c.runY++
+	} else {
+		c.runY = 1
+	}
+	c.lastX, c.lastY = x, y
+
+	c.n++
+	n := float64(c.n)
+	dx := x - c.mx.sum()
+	dy := y - c.my.sum()
+	c.mx.add(dx / n)
+	c.my.add(dy / n)
+	// dx and dy are relative to the old means, the second factor is relative
+	// to the new means.
+	c.cxx.add(dx * (x - c.mx.sum()))
+	c.cyy.add(dy * (y - c.my.sum()))
+	c.cxy.add(dx * (y - c.my.sum()))
+	c.reset()
+}
+
+func (c *comoment) remove(x, y float64) {
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return
+	}
+	if c.n == 1 {
+		*c = comoment{}
+		return
+	}
+	c.n--
+	n := float64(c.n)
+	// dx and dy are relative to the means with the pair still present, the
+	// first factors below are relative to the means after its removal.
+	dx := x - c.mx.sum()
+	dy := y - c.my.sum()
+	c.mx.add(-dx / n)
+	c.my.add(-dy / n)
+	c.cxx.add(-(x - c.mx.sum()) * dx)
+	c.cyy.add(-(y - c.my.sum()) * dy)
+	c.cxy.add(-(x - c.mx.sum()) * dy)
+	c.reset()
+}
+
+// reset zeroes the moments of a constant series exactly.
+func (c *comoment) reset() {
+	if c.constX() {
+		c.mx.set(c.lastX)
+		c.cxx.set(0)
+		c.cxy.set(0)
+	}
+	if c.constY() {
+		c.my.set(c.lastY)
+		c.cyy.set(0)
+		c.cxy.set(0)
+	}
+}
+
+func (c *comoment) constX() bool { return c.runX >= c.n }
+func (c *comoment) constY() bool { return c.runY >= c.n }
+
+// RollingMean computes the mean of x over a sliding window of the given
+// length. Unlike RollingCovariance, only complete windows are reported: the
+// result has length len(x)-window+1 and dst[i] is the mean of
+// x[i:i+window]. The running mean is updated with compensated summation and
+// recomputed from scratch once every window steps, so that it does not drift
+// over long series. Windows containing NaN give NaN.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored
+// in dst and dst is returned. RollingMean panics if window is not positive,
+// if window is greater than len(x), or if a non-nil dst has the wrong
+// length.
+func RollingMean(dst, x []float64, window int) []float64 {
+	dst = checkWindow(dst, len(x), window)
+	var c comoment
+	for i := range x {
+		c.slide(x, x, i, window)
+		if j := i - window + 1; j >= 0 {
+			if c.n < window {
+				dst[j] = math.NaN()
+				continue
+			}
+			dst[j] = c.mx.sum()
+		}
+	}
+	return dst
+}
+
+// RollingStdDev computes the sample standard deviation of x, as StdDev with
+// nil weights, over a sliding window of the given length. The result has
+// length len(x)-window+1 and dst[i] is the standard deviation of
+// x[i:i+window]. The moments are updated by Welford's algorithm with the
+// same safeguards against drift as RollingMean, and windows over a constant
+// stretch of x give exactly zero. Windows containing NaN, and all windows of
+// length 1, give NaN.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored
+// in dst and dst is returned. RollingStdDev panics if window is not
+// positive, if window is greater than len(x), or if a non-nil dst has the
+// wrong length.
+func RollingStdDev(dst, x []float64, window int) []float64 {
+	dst = checkWindow(dst, len(x), window)
+	var c comoment
+	for i := range x {
+		c.slide(x, x, i, window)
+		if j := i - window + 1; j >= 0 {
+			if c.n < window || c.n < 2 {
+				dst[j] = math.NaN()
+				continue
+			}
+			// Rounding may leave a tiny negative moment.
+			dst[j] = math.Sqrt(math.Max(0, c.cxx.sum()) / float64(c.n-1))
+		}
+	}
+	return dst
+}
+
+// RollingMin computes the minimum of x over a sliding window of the given
+// length. The result has length len(x)-window+1 and dst[i] is the minimum of
+// x[i:i+window]. The candidates for the minimum are kept in a monotonic
+// queue, so that the computation takes O(len(x)) time for any window.
+// Windows containing NaN give NaN.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored
+// in dst and dst is returned. RollingMin panics if window is not positive,
+// if window is greater than len(x), or if a non-nil dst has the wrong
+// length.
+func RollingMin(dst, x []float64, window int) []float64 {
+	return rollingExtreme(dst, x, window, func(a, b float64) bool { return a <= b })
+}
+
+// RollingMax computes the maximum of x over a sliding window of the given
+// length, in the same way as RollingMin.
+func RollingMax(dst, x []float64, window int) []float64 {
+	return rollingExtreme(dst, x, window, func(a, b float64) bool { return a >= b })
+}
+
+// rollingExtreme computes the sliding window extreme of x, for which a
+// dominates b if better(a, b) is true.
+func rollingExtreme(dst, x []float64, window int, better func(a, b float64) bool) []float64 {
+	dst = checkWindow(dst, len(x), window)
+	// queue holds the indices of the values that may yet become the
+	// extreme of a window, in increasing order of index and with strictly
+	// dominating values towards the front. lastNaN is the index of the last
+	// NaN seen.
+	queue := make([]int, 0, window)
+	lastNaN := -1
+	for i, v := range x {
+		if len(queue) > 0 && queue[0] <= i-window {
+			queue = queue[1:]
+		}
+		if math.IsNaN(v) {
+			lastNaN = i
+		} else {
+			for len(queue) > 0 && better(v, x[queue[len(queue)-1]]) {
+				queue = queue[:len(queue)-1]
+			}
+			queue = append(queue, i)
+		}
+		if j := i - window + 1; j >= 0 {
+			if lastNaN >= j {
+				dst[j] = math.NaN()
+				continue
+			}
+			dst[j] = x[queue[0]]
+		}
+	}
+	return dst
+}
+
+// RollingQuantile computes the quantile p of x, as Quantile with nil weights,
+// over a sliding window of the given length. The result has length
+// len(x)-window+1 and dst[i] is the quantile of x[i:i+window]. The values of
+// the window are kept in an order statistic tree over the ranks of x, so that
+// the computation takes O(len(x) log len(x)) time for any window. Windows
+// containing NaN give NaN.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored
+// in dst and dst is returned. RollingQuantile panics if window is not
+// positive, if window is greater than len(x), if a non-nil dst has the
+// wrong length, if p is not between 0 and 1, or if c is not a valid
+// CumulantKind.
+func RollingQuantile(dst, x []float64, window int, p float64, c CumulantKind) []float64 {
+	if !(p >= 0 && p <= 1) {
+		panic("stat: percentile out of bounds")
+	}
+	if c != Empirical {
+		panic("stat: bad cumulant kind")
+	}
+	dst = checkWindow(dst, len(x), window)
+
+	// The quantile is the value of rank ⌈p window⌉ in the window, as the
+	// first value whose cumulative count reaches p window in Quantile.
+	k := math.Max(1, math.Ceil(p*float64(window)))
+
+	// Tied values are given distinct ranks, which does not change the
+	// order statistics. NaN values are not ranked, since they would break
+	// the ordering of the others.
+	order := make([]int, 0, len(x))
+	for i, v := range x {
+		if !math.IsNaN(v) {
+			order = append(order, i)
+		}
+	}
+	sort.Sort(indexSorter{idx: order, x: x})
+	rank := make([]int, len(x))
+	for r, i := range order {
+		rank[i] = r + 1
+	}
+	tree := make(fenwick, len(order)+1)
+	var nans int
+	for i, v := range x {
+		if math.IsNaN(v) {
+			nans++
+		} else {
+			tree.add(rank[i], 1)
+		}
+		if i >= window {
+			if math.IsNaN(x[i-window]) {
+				nans--
+			} else {
+				tree.add(rank[i-window], -1)
+			}
+		}
+		if j := i - window + 1; j >= 0 {
+			if nans > 0 {
+				dst[j] = math.NaN()
+				continue
+			}
+			dst[j] = x[order[tree.find(k)-1]]
+		}
+	}
+	return dst
+}
+
+// checkWindow checks the window of a series of length n for the rolling
+// statistics reported over complete windows, and returns dst if it has the
+// length of the result or a new slice if dst is nil.
+func checkWindow(dst []float64, n, window int) []float64 {
+	if window < 1 {
+		panic("stat: non-positive window")
+	}
+	if window > n {
+		panic("stat: window longer than data")
+	}
+	return reuseFloats(dst, n-window+1)
+}
@@ -0,0 +1,250 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestRollingCovarianceCorrelation(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	n := 200
+	x := make([]float64, n)
+	y := make([]float64, n)
+	for i := range x {
+		x[i] = rnd.NormFloat64()
+		y[i] = 0.5*x[i] + rnd.NormFloat64()
+	}
+	// Values around 1e8 with small fluctuations, where accumulating the raw
+	// sums of products loses all precision.
+	xBig := make([]float64, n)
+	yBig := make([]float64, n)
+	for i := range xBig {
+		xBig[i] = 1e8 + rnd.Float64()
+		yBig[i] = 1e8 - 2*xBig[i] + 2e8 + rnd.Float64()
+	}
+	for _, test := range []struct {
+		name   string
+		x, y   []float64
+		window int
+		tol    float64
+	}{
+		{"normal", x, y, 10, 1e-12},
+		{"normal", x, y, 1, 1e-12},
+		{"normal", x, y, 2, 1e-12},
+		{"normal", x, y, 57, 1e-12},
+		{"normal", x, y, n, 1e-12},
+		{"big", xBig, yBig, 20, 1e-6},
+	} {
+		cov := RollingCovariance(nil, test.x, test.y, test.window)
+		corr := RollingCorrelation(nil, test.x, test.y, test.window)
+		for i := range test.x {
+			if i < test.window-1 || test.window < 2 {
+				if !math.IsNaN(cov[i]) || !math.IsNaN(corr[i]) {
+					t.Errorf("%s window %d: expected NaN at %d, got %v and %v", test.name, test.window, i, cov[i], corr[i])
+				}
+				continue
+			}
+			xw := test.x[i-test.window+1 : i+1]
+			yw := test.y[i-test.window+1 : i+1]
+			wantCov := Covariance(xw, yw, nil)
+			if math.Abs(cov[i]-wantCov) > test.tol*math.Max(1, math.Abs(wantCov)) {
+				t.Errorf("%s window %d: covariance mismatch at %d. Want %v, got %v", test.name, test.window, i, wantCov, cov[i])
+			}
+			wantCorr := Correlation(xw, yw, nil)
+			if math.Abs(corr[i]-wantCorr) > test.tol {
+				t.Errorf("%s window %d: correlation mismatch at %d. Want %v, got %v", test.name, test.window, i, wantCorr, corr[i])
+			}
+		}
+	}
+
+	// A constant stretch must give NaN correlation rather than ±Inf.
+	xc := []float64{1, 2, 3, 4, 4, 4, 4, 5, 6}
+	yc := []float64{2, 1, 4, 3, 5, 2, 7, 1, 8}
+	corr := RollingCorrelation(nil, xc, yc, 3)
+	for i, want := range []bool{true, true, false, false, false, true, true, false, false} {
+		if math.IsNaN(corr[i]) != want {
+			t.Errorf("constant window: unexpected value at %d: %v", i, corr[i])
+		}
+	}
+	cov := RollingCovariance(nil, xc, yc, 3)
+	if cov[5] != 0 || cov[6] != 0 {
+		t.Errorf("constant window: expected zero covariance, got %v and %v", cov[5], cov[6])
+	}
+
+	// Minimum periods and NaN skipping.
+	xn := []float64{1, 2, math.NaN(), 4, 7, 3}
+	yn := []float64{2, 5, 1, 3, 1, 9}
+	cov = RollingCovarianceMinPeriods(nil, xn, yn, 3, 2)
+	for i, want := range []float64{
+		math.NaN(),
+		Covariance([]float64{1, 2}, []float64{2, 5}, nil),
+		Covariance([]float64{1, 2}, []float64{2, 5}, nil),
+		Covariance([]float64{2, 4}, []float64{5, 3}, nil),
+		Covariance([]float64{4, 7}, []float64{3, 1}, nil),
+		Covariance([]float64{4, 7, 3}, []float64{3, 1, 9}, nil),
+	} {
+		if !sameOrClose(cov[i], want, 1e-14) {
+			t.Errorf("min periods: covariance mismatch at %d. Want %v, got %v", i, want, cov[i])
+		}
+	}
+	cov = RollingCovariance(nil, xn, yn, 3)
+	for i := 0; i < 5; i++ {
+		if !math.IsNaN(cov[i]) {
+			t.Errorf("incomplete window: expected NaN at %d, got %v", i, cov[i])
+		}
+	}
+
+	dst := make([]float64, len(x))
+	if got := RollingCorrelation(dst, x, y, 5); &got[0] != &dst[0] {
+		t.Errorf("RollingCorrelation did not use the provided destination")
+	}
+
+	if !Panics(func() { RollingCovariance(nil, make([]float64, 3), make([]float64, 2), 2) }) {
+		t.Errorf("RollingCovariance did not panic with length mismatch")
+	}
+	if !Panics(func() { RollingCorrelation(make([]float64, 2), make([]float64, 3), make([]float64, 3), 2) }) {
+		t.Errorf("RollingCorrelation did not panic with dst length mismatch")
+	}
+	if !Panics(func() { RollingCorrelation(nil, make([]float64, 3), make([]float64, 3), 0) }) {
+		t.Errorf("RollingCorrelation did not panic with zero window")
+	}
+	if !Panics(func() { RollingCovarianceMinPeriods(nil, make([]float64, 3), make([]float64, 3), 2, 3) }) {
+		t.Errorf("RollingCovarianceMinPeriods did not panic with minPeriods > window")
+	}
+}
+
+// sameOrClose returns whether a and b are both NaN or are within tol of
+// each other.
+func sameOrClose(a, b, tol float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) <= tol
+}
+
+func BenchmarkRollingCorrelation(b *testing.B) {
+	x := RandomSlice(medium)
+	y := RandomSlice(medium)
+	dst := make([]float64, medium)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RollingCorrelation(dst, x, y, small)
+	}
+}
+
+func TestRollingStatistics(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	n := 300
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rnd.NormFloat64()
+		if i%7 == 0 {
+			// Ties for the order statistics.
+			x[i] = float64(i % 3)
+		}
+	}
+	// Values around 1e9 with small fluctuations, where running sums of raw
+	// values and their squares lose all precision.
+	big := make([]float64, n)
+	for i := range big {
+		big[i] = 1e9 + rnd.Float64()
+	}
+	for _, test := range []struct {
+		name   string
+		x      []float64
+		window int
+		tol    float64
+	}{
+		{"normal", x, 1, 1e-14},
+		{"normal", x, 2, 1e-14},
+		{"normal", x, 10, 1e-13},
+		{"normal", x, 57, 1e-13},
+		{"normal", x, n, 1e-13},
+		{"big", big, 20, 1e-6},
+	} {
+		m := len(test.x) - test.window + 1
+		mean := RollingMean(nil, test.x, test.window)
+		std := RollingStdDev(nil, test.x, test.window)
+		min := RollingMin(nil, test.x, test.window)
+		max := RollingMax(nil, test.x, test.window)
+		median := RollingQuantile(nil, test.x, test.window, 0.5, Empirical)
+		q90 := RollingQuantile(nil, test.x, test.window, 0.9, Empirical)
+		for _, got := range [][]float64{mean, std, min, max, median, q90} {
+			if len(got) != m {
+				t.Fatalf("%s window %d: want %d results, got %d", test.name, test.window, m, len(got))
+			}
+		}
+		for i := 0; i < m; i++ {
+			w := test.x[i : i+test.window]
+			sorted := append([]float64(nil), w...)
+			sort.Float64s(sorted)
+			if want := Mean(w, nil); math.Abs(mean[i]-want) > test.tol*math.Max(1, math.Abs(want)) {
+				t.Errorf("%s window %d: mean mismatch at %d. Want %v, got %v", test.name, test.window, i, want, mean[i])
+			}
+			if want := StdDev(w, nil); !sameOrClose(std[i], want, test.tol) {
+				t.Errorf("%s window %d: standard deviation mismatch at %d. Want %v, got %v", test.name, test.window, i, want, std[i])
+			}
+			if min[i] != sorted[0] || max[i] != sorted[len(sorted)-1] {
+				t.Errorf("%s window %d: extremes mismatch at %d. Want %v and %v, got %v and %v", test.name, test.window, i, sorted[0], sorted[len(sorted)-1], min[i], max[i])
+			}
+			if want := Quantile(0.5, Empirical, sorted, nil); median[i] != want {
+				t.Errorf("%s window %d: median mismatch at %d. Want %v, got %v", test.name, test.window, i, want, median[i])
+			}
+			if want := Quantile(0.9, Empirical, sorted, nil); q90[i] != want {
+				t.Errorf("%s window %d: quantile mismatch at %d. Want %v, got %v", test.name, test.window, i, want, q90[i])
+			}
+		}
+	}
+
+	// Constant stretches give exactly zero standard deviation, and windows
+	// containing NaN give NaN.
+	xc := []float64{1, 4, 4, 4, math.NaN(), 2, 5, 3}
+	for _, test := range []struct {
+		name string
+		got  []float64
+		want []float64
+	}{
+		{"mean", RollingMean(nil, xc, 3), []float64{3, 4, math.NaN(), math.NaN(), math.NaN(), 10.0 / 3}},
+		{"standard deviation", RollingStdDev(nil, xc, 3), []float64{math.Sqrt(3), 0, math.NaN(), math.NaN(), math.NaN(), 1.5275252316519465}},
+		{"minimum", RollingMin(nil, xc, 3), []float64{1, 4, math.NaN(), math.NaN(), math.NaN(), 2}},
+		{"maximum", RollingMax(nil, xc, 3), []float64{4, 4, math.NaN(), math.NaN(), math.NaN(), 5}},
+		{"minimum", RollingQuantile(nil, xc, 3, 0, Empirical), []float64{1, 4, math.NaN(), math.NaN(), math.NaN(), 2}},
+		{"median", RollingQuantile(nil, xc, 3, 0.5, Empirical), []float64{4, 4, math.NaN(), math.NaN(), math.NaN(), 3}},
+	} {
+		if !sameFloatsNaN(test.got, test.want, 1e-15) {
+			t.Errorf("rolling %s mismatch. Want %v, got %v", test.name, test.want, test.got)
+		}
+	}
+
+	dst := make([]float64, n-4)
+	if got := RollingMax(dst, x, 5); &got[0] != &dst[0] {
+		t.Errorf("RollingMax did not use the provided destination")
+	}
+	for i, fn := range []func(){
+		func() { RollingMean(nil, x, 0) },
+		func() { RollingStdDev(nil, x[:3], 4) },
+		func() { RollingMin(make([]float64, n), x, 5) },
+		func() { RollingMax(nil, nil, 1) },
+		func() { RollingQuantile(nil, x, 5, 1.5, Empirical) },
+		func() { RollingQuantile(nil, x, 5, 0.5, CumulantKind(0)) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func BenchmarkRollingQuantile(b *testing.B) {
+	x := RandomSlice(medium)
+	dst := make([]float64, medium-small+1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RollingQuantile(dst, x, small, 0.5, Empirical)
+	}
+}
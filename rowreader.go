@@ -0,0 +1,123 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// RowReader is a source of the rows of a data matrix that need not be held
+// in memory, such as a file or a memory-mapped region.
+type RowReader interface {
+	// Dims returns the numbers of rows and columns of the matrix.
+	Dims() (r, c int)
+
+	// ReadRows reads the n rows starting at row start into dst, in
+	// row-major order. The length of dst is n times the number of columns.
+	// ReadRows may be called concurrently for different rows when the
+	// computations of the package run in parallel, as configured by
+	// Parallel.
+	ReadRows(dst []float64, start, n int) error
+}
+
+// MatrixRowReader is a RowReader reading the rows of a matrix held in memory.
+type MatrixRowReader struct {
+	mat64.Matrix
+}
+
+// ReadRows implements the RowReader interface. It panics if the rows are out
+// of range or if the length of dst is wrong.
+func (m MatrixRowReader) ReadRows(dst []float64, start, n int) error {
+	r, c := m.Dims()
+	if start < 0 || n < 0 || start+n > r {
+		panic("stat: row out of range")
+	}
+	if len(dst) != n*c {
+		panic(ErrLengthMismatch{Got: len(dst), Want: n * c})
+	}
+	if d, ok := m.Matrix.(*mat64.Dense); ok {
+		for i := 0; i < n; i++ {
+			copy(dst[i*c:(i+1)*c], d.RawRowView(start+i))
+		}
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < c; j++ {
+			dst[i*c+j] = m.At(start+i, j)
+		}
+	}
+	return nil
+}
+
+// BinaryRowReader is a RowReader reading the rows of a matrix stored in
+// row-major order as consecutive IEEE 754 binary64 values, such as a flat
+// binary file.
+type BinaryRowReader struct {
+	r          io.ReaderAt
+	offset     int64
+	rows, cols int
+	order      binary.ByteOrder
+}
+
+// NewBinaryRowReader returns a BinaryRowReader for the rows×cols matrix
+// starting at byte offset in r and stored with the given byte order.
+func NewBinaryRowReader(r io.ReaderAt, offset int64, rows, cols int, order binary.ByteOrder) *BinaryRowReader {
+	if rows < 0 || cols < 0 {
+		panic("stat: negative dimension")
+	}
+	return &BinaryRowReader{r: r, offset: offset, rows: rows, cols: cols, order: order}
+}
+
+// Dims implements the RowReader interface.
+func (b *BinaryRowReader) Dims() (r, c int) {
+	return b.rows, b.cols
+}
+
+// ReadRows implements the RowReader interface. It panics if the rows are out
+// of range or if the length of dst is wrong, and returns the error of the
+// underlying io.ReaderAt if the read fails.
+func (b *BinaryRowReader) ReadRows(dst []float64, start, n int) error {
+	if start < 0 || n < 0 || start+n > b.rows {
+		panic("stat: row out of range")
+	}
+	if len(dst) != n*b.cols {
+		panic(ErrLengthMismatch{Got: len(dst), Want: n * b.cols})
+	}
+	buf := make([]byte, 8*len(dst))
+	_, err := b.r.ReadAt(buf, b.offset+8*int64(start)*int64(b.cols))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = math.Float64frombits(b.order.Uint64(buf[8*i:]))
+	}
+	return nil
+}
+
+// CovarianceMatrixFromReader returns the covariance matrix of the rows read
+// from r, as CovarianceMatrix does for a matrix in memory. The rows are read
+// in blocks of a few hundred, once to compute the means and once to
+// accumulate the co-moments, so the memory used does not depend on the number
+// of rows. The result may differ from that of CovarianceMatrix by floating
+// point rounding.
+//
+// CovarianceMatrixFromReader panics for the same invalid arguments as
+// CovarianceMatrix. If a read fails, it returns the error and leaves cov
+// unchanged.
+func CovarianceMatrixFromReader(cov *mat64.Dense, r RowReader, wts []float64) (*mat64.Dense, error) {
+	rows, cols := r.Dims()
+	if err := validateCovarianceDims(cov, rows, cols, wts); err != nil {
+		panic(err)
+	}
+	return covarianceRows(context.Background(), cov, r, wts)
+}
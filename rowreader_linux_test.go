@@ -0,0 +1,48 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"encoding/binary"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// maxRSS returns the peak resident set size of the process in bytes.
+func maxRSS(b *testing.B) int64 {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		b.Fatal(err)
+	}
+	// Maxrss is in kilobytes on Linux.
+	return int64(ru.Maxrss) * 1024
+}
+
+func BenchmarkCovarianceMatrixFromReaderLarge(b *testing.B) {
+	// A file-backed 1e7×20 matrix, 1.6GB of data. The peak resident set
+	// size of the process should grow by about the size of a few blocks of
+	// rows, not by the size of the data. The file is large, so the
+	// benchmark is skipped in short mode.
+	if testing.Short() {
+		b.Skip("skipping 1.6GB file-backed benchmark in short mode")
+	}
+	const r, c = 10000000, 20
+	f := randomBinaryFile(b, r, c)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	rr := NewBinaryRowReader(f, 0, r, c, binary.LittleEndian)
+	before := maxRSS(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CovarianceMatrixFromReader(nil, rr, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	after := maxRSS(b)
+	b.Logf("peak RSS %d bytes before, %d bytes after (growth %d bytes for %d bytes of data)", before, after, after-before, 8*r*c)
+}
@@ -0,0 +1,161 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// binaryMatrix returns the values of m encoded as by BinaryRowReader.
+func binaryMatrix(m *mat64.Dense, order binary.ByteOrder) []byte {
+	r, c := m.Dims()
+	b := make([]byte, 8*r*c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			order.PutUint64(b[8*(i*c+j):], math.Float64bits(m.At(i, j)))
+		}
+	}
+	return b
+}
+
+// failingReaderAt fails all reads.
+type failingReaderAt struct{}
+
+func (failingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestCovarianceMatrixFromReader(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, r := range []int{2, 10, covBlockRows, 3*covBlockRows + 7} {
+		c := 4
+		x := mat64.NewDense(r, c, nil)
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				x.Set(i, j, 10+rnd.NormFloat64()*float64(j+1))
+			}
+		}
+		wts := make([]float64, r)
+		for i := range wts {
+			wts[i] = rnd.Float64()
+		}
+		var xt mat64.Dense
+		xt.TCopy(x)
+		header := []byte("header")
+		data := append(header, binaryMatrix(x, binary.LittleEndian)...)
+
+		for _, w := range [][]float64{nil, wts} {
+			want := CovarianceMatrix(nil, x, w)
+			for _, rr := range []RowReader{
+				MatrixRowReader{x},
+				MatrixRowReader{transposed{&xt}},
+				NewBinaryRowReader(bytes.NewReader(data), int64(len(header)), r, c, binary.LittleEndian),
+			} {
+				dst := mat64.NewDense(c, c, nil)
+				got, err := CovarianceMatrixFromReader(dst, rr, w)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != dst {
+					t.Errorf("result not stored in destination")
+				}
+				if !got.EqualsApprox(want, 1e-12) {
+					t.Errorf("r=%d: covariance mismatch: got %v, want %v", r, got, want)
+				}
+			}
+		}
+	}
+
+	x := mat64.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 7})
+	dst := mat64.NewDense(2, 2, nil)
+	_, err := CovarianceMatrixFromReader(dst, NewBinaryRowReader(failingReaderAt{}, 0, 3, 2, binary.BigEndian), nil)
+	if err == nil {
+		t.Errorf("expected read error")
+	}
+	// A file too short for the declared dimensions.
+	short := binaryMatrix(x, binary.BigEndian)[:40]
+	_, err = CovarianceMatrixFromReader(nil, NewBinaryRowReader(bytes.NewReader(short), 0, 3, 2, binary.BigEndian), nil)
+	if err == nil {
+		t.Errorf("expected error for short data")
+	}
+	if !dst.Equals(mat64.NewDense(2, 2, nil)) {
+		t.Errorf("destination modified on error")
+	}
+	for _, f := range []func(){
+		func() { CovarianceMatrixFromReader(mat64.NewDense(3, 3, nil), MatrixRowReader{x}, nil) },
+		func() { CovarianceMatrixFromReader(nil, MatrixRowReader{x}, []float64{1, 1}) },
+		func() { CovarianceMatrixFromReader(nil, MatrixRowReader{x}, []float64{1, -1, 1}) },
+		func() { MatrixRowReader{x}.ReadRows(make([]float64, 4), 2, 2) },
+		func() { MatrixRowReader{x}.ReadRows(make([]float64, 3), 0, 2) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+// transposed is the transpose of a matrix, accessed through At, to exercise
+// the generic path of MatrixRowReader.
+type transposed struct {
+	m mat64.Matrix
+}
+
+func (t transposed) Dims() (r, c int)    { c, r = t.m.Dims(); return r, c }
+func (t transposed) At(i, j int) float64 { return t.m.At(j, i) }
+
+func BenchmarkCovarianceMatrixFromReader(b *testing.B) {
+	// A file-backed 2e5×20 matrix. The memory allocated per operation is
+	// that of a block of rows, not of the 32MB of data.
+	const r, c = 200000, 20
+	f := randomBinaryFile(b, r, c)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	rr := NewBinaryRowReader(f, 0, r, c, binary.LittleEndian)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CovarianceMatrixFromReader(nil, rr, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// randomBinaryFile returns a temporary file holding an r×c matrix of uniform
+// random values encoded as by BinaryRowReader in little-endian order. The
+// caller is responsible for closing and removing the file.
+func randomBinaryFile(b *testing.B, r, c int) *os.File {
+	f, err := ioutil.TempFile("", "stat-rowreader")
+	if err != nil {
+		b.Fatal(err)
+	}
+	w := bufio.NewWriter(f)
+	row := make([]byte, 8*c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			binary.LittleEndian.PutUint64(row[8*j:], math.Float64bits(rand.Float64()))
+		}
+		if _, err := w.Write(row); err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			b.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		b.Fatal(err)
+	}
+	return f
+}
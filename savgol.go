@@ -0,0 +1,116 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import "github.com/gonum/floats"
+
+// SavitzkyGolay applies a Savitzky–Golay filter to the series x, returning
+// the smoothed series if deriv is zero, or the smoothed derivative of order
+// deriv otherwise. At each point, a polynomial of degree polyOrder is fitted
+// by least squares to the window of the given length centered on the point,
+// and the filtered value is the deriv-th derivative of the fitted polynomial
+// at the point. The samples of x are assumed to be at unit spacing; divide
+// the result by h^deriv for a sample spacing of h.
+//
+// Near the ends of the series, where a centered window does not fit, the
+// polynomial fitted to the first or last window of x is evaluated at the
+// remaining points instead of padding the series. This matches the "interp"
+// mode of scipy.signal.savgol_filter.
+//
+// If dst is nil, a new slice is allocated, otherwise the result is stored in
+// dst and dst is returned. SavitzkyGolay panics if window is not a positive
+// odd number, if polyOrder is negative or not less than window, if deriv is
+// negative, if window is longer than x, or if a non-nil dst has the wrong
+// length.
+func SavitzkyGolay(dst, x []float64, window, polyOrder, deriv int) []float64 {
+	if window < 1 || window%2 == 0 {
+		panic("stat: window not a positive odd number")
+	}
+	if polyOrder < 0 || polyOrder >= window {
+		panic("stat: polynomial order out of range")
+	}
+	if deriv < 0 {
+		panic("stat: negative derivative order")
+	}
+	n := len(x)
+	if window > n {
+		panic("stat: window longer than series")
+	}
+	dst = reuseFloats(dst, n)
+	if deriv > polyOrder {
+		for i := range dst {
+			dst[i] = 0
+		}
+		return dst
+	}
+
+	h := window / 2
+	scale := float64(h)
+	if h == 0 {
+		scale = 1
+	}
+	// Positions within the window are scaled to [-1, 1] to keep the
+	// Vandermonde design well conditioned.
+	t := make([]float64, window)
+	for i := range t {
+		t[i] = float64(i-h) / scale
+	}
+	f := newQR(vandermonde(t, polyOrder))
+	if !f.fullRank() {
+		panic("stat: rank deficient design matrix")
+	}
+	// pinv[j*window+k] is the coefficient of the j-th power in the
+	// polynomial fitted to the unit vector e_k.
+	pinv := make([]float64, (polyOrder+1)*window)
+	e := make([]float64, window)
+	coef := make([]float64, polyOrder+1)
+	for k := range e {
+		e[k] = 1
+		f.solve(coef, e)
+		e[k] = 0
+		for j, c := range coef {
+			pinv[j*window+k] = c
+		}
+	}
+
+	// weights returns the filter weights that evaluate the deriv-th
+	// derivative of the fitted polynomial at the scaled position u.
+	weights := func(dst []float64, u float64) []float64 {
+		for k := range dst {
+			dst[k] = 0
+		}
+		for j := deriv; j <= polyOrder; j++ {
+			// d^deriv/dt^deriv t^j = j!/(j-deriv)! t^(j-deriv), with an extra
+			// factor of 1/scale per derivative from the change of variable.
+			c := 1.0
+			for m := j - deriv + 1; m <= j; m++ {
+				c *= float64(m)
+			}
+			for m := 0; m < j-deriv; m++ {
+				c *= u
+			}
+			for m := 0; m < deriv; m++ {
+				c /= scale
+			}
+			floats.AddScaled(dst, c, pinv[j*window:(j+1)*window])
+		}
+		return dst
+	}
+
+	// Compute the output into a temporary in case dst and x alias.
+	out := make([]float64, n)
+	w := weights(make([]float64, window), 0)
+	for i := h; i < n-h; i++ {
+		out[i] = floats.Dot(w, x[i-h:i+h+1])
+	}
+	for i := 0; i < h; i++ {
+		weights(w, float64(i-h)/scale)
+		out[i] = floats.Dot(w, x[:window])
+		weights(w, float64(h-i)/scale)
+		out[n-1-i] = floats.Dot(w, x[n-window:])
+	}
+	copy(dst, out)
+	return dst
+}
@@ -0,0 +1,122 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestSavitzkyGolay(t *testing.T) {
+	for i, test := range []struct {
+		x                    []float64
+		window, order, deriv int
+		want                 []float64
+	}{
+		{
+			// The example from the scipy.signal.savgol_filter documentation.
+			x:      []float64{2, 2, 5, 2, 1, 0, 1, 4, 9},
+			window: 5, order: 2, deriv: 0,
+			want: []float64{
+				1.6571428571428573, 3.1714285714285713, 3.5428571428571427, 2.857142857142857,
+				0.6571428571428571, 0.17142857142857143, 1, 4, 9,
+			},
+		},
+		{
+			x:      []float64{2, 2, 5, 2, 1, 0, 1, 4, 9},
+			window: 5, order: 2, deriv: 1,
+			want: []float64{2.085714285714286, 0.9428571428571428, -0.2, -0.8, -1, 0.4, 2, 4, 6},
+		},
+		{
+			x:      []float64{1.5, -0.3, 2.7, 4.1, 3.3, 5.9, 6.2, 4.8, 7.7, 9.1, 8.4, 10.6},
+			window: 7, order: 3, deriv: 0,
+			want: []float64{
+				1.0333333333333192, 1.0571428571428718, 1.8785714285714445, 3.1476190476190498,
+				4.800000000000001, 4.961904761904765, 5.438095238095244, 6.600000000000001,
+				7.0523809523809575, 8.285714285714267, 9.457142857142838, 10.266666666666687,
+			},
+		},
+		{
+			x:      []float64{1.5, -0.3, 2.7, 4.1, 3.3, 5.9, 6.2, 4.8, 7.7, 9.1, 8.4, 10.6},
+			window: 7, order: 3, deriv: 1,
+			want: []float64{
+				-0.4916666666666192, 0.4809523809523936, 1.10357142857142, 1.3761904761904598,
+				0.8996031746031776, 0.41706349206348103, 0.4801587301587029, 0.7507936507936506,
+				1.1642857142856886, 1.2523809523809364, 1.0404761904762063, 0.5285714285714977,
+			},
+		},
+		{
+			x:      []float64{1.5, -0.3, 2.7, 4.1, 3.3, 5.9, 6.2, 4.8, 7.7, 9.1, 8.4, 10.6},
+			window: 7, order: 3, deriv: 2,
+			want: []float64{
+				1.147619047619006, 0.7976190476190196, 0.44761904761903315, 0.0976190476190466,
+				-0.4928571428571426, -0.0023809523809537048, 0.21666666666666445, -0.05714285714285791,
+				0.23809523809523692, -0.06190476190474126, -0.3619047619047193, -0.6619047619046976,
+			},
+		},
+		{
+			x:      []float64{3, 1, 4, 1, 5},
+			window: 1, order: 0, deriv: 0,
+			want: []float64{3, 1, 4, 1, 5},
+		},
+		{
+			x:      []float64{3, 1, 4, 1, 5},
+			window: 3, order: 1, deriv: 2,
+			want: []float64{0, 0, 0, 0, 0},
+		},
+	} {
+		got := SavitzkyGolay(nil, test.x, test.window, test.order, test.deriv)
+		if !floats.EqualApprox(got, test.want, 1e-10) {
+			t.Errorf("case %d: mismatch. Want %v, got %v", i, test.want, got)
+		}
+	}
+
+	// A polynomial of degree at most the order is reproduced exactly, along
+	// with its derivatives, including at the ends of the series.
+	x := make([]float64, 40)
+	d1 := make([]float64, len(x))
+	for i := range x {
+		v := float64(i)
+		x[i] = 2 - v + 0.3*v*v - 0.01*v*v*v
+		d1[i] = -1 + 0.6*v - 0.03*v*v
+	}
+	got := SavitzkyGolay(nil, x, 11, 3, 0)
+	if !floats.EqualApprox(got, x, 1e-10) {
+		t.Errorf("cubic not reproduced")
+	}
+	got = SavitzkyGolay(nil, x, 11, 4, 1)
+	if !floats.EqualApprox(got, d1, 1e-10) {
+		t.Errorf("cubic derivative not reproduced")
+	}
+
+	// In-place filtering.
+	y := make([]float64, len(x))
+	copy(y, x)
+	want := SavitzkyGolay(nil, y, 7, 2, 0)
+	SavitzkyGolay(y, y, 7, 2, 0)
+	if !floats.Equal(y, want) {
+		t.Errorf("in-place filtering mismatch")
+	}
+
+	for _, test := range []struct {
+		window, order, deriv int
+	}{
+		{4, 2, 0},
+		{0, 0, 0},
+		{5, 5, 0},
+		{5, -1, 0},
+		{5, 2, -1},
+		{41, 2, 0},
+	} {
+		if !Panics(func() { SavitzkyGolay(nil, x, test.window, test.order, test.deriv) }) {
+			t.Errorf("SavitzkyGolay did not panic with window %d, order %d, deriv %d", test.window, test.order, test.deriv)
+		}
+	}
+	if math.IsNaN(SavitzkyGolay(nil, x, 39, 2, 0)[0]) {
+		t.Errorf("unexpected NaN with window nearly the series length")
+	}
+}
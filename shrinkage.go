@@ -0,0 +1,226 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// ShrinkageTarget specifies the structured matrix towards which
+// ShrunkCovarianceMatrix shrinks the sample covariance matrix.
+type ShrinkageTarget int
+
+const (
+	// IdentityTarget is the scaled identity matrix μI, where μ is the
+	// average of the sample variances, as in Ledoit and Wolf, "A
+	// well-conditioned estimator for large-dimensional covariance
+	// matrices", Journal of Multivariate Analysis, 88, 2004.
+	IdentityTarget ShrinkageTarget = iota
+	// ConstantCorrelationTarget is the matrix with the sample variances on
+	// the diagonal and the covariances of a constant correlation r̄, the
+	// average of the sample correlations, off it,
+	//  F_ij = r̄ √(s_ii s_jj)
+	// as in Ledoit and Wolf, "Honey, I shrunk the sample covariance
+	// matrix", Journal of Portfolio Management, 30, 2004. It suits data
+	// such as asset returns, whose correlations are mostly of one sign.
+	ConstantCorrelationTarget
+)
+
+// ShrunkCovarianceMatrix calculates the Ledoit–Wolf shrinkage estimate of the
+// covariance matrix of the rows of x,
+//  (1-δ) S + δ F
+// where S is the sample covariance matrix computed by CovarianceMatrix, F is
+// the matrix specified by target computed from S, and δ in [0, 1] is the
+// shrinkage intensity minimizing the expected squared Frobenius distance to
+// the true covariance matrix, which is returned along with the estimate.
+// The estimate is positive definite for IdentityTarget and δ > 0 even when
+// there are fewer rows than columns, in which case S is singular.
+//
+// δ is the estimate of Ledoit and Wolf computed from the moments of the
+// centered rows with divisor n, as in their papers and in scikit-learn; it is
+// O(1/n) in large samples, where S is accurate. The weights are frequency
+// weights, so that a row with an integer weight k counts as k rows, and the
+// divisor is their total. ShrunkCovarianceMatrixIntensity computes the
+// estimate with a given intensity.
+//
+// If cov is nil, a new matrix is allocated, otherwise it must be square with
+// as many rows as x has columns. wts is as for CovarianceMatrix. The
+// estimate is NaN for ConstantCorrelationTarget if a column is constant.
+// ShrunkCovarianceMatrix panics if target is not a valid ShrinkageTarget.
+func ShrunkCovarianceMatrix(cov *mat64.Dense, x mat64.Matrix, wts []float64, target ShrinkageTarget) (shrunk *mat64.Dense, intensity float64) {
+	if target != IdentityTarget && target != ConstantCorrelationTarget {
+		panic("stat: bad shrinkage target")
+	}
+	if err := ValidateCovarianceMatrix(cov, x, wts); err != nil {
+		panic(err)
+	}
+	intensity = shrinkageIntensity(x, wts, target)
+	return ShrunkCovarianceMatrixIntensity(cov, x, wts, target, intensity), intensity
+}
+
+// ShrunkCovarianceMatrixIntensity is like ShrunkCovarianceMatrix, but uses
+// the given shrinkage intensity, which must be in [0, 1]. With intensity 0
+// the result is that of CovarianceMatrix, and with intensity 1 it is the
+// target.
+func ShrunkCovarianceMatrixIntensity(cov *mat64.Dense, x mat64.Matrix, wts []float64, target ShrinkageTarget, intensity float64) *mat64.Dense {
+	if target != IdentityTarget && target != ConstantCorrelationTarget {
+		panic("stat: bad shrinkage target")
+	}
+	if !(intensity >= 0 && intensity <= 1) {
+		panic("stat: shrinkage intensity out of range")
+	}
+	cov = CovarianceMatrix(cov, x, wts)
+	if intensity == 0 {
+		return cov
+	}
+	p, _ := cov.Dims()
+	f := shrinkageTarget(cov, target)
+	for i := 0; i < p; i++ {
+		row := cov.RawRowView(i)
+		for j := range row {
+			row[j] = (1-intensity)*row[j] + intensity*f[i*p+j]
+		}
+	}
+	return cov
+}
+
+// shrinkageTarget returns the target matrix computed from the covariance
+// matrix s, in row-major order.
+func shrinkageTarget(s mat64.Matrix, target ShrinkageTarget) []float64 {
+	p, _ := s.Dims()
+	f := make([]float64, p*p)
+	switch target {
+	case IdentityTarget:
+		var mu float64
+		for i := 0; i < p; i++ {
+			mu += s.At(i, i)
+		}
+		mu /= float64(p)
+		for i := 0; i < p; i++ {
+			f[i*p+i] = mu
+		}
+	case ConstantCorrelationTarget:
+		sd := make([]float64, p)
+		for i := range sd {
+			sd[i] = math.Sqrt(s.At(i, i))
+		}
+		r := averageCorrelation(s, sd)
+		for i := 0; i < p; i++ {
+			f[i*p+i] = s.At(i, i)
+			for j := i + 1; j < p; j++ {
+				f[i*p+j] = r * sd[i] * sd[j]
+				f[j*p+i] = f[i*p+j]
+			}
+		}
+	}
+	return f
+}
+
+// averageCorrelation returns the average of the off-diagonal correlations of
+// the covariance matrix s with the standard deviations sd, or 0 if there is
+// a single column.
+func averageCorrelation(s mat64.Matrix, sd []float64) float64 {
+	p := len(sd)
+	if p < 2 {
+		return 0
+	}
+	var r float64
+	for i := 0; i < p; i++ {
+		for j := i + 1; j < p; j++ {
+			r += s.At(i, j) / (sd[i] * sd[j])
+		}
+	}
+	return 2 * r / float64(p*(p-1))
+}
+
+// shrinkageIntensity returns the Ledoit–Wolf estimate of the optimal
+// shrinkage intensity of the covariance matrix of the rows of x towards
+// target, computed from the weighted moments of the centered rows with
+// divisor the total weight.
+func shrinkageIntensity(x mat64.Matrix, wts []float64, target ShrinkageTarget) float64 {
+	r, p := x.Dims()
+	weight := func(i int) float64 {
+		if wts == nil {
+			return 1
+		}
+		return wts[i]
+	}
+	d := mat64.NewDense(r, p, nil)
+	CenterColumns(d, x, wts)
+	var n float64
+	for i := 0; i < r; i++ {
+		n += weight(i)
+	}
+	// s is the covariance matrix with divisor n.
+	s := make([]float64, p*p)
+	for i := 0; i < r; i++ {
+		w := weight(i)
+		row := d.RawRowView(i)
+		for j, v := range row {
+			floats.AddScaled(s[j*p:(j+1)*p], w*v/n, row)
+		}
+	}
+	sm := mat64.NewDense(p, p, s)
+	f := shrinkageTarget(sm, target)
+	// gamma is the squared distance of the sample covariance matrix from
+	// the target.
+	gamma := floats.Distance(s, f, 2)
+	gamma *= gamma
+	if gamma == 0 {
+		// S is the target, and the intensity is immaterial.
+		return 0
+	}
+
+	switch target {
+	case IdentityTarget:
+		// The sum of the squared distances of the outer products of the
+		// rows from S is \sum_i w_i |d_i|⁴ - n |S|².
+		var sum float64
+		for i := 0; i < r; i++ {
+			sq := floats.Dot(d.RawRowView(i), d.RawRowView(i))
+			sum += weight(i) * sq * sq
+		}
+		norm := floats.Norm(s, 2)
+		beta := (sum/n - norm*norm) / n
+		return math.Min(beta, gamma) / gamma
+	default:
+		// pi is the sum over the elements of the variances of the
+		// products of the centered columns, and rho the sum of their
+		// covariances with the target, in the notation of Ledoit and
+		// Wolf.
+		sd := make([]float64, p)
+		for i := range sd {
+			sd[i] = math.Sqrt(s[i*p+i])
+		}
+		rbar := averageCorrelation(sm, sd)
+		var pi, rho float64
+		for a := 0; a < p; a++ {
+			for b := 0; b < p; b++ {
+				var piAB, theta float64
+				for i := 0; i < r; i++ {
+					row := d.RawRowView(i)
+					w := weight(i)
+					prod := row[a]*row[b] - s[a*p+b]
+					piAB += w * prod * prod
+					if a != b {
+						theta += w * (row[a]*row[a] - s[a*p+a]) * prod
+					}
+				}
+				piAB /= n
+				pi += piAB
+				if a == b {
+					rho += piAB
+				} else {
+					rho += rbar * sd[b] / sd[a] * theta / n
+				}
+			}
+		}
+		kappa := (pi - rho) / gamma
+		return math.Max(0, math.Min(1, kappa/n))
+	}
+}
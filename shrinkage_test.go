@@ -0,0 +1,141 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestShrunkCovarianceMatrix(t *testing.T) {
+	// Intensities computed directly from the definitions, as in
+	// ledoit_wolf of scikit-learn and the covCor code of Ledoit and Wolf.
+	x := mat64.NewDense(8, 3, []float64{
+		1.2, 0.5, -0.3,
+		0.4, 1.1, 0.2,
+		-0.7, -0.2, 0.9,
+		2.1, 1.5, -1.0,
+		0.0, 0.3, 0.4,
+		-1.3, -0.9, 1.2,
+		0.8, 0.2, -0.5,
+		0.5, 1.0, 0.1,
+	})
+	sample := CovarianceMatrix(nil, x, nil)
+	for _, test := range []struct {
+		target    ShrinkageTarget
+		intensity float64
+	}{
+		{IdentityTarget, 0.31162699273990735},
+		{ConstantCorrelationTarget, 0.2098919535601778},
+	} {
+		cov, delta := ShrunkCovarianceMatrix(nil, x, nil, test.target)
+		if math.Abs(delta-test.intensity) > 1e-14 {
+			t.Errorf("target %d: intensity mismatch. Want %v, got %v", test.target, test.intensity, delta)
+		}
+		want := ShrunkCovarianceMatrixIntensity(nil, x, nil, test.target, delta)
+		if !floats.Equal(cov.RawMatrix().Data, want.RawMatrix().Data) {
+			t.Errorf("target %d: estimate mismatch with the intensity %v", test.target, delta)
+		}
+
+		// Intensity 0 gives the sample covariance matrix exactly, and
+		// intensity 1 the target.
+		got := ShrunkCovarianceMatrixIntensity(mat64.NewDense(3, 3, nil), x, nil, test.target, 0)
+		if !floats.Equal(got.RawMatrix().Data, sample.RawMatrix().Data) {
+			t.Errorf("target %d: intensity 0 does not give CovarianceMatrix", test.target)
+		}
+		f := ShrunkCovarianceMatrixIntensity(nil, x, nil, test.target, 1)
+		for i := 0; i < 3; i++ {
+			if math.Abs(f.At(i, i)-sample.At(i, i)) > 1e-14 && test.target == ConstantCorrelationTarget {
+				t.Errorf("constant correlation target: variance %d changed", i)
+			}
+			for j := 0; j < 3; j++ {
+				if f.At(i, j) != f.At(j, i) {
+					t.Errorf("target %d: asymmetric target", test.target)
+				}
+			}
+		}
+		if test.target == IdentityTarget {
+			mu := (sample.At(0, 0) + sample.At(1, 1) + sample.At(2, 2)) / 3
+			want := []float64{mu, 0, 0, 0, mu, 0, 0, 0, mu}
+			if !floats.EqualApprox(f.RawMatrix().Data, want, 1e-15) {
+				t.Errorf("identity target mismatch. Want %v, got %v", want, f.RawMatrix().Data)
+			}
+		} else {
+			var rbar float64
+			for _, ij := range [][2]int{{0, 1}, {0, 2}, {1, 2}} {
+				i, j := ij[0], ij[1]
+				rbar += sample.At(i, j) / math.Sqrt(sample.At(i, i)*sample.At(j, j)) / 3
+			}
+			for _, ij := range [][2]int{{0, 1}, {0, 2}, {1, 2}} {
+				i, j := ij[0], ij[1]
+				if r := f.At(i, j) / math.Sqrt(f.At(i, i)*f.At(j, j)); math.Abs(r-rbar) > 1e-14 {
+					t.Errorf("constant correlation target: correlation (%d, %d) is %v, want %v", i, j, r, rbar)
+				}
+			}
+		}
+	}
+
+	// Integer weights are equivalent to repeated rows.
+	wts := []float64{1, 2, 1, 3, 1, 1, 2, 1}
+	var rows []float64
+	for i, w := range wts {
+		for k := 0; k < int(w); k++ {
+			rows = append(rows, x.RawRowView(i)...)
+		}
+	}
+	repeated := mat64.NewDense(len(rows)/3, 3, rows)
+	for _, target := range []ShrinkageTarget{IdentityTarget, ConstantCorrelationTarget} {
+		_, weighted := ShrunkCovarianceMatrix(nil, x, wts, target)
+		_, want := ShrunkCovarianceMatrix(nil, repeated, nil, target)
+		if math.Abs(weighted-want) > 1e-13 {
+			t.Errorf("target %d: weighted intensity mismatch. Want %v, got %v", target, want, weighted)
+		}
+	}
+
+	// With fewer rows than columns the sample covariance matrix is
+	// singular, and the estimate is positive definite. Independent columns
+	// of equal variance are shrunk strongly, and more strongly than those
+	// of a large sample.
+	rnd := rand.New(rand.NewSource(1))
+	small := mat64.NewDense(10, 20, nil)
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 20; j++ {
+			small.Set(i, j, rnd.NormFloat64())
+		}
+	}
+	cov, delta := ShrunkCovarianceMatrix(nil, small, nil, IdentityTarget)
+	if delta < 0.5 || delta > 1 {
+		t.Errorf("unexpected intensity %v for fewer rows than columns", delta)
+	}
+	var chol mat64.TriDense
+	if ok := chol.Cholesky(mat64.NewSymDense(20, cov.RawMatrix().Data), false); !ok {
+		t.Errorf("shrunk estimate is not positive definite")
+	}
+	large := mat64.NewDense(2000, 5, nil)
+	for i := 0; i < 2000; i++ {
+		for j := 0; j < 5; j++ {
+			large.Set(i, j, rnd.NormFloat64()+float64(j)*large.At(i, 0))
+		}
+	}
+	if _, d := ShrunkCovarianceMatrix(nil, large, nil, IdentityTarget); d > 0.05 {
+		t.Errorf("unexpected intensity %v for a large sample", d)
+	}
+
+	for i, fn := range []func(){
+		func() { ShrunkCovarianceMatrix(nil, x, nil, ShrinkageTarget(2)) },
+		func() { ShrunkCovarianceMatrix(mat64.NewDense(2, 2, nil), x, nil, IdentityTarget) },
+		func() { ShrunkCovarianceMatrix(nil, x, []float64{1}, IdentityTarget) },
+		func() { ShrunkCovarianceMatrixIntensity(nil, x, nil, IdentityTarget, 1.5) },
+		func() { ShrunkCovarianceMatrixIntensity(nil, x, nil, IdentityTarget, -0.1) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+// VerifySorted enables the verification of the sorted-input contract of
+// Sorted. When VerifySorted is true, AsSorted and the methods of Sorted check
+// that the data are sorted and panic with an ErrUnsorted holding the index of
+// the first violation if they are not, at the cost of O(n) time per call. It
+// is intended for debugging and tests.
+var VerifySorted = false
+
+// Sorted is a sample whose values are sorted in increasing order, with any
+// NaN values first, as by sort.Float64s. The methods of Sorted rely on the
+// order to run in constant or logarithmic time, and do not check it unless
+// VerifySorted is true.
+type Sorted []float64
+
+// NewSorted returns a sorted copy of x.
+func NewSorted(x []float64) Sorted {
+	s := make(Sorted, len(x))
+	copy(s, x)
+	sort.Float64s(s)
+	return s
+}
+
+// AsSorted returns x as a Sorted without copying it. The caller must ensure
+// that x is sorted in increasing order and is not changed while in use.
+func AsSorted(x []float64) Sorted {
+	s := Sorted(x)
+	s.verify()
+	return s
+}
+
+// verify panics if VerifySorted is true and s is not sorted.
+func (s Sorted) verify() {
+	if VerifySorted {
+		checkSorted(s)
+	}
+}
+
+// nonEmpty panics if s is empty and reports whether s contains no NaN
+// values. Since NaN values sort first, only the first value is checked.
+func (s Sorted) nonEmpty() bool {
+	if len(s) == 0 {
+		panic("stat: zero length slice")
+	}
+	s.verify()
+	return !math.IsNaN(s[0])
+}
+
+// Min returns the smallest value of s, or NaN if s contains a NaN value.
+func (s Sorted) Min() float64 {
+	s.nonEmpty()
+	return s[0]
+}
+
+// Max returns the largest value of s, or NaN if s contains a NaN value.
+func (s Sorted) Max() float64 {
+	if !s.nonEmpty() {
+		return math.NaN()
+	}
+	return s[len(s)-1]
+}
+
+// Median returns the median of s, the middle value if the length of s is
+// odd and the mean of the two middle values if it is even. Median returns
+// NaN if s contains a NaN value.
+func (s Sorted) Median() float64 {
+	if !s.nonEmpty() {
+		return math.NaN()
+	}
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+// Quantile returns the quantile of s at p, as returned by Quantile with nil
+// weights, in constant time.
+func (s Sorted) Quantile(p float64, c CumulantKind) float64 {
+	if !(p >= 0 && p <= 1) {
+		panic("stat: percentile out of bounds")
+	}
+	if !s.nonEmpty() {
+		return math.NaN()
+	}
+	switch c {
+	case Empirical:
+		// Quantile returns x[i] for the smallest i with i+1 ≥ p n.
+		i := int(math.Ceil(p*float64(len(s)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return s[i]
+	default:
+		panic("stat: bad cumulant kind")
+	}
+}
+
+// IQR returns the interquartile range of s, the difference between its
+// quantiles at 0.75 and 0.25 as returned by Quantile with the given kind.
+func (s Sorted) IQR(c CumulantKind) float64 {
+	return s.Quantile(0.75, c) - s.Quantile(0.25, c)
+}
+
+// CDF returns the empirical cumulative distribution function of s at q, as
+// returned by CDF with nil weights, in logarithmic time.
+func (s Sorted) CDF(q float64, c CumulantKind) float64 {
+	if !s.nonEmpty() {
+		return math.NaN()
+	}
+	switch c {
+	case Empirical:
+		n := sort.Search(len(s), func(i int) bool { return s[i] > q })
+		return float64(n) / float64(len(s))
+	default:
+		panic("stat: bad cumulant kind")
+	}
+}
+
+// Min returns the smallest value of x, or NaN if x contains a NaN value.
+// Min panics if x is empty.
+func Min(x []float64) float64 {
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	min := x[0]
+	for _, v := range x {
+		if math.IsNaN(v) {
+			return math.NaN()
+		}
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// Max returns the largest value of x, or NaN if x contains a NaN value.
+// Max panics if x is empty.
+func Max(x []float64) float64 {
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	max := x[0]
+	for _, v := range x {
+		if math.IsNaN(v) {
+			return math.NaN()
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// Median returns the median of x, the middle value if the length of x is odd
+// and the mean of the two middle values if it is even, or NaN if x contains a
+// NaN value. x is not modified. Median panics if x is empty. For data that
+// are already sorted, Sorted.Median runs in constant time.
+func Median(x []float64) float64 {
+	return NewSorted(x).Median()
+}
+
+// IQR returns the interquartile range of x, the difference between the
+// quantiles of x at 0.75 and 0.25 as returned by Quantile with the given kind
+// and nil weights. x is not modified. IQR panics if x is empty. For data that
+// are already sorted, Sorted.IQR runs in constant time.
+func IQR(x []float64, c CumulantKind) float64 {
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	s := NewSorted(x)
+	return Quantile(0.75, c, s, nil) - Quantile(0.25, c, s, nil)
+}
@@ -0,0 +1,132 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSortedFastPaths(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 2, 3, 10, 11, 100} {
+		x := make([]float64, n)
+		for i := range x {
+			// Rounded values so that there are ties.
+			x[i] = math.Floor(10 * rnd.NormFloat64())
+		}
+		orig := make([]float64, n)
+		copy(orig, x)
+		s := NewSorted(x)
+		if !reflect.DeepEqual(x, orig) {
+			t.Fatalf("NewSorted modified its input")
+		}
+		if !sort.Float64sAreSorted(s) {
+			t.Fatalf("NewSorted result not sorted")
+		}
+
+		if got, want := s.Min(), Min(x); got != want {
+			t.Errorf("n=%d: Min mismatch: got %v, want %v", n, got, want)
+		}
+		if got, want := s.Max(), Max(x); got != want {
+			t.Errorf("n=%d: Max mismatch: got %v, want %v", n, got, want)
+		}
+		for _, p := range []float64{0, 0.1, 0.25, 0.5, 0.75, 0.9, 1} {
+			if got, want := s.Quantile(p, Empirical), Quantile(p, Empirical, s, nil); got != want {
+				t.Errorf("n=%d: Quantile(%v) mismatch: got %v, want %v", n, p, got, want)
+			}
+		}
+		for _, q := range []float64{-100, orig[0], 0, 0.5, orig[n-1], 100} {
+			if got, want := s.CDF(q, Empirical), CDF(q, Empirical, s, nil); got != want {
+				t.Errorf("n=%d: CDF(%v) mismatch: got %v, want %v", n, q, got, want)
+			}
+		}
+		wantIQR := Quantile(0.75, Empirical, s, nil) - Quantile(0.25, Empirical, s, nil)
+		if got := s.IQR(Empirical); got != wantIQR {
+			t.Errorf("n=%d: IQR mismatch: got %v, want %v", n, got, wantIQR)
+		}
+		if got := IQR(x, Empirical); got != wantIQR {
+			t.Errorf("n=%d: IQR mismatch: got %v, want %v", n, got, wantIQR)
+		}
+		if got, want := s.Median(), Median(x); got != want {
+			t.Errorf("n=%d: Median mismatch: got %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestMedian(t *testing.T) {
+	for i, test := range []struct {
+		x    []float64
+		want float64
+	}{
+		{[]float64{3}, 3},
+		{[]float64{4, 1}, 2.5},
+		{[]float64{5, 1, 3}, 3},
+		{[]float64{7, 1, 3, 2}, 2.5},
+		{[]float64{2, math.NaN(), 1}, math.NaN()},
+	} {
+		got := Median(test.x)
+		if got != test.want && !(math.IsNaN(got) && math.IsNaN(test.want)) {
+			t.Errorf("case %d: median mismatch: got %v, want %v", i, got, test.want)
+		}
+	}
+	x := []float64{1, math.NaN(), 3}
+	if !math.IsNaN(Min(x)) || !math.IsNaN(Max(x)) {
+		t.Errorf("Min or Max did not return NaN for data with NaN")
+	}
+	s := NewSorted(x)
+	if !math.IsNaN(s.Min()) || !math.IsNaN(s.Max()) || !math.IsNaN(s.IQR(Empirical)) {
+		t.Errorf("Sorted did not return NaN for data with NaN")
+	}
+	for _, f := range []func(){
+		func() { Min(nil) },
+		func() { Max(nil) },
+		func() { Median(nil) },
+		func() { IQR(nil, Empirical) },
+		func() { Sorted(nil).Median() },
+		func() { Sorted{1}.Quantile(1.5, Empirical) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
+
+func TestVerifySorted(t *testing.T) {
+	unsorted := []float64{1, 2, 5, 4, 6}
+	recovered := func(f func()) (v interface{}) {
+		defer func() { v = recover() }()
+		f()
+		return nil
+	}
+
+	defer func(v bool) { VerifySorted = v }(VerifySorted)
+	VerifySorted = false
+	if v := recovered(func() { AsSorted(unsorted).Median() }); v != nil {
+		t.Errorf("unexpected panic with verification off: %v", v)
+	}
+
+	VerifySorted = true
+	want := ErrUnsorted{Index: 3}
+	for i, f := range []func(){
+		func() { AsSorted(unsorted) },
+		func() { Sorted(unsorted).Min() },
+		func() { Sorted(unsorted).Max() },
+		func() { Sorted(unsorted).Median() },
+		func() { Sorted(unsorted).IQR(Empirical) },
+		func() { Sorted(unsorted).Quantile(0.5, Empirical) },
+		func() { Sorted(unsorted).CDF(3, Empirical) },
+	} {
+		if v := recovered(f); v != want {
+			t.Errorf("case %d: unexpected panic value: got %#v, want %#v", i, v, want)
+		}
+	}
+	if v := recovered(func() { AsSorted([]float64{math.NaN(), 1, 2}).Median() }); v != nil {
+		t.Errorf("unexpected panic for sorted data: %v", v)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// WindowKind specifies the taper applied to a segment of a series before
+// its spectrum is computed.
+type WindowKind int
+
+const (
+	// RectangularWindow applies no taper.
+	RectangularWindow WindowKind = iota
+	// HannWindow applies the periodic Hann window
+	//  w_i = 0.5 - 0.5 cos(2π i/n)
+	HannWindow
+	// HammingWindow applies the periodic Hamming window
+	//  w_i = 0.54 - 0.46 cos(2π i/n)
+	HammingWindow
+)
+
+// window returns the window of the given kind and length n.
+func (k WindowKind) window(n int) []float64 {
+	w := make([]float64, n)
+	var a, b float64
+	switch k {
+	case RectangularWindow:
+		a, b = 1, 0
+	case HannWindow:
+		a, b = 0.5, 0.5
+	case HammingWindow:
+		a, b = 0.54, 0.46
+	default:
+		panic("stat: unknown window kind")
+	}
+	for i := range w {
+		w[i] = a - b*math.Cos(2*math.Pi*float64(i)/float64(n))
+	}
+	return w
+}
+
+// Periodogram returns the one-sided power spectral density estimate of the
+// series x sampled at frequency fs, after the mean of x is removed. The
+// estimate at frequency freqs[k] = k fs/n, for k = 0, ..., n/2, is
+//  power[k] = c |X_k|² / (fs n)
+// where X is the discrete Fourier transform of the centered series and c is
+// 2 except at zero frequency and, for even n, at the Nyquist frequency, where
+// it is 1. With this normalization the sum of power times the frequency
+// spacing fs/n equals the population variance of x.
+//
+// This matches scipy.signal.periodogram with its default arguments.
+// Periodogram panics if x is empty or fs is not positive.
+func Periodogram(x []float64, fs float64) (freqs, power []float64) {
+	if len(x) == 0 {
+		panic("stat: zero length series")
+	}
+	return WelchPSD(x, fs, len(x), 0, RectangularWindow)
+}
+
+// WelchPSD returns Welch's estimate of the one-sided power spectral density
+// of the series x sampled at frequency fs. The series is split into segments
+// of length segmentLen, consecutive segments overlapping by overlap samples,
+// and any samples after the last full segment are ignored. Each segment has
+// its mean removed and is multiplied by the window w before its periodogram
+// is computed as
+//  c |X_k|² / (fs Σ w_i²)
+// with c as for Periodogram, and the periodograms of the segments are
+// averaged. The frequencies are k fs/segmentLen for k = 0, ..., segmentLen/2.
+//
+// The normalization is such that the integral of the estimate approximates
+// the variance of x, and matches scipy.signal.welch with scaling="density"
+// and the default constant detrending. A common choice is a Hann window with
+// fifty percent overlap.
+//
+// WelchPSD panics if fs is not positive, if segmentLen is not positive or is
+// longer than x, or if overlap is negative or not less than segmentLen.
+func WelchPSD(x []float64, fs float64, segmentLen, overlap int, window WindowKind) (freqs, power []float64) {
+	if !(fs > 0) {
+		panic("stat: non-positive sampling frequency")
+	}
+	if segmentLen < 1 || segmentLen > len(x) {
+		panic("stat: segment length out of range")
+	}
+	if overlap < 0 || overlap >= segmentLen {
+		panic("stat: overlap out of range")
+	}
+	w := window.window(segmentLen)
+	var norm float64
+	for _, v := range w {
+		norm += v * v
+	}
+	norm *= fs
+
+	nf := segmentLen/2 + 1
+	freqs = make([]float64, nf)
+	for k := range freqs {
+		freqs[k] = float64(k) * fs / float64(segmentLen)
+	}
+	power = make([]float64, nf)
+	seg := make([]float64, segmentLen)
+	step := segmentLen - overlap
+	var count int
+	for start := 0; start+segmentLen <= len(x); start += step {
+		Detrend(seg, x[start:start+segmentLen], ConstantTrend)
+		for i, v := range w {
+			seg[i] *= v
+		}
+		for k, c := range dft(seg)[:nf] {
+			a := cmplx.Abs(c)
+			power[k] += a * a
+		}
+		count++
+	}
+	for k := range power {
+		power[k] /= norm * float64(count)
+		if k != 0 && !(segmentLen%2 == 0 && k == nf-1) {
+			power[k] *= 2
+		}
+	}
+	return freqs, power
+}
+
+// dft returns the discrete Fourier transform of x,
+//  X_k = Σ_j x_j exp(-2πi jk/n)
+// using a radix-2 fast Fourier transform when the length of x is a power of
+// two, and otherwise Bluestein's algorithm, which writes the transform as a
+// convolution computed by radix-2 transforms of at least 2n-1 points, so
+// that the cost is O(n log n) for any n.
+func dft(x []float64) []complex128 {
+	n := len(x)
+	out := make([]complex128, n)
+	if n&(n-1) == 0 {
+		for i, v := range x {
+			out[i] = complex(v, 0)
+		}
+		fft(out, false)
+		return out
+	}
+
+	// With jk = (j² + k² - (k-j)²)/2, X_k = w_k Σ_j (x_j w_j) conj(w_{k-j})
+	// for the chirp w_j = exp(-πi j²/n). The exponent j² is reduced modulo
+	// 2n to keep the angles accurate for large n.
+	chirp := make([]complex128, n)
+	for j := range chirp {
+		s, c := math.Sincos(-math.Pi * float64((j*j)%(2*n)) / float64(n))
+		chirp[j] = complex(c, s)
+	}
+	m := 1
+	for m < 2*n-1 {
+		m <<= 1
+	}
+	a := make([]complex128, m)
+	b := make([]complex128, m)
+	for j, v := range x {
+		a[j] = complex(v, 0) * chirp[j]
+	}
+	b[0] = 1
+	for j := 1; j < n; j++ {
+		c := cmplx.Conj(chirp[j])
+		b[j] = c
+		b[m-j] = c
+	}
+	fft(a, false)
+	fft(b, false)
+	for i := range a {
+		a[i] *= b[i]
+	}
+	fft(a, true)
+	scale := complex(1/float64(m), 0)
+	for k := range out {
+		out[k] = chirp[k] * a[k] * scale
+	}
+	return out
+}
+
+// fft computes in place the unnormalized discrete Fourier transform of a,
+// whose length must be a power of two, by the iterative radix-2 Cooley–Tukey
+// algorithm, or the transform with the opposite sign of the exponent if
+// inverse is true.
+func fft(a []complex128, inverse bool) {
+	n := len(a)
+	bits := uint(0)
+	for 1<<bits < n {
+		bits++
+	}
+	for i := range a {
+		var r int
+		for b := uint(0); b < bits; b++ {
+			r |= (i >> b & 1) << (bits - 1 - b)
+		}
+		if i < r {
+			a[i], a[r] = a[r], a[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		s, c := math.Sincos(sign * 2 * math.Pi / float64(size))
+		step := complex(c, s)
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for j := 0; j < size/2; j++ {
+				u := a[start+j]
+				v := w * a[start+j+size/2]
+				a[start+j] = u + v
+				a[start+j+size/2] = u - v
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestDFT(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	// Lengths that are not powers of two, including primes, use Bluestein's
+	// algorithm.
+	for _, n := range []int{1, 2, 3, 5, 7, 8, 12, 64, 100, 997} {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = rnd.NormFloat64()
+		}
+		got := dft(x)
+		for k := range got {
+			var want complex128
+			for j, v := range x {
+				want += complex(v, 0) * cmplx.Exp(complex(0, -2*math.Pi*float64(j*k)/float64(n)))
+			}
+			if cmplx.Abs(got[k]-want) > 1e-12*float64(n) {
+				t.Errorf("n = %d: coefficient %d mismatch. Want %v, got %v", n, k, want, got[k])
+			}
+		}
+	}
+}
+
+func TestPeriodogram(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{5, 16, 31, 100} {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = 3 + rnd.NormFloat64()
+		}
+		fs := 4.0
+		freqs, power := Periodogram(x, fs)
+		if len(freqs) != n/2+1 || len(power) != n/2+1 {
+			t.Fatalf("n = %d: wrong number of frequencies: %d", n, len(freqs))
+		}
+		if freqs[1] != fs/float64(n) {
+			t.Errorf("n = %d: frequency spacing mismatch. Want %v, got %v", n, fs/float64(n), freqs[1])
+		}
+		// Parseval's relation.
+		v := Variance(x, nil) * float64(n-1) / float64(n)
+		integral := floats.Sum(power) * fs / float64(n)
+		if math.Abs(integral-v) > 1e-12*v {
+			t.Errorf("n = %d: integral of periodogram %v does not equal variance %v", n, integral, v)
+		}
+		if power[0] > 1e-20 {
+			t.Errorf("n = %d: non-zero power at zero frequency: %v", n, power[0])
+		}
+	}
+}
+
+func TestWelchPSD(t *testing.T) {
+	// Values computed following the algorithm of scipy.signal.welch with
+	// scaling="density".
+	x := []float64{1, 4, 2, 8, 5, 7, 3, 6, 2, 9, 4, 1, 7, 3, 8, 5}
+	for _, test := range []struct {
+		fs           float64
+		seg, overlap int
+		window       WindowKind
+		want         []float64
+	}{
+		{2, 8, 4, HannWindow, []float64{0.9010431710968354, 3.438422922970427, 3.1000841772313894, 9.668839180188934, 9.689468088164478}},
+		{1, 7, 3, HammingWindow, []float64{2.240335236624994, 9.141462997867125, 7.460777192680717, 29.523788094281688}},
+	} {
+		_, got := WelchPSD(x, test.fs, test.seg, test.overlap, test.window)
+		if !floats.EqualApprox(got, test.want, 1e-12) {
+			t.Errorf("segment %d, window %d: PSD mismatch. Want %v, got %v", test.seg, test.window, test.want, got)
+		}
+	}
+
+	// A single rectangular segment is the periodogram.
+	_, p1 := Periodogram(x, 3)
+	_, p2 := WelchPSD(x, 3, len(x), 0, RectangularWindow)
+	if !floats.Equal(p1, p2) {
+		t.Errorf("single rectangular segment does not match periodogram")
+	}
+
+	// Recover the frequency of a noisy sinusoid, and check that the
+	// integral of the estimate is close to the variance.
+	rnd := rand.New(rand.NewSource(1))
+	const (
+		fs   = 100.0
+		freq = 12.5
+		n    = 4096
+	)
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 2*math.Sin(2*math.Pi*freq*float64(i)/fs) + rnd.NormFloat64()
+	}
+	for _, w := range []WindowKind{HannWindow, HammingWindow} {
+		freqs, power := WelchPSD(y, fs, 256, 128, w)
+		if peak := freqs[floats.MaxIdx(power)]; peak != freq {
+			t.Errorf("window %d: peak frequency mismatch. Want %v, got %v", w, freq, peak)
+		}
+		v := Variance(y, nil)
+		integral := floats.Sum(power) * (freqs[1] - freqs[0])
+		if math.Abs(integral-v) > 0.05*v {
+			t.Errorf("window %d: integral of PSD %v far from variance %v", w, integral, v)
+		}
+	}
+
+	if !Panics(func() { WelchPSD(x, 0, 8, 4, HannWindow) }) {
+		t.Errorf("WelchPSD did not panic with zero sampling frequency")
+	}
+	if !Panics(func() { WelchPSD(x, 1, 17, 4, HannWindow) }) {
+		t.Errorf("WelchPSD did not panic with segment longer than series")
+	}
+	if !Panics(func() { WelchPSD(x, 1, 8, 8, HannWindow) }) {
+		t.Errorf("WelchPSD did not panic with overlap equal to segment length")
+	}
+	if !Panics(func() { Periodogram(nil, 1) }) {
+		t.Errorf("Periodogram did not panic with empty series")
+	}
+}
+
+func BenchmarkDFTPrime(b *testing.B) {
+	x := RandomSlice(4099)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dft(x)
+	}
+}
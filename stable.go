@@ -0,0 +1,80 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+// MeanStable returns the weighted mean of x, as Mean does, but accumulates
+// the sums with compensated (Neumaier) summation. The rounding error of the
+// result does not grow with the length of x, at the cost of a few more
+// floating point operations per element. This matters for long samples with
+// a large common offset, such as timestamps, where the error of Mean can
+// exceed the spread of the data.
+func MeanStable(x, weights []float64) float64 {
+	var total neumaier
+	if weights == nil {
+		for _, v := range x {
+			total.add(v)
+		}
+		return total.sum() / float64(len(x))
+	}
+	checkLengths(x, weights)
+	var sumWeights neumaier
+	for i, w := range weights {
+		total.add(w * x[i])
+		sumWeights.add(w)
+	}
+	return total.sum() / sumWeights.sum()
+}
+
+// VarianceStable returns the weighted sample variance of x, as Variance
+// does, using the corrected two-pass algorithm about the mean returned by
+// MeanStable, with all sums accumulated by compensated summation.
+func VarianceStable(x, weights []float64) float64 {
+	mean := MeanStable(x, weights)
+	var ss, comp, sumWeights neumaier
+	if weights == nil {
+		for _, v := range x {
+			d := v - mean
+			ss.add(d * d)
+			comp.add(d)
+		}
+		n := float64(len(x))
+		c := comp.sum()
+		return (ss.sum() - c*c/n) / (n - 1)
+	}
+	for i, v := range x {
+		w := weights[i]
+		d := v - mean
+		ss.add(w * d * d)
+		comp.add(w * d)
+		sumWeights.add(w)
+	}
+	n := sumWeights.sum()
+	c := comp.sum()
+	return (ss.sum() - c*c/n) / (n - 1)
+}
+
+// CovarianceStable returns the weighted covariance of x and y, as Covariance
+// does, using the corrected two-pass algorithm about the means returned by
+// MeanStable, with all sums accumulated by compensated summation.
+func CovarianceStable(x, y, weights []float64) float64 {
+	checkLengths(x, y)
+	xu := MeanStable(x, weights)
+	yu := MeanStable(y, weights)
+	var ss, xcomp, ycomp, sumWeights neumaier
+	for i, xv := range x {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		xd := xv - xu
+		yd := y[i] - yu
+		ss.add(w * xd * yd)
+		xcomp.add(w * xd)
+		ycomp.add(w * yd)
+		sumWeights.add(w)
+	}
+	n := sumWeights.sum()
+	return (ss.sum() - xcomp.sum()*ycomp.sum()/n) / (n - 1)
+}
@@ -0,0 +1,112 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// bigMeanVariance returns the mean and sample variance of x computed with
+// 256-bit floating point arithmetic.
+func bigMeanVariance(x []float64) (mean, variance float64) {
+	const prec = 256
+	sum := new(big.Float).SetPrec(prec)
+	v := new(big.Float).SetPrec(prec)
+	for _, xv := range x {
+		sum.Add(sum, v.SetFloat64(xv))
+	}
+	n := new(big.Float).SetPrec(prec).SetInt64(int64(len(x)))
+	m := new(big.Float).SetPrec(prec).Quo(sum, n)
+	ss := new(big.Float).SetPrec(prec)
+	for _, xv := range x {
+		v.SetFloat64(xv)
+		v.Sub(v, m)
+		ss.Add(ss, v.Mul(v, v))
+	}
+	ss.Quo(ss, n.SetInt64(int64(len(x)-1)))
+	mean, _ = m.Float64()
+	variance, _ = ss.Float64()
+	return mean, variance
+}
+
+func TestStableAdversarial(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large fixture in short mode")
+	}
+	// A large common offset with tiny noise, as for timestamps.
+	const offset = 1e9
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float64, 1e7)
+	for i := range x {
+		x[i] = offset + 1e-3*rnd.Float64()
+	}
+	mean, variance := bigMeanVariance(x)
+
+	// The noise is lost in the naive sum, but the compensated mean is
+	// correctly rounded up to an ulp.
+	ulp := math.Nextafter(offset, math.Inf(1)) - offset
+	stableErr := math.Abs(MeanStable(x, nil) - mean)
+	naiveErr := math.Abs(Mean(x, nil) - mean)
+	if stableErr > ulp {
+		t.Errorf("MeanStable error too large: got %v, want at most %v", stableErr, ulp)
+	}
+	if naiveErr < 1000*ulp {
+		t.Errorf("fixture not adversarial: naive mean error %v", naiveErr)
+	}
+	if got := VarianceStable(x, nil); math.Abs(got-variance) > 1e-12*variance {
+		t.Errorf("VarianceStable mismatch: got %v, want %v", got, variance)
+	}
+	if got := CovarianceStable(x, x, nil); got != VarianceStable(x, nil) {
+		t.Errorf("CovarianceStable(x, x) != VarianceStable(x): %v", got)
+	}
+}
+
+func TestStable(t *testing.T) {
+	for i, test := range []struct {
+		x, y, weights []float64
+		mean          float64
+	}{
+		{
+			x:    []float64{8, -3, 7, 8, -4},
+			y:    []float64{10, 5, 6, 3, -1},
+			mean: 3.2,
+		},
+		{
+			x:       []float64{1, 2, 3, 4, 5},
+			y:       []float64{10, 11, 12, 11, 10},
+			weights: []float64{2, 3, 4, 5, 6},
+			mean:    3.5,
+		},
+		{
+			x:       []float64{-1e7, 3, 1e-5, 2.5, 1e7},
+			y:       []float64{1e3, 2, 3, 4, 5},
+			weights: []float64{1, 0.5, 2, 3, 1},
+			// The large terms cancel, which Mean does not get right.
+			mean: 9.00002 / 7.5,
+		},
+	} {
+		if got, want := MeanStable(test.x, test.weights), test.mean; math.Abs(got-want) > 1e-15*math.Abs(want) {
+			t.Errorf("case %d: mean mismatch: got %v, want %v", i, got, want)
+		}
+		if got, want := VarianceStable(test.x, test.weights), Variance(test.x, test.weights); math.Abs(got-want) > 1e-14*want {
+			t.Errorf("case %d: variance mismatch: got %v, want %v", i, got, want)
+		}
+		if got, want := CovarianceStable(test.x, test.y, test.weights), Covariance(test.x, test.y, test.weights); math.Abs(got-want) > 1e-13*math.Abs(want) {
+			t.Errorf("case %d: covariance mismatch: got %v, want %v", i, got, want)
+		}
+	}
+	for _, f := range []func(){
+		func() { MeanStable([]float64{1, 2}, []float64{1}) },
+		func() { VarianceStable([]float64{1, 2}, []float64{1}) },
+		func() { CovarianceStable([]float64{1, 2}, []float64{1}, nil) },
+	} {
+		if !Panics(f) {
+			t.Errorf("expected panic")
+		}
+	}
+}
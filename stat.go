@@ -9,6 +9,7 @@ import (
 	"sort"
 
 	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
 )
 
 // CumulantKind specifies the behavior for calculating the empirical CDF or Quantile
@@ -39,9 +40,7 @@ func bhattacharyyaCoeff(p, q []float64) float64 {
 //
 // The lengths of p and q must be equal. It is assumed that p and q sum to 1.
 func Bhattacharyya(p, q []float64) float64 {
-	if len(p) != len(q) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(p, q)
 	bc := bhattacharyyaCoeff(p, q)
 	return -math.Log(bc)
 }
@@ -59,15 +58,11 @@ func Bhattacharyya(p, q []float64) float64 {
 //  - Empirical: Returns the lowest fraction for which q is greater than or equal
 //  to that fraction of samples
 func CDF(q float64, c CumulantKind, x, weights []float64) float64 {
-	if weights != nil && len(x) != len(weights) {
-		panic("stat: slice length mismatch")
-	}
+	checkWeightLength(x, weights)
 	if floats.HasNaN(x) {
 		return math.NaN()
 	}
-	if !sort.Float64sAreSorted(x) {
-		panic("x data are not sorted")
-	}
+	checkSorted(x)
 
 	if q < x[0] {
 		return 0
@@ -110,9 +105,7 @@ func CDF(q float64, c CumulantKind, x, weights []float64) float64 {
 //
 // The lengths of obs and exp must be equal.
 func ChiSquare(obs, exp []float64) float64 {
-	if len(obs) != len(exp) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(obs, exp)
 	var result float64
 	for i, a := range obs {
 		b := exp[i]
@@ -134,9 +127,7 @@ func Correlation(x, y, weights []float64) float64 {
 	// algorithm used in the MeanVariance function, which applies a correction
 	// to the typical two pass approach.
 
-	if len(x) != len(y) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(x, y)
 	xu := Mean(x, weights)
 	yu := Mean(y, weights)
 	var (
@@ -192,6 +183,37 @@ func Correlation(x, y, weights []float64) float64 {
 	return (sxy - xcompensation*ycompensation/sumWeights) / math.Sqrt(sxx*syy)
 }
 
+// Spearman returns the weighted Spearman rank correlation between the samples
+// of x and y, the correlation returned by Correlation between the ranks of x
+// and y. Tied values are given the average of the ranks they span. The ranks
+// do not depend on the weights.
+// The lengths of x and y must be equal. If weights is nil then all of the
+// weights are 1. If weights is not nil, then len(x) must equal len(weights).
+func Spearman(x, y, weights []float64) float64 {
+	checkLengths(x, y)
+	return Correlation(averageRanks(x), averageRanks(y), weights)
+}
+
+// averageRanks returns the ranks of the values of x, starting at 1, with
+// tied values given the average of the ranks they span.
+func averageRanks(x []float64) []float64 {
+	order := argsortFloats(x)
+	ranks := make([]float64, len(x))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && x[order[end]] == x[order[start]] {
+			end++
+		}
+		// The ranks start+1, ..., end average to (start+end+1)/2.
+		r := float64(start+end+1) / 2
+		for _, i := range order[start:end] {
+			ranks[i] = r
+		}
+		start = end
+	}
+	return ranks
+}
+
 // Covariance returns the weighted covariance between the samples of x and y.
 //  sum_i {w_i (x_i - meanX) * (y_i - meanY)} / (sum_j {w_j} - 1)
 // The lengths of x and y must be equal. If weights is nil then all of the
@@ -201,9 +223,7 @@ func Covariance(x, y, weights []float64) float64 {
 	// algorithm used in the MeanVariance function, which applies a correction
 	// to the typical two pass approach.
 
-	if len(x) != len(y) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(x, y)
 	xu := Mean(x, weights)
 	yu := Mean(y, weights)
 	var (
@@ -246,11 +266,10 @@ func Covariance(x, y, weights []float64) float64 {
 }
 
 // CrossEntropy computes the cross-entropy between the two distributions specified
-// in p and q.
+// in p and q. The natural logarithm is used; the cross-entropy in base b is
+// CrossEntropy(p, q) / math.Log(b).
 func CrossEntropy(p, q []float64) float64 {
-	if len(p) != len(q) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(p, q)
 	var ce float64
 	for i, v := range p {
 		if v != 0 {
@@ -260,9 +279,109 @@ func CrossEntropy(p, q []float64) float64 {
 	return ce
 }
 
+// DifferentialEntropy estimates the differential entropy in nats of the
+// continuous distribution from which the sample x is drawn by the k-nearest
+// neighbour estimator of Kozachenko and Leonenko,
+//  H = ψ(n) - ψ(k) + log(2) + 1/n \sum_i log(ε_i)
+// where ψ is the digamma function and ε_i is the distance from x_i to its
+// kth nearest neighbour in the sample. Unlike the Entropy of a histogram, the
+// estimate does not depend on a choice of bins. The nearest neighbours are
+// found from the sorted sample, so DifferentialEntropy takes O(n log n + nk)
+// time. x is not modified.
+//
+// The estimator is consistent, but biased for small samples. The bias is
+// largest for distributions with bounded support or sharp features, such as
+// the uniform distribution, since the neighbours of the points near an edge
+// lie only on one side. Larger k reduces the variance of the estimate at the
+// cost of greater bias, and k = 3 is a common choice.
+//
+// DifferentialEntropy returns -Inf if a value occurs more than k times, and
+// panics if k is not positive or not less than len(x).
+func DifferentialEntropy(x []float64, k int) float64 {
+	n := len(x)
+	if k < 1 {
+		panic("stat: non-positive number of neighbors")
+	}
+	if k >= n {
+		panic("stat: too few samples for the number of neighbors")
+	}
+	s := make([]float64, n)
+	copy(s, x)
+	sort.Float64s(s)
+
+	var sumLog float64
+	for i, v := range s {
+		// The k nearest neighbours and x_i are consecutive in sorted
+		// order, so ε_i is the smallest distance to the farther end of
+		// the windows of k+1 points containing x_i.
+		eps := math.Inf(1)
+		for lo := i - k; lo <= i; lo++ {
+			hi := lo + k
+			if lo < 0 || hi >= n {
+				continue
+			}
+			eps = math.Min(eps, math.Max(v-s[lo], s[hi]-v))
+		}
+		sumLog += math.Log(eps)
+	}
+	// ψ(n) - ψ(k) = \sum_{j=k}^{n-1} 1/j.
+	var psi float64
+	for j := k; j < n; j++ {
+		psi += 1 / float64(j)
+	}
+	return psi + math.Ln2 + sumLog/float64(n)
+}
+
+// EnergyDistance computes the energy distance of Székely and Rizzo between
+// the empirical distributions of the samples x and y,
+//  E = 2 A - B - C
+// where A is the mean of |x_i - y_j| over all pairs of elements of x and y,
+// and B and C are the means of |x_i - x_j| and |y_i - y_j| over all pairs of
+// elements, including those with i = j. E is zero if the samples have the same
+// empirical distribution and positive otherwise, and is twice the integral
+// of the squared difference of their empirical CDFs. Its square root is the
+// energy_distance of SciPy.
+//
+// The sums over pairs are computed from the sorted samples, using
+//  \sum_{i<j} |z_i - z_j| = \sum_k (2k - n - 1) z_(k)
+// for the order statistics z_(1) ≤ ... ≤ z_(n), so EnergyDistance takes
+// O(n log n) time. EnergyDistance returns NaN if x or y contains NaN, and
+// panics if either is empty.
+func EnergyDistance(x, y []float64) float64 {
+	if len(x) == 0 || len(y) == 0 {
+		panic("stat: zero length slice")
+	}
+	if floats.HasNaN(x) || floats.HasNaN(y) {
+		return math.NaN()
+	}
+	pooled := make([]float64, 0, len(x)+len(y))
+	pooled = append(pooled, x...)
+	pooled = append(pooled, y...)
+	sx := sortedPairSum(pooled[:len(x)])
+	sy := sortedPairSum(pooled[len(x):])
+	// The pairs of the pooled sample are those within x, within y and
+	// between them.
+	sxy := sortedPairSum(pooled) - sx - sy
+	nx, ny := float64(len(x)), float64(len(y))
+	return 2*sxy/(nx*ny) - 2*sx/(nx*nx) - 2*sy/(ny*ny)
+}
+
+// sortedPairSum sorts x in place and returns the sum of |x_i - x_j| over
+// the pairs i < j.
+func sortedPairSum(x []float64) float64 {
+	sort.Float64s(x)
+	n := len(x)
+	var s float64
+	for k, v := range x {
+		s += float64(2*k-n+1) * v
+	}
+	return s
+}
+
 // Entropy computes the Shannon entropy of a distribution or the distance between
 // two distributions. The natural logarithm is used.
 //  - sum_i (p_i * log_e(p_i))
+// The entropy in another base is computed by RenyiEntropy of order 1.
 func Entropy(p []float64) float64 {
 	var e float64
 	for _, v := range p {
@@ -273,6 +392,73 @@ func Entropy(p []float64) float64 {
 	return e
 }
 
+// RenyiEntropy computes the Rényi entropy of order q of the distribution p,
+//  H_q = log_b(\sum_i p_i^q) / (1 - q)
+// where b is the given base of the logarithm, such as 2 for bits or math.E
+// for nats. The limits at the special orders are computed explicitly:
+//  - q = 0: the Hartley entropy log_b(n) of the number n of nonzero p_i
+//  - q = 1: the Shannon entropy, Entropy(p) / log(b)
+//  - q = +Inf: the min-entropy -log_b(max_i p_i)
+// The entropy does not increase with q, and order 2 is the collision entropy.
+// It is assumed that p sums to 1. RenyiEntropy panics if q is negative.
+func RenyiEntropy(p []float64, q, base float64) float64 {
+	if !(q >= 0) {
+		panic("stat: negative order")
+	}
+	var h float64
+	switch {
+	case q == 0:
+		var n int
+		for _, v := range p {
+			if v != 0 {
+				n++
+			}
+		}
+		h = math.Log(float64(n))
+	case q == 1:
+		h = Entropy(p)
+	case math.IsInf(q, 1):
+		h = -math.Log(floats.Max(p))
+	default:
+		h = math.Log(powerSum(p, q)) / (1 - q)
+	}
+	return h / math.Log(base)
+}
+
+// TsallisEntropy computes the Tsallis entropy of order q of the distribution p,
+//  S_q = (1 - \sum_i p_i^q) / (q - 1)
+// The limits at the special orders are computed explicitly:
+//  - q = 0: n - 1 for the number n of nonzero p_i
+//  - q = 1: the Shannon entropy in nats, Entropy(p)
+//  - q = +Inf: 0
+// Unlike the Rényi entropy, the Tsallis entropy is not additive for
+// independent distributions. It is assumed that p sums to 1. TsallisEntropy
+// panics if q is negative.
+func TsallisEntropy(p []float64, q float64) float64 {
+	if !(q >= 0) {
+		panic("stat: negative order")
+	}
+	switch {
+	case q == 1:
+		return Entropy(p)
+	case math.IsInf(q, 1):
+		return 0
+	}
+	return (1 - powerSum(p, q)) / (q - 1)
+}
+
+// powerSum returns \sum_i p_i^q over the nonzero p_i, so that 0^0 is
+// taken to be 0.
+func powerSum(p []float64, q float64) float64 {
+	var s float64
+	for _, v := range p {
+		if v != 0 {
+			s += math.Pow(v, q)
+		}
+	}
+	return s
+}
+
 // ExKurtosis returns the population excess kurtosis of the sample.
 // The kurtosis is defined by the 4th moment of the mean divided by the squared
 // variance. The excess kurtosis subtracts 3.0 so that the excess kurtosis of
@@ -324,9 +510,7 @@ func GeometricMean(x, weights []float64) float64 {
 		s /= float64(len(x))
 		return math.Exp(s)
 	}
-	if len(x) != len(weights) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(x, weights)
 	var (
 		s          float64
 		sumWeights float64
@@ -345,9 +529,7 @@ func GeometricMean(x, weights []float64) float64 {
 // If weights is nil then all of the weights are 1. If weights is not nil, then
 // len(x) must equal len(weights).
 func HarmonicMean(x, weights []float64) float64 {
-	if weights != nil && len(x) != len(weights) {
-		panic("stat: slice length mismatch")
-	}
+	checkWeightLength(x, weights)
 	// TODO: Fix this to make it more efficient and avoid allocation
 
 	// This can be numerically unstable (for example if x is very small)
@@ -376,9 +558,7 @@ func HarmonicMean(x, weights []float64) float64 {
 //
 // The lengths of p and q must be equal. It is assumed that p and q sum to 1.
 func Hellinger(p, q []float64) float64 {
-	if len(p) != len(q) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(p, q)
 	bc := bhattacharyyaCoeff(p, q)
 	return math.Sqrt(1 - bc)
 }
@@ -395,9 +575,7 @@ func Hellinger(p, q []float64) float64 {
 //  - If weights is nil then all of the weights are 1.
 //  - If weights is not nil, then len(x) must equal len(weights).
 func Histogram(count, dividers, x, weights []float64) []float64 {
-	if weights != nil && len(x) != len(weights) {
-		panic("stat: slice length mismatch")
-	}
+	checkWeightLength(x, weights)
 	if count == nil {
 		count = make([]float64, len(dividers)-1)
 	}
@@ -407,12 +585,8 @@ func Histogram(count, dividers, x, weights []float64) []float64 {
 	if len(count) != len(dividers)-1 {
 		panic("histogram: bin count mismatch")
 	}
-	if !sort.Float64sAreSorted(dividers) {
-		panic("histogram: dividers are not sorted")
-	}
-	if !sort.Float64sAreSorted(x) {
-		panic("histogram: x data are not sorted")
-	}
+	checkSorted(dividers)
+	checkSorted(x)
 	for i := range count {
 		count[i] = 0
 	}
@@ -467,6 +641,126 @@ func Histogram(count, dividers, x, weights []float64) []float64 {
 	return count
 }
 
+// HistogramNormalization specifies the normalization of the counts of
+// NormalizedHistogram.
+type HistogramNormalization int
+
+const (
+	// CountHistogram leaves the weighted counts unnormalized, as
+	// returned by Histogram.
+	CountHistogram HistogramNormalization = iota
+	// ProbabilityHistogram divides the counts by their total, so that they
+	// sum to 1.
+	ProbabilityHistogram
+	// DensityHistogram divides the counts by their total and by the widths
+	// of the bins, so that the histogram integrates to 1 and estimates the
+	// probability density even for bins of unequal widths.
+	DensityHistogram
+)
+
+// NormalizedHistogram computes the histogram of x as Histogram does, with
+// the same conditions on the inputs, and normalizes it as specified by norm.
+// If the total weight is zero, the normalized counts are NaN.
+// NormalizedHistogram panics if norm is not a valid HistogramNormalization.
+func NormalizedHistogram(count, dividers, x, weights []float64, norm HistogramNormalization) []float64 {
+	if norm < CountHistogram || norm > DensityHistogram {
+		panic("stat: bad histogram normalization")
+	}
+	count = Histogram(count, dividers, x, weights)
+	if norm == CountHistogram {
+		return count
+	}
+	total := floats.Sum(count)
+	for i := range count {
+		count[i] /= total
+		if norm == DensityHistogram {
+			count[i] /= dividers[i+1] - dividers[i]
+		}
+	}
+	return count
+}
+
+// CumulativeHistogram computes the histogram of x as Histogram does, with
+// the same conditions on the inputs, and returns the cumulative counts, the
+// weighted number of data points less than each upper divider,
+// dividers[j+1]. If normalize is true, the cumulative counts are divided by
+// the total weight, giving the empirical CDF at the upper dividers, the
+// last of which is 1. If the total weight is zero, the normalized counts
+// are NaN.
+func CumulativeHistogram(count, dividers, x, weights []float64, normalize bool) []float64 {
+	count = Histogram(count, dividers, x, weights)
+	floats.CumSum(count, count)
+	if normalize {
+		total := count[len(count)-1]
+		for i := range count {
+			count[i] /= total
+		}
+	}
+	return count
+}
+
+// Histogram2D sums up the weighted number of the data points (x[i], y[i]) in
+// each rectangular bin of a two-dimensional histogram. The weight of the
+// data point is added to counts.At(j, k) if
+//  xDividers[j] <= x[i] < xDividers[j+1] and yDividers[k] <= y[i] < yDividers[k+1]
+// as for Histogram, so the rows of counts correspond to the bins of x and the
+// columns to the bins of y. The dividers may be generated by the functions
+// such as AutoBins.
+//
+// If counts is nil, a new matrix is allocated, otherwise it must have
+// len(xDividers)-1 rows and len(yDividers)-1 columns and is overwritten.
+// Unlike for Histogram, x and y need not be sorted. If weights is nil then
+// all of the weights are 1, otherwise len(weights) must equal len(x).
+//
+// Histogram2D panics if the lengths of x and y differ, if there are fewer
+// than two dividers in either dimension, if the dividers are not sorted, or
+// if a data point lies outside the range of the dividers, including NaN.
+func Histogram2D(counts *mat64.Dense, x, y, xDividers, yDividers, weights []float64) *mat64.Dense {
+	checkLengths(x, y)
+	checkWeightLength(x, weights)
+	if len(xDividers) < 2 || len(yDividers) < 2 {
+		panic("stat: fewer than two dividers")
+	}
+	checkSorted(xDividers)
+	checkSorted(yDividers)
+	r, c := len(xDividers)-1, len(yDividers)-1
+	if counts == nil {
+		counts = mat64.NewDense(r, c, nil)
+	} else {
+		if cr, cc := counts.Dims(); cr != r || cc != c {
+			panic("stat: histogram size mismatch")
+		}
+		for j := 0; j < r; j++ {
+			row := counts.RawRowView(j)
+			for k := range row[:c] {
+				row[k] = 0
+			}
+		}
+	}
+	for i, v := range x {
+		j := histogramBin(xDividers, v)
+		k := histogramBin(yDividers, y[i])
+		if j < 0 || k < 0 {
+			panic("stat: data point outside the range of the dividers")
+		}
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		counts.Set(j, k, counts.At(j, k)+w)
+	}
+	return counts
+}
+
+// histogramBin returns the index j of the bin dividers[j] <= v < dividers[j+1],
+// or -1 if v is outside the range of the dividers or is NaN.
+func histogramBin(dividers []float64, v float64) int {
+	if !(v >= dividers[0] && v < dividers[len(dividers)-1]) {
+		return -1
+	}
+	return sort.Search(len(dividers), func(i int) bool { return dividers[i] > v }) - 1
+}
+
 // JensenShannon computes the JensenShannon divergence between the distributions
 // p and q. The Jensen-Shannon divergence is defined as
 //  m = 0.5 * (p + q)
@@ -474,9 +768,7 @@ func Histogram(count, dividers, x, weights []float64) []float64 {
 // Unlike Kullback-Liebler, the Jensen-Shannon distance is symmetric. The value
 // is between 0 and ln(2).
 func JensenShannon(p, q []float64) float64 {
-	if len(p) != len(q) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(p, q)
 	var js float64
 	for i, v := range p {
 		qi := q[i]
@@ -493,6 +785,19 @@ func JensenShannon(p, q []float64) float64 {
 	return js
 }
 
+// JensenShannonDistance computes the Jensen-Shannon distance between the
+// distributions p and q, the square root of their Jensen-Shannon divergence,
+//  \sqrt{ JS(p, q) }
+// Unlike the divergence, the distance is a metric, satisfying the triangle
+// inequality. The value is between 0 and \sqrt{ln(2)}.
+//
+// The lengths of p and q must be equal. It is assumed that p and q sum to 1.
+func JensenShannonDistance(p, q []float64) float64 {
+	// The divergence of nearly equal distributions may be rounded to a
+	// small negative value.
+	return math.Sqrt(math.Max(JensenShannon(p, q), 0))
+}
+
 // KolmogorovSmirnov computes the largest distance between two empirical CDFs.
 // Each dataset x and y consists of sample locations and counts, xWeights and
 // yWeights, respectively.
@@ -504,12 +809,8 @@ func JensenShannon(p, q []float64) float64 {
 //  = 0 if len(x) == len(y) == 0
 //  = 1 if len(x) == 0, len(y) != 0 or len(x) != 0 and len(y) == 0
 func KolmogorovSmirnov(x, xWeights, y, yWeights []float64) float64 {
-	if xWeights != nil && len(x) != len(xWeights) {
-		panic("stat: slice length mismatch")
-	}
-	if yWeights != nil && len(y) != len(yWeights) {
-		panic("stat: slice length mismatch")
-	}
+	checkWeightLength(x, xWeights)
+	checkWeightLength(y, yWeights)
 	if len(x) == 0 || len(y) == 0 {
 		if len(x) == 0 && len(y) == 0 {
 			return 0
@@ -524,12 +825,8 @@ func KolmogorovSmirnov(x, xWeights, y, yWeights []float64) float64 {
 		return math.NaN()
 	}
 
-	if !sort.Float64sAreSorted(x) {
-		panic("x data are not sorted")
-	}
-	if !sort.Float64sAreSorted(y) {
-		panic("y data are not sorted")
-	}
+	checkSorted(x)
+	checkSorted(y)
 
 	xWeightsNil := xWeights == nil
 	yWeightsNil := yWeights == nil
@@ -635,9 +932,7 @@ func updateKS(idx int, cdf, sum float64, values, weights []float64, isNil bool)
 // Note that the Kullback-Leibler distance is not symmetric;
 // KullbackLeibler(p,q) != KullbackLeibler(q,p)
 func KullbackLeibler(p, q []float64) float64 {
-	if len(p) != len(q) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(p, q)
 	var kl float64
 	for i, v := range p {
 		if v != 0 { // Entropy needs 0 * log(0) == 0
@@ -647,6 +942,101 @@ func KullbackLeibler(p, q []float64) float64 {
 	return kl
 }
 
+// LinearRegression computes the best-fit line
+//  y = alpha + beta*x
+// to the data in x and y with the given weights. If origin is true, the
+// regression is forced to pass through the origin.
+//
+// Specifically, LinearRegression computes the values of alpha and
+// beta such that the total residual
+//  \sum_i w[i]*(y[i] - alpha - beta*x[i])^2
+// is minimized. If origin is true, then alpha is forced to be zero.
+//
+// The lengths of x and y must be equal. If weights is nil then all of the
+// weights are 1. If weights is not nil, then len(x) must equal len(weights).
+func LinearRegression(x, y, weights []float64, origin bool) (alpha, beta float64) {
+	checkLengths(x, y)
+	checkWeightLength(x, weights)
+
+	w := 1.0
+	if origin {
+		var x2Sum, xySum float64
+		for i, xi := range x {
+			if weights != nil {
+				w = weights[i]
+			}
+			yi := y[i]
+			xySum += w * xi * yi
+			x2Sum += w * xi * xi
+		}
+		beta = xySum / x2Sum
+
+		return 0, beta
+	}
+
+	xu, xv := MeanVariance(x, weights)
+	yu := Mean(y, weights)
+	cov := Covariance(x, y, weights)
+	beta = cov / xv
+	alpha = yu - beta*xu
+	return alpha, beta
+}
+
+// LinearRegressionFit computes the best-fit line
+//  y = alpha + beta*x
+// as by LinearRegression, together with the standard errors, t statistics,
+// p-values and confidence intervals at the given level of alpha and beta,
+// the coefficient of determination and the residual standard error. The
+// error variance is estimated by the weighted residual sum of squares
+//  \sum_i w[i]*(y[i] - alpha - beta*x[i])^2
+// divided by the residual degrees of freedom, n-2, or n-1 for a regression
+// through the origin, where n is the number of observations with non-zero
+// weight. For a regression through the origin, R² is computed about zero
+// rather than about the mean of y, as by lm in R.
+//
+// LinearRegressionFit panics if the lengths of x, y and weights do not
+// match, if a weight is negative, if level is not in (0, 1) or if all of the
+// values of x with non-zero weight are equal.
+func LinearRegressionFit(x, y, weights []float64, origin bool, level float64) LinearRegressionResult {
+	checkLengths(x, y)
+	checkWeightLength(x, weights)
+	if !(level > 0 && level < 1) {
+		panic("stat: confidence level out of range")
+	}
+	s := NewOLS(mat64.NewDense(len(x), 1, x), y, weights, !origin).Summary
+	q := tQuantile(1-(1-level)/2, s.DF)
+	ci := func(i int) [2]float64 {
+		d := q * s.StdErrs[i]
+		return [2]float64{s.Coefficients[i] - d, s.Coefficients[i] + d}
+	}
+	nan := math.NaN()
+	r := LinearRegressionResult{
+		AlphaStdErr:    nan,
+		AlphaT:         nan,
+		AlphaP:         nan,
+		AlphaCI:        [2]float64{nan, nan},
+		Level:          level,
+		DF:             s.DF,
+		R2:             s.R2,
+		ResidualStdErr: s.ResidualStdErr,
+	}
+	b := 0
+	if !origin {
+		r.Alpha = s.Coefficients[0]
+		r.AlphaStdErr = s.StdErrs[0]
+		r.AlphaT = s.TStats[0]
+		r.AlphaP = s.PValues[0]
+		r.AlphaCI = ci(0)
+		b = 1
+	}
+	r.Beta = s.Coefficients[b]
+	r.BetaStdErr = s.StdErrs[b]
+	r.BetaT = s.TStats[b]
+	r.BetaP = s.PValues[b]
+	r.BetaCI = ci(b)
+	return r
+}
+
 // Mean computes the weighted mean of the data set.
 //  sum_i {w_i * x_i} / sum_i {w_i}
 // If weights is nil then all of the weights are 1. If weights is not nil, then
@@ -655,9 +1045,7 @@ func Mean(x, weights []float64) float64 {
 	if weights == nil {
 		return floats.Sum(x) / float64(len(x))
 	}
-	if len(x) != len(weights) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(x, weights)
 	var (
 		sumValues  float64
 		sumWeights float64
@@ -673,9 +1061,7 @@ func Mean(x, weights []float64) float64 {
 // given weights. Strict float64 equality is used when comparing values, so users
 // should take caution. If several values are the mode, any of them may be returned.
 func Mode(x, weights []float64) (val float64, count float64) {
-	if weights != nil && len(x) != len(weights) {
-		panic("stat: slice length mismatch")
-	}
+	checkWeightLength(x, weights)
 	if len(x) == 0 {
 		return 0, 0
 	}
@@ -742,9 +1128,7 @@ func MomentAbout(moment float64, x []float64, mean float64, weights []float64) f
 		m /= float64(len(x))
 		return m
 	}
-	if len(weights) != len(x) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(weights, x)
 	var (
 		m          float64
 		sumWeights float64
@@ -756,6 +1140,66 @@ func MomentAbout(moment float64, x []float64, mean float64, weights []float64) f
 	return m / sumWeights
 }
 
+// PooledVariance returns the pooled sample variance of the groups,
+//  s_p² = \sum_i \sum_j w_ij (x_ij - mean_i)^2 / \sum_i (W_i - 1)
+// where mean_i and W_i are the weighted mean and the sum of the weights of
+// group i. This is the average of the variances of the groups weighted by
+// their degrees of freedom, the estimate of a common variance used by the
+// two-sample t-test with equal variances and by Cohen's d. A group with
+// fewer than two observations adds nothing to either sum, whatever its
+// weight. PooledVariance returns NaN if no group has more than one
+// observation.
+//
+// If weights is nil then all of the weights are 1. If weights is not nil, then
+// len(weights) must equal len(groups), and weights[i] holds the weights of
+// groups[i] as for Variance. The weights are frequency weights.
+func PooledVariance(groups, weights [][]float64) float64 {
+	if weights != nil && len(weights) != len(groups) {
+		panic(ErrLengthMismatch{Got: len(weights), Want: len(groups)})
+	}
+	var ss, df float64
+	for i, g := range groups {
+		var w []float64
+		if weights != nil {
+			w = weights[i]
+		}
+		checkWeightLength(g, w)
+		if len(g) < 2 {
+			continue
+		}
+		// The corrected two-pass algorithm of MeanVariance, without the
+		// division by the degrees of freedom of the group.
+		mean := Mean(g, w)
+		var gss, comp float64
+		sumWeights := float64(len(g))
+		if w == nil {
+			for _, v := range g {
+				d := v - mean
+				gss += d * d
+				comp += d
+			}
+		} else {
+			sumWeights = 0
+			for j, v := range g {
+				d := v - mean
+				wd := w[j] * d
+				gss += wd * d
+				comp += wd
+				sumWeights += w[j]
+			}
+		}
+		ss += gss - comp*comp/sumWeights
+		df += sumWeights - 1
+	}
+	return ss / df
+}
+
+// PooledStdDev returns the pooled sample standard deviation of the groups,
+// the square root of PooledVariance.
+func PooledStdDev(groups, weights [][]float64) float64 {
+	return math.Sqrt(PooledVariance(groups, weights))
+}
+
 // Quantile returns the sample of x such that x is greater than or
 // equal to the fraction p of samples. The exact behavior is determined by the
 // CumulantKind, and p should be a number between 0 and 1. Quantile is theoretically
@@ -764,23 +1208,28 @@ func MomentAbout(moment float64, x []float64, mean float64, weights []float64) f
 //
 // The x data must be sorted in increasing order. If weights is nil then all
 // of the weights are 1. If weights is not nil, then len(x) must equal len(weights).
+// The weights are frequency or importance weights, which need not be integers,
+// and a sample with weight w counts as w samples of weight 1. If all of the
+// weights are equal, the result is that for nil weights.
 //
 // CumulantKind behaviors:
 //  - Empirical: Returns the lowest value q for which q is greater than or equal
-//  to the fraction p of samples
+//  to the fraction p of samples, that is the first x[i] for which
+//   sum_{j <= i} w_j >= p * sum_j w_j
 func Quantile(p float64, c CumulantKind, x, weights []float64) float64 {
 	if !(p >= 0 && p <= 1) {
 		panic("stat: percentile out of bounds")
 	}
 
-	if weights != nil && len(x) != len(weights) {
-		panic("stat: slice length mismatch")
-	}
+	checkWeightLength(x, weights)
 	if floats.HasNaN(x) {
 		return math.NaN() // This is needed because the algorithm breaks otherwise
 	}
-	if !sort.Float64sAreSorted(x) {
-		panic("x data are not sorted")
+	checkSorted(x)
+	if equalWeights(weights) {
+		// Avoid the rounding of fractional cumulative weights, so that
+		// the result is exactly that for nil weights.
+		weights = nil
 	}
 
 	var sumWeights float64
@@ -809,6 +1258,63 @@ func Quantile(p float64, c CumulantKind, x, weights []float64) float64 {
 	}
 }
 
+// equalWeights returns whether weights is not empty and all of its elements
+// are equal and positive.
+func equalWeights(weights []float64) bool {
+	if len(weights) == 0 || !(weights[0] > 0) {
+		return false
+	}
+	for _, w := range weights[1:] {
+		if w != weights[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// WeightedMedian returns the weighted median of x, the value at which the
+// cumulative weight of the samples in increasing order reaches half of the
+// total weight. If it reaches exactly half at a value, the mean of that value
+// and the next with positive weight is returned. x need not be sorted and is
+// not modified. If weights is nil or all of its elements are equal, the
+// result is that of Median. If weights is not nil, then len(x) must equal
+// len(weights), and the weights must not be negative. WeightedMedian returns
+// NaN if x contains a NaN value, and panics if x is empty.
+func WeightedMedian(x, weights []float64) float64 {
+	if err := ValidateWeights(x, weights); err != nil {
+		panic(err)
+	}
+	if weights == nil || equalWeights(weights) {
+		return Median(x)
+	}
+	if len(x) == 0 {
+		panic("stat: zero length slice")
+	}
+	if floats.HasNaN(x) {
+		return math.NaN()
+	}
+	order := argsortFloats(x)
+	half := floats.Sum(weights) / 2
+	var cumsum float64
+	for k, i := range order {
+		cumsum += weights[i]
+		if cumsum < half {
+			continue
+		}
+		if cumsum == half {
+			for _, j := range order[k+1:] {
+				if weights[j] > 0 {
+					return (x[i] + x[j]) / 2
+				}
+			}
+		}
+		return x[i]
+	}
+	// The running sum can fall short of the total computed in a different
+	// order only by rounding, at the largest value.
+	return x[order[len(order)-1]]
+}
+
 // Skew computes the skewness of the sample data.
 // If weights is nil then all of the weights are 1. If weights is not nil, then
 // len(x) must equal len(weights).
@@ -849,9 +1355,7 @@ func SortWeighted(x, weights []float64) {
 		sort.Float64s(x)
 		return
 	}
-	if len(x) != len(weights) {
-		panic("stat: slice length mismatch")
-	}
+	checkLengths(x, weights)
 	sort.Sort(weightSorter{
 		x: x,
 		w: weights,
@@ -949,3 +1453,98 @@ func MeanVariance(x, weights []float64) (mean, variance float64) {
 	variance = (ss - compensation*compensation/sumWeights) / (sumWeights - 1)
 	return
 }
+
+// Wasserstein computes the p-Wasserstein, or earth mover's, distance between
+// the empirical distributions of the samples x and y with the weights
+// xWeights and yWeights,
+//  W_p = ( \int_0^1 |F^{-1}(u) - G^{-1}(u)|^p du )^{1/p}
+// where F^{-1} and G^{-1} are the quantile functions of the two samples.
+// For p = 1 the distance is the area between the empirical CDFs,
+//  W_1 = \int |F(t) - G(t)| dt
+// and for p = +Inf it is the largest distance by which any mass is moved.
+// Unlike KolmogorovSmirnov, the distance depends on how far apart the masses
+// of the distributions are. The weights are frequency weights, so a sample
+// with weight w counts as w observations, and only the relative weights
+// within each sample matter.
+//
+// x and y need not be sorted, and may have different lengths. If xWeights is
+// nil then all of the weights of x are 1, otherwise len(x) must equal
+// len(xWeights), and likewise for y. Wasserstein returns NaN if either
+// sample has zero total weight or contains NaN, and panics if p is less
+// than 1.
+func Wasserstein(x, xWeights, y, yWeights []float64, p float64) float64 {
+	checkWeightLength(x, xWeights)
+	checkWeightLength(y, yWeights)
+	if !(p >= 1) {
+		panic("stat: Wasserstein order less than 1")
+	}
+	if floats.HasNaN(x) || floats.HasNaN(y) {
+		return math.NaN()
+	}
+	xs, xc := quantileSteps(x, xWeights)
+	ys, yc := quantileSteps(y, yWeights)
+	if xs == nil || ys == nil {
+		return math.NaN()
+	}
+
+	// The quantile functions are constant between the cumulative weight
+	// fractions of either sample.
+	var dist, u float64
+	for i, j := 0, 0; i < len(xs) && j < len(ys); {
+		next := math.Min(xc[i], yc[j])
+		d := math.Abs(xs[i] - ys[j])
+		switch {
+		case math.IsInf(p, 1):
+			if next > u && d > dist {
+				dist = d
+			}
+		case p == 1:
+			dist += (next - u) * d
+		default:
+			dist += (next - u) * math.Pow(d, p)
+		}
+		u = next
+		if xc[i] == next {
+			i++
+		}
+		if yc[j] == next {
+			j++
+		}
+	}
+	if math.IsInf(p, 1) || p == 1 {
+		return dist
+	}
+	return math.Pow(dist, 1/p)
+}
+
+// quantileSteps returns the sorted values of x and the fractions of the
+// total weight of the values up to and including each of them, the last of
+// which is exactly 1. It returns nil slices if the total weight is zero.
+func quantileSteps(x, weights []float64) (sorted, cum []float64) {
+	sorted = make([]float64, len(x))
+	copy(sorted, x)
+	var w []float64
+	if weights != nil {
+		w = make([]float64, len(weights))
+		copy(w, weights)
+	}
+	SortWeighted(sorted, w)
+	cum = make([]float64, len(sorted))
+	var sum float64
+	for i := range sorted {
+		if w == nil {
+			sum++
+		} else {
+			sum += w[i]
+		}
+		cum[i] = sum
+	}
+	if !(sum > 0) {
+		return nil, nil
+	}
+	for i := range cum {
+		cum[i] /= sum
+	}
+	cum[len(cum)-1] = 1
+	return sorted, cum
+}
@@ -7,9 +7,12 @@ package stat
 import (
 	"fmt"
 	"math"
+	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
 )
 
 func ExampleCorrelation() {
@@ -80,6 +83,54 @@ func TestCorrelation(t *testing.T) {
 	}
 }
 
+func TestSpearman(t *testing.T) {
+	for i, test := range []struct {
+		x   []float64
+		y   []float64
+		w   []float64
+		ans float64
+	}{
+		{
+			x:   []float64{1, 2, 3, 4, 5},
+			y:   []float64{1, 8, 27, 64, 125},
+			ans: 1,
+		},
+		{
+			x:   []float64{1, 2, 3, 4, 5},
+			y:   []float64{math.Exp(5), math.Exp(4), math.Exp(3), math.Exp(2), math.Exp(1)},
+			ans: -1,
+		},
+		{
+			// Values from scipy.stats.spearmanr.
+			x:   []float64{1, 2, 3, 4, 5},
+			y:   []float64{5, 6, 7, 8, 7},
+			ans: 0.8207826816681233,
+		},
+		{
+			x:   []float64{8, -3, 7, 8, -4},
+			y:   []float64{10, 15, 4, 5, -1},
+			ans: 0.35909242322980395,
+		},
+		{
+			x:   []float64{8, -3, 7, 8, -4},
+			y:   []float64{10, 15, 4, 5, -1},
+			w:   []float64{1, 3, 1, 2, 2},
+			ans: Correlation([]float64{4.5, 2, 3, 4.5, 1}, []float64{4, 5, 2, 3, 1}, []float64{1, 3, 1, 2, 2}),
+		},
+	} {
+		c := Spearman(test.x, test.y, test.w)
+		if math.Abs(test.ans-c) > 1e-14 {
+			t.Errorf("Spearman mismatch case %d. Expected %v, Found %v", i, test.ans, c)
+		}
+	}
+	if !Panics(func() { Spearman(make([]float64, 2), make([]float64, 3), nil) }) {
+		t.Errorf("Spearman did not panic with length mismatch")
+	}
+	if !Panics(func() { Spearman(make([]float64, 3), make([]float64, 3), make([]float64, 2)) }) {
+		t.Errorf("Spearman did not panic with weights length mismatch")
+	}
+}
+
 func ExampleCovariance() {
 	fmt.Println("Covariance computes the degree to which datasets move together")
 	fmt.Println("about their mean.")
@@ -235,6 +286,198 @@ distribution`)
 	// Weighted ExKurtosis is -0.6779
 }
 
+func TestDifferentialEntropy(t *testing.T) {
+	// ε = 1, 1, 2 and ψ(3) - ψ(1) = 3/2.
+	x := []float64{3, 0, 1}
+	if got, want := DifferentialEntropy(x, 1), 1.5+math.Ln2+math.Ln2/3; math.Abs(got-want) > 1e-15 {
+		t.Errorf("entropy mismatch. Want %v, got %v", want, got)
+	}
+	if x[0] != 3 {
+		t.Errorf("DifferentialEntropy modified its input")
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	const n = 5000
+	uniform := make([]float64, n)
+	normal := make([]float64, n)
+	for i := range uniform {
+		uniform[i] = 4 * rnd.Float64()
+		normal[i] = 2 * rnd.NormFloat64()
+	}
+	for _, test := range []struct {
+		name string
+		x    []float64
+		want float64
+	}{
+		{"uniform on [0, 4]", uniform, math.Log(4)},
+		{"normal with σ = 2", normal, 0.5 * math.Log(2*math.Pi*math.E*4)},
+	} {
+		for _, k := range []int{1, 3, 10} {
+			if got := DifferentialEntropy(test.x, k); math.Abs(got-test.want) > 0.03 {
+				t.Errorf("%s, k = %d: entropy mismatch. Want %v, got %v", test.name, k, test.want, got)
+			}
+		}
+	}
+
+	// Scaling the sample by c adds log(c) to the estimate.
+	scaled := make([]float64, n)
+	for i, v := range normal {
+		scaled[i] = 10 * v
+	}
+	if got, want := DifferentialEntropy(scaled, 3), DifferentialEntropy(normal, 3)+math.Log(10); math.Abs(got-want) > 1e-12 {
+		t.Errorf("entropy of scaled sample mismatch. Want %v, got %v", want, got)
+	}
+
+	if h := DifferentialEntropy([]float64{1, 1, 2, 5}, 1); !math.IsInf(h, -1) {
+		t.Errorf("entropy with duplicates: want -Inf, got %v", h)
+	}
+	if h := DifferentialEntropy([]float64{1, 1, 2, 5}, 2); math.IsInf(h, 0) {
+		t.Errorf("entropy with fewer than k duplicates: want finite, got %v", h)
+	}
+
+	for i, fn := range []func(){
+		func() { DifferentialEntropy([]float64{1, 2, 3}, 0) },
+		func() { DifferentialEntropy([]float64{1, 2, 3}, 3) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
+
+func TestEnergyDistance(t *testing.T) {
+	// The examples of energy_distance in SciPy, which returns the square
+	// root of the distance.
+	if e := EnergyDistance([]float64{0}, []float64{1}); e != 2 {
+		t.Errorf("energy distance mismatch. Want 2, got %v", e)
+	}
+	if e := EnergyDistance([]float64{0}, []float64{2}); e != 4 {
+		t.Errorf("energy distance mismatch. Want 4, got %v", e)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for k := 0; k < 20; k++ {
+		x := make([]float64, 1+rnd.Intn(20))
+		for i := range x {
+			x[i] = float64(rnd.Intn(10))
+		}
+		y := make([]float64, 1+rnd.Intn(20))
+		for i := range y {
+			y[i] = rnd.NormFloat64()*3 + 4
+		}
+		mean := func(a, b []float64) float64 {
+			var s float64
+			for _, u := range a {
+				for _, v := range b {
+					s += math.Abs(u - v)
+				}
+			}
+			return s / float64(len(a)*len(b))
+		}
+		want := 2*mean(x, y) - mean(x, x) - mean(y, y)
+		xc := append([]float64(nil), x...)
+		yc := append([]float64(nil), y...)
+		got := EnergyDistance(x, y)
+		if math.Abs(got-want) > 1e-12 {
+			t.Errorf("case %d: energy distance mismatch. Want %v, got %v", k, want, got)
+		}
+		if !floats.Equal(x, xc) || !floats.Equal(y, yc) {
+			t.Errorf("case %d: energy distance modified its input", k)
+		}
+
+		// E is twice the integral of the squared difference of the CDFs.
+		pts := append(append([]float64(nil), x...), y...)
+		sort.Float64s(pts)
+		cdf := func(s []float64, t float64) float64 {
+			var c float64
+			for _, v := range s {
+				if v <= t {
+					c++
+				}
+			}
+			return c / float64(len(s))
+		}
+		var integral float64
+		for i := 1; i < len(pts); i++ {
+			d := cdf(x, pts[i-1]) - cdf(y, pts[i-1])
+			integral += (pts[i] - pts[i-1]) * d * d
+		}
+		if math.Abs(got-2*integral) > 1e-12 {
+			t.Errorf("case %d: energy distance mismatch with CDF integral. Want %v, got %v", k, 2*integral, got)
+		}
+	}
+
+	if e := EnergyDistance([]float64{1, math.NaN()}, []float64{1}); !math.IsNaN(e) {
+		t.Errorf("energy distance with NaN: want NaN, got %v", e)
+	}
+	if !Panics(func() { EnergyDistance(nil, []float64{1}) }) {
+		t.Errorf("EnergyDistance did not panic with empty sample")
+	}
+}
+
+func TestRenyiEntropy(t *testing.T) {
+	p := []float64{0.5, 0.25, 0, 0.25}
+	for _, test := range []struct {
+		q, base, want float64
+	}{
+		{q: 0, base: 2, want: 1.584962500721156},
+		{q: 0.5, base: 2, want: 1.5431066063272239},
+		{q: 1, base: 2, want: 1.5},
+		{q: 1, base: math.E, want: 1.5 * math.Ln2},
+		{q: 2, base: 2, want: 1.415037499278844},
+		{q: 2, base: 10, want: 1.415037499278844 * math.Log10(2)},
+		{q: math.Inf(1), base: 2, want: 1},
+	} {
+		if got := RenyiEntropy(p, test.q, test.base); math.Abs(got-test.want) > 1e-14 {
+			t.Errorf("order %v, base %v: Rényi entropy mismatch. Want %v, got %v", test.q, test.base, test.want, got)
+		}
+	}
+	// The orders near 1 and large orders approach the limits.
+	for _, q := range []float64{1 - 1e-7, 1 + 1e-7} {
+		if got := RenyiEntropy(p, q, 2); math.Abs(got-1.5) > 1e-6 {
+			t.Errorf("order %v: Rényi entropy mismatch. Want about 1.5, got %v", q, got)
+		}
+	}
+	if got := RenyiEntropy(p, 200, 2); math.Abs(got-1) > 0.01 {
+		t.Errorf("order 200: Rényi entropy mismatch. Want about 1, got %v", got)
+	}
+	// The uniform distribution has the same entropy at all orders.
+	u := []float64{0.25, 0.25, 0.25, 0.25}
+	for _, q := range []float64{0, 0.5, 1, 3, math.Inf(1)} {
+		if got := RenyiEntropy(u, q, 2); math.Abs(got-2) > 1e-14 {
+			t.Errorf("order %v: Rényi entropy of uniform distribution mismatch. Want 2, got %v", q, got)
+		}
+	}
+	if !Panics(func() { RenyiEntropy(p, -1, 2) }) {
+		t.Errorf("RenyiEntropy did not panic with negative order")
+	}
+}
+
+func TestTsallisEntropy(t *testing.T) {
+	p := []float64{0.5, 0.25, 0, 0.25}
+	for _, test := range []struct {
+		q, want float64
+	}{
+		{q: 0, want: 2},
+		{q: 1, want: 1.5 * math.Ln2},
+		{q: 2, want: 0.625},
+		{q: 3, want: 0.421875},
+		{q: math.Inf(1), want: 0},
+	} {
+		if got := TsallisEntropy(p, test.q); math.Abs(got-test.want) > 1e-15 {
+			t.Errorf("order %v: Tsallis entropy mismatch. Want %v, got %v", test.q, test.want, got)
+		}
+	}
+	for _, q := range []float64{1 - 1e-7, 1 + 1e-7} {
+		if got := TsallisEntropy(p, q); math.Abs(got-1.5*math.Ln2) > 1e-6 {
+			t.Errorf("order %v: Tsallis entropy mismatch. Want about %v, got %v", q, 1.5*math.Ln2, got)
+		}
+	}
+	if !Panics(func() { TsallisEntropy(p, math.NaN()) }) {
+		t.Errorf("TsallisEntropy did not panic with NaN order")
+	}
+}
+
 func TestExKurtosis(t *testing.T) {
 	// the example does a good job, this just has to cover the panic
 	if !Panics(func() { ExKurtosis(make([]float64, 3), make([]float64, 2)) }) {
@@ -470,6 +713,116 @@ the count field in order to avoid extra garbage`)
 	// Weighted Hist = [66 165 265 365 465 565 665 765 865 965]
 }
 
+func TestNormalizedHistogram(t *testing.T) {
+	x := []float64{1, 3, 5, 6, 7, 8}
+	weights := []float64{1, 2, 1, 1, 1, 2}
+	dividers := []float64{1, 2, 4, 6, 7, 9}
+	// The counts are 1, 2, 1, 1 and 3 of a total of 8.
+	for _, test := range []struct {
+		norm HistogramNormalization
+		want []float64
+	}{
+		{CountHistogram, []float64{1, 2, 1, 1, 3}},
+		{ProbabilityHistogram, []float64{1.0 / 8, 2.0 / 8, 1.0 / 8, 1.0 / 8, 3.0 / 8}},
+		{DensityHistogram, []float64{1.0 / 8, 1.0 / 8, 1.0 / 16, 1.0 / 8, 3.0 / 16}},
+	} {
+		got := NormalizedHistogram(nil, dividers, x, weights, test.norm)
+		if !floats.EqualApprox(got, test.want, 1e-15) {
+			t.Errorf("normalization %d: histogram mismatch. Want %v, got %v", test.norm, test.want, got)
+		}
+		// Reusing the destination gives the same result.
+		NormalizedHistogram(got, dividers, x, weights, test.norm)
+		if !floats.EqualApprox(got, test.want, 1e-15) {
+			t.Errorf("normalization %d: histogram mismatch with destination. Want %v, got %v", test.norm, test.want, got)
+		}
+	}
+	// The density integrates to 1.
+	density := NormalizedHistogram(nil, dividers, x, weights, DensityHistogram)
+	var integral float64
+	for i, d := range density {
+		integral += d * (dividers[i+1] - dividers[i])
+	}
+	if math.Abs(integral-1) > 1e-15 {
+		t.Errorf("density integrates to %v", integral)
+	}
+
+	cum := CumulativeHistogram(nil, dividers, x, weights, false)
+	if want := []float64{1, 3, 4, 5, 8}; !floats.Equal(cum, want) {
+		t.Errorf("cumulative histogram mismatch. Want %v, got %v", want, cum)
+	}
+	cum = CumulativeHistogram(cum, dividers, x, weights, true)
+	if want := []float64{1.0 / 8, 3.0 / 8, 4.0 / 8, 5.0 / 8, 1}; !floats.Equal(cum, want) {
+		t.Errorf("normalized cumulative histogram mismatch. Want %v, got %v", want, cum)
+	}
+	// The normalized cumulative histogram is the empirical CDF below the
+	// upper dividers.
+	for i, v := range cum {
+		if cdf := CDF(math.Nextafter(dividers[i+1], math.Inf(-1)), Empirical, x, weights); math.Abs(cdf-v) > 1e-15 {
+			t.Errorf("cumulative histogram mismatch with CDF at %v. Want %v, got %v", dividers[i+1], cdf, v)
+		}
+	}
+
+	if got := NormalizedHistogram(nil, []float64{0, 1}, nil, nil, ProbabilityHistogram); !math.IsNaN(got[0]) {
+		t.Errorf("normalized histogram of empty data: want NaN, got %v", got)
+	}
+	if !Panics(func() { NormalizedHistogram(nil, dividers, x, weights, HistogramNormalization(3)) }) {
+		t.Errorf("NormalizedHistogram did not panic with bad normalization")
+	}
+}
+
+func TestHistogram2D(t *testing.T) {
+	x := []float64{1, 3, 5, 6, 7, 8, 1}
+	y := []float64{0.5, 0.1, 0.9, 0.5, 0.5, 0.2, 0.6}
+	weights := []float64{1, 2, 1, 1, 1, 2, 3}
+	xDiv := []float64{0, 2, 4, 6, 7, 9}
+	yDiv := []float64{0, 0.5, 1}
+	want := mat64.NewDense(5, 2, []float64{
+		0, 4,
+		2, 0,
+		0, 1,
+		0, 1,
+		2, 1,
+	})
+	hist := Histogram2D(nil, x, y, xDiv, yDiv, weights)
+	if !floats.Equal(hist.RawMatrix().Data, want.RawMatrix().Data) {
+		t.Errorf("histogram mismatch. Want %v, got %v", want.RawMatrix().Data, hist.RawMatrix().Data)
+	}
+	// The destination is overwritten.
+	Histogram2D(hist, x, y, xDiv, yDiv, weights)
+	if !floats.Equal(hist.RawMatrix().Data, want.RawMatrix().Data) {
+		t.Errorf("histogram mismatch when reusing the destination. Want %v, got %v", want.RawMatrix().Data, hist.RawMatrix().Data)
+	}
+
+	// The margins are the one-dimensional histograms.
+	hist = Histogram2D(nil, x, y, xDiv, yDiv, nil)
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+	rows := Histogram(nil, xDiv, sorted, nil)
+	for j, v := range rows {
+		if s := hist.At(j, 0) + hist.At(j, 1); s != v {
+			t.Errorf("margin mismatch for x bin %d. Want %v, got %v", j, v, s)
+		}
+	}
+
+	for _, test := range []struct {
+		name string
+		fn   func()
+	}{
+		{"length mismatch", func() { Histogram2D(nil, x, y[1:], xDiv, yDiv, nil) }},
+		{"weights length", func() { Histogram2D(nil, x, y, xDiv, yDiv, weights[1:]) }},
+		{"one divider", func() { Histogram2D(nil, x, y, xDiv, []float64{0}, nil) }},
+		{"unsorted dividers", func() { Histogram2D(nil, x, y, []float64{0, 9, 4}, yDiv, nil) }},
+		{"destination size", func() { Histogram2D(mat64.NewDense(2, 5, nil), x, y, xDiv, yDiv, nil) }},
+		{"x out of range", func() { Histogram2D(nil, []float64{9}, []float64{0}, xDiv, yDiv, nil) }},
+		{"y out of range", func() { Histogram2D(nil, []float64{1}, []float64{-0.1}, xDiv, yDiv, nil) }},
+		{"NaN", func() { Histogram2D(nil, []float64{1}, []float64{math.NaN()}, xDiv, yDiv, nil) }},
+	} {
+		if !Panics(test.fn) {
+			t.Errorf("Histogram2D did not panic with %s", test.name)
+		}
+	}
+}
+
 func TestJensenShannon(t *testing.T) {
 	for i, test := range []struct {
 		p []float64
@@ -520,6 +873,48 @@ func TestJensenShannon(t *testing.T) {
 	}
 }
 
+func TestJensenShannonDistance(t *testing.T) {
+	p := []float64{0.5, 0.1, 0.3, 0.1}
+	q := []float64{0.1, 0.4, 0.25, 0.25}
+	if got, want := JensenShannonDistance(p, q), math.Sqrt(JensenShannon(p, q)); got != want {
+		t.Errorf("JS distance mismatch. Want %v, got %v", want, got)
+	}
+	if d := JensenShannonDistance([]float64{1, 0}, []float64{0, 1}); math.Abs(d-math.Sqrt(math.Ln2)) > 1e-15 {
+		t.Errorf("JS distance of disjoint distributions: want %v, got %v", math.Sqrt(math.Ln2), d)
+	}
+	third := []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}
+	if d := JensenShannonDistance(third, third); d != 0 {
+		t.Errorf("JS distance of equal distributions: want 0, got %v", d)
+	}
+
+	// The distance is a metric.
+	rnd := rand.New(rand.NewSource(1))
+	dist := func() []float64 {
+		d := make([]float64, 5)
+		for i := range d {
+			if rnd.Intn(4) > 0 {
+				d[i] = rnd.Float64()
+			}
+		}
+		d[0] += 1e-3
+		floats.Scale(1/floats.Sum(d), d)
+		return d
+	}
+	for i := 0; i < 100; i++ {
+		a, b, c := dist(), dist(), dist()
+		ab := JensenShannonDistance(a, b)
+		if math.Abs(ab-JensenShannonDistance(b, a)) > 1e-15 {
+			t.Errorf("JS distance not symmetric for %v and %v", a, b)
+		}
+		if ab+JensenShannonDistance(b, c) < JensenShannonDistance(a, c)-1e-15 {
+			t.Errorf("JS distance triangle inequality violated for %v, %v and %v", a, b, c)
+		}
+	}
+	if !Panics(func() { JensenShannonDistance(make([]float64, 3), make([]float64, 2)) }) {
+		t.Errorf("JensenShannonDistance did not panic with p, q length mismatch")
+	}
+}
+
 func TestKolmogorovSmirnov(t *testing.T) {
 	for i, test := range []struct {
 		x        []float64
@@ -752,6 +1147,168 @@ func TestKullbackLeibler(t *testing.T) {
 	}
 }
 
+func TestLinearRegression(t *testing.T) {
+	for i, test := range []struct {
+		x, y    []float64
+		weights []float64
+		origin  bool
+		alpha   float64
+		beta    float64
+	}{
+		{
+			x:     []float64{0, 1, 2, 3, 4},
+			y:     []float64{2, 5, 8, 11, 14},
+			alpha: 2,
+			beta:  3,
+		},
+		{
+			x:       []float64{0, 1, 2, 3, 4},
+			y:       []float64{2, 5, 8, 11, 14},
+			weights: []float64{1, 2, 0.5, 3, 1},
+			alpha:   2,
+			beta:    3,
+		},
+		{
+			x:     []float64{1, 2, 3},
+			y:     []float64{1, 3, 2},
+			alpha: 1,
+			beta:  0.5,
+		},
+		{
+			x:       []float64{1, 2, 3},
+			y:       []float64{1, 3, 2},
+			weights: []float64{1, 1, 2},
+			alpha:   1.1818181818181819,
+			beta:    0.36363636363636365,
+		},
+		{
+			x:      []float64{1, 2, 3},
+			y:      []float64{1, 3, 2},
+			origin: true,
+			alpha:  0,
+			beta:   13.0 / 14,
+		},
+		{
+			x:       []float64{1, 2, 3},
+			y:       []float64{1, 3, 2},
+			weights: []float64{2, 1, 1},
+			origin:  true,
+			alpha:   0,
+			beta:    14.0 / 15,
+		},
+	} {
+		alpha, beta := LinearRegression(test.x, test.y, test.weights, test.origin)
+		if math.Abs(alpha-test.alpha) > 1e-14 || math.Abs(beta-test.beta) > 1e-14 {
+			t.Errorf("LinearRegression mismatch case %d: Expected (%v, %v), Found (%v, %v)", i, test.alpha, test.beta, alpha, beta)
+		}
+	}
+	if !Panics(func() { LinearRegression(make([]float64, 3), make([]float64, 2), nil, false) }) {
+		t.Errorf("LinearRegression did not panic with x, y length mismatch")
+	}
+	if !Panics(func() { LinearRegression(make([]float64, 3), make([]float64, 3), make([]float64, 2), false) }) {
+		t.Errorf("LinearRegression did not panic with x, weights length mismatch")
+	}
+}
+
+func TestLinearRegressionFit(t *testing.T) {
+	// Statistics computed with exact rational arithmetic from the normal
+	// equations, p-values by numerical integration of the t density and
+	// the t quantiles t_{0.975,8} = 2.306004135204166 and
+	// t_{0.975,9} = 2.2621571627409915.
+	x := []float64{0.5, 1.5, 2, 3, 3.5, 4.5, 5, 6.5, 7, 8}
+	y := []float64{1.9, 3.2, 3.8, 5.1, 6.3, 7.2, 8.4, 10.1, 10.4, 12.6}
+	w := []float64{1, 2, 1, 3, 1, 0.5, 2, 1, 1, 2}
+	nan := math.NaN()
+	for i, test := range []struct {
+		weights []float64
+		origin  bool
+
+		alpha, alphaSE, alphaT, alphaP float64
+		beta, betaSE, betaT, betaP     float64
+		df, r2, sigma, q               float64
+	}{
+		{
+			alpha: 1.1222222222222222, alphaSE: 0.17849483068505967, alphaT: 6.287141302160714, alphaP: 0.00023603725211029936,
+			beta: 1.392235609103079, betaSE: 0.03736115124565028, betaT: 37.26425880051456, betaP: 2.9504709786465355e-10,
+			df: 8, r2: 0.9942719054206204, sigma: 0.2796476555327792, q: 2.306004135204166,
+		},
+		{
+			weights: w,
+			alpha:   1.0388635156664896, alphaSE: 0.17094516546724225, alphaT: 6.077174003879999, alphaP: 0.0002968537547974881,
+			beta: 1.4181837493361658, betaSE: 0.03597643024408324, betaT: 39.419801790073464, betaP: 1.8855750294477502e-10,
+			df: 8, r2: 0.9948781012856559, sigma: 0.32411507859738664, q: 2.306004135204166,
+		},
+		{
+			origin: true,
+			alpha:  0, alphaSE: nan, alphaT: nan, alphaP: nan,
+			beta: 1.596276013143483, betaSE: 0.04253632548306845, betaT: 37.5273603212125, betaP: 3.3602343130212375e-11,
+			df: 9, r2: 0.9936499101155403, sigma: 0.6426364629116147, q: 2.2621571627409915,
+		},
+		{
+			weights: w,
+			origin:  true,
+			alpha:   0, alphaSE: nan, alphaT: nan, alphaP: nan,
+			beta: 1.6077892325315006, betaSE: 0.04002518174319629, betaT: 40.16944239871695, betaP: 1.8267609647182326e-11,
+			df: 9, r2: 0.994453292137248, sigma: 0.7241959109587738, q: 2.2621571627409915,
+		},
+	} {
+		r := LinearRegressionFit(x, y, test.weights, test.origin, 0.95)
+		alpha, beta := LinearRegression(x, y, test.weights, test.origin)
+		if math.Abs(r.Alpha-alpha) > 1e-12 || math.Abs(r.Beta-beta) > 1e-12 {
+			t.Errorf("case %d: fit does not match LinearRegression. Want (%v, %v), got (%v, %v)", i, alpha, beta, r.Alpha, r.Beta)
+		}
+		alphaCI := [2]float64{test.alpha - test.q*test.alphaSE, test.alpha + test.q*test.alphaSE}
+		betaCI := [2]float64{test.beta - test.q*test.betaSE, test.beta + test.q*test.betaSE}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+			tol       float64
+		}{
+			{"alpha", r.Alpha, test.alpha, 1e-12},
+			{"alpha standard error", r.AlphaStdErr, test.alphaSE, 1e-12},
+			{"alpha t", r.AlphaT, test.alphaT, 1e-10},
+			{"alpha p-value", r.AlphaP, test.alphaP, 1e-10},
+			{"alpha lower bound", r.AlphaCI[0], alphaCI[0], 1e-10},
+			{"alpha upper bound", r.AlphaCI[1], alphaCI[1], 1e-10},
+			{"beta", r.Beta, test.beta, 1e-12},
+			{"beta standard error", r.BetaStdErr, test.betaSE, 1e-12},
+			{"beta t", r.BetaT, test.betaT, 1e-10},
+			{"beta p-value", r.BetaP, test.betaP, 1e-12},
+			{"beta lower bound", r.BetaCI[0], betaCI[0], 1e-10},
+			{"beta upper bound", r.BetaCI[1], betaCI[1], 1e-10},
+			{"degrees of freedom", r.DF, test.df, 0},
+			{"R²", r.R2, test.r2, 1e-13},
+			{"residual standard error", r.ResidualStdErr, test.sigma, 1e-13},
+		} {
+			if math.IsNaN(v.want) {
+				if !math.IsNaN(v.got) {
+					t.Errorf("case %d: %s mismatch. Want NaN, got %v", i, v.name, v.got)
+				}
+				continue
+			}
+			if math.Abs(v.got-v.want) > v.tol*math.Max(1, math.Abs(v.want)) {
+				t.Errorf("case %d: %s mismatch. Want %v, got %v", i, v.name, v.want, v.got)
+			}
+		}
+		if r.Level != 0.95 {
+			t.Errorf("case %d: level mismatch. Want 0.95, got %v", i, r.Level)
+		}
+	}
+
+	if !Panics(func() { LinearRegressionFit(x, y[:3], nil, false, 0.95) }) {
+		t.Errorf("LinearRegressionFit did not panic with x, y length mismatch")
+	}
+	if !Panics(func() { LinearRegressionFit(x, y, w[:3], false, 0.95) }) {
+		t.Errorf("LinearRegressionFit did not panic with x, weights length mismatch")
+	}
+	if !Panics(func() { LinearRegressionFit(x, y, nil, false, 1) }) {
+		t.Errorf("LinearRegressionFit did not panic with level out of range")
+	}
+	if !Panics(func() { LinearRegressionFit([]float64{1, 1, 1}, []float64{1, 2, 3}, nil, false, 0.95) }) {
+		t.Errorf("LinearRegressionFit did not panic with constant x")
+	}
+}
+
 func TestChiSquare(t *testing.T) {
 	for i, test := range []struct {
 		p   []float64
@@ -1175,6 +1732,81 @@ func TestQuantile(t *testing.T) {
 	}
 }
 
+func TestQuantileWeighted(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float64, 10)
+	for i := range x {
+		x[i] = rnd.NormFloat64()
+	}
+	sort.Float64s(x)
+	// Equal fractional weights give exactly the unweighted result.
+	for _, w := range []float64{0.1, 1.0 / 3, 7} {
+		weights := make([]float64, len(x))
+		for i := range weights {
+			weights[i] = w
+		}
+		for k := 0; k <= 1000; k++ {
+			p := float64(k) / 1000
+			if got, want := Quantile(p, Empirical, x, weights), Quantile(p, Empirical, x, nil); got != want {
+				t.Errorf("weight %v, p = %v: equal weights mismatch: got %v, want %v", w, p, got, want)
+			}
+		}
+		if got, want := WeightedMedian(x, weights), Median(x); got != want {
+			t.Errorf("weight %v: equal weights median mismatch: got %v, want %v", w, got, want)
+		}
+	}
+
+	// A single huge weight dominates.
+	weights := []float64{1, 1, 1, 1e12, 1, 1, 1, 1, 1, 1}
+	for _, p := range []float64{1e-6, 0.1, 0.5, 0.9, 1 - 1e-6} {
+		if got := Quantile(p, Empirical, x, weights); got != x[3] {
+			t.Errorf("p = %v: huge weight mismatch: got %v, want %v", p, got, x[3])
+		}
+	}
+	if got := WeightedMedian(x, weights); got != x[3] {
+		t.Errorf("huge weight median mismatch: got %v, want %v", got, x[3])
+	}
+	if got := Quantile(0, Empirical, x, weights); got != x[0] {
+		t.Errorf("p = 0: huge weight mismatch: got %v, want %v", got, x[0])
+	}
+	if got := Quantile(1, Empirical, x, weights); got != x[9] {
+		t.Errorf("p = 1: huge weight mismatch: got %v, want %v", got, x[9])
+	}
+
+	for i, test := range []struct {
+		x, w []float64
+		ans  float64
+	}{
+		{x: []float64{3, 1, 2}, w: []float64{0.5, 0.25, 0.25}, ans: 2.5},
+		{x: []float64{3, 1, 2, 4}, w: []float64{0.5, 0.25, 0, 0.25}, ans: 3},
+		{x: []float64{3, 1, 2, 4}, w: []float64{0.25, 0.5, 0, 0.25}, ans: 2},
+		{x: []float64{1, 2, 4}, w: []float64{2, 0, 2}, ans: 2.5},
+		{x: []float64{5, 1, math.NaN()}, w: []float64{1, 2, 3}, ans: math.NaN()},
+		{x: []float64{5, 1, 3}, ans: 3},
+	} {
+		got := WeightedMedian(test.x, test.w)
+		if got != test.ans && !(math.IsNaN(got) && math.IsNaN(test.ans)) {
+			t.Errorf("WeightedMedian mismatch case %d: got %v, want %v", i, got, test.ans)
+		}
+	}
+	if !Panics(func() { WeightedMedian(nil, nil) }) {
+		t.Errorf("WeightedMedian did not panic with empty input")
+	}
+	if !Panics(func() { WeightedMedian([]float64{1, 2}, []float64{1}) }) {
+		t.Errorf("WeightedMedian did not panic with weights length mismatch")
+	}
+	for _, w := range [][]float64{{-3, 1}, {-1, -1}} {
+		func() {
+			defer func() {
+				if r := recover(); r != (ErrNegativeWeight{Index: 0}) {
+					t.Errorf("WeightedMedian with weights %v: got panic %v, want %v", w, r, ErrNegativeWeight{Index: 0})
+				}
+			}()
+			WeightedMedian([]float64{1, 2}, w)
+		}()
+	}
+}
+
 func ExampleStdDev() {
 	x := []float64{8, 2, -9, 15, 4}
 	stdev := StdDev(x, nil)
@@ -1307,6 +1939,69 @@ func TestVariance(t *testing.T) {
 
 }
 
+func TestPooledVariance(t *testing.T) {
+	for i, test := range []struct {
+		groups  [][]float64
+		weights [][]float64
+		ans     float64
+	}{
+		{
+			groups: [][]float64{{8, -3, 7, 8, -4}},
+			ans:    37.7,
+		},
+		{
+			groups: [][]float64{{8, -3, 7, 8, -4}, {1, 2, 3}},
+			ans:    (4*37.7 + 2*1) / 6,
+		},
+		{
+			// Single observations and empty groups contribute nothing.
+			groups: [][]float64{{8, -3, 7, 8, -4}, {5}, {1, 2, 3}, {}},
+			ans:    (4*37.7 + 2*1) / 6,
+		},
+		{
+			groups:  [][]float64{{8, 3, 7, 8, 4}, {1, 2, 3}},
+			weights: [][]float64{{2, 1, 2, 1, 1}, nil},
+			ans:     (6*4.2857142857142865 + 2*1) / 8,
+		},
+		{
+			// Integer weights are equivalent to repeated observations.
+			groups:  [][]float64{{8, 3, 7, 8, 4}, {1, 2}},
+			weights: [][]float64{{2, 1, 2, 1, 1}, {3, 1}},
+			ans:     PooledVariance([][]float64{{8, 8, 3, 7, 7, 8, 4}, {1, 1, 1, 2}}, nil),
+		},
+		{
+			// A weighted single observation contributes no degrees of
+			// freedom whatever its weight.
+			groups:  [][]float64{{1, 2}, {5}},
+			weights: [][]float64{nil, {0.5}},
+			ans:     0.5,
+		},
+		{
+			groups:  [][]float64{{1, 2}, {5}},
+			weights: [][]float64{nil, {3}},
+			ans:     0.5,
+		},
+		{
+			groups: [][]float64{{1}, {2}},
+			ans:    math.NaN(),
+		},
+	} {
+		v := PooledVariance(test.groups, test.weights)
+		if !sameOrClose(v, test.ans, 1e-13) {
+			t.Errorf("PooledVariance mismatch case %d. Expected %v, Found %v", i, test.ans, v)
+		}
+		if std := PooledStdDev(test.groups, test.weights); !sameOrClose(std, math.Sqrt(test.ans), 1e-14) {
+			t.Errorf("PooledStdDev mismatch case %d. Expected %v, Found %v", i, math.Sqrt(test.ans), std)
+		}
+	}
+	if !Panics(func() { PooledVariance([][]float64{{1, 2}, {3, 4}}, [][]float64{{1, 1}}) }) {
+		t.Errorf("PooledVariance did not panic with groups, weights length mismatch")
+	}
+	if !Panics(func() { PooledVariance([][]float64{{1, 2}, {3, 4}}, [][]float64{{1, 1}, {1}}) }) {
+		t.Errorf("PooledVariance did not panic with group, weights length mismatch")
+	}
+}
+
 func ExampleVariance() {
 	x := []float64{8, 2, -9, 15, 4}
 	variance := Variance(x, nil)
@@ -1347,3 +2042,130 @@ func TestStdScore(t *testing.T) {
 	}
 
 }
+
+func TestWasserstein(t *testing.T) {
+	for i, test := range []struct {
+		x, xWeights, y, yWeights []float64
+		p, want                  float64
+	}{
+		// The examples of wasserstein_distance in SciPy.
+		{
+			x:    []float64{0, 1, 3},
+			y:    []float64{5, 6, 8},
+			p:    1,
+			want: 5,
+		},
+		{
+			x:        []float64{0, 1},
+			xWeights: []float64{3, 1},
+			y:        []float64{0, 1},
+			yWeights: []float64{2, 2},
+			p:        1,
+			want:     0.25,
+		},
+		{
+			x:        []float64{3.4, 3.9, 7.5, 7.8},
+			xWeights: []float64{1.4, 0.9, 3.1, 7.2},
+			y:        []float64{4.5, 1.4},
+			yWeights: []float64{3.2, 3.5},
+			p:        1,
+			want:     4.0781331438047861,
+		},
+		{
+			// Half of the mass moves by 1.
+			x:    []float64{1, 0},
+			y:    []float64{0, 2},
+			p:    2,
+			want: math.Sqrt(0.5),
+		},
+		{
+			x:    []float64{1, 0},
+			y:    []float64{0, 2},
+			p:    math.Inf(1),
+			want: 1,
+		},
+		{
+			// Shifting a sample moves all of the mass by the shift.
+			x:    []float64{0.3, -1, 2, 5},
+			y:    []float64{2.8, 1.5, 4.5, 7.5},
+			p:    3,
+			want: 2.5,
+		},
+		{
+			x:        []float64{1, 2},
+			y:        []float64{1},
+			yWeights: []float64{0},
+			p:        1,
+			want:     math.NaN(),
+		},
+		{
+			x:    []float64{1, math.NaN()},
+			y:    []float64{1},
+			p:    1,
+			want: math.NaN(),
+		},
+	} {
+		got := Wasserstein(test.x, test.xWeights, test.y, test.yWeights, test.p)
+		if math.IsNaN(test.want) {
+			if !math.IsNaN(got) {
+				t.Errorf("case %d: want NaN, got %v", i, got)
+			}
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-14 {
+			t.Errorf("case %d: Wasserstein mismatch. Want %v, got %v", i, test.want, got)
+		}
+		if back := Wasserstein(test.y, test.yWeights, test.x, test.xWeights, test.p); math.Abs(back-got) > 1e-14 {
+			t.Errorf("case %d: Wasserstein not symmetric. Got %v and %v", i, got, back)
+		}
+	}
+
+	// For p = 1 the distance is the area between the empirical CDFs.
+	rnd := rand.New(rand.NewSource(1))
+	for k := 0; k < 20; k++ {
+		x := make([]float64, 1+rnd.Intn(10))
+		xw := make([]float64, len(x))
+		for i := range x {
+			x[i] = float64(rnd.Intn(8))
+			xw[i] = rnd.Float64()
+		}
+		y := make([]float64, 1+rnd.Intn(10))
+		for i := range y {
+			y[i] = rnd.NormFloat64() * 3
+		}
+		cdf := func(s, w []float64, t float64) float64 {
+			var c, sum float64
+			for i, v := range s {
+				wi := 1.0
+				if w != nil {
+					wi = w[i]
+				}
+				if v <= t {
+					c += wi
+				}
+				sum += wi
+			}
+			return c / sum
+		}
+		pts := append(append([]float64(nil), x...), y...)
+		sort.Float64s(pts)
+		var area float64
+		for i := 1; i < len(pts); i++ {
+			area += (pts[i] - pts[i-1]) * math.Abs(cdf(x, xw, pts[i-1])-cdf(y, nil, pts[i-1]))
+		}
+		if got := Wasserstein(x, xw, y, nil, 1); math.Abs(got-area) > 1e-12 {
+			t.Errorf("case %d: Wasserstein mismatch with ECDF area. Want %v, got %v", k, area, got)
+		}
+	}
+
+	for i, fn := range []func(){
+		func() { Wasserstein([]float64{1, 2}, []float64{1}, []float64{1}, nil, 1) },
+		func() { Wasserstein([]float64{1}, nil, []float64{1}, []float64{1, 2}, 1) },
+		func() { Wasserstein([]float64{1}, nil, []float64{1}, nil, 0.5) },
+		func() { Wasserstein([]float64{1}, nil, []float64{1}, nil, math.NaN()) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}
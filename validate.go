@@ -0,0 +1,146 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// The functions in this package panic when given invalid input. The
+// Validate functions below perform the same checks and return the errors
+// that would otherwise be the panic values, so that input from untrusted
+// sources can be checked before a call. The errors are of the types below,
+// and can be inspected with a type assertion or a type switch.
+
+// ErrLengthMismatch is the error for a slice whose length differs from the
+// length required by another argument.
+type ErrLengthMismatch struct {
+	Got, Want int
+}
+
+func (e ErrLengthMismatch) Error() string {
+	return fmt.Sprintf("stat: slice length mismatch: got %d, want %d", e.Got, e.Want)
+}
+
+// ErrUnsorted is the error for data that must be sorted in increasing order
+// but are not. Index is the first index i with x[i] < x[i-1].
+type ErrUnsorted struct {
+	Index int
+}
+
+func (e ErrUnsorted) Error() string {
+	return fmt.Sprintf("stat: data not sorted at index %d", e.Index)
+}
+
+// ErrNegativeWeight is the error for a negative weight where weights must be
+// non-negative. Index is the index of the first negative weight.
+type ErrNegativeWeight struct {
+	Index int
+}
+
+func (e ErrNegativeWeight) Error() string {
+	return fmt.Sprintf("stat: negative weight at index %d", e.Index)
+}
+
+// ValidateLengths returns an ErrLengthMismatch if x and y have different
+// lengths, as required of the paired arguments of functions such as
+// Correlation and KullbackLeibler, and nil otherwise.
+func ValidateLengths(x, y []float64) error {
+	if len(y) != len(x) {
+		return ErrLengthMismatch{Got: len(y), Want: len(x)}
+	}
+	return nil
+}
+
+// ValidateWeights returns an error if weights is not a valid set of weights
+// for the data x, that is if weights is non-nil and has a length different
+// from x, in which case the error is an ErrLengthMismatch, or if a weight is
+// negative, in which case the error is an ErrNegativeWeight. A nil weights
+// is valid.
+func ValidateWeights(x, weights []float64) error {
+	if weights == nil {
+		return nil
+	}
+	if err := ValidateLengths(x, weights); err != nil {
+		return err
+	}
+	return validateNonNegative(weights)
+}
+
+// validateNonNegative returns an ErrNegativeWeight for the first negative
+// element of weights, or nil if there is none.
+func validateNonNegative(weights []float64) error {
+	for i, w := range weights {
+		if w < 0 {
+			return ErrNegativeWeight{Index: i}
+		}
+	}
+	return nil
+}
+
+// ValidateSorted returns an ErrUnsorted if x is not sorted in increasing
+// order, as required by functions such as Quantile and CDF, and nil
+// otherwise. NaN values are ordered before all other values, as by
+// sort.Float64s.
+func ValidateSorted(x []float64) error {
+	for i := 1; i < len(x); i++ {
+		if x[i] < x[i-1] || (math.IsNaN(x[i]) && !math.IsNaN(x[i-1])) {
+			return ErrUnsorted{Index: i}
+		}
+	}
+	return nil
+}
+
+// ValidateCovarianceMatrix returns the error for which CovarianceMatrix
+// would panic with the same arguments, or nil if the arguments are valid. The
+// error is mat64.ErrShape if cov is non-nil and not c×c, where c is the
+// number of columns of x, and otherwise is the error returned by
+// ValidateWeights for the weights of the rows of x.
+func ValidateCovarianceMatrix(cov *mat64.Dense, x mat64.Matrix, weights []float64) error {
+	r, c := x.Dims()
+	return validateCovarianceDims(cov, r, c, weights)
+}
+
+// validateCovarianceDims is ValidateCovarianceMatrix for data with r rows and
+// c columns.
+func validateCovarianceDims(cov *mat64.Dense, r, c int, weights []float64) error {
+	if cov != nil {
+		if cr, cc := cov.Dims(); cr != c || cc != c {
+			return mat64.ErrShape
+		}
+	}
+	if weights != nil && len(weights) != r {
+		return ErrLengthMismatch{Got: len(weights), Want: r}
+	}
+	return validateNonNegative(weights)
+}
+
+// checkLengths panics with an ErrLengthMismatch if x and y have different
+// lengths.
+func checkLengths(x, y []float64) {
+	if err := ValidateLengths(x, y); err != nil {
+		panic(err)
+	}
+}
+
+// checkWeightLength panics with an ErrLengthMismatch if weights is non-nil
+// and has a length different from x. Unlike ValidateWeights, it does not
+// check the signs of the weights.
+func checkWeightLength(x, weights []float64) {
+	if weights != nil {
+		checkLengths(x, weights)
+	}
+}
+
+// checkSorted panics with an ErrUnsorted if x is not sorted in increasing
+// order.
+func checkSorted(x []float64) {
+	if err := ValidateSorted(x); err != nil {
+		panic(err)
+	}
+}
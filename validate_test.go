@@ -0,0 +1,72 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestValidate(t *testing.T) {
+	nan := math.NaN()
+	for i, test := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"lengths equal", ValidateLengths([]float64{1, 2}, []float64{3, 4}), nil},
+		{"lengths differ", ValidateLengths([]float64{1, 2}, []float64{3}), ErrLengthMismatch{Got: 1, Want: 2}},
+		{"weights nil", ValidateWeights([]float64{1, 2}, nil), nil},
+		{"weights valid", ValidateWeights([]float64{1, 2}, []float64{0, 3}), nil},
+		{"weights short", ValidateWeights([]float64{1, 2, 3}, []float64{1, 2}), ErrLengthMismatch{Got: 2, Want: 3}},
+		{"weights negative", ValidateWeights([]float64{1, 2, 3}, []float64{1, -2, -3}), ErrNegativeWeight{Index: 1}},
+		{"sorted", ValidateSorted([]float64{nan, 1, 1, 2}), nil},
+		{"sorted empty", ValidateSorted(nil), nil},
+		{"unsorted", ValidateSorted([]float64{1, 2, 4, 3, 0}), ErrUnsorted{Index: 3}},
+		{"unsorted nan", ValidateSorted([]float64{1, nan}), ErrUnsorted{Index: 1}},
+		{"covariance valid", ValidateCovarianceMatrix(mat64.NewDense(2, 2, nil), mat64.NewDense(3, 2, nil), []float64{1, 1, 1}), nil},
+		{"covariance shape", ValidateCovarianceMatrix(mat64.NewDense(3, 3, nil), mat64.NewDense(3, 2, nil), nil), mat64.ErrShape},
+		{"covariance weights short", ValidateCovarianceMatrix(nil, mat64.NewDense(3, 2, nil), []float64{1, 1}), ErrLengthMismatch{Got: 2, Want: 3}},
+		{"covariance weights negative", ValidateCovarianceMatrix(nil, mat64.NewDense(3, 2, nil), []float64{1, 1, -1}), ErrNegativeWeight{Index: 2}},
+	} {
+		if !reflect.DeepEqual(test.err, test.want) {
+			t.Errorf("case %d (%s): got error %#v, want %#v", i, test.name, test.err, test.want)
+		}
+	}
+}
+
+func TestValidatePanicValues(t *testing.T) {
+	recovered := func(f func()) (v interface{}) {
+		defer func() { v = recover() }()
+		f()
+		return nil
+	}
+	for i, test := range []struct {
+		name string
+		f    func()
+		want interface{}
+	}{
+		{"Mean", func() { Mean([]float64{1, 2, 3}, []float64{1}) }, ErrLengthMismatch{Got: 1, Want: 3}},
+		{"Correlation", func() { Correlation([]float64{1, 2}, []float64{1, 2, 3}, nil) }, ErrLengthMismatch{Got: 3, Want: 2}},
+		{"Quantile", func() { Quantile(0.5, Empirical, []float64{2, 1}, nil) }, ErrUnsorted{Index: 1}},
+		{"CDF", func() { CDF(1, Empirical, []float64{1, 3, 2}, nil) }, ErrUnsorted{Index: 2}},
+		{"CovarianceMatrix shape", func() { CovarianceMatrix(mat64.NewDense(1, 1, nil), mat64.NewDense(3, 2, nil), nil) }, mat64.ErrShape},
+		{"CovarianceMatrix weights", func() {
+			CovarianceMatrix(nil, mat64.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 7}), []float64{1, -1, 1})
+		}, ErrNegativeWeight{Index: 1}},
+		{"Crosstab", func() { Crosstab([]int{0, 1}, []int{0}) }, ErrLengthMismatch{Got: 1, Want: 2}},
+	} {
+		got := recovered(test.f)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("case %d (%s): got panic value %#v, want %#v", i, test.name, got, test.want)
+		}
+		if err, ok := got.(error); !ok || err.Error() == "" {
+			t.Errorf("case %d (%s): panic value is not an error", i, test.name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// WhitenKind specifies the whitening transform computed by Whiten.
+type WhitenKind int
+
+const (
+	// PCAWhiten projects the centered data onto the eigenvectors of their
+	// covariance matrix, in decreasing order of the eigenvalues, and
+	// scales the projections to unit variance,
+	//  W = V Λ^(-1/2)
+	// so that the columns of the result are the standardized principal
+	// components.
+	PCAWhiten WhitenKind = iota
+	// ZCAWhiten, or Mahalanobis whitening, rotates the PCA-whitened data
+	// back to the original axes,
+	//  W = V Λ^(-1/2) V^T = Σ^(-1/2)
+	// which gives the whitened data closest to the centered data in the
+	// least squares sense, so that each column of the result still
+	// corresponds to that of the data.
+	ZCAWhiten
+)
+
+// WhitenModel holds a whitening transform fitted by Whiten. Transform
+// applies it to other data, so that for example a test set is transformed
+// consistently with the training set.
+type WhitenModel struct {
+	// Means holds the means of the columns of the fitted data.
+	Means []float64
+	// W is the whitening matrix, which multiplies the centered rows from
+	// the right.
+	W *mat64.Dense
+}
+
+// Whiten fits a whitening transform of the kind specified by kind to the
+// columns of x, and stores the transformed data in dst, whose columns have
+// zero mean and are uncorrelated with unit sample variance, as computed by
+// CovarianceMatrix. The rows of the result are
+//  z_i = (x_i - x̄) W
+// where the whitening matrix W is computed from the eigendecomposition
+// Σ = V Λ V^T of the sample covariance matrix, with the eigenvalues λ
+// replaced by λ + eps. A positive eps regularizes the transform of data
+// whose covariance matrix is singular or nearly so, at the cost of columns
+// of variance λ/(λ + eps) rather than 1.
+//
+// Eigenvalues below 10 c ε times the largest, for c columns and the machine
+// epsilon ε, are taken to be zero.
+//
+// dst must have the same dimensions as x, and may be x itself. Whiten panics
+// if eps is negative, if kind is not a valid WhitenKind, or if an
+// eigenvalue plus eps is not positive, as for data with linearly dependent
+// columns and eps = 0.
+func Whiten(dst *mat64.Dense, x mat64.Matrix, kind WhitenKind, eps float64) (fitted WhitenModel) {
+	if kind != PCAWhiten && kind != ZCAWhiten {
+		panic("stat: bad whitening kind")
+	}
+	if !(eps >= 0) {
+		panic("stat: negative regularization")
+	}
+	checkColumnsDst(dst, x)
+	_, c := x.Dims()
+	cov := CovarianceMatrix(nil, x, nil)
+	vals, vecs := symEigen(cov.RawMatrix().Data, c)
+
+	// Order the eigenpairs by decreasing eigenvalue.
+	neg := make([]float64, c)
+	for i, v := range vals {
+		neg[i] = -v
+	}
+	order := argsortFloats(neg)
+	// Eigenvalues that are zero up to the rounding of the decomposition
+	// are set to zero, so that a singular covariance matrix is detected.
+	var tol float64
+	if c > 0 {
+		tol = 10 * float64(c) * 2.220446049250313e-16 * vals[order[0]]
+	}
+	scale := make([]float64, c)
+	for k, idx := range order {
+		l := vals[idx]
+		if l < tol {
+			l = 0
+		}
+		l += eps
+		if !(l > 0) {
+			panic("stat: singular covariance matrix")
+		}
+		scale[k] = 1 / math.Sqrt(l)
+	}
+
+	// The columns of pca are those of V Λ^(-1/2) in decreasing order.
+	pca := mat64.NewDense(c, c, nil)
+	for i := 0; i < c; i++ {
+		row := pca.RawRowView(i)
+		for k, idx := range order {
+			row[k] = vecs[i*c+idx] * scale[k]
+		}
+	}
+	w := pca
+	if kind == ZCAWhiten {
+		w = mat64.NewDense(c, c, nil)
+		for i := 0; i < c; i++ {
+			for j := i; j < c; j++ {
+				var v float64
+				for k, idx := range order {
+					v += pca.At(i, k) * vecs[j*c+idx]
+				}
+				w.Set(i, j, v)
+				w.Set(j, i, v)
+			}
+		}
+	}
+	fitted = WhitenModel{Means: ColumnMeans(nil, x, nil), W: w}
+	fitted.Transform(dst, x)
+	return fitted
+}
+
+// Transform stores in dst the matrix x whitened by the fitted transform,
+// with the fitted means subtracted from its rows before the multiplication
+// by W. x must have as many columns as m has means, and dst must have the
+// same dimensions as x, and may be x itself.
+func (m WhitenModel) Transform(dst *mat64.Dense, x mat64.Matrix) {
+	checkColumnsDst(dst, x)
+	r, c := x.Dims()
+	if c != len(m.Means) {
+		panic(mat64.ErrShape)
+	}
+	centered := make([]float64, c)
+	eachRow(x, 0, r, func(i int, row []float64) {
+		floats.SubTo(centered, row, m.Means)
+		d := dst.RawRowView(i)
+		for j := range d {
+			d[j] = 0
+		}
+		for k, v := range centered {
+			floats.AddScaled(d, v, m.W.RawRowView(k))
+		}
+	})
+}
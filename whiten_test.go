@@ -0,0 +1,127 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+func TestWhiten(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r, c := 200, 4
+	x := mat64.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		z := rnd.NormFloat64()
+		for j := 0; j < c; j++ {
+			x.Set(i, j, 5+float64(j+1)*(z+0.5*rnd.NormFloat64()))
+		}
+	}
+	orig := mat64.DenseCopyOf(x)
+	identity := make([]float64, c*c)
+	for i := 0; i < c; i++ {
+		identity[i*c+i] = 1
+	}
+	for _, kind := range []WhitenKind{PCAWhiten, ZCAWhiten} {
+		dst := mat64.NewDense(r, c, nil)
+		m := Whiten(dst, x, kind, 0)
+		if !x.Equals(orig) {
+			t.Errorf("kind %d: input modified", kind)
+		}
+		cov := CovarianceMatrix(nil, dst, nil)
+		if !floats.EqualApprox(cov.RawMatrix().Data, identity, 1e-12) {
+			t.Errorf("kind %d: covariance of whitened data is not the identity: %v", kind, cov.RawMatrix().Data)
+		}
+		for j, v := range ColumnMeans(nil, dst, nil) {
+			if math.Abs(v) > 1e-12 {
+				t.Errorf("kind %d: mean of column %d is %v", kind, j, v)
+			}
+		}
+		// W^T Σ W = I.
+		var prod, wsw mat64.Dense
+		prod.MulTrans(m.W, true, CovarianceMatrix(nil, x, nil), false)
+		wsw.Mul(&prod, m.W)
+		if !floats.EqualApprox(wsw.RawMatrix().Data, identity, 1e-12) {
+			t.Errorf("kind %d: W^T Σ W is not the identity: %v", kind, wsw.RawMatrix().Data)
+		}
+
+		// Transform reproduces the result, also in place.
+		again := mat64.DenseCopyOf(x)
+		m.Transform(again, again)
+		if !floats.EqualApprox(again.RawMatrix().Data, dst.RawMatrix().Data, 1e-12) {
+			t.Errorf("kind %d: Transform does not reproduce the whitened data", kind)
+		}
+
+		switch kind {
+		case PCAWhiten:
+			// The first component carries the common factor, so that it
+			// is correlated with every column.
+			for j := 0; j < c; j++ {
+				var a, b []float64
+				for i := 0; i < r; i++ {
+					a = append(a, dst.At(i, 0))
+					b = append(b, x.At(i, j))
+				}
+				if math.Abs(Correlation(a, b, nil)) < 0.7 {
+					t.Errorf("first principal component weakly correlated with column %d", j)
+				}
+			}
+		case ZCAWhiten:
+			for i := 0; i < c; i++ {
+				for j := 0; j < i; j++ {
+					if math.Abs(m.W.At(i, j)-m.W.At(j, i)) > 1e-14 {
+						t.Errorf("ZCA whitening matrix is not symmetric")
+					}
+				}
+			}
+			// Each whitened column corresponds to the original column.
+			for j := 0; j < c; j++ {
+				var a, b []float64
+				for i := 0; i < r; i++ {
+					a = append(a, dst.At(i, j))
+					b = append(b, x.At(i, j))
+				}
+				if Correlation(a, b, nil) < 0.3 {
+					t.Errorf("ZCA column %d not correlated with the original", j)
+				}
+			}
+		}
+	}
+
+	// Linearly dependent columns need regularization, which leaves the
+	// null direction with zero variance and the others shrunk.
+	dep := mat64.NewDense(r, 3, nil)
+	for i := 0; i < r; i++ {
+		a, b := rnd.NormFloat64(), rnd.NormFloat64()
+		dep.Set(i, 0, a)
+		dep.Set(i, 1, b)
+		dep.Set(i, 2, a+b)
+	}
+	if !Panics(func() { Whiten(mat64.NewDense(r, 3, nil), dep, PCAWhiten, 0) }) {
+		t.Errorf("expected panic for singular covariance without regularization")
+	}
+	dst := mat64.NewDense(r, 3, nil)
+	Whiten(dst, dep, PCAWhiten, 1e-3)
+	vars := ColumnVariances(nil, dst, nil)
+	if vars[0] > 1 || vars[0] < 0.99 || vars[1] > 1 || vars[1] < 0.99 || vars[2] > 1e-9 {
+		t.Errorf("unexpected variances of regularized whitening %v", vars)
+	}
+
+	m := Whiten(mat64.NewDense(r, c, nil), x, PCAWhiten, 0)
+	for i, fn := range []func(){
+		func() { Whiten(mat64.NewDense(r, c, nil), x, WhitenKind(2), 0) },
+		func() { Whiten(mat64.NewDense(r, c, nil), x, PCAWhiten, -1) },
+		func() { Whiten(mat64.NewDense(r, c+1, nil), x, PCAWhiten, 0) },
+		func() { m.Transform(mat64.NewDense(2, 3, nil), mat64.NewDense(2, 3, nil)) },
+	} {
+		if !Panics(fn) {
+			t.Errorf("case %d: expected panic", i)
+		}
+	}
+}